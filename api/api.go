@@ -17,6 +17,7 @@ import (
 	authmw "github.com/caesium-cloud/caesium/api/middleware"
 	"github.com/caesium-cloud/caesium/api/rest/bind"
 	authctrl "github.com/caesium-cloud/caesium/api/rest/controller/auth"
+	receiptctrl "github.com/caesium-cloud/caesium/api/rest/controller/receipt"
 	"github.com/caesium-cloud/caesium/internal/auth"
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/metrics"
@@ -54,6 +55,7 @@ func Start(ctx context.Context, bus event.Bus, authSvc *auth.Service, auditor *a
 	// health
 	e.GET("/health", Health)
 	e.GET("/auth/status", authStatus(vars))
+	e.GET("/.well-known/caesium-receipt-keys", receiptctrl.Keys)
 	registerSSORoutes(e, vars, authSvc, auditor, limiter, sessions, sso, providers)
 	registerInternalWakeup(e, vars, wakeupHandler)
 
@@ -64,7 +66,7 @@ func Start(ctx context.Context, bus event.Bus, authSvc *auth.Service, auditor *a
 	// REST
 	bind.All(e.Group("/v1"), bus, authSvc, auditor, limiter, sessions)
 
-	registerGraphQL(e, vars)
+	registerGraphQL(e, vars, authSvc, auditor, limiter, sessions)
 
 	// Embedded web UI
 	RegisterUI(e)
@@ -288,13 +290,31 @@ func registerMetrics(e *echo.Echo, vars env.Environment, authSvc *auth.Service,
 	e.GET("/metrics", handler)
 }
 
-func registerGraphQL(e *echo.Echo, vars env.Environment) {
+// registerGraphQL serves the read-only GraphQL API. With authentication on it
+// sits behind the same middleware as REST, and its resolvers enforce the
+// caller's role and key scope per field.
+func registerGraphQL(e *echo.Echo, vars env.Environment, authSvc *auth.Service, auditor *auth.AuditLogger, limiter *auth.RateLimiter, sessions *auth.SessionStore) {
 	if vars.AuthMode == "none" {
-		e.GET("/gql", gql.Handler())
+		handler := gql.Handler(true)
+		e.GET("/gql", handler)
+		e.POST("/gql", handler)
+		return
+	}
+	if authSvc == nil {
+		log.Info("graphql endpoint disabled while authentication is enabled")
 		return
 	}
 
-	log.Info("graphql endpoint disabled while authentication is enabled")
+	handler := gql.Handler(false)
+	mw := authmw.Auth(authmw.AuthDeps{
+		Service:    authSvc,
+		Auditor:    auditor,
+		Limiter:    limiter,
+		Sessions:   sessions,
+		CookieName: vars.AuthSessionCookieName,
+	})
+	e.GET("/gql", handler, mw)
+	e.POST("/gql", handler, mw)
 }
 
 func configureIPExtractor(e *echo.Echo, vars env.Environment) {
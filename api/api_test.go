@@ -22,14 +22,30 @@ import (
 
 func TestRegisterGraphQLWhenAuthDisabled(t *testing.T) {
 	e := echo.New()
-	registerGraphQL(e, env.Environment{AuthMode: "none"})
+	registerGraphQL(e, env.Environment{AuthMode: "none"}, nil, nil, nil, nil)
 
 	require.True(t, hasRoute(e, http.MethodGet, "/gql"))
+	require.True(t, hasRoute(e, http.MethodPost, "/gql"))
 }
 
-func TestRegisterGraphQLSkippedWhenAuthEnabled(t *testing.T) {
+func TestRegisterGraphQLProtectedWhenAuthEnabled(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	e := echo.New()
+	registerGraphQL(e, env.Environment{AuthMode: "api-key"}, iauth.NewService(db), iauth.NewAuditLogger(db), iauth.NewRateLimiter(5, time.Minute), nil)
+	require.True(t, hasRoute(e, http.MethodPost, "/gql"))
+
+	req := httptest.NewRequest(http.MethodPost, "/gql", strings.NewReader(`{"query":"{ jobs { id } }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRegisterGraphQLSkippedWithoutAuthService(t *testing.T) {
 	e := echo.New()
-	registerGraphQL(e, env.Environment{AuthMode: "api-key"})
+	registerGraphQL(e, env.Environment{AuthMode: "api-key"}, nil, nil, nil, nil)
 
 	require.False(t, hasRoute(e, http.MethodGet, "/gql"))
 }
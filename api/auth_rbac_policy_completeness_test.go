@@ -8,6 +8,7 @@ import (
 
 	authmw "github.com/caesium-cloud/caesium/api/middleware"
 	"github.com/caesium-cloud/caesium/api/rest/bind"
+	receiptctrl "github.com/caesium-cloud/caesium/api/rest/controller/receipt"
 	iauth "github.com/caesium-cloud/caesium/internal/auth"
 	"github.com/caesium-cloud/caesium/pkg/env"
 	"github.com/labstack/echo/v5"
@@ -40,6 +41,7 @@ func TestAuthMiddlewareMountedRoutesHaveRBACPolicy(t *testing.T) {
 
 	e.GET("/health", Health)
 	e.GET("/auth/status", authStatus(vars))
+	e.GET("/.well-known/caesium-receipt-keys", receiptctrl.Keys)
 	registerSSORoutes(e, vars, authSvc, nil, nil, sessions, nil, providers)
 	registerMetrics(e, vars, authSvc, nil, nil, sessions)
 	bind.All(e.Group("/v1"), nil, authSvc, nil, nil, sessions)
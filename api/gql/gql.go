@@ -2,26 +2,35 @@ package gql
 
 import (
 	"github.com/caesium-cloud/caesium/api/gql/schema"
+	authmw "github.com/caesium-cloud/caesium/api/middleware"
+	"github.com/caesium-cloud/caesium/pkg/db"
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/handler"
 	"github.com/labstack/echo/v5"
 )
 
 // Handler wraps the GraphQL schema and makes it injectable
-// into the echo HTTP framework.
-func Handler() echo.HandlerFunc {
-	schema, err := graphql.NewSchema(schema.New())
+// into the echo HTTP framework. Each request executes as the principal the
+// auth middleware authenticated, if any, with its own batch loaders.
+func Handler(graphiQL bool) echo.HandlerFunc {
+	s, err := graphql.NewSchema(schema.New())
 	if err != nil {
 		panic(err)
 	}
 
-	return echo.WrapHandler(
-		handler.New(
-			&handler.Config{
-				Schema:   &schema,
-				Pretty:   true,
-				GraphiQL: true,
-			},
-		),
+	h := handler.New(
+		&handler.Config{
+			Schema:   &s,
+			Pretty:   true,
+			GraphiQL: graphiQL,
+		},
 	)
+
+	return func(c *echo.Context) error {
+		req := c.Request()
+		ctx := schema.WithRequest(req.Context(), db.Connection(), authmw.GetPrincipal(c))
+		ctx = schema.WithHistory(ctx, db.DefaultRouter().Cold())
+		h.ContextHandler(ctx, c.Response(), req)
+		return nil
+	}
 }
@@ -0,0 +1,177 @@
+package schema
+
+import (
+	"slices"
+
+	datasetsvc "github.com/caesium-cloud/caesium/api/rest/service/dataset"
+	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
+	tasksvc "github.com/caesium-cloud/caesium/api/rest/service/task"
+	tsvc "github.com/caesium-cloud/caesium/api/rest/service/trigger"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/google/uuid"
+)
+
+// batch collects the keys a query level asks for and fetches them together
+// the first time any of them is read. Resolvers return the thunk from load
+// instead of a value; graphql-go resolves thunks breadth first, after every
+// sibling field has queued its key, so a list of N objects costs one fetch
+// rather than N. A batch lives for one request, which graphql-go executes on
+// a single goroutine.
+type batch[K comparable, V any] struct {
+	fetch   func([]K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func newBatch[K comparable, V any](fetch func([]K) (map[K]V, error)) *batch[K, V] {
+	return &batch[K, V]{
+		fetch:   fetch,
+		queued:  make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// load queues key and returns a function that reads its value, reporting
+// false when the fetch did not find it.
+func (b *batch[K, V]) load(key K) func() (V, bool, error) {
+	if !b.queued[key] {
+		b.queued[key] = true
+		b.pending = append(b.pending, key)
+	}
+	return func() (V, bool, error) {
+		if len(b.pending) > 0 {
+			keys := b.pending
+			b.pending = nil
+			results, err := b.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					b.errs[k] = err
+				} else if v, ok := results[k]; ok {
+					b.results[k] = v
+				}
+			}
+		}
+		if err := b.errs[key]; err != nil {
+			var zero V
+			return zero, false, err
+		}
+		v, ok := b.results[key]
+		return v, ok, nil
+	}
+}
+
+// loaders holds a request's batches.
+type loaders struct {
+	jobs        *batch[uuid.UUID, *models.Job]
+	triggers    *batch[uuid.UUID, *models.Trigger]
+	tasks       *batch[uuid.UUID, *models.Task]
+	jobTasks    *batch[uuid.UUID, models.Tasks]
+	runs        *batch[uuid.UUID, *runstorage.JobRun]
+	recentRuns  map[int]*batch[uuid.UUID, []*runstorage.JobRun]
+	derivations map[int]*batch[datasetsvc.Key, []models.DatasetDerivation]
+
+	r *request
+}
+
+func newLoaders(r *request) *loaders {
+	l := &loaders{
+		r:           r,
+		recentRuns:  make(map[int]*batch[uuid.UUID, []*runstorage.JobRun]),
+		derivations: make(map[int]*batch[datasetsvc.Key, []models.DatasetDerivation]),
+	}
+	l.jobs = newBatch(func(ids []uuid.UUID) (map[uuid.UUID]*models.Job, error) {
+		jobs, err := jsvc.ServiceWithDatabase(r.ctx, r.db).List(&jsvc.ListRequest{IDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[uuid.UUID]*models.Job, len(jobs))
+		for _, job := range jobs {
+			out[job.ID] = job
+		}
+		return out, nil
+	})
+	l.triggers = newBatch(func(ids []uuid.UUID) (map[uuid.UUID]*models.Trigger, error) {
+		triggers, err := tsvc.ServiceWithDatabase(r.ctx, r.db).List(&tsvc.ListRequest{IDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[uuid.UUID]*models.Trigger, len(triggers))
+		for _, trigger := range triggers {
+			out[trigger.ID] = trigger
+		}
+		return out, nil
+	})
+	l.tasks = newBatch(func(ids []uuid.UUID) (map[uuid.UUID]*models.Task, error) {
+		tasks, err := tasksvc.ServiceWithDB(r.ctx, r.db).List(&tasksvc.ListRequest{IDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[uuid.UUID]*models.Task, len(tasks))
+		for _, task := range tasks {
+			out[task.ID] = task
+		}
+		return out, nil
+	})
+	l.jobTasks = newBatch(func(jobIDs []uuid.UUID) (map[uuid.UUID]models.Tasks, error) {
+		tasks, err := tasksvc.ServiceWithDB(r.ctx, r.db).List(&tasksvc.ListRequest{
+			JobIDs:  jobIDs,
+			OrderBy: []string{"position", "name"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[uuid.UUID]models.Tasks, len(jobIDs))
+		for _, task := range tasks {
+			out[task.JobID] = append(out[task.JobID], task)
+		}
+		return out, nil
+	})
+	l.runs = newBatch(func(ids []uuid.UUID) (map[uuid.UUID]*runstorage.JobRun, error) {
+		return r.runs().ListByIDs(ids)
+	})
+	return l
+}
+
+// recent returns the batch loading each job's latest limit runs, newest
+// first.
+func (l *loaders) recent(limit int) *batch[uuid.UUID, []*runstorage.JobRun] {
+	if b, ok := l.recentRuns[limit]; ok {
+		return b
+	}
+	b := newBatch(func(jobIDs []uuid.UUID) (map[uuid.UUID][]*runstorage.JobRun, error) {
+		summaries, err := jsvc.ServiceWithDatabase(l.r.ctx, l.r.db).ListRecentRuns(jobIDs, limit)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[uuid.UUID][]*runstorage.JobRun, len(summaries))
+		for jobID, runs := range summaries {
+			converted := make([]*runstorage.JobRun, 0, len(runs))
+			for _, run := range runs {
+				converted = append(converted, run.JobRun())
+			}
+			// ListRecentRuns orders oldest first.
+			slices.Reverse(converted)
+			out[jobID] = converted
+		}
+		return out, nil
+	})
+	l.recentRuns[limit] = b
+	return b
+}
+
+// recentDerivations returns the batch loading each dataset's latest limit
+// derivations, newest first.
+func (l *loaders) recentDerivations(limit int) *batch[datasetsvc.Key, []models.DatasetDerivation] {
+	if b, ok := l.derivations[limit]; ok {
+		return b
+	}
+	b := newBatch(func(keys []datasetsvc.Key) (map[datasetsvc.Key][]models.DatasetDerivation, error) {
+		return datasetsvc.NewWithDatabase(l.r.ctx, l.r.db).RecentDerivations(keys, limit)
+	})
+	l.derivations[limit] = b
+	return b
+}
@@ -0,0 +1,165 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	authmw "github.com/caesium-cloud/caesium/api/middleware"
+	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
+	runsvc "github.com/caesium-cloud/caesium/api/rest/service/run"
+	"github.com/caesium-cloud/caesium/internal/auth"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errForbidden is returned for a field the caller's role or key scope does
+// not cover, matching the REST API's 403.
+var errForbidden = errors.New("insufficient permissions")
+
+// errLineageScoped mirrors the REST API's reason for denying lineage impact
+// to scoped principals.
+var errLineageScoped = errors.New(authmw.LineageImpactScopedDenyMessage)
+
+// request is what resolvers share while executing one GraphQL request.
+type request struct {
+	ctx       context.Context
+	db        *gorm.DB
+	history   *gorm.DB
+	principal *auth.Principal
+	loaders   *loaders
+}
+
+type requestKey struct{}
+
+// WithRequest prepares ctx for executing one GraphQL request against conn on
+// behalf of principal. A nil principal is unrestricted, as it is for REST when
+// authentication is disabled.
+func WithRequest(ctx context.Context, conn *gorm.DB, principal *auth.Principal) context.Context {
+	r := &request{ctx: ctx, db: conn, principal: principal}
+	r.loaders = newLoaders(r)
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// WithHistory lets the request prepared by WithRequest read runs that were
+// archived to cold when conn no longer has them.
+func WithHistory(ctx context.Context, cold *gorm.DB) context.Context {
+	if r, ok := ctx.Value(requestKey{}).(*request); ok {
+		r.history = cold
+	}
+	return ctx
+}
+
+func requestFrom(ctx context.Context) (*request, error) {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return nil, errors.New("graphql request context is not initialised")
+	}
+	return r, nil
+}
+
+// authorize applies the REST API's RBAC policy for a GET of path, so a field
+// is readable exactly when the route it mirrors is.
+func (r *request) authorize(path string) error {
+	if r.principal == nil {
+		return nil
+	}
+	required, ok := auth.RequiredRole("GET", path)
+	if !ok || !auth.HasRole(r.principal.Role, required) {
+		return errForbidden
+	}
+	return nil
+}
+
+// scope returns the caller's key scope, or nil when it is unrestricted.
+func (r *request) scope() (*models.KeyScope, error) {
+	if r.principal == nil {
+		return nil, nil
+	}
+	scope, err := auth.DecodeScope(r.principal.Scope)
+	if err != nil {
+		return nil, errForbidden
+	}
+	return scope, nil
+}
+
+// authorizeUnscoped authorizes path for callers without a key scope only, as
+// the REST API does for its global, cross-job routes. Scoped callers get
+// denied.
+func (r *request) authorizeUnscoped(path string, denied error) error {
+	if err := r.authorize(path); err != nil {
+		return err
+	}
+	scope, err := r.scope()
+	if err != nil {
+		return err
+	}
+	if scope != nil {
+		return denied
+	}
+	return nil
+}
+
+// canSeeJob reports whether the caller's scope covers job.
+func (r *request) canSeeJob(job *models.Job) bool {
+	if r.principal == nil {
+		return true
+	}
+	return auth.CheckJobScope(r.principal.Scope, auth.ScopedJob{Namespace: job.Namespace, Alias: job.Alias})
+}
+
+// jobFilter narrows a job listing to the caller's scope and the requested
+// namespace and alias. Scoped jobs are qualified aliases that the listing
+// matches on namespace and alias together.
+func (r *request) jobFilter(namespace, alias string) (*jsvc.ListRequest, error) {
+	scope, err := r.scope()
+	if err != nil {
+		return nil, err
+	}
+	req := &jsvc.ListRequest{}
+	if scope != nil {
+		req.Namespaces, req.Jobs = scope.Namespaces, scope.Jobs
+	}
+	if namespace = strings.TrimSpace(namespace); namespace != "" {
+		if len(req.Namespaces) > 0 && !slices.Contains(req.Namespaces, namespace) {
+			return nil, errForbidden
+		}
+		req.Namespaces = []string{namespace}
+	}
+	if alias = strings.TrimSpace(alias); alias != "" {
+		req.Aliases = []string{alias}
+	}
+	return req, nil
+}
+
+// triggerNamespaces returns the namespaces a trigger listing is limited to.
+// Triggers carry no job alias, so only a principal scoped purely by
+// namespace may list them.
+func (r *request) triggerNamespaces() ([]string, error) {
+	scope, err := r.scope()
+	if err != nil || scope == nil {
+		return nil, err
+	}
+	if len(scope.Jobs) > 0 {
+		return nil, errForbidden
+	}
+	return scope.Namespaces, nil
+}
+
+func parseID(raw any) (uuid.UUID, error) {
+	value, _ := raw.(string)
+	id, err := uuid.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return uuid.Nil, errors.New("invalid id")
+	}
+	return id, nil
+}
+
+// runs returns the run service for this request, reading archived runs from
+// history when there is one.
+func (r *request) runs() runsvc.Service {
+	return runsvc.NewWithDatabase(r.ctx, r.db).WithStore(runstorage.NewStore(r.db).WithHistory(r.history))
+}
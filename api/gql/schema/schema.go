@@ -1,64 +1,301 @@
+// Package schema is Caesium's read-only GraphQL schema. Its resolvers sit on
+// the same services as the REST API and apply the same RBAC policy and key
+// scopes, and nested lookups are batched per request so that listing N jobs
+// with their runs and tasks costs a fixed number of queries, not N.
 package schema
 
-import "github.com/graphql-go/graphql"
+import (
+	"errors"
+	"strings"
 
-// jobRunParamsType exposes run parameters as a GraphQL scalar (JSON object).
-var jobRunParamsType = graphql.NewScalar(graphql.ScalarConfig{
-	Name:        "JobRunParams",
-	Description: "Key-value pairs passed to a job run as parameters.",
-	Serialize: func(value interface{}) interface{} {
-		return value
-	},
-})
-
-// JobRunType exposes a job run including its params.
-var JobRunType = graphql.NewObject(graphql.ObjectConfig{
-	Name:        "JobRun",
-	Description: "A single execution of a job.",
-	Fields: graphql.Fields{
-		"id": &graphql.Field{
-			Type:        graphql.String,
-			Description: "The UUID of the job run.",
-			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-				if r, ok := p.Source.(map[string]interface{}); ok {
-					return r["id"], nil
-				}
-				return nil, nil
-			},
-		},
-		"params": &graphql.Field{
-			Type:        jobRunParamsType,
-			Description: "Parameters passed to the run.",
-			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-				if r, ok := p.Source.(map[string]interface{}); ok {
-					return r["params"], nil
-				}
-				return nil, nil
-			},
-		},
-	},
-})
+	datasetsvc "github.com/caesium-cloud/caesium/api/rest/service/dataset"
+	incidentsvc "github.com/caesium-cloud/caesium/api/rest/service/incident"
+	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
+	lineagesvc "github.com/caesium-cloud/caesium/api/rest/service/lineage"
+	tsvc "github.com/caesium-cloud/caesium/api/rest/service/trigger"
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
 
 // New instantiates a fresh GraphQL schema for
 // Caesium's API.
 func New() graphql.SchemaConfig {
+	t := newTypes()
 	return graphql.SchemaConfig{
 		Query: graphql.NewObject(
 			graphql.ObjectConfig{
 				Name:   "Query",
-				Fields: fields(),
+				Fields: fields(t),
 			},
 		),
 	}
 }
 
-func fields() graphql.Fields {
+func fields(t *types) graphql.Fields {
 	return graphql.Fields{
-		"place": &graphql.Field{
-			Type: graphql.String,
-			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-				return "holder", nil
+		"jobs": &graphql.Field{
+			Type:        nonNullList(t.job),
+			Description: "Jobs visible to the caller, optionally filtered by namespace and alias.",
+			Args: graphql.FieldConfigArgument{
+				"namespace": {Type: graphql.String},
+				"alias":     {Type: graphql.String},
+				"limit":     {Type: graphql.Int},
+				"offset":    {Type: graphql.Int},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorize("/v1/jobs"); err != nil {
+					return nil, err
+				}
+				namespace, _ := p.Args["namespace"].(string)
+				alias, _ := p.Args["alias"].(string)
+				req, err := r.jobFilter(namespace, alias)
+				if err != nil {
+					return []interface{}{}, err
+				}
+				req.Limit = uintArg(p, "limit")
+				req.Offset = uintArg(p, "offset")
+				req.OrderBy = []string{"namespace", "alias"}
+				return jsvc.ServiceWithDatabase(r.ctx, r.db).List(req)
+			}),
+		},
+		"job": &graphql.Field{
+			Type:        t.job,
+			Description: "The job with the given ID.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorize("/v1/jobs/:id"); err != nil {
+					return nil, err
+				}
+				id, err := parseID(p.Args["id"])
+				if err != nil {
+					return nil, err
+				}
+				job, err := jsvc.ServiceWithDatabase(r.ctx, r.db).Get(id)
+				if err != nil {
+					return nil, notFoundAsNull(err)
+				}
+				if !r.canSeeJob(job) {
+					return nil, errForbidden
+				}
+				return job, nil
+			}),
+		},
+		"triggers": &graphql.Field{
+			Type:        nonNullList(t.trigger),
+			Description: "Triggers, optionally filtered by type. Callers scoped to job aliases may not list triggers.",
+			Args: graphql.FieldConfigArgument{
+				"type":   {Type: graphql.String},
+				"limit":  {Type: graphql.Int},
+				"offset": {Type: graphql.Int},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorize("/v1/triggers"); err != nil {
+					return nil, err
+				}
+				namespaces, err := r.triggerNamespaces()
+				if err != nil {
+					return nil, err
+				}
+				triggerType, _ := p.Args["type"].(string)
+				return tsvc.ServiceWithDatabase(r.ctx, r.db).List(&tsvc.ListRequest{
+					Limit:      uintArg(p, "limit"),
+					Offset:     uintArg(p, "offset"),
+					OrderBy:    []string{"namespace", "alias"},
+					Type:       triggerType,
+					Namespaces: namespaces,
+				})
+			}),
+		},
+		"trigger": &graphql.Field{
+			Type:        t.trigger,
+			Description: "The trigger with the given ID. Requires an unscoped caller.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorizeUnscoped("/v1/triggers/:id", errForbidden); err != nil {
+					return nil, err
+				}
+				id, err := parseID(p.Args["id"])
+				if err != nil {
+					return nil, err
+				}
+				trigger, err := tsvc.ServiceWithDatabase(r.ctx, r.db).Get(id)
+				if err != nil {
+					return nil, notFoundAsNull(err)
+				}
+				return trigger, nil
+			}),
+		},
+		"run": &graphql.Field{
+			Type:        t.run,
+			Description: "The job run with the given ID.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorize("/v1/jobs/:id/runs/:id"); err != nil {
+					return nil, err
+				}
+				id, err := parseID(p.Args["id"])
+				if err != nil {
+					return nil, err
+				}
+				run, err := r.runs().Get(id)
+				if err != nil {
+					return nil, notFoundAsNull(err)
+				}
+				if r.principal != nil {
+					job, err := jsvc.ServiceWithDatabase(r.ctx, r.db).Get(run.JobID)
+					if err != nil {
+						return nil, notFoundAsNull(err)
+					}
+					if !r.canSeeJob(job) {
+						return nil, errForbidden
+					}
+				}
+				return run, nil
+			}),
+		},
+		"datasets": &graphql.Field{
+			Type:        nonNullList(t.dataset),
+			Description: "Dataset freshness states newest first. Requires an unscoped caller.",
+			Args: graphql.FieldConfigArgument{
+				"status": {Type: graphql.String},
+				"limit":  {Type: graphql.Int},
+				"offset": {Type: graphql.Int},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorizeUnscoped("/v1/datasets", errForbidden); err != nil {
+					return nil, err
+				}
+				status, _ := p.Args["status"].(string)
+				limit, _ := p.Args["limit"].(int)
+				offset, _ := p.Args["offset"].(int)
+				result, err := datasetsvc.NewWithDatabase(r.ctx, r.db).List(datasetsvc.ListParams{Status: status, Limit: limit, Offset: offset})
+				if err != nil {
+					return nil, err
+				}
+				return result.Datasets, nil
+			}),
+		},
+		"dataset": &graphql.Field{
+			Type:        t.dataset,
+			Description: "One dataset's freshness state. Requires an unscoped caller.",
+			Args: graphql.FieldConfigArgument{
+				"namespace": {Type: graphql.String},
+				"name":      {Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorizeUnscoped("/v1/datasets/:id/:id", errForbidden); err != nil {
+					return nil, err
+				}
+				namespace, _ := p.Args["namespace"].(string)
+				name, _ := p.Args["name"].(string)
+				detail, err := datasetsvc.NewWithDatabase(r.ctx, r.db).Get(namespace, name)
+				if err != nil {
+					return nil, notFoundAsNull(err)
+				}
+				return &detail.State, nil
+			}),
+		},
+		"lineageImpact": &graphql.Field{
+			Type:        graphql.NewNonNull(t.impact),
+			Description: "Every dataset transitively downstream of a dataset. Requires an unscoped caller.",
+			Args: graphql.FieldConfigArgument{
+				"namespace": {Type: graphql.NewNonNull(graphql.String)},
+				"name":      {Type: graphql.NewNonNull(graphql.String)},
+				"maxDepth":  {Type: graphql.Int},
 			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorizeUnscoped("/v1/lineage/impact", errLineageScoped); err != nil {
+					return nil, err
+				}
+				namespace, _ := p.Args["namespace"].(string)
+				name, _ := p.Args["name"].(string)
+				maxDepth, _ := p.Args["maxDepth"].(int)
+				return lineagesvc.NewWithDatabase(r.ctx, r.db).Impact(strings.TrimSpace(namespace), strings.TrimSpace(name), maxDepth)
+			}),
+		},
+		"incidents": &graphql.Field{
+			Type:        nonNullList(t.incident),
+			Description: "Incidents newest first. Requires an unscoped caller.",
+			Args: graphql.FieldConfigArgument{
+				"status": {Type: graphql.String},
+				"class":  {Type: graphql.String},
+				"jobId":  {Type: graphql.ID},
+				"limit":  {Type: graphql.Int},
+				"offset": {Type: graphql.Int},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorizeUnscoped("/v1/incidents", errForbidden); err != nil {
+					return nil, err
+				}
+				params := incidentsvc.ListParams{}
+				params.Status, _ = p.Args["status"].(string)
+				params.Class, _ = p.Args["class"].(string)
+				params.Limit, _ = p.Args["limit"].(int)
+				params.Offset, _ = p.Args["offset"].(int)
+				if raw, ok := p.Args["jobId"]; ok {
+					jobID, err := parseID(raw)
+					if err != nil {
+						return nil, err
+					}
+					params.JobID = &jobID
+				}
+				result, err := incidentsvc.NewWithDatabase(r.ctx, r.db).List(params)
+				if err != nil {
+					return nil, err
+				}
+				return result.Incidents, nil
+			}),
+		},
+		"incident": &graphql.Field{
+			Type:        t.incident,
+			Description: "The incident with the given ID. Requires an unscoped caller.",
+			Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				if err := r.authorizeUnscoped("/v1/incidents/:id", errForbidden); err != nil {
+					return nil, err
+				}
+				id, err := parseID(p.Args["id"])
+				if err != nil {
+					return nil, err
+				}
+				detail, err := incidentsvc.NewWithDatabase(r.ctx, r.db).Get(id)
+				if err != nil {
+					return nil, notFoundAsNull(err)
+				}
+				return &detail.Incident, nil
+			}),
 		},
 	}
 }
+
+// resolve hands fn the request state that WithRequest put on the context.
+func resolve(fn func(*request, graphql.ResolveParams) (interface{}, error)) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		r, err := requestFrom(p.Context)
+		if err != nil {
+			return nil, err
+		}
+		return fn(r, p)
+	}
+}
+
+// notFoundAsNull resolves a missing record to null, as GraphQL convention
+// has it, and passes any other error through.
+func notFoundAsNull(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func uintArg(p graphql.ResolveParams, name string) uint64 {
+	value, _ := p.Args[name].(int)
+	if value < 0 {
+		return 0
+	}
+	return uint64(value)
+}
+
+func nonNullList(of graphql.Type) *graphql.NonNull {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(of)))
+}
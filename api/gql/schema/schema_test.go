@@ -0,0 +1,317 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/auth"
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const jobsQuery = `{
+	jobs {
+		alias
+		trigger { alias type }
+		tasks { name }
+		latestRun { status }
+		runs {
+			status
+			cacheHits
+			taskRuns { status cacheHit output task { name } }
+		}
+	}
+}`
+
+func TestJobsQueryResolvesNestedFields(t *testing.T) {
+	db := openDB(t)
+	job := createJob(t, db, "team-a", "extract", "load", "transform")
+	runID := createRun(t, db, job, map[string]string{"rows": "42"})
+
+	result := execute(t, db, nil, jobsQuery)
+	require.Empty(t, result.Errors)
+
+	var data struct {
+		Jobs []struct {
+			Alias   string
+			Trigger struct{ Alias, Type string }
+			Tasks   []struct{ Name string }
+			Runs    []struct {
+				Status    string
+				CacheHits int
+				TaskRuns  []struct {
+					Status   string
+					CacheHit bool
+					Output   map[string]string
+					Task     struct{ Name string }
+				}
+			}
+			LatestRun struct{ Status string }
+		}
+	}
+	decode(t, result, &data)
+	require.Len(t, data.Jobs, 1)
+	got := data.Jobs[0]
+	require.Equal(t, "extract", got.Alias)
+	require.Equal(t, "extract-trigger", got.Trigger.Alias)
+	require.Equal(t, "cron", got.Trigger.Type)
+	require.Len(t, got.Tasks, 2)
+	require.Len(t, got.Runs, 1)
+	require.Equal(t, "running", got.LatestRun.Status)
+	require.Equal(t, 1, got.Runs[0].CacheHits)
+
+	taskRuns := got.Runs[0].TaskRuns
+	require.Len(t, taskRuns, 2)
+	byName := map[string]int{}
+	for i, taskRun := range taskRuns {
+		byName[taskRun.Task.Name] = i
+	}
+	require.True(t, taskRuns[byName["transform"]].CacheHit)
+	require.Equal(t, map[string]string{"rows": "42"}, taskRuns[byName["transform"]].Output)
+
+	result = execute(t, db, nil, `query($id: ID!) { run(id: $id) { id job { alias } taskRuns { task { name } } } }`, "id", runID.String())
+	require.Empty(t, result.Errors)
+	var single struct {
+		Run struct {
+			ID       string
+			Job      struct{ Alias string }
+			TaskRuns []struct{ Task struct{ Name string } }
+		}
+	}
+	decode(t, result, &single)
+	require.Equal(t, runID.String(), single.Run.ID)
+	require.Equal(t, "extract", single.Run.Job.Alias)
+	require.Len(t, single.Run.TaskRuns, 2)
+}
+
+func TestNestedFieldsAreBatched(t *testing.T) {
+	db := openDB(t)
+	queries := countQueries(t, db)
+
+	job := createJob(t, db, "team-a", "first", "a", "b")
+	createRun(t, db, job, nil)
+	*queries = 0
+	require.Empty(t, execute(t, db, nil, jobsQuery).Errors)
+	single := *queries
+	require.Positive(t, single)
+
+	for _, alias := range []string{"second", "third", "fourth"} {
+		job := createJob(t, db, "team-a", alias, "a", "b")
+		createRun(t, db, job, nil)
+	}
+	*queries = 0
+	result := execute(t, db, nil, jobsQuery)
+	require.Empty(t, result.Errors)
+	var data struct{ Jobs []struct{ Alias string } }
+	decode(t, result, &data)
+	require.Len(t, data.Jobs, 4)
+	require.Equal(t, single, *queries)
+}
+
+func TestScopedPrincipalSeesOnlyItsJobs(t *testing.T) {
+	db := openDB(t)
+	allowed := createJob(t, db, "team-a", "extract", "load")
+	denied := createJob(t, db, "team-a", "report", "render")
+	deniedRun := createRun(t, db, denied, nil)
+	principal := &auth.Principal{Role: models.RoleViewer, Scope: []byte(`{"jobs":["extract"]}`)}
+
+	result := execute(t, db, principal, `{ jobs { alias } }`)
+	require.Empty(t, result.Errors)
+	var data struct{ Jobs []struct{ Alias string } }
+	decode(t, result, &data)
+	require.Len(t, data.Jobs, 1)
+	require.Equal(t, "extract", data.Jobs[0].Alias)
+
+	result = execute(t, db, principal, `query($id: ID!) { job(id: $id) { alias } }`, "id", allowed.ID.String())
+	require.Empty(t, result.Errors)
+
+	for _, query := range []struct {
+		query string
+		args  []string
+	}{
+		{`query($id: ID!) { job(id: $id) { alias } }`, []string{"id", denied.ID.String()}},
+		{`query($id: ID!) { run(id: $id) { id } }`, []string{"id", deniedRun.String()}},
+		{`{ triggers { alias } }`, nil},
+		{`{ datasets { name } }`, nil},
+		{`{ incidents { id } }`, nil},
+	} {
+		result := execute(t, db, principal, query.query, query.args...)
+		require.Len(t, result.Errors, 1, query.query)
+		require.Equal(t, errForbidden.Error(), result.Errors[0].Message, query.query)
+	}
+
+	result = execute(t, db, principal, `{ lineageImpact(namespace: "warehouse", name: "orders") { rootName } }`)
+	require.Len(t, result.Errors, 1)
+	require.Equal(t, errLineageScoped.Error(), result.Errors[0].Message)
+}
+
+func TestNamespaceScopedPrincipalListsItsTriggers(t *testing.T) {
+	db := openDB(t)
+	createJob(t, db, "team-a", "extract")
+	createJob(t, db, "team-b", "report")
+	principal := &auth.Principal{Role: models.RoleViewer, Scope: []byte(`{"namespaces":["team-a"]}`)}
+
+	result := execute(t, db, principal, `{ triggers { alias } jobs { alias } }`)
+	require.Empty(t, result.Errors)
+	var data struct {
+		Triggers []struct{ Alias string }
+		Jobs     []struct{ Alias string }
+	}
+	decode(t, result, &data)
+	require.Len(t, data.Triggers, 1)
+	require.Equal(t, "extract-trigger", data.Triggers[0].Alias)
+	require.Len(t, data.Jobs, 1)
+
+	result = execute(t, db, principal, `{ jobs(namespace: "team-b") { alias } }`)
+	require.Len(t, result.Errors, 1)
+}
+
+func TestDatasetsAndIncidentsQuery(t *testing.T) {
+	db := openDB(t)
+	job := createJob(t, db, "team-a", "extract", "load")
+	runID := createRun(t, db, job, nil)
+	now := time.Now().UTC()
+
+	require.NoError(t, db.Create(&models.DatasetState{
+		ID: uuid.New(), Name: "warehouse.orders", Watermark: "2026-10-17", Status: models.DatasetStatusFresh,
+		LastRunID: &runID, CreatedAt: now, UpdatedAt: now,
+	}).Error)
+	for i, decision := range []string{"skipped", "derived"} {
+		require.NoError(t, db.Create(&models.DatasetDerivation{
+			ID: uuid.New(), Name: "warehouse.orders", Decision: decision, RunID: &runID,
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}).Error)
+	}
+	require.NoError(t, db.Create(&models.Incident{
+		ID: uuid.New(), JobID: job.ID, RunID: &runID, TaskName: "load", Class: "oom",
+		Status: models.IncidentStatusOpen, DedupeKey: "load/oom", Evidence: datatypes.JSON(`{"exit_code":137}`),
+		OpenedAt: now, CreatedAt: now, UpdatedAt: now,
+	}).Error)
+
+	result := execute(t, db, nil, `{
+		datasets { name status lastRun { id } derivations(limit: 1) { decision run { id } } }
+		incidents { class evidence job { alias } run { status } }
+	}`)
+	require.Empty(t, result.Errors)
+	var data struct {
+		Datasets []struct {
+			Name        string
+			Status      string
+			LastRun     struct{ ID string }
+			Derivations []struct {
+				Decision string
+				Run      struct{ ID string }
+			}
+		}
+		Incidents []struct {
+			Class    string
+			Evidence map[string]int
+			Job      struct{ Alias string }
+			Run      struct{ Status string }
+		}
+	}
+	decode(t, result, &data)
+	require.Len(t, data.Datasets, 1)
+	require.Equal(t, runID.String(), data.Datasets[0].LastRun.ID)
+	require.Len(t, data.Datasets[0].Derivations, 1)
+	require.Equal(t, "derived", data.Datasets[0].Derivations[0].Decision)
+	require.Len(t, data.Incidents, 1)
+	require.Equal(t, 137, data.Incidents[0].Evidence["exit_code"])
+	require.Equal(t, "extract", data.Incidents[0].Job.Alias)
+	require.Equal(t, "running", data.Incidents[0].Run.Status)
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	return db
+}
+
+func execute(t *testing.T, db *gorm.DB, principal *auth.Principal, query string, vars ...string) *graphql.Result {
+	t.Helper()
+	s, err := graphql.NewSchema(New())
+	require.NoError(t, err)
+	variables := map[string]interface{}{}
+	for i := 0; i+1 < len(vars); i += 2 {
+		variables[vars[i]] = vars[i+1]
+	}
+	return graphql.Do(graphql.Params{
+		Schema:         s,
+		RequestString:  query,
+		VariableValues: variables,
+		Context:        WithRequest(context.Background(), db, principal),
+	})
+}
+
+func decode(t *testing.T, result *graphql.Result, out any) {
+	t.Helper()
+	raw, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, out))
+}
+
+// countQueries counts the SELECTs db runs from here on.
+func countQueries(t *testing.T, db *gorm.DB) *int {
+	t.Helper()
+	count := new(int)
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count", func(*gorm.DB) { *count++ }))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:count_rows", func(*gorm.DB) { *count++ }))
+	return count
+}
+
+func createJob(t *testing.T, db *gorm.DB, namespace, alias string, tasks ...string) *models.Job {
+	t.Helper()
+	now := time.Now().UTC()
+	trigger := &models.Trigger{
+		ID:            uuid.New(),
+		Namespace:     namespace,
+		Alias:         alias + "-trigger",
+		Type:          models.TriggerTypeCron,
+		Configuration: `{"cron":"0 * * * *","timezone":"UTC"}`,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, db.Create(trigger).Error)
+	job := &models.Job{ID: uuid.New(), Namespace: namespace, Alias: alias, TriggerID: trigger.ID, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(job).Error)
+	for i, name := range tasks {
+		atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(atom).Error)
+		require.NoError(t, db.Create(&models.Task{ID: uuid.New(), JobID: job.ID, AtomID: atom.ID, Name: name, Position: i, CreatedAt: now, UpdatedAt: now}).Error)
+	}
+	return job
+}
+
+// createRun starts a run of job with every task registered; the job's second
+// task, if any, is a cache hit with output.
+func createRun(t *testing.T, db *gorm.DB, job *models.Job, output map[string]string) uuid.UUID {
+	t.Helper()
+	store := runstorage.NewStore(db)
+	run, err := store.Start(job.ID, nil)
+	require.NoError(t, err)
+
+	var tasks []models.Task
+	require.NoError(t, db.Where("job_id = ?", job.ID).Order("position").Find(&tasks).Error)
+	for i := range tasks {
+		var atom models.Atom
+		require.NoError(t, db.First(&atom, "id = ?", tasks[i].AtomID).Error)
+		require.NoError(t, store.RegisterTask(run.ID, &tasks[i], &atom, 0))
+	}
+	if len(tasks) > 1 {
+		raw, err := json.Marshal(output)
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.TaskRun{}).
+			Where("job_run_id = ? AND task_id = ?", run.ID, tasks[1].ID).
+			Updates(map[string]any{"cache_hit": true, "status": "succeeded", "output": datatypes.JSON(raw)}).Error)
+	}
+	return run.ID
+}
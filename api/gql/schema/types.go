@@ -0,0 +1,437 @@
+package schema
+
+import (
+	"encoding/json"
+
+	datasetsvc "github.com/caesium-cloud/caesium/api/rest/service/dataset"
+	"github.com/caesium-cloud/caesium/internal/lineage"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"gorm.io/datatypes"
+)
+
+const (
+	defaultRunsLimit        = 10
+	defaultDerivationsLimit = 20
+)
+
+// jsonType carries free-form values such as labels, params, and task
+// outputs as plain JSON.
+var jsonType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value.",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case datatypes.JSON:
+			if len(v) == 0 {
+				return nil
+			}
+			return json.RawMessage(v)
+		case json.RawMessage:
+			if len(v) == 0 {
+				return nil
+			}
+			return v
+		default:
+			return v
+		}
+	},
+})
+
+// types holds the schema's object types. They refer to one another, so each
+// declares its fields in a thunk that runs once every type exists.
+type types struct {
+	job        *graphql.Object
+	trigger    *graphql.Object
+	task       *graphql.Object
+	run        *graphql.Object
+	taskRun    *graphql.Object
+	dataset    *graphql.Object
+	derivation *graphql.Object
+	impact     *graphql.Object
+	impactNode *graphql.Object
+	incident   *graphql.Object
+}
+
+func newTypes() *types {
+	t := &types{}
+	t.job = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Job",
+		Description: "A job definition.",
+		Fields:      graphql.FieldsThunk(t.jobFields),
+	})
+	t.trigger = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Trigger",
+		Description: "What starts a job's runs.",
+		Fields: graphql.Fields{
+			"id":               {Type: graphql.NewNonNull(graphql.ID)},
+			"namespace":        {Type: graphql.NewNonNull(graphql.String)},
+			"alias":            {Type: graphql.NewNonNull(graphql.String)},
+			"type":             {Type: graphql.NewNonNull(graphql.String)},
+			"configuration":    {Type: graphql.NewNonNull(graphql.String), Description: "The trigger's configuration as a JSON document."},
+			"provenanceRepo":   {Type: graphql.String},
+			"provenanceCommit": {Type: graphql.String},
+			"createdAt":        {Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt":        {Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+	t.task = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Task",
+		Description: "One step of a job.",
+		Fields: graphql.Fields{
+			"id":           {Type: graphql.NewNonNull(graphql.ID)},
+			"jobId":        {Type: graphql.NewNonNull(graphql.ID)},
+			"name":         {Type: graphql.NewNonNull(graphql.String)},
+			"type":         {Type: graphql.NewNonNull(graphql.String)},
+			"retries":      {Type: graphql.NewNonNull(graphql.Int)},
+			"triggerRule":  {Type: graphql.NewNonNull(graphql.String)},
+			"nodeSelector": {Type: jsonType},
+			"nodeAffinity": {Type: jsonType},
+		},
+	})
+	t.run = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "JobRun",
+		Description: "A single execution of a job.",
+		Fields:      graphql.FieldsThunk(t.runFields),
+	})
+	t.taskRun = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TaskRun",
+		Description: "A task's execution within a job run.",
+		Fields:      graphql.FieldsThunk(t.taskRunFields),
+	})
+	t.dataset = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Dataset",
+		Description: "A dataset's freshness state.",
+		Fields:      graphql.FieldsThunk(t.datasetFields),
+	})
+	t.derivation = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "DatasetDerivation",
+		Description: "A recorded decision to derive a dataset, or to skip deriving it.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                 {Type: graphql.NewNonNull(graphql.ID)},
+				"namespace":          {Type: graphql.String},
+				"name":               {Type: graphql.NewNonNull(graphql.String)},
+				"decision":           {Type: graphql.NewNonNull(graphql.String)},
+				"reason":             {Type: graphql.NewNonNull(graphql.String)},
+				"consumedWatermarks": {Type: jsonType},
+				"runId":              {Type: graphql.ID},
+				"run": {
+					Type: t.run,
+					Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+						return r.loadRun(p.Source.(models.DatasetDerivation).RunID), nil
+					}),
+				},
+				"createdAt": {Type: graphql.NewNonNull(graphql.DateTime)},
+			}
+		}),
+	})
+	t.impactNode = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "LineageImpactNode",
+		Description: "A dataset downstream of the impact root, and the job step that produces it.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"datasetNamespace": {Type: graphql.NewNonNull(graphql.String)},
+				"datasetName":      {Type: graphql.NewNonNull(graphql.String)},
+				"direction":        {Type: graphql.NewNonNull(graphql.String)},
+				"producingStep":    {Type: graphql.String},
+				"jobId":            {Type: graphql.NewNonNull(graphql.ID)},
+				"jobAlias":         {Type: graphql.NewNonNull(graphql.String)},
+				"job": {
+					Type: t.job,
+					Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+						return r.loadJob(p.Source.(lineage.ImpactNode).JobID), nil
+					}),
+				},
+				"provenanceCommit": {Type: graphql.String},
+				"provenanceRepo":   {Type: graphql.String},
+				"lastSeen":         {Type: graphql.NewNonNull(graphql.DateTime)},
+				"depth":            {Type: graphql.NewNonNull(graphql.Int)},
+			}
+		}),
+	})
+	t.impact = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "LineageImpact",
+		Description: "The datasets transitively downstream of a root dataset, breadth first.",
+		Fields: graphql.Fields{
+			"rootNamespace": {Type: graphql.NewNonNull(graphql.String)},
+			"rootName":      {Type: graphql.NewNonNull(graphql.String)},
+			"downstream":    {Type: nonNullList(t.impactNode)},
+		},
+	})
+	t.incident = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Incident",
+		Description: "A classified task failure and its remediation state.",
+		Fields:      graphql.FieldsThunk(t.incidentFields),
+	})
+	return t
+}
+
+func (t *types) jobFields() graphql.Fields {
+	return graphql.Fields{
+		"id":               {Type: graphql.NewNonNull(graphql.ID)},
+		"namespace":        {Type: graphql.NewNonNull(graphql.String)},
+		"alias":            {Type: graphql.NewNonNull(graphql.String)},
+		"triggerId":        {Type: graphql.NewNonNull(graphql.ID)},
+		"labels":           {Type: jsonType},
+		"annotations":      {Type: jsonType},
+		"paused":           {Type: graphql.NewNonNull(graphql.Boolean)},
+		"provenanceRepo":   {Type: graphql.String},
+		"provenanceCommit": {Type: graphql.String},
+		"createdAt":        {Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt":        {Type: graphql.NewNonNull(graphql.DateTime)},
+		"trigger": {
+			Type: t.trigger,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return thunk(r.loaders.triggers.load(p.Source.(*models.Job).TriggerID)), nil
+			}),
+		},
+		"tasks": {
+			Type: nonNullList(t.task),
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return listThunk(r.loaders.jobTasks.load(p.Source.(*models.Job).ID)), nil
+			}),
+		},
+		"runs": {
+			Type:        nonNullList(t.run),
+			Description: "The job's latest runs, newest first.",
+			Args: graphql.FieldConfigArgument{
+				"limit": {Type: graphql.Int, DefaultValue: defaultRunsLimit},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				limit, _ := p.Args["limit"].(int)
+				if limit <= 0 {
+					limit = defaultRunsLimit
+				}
+				job := p.Source.(*models.Job)
+				get := r.loaders.recent(limit).load(job.ID)
+				return func() (interface{}, error) {
+					runs, _, err := get()
+					for _, run := range runs {
+						run.JobAlias, run.Namespace = job.Alias, job.Namespace
+					}
+					return runs, err
+				}, nil
+			}),
+		},
+		"latestRun": {
+			Type: t.run,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				job := p.Source.(*models.Job)
+				get := r.loaders.recent(1).load(job.ID)
+				return func() (interface{}, error) {
+					runs, _, err := get()
+					if err != nil || len(runs) == 0 {
+						return nil, err
+					}
+					runs[0].JobAlias, runs[0].Namespace = job.Alias, job.Namespace
+					return runs[0], nil
+				}, nil
+			}),
+		},
+	}
+}
+
+func (t *types) runFields() graphql.Fields {
+	return graphql.Fields{
+		"id":           {Type: graphql.NewNonNull(graphql.ID)},
+		"jobId":        {Type: graphql.NewNonNull(graphql.ID)},
+		"jobAlias":     {Type: graphql.String},
+		"namespace":    {Type: graphql.String},
+		"backfillId":   {Type: graphql.ID},
+		"triggerType":  {Type: graphql.String},
+		"triggerAlias": {Type: graphql.String},
+		"status":       {Type: graphql.NewNonNull(graphql.String)},
+		"priority":     {Type: graphql.NewNonNull(graphql.Int)},
+		"params":       {Type: jsonType},
+		"quarantine":   {Type: graphql.NewNonNull(graphql.Boolean)},
+		"startedAt":    {Type: graphql.NewNonNull(graphql.DateTime)},
+		"completedAt":  {Type: graphql.DateTime},
+		"error":        {Type: graphql.String},
+		"cacheHits": {
+			Type:        graphql.NewNonNull(graphql.Int),
+			Description: "How many of the run's tasks were served from the cache.",
+		},
+		"executedTasks": {Type: graphql.NewNonNull(graphql.Int)},
+		"totalTasks":    {Type: graphql.NewNonNull(graphql.Int)},
+		"job": {
+			Type: t.job,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return r.loadJob(p.Source.(*runstorage.JobRun).JobID), nil
+			}),
+		},
+		"taskRuns": {
+			Type: nonNullList(t.taskRun),
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				run := p.Source.(*runstorage.JobRun)
+				if run.Tasks != nil {
+					return run.Tasks, nil
+				}
+				// Runs listed from a job carry task counts but not task runs.
+				get := r.loaders.runs.load(run.ID)
+				return func() (interface{}, error) {
+					loaded, ok, err := get()
+					if err != nil || !ok {
+						return []*runstorage.TaskRun{}, err
+					}
+					return loaded.Tasks, nil
+				}, nil
+			}),
+		},
+	}
+}
+
+func (t *types) taskRunFields() graphql.Fields {
+	return graphql.Fields{
+		"taskId":      {Type: graphql.NewNonNull(graphql.ID)},
+		"jobRunId":    {Type: graphql.NewNonNull(graphql.ID)},
+		"status":      {Type: graphql.NewNonNull(graphql.String)},
+		"engine":      {Type: graphql.NewNonNull(graphql.String)},
+		"image":       {Type: graphql.NewNonNull(graphql.String)},
+		"attempt":     {Type: graphql.NewNonNull(graphql.Int)},
+		"maxAttempts": {Type: graphql.NewNonNull(graphql.Int)},
+		"claimedBy":   {Type: graphql.String},
+		"result":      {Type: graphql.String},
+		"output": {
+			Type:        jsonType,
+			Description: "The key-value output the task emitted.",
+		},
+		"cacheHit": {
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "Whether the task's result was reused from an earlier run.",
+		},
+		"cacheOriginRunId": {
+			Type:        graphql.ID,
+			Description: "The run whose result a cache hit reused.",
+		},
+		"startedAt":   {Type: graphql.DateTime},
+		"completedAt": {Type: graphql.DateTime},
+		"error":       {Type: graphql.String},
+		"task": {
+			Type: t.task,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return thunk(r.loaders.tasks.load(p.Source.(*runstorage.TaskRun).TaskID)), nil
+			}),
+		},
+	}
+}
+
+func (t *types) datasetFields() graphql.Fields {
+	return graphql.Fields{
+		"namespace":          {Type: graphql.NewNonNull(graphql.String)},
+		"name":               {Type: graphql.NewNonNull(graphql.String)},
+		"watermark":          {Type: graphql.NewNonNull(graphql.String)},
+		"status":             {Type: graphql.NewNonNull(graphql.String)},
+		"reason":             {Type: graphql.String},
+		"advancedAt":         {Type: graphql.DateTime},
+		"verifiedAt":         {Type: graphql.DateTime},
+		"consumedWatermarks": {Type: jsonType},
+		"lastRunId":          {Type: graphql.ID},
+		"lastRun": {
+			Type: t.run,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return r.loadRun(datasetState(p.Source).LastRunID), nil
+			}),
+		},
+		"derivations": {
+			Type:        nonNullList(t.derivation),
+			Description: "The dataset's latest derivation decisions, newest first.",
+			Args: graphql.FieldConfigArgument{
+				"limit": {Type: graphql.Int, DefaultValue: defaultDerivationsLimit},
+			},
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				limit, _ := p.Args["limit"].(int)
+				state := datasetState(p.Source)
+				key := datasetsvc.Key{Namespace: state.Namespace, Name: state.Name}
+				return listThunk(r.loaders.recentDerivations(limit).load(key)), nil
+			}),
+		},
+		"createdAt": {Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt": {Type: graphql.NewNonNull(graphql.DateTime)},
+	}
+}
+
+func (t *types) incidentFields() graphql.Fields {
+	return graphql.Fields{
+		"id":        {Type: graphql.NewNonNull(graphql.ID)},
+		"namespace": {Type: graphql.String},
+		"jobId":     {Type: graphql.NewNonNull(graphql.ID)},
+		"job": {
+			Type: t.job,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return r.loadJob(incident(p.Source).JobID), nil
+			}),
+		},
+		"runId": {Type: graphql.ID},
+		"run": {
+			Type: t.run,
+			Resolve: resolve(func(r *request, p graphql.ResolveParams) (interface{}, error) {
+				return r.loadRun(incident(p.Source).RunID), nil
+			}),
+		},
+		"taskId":            {Type: graphql.ID},
+		"taskName":          {Type: graphql.String},
+		"class":             {Type: graphql.NewNonNull(graphql.String)},
+		"status":            {Type: graphql.NewNonNull(graphql.String)},
+		"occurrenceCount":   {Type: graphql.NewNonNull(graphql.Int)},
+		"attempt":           {Type: graphql.NewNonNull(graphql.Int)},
+		"backfillId":        {Type: graphql.ID},
+		"lastError":         {Type: graphql.String},
+		"resolutionSummary": {Type: graphql.String},
+		"evidence":          {Type: jsonType},
+		"openedAt":          {Type: graphql.NewNonNull(graphql.DateTime)},
+		"closedAt":          {Type: graphql.DateTime},
+	}
+}
+
+// loadJob returns a thunk for the job with id, or null once it is gone.
+func (r *request) loadJob(id uuid.UUID) func() (interface{}, error) {
+	return thunk(r.loaders.jobs.load(id))
+}
+
+// loadRun returns a thunk for the run with id, or nil when id is unset.
+func (r *request) loadRun(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return thunk(r.loaders.runs.load(*id))
+}
+
+// thunk adapts a batched read to graphql-go's thunk resolver, resolving a
+// missing value to null.
+func thunk[V any](get func() (V, bool, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		v, ok, err := get()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return v, nil
+	}
+}
+
+// listThunk adapts a batched read of a list, resolving a missing value to an
+// empty list.
+func listThunk[V any](get func() (V, bool, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		v, _, err := get()
+		return v, err
+	}
+}
+
+// datasetState returns the state a Dataset resolves from; the list query
+// yields values and the single lookup a pointer.
+func datasetState(source interface{}) *models.DatasetState {
+	if state, ok := source.(models.DatasetState); ok {
+		return &state
+	}
+	return source.(*models.DatasetState)
+}
+
+func incident(source interface{}) *models.Incident {
+	if inc, ok := source.(models.Incident); ok {
+		return &inc
+	}
+	return source.(*models.Incident)
+}
@@ -26,7 +26,8 @@ var namedParamPattern = regexp.MustCompile(`:[^/]+`)
 
 // skipPaths lists exact paths that never require authentication.
 var skipPaths = map[string]bool{
-	"/health": true,
+	"/health":                           true,
+	"/.well-known/caesium-receipt-keys": true,
 }
 
 var publicAuthPaths = map[string]bool{
@@ -366,6 +367,8 @@ func logSuccessfulAction(
 		entry.ResourceType = "cache"
 	case auth.ActionCacheDelete:
 		entry.ResourceType = "cache"
+	case auth.ActionDBBackup:
+		entry.ResourceType = "database"
 	}
 
 	logAuditFailure(auditor.Log(entry))
@@ -389,9 +392,17 @@ func auditActionForRoute(method, routePath string) string {
 		return auth.ActionRunQueueCancel
 	case "POST /v1/jobs/:id/runs/:id/retry":
 		return auth.ActionRunRetry
+	case "POST /v1/jobs/:id/runs/:id/cancel":
+		return auth.ActionRunCancel
+	case "POST /v1/jobs/:id/runs/:id/tasks/:id/approve":
+		return auth.ActionTaskApprove
+	case "POST /v1/jobs/:id/runs/:id/tasks/:id/reject":
+		return auth.ActionTaskReject
 	case "POST /v1/jobs/:id/backfill":
 		return auth.ActionBackfill
-	case "PUT /v1/jobs/:id/backfills/:id/cancel":
+	case "PUT /v1/jobs/:id/backfills/:id/cancel",
+		"PUT /v1/jobs/:id/backfills/:id/pause",
+		"PUT /v1/jobs/:id/backfills/:id/resume":
 		return auth.ActionBackfill
 	case "POST /v1/jobdefs/apply":
 		return auth.ActionJobdefApply
@@ -401,6 +412,8 @@ func auditActionForRoute(method, routePath string) string {
 		return auth.ActionCacheDelete
 	case "DELETE /v1/jobs/:id/cache/:id":
 		return auth.ActionCacheDelete
+	case "POST /v1/admin/backup":
+		return auth.ActionDBBackup
 	default:
 		return ""
 	}
@@ -112,6 +112,7 @@ func TestAgentTokenDeniedOnNonAgentRoutes(t *testing.T) {
 		{"jobs list", http.MethodGet, "/v1/jobs"},
 		{"stats", http.MethodGet, "/v1/stats"},
 		{"lineage impact", http.MethodGet, "/v1/lineage/impact"},
+		{"lineage upstream", http.MethodGet, "/v1/lineage/upstream"},
 		{"events", http.MethodGet, "/v1/events"},
 	}
 	for _, tc := range cases {
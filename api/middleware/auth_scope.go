@@ -17,11 +17,24 @@ import (
 // ContextKeyAllowedJobAliases stores the scoped job aliases available to list endpoints.
 const ContextKeyAllowedJobAliases = "auth.allowed_job_aliases"
 
+// ContextKeyAllowedNamespaces stores the scoped namespaces available to list endpoints.
+const ContextKeyAllowedNamespaces = "auth.allowed_namespaces"
+
 // LineageImpactScopedDenyMessage is the 403 reason returned to a scoped principal
 // on the global, cross-job /v1/lineage/impact route. Exported so the auth and
 // integration tests assert against the single source of truth.
 const LineageImpactScopedDenyMessage = "lineage impact is a global cross-job query and requires an unscoped principal"
 
+// LineageScopedDenyMessage is the 403 reason returned to a scoped principal
+// on the global, cross-job /v1/lineage/upstream and /v1/lineage/column-impact
+// routes.
+const LineageScopedDenyMessage = "lineage provenance is a global cross-job query and requires an unscoped principal"
+
+// LineageIngestScopedDenyMessage is the 403 reason returned to a scoped
+// principal posting an OpenLineage event to /v1/lineage whose parent run
+// facet does not name a Caesium run.
+const LineageIngestScopedDenyMessage = "lineage ingestion requires a parent run facet naming an in-scope run for scoped principals"
+
 // ContractsGraphScopedDenyMessage is the 403 reason returned to a scoped
 // principal on the global, cross-job /v1/contracts/graph route.
 const ContractsGraphScopedDenyMessage = "contracts graph is a global cross-job query and requires an unscoped principal"
@@ -64,15 +77,25 @@ type scopeAuditContext struct {
 
 // GetAllowedJobAliases returns the scoped aliases injected by the auth middleware.
 func GetAllowedJobAliases(c *echo.Context) []string {
-	v := c.Get(ContextKeyAllowedJobAliases)
+	return getAllowedNames(c, ContextKeyAllowedJobAliases)
+}
+
+// GetAllowedNamespaces returns the scoped namespaces injected by the auth
+// middleware, or nil when the principal may see every namespace.
+func GetAllowedNamespaces(c *echo.Context) []string {
+	return getAllowedNames(c, ContextKeyAllowedNamespaces)
+}
+
+func getAllowedNames(c *echo.Context, key string) []string {
+	v := c.Get(key)
 	if v == nil {
 		return nil
 	}
-	aliases, ok := v.([]string)
+	names, ok := v.([]string)
 	if !ok {
 		return nil
 	}
-	return append([]string(nil), aliases...)
+	return append([]string(nil), names...)
 }
 
 func authorizeScope(c *echo.Context, svc *auth.Service, scopeJSON []byte, routePath string) (*scopeAuditContext, error) {
@@ -101,11 +124,11 @@ func authorizeScope(c *echo.Context, svc *auth.Service, scopeJSON []byte, routeP
 		return authorizeAgentScope(c, agentClaim, routePath)
 	}
 
-	scopeJobs, err := auth.ScopeJobs(scopeJSON)
+	scope, err := auth.DecodeScope(scopeJSON)
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 	}
-	if len(scopeJobs) == 0 {
+	if scope == nil {
 		return &scopeAuditContext{}, nil
 	}
 
@@ -115,38 +138,84 @@ func authorizeScope(c *echo.Context, svc *auth.Service, scopeJSON []byte, routeP
 	case "/v1/jobs":
 		switch c.Request().Method {
 		case http.MethodGet:
-			c.Set(ContextKeyAllowedJobAliases, append([]string(nil), scopeJobs...))
+			if len(scope.Jobs) > 0 {
+				c.Set(ContextKeyAllowedJobAliases, append([]string(nil), scope.Jobs...))
+			}
+			if len(scope.Namespaces) > 0 {
+				c.Set(ContextKeyAllowedNamespaces, append([]string(nil), scope.Namespaces...))
+			}
 			return state, nil
 		case http.MethodPost:
-			aliases, err := parseJobAliasesForScope(c)
+			job, err := parseJobForScope(c)
 			if err != nil {
 				return nil, echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
 			}
-			if len(aliases) != 1 || !auth.CheckScope(scopeJSON, aliases[0]) {
+			if !auth.CheckJobScope(scopeJSON, job) {
 				return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 			}
-			state.jobAliases = aliases
+			state.jobAliases = []string{job.Alias}
 			return state, nil
 		}
 	case "/v1/jobdefs/apply":
-		aliases, prune, err := parseApplyAliasesForScope(c)
+		jobs, prune, err := parseApplyJobsForScope(c)
 		if err != nil {
 			return nil, echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
 		}
 		if prune {
 			return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 		}
-		for _, alias := range aliases {
-			if !auth.CheckScope(scopeJSON, alias) {
+		for _, job := range jobs {
+			if !auth.CheckJobScope(scopeJSON, job) {
 				return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 			}
+			state.jobAliases = append(state.jobAliases, job.Alias)
 		}
-		state.jobAliases = aliases
+		return state, nil
+	case "/v1/triggers":
+		// Triggers carry their job's namespace but no job alias, so only a
+		// principal scoped purely by namespace may list them.
+		if c.Request().Method == http.MethodGet && len(scope.Jobs) == 0 {
+			c.Set(ContextKeyAllowedNamespaces, append([]string(nil), scope.Namespaces...))
+			return state, nil
+		}
+	case "/gql":
+		// GraphQL resolvers apply the scope to every field they resolve.
 		return state, nil
 	case "/v1/lineage/impact":
 		if c.Request().Method == http.MethodGet {
 			return nil, echo.NewHTTPError(http.StatusForbidden, LineageImpactScopedDenyMessage)
 		}
+	case "/v1/lineage/upstream", "/v1/lineage/column-impact":
+		if c.Request().Method == http.MethodGet {
+			return nil, echo.NewHTTPError(http.StatusForbidden, LineageScopedDenyMessage)
+		}
+	case "/v1/lineage":
+		if c.Request().Method == http.MethodPost {
+			// A scoped key may report lineage only for its own jobs' runs, so
+			// the parent run facet must resolve to an in-scope job.
+			parentRunID, err := parseLineageParentForScope(c)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+			}
+			if parentRunID == uuid.Nil {
+				return nil, echo.NewHTTPError(http.StatusForbidden, LineageIngestScopedDenyMessage)
+			}
+			job, err := svc.ScopedJobByTaskRunID(c.Request().Context(), parentRunID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				job, err = svc.ScopedJobByRunID(c.Request().Context(), parentRunID)
+			}
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, echo.NewHTTPError(http.StatusForbidden, LineageIngestScopedDenyMessage)
+				}
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+			}
+			if !auth.CheckJobScope(scopeJSON, job) {
+				return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
+			}
+			state.jobAliases = []string{job.Alias}
+			return state, nil
+		}
 	case "/v1/contracts/graph":
 		if c.Request().Method == http.MethodGet {
 			return nil, echo.NewHTTPError(http.StatusForbidden, ContractsGraphScopedDenyMessage)
@@ -161,33 +230,33 @@ func authorizeScope(c *echo.Context, svc *auth.Service, scopeJSON []byte, routeP
 			if err != nil {
 				return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid run_id")
 			}
-			jobAlias, err := svc.JobAliasByRunID(c.Request().Context(), runID)
+			job, err := svc.ScopedJobByRunID(c.Request().Context(), runID)
 			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					return nil, echo.ErrNotFound
 				}
 				return nil, echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 			}
-			if !auth.CheckScope(scopeJSON, jobAlias) {
+			if !auth.CheckJobScope(scopeJSON, job) {
 				return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 			}
-			state.jobAliases = []string{jobAlias}
+			state.jobAliases = []string{job.Alias}
 			return state, nil
 		}
 	}
 
 	if strings.HasPrefix(routePath, "/v1/jobs/:id") {
-		jobAlias, err := resolveScopedJobAlias(c, svc, routePath)
+		job, err := resolveScopedJob(c, svc, routePath)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, echo.ErrNotFound
 			}
 			return nil, echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 		}
-		if !auth.CheckScope(scopeJSON, jobAlias) {
+		if !auth.CheckJobScope(scopeJSON, job) {
 			return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 		}
-		state.jobAliases = []string{jobAlias}
+		state.jobAliases = []string{job.Alias}
 		return state, nil
 	}
 
@@ -234,56 +303,58 @@ func authorizeAgentScope(c *echo.Context, claim *auth.AgentClaimView, routePath
 	return &scopeAuditContext{jobAliases: allowed}, nil
 }
 
-func resolveScopedJobAlias(c *echo.Context, svc *auth.Service, routePath string) (string, error) {
+func resolveScopedJob(c *echo.Context, svc *auth.Service, routePath string) (auth.ScopedJob, error) {
 	ctx := c.Request().Context()
 
 	switch {
 	case strings.Contains(routePath, "/runs/:id/"):
 		runID, err := uuid.Parse(c.Param("run_id"))
 		if err != nil {
-			return "", err
+			return auth.ScopedJob{}, err
 		}
-		return svc.JobAliasByRunID(ctx, runID)
+		return svc.ScopedJobByRunID(ctx, runID)
 	case strings.Contains(routePath, "/runs/:id"):
 		runID, err := uuid.Parse(c.Param("run_id"))
 		if err != nil {
-			return "", err
+			return auth.ScopedJob{}, err
 		}
-		return svc.JobAliasByRunID(ctx, runID)
+		return svc.ScopedJobByRunID(ctx, runID)
 	case strings.Contains(routePath, "/backfills/:id"):
 		backfillID, err := uuid.Parse(c.Param("backfill_id"))
 		if err != nil {
-			return "", err
+			return auth.ScopedJob{}, err
 		}
-		return svc.JobAliasByBackfillID(ctx, backfillID)
+		return svc.ScopedJobByBackfillID(ctx, backfillID)
 	default:
 		jobID, err := uuid.Parse(c.Param("id"))
 		if err != nil {
-			return "", err
+			return auth.ScopedJob{}, err
 		}
-		return svc.JobAliasByID(ctx, jobID)
+		return svc.ScopedJobByID(ctx, jobID)
 	}
 }
 
-func parseJobAliasesForScope(c *echo.Context) ([]string, error) {
+func parseJobForScope(c *echo.Context) (auth.ScopedJob, error) {
 	var payload struct {
-		Alias string `json:"alias"`
+		Alias     string `json:"alias"`
+		Namespace string `json:"namespace"`
 	}
 	if err := decodeScopedBody(c, &payload); err != nil {
-		return nil, err
+		return auth.ScopedJob{}, err
 	}
 	alias := strings.TrimSpace(payload.Alias)
 	if alias == "" {
-		return nil, errors.New("alias is required")
+		return auth.ScopedJob{}, errors.New("alias is required")
 	}
-	return []string{alias}, nil
+	return auth.ScopedJob{Namespace: strings.TrimSpace(payload.Namespace), Alias: alias}, nil
 }
 
-func parseApplyAliasesForScope(c *echo.Context) ([]string, bool, error) {
+func parseApplyJobsForScope(c *echo.Context) ([]auth.ScopedJob, bool, error) {
 	var payload struct {
 		Definitions []struct {
 			Metadata struct {
-				Alias string `json:"alias"`
+				Alias     string `json:"alias"`
+				Namespace string `json:"namespace"`
 			} `json:"metadata"`
 		} `json:"definitions"`
 		Prune bool `json:"prune"`
@@ -292,15 +363,38 @@ func parseApplyAliasesForScope(c *echo.Context) ([]string, bool, error) {
 		return nil, false, err
 	}
 
-	aliases := make([]string, 0, len(payload.Definitions))
+	jobs := make([]auth.ScopedJob, 0, len(payload.Definitions))
 	for _, def := range payload.Definitions {
 		alias := strings.TrimSpace(def.Metadata.Alias)
 		if alias == "" {
 			return nil, false, errors.New("definition metadata.alias is required")
 		}
-		aliases = append(aliases, alias)
+		jobs = append(jobs, auth.ScopedJob{Namespace: strings.TrimSpace(def.Metadata.Namespace), Alias: alias})
+	}
+	return jobs, payload.Prune, nil
+}
+
+// parseLineageParentForScope reads run.facets.parent.run.runId from an
+// OpenLineage event. uuid.Nil means the event has no parent run.
+func parseLineageParentForScope(c *echo.Context) (uuid.UUID, error) {
+	var payload struct {
+		Run struct {
+			Facets struct {
+				Parent *struct {
+					Run struct {
+						RunID uuid.UUID `json:"runId"`
+					} `json:"run"`
+				} `json:"parent"`
+			} `json:"facets"`
+		} `json:"run"`
+	}
+	if err := decodeScopedBody(c, &payload); err != nil {
+		return uuid.Nil, err
+	}
+	if payload.Run.Facets.Parent == nil {
+		return uuid.Nil, nil
 	}
-	return aliases, payload.Prune, nil
+	return payload.Run.Facets.Parent.Run.RunID, nil
 }
 
 func decodeScopedBody(c *echo.Context, target interface{}) error {
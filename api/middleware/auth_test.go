@@ -918,6 +918,97 @@ func TestMiddlewareLineageImpactRejectsScopedViewerWithSpecificMessage(t *testin
 	require.Equal(t, authmw.LineageImpactScopedDenyMessage, he.Message)
 }
 
+func TestMiddlewareLineageProvenanceRejectsScopedViewer(t *testing.T) {
+	_, svc, auditor, limiter, _ := setupAuth(t)
+	key := createKey(t, svc, models.RoleViewer, &models.KeyScope{Jobs: []string{"alpha"}})
+
+	for _, path := range []string{"/v1/lineage/upstream", "/v1/lineage/column-impact"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer "+key)
+			_, err := callMiddleware(
+				t,
+				svc,
+				auditor,
+				limiter,
+				req,
+				&echo.RouteInfo{Path: path, Method: http.MethodGet},
+				nil,
+				nil,
+			)
+			require.Error(t, err)
+
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			require.Equal(t, http.StatusForbidden, he.Code)
+			require.Equal(t, authmw.LineageScopedDenyMessage, he.Message)
+		})
+	}
+}
+
+func TestMiddlewareLineageIngestRequiresInScopeParentRun(t *testing.T) {
+	db, svc, auditor, limiter, _ := setupAuth(t)
+	alphaJobID, alphaRunID, _ := seedJobFixtures(t, db, "alpha")
+	_, betaRunID, _ := seedJobFixtures(t, db, "beta")
+	key := createKey(t, svc, models.RoleRunner, &models.KeyScope{Jobs: []string{"alpha"}})
+
+	atomID, taskID, taskRunID := uuid.New(), uuid.New(), uuid.New()
+	require.NoError(t, db.Create(&models.Atom{ID: atomID, Engine: models.AtomEngineDocker, Image: "alpine:3.23"}).Error)
+	require.NoError(t, db.Create(&models.Task{ID: taskID, JobID: alphaJobID, AtomID: atomID, Name: "transform"}).Error)
+	require.NoError(t, db.Create(&models.TaskRun{
+		ID: taskRunID, JobRunID: alphaRunID, TaskID: taskID, AtomID: atomID,
+		Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: "[]", Status: "running",
+	}).Error)
+
+	event := func(parent string) string {
+		facets := "{}"
+		if parent != "" {
+			facets = `{"parent":{"run":{"runId":"` + parent + `"},"job":{"namespace":"caesium","name":"transform"}}}`
+		}
+		return `{"eventType":"COMPLETE","run":{"runId":"` + uuid.NewString() + `","facets":` + facets + `},"job":{"namespace":"dbt","name":"model"}}`
+	}
+
+	cases := []struct {
+		name    string
+		body    string
+		code    int
+		message string
+	}{
+		{"in-scope job run", event(alphaRunID.String()), http.StatusOK, ""},
+		{"in-scope task run", event(taskRunID.String()), http.StatusOK, ""},
+		{"out-of-scope run", event(betaRunID.String()), http.StatusForbidden, "insufficient permissions"},
+		{"unknown run", event(uuid.NewString()), http.StatusForbidden, authmw.LineageIngestScopedDenyMessage},
+		{"no parent", event(""), http.StatusForbidden, authmw.LineageIngestScopedDenyMessage},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/lineage", strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer "+key)
+			rec, err := callMiddleware(
+				t,
+				svc,
+				auditor,
+				limiter,
+				req,
+				&echo.RouteInfo{Path: "/v1/lineage", Method: http.MethodPost},
+				nil,
+				nil,
+			)
+			if tc.code == http.StatusOK {
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+			require.Error(t, err)
+
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			require.Equal(t, tc.code, he.Code)
+			require.Equal(t, tc.message, he.Message)
+		})
+	}
+}
+
 func TestMiddlewareContractsGraphAllowsUnscopedViewer(t *testing.T) {
 	_, svc, auditor, limiter, _ := setupAuth(t)
 	key := createKey(t, svc, models.RoleViewer, nil)
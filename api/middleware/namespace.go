@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// NamespaceHeader selects the namespace a request is scoped to. The
+// namespace query parameter is equivalent.
+const NamespaceHeader = "X-Caesium-Namespace"
+
+// RequestNamespace returns the namespace the request selected through
+// NamespaceHeader or the namespace query parameter, or "" when it selected
+// none.
+func RequestNamespace(c *echo.Context) string {
+	if namespace := strings.TrimSpace(c.Request().Header.Get(NamespaceHeader)); namespace != "" {
+		return namespace
+	}
+	return strings.TrimSpace(c.QueryParam("namespace"))
+}
+
+// NamespaceFilter returns the namespaces a list endpoint should return: the
+// one the request selected, else those the principal is scoped to, else nil
+// for every namespace. Selecting a namespace outside the principal's scope is
+// forbidden.
+func NamespaceFilter(c *echo.Context) ([]string, error) {
+	allowed := GetAllowedNamespaces(c)
+	requested := RequestNamespace(c)
+	if requested == "" {
+		return allowed, nil
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, requested) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
+	}
+	return []string{requested}, nil
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceFilter(t *testing.T) {
+	newContext := func(target string, allowed []string) *echo.Context {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, target, nil), httptest.NewRecorder())
+		if allowed != nil {
+			c.Set(ContextKeyAllowedNamespaces, allowed)
+		}
+		return c
+	}
+
+	got, err := NamespaceFilter(newContext("/v1/jobs", nil))
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	got, err = NamespaceFilter(newContext("/v1/jobs?namespace=team-a", nil))
+	require.NoError(t, err)
+	require.Equal(t, []string{"team-a"}, got)
+
+	c := newContext("/v1/jobs?namespace=team-a", []string{"team-b", "team-c"})
+	c.Request().Header.Set(NamespaceHeader, "team-b")
+	got, err = NamespaceFilter(c)
+	require.NoError(t, err)
+	require.Equal(t, []string{"team-b"}, got, "the header wins over the query parameter")
+
+	got, err = NamespaceFilter(newContext("/v1/jobs", []string{"team-b", "team-c"}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"team-b", "team-c"}, got)
+
+	_, err = NamespaceFilter(newContext("/v1/jobs?namespace=team-a", []string{"team-b"}))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusForbidden, httpErr.Code)
+}
@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	authmw "github.com/caesium-cloud/caesium/api/middleware"
+	"github.com/caesium-cloud/caesium/internal/tracing"
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContinuesCallerTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var handlerTraceparent string
+	handler := authmw.Trace("webhook.receive")(func(c *echo.Context) error {
+		handlerTraceparent = tracing.Traceparent(c.Request().Context())
+		return c.NoContent(http.StatusAccepted)
+	})
+
+	c := newMiddlewareContext(http.MethodPost, "/v1/hooks/deploy", map[string]string{"traceparent": traceparent})
+	require.NoError(t, handler(c))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "webhook.receive", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Equal(t, tracing.Traceparent(trace.ContextWithSpanContext(context.Background(), span.SpanContext())), handlerTraceparent)
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusAccepted))
+	require.Equal(t, codes.Unset, span.Status().Code)
+
+	failing := authmw.Trace("webhook.receive")(func(*echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error")
+	})
+	require.Error(t, failing(newMiddlewareContext(http.MethodPost, "/v1/hooks/deploy", nil)))
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Contains(t, spans[1].Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -2,6 +2,7 @@ package bind
 
 import (
 	authmw "github.com/caesium-cloud/caesium/api/middleware"
+	"github.com/caesium-cloud/caesium/api/rest/controller/admin"
 	agentctrl "github.com/caesium-cloud/caesium/api/rest/controller/agent"
 	agentprofilectrl "github.com/caesium-cloud/caesium/api/rest/controller/agentprofile"
 	"github.com/caesium-cloud/caesium/api/rest/controller/atom"
@@ -22,6 +23,7 @@ import (
 	"github.com/caesium-cloud/caesium/api/rest/controller/logs"
 	"github.com/caesium-cloud/caesium/api/rest/controller/node"
 	notifctrl "github.com/caesium-cloud/caesium/api/rest/controller/notification"
+	poolctrl "github.com/caesium-cloud/caesium/api/rest/controller/pool"
 	receiptctrl "github.com/caesium-cloud/caesium/api/rest/controller/receipt"
 	replayctrl "github.com/caesium-cloud/caesium/api/rest/controller/replay"
 	reproducectrl "github.com/caesium-cloud/caesium/api/rest/controller/reproduce"
@@ -96,6 +98,9 @@ func Protected(g *echo.Group, bus internal_event.Bus) {
 		g.POST("/jobs/:id/runs/:run_id/callbacks/retry", run.RetryCallbacks)
 		g.POST("/jobs/:id/runs/:run_id/replay", replayctrl.Post)
 		g.POST("/jobs/:id/runs/:run_id/retry", run.Retry)
+		g.POST("/jobs/:id/runs/:run_id/cancel", run.Cancel)
+		g.POST("/jobs/:id/runs/:run_id/tasks/:task/approve", run.ApproveTask)
+		g.POST("/jobs/:id/runs/:run_id/tasks/:task/reject", run.RejectTask)
 		g.POST("/jobs/:id/run", run.Post)
 		g.POST("/jobs", job.Post)
 		g.PUT("/jobs/:id/pause", job.Pause)
@@ -112,6 +117,7 @@ func Protected(g *echo.Group, bus internal_event.Bus) {
 		// reproducibility receipt + verify (data-plane-memory A4)
 		g.GET("/jobs/:id/runs/:run_id/receipt", receiptctrl.Get)
 		g.POST("/jobs/:id/runs/:run_id/receipt/verify", receiptctrl.Verify)
+		g.GET("/jobs/:id/runs/:run_id/provenance", receiptctrl.Provenance)
 
 		// job cache management
 		g.GET("/jobs/:id/cache", jobcache.List)
@@ -123,6 +129,8 @@ func Protected(g *echo.Group, bus internal_event.Bus) {
 		g.GET("/jobs/:id/backfills", backfill.List)
 		g.GET("/jobs/:id/backfills/:backfill_id", backfill.Get)
 		g.PUT("/jobs/:id/backfills/:backfill_id/cancel", backfill.Cancel)
+		g.PUT("/jobs/:id/backfills/:backfill_id/pause", backfill.Pause)
+		g.PUT("/jobs/:id/backfills/:backfill_id/resume", backfill.Resume)
 	}
 
 	// global cache management
@@ -170,9 +178,19 @@ func Protected(g *echo.Group, bus internal_event.Bus) {
 	// system
 	{
 		g.GET("/system/nodes", system.Nodes)
+		g.PUT("/system/nodes/:address/cordon", system.Cordon)
+		g.PUT("/system/nodes/:address/drain", system.Drain)
+		g.PUT("/system/nodes/:address/uncordon", system.Uncordon)
 		g.GET("/system/features", system.Features)
 	}
 
+	// admin: online backups of every database, streamed as one archive, and
+	// hot-shard row counts.
+	{
+		g.POST("/admin/backup", admin.Backup)
+		g.GET("/admin/shards", admin.Shards)
+	}
+
 	// server logs
 	if env.Variables().LogConsoleEnabled {
 		g.GET("/logs/stream", logs.Stream)
@@ -214,14 +232,26 @@ func Protected(g *echo.Group, bus internal_event.Bus) {
 		g.DELETE("/agentprofiles/:id", agentprofilectrl.Delete)
 	}
 
+	// task pools: cluster-wide slot budgets referenced by step pool fields.
+	{
+		g.GET("/pools", poolctrl.List)
+		g.GET("/pools/:name", poolctrl.Get)
+		g.PUT("/pools/:name", poolctrl.Put)
+		g.DELETE("/pools/:name", poolctrl.Delete)
+	}
+
 	// nodes
 	{
 		g.GET("/nodes/:address/workers", node.Workers)
 	}
 
-	// lineage impact (data-plane-memory C2)
+	// lineage impact (data-plane-memory C2), upstream provenance,
+	// column-level impact, and OpenLineage ingestion from tools run by steps
 	{
+		g.POST("/lineage", lineagectrl.Ingest)
 		g.GET("/lineage/impact", lineagectrl.Impact)
+		g.GET("/lineage/upstream", lineagectrl.Upstream)
+		g.GET("/lineage/column-impact", lineagectrl.ColumnImpact)
 	}
 
 	// incidents — operator read API + tier-3 approval decisions
@@ -252,7 +282,7 @@ func Protected(g *echo.Group, bus internal_event.Bus) {
 var webhookHandlerFactory = webhook.ReceiveWith
 
 func bindWebhooks(g *echo.Group, auditor *auth.AuditLogger) {
-	g.POST("/hooks/*", webhookHandlerFactory(auditor))
+	g.POST("/hooks/*", webhookHandlerFactory(auditor), authmw.Trace("webhook.receive"))
 }
 
 func bindAuth(g *echo.Group, controller *authctrl.Controller) {
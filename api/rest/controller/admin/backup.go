@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/backup"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/labstack/echo/v5"
+)
+
+// Backup streams an online backup archive of every database. Nothing is sent
+// until every database has been dumped and checked, so a failure before then
+// is still reported as an HTTP error.
+func Backup(c *echo.Context) error {
+	w := &archiveWriter{c: c, name: "caesium-backup-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"}
+	manifest, err := backup.Take(c.Request().Context(), w)
+	if err != nil {
+		if w.started {
+			// The status line is already out; the client sees a truncated
+			// archive, which restore rejects.
+			log.Error("backup stream failed", "error", err)
+			return nil
+		}
+		if errors.Is(err, backup.ErrUnsupportedDatabase) {
+			return echo.NewHTTPError(http.StatusNotImplemented, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to take backup").Wrap(err)
+	}
+	log.Info("backup streamed", "databases", len(manifest.Databases), "schema_version", manifest.SchemaVersion)
+	return nil
+}
+
+// archiveWriter sends the response headers on the first write.
+type archiveWriter struct {
+	c       *echo.Context
+	name    string
+	started bool
+}
+
+func (w *archiveWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		header := w.c.Response().Header()
+		header.Set(echo.HeaderContentType, "application/gzip")
+		header.Set(echo.HeaderContentDisposition, `attachment; filename="`+w.name+`"`)
+		w.c.Response().WriteHeader(http.StatusOK)
+	}
+	return w.c.Response().Write(p)
+}
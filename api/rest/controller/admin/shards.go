@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/labstack/echo/v5"
+)
+
+// Shards reports the hot run table row counts of the catalog and every hot
+// shard.
+func Shards(c *echo.Context) error {
+	report, err := run.ReportShards(c.Request().Context(), db.DefaultRouter().ShardCount(), db.Databases())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to report shards").Wrap(err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/caesium-cloud/caesium/api/middleware"
 	"github.com/caesium-cloud/caesium/internal/auth"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/labstack/echo/v5"
 )
 
@@ -32,6 +33,13 @@ func (ctrl *Controller) CreateKey(c *echo.Context) error {
 	if !models.ValidRole(req.Role) {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid role: %s (must be admin, operator, runner, or viewer)", req.Role))
 	}
+	if req.Scope != nil {
+		for _, namespace := range req.Scope.Namespaces {
+			if !jobdef.ValidNamespace(namespace) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid scope namespace: %q", namespace))
+			}
+		}
+	}
 
 	var expiresAt *time.Time
 	if req.ExpiresIn != "" {
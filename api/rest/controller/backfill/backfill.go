@@ -1,38 +1,55 @@
 package backfill
 
 import (
-	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"sync"
 	"time"
 
 	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
 	tsvc "github.com/caesium-cloud/caesium/api/rest/service/trigger"
 	backfillstore "github.com/caesium-cloud/caesium/internal/backfill"
-	internalJob "github.com/caesium-cloud/caesium/internal/job"
+	"github.com/caesium-cloud/caesium/internal/backfill/reconcile"
 	"github.com/caesium-cloud/caesium/internal/models"
 	croncfg "github.com/caesium-cloud/caesium/internal/trigger/cron"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 )
 
-// PostRequest is the body for POST /v1/jobs/:id/backfill.
+// PostRequest is the body for POST /v1/jobs/:id/backfill. A backfill covers
+// either the cron fire times in [Start, End) or an explicit list of Dates.
 type PostRequest struct {
 	Start         time.Time `json:"start"`
 	End           time.Time `json:"end"`
 	MaxConcurrent int       `json:"max_concurrent,omitempty"`
 	Reprocess     string    `json:"reprocess,omitempty"`
+
+	Dates       []time.Time                  `json:"dates,omitempty"`
+	Order       string                       `json:"order,omitempty"`
+	BatchSize   int                          `json:"batch_size,omitempty"`
+	Params      map[string]string            `json:"params,omitempty"`
+	DateParams  map[string]map[string]string `json:"date_params,omitempty"`
+	MaxFailures int                          `json:"max_failures,omitempty"`
+	// DryRun returns the planned runs without creating the backfill.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
-// cancelFuncs stores in-memory cancel functions for same-instance wakeups.
-// Cross-instance cancellation is coordinated through the backfill row in the
-// database so any replica can request cancellation safely.
-var (
-	cancelFuncsMu sync.Mutex
-	cancelFuncs   = make(map[uuid.UUID]context.CancelFunc)
-)
+// DryRunResponse lists the runs a backfill would launch, in launch order.
+type DryRunResponse struct {
+	Backfill *models.Backfill `json:"backfill"`
+	Runs     []DryRunRun      `json:"runs"`
+	// Skipped counts planned runs the reprocess policy skips.
+	Skipped int `json:"skipped"`
+}
+
+type DryRunRun struct {
+	LogicalDate time.Time         `json:"logical_date"`
+	Dates       []time.Time       `json:"dates"`
+	Params      map[string]string `json:"params"`
+}
 
 func Post(c *echo.Context) error {
 	ctx := c.Request().Context()
@@ -47,12 +64,9 @@ func Post(c *echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
 	}
 
-	if req.Start.IsZero() || req.End.IsZero() {
-		return echo.NewHTTPError(http.StatusBadRequest, "start and end are required")
-	}
-
-	if !req.End.After(req.Start) {
-		return echo.NewHTTPError(http.StatusBadRequest, "end must be after start")
+	b, err := newBackfill(jobID, &req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
 	j, err := jsvc.Service(ctx).Get(jobID)
@@ -79,57 +93,172 @@ func Post(c *echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, "backfill requires a cron trigger")
 	}
 
-	schedule, loc, err := croncfg.ParseSchedule(trigger.Configuration)
-	if err != nil {
+	if _, _, err := croncfg.ParseSchedule(trigger.Configuration); err != nil {
 		return echo.NewHTTPError(http.StatusUnprocessableEntity, "invalid cron expression in trigger").Wrap(err)
 	}
 
-	reprocess := req.Reprocess
-	if reprocess == "" {
-		reprocess = string(models.ReprocessNone)
+	plan, err := reconcile.NewPlan(b, j.Alias, trigger.Configuration)
+	if err != nil {
+		if errors.Is(err, reconcile.ErrInvalidPlan) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "invalid trigger configuration").Wrap(err)
 	}
-	switch models.ReprocessPolicy(reprocess) {
-	case models.ReprocessNone, models.ReprocessFailed, models.ReprocessAll:
-	default:
-		return echo.NewHTTPError(http.StatusBadRequest, "reprocess must be one of: none, failed, all")
+
+	if req.DryRun {
+		return dryRun(c, b, plan)
 	}
 
-	maxConcurrent := req.MaxConcurrent
-	if maxConcurrent <= 0 {
-		maxConcurrent = 1
+	// The leader's backfill reconciler picks the row up and launches its
+	// logical dates; this node keeps no state for it.
+	if err := backfillstore.Default().Create(b); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
 
+	return c.JSON(http.StatusAccepted, b)
+}
+
+// newBackfill validates req and builds the backfill it describes.
+func newBackfill(jobID uuid.UUID, req *PostRequest) (*models.Backfill, error) {
 	b := &models.Backfill{
 		ID:            uuid.New(),
 		JobID:         jobID,
 		Status:        string(models.BackfillStatusRunning),
-		Start:         req.Start.UTC(),
-		End:           req.End.UTC(),
-		MaxConcurrent: maxConcurrent,
-		Reprocess:     reprocess,
+		MaxConcurrent: req.MaxConcurrent,
+		Reprocess:     req.Reprocess,
+		Order:         req.Order,
+		BatchSize:     req.BatchSize,
+		MaxFailures:   req.MaxFailures,
 	}
 
-	if err := backfillstore.Default().Create(b); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	if len(req.Dates) > 0 {
+		if !req.Start.IsZero() || !req.End.IsZero() {
+			return nil, errors.New("dates cannot be combined with start and end")
+		}
+		dates := make([]string, len(req.Dates))
+		for i, d := range req.Dates {
+			dates[i] = d.UTC().Format(time.RFC3339)
+			if i == 0 || d.Before(b.Start) {
+				b.Start = d.UTC()
+			}
+			if i == 0 || d.After(b.End) {
+				b.End = d.UTC()
+			}
+		}
+		raw, err := json.Marshal(dates)
+		if err != nil {
+			return nil, err
+		}
+		b.Dates = raw
+	} else {
+		if req.Start.IsZero() || req.End.IsZero() {
+			return nil, errors.New("start and end are required")
+		}
+		if !req.End.After(req.Start) {
+			return nil, errors.New("end must be after start")
+		}
+		b.Start = req.Start.UTC()
+		b.End = req.End.UTC()
 	}
 
-	bCtx, cancel := context.WithCancel(context.Background())
+	if b.Reprocess == "" {
+		b.Reprocess = string(models.ReprocessNone)
+	}
+	switch models.ReprocessPolicy(b.Reprocess) {
+	case models.ReprocessNone, models.ReprocessFailed, models.ReprocessAll:
+	default:
+		return nil, errors.New("reprocess must be one of: none, failed, all")
+	}
+
+	if b.Order == "" {
+		b.Order = string(models.BackfillOrderOldestFirst)
+	}
+	switch models.BackfillOrder(b.Order) {
+	case models.BackfillOrderOldestFirst, models.BackfillOrderNewestFirst:
+	default:
+		return nil, errors.New("order must be one of: oldest_first, newest_first")
+	}
 
-	cancelFuncsMu.Lock()
-	cancelFuncs[b.ID] = cancel
-	cancelFuncsMu.Unlock()
+	if b.MaxConcurrent <= 0 {
+		b.MaxConcurrent = 1
+	}
+	if b.BatchSize < 0 {
+		return nil, errors.New("batch_size must not be negative")
+	}
+	if b.BatchSize == 0 {
+		b.BatchSize = 1
+	}
+	if b.MaxFailures < 0 {
+		return nil, errors.New("max_failures must not be negative")
+	}
 
-	go func() {
-		defer func() {
-			cancelFuncsMu.Lock()
-			delete(cancelFuncs, b.ID)
-			cancelFuncsMu.Unlock()
-			cancel()
-		}()
-		internalJob.RunBackfill(bCtx, b, j, schedule, loc)
-	}()
+	for key := range req.Params {
+		if reservedParam(key) {
+			return nil, fmt.Errorf("params cannot set %q", key)
+		}
+	}
+	if len(req.Params) > 0 {
+		raw, err := json.Marshal(req.Params)
+		if err != nil {
+			return nil, err
+		}
+		b.Params = raw
+	}
 
-	return c.JSON(http.StatusAccepted, b)
+	if len(req.DateParams) > 0 {
+		// Keys are normalized to UTC so they match the planned logical dates.
+		dateParams := make(map[string]map[string]string, len(req.DateParams))
+		for key, params := range req.DateParams {
+			d, err := time.Parse(time.RFC3339, key)
+			if err != nil {
+				return nil, fmt.Errorf("date_params key %q must be an RFC3339 logical date", key)
+			}
+			for name := range params {
+				if reservedParam(name) {
+					return nil, fmt.Errorf("date_params cannot set %q", name)
+				}
+			}
+			dateParams[d.UTC().Format(time.RFC3339)] = params
+		}
+		raw, err := json.Marshal(dateParams)
+		if err != nil {
+			return nil, err
+		}
+		b.DateParams = raw
+	}
+	return b, nil
+}
+
+// reservedParam reports whether key is a param the backfill sets per run.
+func reservedParam(key string) bool {
+	switch key {
+	case jobdefschema.ParamLogicalDate, jobdefschema.ParamDataIntervalStart, jobdefschema.ParamDataIntervalEnd:
+		return true
+	}
+	return false
+}
+
+func dryRun(c *echo.Context, b *models.Backfill, plan *reconcile.Plan) error {
+	kept, err := plan.Kept(backfillstore.Default(), b)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	resp := DryRunResponse{
+		Backfill: b,
+		Runs:     make([]DryRunRun, 0, len(kept)),
+		Skipped:  len(plan.Runs) - len(kept),
+	}
+	for _, run := range kept {
+		params, err := plan.Params(run)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		resp.Runs = append(resp.Runs, DryRunRun{LogicalDate: run.LogicalDate, Dates: run.Dates, Params: params})
+	}
+	b.TotalRuns = len(kept)
+
+	return c.JSON(http.StatusOK, resp)
 }
 
 func List(c *echo.Context) error {
@@ -186,20 +315,54 @@ func Cancel(c *echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
 
-	if b.Status != string(models.BackfillStatusRunning) {
+	switch models.BackfillStatus(b.Status) {
+	case models.BackfillStatusRunning, models.BackfillStatusPaused:
+	default:
 		return echo.NewHTTPError(http.StatusConflict, "backfill is not running")
 	}
 
-	cancelFuncsMu.Lock()
-	cancel, ok := cancelFuncs[backfillID]
-	cancelFuncsMu.Unlock()
-
 	if err := backfillstore.Default().RequestCancel(backfillID); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
 
-	if ok {
-		cancel()
+	updated, err := backfillstore.Default().Get(backfillID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// Pause stops a running backfill from launching further logical dates.
+// Runs already in flight finish normally.
+func Pause(c *echo.Context) error {
+	return transition(c, backfillstore.Default().Pause, "backfill is not running")
+}
+
+// Resume lets a paused backfill continue from its cursor.
+func Resume(c *echo.Context) error {
+	return transition(c, backfillstore.Default().Resume, "backfill is not paused")
+}
+
+func transition(c *echo.Context, apply func(uuid.UUID) (bool, error), conflict string) error {
+	backfillID, err := uuid.Parse(c.Param("backfill_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	if _, err := backfillstore.Default().Get(backfillID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	changed, err := apply(backfillID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+	if !changed {
+		return echo.NewHTTPError(http.StatusConflict, conflict)
 	}
 
 	updated, err := backfillstore.Default().Get(backfillID)
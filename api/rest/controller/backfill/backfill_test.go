@@ -0,0 +1,61 @@
+package backfill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackfillDefaults(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	b, err := newBackfill(uuid.New(), &PostRequest{Start: start, End: start.AddDate(0, 0, 7)})
+	require.NoError(t, err)
+	require.Equal(t, string(models.ReprocessNone), b.Reprocess)
+	require.Equal(t, string(models.BackfillOrderOldestFirst), b.Order)
+	require.Equal(t, 1, b.MaxConcurrent)
+	require.Equal(t, 1, b.BatchSize)
+	require.Empty(t, b.Dates)
+}
+
+func TestNewBackfillExplicitDates(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	b, err := newBackfill(uuid.New(), &PostRequest{
+		Dates: []time.Time{
+			time.Date(2026, 10, 9, 2, 0, 0, 0, loc),
+			time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		},
+		DateParams: map[string]map[string]string{"2026-10-09T02:00:00+02:00": {"region": "apac"}},
+		Order:      "newest_first",
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `["2026-10-09T00:00:00Z","2026-10-02T00:00:00Z"]`, string(b.Dates))
+	require.JSONEq(t, `{"2026-10-09T00:00:00Z":{"region":"apac"}}`, string(b.DateParams))
+	require.Equal(t, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), b.Start)
+	require.Equal(t, time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC), b.End)
+}
+
+func TestNewBackfillRejectsInvalidRequests(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	cases := map[string]PostRequest{
+		"missing window":      {},
+		"dates and window":    {Start: start, End: end, Dates: []time.Time{start}},
+		"unknown order":       {Start: start, End: end, Order: "random"},
+		"negative batch":      {Start: start, End: end, BatchSize: -1},
+		"negative failures":   {Start: start, End: end, MaxFailures: -1},
+		"reserved param":      {Start: start, End: end, Params: map[string]string{"logical_date": "x"}},
+		"bad date key":        {Start: start, End: end, DateParams: map[string]map[string]string{"yesterday": {}}},
+		"reserved date key":   {Start: start, End: end, DateParams: map[string]map[string]string{"2026-10-01T00:00:00Z": {"data_interval_end": "x"}}},
+		"unknown reprocess":   {Start: start, End: end, Reprocess: "some"},
+		"end not after start": {Start: end, End: start},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newBackfill(uuid.New(), &req)
+			require.Error(t, err)
+		})
+	}
+}
@@ -199,14 +199,14 @@ func (ctrl *Controller) authorizeRunQuarantineAccess(c *echo.Context, runID uuid
 	if svc == nil {
 		svc = iauth.NewService(db.Connection())
 	}
-	jobAlias, err := svc.JobAliasByRunID(c.Request().Context(), runID)
+	job, err := svc.ScopedJobByRunID(c.Request().Context(), runID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return echo.ErrNotFound
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
-	if !iauth.CheckScope(principal.Scope, jobAlias) {
+	if !iauth.CheckJobScope(principal.Scope, job) {
 		return echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
 	}
 	return nil
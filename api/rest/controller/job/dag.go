@@ -28,6 +28,9 @@ type DAGNode struct {
 	Successors   []uuid.UUID     `json:"successors"`
 	OutputSchema json.RawMessage `json:"output_schema,omitempty"`
 	InputSchema  json.RawMessage `json:"input_schema,omitempty"`
+	Map          json.RawMessage `json:"map,omitempty"`
+	Gate         json.RawMessage `json:"gate,omitempty"`
+	Sensor       json.RawMessage `json:"sensor,omitempty"`
 }
 
 type DAGEdge struct {
@@ -148,6 +151,15 @@ func DAG(c *echo.Context) error {
 		if len(t.InputSchema) > 0 {
 			node.InputSchema = json.RawMessage(t.InputSchema)
 		}
+		if len(t.MapConfig) > 0 {
+			node.Map = json.RawMessage(t.MapConfig)
+		}
+		if len(t.GateConfig) > 0 {
+			node.Gate = json.RawMessage(t.GateConfig)
+		}
+		if len(t.SensorConfig) > 0 {
+			node.Sensor = json.RawMessage(t.SensorConfig)
+		}
 		nodes = append(nodes, node)
 	}
 
@@ -26,8 +26,11 @@ func List(c *echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
 	}
-	if aliases := middleware.GetAllowedJobAliases(c); len(aliases) > 0 {
-		req.Aliases = aliases
+	if jobs := middleware.GetAllowedJobAliases(c); len(jobs) > 0 {
+		req.Jobs = jobs
+	}
+	if req.Namespaces, err = middleware.NamespaceFilter(c); err != nil {
+		return err
 	}
 
 	svc := job.Service(c.Request().Context())
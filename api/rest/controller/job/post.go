@@ -12,6 +12,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
@@ -27,6 +28,12 @@ func Post(c *echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(fmt.Errorf("trigger is required"))
 	}
 
+	req.Namespace = jobdef.NamespaceOrDefault(req.Namespace)
+	if !jobdef.ValidNamespace(req.Namespace) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid namespace: %q", req.Namespace))
+	}
+	req.Trigger.Namespace = req.Namespace
+
 	// Apply the whole job — trigger, job, atoms, tasks, edges — in one
 	// transaction so it commits atomically in a single dqlite/Raft round-trip
 	// (instead of ~6-8 autocommit writes) and never leaves a partial job behind
@@ -51,6 +58,7 @@ func Post(c *echo.Context) error {
 
 		j, err := job.Service(ctx).WithDatabase(tx).Create(&job.CreateRequest{
 			TriggerID:   trig.ID,
+			Namespace:   req.Namespace,
 			Alias:       req.Alias,
 			Labels:      metadataLabels(req.Metadata),
 			Annotations: metadataAnnotations(req.Metadata),
@@ -140,10 +148,13 @@ func metadataAnnotations(md *MetadataRequest) map[string]string {
 }
 
 type PostRequest struct {
-	Alias    string                 `json:"alias"`
-	Metadata *MetadataRequest       `json:"metadata,omitempty"`
-	Trigger  *trigger.CreateRequest `json:"trigger"`
-	Tasks    []TaskRequest          `json:"tasks"`
+	// Namespace defaults to "default"; the trigger is created in the same
+	// namespace.
+	Namespace string                 `json:"namespace,omitempty"`
+	Alias     string                 `json:"alias"`
+	Metadata  *MetadataRequest       `json:"metadata,omitempty"`
+	Trigger   *trigger.CreateRequest `json:"trigger"`
+	Tasks     []TaskRequest          `json:"tasks"`
 }
 
 type TaskRequest struct {
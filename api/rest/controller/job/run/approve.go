@@ -0,0 +1,129 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authmw "github.com/caesium-cloud/caesium/api/middleware"
+	runsvc "github.com/caesium-cloud/caesium/api/rest/service/run"
+	"github.com/caesium-cloud/caesium/internal/auth"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+	"gorm.io/gorm"
+)
+
+// gateDecisionBody is the optional JSON body for approve/reject: a free-text
+// reason recorded on the approval request and, for rejections, on the failed
+// task.
+type gateDecisionBody struct {
+	Reason string `json:"reason"`
+}
+
+// ApproveTask handles POST /v1/jobs/:id/runs/:run_id/tasks/:task/approve. The
+// waiting executor notices the decision on its next poll and runs the step.
+func ApproveTask(c *echo.Context) error {
+	return decideTask(c, models.ApprovalDecisionApproved)
+}
+
+// RejectTask handles POST /v1/jobs/:id/runs/:run_id/tasks/:task/reject. The
+// step fails with the rejection as its error.
+func RejectTask(c *echo.Context) error {
+	return decideTask(c, models.ApprovalDecisionRejected)
+}
+
+func decideTask(c *echo.Context, decision models.ApprovalDecision) error {
+	ctx := c.Request().Context()
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+	taskID, err := uuid.Parse(c.Param("task"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	runEntry, err := runsvc.New(ctx).Get(runID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+	if runEntry.JobID != jobID {
+		return echo.ErrNotFound
+	}
+
+	store := runstorage.Default()
+	gate, err := store.TaskGate(runID, taskID)
+	if err != nil {
+		return mapGateError(err)
+	}
+
+	// The RBAC role gate already ran in the auth middleware; a gate that
+	// names approvers additionally requires an SSO user in one of them.
+	principal := authmw.GetPrincipal(c)
+	if !gateDecisionAllowed(principal, gate.Approvers) {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("approval restricted to members of %s", strings.Join(gate.Approvers, ", ")))
+	}
+
+	decided, err := store.DecideGate(runID, taskID, decision, gateDecider(principal), gateDecisionReason(c))
+	if err != nil {
+		return mapGateError(err)
+	}
+	return c.JSON(http.StatusOK, decided)
+}
+
+// gateDecisionAllowed reports whether principal may decide a gate that names
+// approvers. A request without a principal reaches the handler only when auth
+// is disabled; the server is then intentionally open and there is no identity
+// to check groups against, so the decision is allowed.
+func gateDecisionAllowed(principal *auth.Principal, approvers []string) bool {
+	if len(approvers) == 0 {
+		return true
+	}
+	if principal == nil {
+		vars := env.Variables()
+		return vars.AuthMode != "api-key" && !vars.SSOEnabled()
+	}
+	return principal.InAnyGroup(approvers)
+}
+
+func mapGateError(err error) error {
+	switch {
+	case errors.Is(err, runstorage.ErrGateNotFound):
+		return echo.ErrNotFound
+	case errors.Is(err, runstorage.ErrGateNotPending):
+		return echo.NewHTTPError(http.StatusConflict, "approval is not pending")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+}
+
+func gateDecisionReason(c *echo.Context) string {
+	if c.Request().Body == nil {
+		return ""
+	}
+	var body gateDecisionBody
+	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(body.Reason)
+}
+
+func gateDecider(principal *auth.Principal) string {
+	if principal != nil && strings.TrimSpace(principal.Subject) != "" {
+		return strings.TrimSpace(principal.Subject)
+	}
+	return "operator"
+}
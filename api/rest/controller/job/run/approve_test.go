@@ -0,0 +1,60 @@
+package run
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caesium-cloud/caesium/internal/auth"
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecideTaskReturnsBadRequestForInvalidTaskID verifies that an invalid
+// task UUID is rejected with 400 before any DB lookup occurs.
+func TestDecideTaskReturnsBadRequestForInvalidTaskID(t *testing.T) {
+	for name, handler := range map[string]echo.HandlerFunc{"approve": ApproveTask, "reject": RejectTask} {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(echo.PathValues{
+				{Name: "id", Value: uuid.NewString()},
+				{Name: "run_id", Value: uuid.NewString()},
+				{Name: "task", Value: "not-a-uuid"},
+			})
+
+			err := handler(c)
+			require.Error(t, err)
+
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			require.Equal(t, http.StatusBadRequest, he.Code)
+		})
+	}
+}
+
+// TestGateDecisionAllowed verifies that a gate naming approvers requires a
+// principal in one of them when auth is enabled, and can still be decided on
+// a deployment with auth disabled.
+func TestGateDecisionAllowed(t *testing.T) {
+	approvers := []string{"release-managers"}
+	member := &auth.Principal{Kind: auth.PrincipalUser, Groups: []string{"Release-Managers"}}
+	outsider := &auth.Principal{Kind: auth.PrincipalUser, Groups: []string{"finance"}}
+
+	t.Setenv("CAESIUM_AUTH_MODE", "none")
+	t.Setenv("CAESIUM_DATABASE_PATH", t.TempDir())
+	require.NoError(t, env.Process())
+	require.True(t, gateDecisionAllowed(nil, approvers), "auth disabled")
+	require.True(t, gateDecisionAllowed(nil, nil))
+
+	t.Setenv("CAESIUM_AUTH_MODE", "api-key")
+	require.NoError(t, env.Process())
+	require.False(t, gateDecisionAllowed(nil, approvers))
+	require.False(t, gateDecisionAllowed(outsider, approvers))
+	require.True(t, gateDecisionAllowed(member, approvers))
+	require.True(t, gateDecisionAllowed(outsider, nil))
+}
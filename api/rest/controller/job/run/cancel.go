@@ -0,0 +1,70 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authmw "github.com/caesium-cloud/caesium/api/middleware"
+	runsvc "github.com/caesium-cloud/caesium/api/rest/service/run"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+	"gorm.io/gorm"
+)
+
+// Cancel cancels a running run. Pending and in-flight tasks are marked
+// cancelled; the node holding each task claim notices the cancellation and
+// stops the atom through its engine.
+func Cancel(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	svc := runsvc.New(ctx)
+	runEntry, err := svc.Get(runID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	if runEntry.JobID != jobID {
+		return echo.ErrNotFound
+	}
+
+	if err := runstorage.Default().CancelRunWithReason(ctx, runID, cancelReason(c)); err != nil {
+		var notActive *runstorage.RunNotActiveError
+		if errors.As(err, &notActive) {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("run is %s", notActive.Status))
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	cancelled, err := svc.Get(runID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	return c.JSON(http.StatusAccepted, cancelled)
+}
+
+// cancelReason records who cancelled the run on the run and its tasks.
+func cancelReason(c *echo.Context) string {
+	if principal := authmw.GetPrincipal(c); principal != nil && strings.TrimSpace(principal.Subject) != "" {
+		return "cancelled by " + strings.TrimSpace(principal.Subject)
+	}
+	return "cancelled by operator"
+}
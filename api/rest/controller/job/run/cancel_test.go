@@ -0,0 +1,31 @@
+package run
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCancelRunReturnsBadRequestForInvalidRunID verifies that an invalid run
+// UUID is rejected with 400 before any DB lookup occurs.
+func TestCancelRunReturnsBadRequestForInvalidRunID(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPathValues(echo.PathValues{
+		{Name: "id", Value: uuid.NewString()},
+		{Name: "run_id", Value: "not-a-uuid"},
+	})
+
+	err := Cancel(c)
+	require.Error(t, err)
+
+	he, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusBadRequest, he.Code)
+}
@@ -3,14 +3,26 @@ package run
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
 	runsvc "github.com/caesium-cloud/caesium/api/rest/service/run"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/sla"
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 )
 
+// runResponse is the run payload plus its predicted completion, which is
+// only present while the run is in flight and enough task history exists.
+type runResponse struct {
+	*runstorage.JobRun
+	ETA *sla.Estimate `json:"eta,omitempty"`
+}
+
 func Get(c *echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -44,5 +56,16 @@ func Get(c *echo.Context) error {
 		return echo.ErrNotFound
 	}
 
-	return c.JSON(http.StatusOK, runEntry)
+	resp := runResponse{JobRun: runEntry}
+	if runEntry.Status == runstorage.StatusRunning {
+		predictor := sla.NewPredictor(runstorage.Default().DB(), env.Variables().SLAETAPercentile)
+		eta, err := predictor.Estimate(ctx, runEntry.JobID, runEntry.ID, time.Now().UTC())
+		if err != nil {
+			// The ETA is advisory; never fail the run lookup over it.
+			log.Warn("failed to estimate run completion", "run_id", runEntry.ID, "error", err)
+		}
+		resp.ETA = eta
+	}
+
+	return c.JSON(http.StatusOK, resp)
 }
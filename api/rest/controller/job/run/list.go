@@ -3,14 +3,23 @@ package run
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
 	runsvc "github.com/caesium-cloud/caesium/api/rest/service/run"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 )
 
+// Run lists return the newest defaultRunListLimit runs unless the caller asks
+// for a different page, and never more than maxRunListLimit at once.
+const (
+	defaultRunListLimit = 100
+	maxRunListLimit     = 1000
+)
+
 func List(c *echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -18,6 +27,10 @@ func List(c *echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
 	}
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
 	if _, err = jsvc.Service(ctx).Get(id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -27,10 +40,29 @@ func List(c *echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
 
-	runs, err := runsvc.New(ctx).List(id)
+	runs, err := runsvc.New(ctx).List(id, opts)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
 
 	return c.JSON(http.StatusOK, runs)
 }
+
+func parseListOptions(c *echo.Context) (runstorage.ListOptions, error) {
+	opts := runstorage.ListOptions{Limit: defaultRunListLimit}
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxRunListLimit {
+			return opts, errors.New("limit must be between 1 and " + strconv.Itoa(maxRunListLimit))
+		}
+		opts.Limit = limit
+	}
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return opts, errors.New("offset must be a non-negative integer")
+		}
+		opts.Offset = offset
+	}
+	return opts, nil
+}
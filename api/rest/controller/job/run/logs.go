@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+	"strconv"
+	"sync"
 	"time"
 
 	runsvc "github.com/caesium-cloud/caesium/api/rest/service/run"
@@ -14,6 +15,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/atom/docker"
 	"github.com/caesium-cloud/caesium/internal/atom/kubernetes"
 	"github.com/caesium-cloud/caesium/internal/atom/podman"
+	"github.com/caesium-cloud/caesium/internal/logarchive"
 	"github.com/caesium-cloud/caesium/internal/models"
 	runstorage "github.com/caesium-cloud/caesium/internal/run"
 	"github.com/caesium-cloud/caesium/pkg/log"
@@ -89,14 +91,51 @@ func Logs(c *echo.Context) error {
 		return echo.ErrNotFound
 	}
 
+	query, err := parseStoredLogQuery(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	// A mapped step's instances are archived one log each, by item index.
+	if indexParam := c.QueryParam("index"); indexParam != "" {
+		index, err := strconv.Atoi(indexParam)
+		if err != nil || index < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(fmt.Errorf("index must be a non-negative integer"))
+		}
+		ref := logarchive.InstanceRef(runID, taskID, index)
+		stored := openStoredLog(ctx, taskID, &runstorage.TaskLogSnapshot{ArchiveRef: ref})
+		if stored == nil {
+			return echo.ErrNotFound
+		}
+		return writeStoredLog(c, stored, query)
+	}
+
 	snapshot, err := runService.GetTaskLogSnapshot(runID, taskID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
 	}
 
+	// Byte ranges and grep are served from the stored log: the complete
+	// archive when the task has one, otherwise the capped snapshot. A finished
+	// task's archive is preferred over its runtime, which may be gone.
+	storedLog := sync.OnceValue(func() *storedLog {
+		return openStoredLog(ctx, taskID, snapshot)
+	})
+	if query.requested() {
+		if stored := storedLog(); stored != nil {
+			return writeStoredLog(c, stored, query)
+		}
+		return writeLogState(c, logStateForTask(taskEntry))
+	}
+	if taskEntry.CompletedAt != nil && snapshot != nil && snapshot.ArchiveRef != "" {
+		if stored := storedLog(); stored != nil && stored.source == logSourceArchive {
+			return writeStoredLog(c, stored, query)
+		}
+	}
+
 	if taskEntry.RuntimeID == "" {
-		if snapshot != nil {
-			return writeLogSnapshot(c, snapshot)
+		if stored := storedLog(); stored != nil {
+			return writeStoredLog(c, stored, query)
 		}
 		return writeLogState(c, logStateForTask(taskEntry))
 	}
@@ -108,8 +147,8 @@ func Logs(c *echo.Context) error {
 
 	reader, err := engine.Logs(&atom.EngineLogsRequest{ID: taskEntry.RuntimeID, Since: since})
 	if err != nil {
-		if snapshot != nil {
-			return writeLogSnapshot(c, snapshot)
+		if stored := storedLog(); stored != nil {
+			return writeStoredLog(c, stored, query)
 		}
 		if taskEntry.CompletedAt != nil || runEntry.CompletedAt != nil {
 			return writeLogState(c, "unavailable")
@@ -164,21 +203,6 @@ func Logs(c *echo.Context) error {
 	}
 }
 
-func writeLogSnapshot(c *echo.Context, snapshot *runstorage.TaskLogSnapshot) error {
-	res := c.Response()
-	res.Header().Set(echo.HeaderContentType, "text/plain; charset=utf-8")
-	res.Header().Set(logHeaderSource, "persisted")
-	if snapshot != nil && snapshot.Truncated {
-		res.Header().Set(logHeaderTruncated, "true")
-	}
-	res.WriteHeader(http.StatusOK)
-	if snapshot == nil || snapshot.Text == "" {
-		return nil
-	}
-	_, err := io.Copy(res, strings.NewReader(snapshot.Text))
-	return err
-}
-
 func writeLogState(c *echo.Context, state string) error {
 	c.Response().Header().Set(logHeaderState, state)
 	c.Response().WriteHeader(http.StatusNoContent)
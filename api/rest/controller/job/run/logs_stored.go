@@ -0,0 +1,221 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/internal/logarchive"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+)
+
+const (
+	logHeaderSize     = "X-Caesium-Log-Size"
+	logHeaderComplete = "X-Caesium-Log-Complete"
+
+	logSourceArchive   = "archive"
+	logSourcePersisted = "persisted"
+
+	defaultGrepMatches = 1000
+	maxGrepMatches     = 100000
+)
+
+// logArchive returns the archive task logs are read from; nil when
+// archiving is disabled.
+var logArchive = logarchive.Default
+
+// storedLog is a task log kept after its runtime: the complete archive or
+// the capped snapshot persisted on the task run.
+type storedLog struct {
+	source    string
+	size      int64
+	complete  bool
+	truncated bool
+	open      func(offset int64) (io.ReadCloser, error)
+}
+
+// openStoredLog returns the task's archived log when it has one, falling
+// back to its snapshot. It returns nil when neither holds any text.
+func openStoredLog(ctx context.Context, taskID uuid.UUID, snapshot *runstorage.TaskLogSnapshot) *storedLog {
+	if snapshot == nil {
+		return nil
+	}
+	if archive := logArchive(); archive != nil && snapshot.ArchiveRef != "" {
+		object, err := archive.Open(ctx, snapshot.ArchiveRef)
+		if err == nil {
+			return &storedLog{
+				source:   logSourceArchive,
+				size:     object.Size(),
+				complete: object.Complete(),
+				open: func(offset int64) (io.ReadCloser, error) {
+					return object.NewReader(ctx, offset)
+				},
+			}
+		}
+		if !errors.Is(err, logarchive.ErrNotFound) {
+			log.Warn("task log archive unreadable; serving the snapshot", "task_id", taskID, "ref", snapshot.ArchiveRef, "error", err)
+		}
+	}
+	if snapshot.Text == "" && !snapshot.Truncated {
+		return nil
+	}
+	text := snapshot.Text
+	return &storedLog{
+		source:    logSourcePersisted,
+		size:      int64(len(text)),
+		complete:  true,
+		truncated: snapshot.Truncated,
+		open: func(offset int64) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(text[offset:])), nil
+		},
+	}
+}
+
+// storedLogQuery selects part of a stored log: an HTTP byte range, or the
+// lines matching a grep pattern.
+type storedLogQuery struct {
+	rangeHeader string
+	grep        *regexp.Regexp
+	maxMatches  int
+}
+
+func parseStoredLogQuery(c *echo.Context) (storedLogQuery, error) {
+	query := storedLogQuery{
+		rangeHeader: strings.TrimSpace(c.Request().Header.Get("Range")),
+		maxMatches:  defaultGrepMatches,
+	}
+	if pattern := c.QueryParam("grep"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return query, fmt.Errorf("invalid grep pattern: %w", err)
+		}
+		query.grep = re
+	}
+	if raw := c.QueryParam("max_matches"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxGrepMatches {
+			return query, fmt.Errorf("max_matches must be between 1 and %d", maxGrepMatches)
+		}
+		query.maxMatches = n
+	}
+	return query, nil
+}
+
+func (q storedLogQuery) requested() bool {
+	return q.grep != nil || q.rangeHeader != ""
+}
+
+// writeStoredLog serves a stored log. grep returns matching lines prefixed
+// with their line number and applies to the whole log; otherwise a single
+// byte range is honoured with 206 Partial Content.
+func writeStoredLog(c *echo.Context, stored *storedLog, query storedLogQuery) error {
+	res := c.Response()
+	header := res.Header()
+	header.Set(echo.HeaderContentType, "text/plain; charset=utf-8")
+	header.Set(logHeaderSource, stored.source)
+	header.Set(logHeaderSize, strconv.FormatInt(stored.size, 10))
+	if !stored.complete {
+		header.Set(logHeaderComplete, "false")
+	}
+	if stored.truncated {
+		header.Set(logHeaderTruncated, "true")
+	}
+
+	if query.grep != nil {
+		reader, err := stored.open(0)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadGateway, "stored log unavailable").Wrap(err)
+		}
+		defer func() { _ = reader.Close() }()
+		res.WriteHeader(http.StatusOK)
+		_, err = logarchive.Grep(reader, res, query.grep, query.maxMatches)
+		return err
+	}
+
+	header.Set("Accept-Ranges", "bytes")
+	start, end, status := int64(0), stored.size-1, http.StatusOK
+	var ranged bool
+	if query.rangeHeader != "" {
+		var ok bool
+		start, end, ranged, ok = parseByteRange(query.rangeHeader, stored.size)
+		if !ok {
+			header.Set("Content-Range", "bytes */"+strconv.FormatInt(stored.size, 10))
+			res.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return nil
+		}
+	}
+	if ranged {
+		total := "*"
+		if stored.complete {
+			total = strconv.FormatInt(stored.size, 10)
+		}
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%s", start, end, total))
+		status = http.StatusPartialContent
+	}
+	length := end - start + 1
+	if length <= 0 {
+		res.WriteHeader(status)
+		return nil
+	}
+
+	reader, err := stored.open(start)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, "stored log unavailable").Wrap(err)
+	}
+	defer func() { _ = reader.Close() }()
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(length, 10))
+	res.WriteHeader(status)
+	_, err = io.CopyN(res, reader, length)
+	return err
+}
+
+// parseByteRange resolves a single "bytes=" range against a log of size
+// bytes. ranged is false when the header does not parse, in which case the
+// whole log is served as RFC 9110 requires; ok is false when a parsed range
+// cannot be satisfied.
+func parseByteRange(header string, size int64) (start, end int64, ranged, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, size - 1, false, true
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, size - 1, false, true
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, size - 1, false, true
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, true, false
+		}
+		return max(size-suffix, 0), size - 1, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, size - 1, false, true
+	}
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, size - 1, false, true
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, true, false
+	}
+	return start, end, true, true
+}
@@ -10,6 +10,7 @@ import (
 	contractenforce "github.com/caesium-cloud/caesium/internal/contract"
 	internaljobdef "github.com/caesium-cloud/caesium/internal/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/db"
+	schema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/labstack/echo/v5"
 )
 
@@ -49,6 +50,9 @@ func Apply(c *echo.Context) error {
 	if err := importer.ValidateBatch(ctx, req.Definitions); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if err := importer.ValidateImagePolicy(ctx, req.Definitions, applyActor(c)); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
 	for i := range req.Definitions {
 		def := &req.Definitions[i]
@@ -56,7 +60,7 @@ func Apply(c *echo.Context) error {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 
-		aliases = append(aliases, def.Metadata.Alias)
+		aliases = append(aliases, schema.QualifiedAlias(def.Metadata.Namespace, def.Metadata.Alias))
 		if _, err := importer.ApplyWithOptions(ctx, def, opts); err != nil {
 			if errors.Is(err, internaljobdef.ErrContractBreak) {
 				if payload, ok := internaljobdef.ContractBreakResponse(err); ok {
@@ -42,7 +42,8 @@ func Diff(c *echo.Context) error {
 		if err := def.Validate(); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
-		desired[def.Metadata.Alias] = jobdiff.FromDefinition(def)
+		spec := jobdiff.FromDefinition(def)
+		desired[spec.Key()] = spec
 	}
 
 	ctx := c.Request().Context()
@@ -62,9 +63,9 @@ func Diff(c *echo.Context) error {
 		contractsvc.RecordFindings(*graph)
 		findingsByAlias = make(map[string][]contractsvc.Finding, len(desired))
 		allFindings := contractsvc.FindingsFromGraph(*graph)
-		for alias := range desired {
-			if findings := contractsvc.FilterFindingsForAlias(allFindings, alias); len(findings) > 0 {
-				findingsByAlias[alias] = findings
+		for key, spec := range desired {
+			if findings := contractsvc.FilterFindingsForAlias(allFindings, spec.Alias); len(findings) > 0 {
+				findingsByAlias[key] = findings
 			}
 		}
 	}
@@ -86,7 +87,7 @@ func diffJobSpecs(specs []jobdiff.JobSpec, findingsByAlias map[string][]contract
 	for _, spec := range specs {
 		out = append(out, DiffJobSpec{
 			JobSpec:          spec,
-			ContractFindings: findingsByAlias[spec.Alias],
+			ContractFindings: findingsByAlias[spec.Key()],
 		})
 	}
 	return out
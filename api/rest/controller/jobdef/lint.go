@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	contractsvc "github.com/caesium-cloud/caesium/api/rest/service/contract"
+	"github.com/caesium-cloud/caesium/internal/imagepolicy"
 	internaljobdef "github.com/caesium-cloud/caesium/internal/jobdef"
+	"github.com/caesium-cloud/caesium/internal/placement"
 	"github.com/caesium-cloud/caesium/pkg/db"
 	schema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/labstack/echo/v5"
@@ -74,6 +76,32 @@ func Lint(c *echo.Context) error {
 		}
 	}
 
+	if len(resp.Errors) == 0 {
+		policy := imagepolicy.Default()
+		for _, d := range internaljobdef.CheckImagePolicy(c.Request().Context(), policy, req.Definitions) {
+			for _, v := range d.Violations {
+				msg := LintMessage{Message: fmt.Sprintf("%s: %s (image policy: %s)", v.Job, v.String(), v.Rule)}
+				if d.Denied {
+					resp.Errors = append(resp.Errors, msg)
+				} else {
+					resp.Warnings = append(resp.Warnings, msg)
+				}
+			}
+		}
+	}
+
+	if len(resp.Errors) == 0 {
+		if conn := db.Connection(); conn != nil {
+			warnings, err := placement.NewRegistry(conn).Warnings(c.Request().Context(), req.Definitions)
+			if err != nil {
+				warnings = []string{"Could not check node placement: " + err.Error()}
+			}
+			for _, warning := range warnings {
+				resp.Warnings = append(resp.Warnings, LintMessage{Message: warning})
+			}
+		}
+	}
+
 	if len(resp.Errors) == 0 {
 		if contractsvc.Enabled() {
 			graph, err := contractsvc.New(c.Request().Context()).Graph("", req.Definitions)
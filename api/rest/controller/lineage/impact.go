@@ -2,7 +2,6 @@ package lineage
 
 import (
 	"net/http"
-	"strconv"
 
 	lsvc "github.com/caesium-cloud/caesium/api/rest/service/lineage"
 	"github.com/labstack/echo/v5"
@@ -35,14 +34,9 @@ func Impact(c *echo.Context) error {
 
 	// maxDepth=0 is passed through to QueryImpact, which treats it as
 	// "use the server default of 10 hops."  Negative values are invalid.
-	maxDepth := 0
-	if raw := c.QueryParam("max_depth"); raw != "" {
-		d, err := strconv.Atoi(raw)
-		if err != nil || d < 0 {
-			return echo.NewHTTPError(http.StatusBadRequest,
-				"max_depth must be a non-negative integer (0 = server default)")
-		}
-		maxDepth = d
+	maxDepth, err := parseMaxDepth(c)
+	if err != nil {
+		return err
 	}
 
 	ctx := c.Request().Context()
@@ -0,0 +1,77 @@
+package lineage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	lsvc "github.com/caesium-cloud/caesium/api/rest/service/lineage"
+	freshnesspkg "github.com/caesium-cloud/caesium/internal/freshness"
+	illineage "github.com/caesium-cloud/caesium/internal/lineage"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/labstack/echo/v5"
+)
+
+// maxRunEventBytes bounds one OpenLineage event. Schema and column lineage
+// facets of wide tables are the bulk of a real event.
+const maxRunEventBytes = 1 << 20
+
+var observeLineageOutputs = func(ctx context.Context, obs freshnesspkg.LineageObservation) error {
+	_, err := freshnesspkg.DefaultArrivalObserver().ObserveLineage(ctx, obs)
+	return err
+}
+
+// Ingest handles POST /lineage
+//
+// It accepts an OpenLineage RunEvent from a tool running inside a step (dbt,
+// Spark, Airbyte, …). When the event's parent run facet names a Caesium run
+// — the step's task run, or CAESIUM_RUN_ID with the step name as the parent
+// job name — its inputs and outputs are merged into the dataset graph of
+// that task run. Outputs of a COMPLETE event also refresh dataset freshness.
+// An event that names no Caesium run is acknowledged with correlated=false
+// and not stored, so a tool's transport never retries it.
+//
+// Example:
+//
+//	OPENLINEAGE_URL=http://caesium:8080 OPENLINEAGE_ENDPOINT=v1/lineage \
+//	OPENLINEAGE_PARENT_ID="caesium/transform/$CAESIUM_RUN_ID" dbt-ol run
+func Ingest(c *echo.Context) error {
+	var evt illineage.RunEvent
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxRunEventBytes)
+	if err := json.NewDecoder(body).Decode(&evt); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "request body too large")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	ctx := c.Request().Context()
+	result, err := lsvc.New(ctx).Ingest(evt)
+	switch {
+	case errors.Is(err, illineage.ErrInvalidRunEvent):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	if result.Correlated && evt.EventType == illineage.EventTypeComplete && len(result.Outputs) > 0 {
+		names := make([]string, 0, len(result.Outputs))
+		for _, ds := range result.Outputs {
+			names = append(names, ds.Name)
+		}
+		// The datasets are already merged; a freshness failure is logged
+		// rather than failing an event the tool would then resend.
+		if err := observeLineageOutputs(ctx, freshnesspkg.LineageObservation{
+			JobID: *result.JobID,
+			RunID: *result.RunID,
+			Names: names,
+			At:    evt.EventTime,
+		}); err != nil {
+			log.Warn("lineage ingest freshness observer failed", "run_id", *result.RunID, "error", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
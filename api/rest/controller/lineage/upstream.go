@@ -0,0 +1,100 @@
+package lineage
+
+import (
+	"net/http"
+	"strconv"
+
+	lsvc "github.com/caesium-cloud/caesium/api/rest/service/lineage"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+)
+
+// Upstream handles GET /lineage/upstream?namespace=<ns>&name=<name>[&run_id=<uuid>][&field=<column>][&max_depth=N]
+//
+// It returns the provenance of a dataset version — the run that produced it,
+// the datasets that run read, and the runs that produced those versions —
+// i.e. "where did this data come from." Without run_id the latest production
+// is walked. With field, the declared column lineage upstream of that column
+// is included. max_depth follows /lineage/impact.
+//
+// Example:
+//
+//	GET /lineage/upstream?namespace=caesium&name=mart.revenue
+//	GET /lineage/upstream?namespace=caesium&name=mart.revenue&run_id=6f1c…&field=total
+func Upstream(c *echo.Context) error {
+	namespace := c.QueryParam("namespace")
+	name := c.QueryParam("name")
+
+	if namespace == "" || name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"namespace and name query parameters are required")
+	}
+
+	var runID *uuid.UUID
+	if raw := c.QueryParam("run_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "run_id must be a UUID")
+		}
+		runID = &id
+	}
+
+	maxDepth, err := parseMaxDepth(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := lsvc.New(c.Request().Context()).Upstream(namespace, name, c.QueryParam("field"), runID, maxDepth)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ColumnImpact handles GET /lineage/column-impact?name=<name>&field=<column>[&max_depth=N]
+//
+// It returns every column transitively derived from the named dataset column,
+// following the columnLineage declared on produced datasets — i.e. "what
+// breaks if this column changes." Column lineage is declared by dataset name,
+// so no namespace is taken. max_depth follows /lineage/impact.
+//
+// Example:
+//
+//	GET /lineage/column-impact?name=raw.orders&field=amount
+func ColumnImpact(c *echo.Context) error {
+	name := c.QueryParam("name")
+	field := c.QueryParam("field")
+
+	if name == "" || field == "" {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			"name and field query parameters are required")
+	}
+
+	maxDepth, err := parseMaxDepth(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := lsvc.New(c.Request().Context()).ColumnImpact(name, field, maxDepth)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// parseMaxDepth reads the optional max_depth query parameter. 0 (or omitted)
+// selects the server default; negative values are rejected with 400.
+func parseMaxDepth(c *echo.Context) (int, error) {
+	raw := c.QueryParam("max_depth")
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := strconv.Atoi(raw)
+	if err != nil || d < 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest,
+			"max_depth must be a non-negative integer (0 = server default)")
+	}
+	return d, nil
+}
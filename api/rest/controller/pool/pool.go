@@ -0,0 +1,115 @@
+// Package pool is the REST surface for cluster-wide task pools. Pools are
+// addressed by name, and every view carries live occupancy so operators can
+// see which pools are saturated.
+package pool
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	svc "github.com/caesium-cloud/caesium/api/rest/service/pool"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/labstack/echo/v5"
+	"gorm.io/gorm"
+)
+
+func List(c *echo.Context) error {
+	service := svc.New(c.Request().Context())
+	pools, err := service.List()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+	usages, err := service.Usages()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	views := make([]poolView, len(pools))
+	for i := range pools {
+		views[i] = toView(pools[i], usages[pools[i].Name])
+	}
+	return c.JSON(http.StatusOK, views)
+}
+
+func Get(c *echo.Context) error {
+	service := svc.New(c.Request().Context())
+	p, err := service.Get(c.Param("name"))
+	if err != nil {
+		return serviceError(err)
+	}
+	usages, err := service.Usages()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+	return c.JSON(http.StatusOK, toView(*p, usages[p.Name]))
+}
+
+func Put(c *echo.Context) error {
+	req := &svc.PutRequest{}
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	service := svc.New(c.Request().Context())
+	p, created, err := service.Put(c.Param("name"), req)
+	if err != nil {
+		return serviceError(err)
+	}
+	usages, err := service.Usages()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, toView(*p, usages[p.Name]))
+}
+
+func Delete(c *echo.Context) error {
+	if err := svc.New(c.Request().Context()).Delete(c.Param("name")); err != nil {
+		return serviceError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// poolView is the API response for a Pool with its current occupancy.
+type poolView struct {
+	Name          string    `json:"name"`
+	Slots         int       `json:"slots"`
+	Description   string    `json:"description,omitempty"`
+	OccupiedSlots int       `json:"occupied_slots"`
+	RunningTasks  int       `json:"running_tasks"`
+	QueuedTasks   int       `json:"queued_tasks"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func toView(p models.Pool, usage run.PoolUsage) poolView {
+	return poolView{
+		Name:          p.Name,
+		Slots:         p.Slots,
+		Description:   p.Description,
+		OccupiedSlots: usage.OccupiedSlots,
+		RunningTasks:  usage.RunningTasks,
+		QueuedTasks:   usage.QueuedTasks,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+func serviceError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return echo.ErrNotFound
+	case errors.Is(err, svc.ErrInvalidPool):
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+}
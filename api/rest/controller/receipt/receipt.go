@@ -1,6 +1,7 @@
 // Package receipt exposes the reproducibility-receipt REST endpoints: emit a
-// content-addressed receipt for a run, and verify a committed receipt against a
-// run's current persisted state (drift detection).
+// signed, content-addressed receipt for a run, verify a committed receipt
+// against a run's current persisted state (drift detection), export a run as
+// SLSA provenance, and publish the public keys receipts verify against.
 package receipt
 
 import (
@@ -19,7 +20,8 @@ import (
 // returns it as JSON — a small, content-addressed artifact intended to be
 // committed to git alongside the pipeline. The receipt's `degraded` flag is
 // honest: if any task ran on an unpinned, mutable image tag, the receipt cannot
-// attest reproducibility and says so.
+// attest reproducibility and says so. When the cluster has a receipt signing
+// key, the receipt carries its signature.
 func Get(c *echo.Context) error {
 	// Parse and validate BOTH path params up-front. The job ID must be a valid
 	// UUID so the ownership cross-check below cannot be silently bypassed by a
@@ -60,7 +62,8 @@ func Get(c *echo.Context) error {
 // drift report: whether the run still matches, and if not, every divergence
 // ("image tag mutated: digest mismatch", "manifest changed", …). A run whose
 // tasks were not digest-pinned is reported as degraded and never as a clean
-// match.
+// match. The result also reports whether the committed receipt's signature
+// verifies against the cluster's public keys; an invalid signature is drift.
 func Verify(c *echo.Context) error {
 	// Parse and validate BOTH path params up-front (see Get) so the ownership
 	// cross-check below cannot be bypassed by a malformed `id`.
@@ -99,3 +102,47 @@ func Verify(c *echo.Context) error {
 
 	return c.JSON(http.StatusOK, result)
 }
+
+// Provenance handles GET /jobs/:id/runs/:run_id/provenance.
+//
+// It renders the run as an in-toto statement with a SLSA v1 provenance
+// predicate — image digests, manifest hash, and git provenance as resolved
+// dependencies — and returns it as a DSSE envelope. The envelope's signatures
+// are empty when the cluster has no receipt signing key.
+func Provenance(c *echo.Context) error {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+	runID, err := uuid.Parse(c.Param("run_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+
+	ctx := c.Request().Context()
+
+	statement, envelope, err := rsvc.New(ctx).Provenance(runID)
+	if err != nil {
+		if errors.Is(err, ireceipt.ErrRunNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	// Same ownership cross-check as Get.
+	if statement.Predicate.BuildDefinition.ExternalParameters["job_id"] != jobID.String() {
+		return echo.ErrNotFound
+	}
+
+	return c.JSON(http.StatusOK, envelope)
+}
+
+// Keys handles GET /.well-known/caesium-receipt-keys.
+//
+// It publishes the cluster's receipt public key set — current and retired
+// signing keys — so `caesium verify` and third parties can check receipt and
+// provenance signatures offline. Public keys are not secret, so the route is
+// served without authentication.
+func Keys(c *echo.Context) error {
+	return c.JSON(http.StatusOK, rsvc.New(c.Request().Context()).PublicKeys())
+}
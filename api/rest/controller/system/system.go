@@ -1,9 +1,14 @@
 package system
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	authmw "github.com/caesium-cloud/caesium/api/middleware"
 	"github.com/caesium-cloud/caesium/api/rest/service/system"
+	"github.com/caesium-cloud/caesium/internal/cordon"
 	"github.com/labstack/echo/v5"
 )
 
@@ -26,3 +31,66 @@ func Features(c *echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, resp)
 }
+
+// Cordon stops a node from claiming tasks and from receiving dispatches.
+// Tasks it is already running keep running.
+func Cordon(c *echo.Context) error {
+	state, err := system.New(c.Request().Context()).Cordon(c.Param("address"), actor(c))
+	if err != nil {
+		return cordonError(err)
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+// DrainRequest is the optional body of PUT /v1/system/nodes/:address/drain.
+type DrainRequest struct {
+	// Timeout is how long the node waits for its running tasks before it
+	// releases their claims to other nodes, e.g. "10m". Empty waits for them
+	// to finish.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Drain cordons a node and hands its running tasks back once they finish or
+// the drain timeout passes.
+func Drain(c *echo.Context) error {
+	var req DrainRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
+	}
+	var timeout time.Duration
+	if raw := strings.TrimSpace(req.Timeout); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid timeout")
+		}
+		timeout = parsed
+	}
+
+	state, err := system.New(c.Request().Context()).Drain(c.Param("address"), actor(c), timeout)
+	if err != nil {
+		return cordonError(err)
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+// Uncordon makes a cordoned or draining node schedulable again.
+func Uncordon(c *echo.Context) error {
+	if err := system.New(c.Request().Context()).Uncordon(c.Param("address")); err != nil {
+		return cordonError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func actor(c *echo.Context) string {
+	if principal := authmw.GetPrincipal(c); principal != nil {
+		return strings.TrimSpace(principal.Subject)
+	}
+	return ""
+}
+
+func cordonError(err error) error {
+	if errors.Is(err, cordon.ErrAddressRequired) {
+		return echo.NewHTTPError(http.StatusBadRequest, "address is required")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").Wrap(err)
+}
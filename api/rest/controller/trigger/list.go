@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/caesium-cloud/caesium/api/middleware"
 	"github.com/caesium-cloud/caesium/api/rest/service/trigger"
 	"github.com/labstack/echo/v5"
 )
@@ -14,6 +15,9 @@ func List(c *echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "bad request").Wrap(err)
 	}
+	if req.Namespaces, err = middleware.NamespaceFilter(c); err != nil {
+		return err
+	}
 
 	triggers, err := trigger.Service(c.Request().Context()).List(req)
 	if err != nil {
@@ -24,7 +28,9 @@ func List(c *echo.Context) error {
 }
 
 func parseListRequest(c *echo.Context) (req *trigger.ListRequest, err error) {
-	req = &trigger.ListRequest{}
+	req = &trigger.ListRequest{
+		Type: c.QueryParam("type"),
+	}
 
 	if limit := c.QueryParam("limit"); limit != "" {
 		if req.Limit, err = strconv.ParseUint(limit, 10, 64); err != nil {
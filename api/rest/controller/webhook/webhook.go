@@ -223,6 +223,12 @@ func launchHTTPTriggerJobs(ctx context.Context, accepted acceptedHTTPTrigger, ru
 
 	log.Info("running jobs", "count", len(accepted.jobs), "trigger_id", accepted.trigger.ID)
 
+	params, err := accepted.httpTrigger.MergeParams(accepted.params)
+	if err != nil {
+		log.Error("failed to render webhook trigger params", "trigger_id", accepted.trigger.ID, "error", err)
+		return 0
+	}
+
 	started := 0
 	for _, j := range accepted.jobs {
 		if j == nil {
@@ -235,7 +241,7 @@ func launchHTTPTriggerJobs(ctx context.Context, accepted acceptedHTTPTrigger, ru
 		}
 
 		capturedJob := j
-		capturedParams := cloneStringMap(accepted.httpTrigger.MergeParams(accepted.params))
+		capturedParams := cloneStringMap(params)
 		started++
 		go func() {
 			runCtx := context.WithoutCancel(ctx)
@@ -14,12 +14,17 @@ import (
 )
 
 func seedJob(t *testing.T, db *gorm.DB, alias string) uuid.UUID {
+	t.Helper()
+	return seedNamespacedJob(t, db, "", alias)
+}
+
+func seedNamespacedJob(t *testing.T, db *gorm.DB, namespace, alias string) uuid.UUID {
 	t.Helper()
 	now := time.Now().UTC()
 	trigger := uuid.New()
-	require.NoError(t, db.Create(&models.Trigger{ID: trigger, Type: models.TriggerTypeCron, Configuration: `{"cron":"0 * * * *"}`, CreatedAt: now, UpdatedAt: now}).Error)
+	require.NoError(t, db.Create(&models.Trigger{ID: trigger, Namespace: namespace, Type: models.TriggerTypeCron, Configuration: `{"cron":"0 * * * *"}`, CreatedAt: now, UpdatedAt: now}).Error)
 	jobID := uuid.New()
-	require.NoError(t, db.Create(&models.Job{ID: jobID, Alias: alias, TriggerID: trigger, CreatedAt: now, UpdatedAt: now}).Error)
+	require.NoError(t, db.Create(&models.Job{ID: jobID, Namespace: namespace, Alias: alias, TriggerID: trigger, CreatedAt: now, UpdatedAt: now}).Error)
 	return jobID
 }
 
@@ -115,6 +120,34 @@ func TestHistoryEnforcesAllowlist(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestHistoryMatchesAllowlistOnNamespaceAndAlias(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	jobID := seedJob(t, db, "vendor-x")
+	seedNamespacedJob(t, db, "team-a", "report")
+	otherID := seedNamespacedJob(t, db, "team-b", "report")
+	inc := seedIncident(t, db, jobID)
+
+	now := time.Now().UTC()
+	runID := uuid.New()
+	require.NoError(t, db.Create(&models.JobRun{ID: runID, JobID: otherID, Status: "succeeded", StartedAt: now, CreatedAt: now, UpdatedAt: now}).Error)
+
+	svc := &Service{ctx: context.Background(), db: db}
+
+	// An allowlisted team-b/report does not open team-a/report or a bare
+	// "report", which names the default namespace.
+	_, err := svc.History(inc, "team-a/report", []string{"team-b/report"})
+	require.ErrorIs(t, err, ErrForbiddenJob)
+	_, err = svc.History(inc, "report", []string{"team-b/report"})
+	require.ErrorIs(t, err, ErrForbiddenJob)
+
+	runs, err := svc.History(inc, "team-b/report", []string{"team-b/report"})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, runID, runs[0].ID)
+}
+
 // TestHistoryEmptyAllowlistDeniesCrossJob is the security regression for the
 // "empty means unrestricted" footgun: an agent token whose frozen allowlist is
 // EMPTY may read only its own incident's job, never any other job's history.
@@ -8,6 +8,7 @@ import (
 	iincident "github.com/caesium-cloud/caesium/internal/incident"
 	"github.com/caesium-cloud/caesium/internal/models"
 	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -49,26 +50,29 @@ func (s *Service) FailingLog(inc *models.Incident) (string, bool, error) {
 	return iincident.NewScrubber(nil).Scrub(tr.LogText), true, nil
 }
 
-// History returns recent runs for the incident's own job, or — when jobAlias is
+// History returns recent runs for the incident's own job, or — when jobRef is
 // supplied — for that job PROVIDED it is within the incident's frozen allowlist.
-// A request for an out-of-allowlist job is refused (ErrForbiddenJob), which is
-// the read-scope boundary for agent tokens.
-func (s *Service) History(inc *models.Incident, jobAlias string, allowed []string) ([]RunSummary, error) {
+// jobRef and allowlist entries are "namespace/alias", or a bare alias in the
+// default namespace. A request for an out-of-allowlist job is refused
+// (ErrForbiddenJob), which is the read-scope boundary for agent tokens.
+func (s *Service) History(inc *models.Incident, jobRef string, allowed []string) ([]RunSummary, error) {
 	jobID := inc.JobID
-	if jobAlias != "" {
+	if jobRef != "" {
 		// The incident's own job is always readable; ANY other job must be
 		// explicitly listed in the frozen allowlist. An empty allowlist therefore
 		// permits ONLY the incident's own job — empty is never "allow any" (the
 		// same "empty means unrestricted" footgun closed in authorizeAgentScope).
-		ownAlias, err := s.incidentJobAlias(inc.JobID)
+		ownRef, err := s.incidentJobRef(inc.JobID)
 		if err != nil {
 			return nil, err
 		}
-		if jobAlias != ownAlias && !jobInAllowlist(jobAlias, allowed) {
+		namespace, alias := jobdef.SplitQualifiedAlias(jobRef)
+		ref := jobdef.QualifiedAlias(namespace, alias)
+		if ref != ownRef && !jobInAllowlist(ref, allowed) {
 			return nil, ErrForbiddenJob
 		}
 		var job models.Job
-		if err := s.db.WithContext(s.ctx).Select("id").First(&job, "alias = ?", jobAlias).Error; err != nil {
+		if err := s.db.WithContext(s.ctx).Select("id").First(&job, "namespace = ? AND alias = ?", namespace, alias).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, ErrForbiddenJob
 			}
@@ -105,23 +109,24 @@ func (s *Service) Why(inc *models.Incident, task string) (*runstorage.WhyExplana
 	return whysvc.New(s.ctx).WithDatabase(s.db).Why(*inc.RunID, task)
 }
 
-// incidentJobAlias resolves the alias of the incident's own job (always readable
-// through the context passthroughs regardless of the frozen allowlist).
-func (s *Service) incidentJobAlias(jobID uuid.UUID) (string, error) {
+// incidentJobRef resolves the qualified alias of the incident's own job (always
+// readable through the context passthroughs regardless of the frozen allowlist).
+func (s *Service) incidentJobRef(jobID uuid.UUID) (string, error) {
 	var job models.Job
-	if err := s.db.WithContext(s.ctx).Select("alias").First(&job, "id = ?", jobID).Error; err != nil {
+	if err := s.db.WithContext(s.ctx).Select("namespace", "alias").First(&job, "id = ?", jobID).Error; err != nil {
 		return "", err
 	}
-	return job.Alias, nil
+	return jobdef.QualifiedAlias(job.Namespace, job.Alias), nil
 }
 
-// jobInAllowlist reports whether jobAlias is EXPLICITLY within the frozen
-// allowlist. An empty allowlist matches nothing — it is never treated as
-// "unrestricted", so a scoped agent token with an empty frozen list can read no
-// cross-job context (only the incident's own job, handled by the caller).
-func jobInAllowlist(jobAlias string, allowed []string) bool {
+// jobInAllowlist reports whether the qualified ref is EXPLICITLY within the
+// frozen allowlist, whose bare entries name default-namespace jobs. An empty
+// allowlist matches nothing — it is never treated as "unrestricted", so a
+// scoped agent token with an empty frozen list can read no cross-job context
+// (only the incident's own job, handled by the caller).
+func jobInAllowlist(ref string, allowed []string) bool {
 	for _, a := range allowed {
-		if a == jobAlias {
+		if jobdef.QualifiedAlias(jobdef.SplitQualifiedAlias(a)) == ref {
 			return true
 		}
 	}
@@ -36,6 +36,11 @@ func New(ctx context.Context) *Service {
 	return &Service{ctx: ctx, db: db.Connection()}
 }
 
+// NewWithDatabase creates a Service backed by conn.
+func NewWithDatabase(ctx context.Context, conn *gorm.DB) *Service {
+	return &Service{ctx: ctx, db: conn}
+}
+
 // WithDatabase returns a copy of the Service backed by conn; used by tests.
 func (s *Service) WithDatabase(conn *gorm.DB) *Service {
 	if conn == nil {
@@ -109,6 +114,12 @@ type DerivationsResult struct {
 	Offset      int                        `json:"offset"`
 }
 
+// Key identifies a dataset by namespace and name.
+type Key struct {
+	Namespace string
+	Name      string
+}
+
 // AdvanceParams carries a manual dataset arrival.
 type AdvanceParams struct {
 	Namespace string
@@ -249,6 +260,50 @@ func (s *Service) Derivations(p DerivationsParams) (*DerivationsResult, error) {
 	}, nil
 }
 
+// RecentDerivations returns up to limit derivations per dataset, newest first,
+// for every key in one query.
+func (s *Service) RecentDerivations(keys []Key, limit int) (map[Key][]models.DatasetDerivation, error) {
+	out := make(map[Key][]models.DatasetDerivation, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	limit, _ = normalizePagination(limit, 0)
+
+	wanted := make(map[Key]bool, len(keys))
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = Key{Namespace: strings.TrimSpace(key.Namespace), Name: strings.TrimSpace(key.Name)}
+		wanted[key] = true
+		names = append(names, key.Name)
+	}
+
+	ranked := s.db.WithContext(s.ctx).
+		Model(&models.DatasetDerivation{}).
+		Select(`dataset_derivations.*,
+			ROW_NUMBER() OVER (
+				PARTITION BY COALESCE(namespace, ''), name
+				ORDER BY created_at DESC, id DESC
+			) AS rn`).
+		Where("name IN ?", names)
+
+	var rows []models.DatasetDerivation
+	if err := s.db.WithContext(s.ctx).
+		Table("(?) AS ranked", ranked).
+		Where("ranked.rn <= ?", limit).
+		Order("ranked.created_at DESC").
+		Order("ranked.id DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		key := Key{Namespace: declNamespaceValue(row.Namespace), Name: row.Name}
+		if wanted[key] {
+			out[key] = append(out[key], row)
+		}
+	}
+	return out, nil
+}
+
 // Advance applies a manual arrival through the freshness.Store contract.
 func (s *Service) Advance(p AdvanceParams) (*AdvanceResult, error) {
 	now := time.Now().UTC()
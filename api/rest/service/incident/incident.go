@@ -31,6 +31,11 @@ func New(ctx context.Context) *Service {
 	return &Service{ctx: ctx, db: db.Connection()}
 }
 
+// NewWithDatabase creates a Service backed by conn.
+func NewWithDatabase(ctx context.Context, conn *gorm.DB) *Service {
+	return &Service{ctx: ctx, db: conn}
+}
+
 // WithDatabase returns a copy of the Service backed by conn; used by tests.
 func (s *Service) WithDatabase(conn *gorm.DB) *Service {
 	if conn == nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/models"
 	runstorage "github.com/caesium-cloud/caesium/internal/run"
 	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/jsonmap"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
@@ -93,11 +94,16 @@ func (j *jobService) publishEvents(events ...event.Event) {
 }
 
 type ListRequest struct {
-	Limit     uint64
-	Offset    uint64
-	OrderBy   []string
-	TriggerID string
-	Aliases   []string
+	Limit      uint64
+	Offset     uint64
+	OrderBy    []string
+	TriggerID  string
+	Aliases    []string
+	Namespaces []string
+	// Jobs limits the listing to jobs named as by jobdef.QualifiedAlias,
+	// matching namespace and alias together.
+	Jobs []string
+	IDs  []uuid.UUID
 }
 
 type RunListSummary struct {
@@ -143,6 +149,22 @@ func (j *jobService) List(req *ListRequest) (models.Jobs, error) {
 	if len(req.Aliases) > 0 {
 		q = q.Where("alias IN ?", req.Aliases)
 	}
+	if len(req.Namespaces) > 0 {
+		q = q.Where("namespace IN ?", req.Namespaces)
+	}
+	if len(req.Jobs) > 0 {
+		clauses := make([]string, 0, len(req.Jobs))
+		args := make([]any, 0, 2*len(req.Jobs))
+		for _, ref := range req.Jobs {
+			namespace, alias := jobdef.SplitQualifiedAlias(ref)
+			clauses = append(clauses, "(namespace = ? AND alias = ?)")
+			args = append(args, namespace, alias)
+		}
+		q = q.Where("("+strings.Join(clauses, " OR ")+")", args...)
+	}
+	if len(req.IDs) > 0 {
+		q = q.Where("id IN ?", req.IDs)
+	}
 
 	for _, orderBy := range req.OrderBy {
 		q = q.Order(orderBy)
@@ -391,6 +413,7 @@ func decodeQueueParams(raw []byte) (map[string]string, error) {
 
 type CreateRequest struct {
 	TriggerID   uuid.UUID         `json:"trigger_id"`
+	Namespace   string            `json:"namespace,omitempty"`
 	Alias       string            `json:"alias"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
@@ -405,6 +428,7 @@ func (j *jobService) Create(req *CreateRequest) (*models.Job, error) {
 	job := &models.Job{
 		ID:          id,
 		TriggerID:   req.TriggerID,
+		Namespace:   jobdef.NamespaceOrDefault(req.Namespace),
 		Alias:       req.Alias,
 		Labels:      jsonmap.FromStringMap(req.Labels),
 		Annotations: jsonmap.FromStringMap(req.Annotations),
@@ -108,6 +108,30 @@ func TestListFiltersByAliases(t *testing.T) {
 	require.Equal(t, "beta", jobs[0].Alias)
 }
 
+func TestListFiltersByQualifiedJobs(t *testing.T) {
+	db := openTestDB(t)
+	svc := &jobService{ctx: context.Background(), db: db}
+
+	for _, req := range []CreateRequest{
+		{Alias: "etl"},
+		{Namespace: "team-a", Alias: "etl"},
+		{Namespace: "team-a", Alias: "load"},
+		{Namespace: "team-b", Alias: "load"},
+	} {
+		req.TriggerID = uuid.New()
+		_, err := svc.Create(&req)
+		require.NoError(t, err)
+	}
+
+	jobs, err := svc.List(&ListRequest{Jobs: []string{"etl", "team-b/load"}, OrderBy: []string{"namespace"}})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.Equal(t, []string{"default/etl", "team-b/load"}, []string{
+		jobs[0].Namespace + "/" + jobs[0].Alias,
+		jobs[1].Namespace + "/" + jobs[1].Alias,
+	})
+}
+
 func TestDeleteSoftDeletesOwningTrigger(t *testing.T) {
 	db := openTestDB(t)
 	svc := &jobService{ctx: context.Background(), db: db}
@@ -5,6 +5,7 @@ import (
 
 	illineage "github.com/caesium-cloud/caesium/internal/lineage"
 	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +20,11 @@ func New(ctx context.Context) *Service {
 	return &Service{ctx: ctx, db: db.Connection()}
 }
 
+// NewWithDatabase creates a Service backed by conn.
+func NewWithDatabase(ctx context.Context, conn *gorm.DB) *Service {
+	return &Service{ctx: ctx, db: conn}
+}
+
 // WithDatabase returns a copy of the Service using the given connection; used
 // in tests to inject an in-memory database without touching the singleton.
 func (s *Service) WithDatabase(conn *gorm.DB) *Service {
@@ -30,3 +36,32 @@ func (s *Service) WithDatabase(conn *gorm.DB) *Service {
 func (s *Service) Impact(namespace, name string, maxDepth int) (*illineage.ImpactResult, error) {
 	return illineage.QueryImpact(s.ctx, s.db, namespace, name, maxDepth)
 }
+
+// Upstream returns the provenance of a dataset version, optionally pinned to
+// the producing run. When field is set the declared column lineage upstream
+// of that column is included. See internal/lineage.QueryUpstream.
+func (s *Service) Upstream(namespace, name, field string, runID *uuid.UUID, maxDepth int) (*illineage.UpstreamResult, error) {
+	result, err := illineage.QueryUpstream(s.ctx, s.db, namespace, name, runID, maxDepth)
+	if err != nil || field == "" {
+		return result, err
+	}
+	columns, err := illineage.QueryColumnUpstream(s.ctx, s.db, name, field, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	result.RootField = field
+	result.Columns = columns
+	return result, nil
+}
+
+// ColumnImpact returns every column transitively derived from a dataset
+// column. See internal/lineage.QueryColumnImpact.
+func (s *Service) ColumnImpact(name, field string, maxDepth int) (*illineage.ColumnImpactResult, error) {
+	return illineage.QueryColumnImpact(s.ctx, s.db, name, field, maxDepth)
+}
+
+// Ingest merges an OpenLineage RunEvent emitted by a tool inside a step into
+// the dataset graph. See internal/lineage.Ingest.
+func (s *Service) Ingest(evt illineage.RunEvent) (*illineage.IngestResult, error) {
+	return illineage.Ingest(s.ctx, s.db, evt)
+}
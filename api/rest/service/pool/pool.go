@@ -0,0 +1,132 @@
+// Package pool implements the cluster-wide task pool resource: a named slot
+// budget that step `pool` references draw from across every job. Pools are
+// addressed by name because job definitions reference them by name.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/db"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidPool = errors.New("invalid pool")
+
+// Service manages Pool resources.
+type Service interface {
+	List() ([]models.Pool, error)
+	Get(name string) (*models.Pool, error)
+	Put(name string, req *PutRequest) (*models.Pool, bool, error)
+	Delete(name string) error
+	Usages() (map[string]run.PoolUsage, error)
+}
+
+type service struct {
+	ctx context.Context
+	db  *gorm.DB
+}
+
+// New returns a new Pool service.
+func New(ctx context.Context) Service {
+	return &service{ctx: ctx, db: db.Connection()}
+}
+
+// PutRequest creates or replaces a pool. Slots may be zero to pause every
+// task in the pool without deleting it.
+type PutRequest struct {
+	Slots       *int   `json:"slots"`
+	Description string `json:"description,omitempty"`
+}
+
+func (s *service) List() ([]models.Pool, error) {
+	var pools []models.Pool
+	return pools, s.db.WithContext(s.ctx).Order("name ASC").Find(&pools).Error
+}
+
+func (s *service) Get(name string) (*models.Pool, error) {
+	var p models.Pool
+	return &p, s.db.WithContext(s.ctx).First(&p, "name = ?", strings.TrimSpace(name)).Error
+}
+
+// Put upserts the named pool and reports whether it was created. Shrinking a
+// pool never preempts running tasks; it only delays admission until the
+// occupied slots drain below the new capacity.
+func (s *service) Put(name string, req *PutRequest) (*models.Pool, bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, false, fmt.Errorf("%w: name is required", ErrInvalidPool)
+	}
+	if !jobdefschema.ValidPoolName(name) {
+		return nil, false, fmt.Errorf("%w: name %q must be lowercase alphanumeric with '-', '_' or '.' and at most %d characters", ErrInvalidPool, name, jobdefschema.MaxPoolNameLength)
+	}
+	if req == nil || req.Slots == nil {
+		return nil, false, fmt.Errorf("%w: slots is required", ErrInvalidPool)
+	}
+	if *req.Slots < 0 {
+		return nil, false, fmt.Errorf("%w: slots must be >= 0", ErrInvalidPool)
+	}
+
+	var (
+		pool    models.Pool
+		created bool
+	)
+	err := s.db.WithContext(s.ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&pool, "name = ?", name).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			created = true
+		case err != nil:
+			return err
+		}
+
+		now := time.Now().UTC()
+		if created {
+			pool = models.Pool{Name: name, CreatedAt: now}
+		}
+		pool.Slots = *req.Slots
+		pool.Description = strings.TrimSpace(req.Description)
+		pool.UpdatedAt = now
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"slots", "description", "updated_at"}),
+		}).Create(&pool).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &pool, created, nil
+}
+
+// Delete removes the named pool. Tasks that still reference it wait for
+// admission until the pool is defined again.
+func (s *service) Delete(name string) error {
+	result := s.db.WithContext(s.ctx).Where("name = ?", strings.TrimSpace(name)).Delete(&models.Pool{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Usages returns the occupancy of every pool keyed by name.
+func (s *service) Usages() (map[string]run.PoolUsage, error) {
+	usages, err := run.PoolUsages(s.ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]run.PoolUsage, len(usages))
+	for _, usage := range usages {
+		out[usage.Name] = usage
+	}
+	return out, nil
+}
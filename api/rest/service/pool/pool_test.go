@@ -0,0 +1,97 @@
+package pool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type PoolSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func TestPoolSuite(t *testing.T) {
+	suite.Run(t, new(PoolSuite))
+}
+
+func (s *PoolSuite) SetupTest() {
+	dsn := "file:" + uuid.NewString() + "?mode=memory&cache=shared"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(models.All...))
+	s.db = db
+}
+
+func (s *PoolSuite) TearDownTest() {
+	if s.db != nil {
+		sqlDB, _ := s.db.DB()
+		if sqlDB != nil {
+			_ = sqlDB.Close()
+		}
+	}
+}
+
+func (s *PoolSuite) svc() *service {
+	return &service{ctx: context.Background(), db: s.db}
+}
+
+func slots(n int) *int { return &n }
+
+func (s *PoolSuite) TestPutGetListDelete() {
+	svc := s.svc()
+
+	created, isNew, err := svc.Put("warehouse", &PutRequest{Slots: slots(4), Description: " shared warehouse "})
+	s.Require().NoError(err)
+	s.True(isNew)
+	s.Equal(4, created.Slots)
+	s.Equal("shared warehouse", created.Description)
+
+	updated, isNew, err := svc.Put("warehouse", &PutRequest{Slots: slots(0)})
+	s.Require().NoError(err)
+	s.False(isNew)
+	s.Equal(0, updated.Slots, "zero slots pauses the pool")
+	s.Equal(created.CreatedAt.Unix(), updated.CreatedAt.Unix())
+
+	got, err := svc.Get("warehouse")
+	s.Require().NoError(err)
+	s.Equal(0, got.Slots)
+
+	_, _, err = svc.Put("api", &PutRequest{Slots: slots(2)})
+	s.Require().NoError(err)
+	pools, err := svc.List()
+	s.Require().NoError(err)
+	s.Require().Len(pools, 2)
+	s.Equal("api", pools[0].Name)
+
+	usages, err := svc.Usages()
+	s.Require().NoError(err)
+	s.Equal(2, usages["api"].Slots)
+
+	s.Require().NoError(svc.Delete("warehouse"))
+	s.ErrorIs(svc.Delete("warehouse"), gorm.ErrRecordNotFound)
+	_, err = svc.Get("warehouse")
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *PoolSuite) TestPutRejectsInvalidPools() {
+	svc := s.svc()
+
+	for name, tc := range map[string]struct {
+		name string
+		req  *PutRequest
+	}{
+		"empty name":     {name: " ", req: &PutRequest{Slots: slots(1)}},
+		"invalid name":   {name: "Warehouse!", req: &PutRequest{Slots: slots(1)}},
+		"missing slots":  {name: "warehouse", req: &PutRequest{}},
+		"negative slots": {name: "warehouse", req: &PutRequest{Slots: slots(-1)}},
+	} {
+		_, _, err := svc.Put(tc.name, tc.req)
+		s.ErrorIs(err, ErrInvalidPool, name)
+	}
+}
@@ -1,6 +1,7 @@
 // Package receipt is the REST service wrapper around internal/receipt: it
-// builds a reproducibility receipt for a run and verifies a committed receipt
-// against a run's current persisted state.
+// builds and signs a reproducibility receipt for a run, verifies a committed
+// receipt against a run's current persisted state, and exports a run as signed
+// SLSA provenance.
 package receipt
 
 import (
@@ -8,34 +9,76 @@ import (
 
 	ireceipt "github.com/caesium-cloud/caesium/internal/receipt"
 	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/env"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // Service wraps the internal receipt build/verify layer for REST controllers.
 type Service struct {
-	ctx context.Context
-	db  *gorm.DB
+	ctx     context.Context
+	db      *gorm.DB
+	keyring *ireceipt.Keyring
 }
 
-// New creates a Service with the default DB connection.
+// New creates a Service with the default DB connection and the signing
+// keyring configured by the environment.
 func New(ctx context.Context) *Service {
-	return &Service{ctx: ctx, db: db.Connection()}
+	return &Service{ctx: ctx, db: db.Connection(), keyring: ireceipt.DefaultKeyring()}
 }
 
 // WithDatabase returns a copy of the Service using the given connection; used
 // in tests to inject an in-memory database without touching the singleton.
 func (s *Service) WithDatabase(conn *gorm.DB) *Service {
-	return &Service{ctx: s.ctx, db: conn}
+	return &Service{ctx: s.ctx, db: conn, keyring: s.keyring}
 }
 
-// Build re-derives the reproducibility receipt for a run from persisted state.
+// WithKeyring returns a copy of the Service that signs with keyring. A nil
+// keyring leaves receipts and provenance unsigned.
+func (s *Service) WithKeyring(keyring *ireceipt.Keyring) *Service {
+	return &Service{ctx: s.ctx, db: s.db, keyring: keyring}
+}
+
+// Build re-derives the reproducibility receipt for a run from persisted state
+// and signs it when a keyring is configured.
 func (s *Service) Build(runID uuid.UUID) (*ireceipt.Receipt, error) {
-	return ireceipt.Build(s.ctx, s.db, runID)
+	r, err := ireceipt.Build(s.ctx, s.db, runID)
+	if err != nil {
+		return nil, err
+	}
+	s.keyring.Sign(r)
+	return r, nil
 }
 
-// Verify re-derives the receipt for committed.RunID and reports drift against
-// the committed receipt.
+// Verify re-derives the receipt for committed.RunID, reports drift against
+// the committed receipt, and checks its signatures against the cluster's
+// public keys.
 func (s *Service) Verify(committed *ireceipt.Receipt) (*ireceipt.VerifyResult, error) {
-	return ireceipt.Verify(s.ctx, s.db, committed)
+	result, err := ireceipt.Verify(s.ctx, s.db, committed)
+	if err != nil {
+		return nil, err
+	}
+	result.CheckSignatures(committed, s.keyring.PublicKeys())
+	return result, nil
+}
+
+// Provenance exports a run as an in-toto SLSA provenance statement and wraps
+// it in a DSSE envelope, signed when a keyring is configured. The statement is
+// returned alongside the envelope so callers need not decode the payload.
+func (s *Service) Provenance(runID uuid.UUID) (*ireceipt.Statement, *ireceipt.Envelope, error) {
+	statement, err := ireceipt.BuildProvenance(s.ctx, s.db, runID, env.Variables().ReceiptBuilderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope, err := s.keyring.Envelope(statement)
+	if err != nil {
+		return nil, nil, err
+	}
+	return statement, envelope, nil
+}
+
+// PublicKeys returns the public key set receipts and provenance verify
+// against.
+func (s *Service) PublicKeys() ireceipt.PublicKeySet {
+	return s.keyring.PublicKeys()
 }
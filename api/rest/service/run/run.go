@@ -17,7 +17,8 @@ type Service interface {
 	Start(jobID uuid.UUID, triggerID *uuid.UUID, opts ...runstorage.StartOption) (*runstorage.JobRun, error)
 	Get(uuid.UUID) (*runstorage.JobRun, error)
 	GetTaskLogSnapshot(runID, taskID uuid.UUID) (*runstorage.TaskLogSnapshot, error)
-	List(uuid.UUID) ([]*runstorage.JobRun, error)
+	List(uuid.UUID, runstorage.ListOptions) ([]*runstorage.JobRun, error)
+	ListByIDs([]uuid.UUID) (map[uuid.UUID]*runstorage.JobRun, error)
 	Latest(uuid.UUID) (*runstorage.JobRun, error)
 }
 
@@ -46,6 +47,14 @@ func New(ctx context.Context) Service {
 	}
 }
 
+// NewWithDatabase creates a Service backed by a store over conn.
+func NewWithDatabase(ctx context.Context, conn *gorm.DB) Service {
+	return &runService{
+		ctx:   ctx,
+		store: runstorage.NewStore(conn),
+	}
+}
+
 func (r *runService) SetBus(bus event.Bus) {
 	r.store.SetBus(bus)
 	defaultServiceMu.Lock()
@@ -67,8 +76,12 @@ func (r *runService) GetTaskLogSnapshot(runID, taskID uuid.UUID) (*runstorage.Ta
 	return r.store.GetTaskLogSnapshot(runID, taskID)
 }
 
-func (r *runService) List(jobID uuid.UUID) ([]*runstorage.JobRun, error) {
-	return r.store.List(jobID)
+func (r *runService) List(jobID uuid.UUID, opts runstorage.ListOptions) ([]*runstorage.JobRun, error) {
+	return r.store.List(jobID, opts)
+}
+
+func (r *runService) ListByIDs(ids []uuid.UUID) (map[uuid.UUID]*runstorage.JobRun, error) {
+	return r.store.ListByIDs(ids)
 }
 
 func (r *runService) Latest(jobID uuid.UUID) (*runstorage.JobRun, error) {
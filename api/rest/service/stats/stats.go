@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
 	"github.com/caesium-cloud/caesium/pkg/db"
 	"gorm.io/gorm"
 )
@@ -16,6 +17,9 @@ type StatsResponse struct {
 	TopFailingAtoms  []FailingAtom `json:"top_failing_atoms"`
 	SlowestJobs      []SlowestJob  `json:"slowest_jobs"`
 	SuccessRateTrend []DailyStats  `json:"success_rate_trend"`
+	// Pools is the point-in-time occupancy of every task pool; it ignores
+	// the summary window.
+	Pools []run.PoolUsage `json:"pools"`
 }
 
 // DailyStats describes success rate for a specific day or hour.
@@ -275,6 +279,15 @@ func (s *Service) Summary(window string) (*StatsResponse, error) {
 		}
 	}
 
+	pools, err := run.PoolUsages(s.ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	resp.Pools = pools
+	if resp.Pools == nil {
+		resp.Pools = []run.PoolUsage{}
+	}
+
 	return resp, nil
 }
 
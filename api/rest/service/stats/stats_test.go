@@ -214,3 +214,16 @@ func (s *StatsSuite) createJobRun(jobID uuid.UUID, status string, started time.T
 	}
 	s.Require().NoError(s.db.Create(run).Error)
 }
+
+func (s *StatsSuite) TestSummaryReportsPoolOccupancy() {
+	now := time.Now().UTC()
+	s.Require().NoError(s.db.Create(&models.Pool{Name: "warehouse", Slots: 4, CreatedAt: now, UpdatedAt: now}).Error)
+
+	svc := &Service{ctx: context.Background(), db: s.db}
+	resp, err := svc.Summary("24h")
+	s.Require().NoError(err)
+	s.Require().Len(resp.Pools, 1)
+	s.Equal("warehouse", resp.Pools[0].Name)
+	s.Equal(4, resp.Pools[0].Slots)
+	s.Equal(0, resp.Pools[0].OccupiedSlots)
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	contractsvc "github.com/caesium-cloud/caesium/api/rest/service/contract"
+	"github.com/caesium-cloud/caesium/internal/cordon"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/pkg/db"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
@@ -32,6 +34,11 @@ type Node struct {
 	Role         NodeRole `json:"role"`
 	WorkersBusy  int      `json:"workers_busy"`
 	WorkersTotal int      `json:"workers_total"`
+	// State is "active", "cordoned" or "draining". Drained reports a draining
+	// node with no running tasks left, which is safe to stop.
+	State         cordon.State `json:"state"`
+	Drained       bool         `json:"drained"`
+	DrainDeadline *time.Time   `json:"drain_deadline,omitempty"`
 }
 
 type Features struct {
@@ -105,6 +112,17 @@ func (s *Service) Nodes() ([]Node, error) {
 		}
 	}
 
+	// Cordoned and draining nodes stay listed even after they stop claiming.
+	states, err := cordon.NewStore(s.db).List(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for addr := range states {
+		if _, ok := addrMap[addr]; !ok {
+			addrMap[addr] = RoleWorker
+		}
+	}
+
 	nodes := make([]Node, 0, len(addrMap))
 	for addr, role := range addrMap {
 		var busy int64
@@ -112,17 +130,40 @@ func (s *Service) Nodes() ([]Node, error) {
 			Where("status = ? AND claimed_by = ?", "running", addr).
 			Count(&busy)
 
-		nodes = append(nodes, Node{
+		node := Node{
 			Address:      addr,
 			Arch:         runtime.GOARCH,
 			Role:         role,
 			WorkersBusy:  int(busy),
 			WorkersTotal: env.Variables().WorkerPoolSize,
-		})
+			State:        cordon.StateActive,
+		}
+		if state, ok := states[addr]; ok {
+			node.State = cordon.State(state.State)
+			node.DrainDeadline = state.DrainDeadline
+			node.Drained = node.State == cordon.StateDraining && busy == 0
+		}
+		nodes = append(nodes, node)
 	}
 	return nodes, nil
 }
 
+// Cordon stops address from claiming tasks or receiving dispatches.
+func (s *Service) Cordon(address, actor string) (*models.NodeState, error) {
+	return cordon.NewStore(s.db).Cordon(s.ctx, address, actor)
+}
+
+// Drain cordons address and hands its running tasks back, releasing any still
+// running once timeout passes. A zero timeout waits for them to finish.
+func (s *Service) Drain(address, actor string, timeout time.Duration) (*models.NodeState, error) {
+	return cordon.NewStore(s.db).Drain(s.ctx, address, actor, timeout)
+}
+
+// Uncordon makes address schedulable again.
+func (s *Service) Uncordon(address string) error {
+	return cordon.NewStore(s.db).Uncordon(s.ctx, address)
+}
+
 func (s *Service) Features() (*Features, error) {
 	v := env.Variables()
 	return &Features{
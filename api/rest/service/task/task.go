@@ -51,6 +51,8 @@ type ListRequest struct {
 	OrderBy []string
 	JobID   string
 	AtomID  string
+	IDs     []uuid.UUID
+	JobIDs  []uuid.UUID
 }
 
 func (t *taskService) List(req *ListRequest) (models.Tasks, error) {
@@ -75,6 +77,14 @@ func (t *taskService) List(req *ListRequest) (models.Tasks, error) {
 		q = q.Where("atom_id = ?", req.AtomID)
 	}
 
+	if len(req.IDs) > 0 {
+		q = q.Where("id IN ?", req.IDs)
+	}
+
+	if len(req.JobIDs) > 0 {
+		q = q.Where("job_id IN ?", req.JobIDs)
+	}
+
 	for _, orderBy := range req.OrderBy {
 		q = q.Order(orderBy)
 	}
@@ -76,10 +76,12 @@ func (t *triggerService) WithDatabase(conn *gorm.DB) Trigger {
 }
 
 type ListRequest struct {
-	Limit   uint64
-	Offset  uint64
-	OrderBy []string
-	Type    string
+	Limit      uint64
+	Offset     uint64
+	OrderBy    []string
+	Type       string
+	Namespaces []string
+	IDs        []uuid.UUID
 }
 
 func (t *triggerService) List(req *ListRequest) (models.Triggers, error) {
@@ -91,6 +93,12 @@ func (t *triggerService) List(req *ListRequest) (models.Triggers, error) {
 	if req.Type != "" {
 		q = q.Where("type = ?", req.Type)
 	}
+	if len(req.Namespaces) > 0 {
+		q = q.Where("namespace IN ?", req.Namespaces)
+	}
+	if len(req.IDs) > 0 {
+		q = q.Where("id IN ?", req.IDs)
+	}
 
 	for _, orderBy := range req.OrderBy {
 		q = q.Order(orderBy)
@@ -143,6 +151,7 @@ func (t *triggerService) Get(id uuid.UUID) (*models.Trigger, error) {
 }
 
 type CreateRequest struct {
+	Namespace     string                 `json:"namespace,omitempty"`
 	Alias         string                 `json:"alias"`
 	Type          string                 `json:"type"`
 	Configuration map[string]interface{} `json:"configuration"`
@@ -167,12 +176,17 @@ func (t *triggerService) Create(req *CreateRequest) (*models.Trigger, error) {
 	if err := validateTriggerRequest(triggerType, req.Configuration); err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidTriggerRequest, err)
 	}
-	if err := ensureAliasAvailable(q, strings.TrimSpace(req.Alias), uuid.Nil); err != nil {
+	namespace := jobdefschema.NamespaceOrDefault(req.Namespace)
+	if !jobdefschema.ValidNamespace(namespace) {
+		return nil, fmt.Errorf("%w: invalid namespace %q", ErrInvalidTriggerRequest, namespace)
+	}
+	if err := ensureAliasAvailable(q, namespace, strings.TrimSpace(req.Alias), uuid.Nil); err != nil {
 		return nil, err
 	}
 
 	trigger := &models.Trigger{
 		ID:            id,
+		Namespace:     namespace,
 		Alias:         req.Alias,
 		Type:          triggerType,
 		Configuration: cfg,
@@ -199,7 +213,7 @@ func (t *triggerService) Update(id uuid.UUID, req *UpdateRequest) (*models.Trigg
 	}
 
 	if req.Alias != nil {
-		if err := ensureAliasAvailable(t.db.WithContext(t.ctx), strings.TrimSpace(*req.Alias), id); err != nil {
+		if err := ensureAliasAvailable(t.db.WithContext(t.ctx), trigger.Namespace, strings.TrimSpace(*req.Alias), id); err != nil {
 			return nil, err
 		}
 		trigger.Alias = *req.Alias
@@ -252,14 +266,14 @@ func validateTriggerRequest(triggerType models.TriggerType, configuration map[st
 	})
 }
 
-func ensureAliasAvailable(q *gorm.DB, alias string, excludeID uuid.UUID) error {
+func ensureAliasAvailable(q *gorm.DB, namespace, alias string, excludeID uuid.UUID) error {
 	alias = strings.TrimSpace(alias)
 	if alias == "" {
 		return nil
 	}
 
 	var existing models.Trigger
-	query := q.Where("alias = ?", alias)
+	query := q.Where("namespace = ? AND alias = ?", jobdefschema.NamespaceOrDefault(namespace), alias)
 	if excludeID != uuid.Nil {
 		query = query.Where("id <> ?", excludeID)
 	}
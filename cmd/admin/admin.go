@@ -0,0 +1,9 @@
+package admin
+
+import "github.com/spf13/cobra"
+
+// Cmd is the parent command for cluster administration.
+var Cmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Administer the Caesium cluster",
+}
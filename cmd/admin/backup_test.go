@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/caesium-cloud/caesium/internal/backup"
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/dqlite"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func testArchive(t *testing.T) []byte {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "caesium")
+	conn, err := sql.Open("sqlite3", file)
+	require.NoError(t, err)
+	_, err = conn.Exec("CREATE TABLE jobs (id TEXT PRIMARY KEY, alias TEXT); INSERT INTO jobs VALUES ('1', 'nightly')")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	var archive bytes.Buffer
+	_, err = backup.Write(context.Background(), &archive, backup.Options{
+		Databases: []db.NamedDatabase{{Name: "caesium", Role: db.DatabaseRoleCatalog}},
+		Dump: func(context.Context, string) ([]dqlite.File, error) {
+			data, err := os.ReadFile(file)
+			return []dqlite.File{{Name: "caesium", Data: data}}, err
+		},
+	})
+	require.NoError(t, err)
+	return archive.Bytes()
+}
+
+func TestBackupDownloadsAndVerifiesArchive(t *testing.T) {
+	originalAPI, originalOutput := backupAPI, backupOutput
+	t.Cleanup(func() { backupAPI, backupOutput = originalAPI, originalOutput })
+
+	archive := testArchive(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v1/admin/backup", r.URL.Path)
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+	backupAPI.Server = server.URL
+	backupOutput = filepath.Join(t.TempDir(), "cluster.tar.gz")
+
+	cmd, stdout := newAdminTestCommand()
+	require.NoError(t, runBackup(cmd, nil))
+	require.Contains(t, stdout.String(), "wrote "+backupOutput+" (1 databases, schema ")
+	written, err := os.ReadFile(backupOutput)
+	require.NoError(t, err)
+	require.Equal(t, archive, written)
+
+	restoreVerifyOnly = true
+	t.Cleanup(func() { restoreVerifyOnly = false })
+	cmd, stdout = newAdminTestCommand()
+	require.NoError(t, runRestore(cmd, []string{backupOutput}))
+	require.Contains(t, stdout.String(), "caesium (catalog): 1 tables, 1 rows\n")
+	require.Contains(t, stdout.String(), "backup verified\n")
+}
+
+func TestBackupRejectsTruncatedArchive(t *testing.T) {
+	originalAPI, originalOutput := backupAPI, backupOutput
+	t.Cleanup(func() { backupAPI, backupOutput = originalAPI, originalOutput })
+
+	archive := testArchive(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive[:len(archive)/2])
+	}))
+	defer server.Close()
+	backupAPI.Server = server.URL
+	dir := t.TempDir()
+	backupOutput = filepath.Join(dir, "cluster.tar.gz")
+
+	cmd, _ := newAdminTestCommand()
+	require.ErrorContains(t, runBackup(cmd, nil), "downloaded backup is invalid")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "a failed download leaves nothing behind")
+}
+
+func newAdminTestCommand() (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	return cmd, &stdout
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shardsAPI    cliutil.APIFlags
+	shardsOutput string
+)
+
+var shardsCmd = &cobra.Command{
+	Use:   "shards",
+	Short: "Show hot-shard row counts",
+	Long:  "List the catalog and every hot shard with its run-table row counts. Requires an admin API key.",
+	Args:  cobra.NoArgs,
+	RunE:  runShards,
+}
+
+func runShards(cmd *cobra.Command, _ []string) error {
+	if err := cliutil.ValidateOutput(shardsOutput); err != nil {
+		return err
+	}
+	report, err := shardsAPI.Client(cmd).Shards(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return cliutil.WriteOutput(cmd, shardsOutput, report, func(out io.Writer) error {
+		return renderShards(out, report)
+	})
+}
+
+func renderShards(out io.Writer, report *client.ShardReport) error {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "DATABASE\tJOB_RUNS\tTASK_RUNS\tEVENTS")
+	for _, database := range report.Databases {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n",
+			database.Name,
+			database.Tables["job_runs"],
+			database.Tables["task_runs"],
+			database.Tables["execution_events"],
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "\n%d shards, %d runs\n", report.Shards, report.Runs)
+	return err
+}
+
+func init() {
+	shardsAPI.Register(shardsCmd)
+	cliutil.AddOutputFlag(shardsCmd, &shardsOutput)
+
+	Cmd.AddCommand(shardsCmd)
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardsShowsRowCounts(t *testing.T) {
+	originalAPI, originalOutput := shardsAPI, shardsOutput
+	t.Cleanup(func() { shardsAPI, shardsOutput = originalAPI, originalOutput })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/v1/admin/shards", r.URL.Path)
+		require.NoError(t, json.NewEncoder(w).Encode(runstorage.ShardReport{
+			Shards: 2,
+			Databases: []runstorage.ShardSummary{
+				{Name: "caesium", Tables: map[string]int64{"job_runs": 5, "task_runs": 10, "execution_events": 40}},
+				{Name: "caesium_hot_00", Tables: map[string]int64{}},
+				{Name: "caesium_hot_01", Tables: map[string]int64{}},
+			},
+			Runs: 5,
+		}))
+	}))
+	defer server.Close()
+	shardsAPI.Server = server.URL
+
+	cmd, stdout := newAdminTestCommand()
+	require.NoError(t, runShards(cmd, nil))
+	require.Contains(t, stdout.String(), "caesium         5         10         40\n")
+	require.Contains(t, stdout.String(), "\n2 shards, 5 runs\n")
+
+	shardsOutput = "json"
+	cmd, stdout = newAdminTestCommand()
+	require.NoError(t, runShards(cmd, nil))
+	var report runstorage.ShardReport
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.EqualValues(t, 5, report.Runs)
+}
@@ -11,19 +11,21 @@ import (
 )
 
 var (
-	createRole        string
-	createDescription string
-	createExpiresIn   string
-	createServer      string
-	createAPIKey      string
-	createScopeJobs   []string
+	createRole            string
+	createDescription     string
+	createExpiresIn       string
+	createServer          string
+	createAPIKey          string
+	createScopeJobs       []string
+	createScopeNamespaces []string
 )
 
 var keyCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new API key",
 	Example: `  caesium auth key create --role operator --description "CI deploy key" --expires 90d
-  caesium auth key create --role runner --description "ETL runner" --scope-jobs etl-daily,etl-hourly`,
+  caesium auth key create --role runner --description "ETL runner" --scope-jobs etl-daily,etl-hourly
+  caesium auth key create --role operator --description "Team A deploys" --scope-namespaces team-a`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		server := strings.TrimSuffix(createServer, "/")
 		apiKey := resolveAPIKey(cmd, createAPIKey)
@@ -35,10 +37,15 @@ var keyCreateCmd = &cobra.Command{
 		if createExpiresIn != "" {
 			body["expires_in"] = createExpiresIn
 		}
-		if len(createScopeJobs) > 0 {
-			body["scope"] = map[string]interface{}{
-				"jobs": createScopeJobs,
+		if len(createScopeJobs) > 0 || len(createScopeNamespaces) > 0 {
+			scope := map[string]interface{}{}
+			if len(createScopeJobs) > 0 {
+				scope["jobs"] = createScopeJobs
 			}
+			if len(createScopeNamespaces) > 0 {
+				scope["namespaces"] = createScopeNamespaces
+			}
+			body["scope"] = scope
 		}
 
 		payload, err := json.Marshal(body)
@@ -94,7 +101,8 @@ func init() {
 	keyCreateCmd.Flags().StringVar(&createExpiresIn, "expires", "", "Expiration duration (e.g. 90d, 24h)")
 	keyCreateCmd.Flags().StringVar(&createServer, "server", "http://localhost:8080", "Caesium server base URL")
 	keyCreateCmd.Flags().StringVar(&createAPIKey, "api-key", "", apiKeyFlagUsage("Admin"))
-	keyCreateCmd.Flags().StringSliceVar(&createScopeJobs, "scope-jobs", nil, "Restrict key to specific job aliases (comma-separated)")
+	keyCreateCmd.Flags().StringSliceVar(&createScopeJobs, "scope-jobs", nil, "Restrict key to specific jobs as namespace/alias; a bare alias is in the default namespace (comma-separated)")
+	keyCreateCmd.Flags().StringSliceVar(&createScopeNamespaces, "scope-namespaces", nil, "Restrict key to the jobs of specific namespaces (comma-separated)")
 	_ = keyCreateCmd.MarkFlagRequired("role")
 
 	keyCmd.AddCommand(keyCreateCmd)
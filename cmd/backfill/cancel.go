@@ -17,7 +17,7 @@ var (
 
 var cancelCmd = &cobra.Command{
 	Use:   "cancel",
-	Short: "Cancel a running backfill",
+	Short: "Cancel a running or paused backfill",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if cancelJobID == "" {
 			return fmt.Errorf("--job-id is required")
@@ -19,37 +19,62 @@ var (
 	createMaxConc   int
 	createReprocess string
 	createServer    string
+
+	createDates       []string
+	createOrder       string
+	createBatchSize   int
+	createParams      []string
+	createMaxFailures int
+	createDryRun      bool
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Start a backfill for a job",
-	Long: `Start a backfill that queues runs for each cron fire time in [start, end).
+	Long: `Start a backfill that queues runs for each cron fire time in [start, end),
+or for an explicit list of --date values.
 
 Reprocess policies:
   none    Skip dates that already have any run (default)
   failed  Skip dates whose latest run succeeded
-  all     Queue a new run for every date regardless of existing runs`,
+  all     Queue a new run for every date regardless of existing runs
+
+--batch-size groups consecutive logical dates into one run whose data interval
+spans them all. --dry-run prints the planned runs without starting anything.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if createJobID == "" {
 			return fmt.Errorf("--job-id is required")
 		}
-		if createStart == "" || createEnd == "" {
-			return fmt.Errorf("--start and --end are required")
-		}
 
-		start, err := time.Parse(time.RFC3339, createStart)
-		if err != nil {
-			return fmt.Errorf("invalid --start (expected RFC3339, e.g. 2024-01-01T00:00:00Z): %w", err)
-		}
-		end, err := time.Parse(time.RFC3339, createEnd)
-		if err != nil {
-			return fmt.Errorf("invalid --end (expected RFC3339, e.g. 2024-02-01T00:00:00Z): %w", err)
-		}
+		body := map[string]interface{}{}
+		if len(createDates) > 0 {
+			if createStart != "" || createEnd != "" {
+				return fmt.Errorf("--date cannot be combined with --start and --end")
+			}
+			dates := make([]time.Time, 0, len(createDates))
+			for _, value := range createDates {
+				d, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					return fmt.Errorf("invalid --date (expected RFC3339, e.g. 2024-01-01T00:00:00Z): %w", err)
+				}
+				dates = append(dates, d)
+			}
+			body["dates"] = dates
+		} else {
+			if createStart == "" || createEnd == "" {
+				return fmt.Errorf("--start and --end are required")
+			}
 
-		body := map[string]interface{}{
-			"start": start,
-			"end":   end,
+			start, err := time.Parse(time.RFC3339, createStart)
+			if err != nil {
+				return fmt.Errorf("invalid --start (expected RFC3339, e.g. 2024-01-01T00:00:00Z): %w", err)
+			}
+			end, err := time.Parse(time.RFC3339, createEnd)
+			if err != nil {
+				return fmt.Errorf("invalid --end (expected RFC3339, e.g. 2024-02-01T00:00:00Z): %w", err)
+			}
+			body["start"] = start
+			body["end"] = end
 		}
 		if createMaxConc > 0 {
 			body["max_concurrent"] = createMaxConc
@@ -57,6 +82,29 @@ Reprocess policies:
 		if createReprocess != "" {
 			body["reprocess"] = createReprocess
 		}
+		if createOrder != "" {
+			body["order"] = createOrder
+		}
+		if createBatchSize > 0 {
+			body["batch_size"] = createBatchSize
+		}
+		if createMaxFailures > 0 {
+			body["max_failures"] = createMaxFailures
+		}
+		if len(createParams) > 0 {
+			params := make(map[string]string, len(createParams))
+			for _, value := range createParams {
+				key, val, ok := strings.Cut(value, "=")
+				if !ok || strings.TrimSpace(key) == "" {
+					return fmt.Errorf("--param must be key=value: %s", value)
+				}
+				params[strings.TrimSpace(key)] = val
+			}
+			body["params"] = params
+		}
+		if createDryRun {
+			body["dry_run"] = true
+		}
 
 		payload, err := json.Marshal(body)
 		if err != nil {
@@ -83,6 +131,10 @@ Reprocess policies:
 			return fmt.Errorf("backfill create failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
 		}
 
+		if createDryRun {
+			cmd.Printf("Planned runs:\n%s\n", string(respBody))
+			return nil
+		}
 		cmd.Printf("Backfill started:\n%s\n", string(respBody))
 		return nil
 	},
@@ -90,10 +142,16 @@ Reprocess policies:
 
 func init() {
 	createCmd.Flags().StringVar(&createJobID, "job-id", "", "Job ID to backfill (required)")
-	createCmd.Flags().StringVar(&createStart, "start", "", "Backfill window start in RFC3339 format (required)")
-	createCmd.Flags().StringVar(&createEnd, "end", "", "Backfill window end in RFC3339 format (required)")
+	createCmd.Flags().StringVar(&createStart, "start", "", "Backfill window start in RFC3339 format")
+	createCmd.Flags().StringVar(&createEnd, "end", "", "Backfill window end in RFC3339 format")
 	createCmd.Flags().IntVar(&createMaxConc, "max-concurrent", 1, "Maximum number of concurrent backfill runs")
 	createCmd.Flags().StringVar(&createReprocess, "reprocess", "none", "Reprocess policy: none, failed, or all")
+	createCmd.Flags().StringArrayVar(&createDates, "date", nil, "Logical date to backfill in RFC3339 format instead of --start/--end (repeatable)")
+	createCmd.Flags().StringVar(&createOrder, "order", "oldest_first", "Launch order: oldest_first or newest_first")
+	createCmd.Flags().IntVar(&createBatchSize, "batch-size", 1, "Number of consecutive logical dates each run covers")
+	createCmd.Flags().StringArrayVar(&createParams, "param", nil, "Run param merged over the trigger's defaultParams as key=value (repeatable)")
+	createCmd.Flags().IntVar(&createMaxFailures, "max-failures", 0, "Stop launching runs after this many failures (0 never stops early)")
+	createCmd.Flags().BoolVar(&createDryRun, "dry-run", false, "Print the planned runs without starting the backfill")
 	createCmd.Flags().StringVar(&createServer, "server", "http://localhost:8080", "Caesium server base URL")
 
 	Cmd.AddCommand(createCmd)
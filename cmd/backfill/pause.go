@@ -0,0 +1,83 @@
+package backfill
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pauseJobID      string
+	pauseBackfillID string
+	pauseServer     string
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause a running backfill",
+	Long:  "Pause stops a backfill from launching further logical dates. Runs already in flight finish normally; resume continues from the next unlaunched date.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return putBackfillAction(cmd, pauseServer, pauseJobID, pauseBackfillID, "pause", "paused")
+	},
+}
+
+var (
+	resumeJobID      string
+	resumeBackfillID string
+	resumeServer     string
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume a paused backfill",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return putBackfillAction(cmd, resumeServer, resumeJobID, resumeBackfillID, "resume", "resumed")
+	},
+}
+
+// putBackfillAction sends PUT /v1/jobs/:id/backfills/:backfill_id/<action>.
+func putBackfillAction(cmd *cobra.Command, server, jobID, backfillID, action, done string) error {
+	if jobID == "" {
+		return fmt.Errorf("--job-id is required")
+	}
+	if backfillID == "" {
+		return fmt.Errorf("--backfill-id is required")
+	}
+
+	server = strings.TrimSuffix(server, "/")
+	url := fmt.Sprintf("%s/v1/jobs/%s/backfills/%s/%s", server, jobID, backfillID, action)
+
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPut, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("backfill %s failed (%d): %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	cmd.Printf("Backfill %s %s\n", backfillID, done)
+	return nil
+}
+
+func init() {
+	pauseCmd.Flags().StringVar(&pauseJobID, "job-id", "", "Job ID owning the backfill (required)")
+	pauseCmd.Flags().StringVar(&pauseBackfillID, "backfill-id", "", "Backfill ID to pause (required)")
+	pauseCmd.Flags().StringVar(&pauseServer, "server", "http://localhost:8080", "Caesium server base URL")
+
+	resumeCmd.Flags().StringVar(&resumeJobID, "job-id", "", "Job ID owning the backfill (required)")
+	resumeCmd.Flags().StringVar(&resumeBackfillID, "backfill-id", "", "Backfill ID to resume (required)")
+	resumeCmd.Flags().StringVar(&resumeServer, "server", "http://localhost:8080", "Caesium server base URL")
+
+	Cmd.AddCommand(pauseCmd, resumeCmd)
+}
@@ -0,0 +1,25 @@
+package cliutil
+
+import (
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// APIFlags are the --server and --api-key flags of a command that talks to
+// the REST API through pkg/client.
+type APIFlags struct {
+	Server string
+	APIKey string
+}
+
+// Register adds the flags to cmd.
+func (f *APIFlags) Register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.Server, "server", client.DefaultServer, "Caesium server base URL")
+	cmd.Flags().StringVar(&f.APIKey, "api-key", "", "API key for authentication (prefer "+APIKeyEnvVar+"; --api-key is visible in process listings)")
+}
+
+// Client returns a client for the configured server, authenticated with the
+// flag or CAESIUM_API_KEY.
+func (f *APIFlags) Client(cmd *cobra.Command) client.Caesium {
+	return client.New(f.Server, client.WithAPIKey(ResolveAPIKey(cmd, f.APIKey, APIKeyEnvVar)))
+}
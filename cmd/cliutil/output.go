@@ -0,0 +1,89 @@
+package cliutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted by --output.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// AddOutputFlag registers -o/--output on cmd, defaulting to a table.
+func AddOutputFlag(cmd *cobra.Command, target *string) {
+	cmd.Flags().StringVarP(target, "output", "o", OutputTable, "Output format: table, json, or yaml")
+}
+
+// ValidateOutput rejects formats WriteOutput cannot produce, so commands can
+// fail before making any requests.
+func ValidateOutput(format string) error {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", OutputTable, OutputJSON, OutputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q; use table, json, or yaml", format)
+	}
+}
+
+// WriteOutput writes v in the requested format. JSON and YAML carry v's JSON
+// field names; the table format is rendered by table.
+func WriteOutput(cmd *cobra.Command, format string, v any, table func(io.Writer) error) error {
+	if err := ValidateOutput(format); err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case OutputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding JSON output: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case OutputYAML:
+		data, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		return table(out)
+	}
+}
+
+// toYAML converts v through its JSON encoding, which YAML parses as a flow
+// document, so that field names, field order, and number formatting match
+// the JSON output; the styles are then reset to block form.
+func toYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding YAML output: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("encoding YAML output: %w", err)
+	}
+	blockStyle(&doc)
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding YAML output: %w", err)
+	}
+	return out, nil
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,48 @@
+package cliutil
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+type outputRow struct {
+	Name   string            `json:"name"`
+	Count  int64             `json:"count"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func TestWriteOutputFormats(t *testing.T) {
+	rows := []outputRow{{Name: "nightly", Count: 1234567, Labels: map[string]string{"team": "data"}}}
+
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{format: OutputTable, want: "table\n"},
+		{format: OutputJSON, want: "[\n  {\n    \"name\": \"nightly\",\n    \"count\": 1234567,\n    \"labels\": {\n      \"team\": \"data\"\n    }\n  }\n]\n"},
+		{format: OutputYAML, want: "- name: nightly\n  count: 1234567\n  labels:\n    team: data\n"},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := WriteOutput(cmd, tc.format, rows, func(w io.Writer) error {
+				_, err := io.WriteString(w, "table\n")
+				return err
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.want, out.String())
+		})
+	}
+}
+
+func TestWriteOutputRejectsUnknownFormat(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	err := WriteOutput(cmd, "xml", nil, func(io.Writer) error { return nil })
+	require.EqualError(t, err, `unsupported output format "xml"; use table, json, or yaml`)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/caesium-cloud/caesium/cmd/admin"
 	"github.com/caesium-cloud/caesium/cmd/agentprofile"
 	"github.com/caesium-cloud/caesium/cmd/auth"
 	"github.com/caesium-cloud/caesium/cmd/backfill"
@@ -12,6 +13,8 @@ import (
 	"github.com/caesium-cloud/caesium/cmd/event"
 	"github.com/caesium-cloud/caesium/cmd/incident"
 	"github.com/caesium-cloud/caesium/cmd/job"
+	"github.com/caesium-cloud/caesium/cmd/node"
+	"github.com/caesium-cloud/caesium/cmd/pool"
 	"github.com/caesium-cloud/caesium/cmd/receipt"
 	"github.com/caesium-cloud/caesium/cmd/reproduce"
 	"github.com/caesium-cloud/caesium/cmd/run"
@@ -24,6 +27,7 @@ import (
 )
 
 var cmds = []*cobra.Command{
+	admin.Cmd,
 	agentprofile.Cmd,
 	auth.Cmd,
 	backfill.Cmd,
@@ -36,6 +40,8 @@ var cmds = []*cobra.Command{
 	event.Cmd,
 	incident.Cmd,
 	job.Cmd,
+	node.Cmd,
+	pool.Cmd,
 	receipt.Cmd,
 	run.Cmd,
 	start.Cmd,
@@ -89,7 +89,10 @@ func collectDefinitions(paths []string) ([]schema.Definition, error) {
 		paths = []string{"."}
 	}
 
-	var defs []schema.Definition
+	var (
+		docs      []fileDefinition
+		templates = schema.NewTemplateRegistry()
+	)
 	for _, p := range paths {
 		info, err := os.Stat(p)
 		if err != nil {
@@ -106,7 +109,7 @@ func collectDefinitions(paths []string) ([]schema.Definition, error) {
 				if !isYAML(path) {
 					return nil
 				}
-				return appendDefinitions(path, &defs)
+				return appendDefinitions(path, &docs, templates)
 			}); err != nil {
 				return nil, err
 			}
@@ -114,15 +117,32 @@ func collectDefinitions(paths []string) ([]schema.Definition, error) {
 			if !isYAML(p) {
 				return nil, fmt.Errorf("%s is not a YAML file", p)
 			}
-			if err := appendDefinitions(p, &defs); err != nil {
+			if err := appendDefinitions(p, &docs, templates); err != nil {
 				return nil, err
 			}
 		}
 	}
+
+	defs := make([]schema.Definition, 0, len(docs))
+	for i := range docs {
+		def := &docs[i].def
+		if err := def.ExpandTemplates(templates); err != nil {
+			return nil, fmt.Errorf("%s: %w", docs[i].path, err)
+		}
+		if err := def.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", docs[i].path, err)
+		}
+		defs = append(defs, *def)
+	}
 	return defs, nil
 }
 
-func appendDefinitions(path string, defs *[]schema.Definition) error {
+type fileDefinition struct {
+	path string
+	def  schema.Definition
+}
+
+func appendDefinitions(path string, docs *[]fileDefinition, templates *schema.TemplateRegistry) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -130,20 +150,31 @@ func appendDefinitions(path string, defs *[]schema.Definition) error {
 
 	dec := yaml.NewDecoder(bytes.NewReader(data))
 	for {
-		var def schema.Definition
-		if err := dec.Decode(&def); err != nil {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
 			return fmt.Errorf("%s: %w", path, err)
 		}
-		if isBlankDefinition(&def) {
+		tmpl, err := schema.DecodeStepTemplate(&node)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if tmpl != nil {
+			if err := templates.Add(tmpl); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
 			continue
 		}
-		if err := def.Validate(); err != nil {
+		var def schema.Definition
+		if err := node.Decode(&def); err != nil {
 			return fmt.Errorf("%s: %w", path, err)
 		}
-		*defs = append(*defs, def)
+		if isBlankDefinition(&def) {
+			continue
+		}
+		*docs = append(*docs, fileDefinition{path: path, def: def})
 	}
 
 	return nil
@@ -0,0 +1,54 @@
+package job
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteAPI cliutil.APIFlags
+	deleteYes bool
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <job>",
+	Short: "Delete a job",
+	Long: "Delete a job, and its trigger when no other job shares it. The command asks for confirmation unless --yes is set. " +
+		"A job that is still declared in a manifest comes back on the next apply.",
+	Args: cobra.ExactArgs(1),
+	RunE: runDelete,
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	c := deleteAPI.Client(cmd)
+	job, err := c.ResolveJob(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	if !deleteYes {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Delete job %s/%s (%s)? [y/N] ", job.Namespace, job.Alias, job.ID)
+		answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			return fmt.Errorf("delete cancelled")
+		}
+	}
+
+	if err := c.DeleteJob(cmd.Context(), job.ID); err != nil {
+		return err
+	}
+	return writeCmdOut(cmd, "deleted job %s (%s)\n", job.Alias, job.ID)
+}
+
+func init() {
+	deleteAPI.Register(deleteCmd)
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	Cmd.AddCommand(deleteCmd)
+}
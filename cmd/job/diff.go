@@ -51,9 +51,9 @@ func printDiff(cmd *cobra.Command, diff jobdiff.Diff) {
 
 	if len(diff.Creates) > 0 {
 		writeLine(cmd, out, "Creates:\n")
-		slices.SortFunc(diff.Creates, func(a, b jobdiff.JobSpec) int { return cmp.Compare(a.Alias, b.Alias) })
+		slices.SortFunc(diff.Creates, func(a, b jobdiff.JobSpec) int { return cmp.Compare(a.Key(), b.Key()) })
 		for _, spec := range diff.Creates {
-			writeLine(cmd, out, "  - %s\n", spec.Alias)
+			writeLine(cmd, out, "  - %s\n", spec.Key())
 		}
 		writeLine(cmd, out, "\n")
 	}
@@ -71,9 +71,9 @@ func printDiff(cmd *cobra.Command, diff jobdiff.Diff) {
 
 	if len(diff.Deletes) > 0 {
 		writeLine(cmd, out, "Deletes:\n")
-		slices.SortFunc(diff.Deletes, func(a, b jobdiff.JobSpec) int { return cmp.Compare(a.Alias, b.Alias) })
+		slices.SortFunc(diff.Deletes, func(a, b jobdiff.JobSpec) int { return cmp.Compare(a.Key(), b.Key()) })
 		for _, spec := range diff.Deletes {
-			writeLine(cmd, out, "  - %s\n", spec.Alias)
+			writeLine(cmd, out, "  - %s\n", spec.Key())
 		}
 	}
 }
@@ -0,0 +1,87 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getAPI    cliutil.APIFlags
+	getOutput string
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <job>",
+	Short: "Show a job, its trigger, and its latest run",
+	Long:  "Show one job. <job> is an alias, namespace/alias, ID, or unambiguous ID prefix.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGet,
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	if err := cliutil.ValidateOutput(getOutput); err != nil {
+		return err
+	}
+
+	job, err := getAPI.Client(cmd).ResolveJob(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+
+	return cliutil.WriteOutput(cmd, getOutput, job, func(out io.Writer) error {
+		return renderJob(out, job)
+	})
+}
+
+func renderJob(out io.Writer, job *client.Job) error {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "ID:\t%s\n", job.ID)
+	_, _ = fmt.Fprintf(tw, "Namespace:\t%s\n", job.Namespace)
+	_, _ = fmt.Fprintf(tw, "Alias:\t%s\n", job.Alias)
+	_, _ = fmt.Fprintf(tw, "Paused:\t%t\n", job.Paused)
+	_, _ = fmt.Fprintf(tw, "Labels:\t%s\n", formatLabels(job.Labels))
+	if job.Trigger != nil {
+		_, _ = fmt.Fprintf(tw, "Trigger:\t%s (%s, %s)\n", dashIfEmpty(job.Trigger.Alias), job.Trigger.Type, job.Trigger.ID)
+	} else {
+		_, _ = fmt.Fprintf(tw, "Trigger:\t%s\n", job.TriggerID)
+	}
+	if run := job.LatestRun; run != nil {
+		_, _ = fmt.Fprintf(tw, "Latest run:\t%s %s at %s\n", run.ID, run.Status, run.StartedAt.UTC().Format(time.RFC3339))
+	} else {
+		_, _ = fmt.Fprintln(tw, "Latest run:\t-")
+	}
+	_, _ = fmt.Fprintf(tw, "Created:\t%s\n", job.CreatedAt.UTC().Format(time.RFC3339))
+	return tw.Flush()
+}
+
+func formatLabels(labels map[string]interface{}) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, labels[key]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func init() {
+	getAPI.Register(getCmd)
+	cliutil.AddOutputFlag(getCmd, &getOutput)
+
+	Cmd.AddCommand(getCmd)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/jobdef/secret"
 	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -34,6 +35,7 @@ var (
 	lintServer           string
 	lintAPIKey           string
 	lintJSON             bool
+	lintShowResolved     bool
 
 	lintHTTPClient = &http.Client{Timeout: cliutil.DefaultHTTPTimeout}
 )
@@ -72,6 +74,11 @@ var lintCmd = &cobra.Command{
 		if err := internaljobdef.ValidateDatasetGraph(cmd.Context(), nil, defs); err != nil {
 			return err
 		}
+		if lintShowResolved {
+			if err := writeResolvedDefinitions(cmd, defs); err != nil {
+				return err
+			}
+		}
 
 		if !lintCheckSecrets {
 			if err := writeCmdOut(cmd, "Validated %d job definition(s)\n", len(defs)); err != nil {
@@ -138,10 +145,31 @@ func init() {
 	}
 	lintCmd.Flags().StringVar(&lintAPIKey, "api-key", "", "API key for authentication (prefer "+cliutil.APIKeyEnvVar+"; --api-key is visible in process listings)")
 	lintCmd.Flags().BoolVar(&lintJSON, "json", false, "Print server lint JSON (requires --server)")
+	lintCmd.Flags().BoolVar(&lintShowResolved, "show-resolved", false, "Print each definition with StepTemplate references expanded inline")
 
 	Cmd.AddCommand(lintCmd)
 }
 
+// writeResolvedDefinitions prints each definition as YAML after template
+// expansion, i.e. exactly the steps the server will store and hash.
+func writeResolvedDefinitions(cmd *cobra.Command, defs []jobdef.Definition) error {
+	for _, def := range defs {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(def); err != nil {
+			return fmt.Errorf("definition %s: %w", def.Metadata.Alias, err)
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+		if err := writeCmdOut(cmd, "---\n%s", buf.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // LoadDefinitions exposes the same manifest loader used by `caesium job lint`
 // for sibling CLI groups that need to lint the exact local path set.
 func LoadDefinitions(paths []string) ([]jobdef.Definition, error) {
@@ -0,0 +1,86 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listAPI     cliutil.APIFlags
+	listOutput  string
+	listLimit   uint64
+	listOffset  uint64
+	listTrigger string
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List jobs with their latest run",
+	Args:  cobra.NoArgs,
+	RunE:  runList,
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	if err := cliutil.ValidateOutput(listOutput); err != nil {
+		return err
+	}
+
+	jobs, err := listAPI.Client(cmd).ListJobs(cmd.Context(), &client.ListJobsRequest{
+		Limit:     listLimit,
+		Offset:    listOffset,
+		OrderBy:   []string{"namespace", "alias"},
+		TriggerID: listTrigger,
+	})
+	if err != nil {
+		return err
+	}
+
+	return cliutil.WriteOutput(cmd, listOutput, jobs, func(out io.Writer) error {
+		return renderJobList(out, jobs)
+	})
+}
+
+func renderJobList(out io.Writer, jobs []*client.Job) error {
+	if len(jobs) == 0 {
+		_, err := fmt.Fprintln(out, "No jobs.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAMESPACE\tALIAS\tPAUSED\tLAST_RUN\tLAST_RUN_AT")
+	for _, job := range jobs {
+		if job == nil {
+			continue
+		}
+		status, startedAt := "-", "-"
+		if job.LatestRun != nil {
+			status = job.LatestRun.Status
+			startedAt = job.LatestRun.StartedAt.UTC().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			job.ID,
+			job.Namespace,
+			job.Alias,
+			job.Paused,
+			status,
+			startedAt,
+		)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	listAPI.Register(listCmd)
+	cliutil.AddOutputFlag(listCmd, &listOutput)
+	listCmd.Flags().Uint64Var(&listLimit, "limit", 0, "Maximum number of jobs to return")
+	listCmd.Flags().Uint64Var(&listOffset, "offset", 0, "Number of jobs to skip")
+	listCmd.Flags().StringVar(&listTrigger, "trigger-id", "", "Only list jobs fired by this trigger")
+
+	Cmd.AddCommand(listCmd)
+}
@@ -0,0 +1,85 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobListRendersTableAndYAML(t *testing.T) {
+	originalAPI, originalOutput := listAPI, listOutput
+	t.Cleanup(func() { listAPI, listOutput = originalAPI, originalOutput })
+
+	jobID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/jobs", r.URL.Path)
+		_, _ = fmt.Fprintf(w, `[{"id":%q,"namespace":"default","alias":"nightly","paused":true,"latest_run":{"status":"failed","started_at":"2026-01-01T00:00:00Z"}}]`, jobID)
+	}))
+	defer server.Close()
+	listAPI.Server = server.URL
+
+	listOutput = "table"
+	cmd, stdout := newJobTestCommand()
+	require.NoError(t, runList(cmd, nil))
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Regexp(t, `^ID\s+NAMESPACE\s+ALIAS\s+PAUSED\s+LAST_RUN\s+LAST_RUN_AT$`, lines[0])
+	require.Regexp(t, "^"+jobID.String()+`\s+default\s+nightly\s+true\s+failed\s+2026-01-01T00:00:00Z$`, lines[1])
+
+	listOutput = "yaml"
+	cmd, stdout = newJobTestCommand()
+	require.NoError(t, runList(cmd, nil))
+	require.Contains(t, stdout.String(), "- id: "+jobID.String()+"\n")
+	require.Contains(t, stdout.String(), "  alias: nightly\n")
+	require.Contains(t, stdout.String(), "  latest_run:\n")
+}
+
+func TestJobDeleteAsksForConfirmation(t *testing.T) {
+	originalAPI, originalYes := deleteAPI, deleteYes
+	t.Cleanup(func() { deleteAPI, deleteYes = originalAPI, originalYes })
+
+	jobID := uuid.New()
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			require.Equal(t, "/v1/jobs/"+jobID.String(), r.URL.Path)
+			deletes++
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/v1/jobs":
+			_, _ = fmt.Fprintf(w, `[{"id":%q,"namespace":"default","alias":"nightly"}]`, jobID)
+		default:
+			_, _ = fmt.Fprintf(w, `{"id":%q,"namespace":"default","alias":"nightly"}`, jobID)
+		}
+	}))
+	defer server.Close()
+	deleteAPI.Server = server.URL
+	deleteYes = false
+
+	cmd, _ := newJobTestCommand()
+	cmd.SetIn(strings.NewReader("n\n"))
+	require.EqualError(t, runDelete(cmd, []string{"nightly"}), "delete cancelled")
+	require.Zero(t, deletes)
+
+	cmd, stdout := newJobTestCommand()
+	cmd.SetIn(strings.NewReader("y\n"))
+	require.NoError(t, runDelete(cmd, []string{"nightly"}))
+	require.Equal(t, 1, deletes)
+	require.Contains(t, stdout.String(), "deleted job nightly ("+jobID.String()+")\n")
+}
+
+func newJobTestCommand() (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	return cmd, &stdout
+}
@@ -0,0 +1,72 @@
+package job
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pauseAPI    cliutil.APIFlags
+	pauseOutput string
+
+	unpauseAPI    cliutil.APIFlags
+	unpauseOutput string
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause <job>",
+	Short: "Pause a job so its trigger stops starting runs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPaused(cmd, &pauseAPI, pauseOutput, args[0], true)
+	},
+}
+
+var unpauseCmd = &cobra.Command{
+	Use:   "unpause <job>",
+	Short: "Resume a paused job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPaused(cmd, &unpauseAPI, unpauseOutput, args[0], false)
+	},
+}
+
+func setPaused(cmd *cobra.Command, api *cliutil.APIFlags, output, ref string, paused bool) error {
+	if err := cliutil.ValidateOutput(output); err != nil {
+		return err
+	}
+
+	c := api.Client(cmd)
+	job, err := c.ResolveJob(cmd.Context(), ref)
+	if err != nil {
+		return err
+	}
+
+	update := c.UnpauseJob
+	verb := "unpaused"
+	if paused {
+		update = c.PauseJob
+		verb = "paused"
+	}
+	updated, err := update(cmd.Context(), job.ID)
+	if err != nil {
+		return err
+	}
+
+	return cliutil.WriteOutput(cmd, output, updated, func(out io.Writer) error {
+		_, err := fmt.Fprintf(out, "%s job %s (%s)\n", verb, updated.Alias, updated.ID)
+		return err
+	})
+}
+
+func init() {
+	pauseAPI.Register(pauseCmd)
+	cliutil.AddOutputFlag(pauseCmd, &pauseOutput)
+	unpauseAPI.Register(unpauseCmd)
+	cliutil.AddOutputFlag(unpauseCmd, &unpauseOutput)
+
+	Cmd.AddCommand(pauseCmd, unpauseCmd)
+}
@@ -0,0 +1,44 @@
+package node
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var cordonCmd = &cobra.Command{
+	Use:   "cordon <address>",
+	Short: "Stop a node from taking new tasks",
+	Long: "Stop a node from claiming tasks and from receiving dispatches. " +
+		"Tasks it is already running keep running. Undo with `caesium node uncordon`.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reqURL, err := nodeURL(args[0], "cordon")
+		if err != nil {
+			return err
+		}
+		if _, err := request(cmd, http.MethodPut, reqURL, nil); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "cordoned node %s\n", args[0])
+		return err
+	},
+}
+
+var uncordonCmd = &cobra.Command{
+	Use:   "uncordon <address>",
+	Short: "Let a cordoned or draining node take tasks again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reqURL, err := nodeURL(args[0], "uncordon")
+		if err != nil {
+			return err
+		}
+		if _, err := request(cmd, http.MethodPut, reqURL, nil); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "uncordoned node %s\n", args[0])
+		return err
+	},
+}
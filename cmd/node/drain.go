@@ -0,0 +1,97 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	drainTimeout      time.Duration
+	drainWait         bool
+	drainPollInterval = 2 * time.Second
+)
+
+var drainCmd = &cobra.Command{
+	Use:   "drain <address> [--timeout 10m] [--wait]",
+	Short: "Cordon a node and hand its running tasks back",
+	Long: "Cordon a node and wait for its running tasks to finish. Once --timeout passes, " +
+		"the node releases the claims of tasks still running so other nodes take them over " +
+		"without waiting for their leases to expire. Without --timeout the node waits for " +
+		"its tasks indefinitely. --wait blocks until the node has no running tasks left.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if drainTimeout < 0 {
+			return fmt.Errorf("--timeout must be greater than or equal to 0")
+		}
+		address := strings.TrimSpace(args[0])
+		reqURL, err := nodeURL(address, "drain")
+		if err != nil {
+			return err
+		}
+
+		req := map[string]string{}
+		if drainTimeout > 0 {
+			req["timeout"] = drainTimeout.String()
+		}
+		payload, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		if _, err := request(cmd, http.MethodPut, reqURL, bytes.NewReader(payload)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "draining node %s\n", address); err != nil {
+			return err
+		}
+		if !drainWait {
+			return nil
+		}
+		return waitDrained(cmd, address)
+	},
+}
+
+// waitDrained polls the node list until address reports no running tasks.
+func waitDrained(cmd *cobra.Command, address string) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		nodes, _, err := listNodes(cmd)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, n := range nodes {
+			if n.Address != address {
+				continue
+			}
+			found = true
+			if n.State != "draining" {
+				return fmt.Errorf("node %s is %s; drain was cancelled", address, n.State)
+			}
+			if n.Drained {
+				_, err := fmt.Fprintf(cmd.OutOrStdout(), "node %s drained\n", address)
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("node %s not found", address)
+		}
+
+		select {
+		case <-cmd.Context().Done():
+			return cmd.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func init() {
+	drainCmd.Flags().DurationVar(&drainTimeout, "timeout", 0, "How long to wait for running tasks before releasing their claims (0 waits indefinitely)")
+	drainCmd.Flags().BoolVar(&drainWait, "wait", false, "Block until the node has no running tasks left")
+}
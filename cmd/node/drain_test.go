@@ -0,0 +1,85 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func newNodeTestCommand() (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	stdout := &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	return cmd, stdout
+}
+
+func TestDrainWaitsUntilNodeIsDrained(t *testing.T) {
+	originalServer, originalTimeout, originalWait, originalPoll := serverFlag, drainTimeout, drainWait, drainPollInterval
+	t.Cleanup(func() {
+		serverFlag, drainTimeout, drainWait, drainPollInterval = originalServer, originalTimeout, originalWait, originalPoll
+	})
+
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/system/nodes/node-a:9001/drain":
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, "10m0s", req["timeout"])
+			_, _ = w.Write([]byte(`{"address":"node-a:9001","state":"draining"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/system/nodes":
+			busy, drained := 1, false
+			if polls.Add(1) > 1 {
+				busy, drained = 0, true
+			}
+			require.NoError(t, json.NewEncoder(w).Encode([]nodeState{
+				{Address: "node-a:9001", State: "draining", WorkersBusy: busy, Drained: drained},
+				{Address: "node-b:9001", State: "active", WorkersBusy: 2},
+			}))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	serverFlag = server.URL
+	drainTimeout = 10 * time.Minute
+	drainWait = true
+	drainPollInterval = time.Millisecond
+
+	cmd, stdout := newNodeTestCommand()
+	require.NoError(t, drainCmd.RunE(cmd, []string{"node-a:9001"}))
+	require.Equal(t, "draining node node-a:9001\nnode node-a:9001 drained\n", stdout.String())
+	require.EqualValues(t, 2, polls.Load())
+}
+
+func TestDrainWaitFailsWhenUncordoned(t *testing.T) {
+	originalServer, originalWait, originalPoll := serverFlag, drainWait, drainPollInterval
+	t.Cleanup(func() { serverFlag, drainWait, drainPollInterval = originalServer, originalWait, originalPoll })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`[{"address":"node-a:9001","state":"active","workers_busy":1}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	serverFlag = server.URL
+	drainWait = true
+	drainPollInterval = time.Millisecond
+
+	cmd, _ := newNodeTestCommand()
+	require.ErrorContains(t, drainCmd.RunE(cmd, []string{"node-a:9001"}), "drain was cancelled")
+}
@@ -0,0 +1,81 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+const apiKeyEnvVar = cliutil.APIKeyEnvVar
+
+var httpClient = &http.Client{Timeout: cliutil.DefaultHTTPTimeout}
+
+// nodeState mirrors an entry of GET /v1/system/nodes.
+type nodeState struct {
+	Address       string     `json:"address"`
+	Role          string     `json:"role"`
+	WorkersBusy   int        `json:"workers_busy"`
+	WorkersTotal  int        `json:"workers_total"`
+	State         string     `json:"state"`
+	Drained       bool       `json:"drained"`
+	DrainDeadline *time.Time `json:"drain_deadline,omitempty"`
+}
+
+func request(cmd *cobra.Command, method, reqURL string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(cmd.Context(), method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey := cliutil.ResolveAPIKey(cmd, apiKeyFlag, apiKeyEnvVar); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading node response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("node request failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func serverBase() string {
+	return strings.TrimSuffix(serverFlag, "/")
+}
+
+func nodeURL(address, action string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", fmt.Errorf("node address is required")
+	}
+	return serverBase() + "/v1/system/nodes/" + url.PathEscape(address) + "/" + action, nil
+}
+
+func listNodes(cmd *cobra.Command) ([]nodeState, []byte, error) {
+	body, err := request(cmd, http.MethodGet, serverBase()+"/v1/system/nodes", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var nodes []nodeState
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		return nil, nil, fmt.Errorf("nodes response was not valid JSON: %w", err)
+	}
+	return nodes, body, nil
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var listJSON bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cluster nodes with their scheduling state and running tasks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodes, body, err := listNodes(cmd)
+		if err != nil {
+			return err
+		}
+		if listJSON {
+			return cliutil.WritePrettyJSON(cmd, body, "nodes")
+		}
+		renderNodeList(cmd, nodes)
+		return nil
+	},
+}
+
+func renderNodeList(cmd *cobra.Command, rows []nodeState) {
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Address < rows[j].Address })
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ADDRESS\tROLE\tSTATE\tRUNNING\tDRAIN DEADLINE")
+	for _, row := range rows {
+		state := row.State
+		if row.Drained {
+			state = "drained"
+		}
+		deadline := "-"
+		if row.DrainDeadline != nil {
+			deadline = row.DrainDeadline.UTC().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\n",
+			row.Address,
+			row.Role,
+			state,
+			row.WorkersBusy,
+			row.WorkersTotal,
+			deadline,
+		)
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Print JSON")
+}
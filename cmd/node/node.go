@@ -0,0 +1,20 @@
+package node
+
+import "github.com/spf13/cobra"
+
+var (
+	serverFlag string
+	apiKeyFlag string
+)
+
+// Cmd is the root `caesium node` command group.
+var Cmd = &cobra.Command{
+	Use:   "node",
+	Short: "List cluster nodes and cordon or drain them for maintenance",
+}
+
+func init() {
+	Cmd.PersistentFlags().StringVar(&serverFlag, "server", "http://localhost:8080", "Caesium server base URL")
+	Cmd.PersistentFlags().StringVar(&apiKeyFlag, "api-key", "", "API key for authentication (prefer "+apiKeyEnvVar+"; --api-key is visible in process listings)")
+	Cmd.AddCommand(listCmd, cordonCmd, drainCmd, uncordonCmd)
+}
@@ -0,0 +1,26 @@
+package pool
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a task pool",
+	Long:  "Delete a task pool. Tasks that still reference it wait for admission until the pool is defined again.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reqURL, err := poolURL(args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := request(cmd, http.MethodDelete, reqURL, nil); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "deleted pool %s\n", args[0])
+		return err
+	},
+}
@@ -0,0 +1,25 @@
+package pool
+
+import (
+	"net/http"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Show a task pool and its current occupancy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reqURL, err := poolURL(args[0])
+		if err != nil {
+			return err
+		}
+		body, err := request(cmd, http.MethodGet, reqURL, nil)
+		if err != nil {
+			return err
+		}
+		return cliutil.WritePrettyJSON(cmd, body, "pool")
+	},
+}
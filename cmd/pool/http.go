@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+const apiKeyEnvVar = cliutil.APIKeyEnvVar
+
+var httpClient = &http.Client{Timeout: cliutil.DefaultHTTPTimeout}
+
+func request(cmd *cobra.Command, method, reqURL string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(cmd.Context(), method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey := cliutil.ResolveAPIKey(cmd, apiKeyFlag, apiKeyEnvVar); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading pool response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("pool request failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func serverBase() string {
+	return strings.TrimSuffix(serverFlag, "/")
+}
+
+func poolURL(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("pool name is required")
+	}
+	return serverBase() + "/v1/pools/" + url.PathEscape(name), nil
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/tabwriter"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var listJSON bool
+
+type poolState struct {
+	Name          string `json:"name"`
+	Slots         int    `json:"slots"`
+	Description   string `json:"description,omitempty"`
+	OccupiedSlots int    `json:"occupied_slots"`
+	RunningTasks  int    `json:"running_tasks"`
+	QueuedTasks   int    `json:"queued_tasks"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List task pools with their current occupancy",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		body, err := request(cmd, http.MethodGet, serverBase()+"/v1/pools", nil)
+		if err != nil {
+			return err
+		}
+		if listJSON {
+			return cliutil.WritePrettyJSON(cmd, body, "pools")
+		}
+
+		var pools []poolState
+		if err := json.Unmarshal(body, &pools); err != nil {
+			return fmt.Errorf("pools response was not valid JSON: %w", err)
+		}
+		renderPoolList(cmd, pools)
+		return nil
+	},
+}
+
+func renderPoolList(cmd *cobra.Command, rows []poolState) {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tSLOTS\tOCCUPIED\tRUNNING\tQUEUED\tDESCRIPTION")
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n",
+			row.Name,
+			row.Slots,
+			row.OccupiedSlots,
+			row.RunningTasks,
+			row.QueuedTasks,
+			row.Description,
+		)
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Print JSON")
+}
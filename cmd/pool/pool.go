@@ -0,0 +1,20 @@
+package pool
+
+import "github.com/spf13/cobra"
+
+var (
+	serverFlag string
+	apiKeyFlag string
+)
+
+// Cmd is the root `caesium pool` command group.
+var Cmd = &cobra.Command{
+	Use:   "pool",
+	Short: "Manage cluster-wide task pools",
+}
+
+func init() {
+	Cmd.PersistentFlags().StringVar(&serverFlag, "server", "http://localhost:8080", "Caesium server base URL")
+	Cmd.PersistentFlags().StringVar(&apiKeyFlag, "api-key", "", "API key for authentication (prefer "+apiKeyEnvVar+"; --api-key is visible in process listings)")
+	Cmd.AddCommand(listCmd, getCmd, setCmd, deleteCmd)
+}
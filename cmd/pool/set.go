@@ -0,0 +1,51 @@
+package pool
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	setSlots       int
+	setDescription string
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set <name> --slots <n> [--description <text>]",
+	Short: "Create or resize a task pool",
+	Long: "Create or resize a task pool. Shrinking a pool never stops running tasks; " +
+		"new tasks wait until occupancy drops below the new size. --slots 0 pauses the pool.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("slots") {
+			return fmt.Errorf("--slots is required")
+		}
+		if setSlots < 0 {
+			return fmt.Errorf("--slots must be greater than or equal to 0")
+		}
+		reqURL, err := poolURL(args[0])
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(map[string]any{"slots": setSlots, "description": setDescription})
+		if err != nil {
+			return err
+		}
+		body, err := request(cmd, http.MethodPut, reqURL, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		return cliutil.WritePrettyJSON(cmd, body, "pool")
+	},
+}
+
+func init() {
+	setCmd.Flags().IntVar(&setSlots, "slots", 0, "Number of slots the pool admits concurrently")
+	setCmd.Flags().StringVar(&setDescription, "description", "", "Human-readable pool description")
+}
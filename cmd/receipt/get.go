@@ -55,11 +55,11 @@ var getCmd = &cobra.Command{
 		if err := json.Unmarshal(body, &r); err != nil {
 			// Server returned something unexpected; surface it verbatim rather
 			// than swallowing it.
-			return writeOut(cmd, body)
+			return writeOut(cmd, getOutput, "receipt", body)
 		}
 		pretty, err := json.MarshalIndent(&r, "", "  ")
 		if err != nil {
-			return writeOut(cmd, body)
+			return writeOut(cmd, getOutput, "receipt", body)
 		}
 		pretty = append(pretty, '\n')
 
@@ -70,20 +70,25 @@ var getCmd = &cobra.Command{
 				"warning: receipt is DEGRADED — %d task(s) ran on unpinned image tags and are not verifiable: %s\n",
 				len(r.DegradedTasks), strings.Join(r.DegradedTasks, ", "))
 		}
-		return writeOut(cmd, pretty)
+		if len(r.Signatures) == 0 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(),
+				"warning: receipt is UNSIGNED — the server has no receipt signing key, so its issuer cannot be verified")
+		}
+		return writeOut(cmd, getOutput, "receipt", pretty)
 	},
 }
 
-// writeOut writes the receipt bytes to --output (if set) or stdout.
-func writeOut(cmd *cobra.Command, data []byte) error {
-	if getOutput == "" {
+// writeOut writes data to output (if set) or stdout; what names the artifact
+// in the confirmation message.
+func writeOut(cmd *cobra.Command, output, what string, data []byte) error {
+	if output == "" {
 		_, err := cmd.OutOrStdout().Write(data)
 		return err
 	}
-	if err := os.WriteFile(getOutput, data, 0o644); err != nil {
-		return fmt.Errorf("write receipt to %s: %w", getOutput, err)
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("write %s to %s: %w", what, output, err)
 	}
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote receipt to %s\n", getOutput)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s to %s\n", what, output)
 	return nil
 }
 
@@ -0,0 +1,78 @@
+package receipt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	ireceipt "github.com/caesium-cloud/caesium/internal/receipt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	provenanceJobID  string
+	provenanceRunID  string
+	provenanceServer string
+	provenanceOutput string
+)
+
+var provenanceCmd = &cobra.Command{
+	Use:   "provenance",
+	Short: "Export a run as a signed SLSA provenance attestation",
+	Long: "Fetch a run's in-toto SLSA v1 provenance attestation from the server " +
+		"as a DSSE envelope: the run's receipt is the subject, and each task's " +
+		"image digest, the manifest hash, and the git commit are its resolved " +
+		"dependencies. The envelope is signed with the cluster's receipt signing " +
+		"key, so tools that consume SLSA provenance can verify it against the " +
+		"published public keys.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if provenanceJobID == "" || provenanceRunID == "" {
+			return fmt.Errorf("--job-id and --run-id are required")
+		}
+
+		server := strings.TrimSuffix(provenanceServer, "/")
+		url := fmt.Sprintf("%s/v1/jobs/%s/runs/%s/provenance", server, provenanceJobID, provenanceRunID)
+
+		req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("provenance export failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		var envelope ireceipt.Envelope
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return writeOut(cmd, provenanceOutput, "provenance", body)
+		}
+		pretty, err := json.MarshalIndent(&envelope, "", "  ")
+		if err != nil {
+			return writeOut(cmd, provenanceOutput, "provenance", body)
+		}
+		pretty = append(pretty, '\n')
+
+		if len(envelope.Signatures) == 0 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(),
+				"warning: provenance is UNSIGNED — the server has no receipt signing key")
+		}
+		return writeOut(cmd, provenanceOutput, "provenance", pretty)
+	},
+}
+
+func init() {
+	provenanceCmd.Flags().StringVar(&provenanceJobID, "job-id", "", "Job ID (required)")
+	provenanceCmd.Flags().StringVar(&provenanceRunID, "run-id", "", "Run ID (required)")
+	provenanceCmd.Flags().StringVar(&provenanceServer, "server", "http://localhost:8080", "Caesium server base URL")
+	provenanceCmd.Flags().StringVarP(&provenanceOutput, "output", "o", "", "Write the DSSE envelope to this file (default: stdout)")
+	Cmd.AddCommand(provenanceCmd)
+}
@@ -1,5 +1,6 @@
 // Package receipt is the `caesium receipt` CLI command group: produce a
-// content-addressed, git-committable reproducibility receipt for a run. The
+// content-addressed, git-committable reproducibility receipt for a run, or
+// export the run as signed SLSA provenance. The
 // companion `caesium verify` command (cmd/verify) re-derives a committed
 // receipt against a run's persisted state and flags drift.
 package receipt
@@ -13,5 +14,6 @@ var Cmd = &cobra.Command{
 	Long: "Produce a content-addressed, git-committable reproducibility receipt " +
 		"for a job run. Commit the receipt alongside your pipeline; later, " +
 		"`caesium verify <receipt>` re-derives it from the run's persisted state " +
-		"and flags drift (e.g. a moved image tag).",
+		"and flags drift (e.g. a moved image tag). Receipts are signed when the " +
+		"server has a receipt signing key.",
 }
@@ -0,0 +1,92 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelJobID  string
+	cancelServer string
+	cancelAPIKey string
+	cancelJSON   bool
+
+	cancelHTTPClient = &http.Client{Timeout: cliutil.DefaultHTTPTimeout}
+)
+
+var cancelCmd = &cobra.Command{
+	Use:   "cancel <run-id> --job-id <job-id>",
+	Short: "Cancel a running job run",
+	Long: "Cancel a running run through the job-scoped cancel REST endpoint. " +
+		"Pending tasks are marked cancelled and in-flight atoms are stopped on " +
+		"whichever node holds the task claim.",
+	Args: cobra.ExactArgs(1),
+	RunE: runCancel,
+}
+
+func runCancel(cmd *cobra.Command, args []string) error {
+	runID := strings.TrimSpace(args[0])
+	jobID := strings.TrimSpace(cancelJobID)
+	if jobID == "" {
+		return fmt.Errorf("--job-id is required")
+	}
+
+	body, err := postCancel(cmd, jobID, runID)
+	if err != nil {
+		return err
+	}
+	if cancelJSON {
+		return cliutil.WritePrettyJSON(cmd, body, "run cancel response")
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "cancelled run %s\n", runID)
+	return nil
+}
+
+func postCancel(cmd *cobra.Command, jobID, runID string) ([]byte, error) {
+	server := strings.TrimSuffix(cancelServer, "/")
+	reqURL := fmt.Sprintf("%s/v1/jobs/%s/runs/%s/cancel", server, url.PathEscape(jobID), url.PathEscape(runID))
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey := resolveRunDiffAPIKey(cmd, cancelAPIKey); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := cancelHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading run cancel response: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return respBody, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("run not found (404): %s", replayErrorMessage(respBody))
+	case http.StatusConflict:
+		return nil, fmt.Errorf("run cancel refused (409): %s", replayErrorMessage(respBody))
+	default:
+		return nil, fmt.Errorf("run cancel failed (%d): %s", resp.StatusCode, replayErrorMessage(respBody))
+	}
+}
+
+func init() {
+	cancelCmd.Flags().StringVar(&cancelJobID, "job-id", "", "Job ID that owns the run (required)")
+	cancelCmd.Flags().StringVar(&cancelServer, "server", "http://localhost:8080", "Caesium server base URL")
+	cancelCmd.Flags().StringVar(&cancelAPIKey, "api-key", "", "API key for authentication (prefer "+runDiffAPIKeyEnvVar+"; --api-key is visible in process listings)")
+	cancelCmd.Flags().BoolVar(&cancelJSON, "json", false, "Emit the cancelled run as JSON")
+	cancelCmd.MarkFlagRequired("job-id") //nolint:errcheck
+
+	Cmd.AddCommand(cancelCmd)
+}
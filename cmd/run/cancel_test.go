@@ -0,0 +1,82 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCancelPostsToCancelEndpoint(t *testing.T) {
+	restoreCancelTestGlobals(t)
+
+	const (
+		jobID = "job-1"
+		runID = "run-1"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v1/jobs/"+jobID+"/runs/"+runID+"/cancel", r.URL.Path)
+		require.Equal(t, "Bearer secret-key", r.Header.Get("Authorization"))
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id":"` + runID + `","status":"cancelled"}`))
+	}))
+	defer server.Close()
+
+	cancelJobID = jobID
+	cancelServer = server.URL
+	cancelAPIKey = "secret-key"
+	cancelJSON = false
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	require.NoError(t, runCancel(cmd, []string{runID}))
+	require.Equal(t, "cancelled run "+runID+"\n", stdout.String())
+	require.Contains(t, stderr.String(), "warning: --api-key is visible in process listings")
+}
+
+func TestRunCancelReportsConflictForFinishedRun(t *testing.T) {
+	restoreCancelTestGlobals(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"run is succeeded"}`))
+	}))
+	defer server.Close()
+
+	cancelJobID = "job-1"
+	cancelServer = server.URL
+	cancelAPIKey = ""
+	cancelJSON = false
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+
+	err := runCancel(cmd, []string{"run-1"})
+	require.EqualError(t, err, "run cancel refused (409): run is succeeded")
+}
+
+func restoreCancelTestGlobals(t *testing.T) {
+	t.Helper()
+	originalJobID := cancelJobID
+	originalServer := cancelServer
+	originalAPIKey := cancelAPIKey
+	originalJSON := cancelJSON
+	originalClient := cancelHTTPClient
+	t.Cleanup(func() {
+		cancelJobID = originalJobID
+		cancelServer = originalServer
+		cancelAPIKey = originalAPIKey
+		cancelJSON = originalJSON
+		cancelHTTPClient = originalClient
+	})
+}
@@ -60,6 +60,19 @@ type runDiffTask struct {
 	HashEqual bool                 `json:"hashEqual"`
 	Changes   []runDiffFieldChange `json:"changes,omitempty"`
 	Degraded  string               `json:"degraded,omitempty"`
+
+	Instances    []runDiffInstance `json:"instances,omitempty"`
+	ItemsAdded   []string          `json:"itemsAdded,omitempty"`
+	ItemsRemoved []string          `json:"itemsRemoved,omitempty"`
+}
+
+type runDiffInstance struct {
+	Item        string               `json:"item"`
+	LeftStatus  string               `json:"leftStatus"`
+	RightStatus string               `json:"rightStatus"`
+	Verdict     string               `json:"verdict"`
+	Changes     []runDiffFieldChange `json:"changes,omitempty"`
+	Degraded    string               `json:"degraded,omitempty"`
 }
 
 type runDiffFieldChange struct {
@@ -174,6 +187,10 @@ func renderRunDiffTable(cmd *cobra.Command, diff *runDiffResponse) {
 	_ = tasks.Flush()
 
 	for _, task := range diff.Tasks {
+		if len(task.Instances) > 0 || len(task.ItemsAdded) > 0 || len(task.ItemsRemoved) > 0 {
+			renderRunDiffInstances(out, task)
+			continue
+		}
 		if task.Degraded != "" {
 			_, _ = fmt.Fprintf(out, "\n%s degraded: %s\n", task.TaskName, task.Degraded)
 			continue
@@ -182,6 +199,50 @@ func renderRunDiffTable(cmd *cobra.Command, diff *runDiffResponse) {
 	}
 }
 
+// renderRunDiffInstances renders a mapped step collapsed: the item-set delta
+// and one line per paired instance that would not have cache-hit.
+func renderRunDiffInstances(out io.Writer, task runDiffTask) {
+	_, _ = fmt.Fprintf(out, "\n%s instances: %d paired", task.TaskName, len(task.Instances))
+	if len(task.ItemsAdded) > 0 {
+		_, _ = fmt.Fprintf(out, ", %d added", len(task.ItemsAdded))
+	}
+	if len(task.ItemsRemoved) > 0 {
+		_, _ = fmt.Fprintf(out, ", %d removed", len(task.ItemsRemoved))
+	}
+	_, _ = fmt.Fprintln(out)
+
+	var rows []string
+	for _, inst := range task.Instances {
+		if inst.Verdict == "WOULD_CACHE_HIT" {
+			continue
+		}
+		changed := dashRunDiffEmpty(inst.Degraded)
+		if len(inst.Changes) > 0 {
+			changed = inst.Changes[0].Field
+			if n := len(inst.Changes); n > 1 {
+				changed = fmt.Sprintf("%s (+%d)", changed, n-1)
+			}
+		}
+		rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t%s\t%s", inst.Item, inst.Verdict, inst.LeftStatus, inst.RightStatus, changed))
+	}
+	for _, item := range task.ItemsAdded {
+		rows = append(rows, item+"\tADDED\t-\t-\t-")
+	}
+	for _, item := range task.ItemsRemoved {
+		rows = append(rows, item+"\tREMOVED\t-\t-\t-")
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ITEM\tVERDICT\tLEFT\tRIGHT\tCHANGED")
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, row)
+	}
+	_ = tw.Flush()
+}
+
 func renderRunDiffChanges(out io.Writer, title string, changes []runDiffFieldChange) {
 	if len(changes) == 0 {
 		return
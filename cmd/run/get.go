@@ -0,0 +1,150 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getAPI    cliutil.APIFlags
+	getJob    string
+	getOutput string
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <run-id> --job <job>",
+	Short: "Show a run and the state of each of its tasks",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGet,
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	if err := cliutil.ValidateOutput(getOutput); err != nil {
+		return err
+	}
+
+	c := getAPI.Client(cmd)
+	target, err := resolveRun(cmd.Context(), c, getJob, args[0])
+	if err != nil {
+		return err
+	}
+
+	return cliutil.WriteOutput(cmd, getOutput, target.run, func(out io.Writer) error {
+		return renderRun(out, target)
+	})
+}
+
+// runTarget is a run together with the job it belongs to and that job's task
+// names, which task runs only reference by ID.
+type runTarget struct {
+	job       *client.Job
+	run       *client.Run
+	taskNames map[uuid.UUID]string
+}
+
+func resolveRun(ctx context.Context, c client.Caesium, jobRef, runRef string) (*runTarget, error) {
+	runID, err := uuid.Parse(strings.TrimSpace(runRef))
+	if err != nil {
+		return nil, fmt.Errorf("invalid run id: %w", err)
+	}
+	job, err := c.ResolveJob(ctx, jobRef)
+	if err != nil {
+		return nil, err
+	}
+	run, err := c.GetRun(ctx, job.ID, runID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.JobTasks(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID]string, len(tasks))
+	for _, task := range tasks {
+		names[task.ID] = task.Name
+	}
+	return &runTarget{job: job, run: run, taskNames: names}, nil
+}
+
+// taskName labels a task run by its step name, falling back to its task ID.
+func (t *runTarget) taskName(task *client.TaskRun) string {
+	if name := t.taskNames[task.TaskID]; name != "" {
+		return name
+	}
+	return task.TaskID.String()
+}
+
+func renderRun(out io.Writer, target *runTarget) error {
+	run := target.run
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Run:\t%s\n", run.ID)
+	_, _ = fmt.Fprintf(tw, "Job:\t%s (%s)\n", target.job.Alias, target.job.ID)
+	_, _ = fmt.Fprintf(tw, "Status:\t%s\n", run.Status)
+	_, _ = fmt.Fprintf(tw, "Trigger:\t%s\n", dashRunDiffEmpty(run.TriggerType))
+	_, _ = fmt.Fprintf(tw, "Started:\t%s\n", run.StartedAt.UTC().Format(time.RFC3339))
+	_, _ = fmt.Fprintf(tw, "Duration:\t%s\n", formatRunDuration(run.StartedAt, run.CompletedAt))
+	_, _ = fmt.Fprintf(tw, "Tasks:\t%s\n", formatRunTaskCounts(run))
+	if run.Error != "" {
+		_, _ = fmt.Fprintf(tw, "Error:\t%s\n", run.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(run.Tasks) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(out)
+	tw = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TASK\tSTATUS\tATTEMPT\tDURATION\tCACHE\tERROR")
+	for _, task := range run.Tasks {
+		if task == nil {
+			continue
+		}
+		cache := "-"
+		if task.CacheHit {
+			cache = "hit"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\t%s\t%s\n",
+			target.taskName(task),
+			task.Status,
+			task.Attempt,
+			task.MaxAttempts,
+			formatTaskDuration(task),
+			cache,
+			dashRunDiffEmpty(firstLine(task.Error)),
+		)
+	}
+	return tw.Flush()
+}
+
+func formatTaskDuration(task *client.TaskRun) string {
+	if task.StartedAt == nil {
+		return "-"
+	}
+	return formatRunDuration(*task.StartedAt, task.CompletedAt)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func init() {
+	getAPI.Register(getCmd)
+	cliutil.AddOutputFlag(getCmd, &getOutput)
+	getCmd.Flags().StringVar(&getJob, "job", "", "Job alias, namespace/alias, or ID (required)")
+	getCmd.MarkFlagRequired("job") //nolint:errcheck
+
+	Cmd.AddCommand(getCmd)
+}
@@ -0,0 +1,107 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listAPI    cliutil.APIFlags
+	listJob    string
+	listOutput string
+	listLimit  int
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list --job <job>",
+	Short: "List a job's runs, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runList,
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	if err := cliutil.ValidateOutput(listOutput); err != nil {
+		return err
+	}
+
+	c := listAPI.Client(cmd)
+	job, err := c.ResolveJob(cmd.Context(), listJob)
+	if err != nil {
+		return err
+	}
+	runs, err := c.ListRuns(cmd.Context(), job.ID, &client.ListRunsRequest{Limit: listLimit})
+	if err != nil {
+		return err
+	}
+	// The API lists runs oldest first.
+	slices.Reverse(runs)
+
+	return cliutil.WriteOutput(cmd, listOutput, runs, func(out io.Writer) error {
+		return renderRunList(out, runs)
+	})
+}
+
+func renderRunList(out io.Writer, runs []*client.Run) error {
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(out, "No runs.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSTATUS\tTRIGGER\tSTARTED_AT\tDURATION\tTASKS")
+	for _, run := range runs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			run.ID,
+			run.Status,
+			dashRunDiffEmpty(run.TriggerType),
+			run.StartedAt.UTC().Format(time.RFC3339),
+			formatRunDuration(run.StartedAt, run.CompletedAt),
+			formatRunTaskCounts(run),
+		)
+	}
+	return tw.Flush()
+}
+
+func formatRunDuration(startedAt time.Time, completedAt *time.Time) string {
+	if completedAt == nil || startedAt.IsZero() {
+		return "-"
+	}
+	return completedAt.Sub(startedAt).Round(time.Second).String()
+}
+
+// formatRunTaskCounts summarizes a run's tasks as finished/total, noting how
+// many were served from cache.
+func formatRunTaskCounts(run *client.Run) string {
+	finished := 0
+	for _, task := range run.Tasks {
+		if task != nil && runstorage.IsTerminal(runstorage.TaskStatus(task.Status)) {
+			finished++
+		}
+	}
+	total := run.TotalTasks
+	if total == 0 {
+		total = len(run.Tasks)
+	}
+	if run.CacheHits > 0 {
+		return fmt.Sprintf("%d/%d (%d cached)", finished, total, run.CacheHits)
+	}
+	return fmt.Sprintf("%d/%d", finished, total)
+}
+
+func init() {
+	listAPI.Register(listCmd)
+	cliutil.AddOutputFlag(listCmd, &listOutput)
+	listCmd.Flags().StringVar(&listJob, "job", "", "Job alias, namespace/alias, or ID (required)")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Maximum number of runs to show (default: the server's 100 newest)")
+	listCmd.MarkFlagRequired("job") //nolint:errcheck
+
+	Cmd.AddCommand(listCmd)
+}
@@ -0,0 +1,46 @@
+package run
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunListShowsNewestFirst(t *testing.T) {
+	restoreListTestGlobals(t)
+	fake := newFakeRunServer()
+	older, newer := uuid.New(), uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/runs") {
+			_, _ = fmt.Fprintf(w, `[{"id":%q,"status":"failed","started_at":"2026-01-01T00:00:00Z","completed_at":"2026-01-01T00:01:30Z","total_tasks":2},{"id":%q,"status":"running","started_at":"2026-01-02T00:00:00Z","cache_hits":1,"total_tasks":2,"tasks":[{"status":"cached"},{"status":"running"}]}]`, older, newer)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	listAPI.Server = server.URL
+	listJob = fake.jobID.String()
+
+	cmd, stdout := newRunTestCommand()
+	require.NoError(t, runList(cmd, nil))
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Regexp(t, `^ID\s+STATUS\s+TRIGGER\s+STARTED_AT\s+DURATION\s+TASKS$`, lines[0])
+	require.Regexp(t, "^"+newer.String()+`\s+running\s+-\s+2026-01-02T00:00:00Z\s+-\s+1/2 \(1 cached\)$`, lines[1])
+	require.Regexp(t, "^"+older.String()+`\s+failed\s+-\s+2026-01-01T00:00:00Z\s+1m30s\s+0/2$`, lines[2])
+}
+
+func restoreListTestGlobals(t *testing.T) {
+	t.Helper()
+	originalAPI, originalJob, originalOutput, originalLimit := listAPI, listJob, listOutput, listLimit
+	t.Cleanup(func() {
+		listAPI, listJob, listOutput, listLimit = originalAPI, originalJob, originalOutput, originalLimit
+	})
+}
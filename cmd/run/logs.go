@@ -0,0 +1,180 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsAPI    cliutil.APIFlags
+	logsJob    string
+	logsTask   string
+	logsFollow bool
+
+	// logsIdleTimeout ends a live log read without --follow once the task
+	// has written nothing new for this long.
+	logsIdleTimeout = 2 * time.Second
+	// logsPollInterval paces --follow while the task has not started yet.
+	logsPollInterval = 2 * time.Second
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <run-id> --job <job> [--task <step>] [--follow]",
+	Short: "Print a task's logs",
+	Long: "Print the logs of one task in a run. --task names the step and may be " +
+		"omitted when the run has a single task. Without --follow the command " +
+		"prints what the task has written so far; with --follow it waits for " +
+		"the task to start and streams its output until the task exits.",
+	Args: cobra.ExactArgs(1),
+	RunE: runLogs,
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c := logsAPI.Client(cmd)
+	target, err := resolveRun(ctx, c, logsJob, args[0])
+	if err != nil {
+		return err
+	}
+	task, err := target.selectTask(logsTask)
+	if err != nil {
+		return err
+	}
+
+	for {
+		logs, err := c.RunLogs(ctx, target.job.ID, target.run.ID, task.ID)
+		if err != nil {
+			return err
+		}
+		if logs.Body != nil {
+			return copyLogs(ctx, cmd, logs)
+		}
+		if !logsFollow || logs.State != "pending" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "no logs for task %s: %s\n", target.taskName(task), dashRunDiffEmpty(logs.State))
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logsPollInterval):
+		}
+	}
+}
+
+// selectTask finds the task run named ref, by step name or ID, or the run's
+// only task when ref is empty.
+func (t *runTarget) selectTask(ref string) (*client.TaskRun, error) {
+	ref = strings.TrimSpace(ref)
+	var (
+		names []string
+		only  *client.TaskRun
+	)
+	for _, task := range t.run.Tasks {
+		if task == nil {
+			continue
+		}
+		name := t.taskName(task)
+		if ref != "" && (ref == name || ref == task.TaskID.String() || ref == task.ID.String()) {
+			return task, nil
+		}
+		names = append(names, name)
+		only = task
+	}
+	if ref == "" && len(names) == 1 {
+		return only, nil
+	}
+	if ref != "" {
+		return nil, fmt.Errorf("run %s has no task %q; tasks: %s", t.run.ID, ref, strings.Join(names, ", "))
+	}
+	return nil, fmt.Errorf("run %s has %d tasks; choose one with --task: %s", t.run.ID, len(names), strings.Join(names, ", "))
+}
+
+func copyLogs(ctx context.Context, cmd *cobra.Command, logs *client.LogStream) error {
+	defer func() { _ = logs.Close() }()
+	out := cmd.OutOrStdout()
+
+	if logs.Truncated {
+		defer func() {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "(log truncated by the server)")
+		}()
+	}
+	if logsFollow || logs.Source != "live" {
+		_, err := io.Copy(out, logs.Body)
+		if err != nil && ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	return copyUntilIdle(out, logs.Body, logsIdleTimeout)
+}
+
+// copyUntilIdle copies r to w until r ends or goes quiet for idle, which is
+// how a one-shot read of a live log, which the server holds open while the
+// task runs, finds the end of what has been written so far.
+func copyUntilIdle(w io.Writer, r io.ReadCloser, idle time.Duration) error {
+	type chunk struct {
+		data []byte
+		err  error
+	}
+	chunks := make(chan chunk)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := r.Read(buf)
+			c := chunk{data: append([]byte(nil), buf[:n]...), err: err}
+			select {
+			case chunks <- c:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+	for {
+		select {
+		case c := <-chunks:
+			if len(c.data) > 0 {
+				if _, err := w.Write(c.data); err != nil {
+					return err
+				}
+			}
+			if c.err != nil {
+				if errors.Is(c.err, io.EOF) {
+					return nil
+				}
+				return c.err
+			}
+			timer.Reset(idle)
+		case <-timer.C:
+			// Closing unblocks the reader goroutine.
+			_ = r.Close()
+			return nil
+		}
+	}
+}
+
+func init() {
+	logsAPI.Register(logsCmd)
+	logsCmd.Flags().StringVar(&logsJob, "job", "", "Job alias, namespace/alias, or ID (required)")
+	logsCmd.Flags().StringVar(&logsTask, "task", "", "Step name or task ID; optional when the run has one task")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Wait for the task to start and stream its logs until it exits")
+	logsCmd.MarkFlagRequired("job") //nolint:errcheck
+
+	Cmd.AddCommand(logsCmd)
+}
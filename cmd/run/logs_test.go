@@ -0,0 +1,52 @@
+package run
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLogsRequiresTaskWhenRunHasSeveral(t *testing.T) {
+	restoreLogsTestGlobals(t)
+	fake := newFakeRunServer()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	logsAPI.Server = server.URL
+	logsJob = fake.jobID.String()
+
+	cmd, _ := newRunTestCommand()
+	err := runLogs(cmd, []string{fake.runID.String()})
+	require.EqualError(t, err, "run "+fake.runID.String()+" has 2 tasks; choose one with --task: extract, load")
+
+	logsTask = "transform"
+	err = runLogs(cmd, []string{fake.runID.String()})
+	require.EqualError(t, err, "run "+fake.runID.String()+` has no task "transform"; tasks: extract, load`)
+}
+
+func TestCopyUntilIdleStopsOnceStreamGoesQuiet(t *testing.T) {
+	reader, writer := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(writer, "first\n")
+		_, _ = io.WriteString(writer, "second\n")
+		// The writer stays open, as a live log does while its task runs.
+	}()
+
+	var out bytes.Buffer
+	start := time.Now()
+	require.NoError(t, copyUntilIdle(&out, reader, 50*time.Millisecond))
+	require.Equal(t, "first\nsecond\n", out.String())
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func restoreLogsTestGlobals(t *testing.T) {
+	t.Helper()
+	originalAPI, originalJob, originalTask, originalFollow := logsAPI, logsJob, logsTask, logsFollow
+	t.Cleanup(func() {
+		logsAPI, logsJob, logsTask, logsFollow = originalAPI, originalJob, originalTask, originalFollow
+	})
+}
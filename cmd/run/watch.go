@@ -0,0 +1,212 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/internal/dag"
+	"github.com/caesium-cloud/caesium/internal/dagrender"
+	"github.com/caesium-cloud/caesium/internal/event"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and clears the terminal between frames.
+const clearScreen = "\033[H\033[2J"
+
+var (
+	watchAPI cliutil.APIFlags
+	watchJob string
+)
+
+// watchEventTypes are the events that change what the watch view shows.
+var watchEventTypes = []event.Type{
+	event.TypeRunCompleted,
+	event.TypeRunFailed,
+	event.TypeRunCancelled,
+	event.TypeRunTerminal,
+	event.TypeRunTimedOut,
+	event.TypeTaskReady,
+	event.TypeTaskStarted,
+	event.TypeTaskSucceeded,
+	event.TypeTaskFailed,
+	event.TypeTaskSkipped,
+	event.TypeTaskRetrying,
+	event.TypeTaskCached,
+	event.TypeTaskAwaitingApproval,
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <run-id> --job <job>",
+	Short: "Follow a run live as an ASCII DAG",
+	Long: "Draw the run's DAG with each task's status and redraw it as the " +
+		"server's event stream reports progress, until the run finishes. The " +
+		"command exits non-zero when the run does not succeed.",
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	c := watchAPI.Client(cmd)
+	target, err := resolveRun(ctx, c, watchJob, args[0])
+	if err != nil {
+		return err
+	}
+	graph, err := c.JobDAG(ctx, target.job.ID)
+	if err != nil {
+		return err
+	}
+	analysis := runAnalysis(graph, target.taskNames)
+
+	out := cmd.OutOrStdout()
+	redraw := isTerminal(out)
+	if err := renderWatchFrame(out, target, analysis, redraw); err != nil {
+		return err
+	}
+	if target.run.Status != client.RunRunning {
+		return watchResult(target.run)
+	}
+
+	// Any matching event marks the view stale; the loop refetches the run
+	// once per burst, so the backlog the stream replays on connect costs a
+	// few requests rather than one per event.
+	stale := make(chan struct{}, 1)
+	streamErr := make(chan error, 1)
+	go func() {
+		filter := client.EventFilter{RunID: target.run.ID}
+		for _, t := range watchEventTypes {
+			filter.Types = append(filter.Types, string(t))
+		}
+		streamErr <- c.Events(ctx, filter, func(client.Event) error {
+			select {
+			case stale <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+	}()
+
+	refresh := func() (bool, error) {
+		run, err := c.GetRun(ctx, target.job.ID, target.run.ID)
+		if err != nil {
+			return false, err
+		}
+		target.run = run
+		if err := renderWatchFrame(out, target, analysis, redraw); err != nil {
+			return false, err
+		}
+		return run.Status != client.RunRunning, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-stale:
+			done, err := refresh()
+			if err != nil {
+				return err
+			}
+			if done {
+				return watchResult(target.run)
+			}
+		case err := <-streamErr:
+			if err != nil {
+				return err
+			}
+			done, err := refresh()
+			if err != nil {
+				return err
+			}
+			if done {
+				return watchResult(target.run)
+			}
+			return errors.New("event stream closed before the run finished")
+		}
+	}
+}
+
+// runAnalysis lays out a job's persisted DAG under its step names.
+func runAnalysis(graph *client.DAG, names map[uuid.UUID]string) *dag.Analysis {
+	name := func(id uuid.UUID) string {
+		if n := names[id]; n != "" {
+			return n
+		}
+		return id.String()
+	}
+
+	steps := make([]string, 0, len(graph.Nodes))
+	successors := make(map[string][]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		from := name(node.ID)
+		steps = append(steps, from)
+		for _, to := range node.Successors {
+			successors[from] = append(successors[from], name(to))
+		}
+	}
+	return dag.FromSuccessors(steps, successors)
+}
+
+func renderWatchFrame(out io.Writer, target *runTarget, analysis *dag.Analysis, redraw bool) error {
+	run := target.run
+	status := make(map[string]string, len(run.Tasks))
+	for _, task := range run.Tasks {
+		if task != nil {
+			status[target.taskName(task)] = task.Status
+		}
+	}
+
+	if redraw {
+		_, _ = io.WriteString(out, clearScreen)
+	} else {
+		_, _ = fmt.Fprintln(out)
+	}
+	_, _ = fmt.Fprintf(out, "Run %s of %s: %s, tasks %s, duration %s\n\n",
+		run.ID,
+		target.job.Alias,
+		run.Status,
+		formatRunTaskCounts(run),
+		formatRunDuration(run.StartedAt, run.CompletedAt),
+	)
+	return dagrender.RenderLabeled(analysis, out, func(step string) string {
+		if s := status[step]; s != "" {
+			return step + " (" + s + ")"
+		}
+		return step
+	})
+}
+
+func watchResult(run *client.Run) error {
+	if run.Status == client.RunSucceeded {
+		return nil
+	}
+	if run.Error != "" {
+		return fmt.Errorf("run %s %s: %s", run.ID, run.Status, firstLine(run.Error))
+	}
+	return fmt.Errorf("run %s %s", run.ID, run.Status)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	watchAPI.Register(watchCmd)
+	watchCmd.Flags().StringVar(&watchJob, "job", "", "Job alias, namespace/alias, or ID (required)")
+	watchCmd.MarkFlagRequired("job") //nolint:errcheck
+
+	Cmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,112 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRunServer struct {
+	jobID, runID      uuid.UUID
+	extract, load     uuid.UUID
+	runFetches        atomic.Int32
+	statusAfterEvents string
+}
+
+func newFakeRunServer() *fakeRunServer {
+	return &fakeRunServer{
+		jobID:             uuid.New(),
+		runID:             uuid.New(),
+		extract:           uuid.New(),
+		load:              uuid.New(),
+		statusAfterEvents: "succeeded",
+	}
+}
+
+func (f *fakeRunServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jobPath := "/v1/jobs/" + f.jobID.String()
+	switch r.URL.Path {
+	case jobPath:
+		_, _ = fmt.Fprintf(w, `{"id":%q,"alias":"nightly","namespace":"default"}`, f.jobID)
+	case jobPath + "/tasks":
+		_, _ = fmt.Fprintf(w, `[{"ID":%q,"name":"extract"},{"ID":%q,"name":"load"}]`, f.extract, f.load)
+	case jobPath + "/dag":
+		_, _ = fmt.Fprintf(w, `{"job_id":%q,"nodes":[{"id":%q,"successors":[%q]},{"id":%q,"successors":[]}]}`, f.jobID, f.extract, f.load, f.load)
+	case jobPath + "/runs/" + f.runID.String():
+		runStatus, loadStatus := "running", "running"
+		if f.runFetches.Add(1) > 1 {
+			runStatus, loadStatus = f.statusAfterEvents, f.statusAfterEvents
+		}
+		_, _ = fmt.Fprintf(w, `{"id":%q,"job_id":%q,"status":%q,"started_at":"2026-01-01T00:00:00Z","tasks":[{"id":%q,"task_id":%q,"status":"succeeded"},{"id":%q,"task_id":%q,"status":%q}]}`,
+			f.runID, f.jobID, runStatus, uuid.New(), f.extract, uuid.New(), f.load, loadStatus)
+	case "/v1/events":
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, ": ping\n\n")
+		_, _ = fmt.Fprintf(w, "id: 1\nevent: run_completed\ndata: {\"sequence\":1,\"type\":\"run_completed\",\"run_id\":%q}\n\n", f.runID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRunWatchRedrawsUntilRunFinishes(t *testing.T) {
+	restoreWatchTestGlobals(t)
+	fake := newFakeRunServer()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	watchAPI.Server = server.URL
+	watchJob = fake.jobID.String()
+
+	cmd, stdout := newRunTestCommand()
+	require.NoError(t, runWatch(cmd, []string{fake.runID.String()}))
+
+	out := stdout.String()
+	require.Contains(t, out, "Run "+fake.runID.String()+" of nightly: running, tasks 1/2")
+	require.Contains(t, out, "load (running)")
+	require.Contains(t, out, "Run "+fake.runID.String()+" of nightly: succeeded, tasks 2/2")
+	require.Contains(t, out, "extract (succeeded)")
+	require.Contains(t, out, "load (succeeded)")
+	require.NotContains(t, out, clearScreen, "frames are only cleared on a terminal")
+}
+
+func TestRunWatchFailsWhenRunFails(t *testing.T) {
+	restoreWatchTestGlobals(t)
+	fake := newFakeRunServer()
+	fake.statusAfterEvents = "failed"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	watchAPI.Server = server.URL
+	watchJob = fake.jobID.String()
+
+	cmd, stdout := newRunTestCommand()
+	err := runWatch(cmd, []string{fake.runID.String()})
+	require.EqualError(t, err, "run "+fake.runID.String()+" failed")
+	require.Contains(t, stdout.String(), "load (failed)")
+}
+
+func newRunTestCommand() (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	return cmd, &stdout
+}
+
+func restoreWatchTestGlobals(t *testing.T) {
+	t.Helper()
+	originalAPI, originalJob := watchAPI, watchJob
+	t.Cleanup(func() {
+		watchAPI, watchJob = originalAPI, originalJob
+	})
+}
@@ -20,27 +20,39 @@ import (
 	authldap "github.com/caesium-cloud/caesium/internal/auth/ldap"
 	authoidc "github.com/caesium-cloud/caesium/internal/auth/oidc"
 	authsaml "github.com/caesium-cloud/caesium/internal/auth/saml"
+	"github.com/caesium-cloud/caesium/internal/backfill/reconcile"
+	"github.com/caesium-cloud/caesium/internal/backup"
+	"github.com/caesium-cloud/caesium/internal/cordon"
 	"github.com/caesium-cloud/caesium/internal/dispatch"
 	dispatchpki "github.com/caesium-cloud/caesium/internal/dispatch/pki"
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/executor"
 	"github.com/caesium-cloud/caesium/internal/freshness"
+	"github.com/caesium-cloud/caesium/internal/gate"
+	"github.com/caesium-cloud/caesium/internal/imagepolicy"
 	"github.com/caesium-cloud/caesium/internal/incident"
 	"github.com/caesium-cloud/caesium/internal/jobdef"
 	"github.com/caesium-cloud/caesium/internal/jobdef/git"
 	"github.com/caesium-cloud/caesium/internal/jobdef/runtime"
 	"github.com/caesium-cloud/caesium/internal/lineage"
+	"github.com/caesium-cloud/caesium/internal/logarchive"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/notification"
+	"github.com/caesium-cloud/caesium/internal/placement"
+	"github.com/caesium-cloud/caesium/internal/pool"
 	"github.com/caesium-cloud/caesium/internal/ratelimit"
+	"github.com/caesium-cloud/caesium/internal/receipt"
 	"github.com/caesium-cloud/caesium/internal/run"
 	"github.com/caesium-cloud/caesium/internal/runqueue"
+	"github.com/caesium-cloud/caesium/internal/sla"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	triggerevent "github.com/caesium-cloud/caesium/internal/trigger/event"
 	triggerhttp "github.com/caesium-cloud/caesium/internal/trigger/http"
 	"github.com/caesium-cloud/caesium/internal/worker"
 	"github.com/caesium-cloud/caesium/pkg/db"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
 	"github.com/caesium-cloud/caesium/pkg/env"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/spf13/cobra"
 )
@@ -100,6 +112,41 @@ func start(cmd *cobra.Command, args []string) error {
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	vars := env.Variables()
+
+	// Deferred first so spans recorded during graceful shutdown are flushed.
+	shutdownTracing, tracingErr := tracing.Setup(ctx, vars)
+	if tracingErr != nil {
+		log.Error("tracing configuration failure, disabling export", "error", tracingErr)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Warn("failed to flush traces on shutdown", "error", err)
+		}
+	}()
+
+	// A receipt key that fails to parse would leave every receipt unsigned,
+	// so refuse to start instead of serving them without signatures.
+	if _, err := receipt.KeyringFromEnv(vars); err != nil {
+		cancelFunc()
+		return fmt.Errorf("receipt keyring: %w", err)
+	}
+	if keyring := receipt.DefaultKeyring(); keyring != nil {
+		log.Info("receipt signing enabled", "key_id", keyring.CurrentKeyID())
+	}
+
+	// An image policy that fails to load would deny every image, so refuse
+	// to start instead of failing every task.
+	if _, err := imagepolicy.FromEnv(vars); err != nil {
+		cancelFunc()
+		return fmt.Errorf("image policy: %w", err)
+	}
+	if mode := imagepolicy.Default().Mode(); mode != imagepolicy.ModeOff {
+		log.Info("image admission policy enabled", "mode", mode)
+	}
+
 	var internalSrv *dispatch.InternalServer
 	shutdownCoordinator := newShutdownCoordinator(shutdownConfig{
 		cancel:      cancelFunc,
@@ -189,6 +236,68 @@ func start(cmd *cobra.Command, args []string) error {
 			dequeuer.Run(ctx)
 		})
 	}
+	backfillReconciler := reconcile.NewReconciler(reconcile.Config{
+		DB:          db.Connection(),
+		RunStore:    runStore,
+		Interval:    vars.BackfillReconcileInterval,
+		LeaderCheck: dqlite.IsLocalLeader,
+	})
+	runAsync(func() {
+		log.Info("launching backfill reconciler", "interval", vars.BackfillReconcileInterval)
+		backfillReconciler.Run(ctx)
+	})
+	var archiveAfter time.Duration
+	if vars.RunArchiveEnabled {
+		archiveAfter = vars.RunArchiveAfter
+	}
+	archiver := run.NewArchiver(run.ArchiverConfig{
+		Store:        runStore,
+		History:      runStore.History(),
+		ArchiveAfter: archiveAfter,
+		Interval:     vars.RunArchiveInterval,
+		BatchSize:    vars.RunArchiveBatchSize,
+		Retention: jobdefschema.Retention{
+			KeepRuns:       vars.RunRetentionKeepRuns,
+			KeepDays:       vars.RunRetentionKeepDays,
+			KeepFailedDays: vars.RunRetentionKeepFailedDays,
+		},
+		LogArchive:  logarchive.Default(),
+		LeaderCheck: dqlite.IsLocalLeader,
+	})
+	if vars.RunArchiveEnabled && runStore.History() == nil {
+		log.Warn("run archival needs the internal dqlite database; only retention applies", "type", vars.DatabaseType)
+	}
+	runAsync(func() {
+		log.Info("launching run archiver", "interval", vars.RunArchiveInterval, "archive_after", archiveAfter)
+		archiver.Run(ctx)
+	})
+	if vars.BackupInterval > 0 {
+		if db.InternalDqlite() {
+			backupScheduler := backup.NewScheduler(backup.SchedulerConfig{
+				Dir:         vars.BackupDir,
+				Interval:    vars.BackupInterval,
+				Keep:        vars.BackupKeep,
+				Backup:      backup.Take,
+				LeaderCheck: dqlite.IsLocalLeader,
+			})
+			runAsync(func() {
+				log.Info("launching backup scheduler", "interval", vars.BackupInterval, "dir", vars.BackupDir, "keep", vars.BackupKeep)
+				backupScheduler.Run(ctx)
+			})
+		} else {
+			log.Warn("scheduled backups need the internal dqlite database; back up PostgreSQL with its own tooling", "type", vars.DatabaseType)
+		}
+	}
+	gateSweeper := gate.NewSweeper(runStore, dqlite.IsLocalLeader, vars.GateSweepInterval)
+	runAsync(func() {
+		log.Info("launching approval gate sweeper", "interval", vars.GateSweepInterval)
+		gateSweeper.Run(ctx)
+	})
+	poolReporter := pool.NewReporter(db.Connection(), dqlite.IsLocalLeader, vars.PoolMetricsInterval)
+	runAsync(func() {
+		log.Info("launching pool metrics reporter", "interval", vars.PoolMetricsInterval)
+		poolReporter.Run(ctx)
+	})
 	if vars.FreshnessEnabled {
 		conn := db.Connection()
 		// Wire the process-wide arrival observer (used by the ingest/webhook
@@ -378,6 +487,8 @@ func start(cmd *cobra.Command, args []string) error {
 			RateLimitDB:  db.Connection(),
 			RateLimiter:  ratelimit.NewLimiter(db.Connection()),
 			Peers:        dqliteDispatchPeerResolver(),
+			Nodes:        cordon.NewStore(db.Connection()),
+			Placement:    placement.NewRegistry(db.Connection()),
 			OwnerManager: ownerManager,
 		})
 		runAsync(func() {
@@ -476,6 +587,7 @@ func start(cmd *cobra.Command, args []string) error {
 		})
 
 		watcher := notification.NewWatcher(conn, bus, event.NewStore(conn), vars.NotificationWatcherInterval)
+		watcher.SetPredictor(sla.NewPredictor(conn, vars.SLAETAPercentile))
 		runAsync(func() {
 			log.Info("launching notification watcher (timeout/SLA)")
 			if err := watcher.Start(ctx); err != nil && ctx.Err() == nil {
@@ -617,14 +729,22 @@ func start(cmd *cobra.Command, args []string) error {
 			vars.WorkerLeaseTTL,
 		)
 
-		claimer := worker.NewClaimer(vars.NodeAddress, runStore, vars.WorkerLeaseTTL, worker.ParseNodeLabels(vars.NodeLabels)).
-			WithRateLimiter(ratelimit.NewLimiter(db.Connection()))
+		// Operator cordon/drain decisions (PUT /v1/system/nodes/:address/...)
+		// stop this node claiming and, on drain, hand its running tasks back.
+		nodeStates := cordon.NewStore(db.Connection())
+		nodeLabels := worker.ParseNodeLabels(vars.NodeLabels)
+		claimer := worker.NewClaimer(vars.NodeAddress, runStore, vars.WorkerLeaseTTL, nodeLabels).
+			WithRateLimiter(ratelimit.NewLimiter(db.Connection())).
+			WithNodeStates(nodeStates)
 		executorFn := worker.NewRuntimeExecutor(runStore, vars.TaskTimeout, vars.TaskFailurePolicy, resolver)
 		wakeups := worker.SubscribeWakeups(ctx, bus, wakeupSignaler.C())
 		distributedWorker = worker.NewWorker(claimer, worker.NewPool(poolSize), vars.WorkerPollInterval, executorFn).
 			WithReclaimInterval(vars.WorkerReclaimInterval).
 			WithWakeups(wakeups).
-			WithLeaseRenewal(runStore, vars.WorkerLeaseTTL, vars.WorkerLeaseRenewInterval)
+			WithLeaseRenewal(runStore, vars.WorkerLeaseTTL, vars.WorkerLeaseRenewInterval).
+			WithCancellationWatch(runStore, vars.RunCancelCheckInterval).
+			WithDrainWatch(nodeStates, vars.NodeAddress).
+			WithNodeRegistration(placement.NewRegistry(db.Connection()), vars.NodeAddress, nodeLabels)
 
 		// Phase 2: piggyback run-lease renewal on the same worker goroutine
 		// when owner mode is enabled, and enable the inbound dispatch path so
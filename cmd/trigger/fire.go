@@ -0,0 +1,135 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// manualKeyEnvVar holds the key the fire endpoint checks; it is the same
+// variable the server reads.
+const manualKeyEnvVar = "CAESIUM_MANUAL_TRIGGER_API_KEY"
+
+var (
+	fireAPI      cliutil.APIFlags
+	fireParams   []string
+	firePriority string
+)
+
+var fireCmd = &cobra.Command{
+	Use:   "fire <trigger> [--params k=v] [--priority high|normal|low]",
+	Short: "Fire an HTTP trigger, starting a run of every job it drives",
+	Long: "Fire an HTTP trigger. <trigger> is a trigger ID, a trigger alias or " +
+		"namespace/alias, or the alias of a job the trigger drives. The server " +
+		"only accepts manual fires when it sets " + manualKeyEnvVar + "; export " +
+		"the same value here and it is sent alongside the regular API key.",
+	Args: cobra.ExactArgs(1),
+	RunE: runFire,
+}
+
+func runFire(cmd *cobra.Command, args []string) error {
+	params, err := parseFireParams(fireParams)
+	if err != nil {
+		return err
+	}
+	priority := strings.TrimSpace(firePriority)
+	if priority != "" {
+		if _, err := runstorage.PriorityValue(priority); err != nil {
+			return err
+		}
+	}
+
+	c := fireAPI.Client(cmd)
+	trigger, err := resolveTrigger(cmd.Context(), c, args[0])
+	if err != nil {
+		return err
+	}
+	if trigger.Type != client.TriggerTypeHTTP {
+		return fmt.Errorf("trigger %s is type %s; only http triggers can be fired", trigger.Alias, trigger.Type)
+	}
+
+	if err := c.FireTrigger(cmd.Context(), trigger.ID, &client.FireRequest{
+		Params:    params,
+		Priority:  priority,
+		ManualKey: os.Getenv(manualKeyEnvVar),
+	}); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "fired trigger %s (%s)\n", trigger.Alias, trigger.ID)
+	return nil
+}
+
+// resolveTrigger finds a trigger by ID, alias, or the alias of a job it
+// drives.
+func resolveTrigger(ctx context.Context, c client.Caesium, ref string) (*client.Trigger, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("trigger is required")
+	}
+
+	triggers, err := c.ListTriggers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*client.Trigger, len(triggers))
+	namespace, alias, qualified := strings.Cut(ref, "/")
+	if !qualified {
+		alias = ref
+	}
+	var matches []*client.Trigger
+	for _, trigger := range triggers {
+		byID[trigger.ID] = trigger
+		if trigger.ID.String() == ref {
+			return trigger, nil
+		}
+		if trigger.Alias == alias && (!qualified || trigger.Namespace == namespace) {
+			matches = append(matches, trigger)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("trigger alias %q exists in several namespaces; use namespace/alias", ref)
+	}
+
+	job, err := c.ResolveJob(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("no trigger or job matches %q", ref)
+	}
+	if job.Trigger != nil {
+		return job.Trigger, nil
+	}
+	if trigger, ok := byID[job.TriggerID]; ok {
+		return trigger, nil
+	}
+	return nil, fmt.Errorf("job %s has no trigger", job.Alias)
+}
+
+func parseFireParams(values []string) (map[string]string, error) {
+	params := make(map[string]string, len(values))
+	for _, raw := range values {
+		key, value, ok := strings.Cut(raw, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--params must be k=v; got %q", raw)
+		}
+		params[strings.TrimSpace(key)] = value
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+	return params, nil
+}
+
+func init() {
+	fireAPI.Register(fireCmd)
+	fireCmd.Flags().StringArrayVar(&fireParams, "params", nil, "Run parameter as k=v (repeatable)")
+	fireCmd.Flags().StringVar(&firePriority, "priority", "", "Run priority override: high, normal, or low")
+}
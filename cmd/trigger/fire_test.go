@@ -0,0 +1,74 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFireResolvesJobAliasToItsTrigger(t *testing.T) {
+	originalAPI, originalParams, originalPriority := fireAPI, fireParams, firePriority
+	t.Cleanup(func() { fireAPI, fireParams, firePriority = originalAPI, originalParams, originalPriority })
+	t.Setenv(manualKeyEnvVar, "manual-key")
+
+	jobID, triggerID := uuid.New(), uuid.New()
+	fired := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/triggers":
+			_, _ = fmt.Fprintf(w, `[{"id":%q,"alias":"on-demand","namespace":"default","type":"http"}]`, triggerID)
+		case "/v1/jobs":
+			_, _ = fmt.Fprintf(w, `[{"id":%q,"alias":"nightly","namespace":"default","trigger_id":%q}]`, jobID, triggerID)
+		case "/v1/jobs/" + jobID.String():
+			_, _ = fmt.Fprintf(w, `{"id":%q,"alias":"nightly","namespace":"default","trigger_id":%q}`, jobID, triggerID)
+		case "/v1/triggers/" + triggerID.String() + "/fire":
+			require.Equal(t, "manual-key", r.Header.Get("X-Caesium-API-Key"))
+			body, _ := io.ReadAll(r.Body)
+			require.JSONEq(t, `{"params":{"date":"2026-01-01"}}`, string(body))
+			fired = true
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fireAPI.Server = server.URL
+	fireParams = []string{"date=2026-01-01"}
+	firePriority = ""
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+
+	require.NoError(t, runFire(cmd, []string{"nightly"}))
+	require.True(t, fired)
+	require.Equal(t, "fired trigger on-demand ("+triggerID.String()+")\n", stdout.String())
+}
+
+func TestFireRejectsNonHTTPTrigger(t *testing.T) {
+	originalAPI := fireAPI
+	t.Cleanup(func() { fireAPI = originalAPI })
+
+	triggerID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/triggers", r.URL.Path)
+		_, _ = fmt.Fprintf(w, `[{"id":%q,"alias":"nightly-cron","namespace":"default","type":"cron"}]`, triggerID)
+	}))
+	defer server.Close()
+	fireAPI.Server = server.URL
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	err := runFire(cmd, []string{triggerID.String()})
+	require.EqualError(t, err, "trigger nightly-cron is type cron; only http triggers can be fired")
+}
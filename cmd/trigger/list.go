@@ -0,0 +1,69 @@
+package trigger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/caesium-cloud/caesium/cmd/cliutil"
+	"github.com/caesium-cloud/caesium/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listAPI    cliutil.APIFlags
+	listOutput string
+	listType   string
+	listLimit  uint64
+	listOffset uint64
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List triggers",
+	Args:  cobra.NoArgs,
+	RunE:  runList,
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	if err := cliutil.ValidateOutput(listOutput); err != nil {
+		return err
+	}
+
+	triggers, err := listAPI.Client(cmd).ListTriggers(cmd.Context(), &client.ListTriggersRequest{
+		Limit:   listLimit,
+		Offset:  listOffset,
+		OrderBy: []string{"namespace", "alias"},
+		Type:    strings.ToLower(strings.TrimSpace(listType)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cliutil.WriteOutput(cmd, listOutput, triggers, func(out io.Writer) error {
+		return renderTriggerList(out, triggers)
+	})
+}
+
+func renderTriggerList(out io.Writer, triggers []*client.Trigger) error {
+	if len(triggers) == 0 {
+		_, err := fmt.Fprintln(out, "No triggers.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAMESPACE\tALIAS\tTYPE")
+	for _, trigger := range triggers {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", trigger.ID, trigger.Namespace, trigger.Alias, trigger.Type)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	listAPI.Register(listCmd)
+	cliutil.AddOutputFlag(listCmd, &listOutput)
+	listCmd.Flags().StringVar(&listType, "type", "", "Only list triggers of this type, e.g. cron or http")
+	listCmd.Flags().Uint64Var(&listLimit, "limit", 0, "Maximum number of triggers to return")
+	listCmd.Flags().Uint64Var(&listOffset, "offset", 0, "Number of triggers to skip")
+}
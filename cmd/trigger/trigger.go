@@ -8,5 +8,5 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
-	Cmd.AddCommand(eventsCmd)
+	Cmd.AddCommand(eventsCmd, listCmd, fireCmd)
 }
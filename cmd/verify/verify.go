@@ -1,6 +1,7 @@
 // Package verify is the `caesium verify <receipt>` CLI command: re-derive a
-// committed reproducibility receipt against a run's current persisted state and
-// flag drift.
+// committed reproducibility receipt against a run's current persisted state,
+// flag drift, and check the receipt's signature against a published public key
+// set.
 package verify
 
 import (
@@ -18,8 +19,10 @@ import (
 )
 
 var (
-	verifyServer string
-	verifyJSON   bool
+	verifyServer           string
+	verifyJSON             bool
+	verifyPublicKeys       string
+	verifyRequireSignature bool
 )
 
 // Cmd is `caesium verify <receipt-file>`.
@@ -30,9 +33,13 @@ var Cmd = &cobra.Command{
 		"run's persisted state and report drift: a moved image tag (digest " +
 		"mismatch), a changed manifest, a changed input. It does NOT resurrect " +
 		"deleted source data — it re-derives the signature and proves what ran.\n\n" +
-		"The job and run IDs are read from the receipt file. Exits non-zero when " +
-		"the run drifted from the receipt OR cannot be soundly verified (it ran " +
-		"on an unpinned, mutable image tag).",
+		"The job and run IDs are read from the receipt file. The receipt's " +
+		"signature is checked locally against --public-keys (a file or URL; by " +
+		"default the server's published key set). Exits non-zero when the run " +
+		"drifted from the receipt, cannot be soundly verified (it ran on an " +
+		"unpinned, mutable image tag), or carries a signature that does not " +
+		"verify. With --require-signature, an unsigned receipt or one signed by " +
+		"an unknown key also fails.",
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		committed, err := loadReceipt(args[0])
@@ -73,6 +80,19 @@ var Cmd = &cobra.Command{
 			return fmt.Errorf("decode verify response: %w", err)
 		}
 
+		// The server already checked the signature against its own keys, but
+		// the point of a signature is not having to trust the server: re-check
+		// against the key set the caller chose.
+		keysSource := verifyPublicKeys
+		if keysSource == "" {
+			keysSource = server + "/.well-known/caesium-receipt-keys"
+		}
+		keys, err := loadPublicKeys(cmd, keysSource)
+		if err != nil {
+			return err
+		}
+		checkSignatures(&result, committed, keys)
+
 		if verifyJSON {
 			pretty, mErr := json.MarshalIndent(&result, "", "  ")
 			if mErr != nil {
@@ -89,11 +109,19 @@ var Cmd = &cobra.Command{
 		if !result.Match {
 			cmd.SilenceUsage = true
 			cmd.SilenceErrors = true
+			if result.Signature != nil && result.Signature.Status == ireceipt.SignatureInvalid {
+				return fmt.Errorf("INVALID SIGNATURE: %s", result.Signature.Detail)
+			}
 			if result.Degraded {
 				return fmt.Errorf("UNVERIFIABLE: run ran on unpinned image tag(s); not reproducible")
 			}
 			return fmt.Errorf("DRIFT: run no longer matches the committed receipt")
 		}
+		if verifyRequireSignature && result.Signature.Status != ireceipt.SignatureValid {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return fmt.Errorf("UNSIGNED: %s", result.Signature.Detail)
+		}
 		return nil
 	},
 }
@@ -111,6 +139,54 @@ func loadReceipt(path string) (*ireceipt.Receipt, error) {
 	return &r, nil
 }
 
+// loadPublicKeys reads a published receipt public key set from a local file or
+// an http(s) URL.
+func loadPublicKeys(cmd *cobra.Command, source string) (ireceipt.PublicKeySet, error) {
+	var (
+		set  ireceipt.PublicKeySet
+		data []byte
+	)
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, source, nil)
+		if err != nil {
+			return set, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return set, fmt.Errorf("fetch public keys: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, _ = io.ReadAll(resp.Body)
+		if resp.StatusCode >= http.StatusBadRequest {
+			return set, fmt.Errorf("fetch public keys (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			return set, fmt.Errorf("read public keys %s: %w", source, err)
+		}
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return set, fmt.Errorf("parse public keys %s: %w", source, err)
+	}
+	return set, nil
+}
+
+// checkSignatures replaces the server's signature verdict with one computed
+// locally against keys, dropping any signature drift the server reported so it
+// is not counted twice.
+func checkSignatures(result *ireceipt.VerifyResult, committed *ireceipt.Receipt, keys ireceipt.PublicKeySet) {
+	drifts := result.Drifts[:0]
+	for _, d := range result.Drifts {
+		if d.Kind != ireceipt.DriftSignature {
+			drifts = append(drifts, d)
+		}
+	}
+	result.Drifts = drifts
+	result.Match = result.ExpectedDigest == result.ActualDigest && !result.Degraded
+	result.CheckSignatures(committed, keys)
+}
+
 // printHuman renders a concise, honest verdict and any drift to stdout.
 func printHuman(cmd *cobra.Command, result *ireceipt.VerifyResult) {
 	out := cmd.OutOrStdout()
@@ -118,6 +194,9 @@ func printHuman(cmd *cobra.Command, result *ireceipt.VerifyResult) {
 	case result.Match:
 		_, _ = fmt.Fprintf(out, "OK: run %s matches the committed receipt (digest %s)\n",
 			result.RunID, shortDigest(result.ActualDigest))
+	case result.Signature != nil && result.Signature.Status == ireceipt.SignatureInvalid:
+		_, _ = fmt.Fprintf(out, "INVALID SIGNATURE: the receipt for run %s was not issued as presented by a key in the public key set.\n",
+			result.RunID)
 	case result.Degraded:
 		_, _ = fmt.Fprintf(out, "UNVERIFIABLE: run %s ran on unpinned image tag(s); the receipt cannot attest reproducibility.\n",
 			result.RunID)
@@ -130,6 +209,10 @@ func printHuman(cmd *cobra.Command, result *ireceipt.VerifyResult) {
 			shortDigest(result.ExpectedDigest), shortDigest(result.ActualDigest))
 	}
 
+	if result.Signature != nil {
+		_, _ = fmt.Fprintf(out, "  signature: %s (%s)\n", result.Signature.Status, result.Signature.Detail)
+	}
+
 	for _, d := range result.Drifts {
 		if d.Task != "" {
 			_, _ = fmt.Fprintf(out, "  - [%s] task %q: %s\n", d.Kind, d.Task, d.Detail)
@@ -154,4 +237,6 @@ func shortDigest(d string) string {
 func init() {
 	Cmd.Flags().StringVar(&verifyServer, "server", "http://localhost:8080", "Caesium server base URL")
 	Cmd.Flags().BoolVar(&verifyJSON, "json", false, "Emit the full machine-readable verify result as JSON")
+	Cmd.Flags().StringVar(&verifyPublicKeys, "public-keys", "", "Receipt public key set to verify signatures against, as a file or URL (default: the server's /.well-known/caesium-receipt-keys)")
+	Cmd.Flags().BoolVar(&verifyRequireSignature, "require-signature", false, "Fail unless the receipt carries a valid signature from a key in the public key set")
 }
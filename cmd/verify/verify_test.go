@@ -51,3 +51,38 @@ func TestShortDigest(t *testing.T) {
 	require.Equal(t, "short", shortDigest("short"))
 	require.Equal(t, "0123456789abcdef…", shortDigest("0123456789abcdef0123456789abcdef"))
 }
+
+// TestLoadPublicKeysFile: a key set written to disk loads back for offline
+// verification.
+func TestLoadPublicKeysFile(t *testing.T) {
+	set := ireceipt.PublicKeySet{Keys: []ireceipt.PublicKey{{KeyID: "k1", Algorithm: "ed25519", PublicKey: "AAAA"}}}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	loaded, err := loadPublicKeys(Cmd, path)
+	require.NoError(t, err)
+	require.Equal(t, set, loaded)
+
+	_, err = loadPublicKeys(Cmd, filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+// TestCheckSignaturesReplacesServerVerdict: the local check against the
+// caller's key set wins over the server's, without double-counting drift.
+func TestCheckSignaturesReplacesServerVerdict(t *testing.T) {
+	committed := &ireceipt.Receipt{RunID: uuid.New(), JobID: uuid.New()}
+	result := &ireceipt.VerifyResult{
+		ExpectedDigest: "abc",
+		ActualDigest:   "abc",
+		Drifts:         []ireceipt.Drift{{Kind: ireceipt.DriftSignature, Detail: "server says invalid"}},
+		Signature:      &ireceipt.SignatureCheck{Status: ireceipt.SignatureInvalid},
+	}
+
+	checkSignatures(result, committed, ireceipt.PublicKeySet{})
+	require.True(t, result.Match)
+	require.Empty(t, result.Drifts)
+	require.Equal(t, ireceipt.SignatureUnsigned, result.Signature.Status)
+}
@@ -50,21 +50,37 @@ type explanation struct {
 		Kind  string `json:"kind"`
 		RunID string `json:"runId"`
 	} `json:"baseline"`
-	Diff *struct {
-		HashEqual    bool   `json:"hashEqual"`
-		SubjectHash  string `json:"subjectHash"`
-		BaselineHash string `json:"baselineHash"`
-		Degraded     string `json:"degraded"`
-		Changes      []struct {
-			Field    string `json:"field"`
-			Kind     string `json:"kind"`
-			Before   string `json:"before"`
-			After    string `json:"after"`
-			Added    bool   `json:"added"`
-			Removed  bool   `json:"removed"`
-			Redacted bool   `json:"redacted"`
-		} `json:"changes"`
-	} `json:"diff"`
+	Diff      *blobDiff `json:"diff"`
+	Instances []struct {
+		Index   int       `json:"index"`
+		Item    string    `json:"item"`
+		Status  string    `json:"status"`
+		Verdict string    `json:"verdict"`
+		Diff    *blobDiff `json:"diff"`
+	} `json:"instances"`
+	PokeCount int64 `json:"pokeCount"`
+	Pokes     []struct {
+		Probe     string `json:"probe"`
+		Satisfied bool   `json:"satisfied"`
+		Detail    string `json:"detail"`
+		At        string `json:"at"`
+	} `json:"pokes"`
+}
+
+type blobDiff struct {
+	HashEqual    bool   `json:"hashEqual"`
+	SubjectHash  string `json:"subjectHash"`
+	BaselineHash string `json:"baselineHash"`
+	Degraded     string `json:"degraded"`
+	Changes      []struct {
+		Field    string `json:"field"`
+		Kind     string `json:"kind"`
+		Before   string `json:"before"`
+		After    string `json:"after"`
+		Added    bool   `json:"added"`
+		Removed  bool   `json:"removed"`
+		Redacted bool   `json:"redacted"`
+	} `json:"changes"`
 }
 
 // Cmd is the `caesium why` command.
@@ -167,6 +183,44 @@ func renderTable(cmd *cobra.Command, exp *explanation) {
 	}
 	_ = tw.Flush()
 
+	// A mapped step's group row has no hash inputs of its own; its instances
+	// carry the explanation, collapsed to one line each.
+	if len(exp.Instances) > 0 {
+		_, _ = fmt.Fprintln(out)
+		_, _ = fmt.Fprintf(out, "Instances (%d):\n", len(exp.Instances))
+		iw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(iw, "INDEX\tITEM\tSTATUS\tVERDICT\tCHANGED")
+		for _, inst := range exp.Instances {
+			changed := "-"
+			if inst.Diff != nil && len(inst.Diff.Changes) > 0 {
+				changed = inst.Diff.Changes[0].Field
+				if n := len(inst.Diff.Changes); n > 1 {
+					changed = fmt.Sprintf("%s (+%d)", changed, n-1)
+				}
+			}
+			_, _ = fmt.Fprintf(iw, "%d\t%s\t%s\t%s\t%s\n", inst.Index, inst.Item, inst.Status, inst.Verdict, changed)
+		}
+		_ = iw.Flush()
+		return
+	}
+
+	// A sensor step runs no container; its pokes are the explanation.
+	if len(exp.Pokes) > 0 {
+		_, _ = fmt.Fprintln(out)
+		if exp.PokeCount > int64(len(exp.Pokes)) {
+			_, _ = fmt.Fprintf(out, "Pokes (last %d of %d):\n", len(exp.Pokes), exp.PokeCount)
+		} else {
+			_, _ = fmt.Fprintf(out, "Pokes (%d):\n", len(exp.Pokes))
+		}
+		pw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(pw, "AT\tPROBE\tSATISFIED\tDETAIL")
+		for _, poke := range exp.Pokes {
+			_, _ = fmt.Fprintf(pw, "%s\t%s\t%t\t%s\n", poke.At, poke.Probe, poke.Satisfied, poke.Detail)
+		}
+		_ = pw.Flush()
+		return
+	}
+
 	if exp.Diff == nil {
 		return
 	}
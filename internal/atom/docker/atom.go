@@ -32,6 +32,14 @@ func (c *Atom) State() atom.State {
 // Result returns the result of the Atom. This function
 // maps Docker container exit codes to Caesium Atom results.
 func (c *Atom) Result() atom.Result {
+	if c.metadata.State == nil {
+		return atom.Unknown
+	}
+	// The kernel OOM killer delivers SIGKILL, so the exit code alone (137) is
+	// indistinguishable from an operator kill; Docker's flag is authoritative.
+	if c.metadata.State.OOMKilled {
+		return atom.ResourceFailure
+	}
 	if result, ok := resultMap[c.metadata.State.ExitCode]; ok {
 		return result
 	}
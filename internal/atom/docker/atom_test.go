@@ -69,4 +69,17 @@ func (s *DockerTestSuite) TestAtom() {
 	}
 
 	assert.Equal(s.T(), atom.Unknown, c.Result())
+
+	// OOM kill takes precedence over the SIGKILL exit code
+	c = &Atom{
+		metadata: newContainer(
+			testAtomID,
+			&container.State{
+				ExitCode:  137,
+				OOMKilled: true,
+			},
+		),
+	}
+
+	assert.Equal(s.T(), atom.ResourceFailure, c.Result())
 }
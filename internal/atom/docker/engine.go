@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"slices"
+	"strconv"
 	"time"
 
 	"github.com/caesium-cloud/caesium/internal/atom"
@@ -113,6 +114,14 @@ func (e *dockerEngine) Create(req *atom.EngineCreateRequest) (atom.Atom, error)
 	if mounts := convertMounts(req.Spec.Mounts, req.Spec.ResolvedVolumeMounts); len(mounts) > 0 {
 		hostCfg = &dockercontainer.HostConfig{Mounts: mounts}
 	}
+	if req.Spec.HasResources() {
+		if hostCfg == nil {
+			hostCfg = &dockercontainer.HostConfig{}
+		}
+		if err := applyResources(hostCfg, req.Spec.Resources); err != nil {
+			return nil, err
+		}
+	}
 
 	log.Info("creating docker container", "image", req.Image)
 
@@ -265,6 +274,45 @@ func formatEnv(values map[string]string) []string {
 	return env
 }
 
+// applyResources maps container.Resources onto Docker's native limits. CPU
+// becomes NanoCPUs and memory a hard limit with swap disabled so the OOM
+// killer fires at the declared size. Ephemeral storage uses the "size"
+// storage option, which requires a storage driver that supports per-container
+// quotas (overlay2 on xfs with pquota, devicemapper, btrfs, zfs). GPUs are
+// requested through the NVIDIA device driver.
+func applyResources(hostCfg *dockercontainer.HostConfig, resources *container.Resources) error {
+	if resources.CPU != "" {
+		millis, err := container.ParseCPUMillis(resources.CPU)
+		if err != nil {
+			return err
+		}
+		hostCfg.NanoCPUs = millis * 1_000_000
+	}
+	if resources.Memory != "" {
+		bytes, err := container.ParseBytes(resources.Memory)
+		if err != nil {
+			return err
+		}
+		hostCfg.Memory = bytes
+		hostCfg.MemorySwap = bytes
+	}
+	if resources.EphemeralStorage != "" {
+		bytes, err := container.ParseBytes(resources.EphemeralStorage)
+		if err != nil {
+			return err
+		}
+		hostCfg.StorageOpt = map[string]string{"size": strconv.FormatInt(bytes, 10)}
+	}
+	if resources.GPUCount > 0 {
+		hostCfg.DeviceRequests = []dockercontainer.DeviceRequest{{
+			Driver:       "nvidia",
+			Count:        resources.GPUCount,
+			Capabilities: [][]string{{"gpu"}},
+		}}
+	}
+	return nil
+}
+
 func convertMounts(specMounts []container.Mount, resolvedMounts []container.VolumeMount) []mount.Mount {
 	if len(specMounts) == 0 && len(resolvedMounts) == 0 {
 		return nil
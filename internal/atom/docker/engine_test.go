@@ -248,6 +248,53 @@ func (s *DockerTestSuite) TestCreateAppliesResolvedVolumeMounts() {
 	s.engine.backend.(*mockDockerBackend).AssertExpectations(s.T())
 }
 
+func (s *DockerTestSuite) TestCreateAppliesResources() {
+	req := &atom.EngineCreateRequest{
+		Name:    testContainerName,
+		Image:   testImage,
+		Command: []string{"run"},
+		Spec: container.Spec{
+			Resources: &container.Resources{
+				CPU:              "1500m",
+				Memory:           "512Mi",
+				EphemeralStorage: "10G",
+				GPUCount:         2,
+			},
+		},
+	}
+
+	s.engine.backend.(*mockDockerBackend).
+		On("ImageInspect", req.Image).
+		Return(nil)
+
+	hostMatcher := mock.MatchedBy(func(host *dockercontainer.HostConfig) bool {
+		if host == nil || len(host.Mounts) != 0 || len(host.DeviceRequests) != 1 {
+			return false
+		}
+		gpu := host.DeviceRequests[0]
+		return host.NanoCPUs == 1_500_000_000 &&
+			host.Memory == 512<<20 &&
+			host.MemorySwap == 512<<20 &&
+			host.StorageOpt["size"] == "10000000000" &&
+			gpu.Count == 2 &&
+			len(gpu.Capabilities) == 1 && gpu.Capabilities[0][0] == "gpu"
+	})
+
+	s.engine.backend.(*mockDockerBackend).
+		On("ContainerCreate", mock.AnythingOfType("*container.Config"), hostMatcher, req.Name).
+		Return()
+	s.engine.backend.(*mockDockerBackend).
+		On("ContainerStart", testAtomID).
+		Return()
+	s.engine.backend.(*mockDockerBackend).
+		On("ContainerInspect", testAtomID).
+		Return()
+
+	_, err := s.engine.Create(req)
+	s.Require().NoError(err)
+	s.engine.backend.(*mockDockerBackend).AssertExpectations(s.T())
+}
+
 func (s *DockerTestSuite) TestCreateSkipsPullWhenImageAlreadyPresent() {
 	req := &atom.EngineCreateRequest{
 		Name:    testContainerName,
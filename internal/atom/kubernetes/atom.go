@@ -36,8 +36,19 @@ func (c *Atom) State() atom.State {
 
 // Result returns the result of the Atom. This function
 // maps pod container exit codes to Caesium Atom results.
+//
+// Resource exhaustion is detected from the reasons the kubelet records rather
+// than the exit code: an OOM-killed container and an evicted pod (node memory
+// or ephemeral-storage pressure, or exceeding an ephemeral-storage limit) both
+// map to atom.ResourceFailure.
 func (c *Atom) Result() atom.Result {
+	if c.metadata.Status.Reason == podReasonEvicted {
+		return atom.ResourceFailure
+	}
 	if term := terminatedState(c.metadata); term != nil {
+		if term.Reason == containerReasonOOMKilled {
+			return atom.ResourceFailure
+		}
 		if result, ok := resultMap[term.ExitCode]; ok {
 			return result
 		}
@@ -96,4 +96,43 @@ func (s *KubernetesTestSuite) TestAtom() {
 	}
 
 	assert.Equal(s.T(), atom.Unknown, c.Result())
+
+	// OOM-killed container takes precedence over the SIGKILL exit code
+	c = &Atom{
+		metadata: newPod(
+			testAtomID,
+			v1.PodStatus{
+				Phase: v1.PodFailed,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						State: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{
+								ExitCode: 137,
+								Reason:   "OOMKilled",
+							},
+						},
+					},
+				},
+			},
+			time.Now(),
+			time.Now(),
+		),
+	}
+
+	assert.Equal(s.T(), atom.ResourceFailure, c.Result())
+
+	// evicted pod, with no terminated container state
+	c = &Atom{
+		metadata: newPod(
+			testAtomID,
+			v1.PodStatus{
+				Phase:  v1.PodFailed,
+				Reason: "Evicted",
+			},
+			time.Now(),
+			time.Now(),
+		),
+	}
+
+	assert.Equal(s.T(), atom.ResourceFailure, c.Result())
 }
@@ -122,6 +122,10 @@ func (e *kubernetesEngine) Create(req *atom.EngineCreateRequest) (atom.Atom, err
 		return nil, err
 	}
 	envVars := convertEnvVars(req.Spec.Env)
+	resources, err := convertResources(req.Spec.Resources)
+	if err != nil {
+		return nil, err
+	}
 
 	spec := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -141,6 +145,7 @@ func (e *kubernetesEngine) Create(req *atom.EngineCreateRequest) (atom.Atom, err
 					Env:             envVars,
 					WorkingDir:      req.Spec.WorkDir,
 					VolumeMounts:    volumeMounts,
+					Resources:       resources,
 					ImagePullPolicy: v1.PullIfNotPresent,
 				},
 			},
@@ -391,3 +396,32 @@ func sanitizeVolumeName(value string) string {
 	}
 	return value
 }
+
+// convertResources maps container.Resources onto the container's requests and
+// limits. Requests equal limits so the pod is scheduled for exactly what it may
+// use (Guaranteed QoS when both cpu and memory are set), and exceeding the
+// memory or ephemeral-storage limit surfaces as an OOM kill or eviction.
+func convertResources(resources *container.Resources) (v1.ResourceRequirements, error) {
+	if resources.IsZero() {
+		return v1.ResourceRequirements{}, nil
+	}
+	list := v1.ResourceList{}
+	for name, value := range map[v1.ResourceName]string{
+		v1.ResourceCPU:              resources.CPU,
+		v1.ResourceMemory:           resources.Memory,
+		v1.ResourceEphemeralStorage: resources.EphemeralStorage,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return v1.ResourceRequirements{}, fmt.Errorf("resources.%s: %w", name, err)
+		}
+		list[name] = quantity
+	}
+	if resources.GPUCount > 0 {
+		list[v1.ResourceName(container.GPUResourceName)] = *resource.NewQuantity(int64(resources.GPUCount), resource.DecimalSI)
+	}
+	return v1.ResourceRequirements{Requests: list, Limits: list.DeepCopy()}, nil
+}
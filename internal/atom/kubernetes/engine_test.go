@@ -215,6 +215,48 @@ func (s *KubernetesTestSuite) TestCreateAppliesResolvedVolumesAndIdentity() {
 // Caesium management label). The label is all Caesium sets — Kueue's webhook
 // gates the pod for admission, which is how scheduling is delegated rather than
 // performed by Caesium.
+// TestCreateAppliesResources asserts step resources become identical
+// container requests and limits, with GPUs as the nvidia.com/gpu resource.
+func (s *KubernetesTestSuite) TestCreateAppliesResources() {
+	req := &atom.EngineCreateRequest{
+		Name:    testAtomID,
+		Image:   testImage,
+		Command: []string{"test"},
+		Spec: container.Spec{
+			Resources: &container.Resources{
+				CPU:              "500m",
+				Memory:           "2Gi",
+				EphemeralStorage: "10Gi",
+				GPUCount:         1,
+			},
+		},
+	}
+
+	podMatcher := mock.MatchedBy(func(pod *v1.Pod) bool {
+		res := pod.Spec.Containers[0].Resources
+		for _, list := range []v1.ResourceList{res.Requests, res.Limits} {
+			if list.Cpu().MilliValue() != 500 ||
+				list.Memory().Value() != 2<<30 ||
+				list.StorageEphemeral().Value() != 10<<30 {
+				return false
+			}
+			gpu := list[v1.ResourceName("nvidia.com/gpu")]
+			if gpu.Value() != 1 {
+				return false
+			}
+		}
+		return true
+	})
+
+	s.engine.backend.(*mockKubernetesBackend).
+		On("Create", podMatcher).
+		Return()
+
+	_, err := s.engine.Create(req)
+	s.Require().NoError(err)
+	s.engine.backend.(*mockKubernetesBackend).AssertExpectations(s.T())
+}
+
 func (s *KubernetesTestSuite) TestCreateDelegatesToKueue() {
 	req := &atom.EngineCreateRequest{
 		Name:    testAtomID,
@@ -29,4 +29,13 @@ var (
 	}
 )
 
-const kubeConfig = ".kube/config"
+const (
+	kubeConfig = ".kube/config"
+
+	// podReasonEvicted is the pod status reason the kubelet sets when it
+	// evicts a pod under node pressure or for exceeding ephemeral storage.
+	podReasonEvicted = "Evicted"
+	// containerReasonOOMKilled is the terminated-state reason for a container
+	// killed by the kernel OOM killer.
+	containerReasonOOMKilled = "OOMKilled"
+)
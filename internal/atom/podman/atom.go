@@ -24,6 +24,14 @@ func (a *Atom) State() atom.State {
 }
 
 func (a *Atom) Result() atom.Result {
+	if a.metadata.State == nil {
+		return atom.Unknown
+	}
+	// An OOM kill surfaces as exit 137, the same as any SIGKILL; Podman's
+	// flag is what distinguishes resource exhaustion.
+	if a.metadata.State.OOMKilled {
+		return atom.ResourceFailure
+	}
 	if result, ok := resultMap[int(a.metadata.State.ExitCode)]; ok {
 		return result
 	}
@@ -99,6 +99,11 @@ func (e *podmanEngine) Create(req *atom.EngineCreateRequest) (atom.Atom, error)
 		spec.Mounts = mounts
 		spec.Volumes = volumes
 	}
+	if req.Spec.HasResources() {
+		if err := applyPodmanResources(spec, req.Spec.Resources); err != nil {
+			return nil, err
+		}
+	}
 
 	created, err := e.backend.ContainerCreate(spec)
 	if err != nil {
@@ -119,6 +124,53 @@ func (e *podmanEngine) Create(req *atom.EngineCreateRequest) (atom.Atom, error)
 	return e.Get(&atom.EngineGetRequest{ID: created.ID})
 }
 
+// podmanCPUPeriod is the CFS period, in microseconds, that CPU quotas are
+// expressed against (the kernel default).
+const podmanCPUPeriod uint64 = 100_000
+
+// applyPodmanResources maps container.Resources onto Podman's native limits:
+// a CFS CPU quota, a hard memory limit with swap disabled, the "size" storage
+// option for ephemeral storage, and CDI GPU devices (nvidia.com/gpu=<index>),
+// mirroring how `podman run --device nvidia.com/gpu=<n>` resolves devices.
+//
+// Podman has no count-based GPU request and no device allocator, so a request
+// for n GPUs is always the CDI devices 0..n-1: concurrent GPU steps on the
+// same host share those devices. Isolate them with pools or node affinity.
+func applyPodmanResources(spec *specgen.SpecGenerator, resources *container.Resources) error {
+	limits := &specs.LinuxResources{}
+	if resources.CPU != "" {
+		millis, err := container.ParseCPUMillis(resources.CPU)
+		if err != nil {
+			return err
+		}
+		period := podmanCPUPeriod
+		quota := millis * int64(podmanCPUPeriod) / 1000
+		limits.CPU = &specs.LinuxCPU{Period: &period, Quota: &quota}
+	}
+	if resources.Memory != "" {
+		bytes, err := container.ParseBytes(resources.Memory)
+		if err != nil {
+			return err
+		}
+		swap := bytes
+		limits.Memory = &specs.LinuxMemory{Limit: &bytes, Swap: &swap}
+	}
+	if limits.CPU != nil || limits.Memory != nil {
+		spec.ResourceLimits = limits
+	}
+	if resources.EphemeralStorage != "" {
+		bytes, err := container.ParseBytes(resources.EphemeralStorage)
+		if err != nil {
+			return err
+		}
+		spec.StorageOpts = map[string]string{"size": strconv.FormatInt(bytes, 10)}
+	}
+	for i := 0; i < resources.GPUCount; i++ {
+		spec.Devices = append(spec.Devices, specs.LinuxDevice{Path: container.GPUResourceName + "=" + strconv.Itoa(i)})
+	}
+	return nil
+}
+
 func (e *podmanEngine) ensureImagePresent(imageRef string) error {
 	if imageRef != "" {
 		exists, err := e.backend.ImageExists(imageRef)
@@ -265,6 +265,53 @@ func (s *PodmanTestSuite) TestCreateSkipsPullWhenImageAlreadyPresent() {
 	s.engine.backend.(*mockPodmanBackend).AssertExpectations(s.T())
 }
 
+func (s *PodmanTestSuite) TestCreateAppliesResources() {
+	req := &atom.EngineCreateRequest{
+		Name:    testContainerName,
+		Image:   testImage,
+		Command: []string{"test"},
+		Spec: container.Spec{
+			Resources: &container.Resources{
+				CPU:              "250m",
+				Memory:           "1Gi",
+				EphemeralStorage: "5Gi",
+				GPUCount:         2,
+			},
+		},
+	}
+
+	specMatcher := mock.MatchedBy(func(spec *specgen.SpecGenerator) bool {
+		limits := spec.ResourceLimits
+		if limits == nil || limits.CPU == nil || limits.Memory == nil || len(spec.Devices) != 2 {
+			return false
+		}
+		return *limits.CPU.Period == 100_000 &&
+			*limits.CPU.Quota == 25_000 &&
+			*limits.Memory.Limit == 1<<30 &&
+			*limits.Memory.Swap == 1<<30 &&
+			spec.StorageOpts["size"] == "5368709120" &&
+			spec.Devices[0].Path == "nvidia.com/gpu=0" &&
+			spec.Devices[1].Path == "nvidia.com/gpu=1"
+	})
+
+	s.engine.backend.(*mockPodmanBackend).
+		On("ImageExists", req.Image).
+		Return(true, nil)
+	s.engine.backend.(*mockPodmanBackend).
+		On("ContainerCreate", specMatcher).
+		Return()
+	s.engine.backend.(*mockPodmanBackend).
+		On("ContainerStart", testAtomID).
+		Return()
+	s.engine.backend.(*mockPodmanBackend).
+		On("ContainerInspect", testAtomID).
+		Return()
+
+	_, err := s.engine.Create(req)
+	assert.Nil(s.T(), err)
+	s.engine.backend.(*mockPodmanBackend).AssertExpectations(s.T())
+}
+
 func (s *PodmanTestSuite) TestCreateError() {
 	req := &atom.EngineCreateRequest{
 		Name:    "fail",
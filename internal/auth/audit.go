@@ -16,6 +16,7 @@ const (
 	OutcomeSuccess = "success"
 	OutcomeDenied  = "denied"
 	OutcomeError   = "error"
+	OutcomeWarned  = "warned"
 )
 
 // AuditAction enumerates well-known auditable actions.
@@ -36,6 +37,9 @@ const (
 	ActionJobUnpause         = "job.unpause"
 	ActionRunTrigger         = "run.trigger"
 	ActionRunRetry           = "run.retry"
+	ActionRunCancel          = "run.cancel"
+	ActionTaskApprove        = "task.approve"
+	ActionTaskReject         = "task.reject"
 	ActionRunQueueRead       = "run_queue.read"
 	ActionRunQueueCancel     = "run_queue.cancel"
 	ActionBackfill           = "run.backfill"
@@ -44,7 +48,9 @@ const (
 	ActionCacheDelete        = "cache.delete"
 	ActionLogLevel           = "log.set_level"
 	ActionDBQuery            = "database.query"
+	ActionDBBackup           = "database.backup"
 	ActionWebhookDenied      = "webhook.denied"
+	ActionImagePolicy        = "image_policy.violation"
 )
 
 // AuditLogger writes structured audit log entries to the database.
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/google/uuid"
 )
@@ -22,6 +25,9 @@ type Principal struct {
 	Subject string // audit actor: key prefix or user email.
 	UserID  *uuid.UUID
 	KeyID   *uuid.UUID
+	// Groups are the IdP groups of an SSO user, as of their last login.
+	// API keys carry none.
+	Groups []string
 }
 
 // PrincipalFromKey builds a Principal from a validated API key.
@@ -37,13 +43,39 @@ func PrincipalFromKey(k *models.APIKey) *Principal {
 }
 
 // PrincipalFromUser builds a Principal from an authenticated user. SSO users are
-// unscoped (nil Scope) in v1.
+// unscoped (nil Scope) unless their role mapping restricted them to namespaces.
 func PrincipalFromUser(u *models.User) *Principal {
 	id := u.ID
+	var groups []string
+	if len(u.Groups) > 0 {
+		_ = json.Unmarshal(u.Groups, &groups)
+	}
+	var scope []byte
+	if len(u.Namespaces) > 0 {
+		// Embed the stored list verbatim so a corrupt value fails scope
+		// decoding (deny) instead of dropping the restriction.
+		scope = []byte(`{"namespaces":` + string(u.Namespaces) + `}`)
+	}
 	return &Principal{
 		Kind:    PrincipalUser,
 		Role:    u.Role,
+		Scope:   scope,
 		Subject: u.Email,
 		UserID:  &id,
+		Groups:  groups,
+	}
+}
+
+// InAnyGroup reports whether the principal belongs to at least one of the
+// given groups. Group names compare case-insensitively, as IdPs differ in how
+// they case them.
+func (p *Principal) InAnyGroup(groups []string) bool {
+	for _, want := range groups {
+		for _, have := range p.Groups {
+			if strings.EqualFold(strings.TrimSpace(have), strings.TrimSpace(want)) {
+				return true
+			}
+		}
 	}
+	return false
 }
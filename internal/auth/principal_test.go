@@ -36,3 +36,26 @@ func TestPrincipalFromUser(t *testing.T) {
 	assert.Nil(t, p.Scope)
 	assert.Nil(t, p.KeyID)
 }
+
+func TestPrincipalFromUserGroups(t *testing.T) {
+	u := &models.User{ID: uuid.New(), Email: "a@b.com", Role: models.RoleOperator, Groups: []byte(`["data-eng","Release-Managers"]`)}
+	p := PrincipalFromUser(u)
+	assert.Equal(t, []string{"data-eng", "Release-Managers"}, p.Groups)
+	assert.True(t, p.InAnyGroup([]string{"release-managers"}))
+	assert.True(t, p.InAnyGroup([]string{"finance", "data-eng"}))
+	assert.False(t, p.InAnyGroup([]string{"finance"}))
+	assert.False(t, p.InAnyGroup(nil))
+
+	key := PrincipalFromKey(&models.APIKey{ID: uuid.New(), KeyPrefix: "csk_live_ab", Role: models.RoleAdmin})
+	assert.False(t, key.InAnyGroup([]string{"data-eng"}))
+}
+
+func TestPrincipalFromUserNamespaces(t *testing.T) {
+	u := &models.User{ID: uuid.New(), Email: "a@b.com", Role: models.RoleOperator, Namespaces: []byte(`["team-a"]`)}
+	p := PrincipalFromUser(u)
+	scope, err := DecodeScope(p.Scope)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team-a"}, scope.Namespaces)
+	assert.True(t, CheckJobScope(p.Scope, ScopedJob{Namespace: "team-a", Alias: "etl"}))
+	assert.False(t, CheckJobScope(p.Scope, ScopedJob{Namespace: "default", Alias: "etl"}))
+}
@@ -82,13 +82,13 @@ func (s *SSOService) Complete(ctx context.Context, ext *ExternalIdentity, method
 		return "", nil, ErrInvalidExternalIdentity
 	}
 
-	role, ok := s.roles.Resolve(ext.Groups)
+	role, namespaces, ok := s.roles.ResolveScoped(ext.Groups)
 	if !ok {
 		outcome = OutcomeDenied
 		s.auditLoginDenied(ext, method, ip, "no_role_mapping")
 		return "", nil, ErrLoginDenied
 	}
-	user, created, err := s.users.upsert(ctx, ext, role)
+	user, created, err := s.users.upsert(ctx, ext, role, namespaces)
 	if err != nil {
 		s.auditLoginError(ext, method, ip, "user_upsert_failed")
 		return "", nil, err
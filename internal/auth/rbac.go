@@ -1,6 +1,9 @@
 package auth
 
-import "github.com/caesium-cloud/caesium/internal/models"
+import (
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+)
 
 // RequiredRole returns the minimum role needed for a given HTTP method + path.
 func RequiredRole(method, path string) (models.Role, bool) {
@@ -13,9 +16,17 @@ func HasRole(keyRole, required models.Role) bool {
 	return models.RoleLevel(keyRole) >= models.RoleLevel(required)
 }
 
-// CheckScope validates whether the key is allowed to act on the given job alias.
-// A nil/empty scope means unrestricted access.
+// CheckScope validates whether the key is allowed to act on the given job alias
+// in the default namespace. A nil/empty scope means unrestricted access.
 func CheckScope(scopeJSON []byte, jobAlias string) bool {
+	return CheckJobScope(scopeJSON, ScopedJob{Alias: jobAlias})
+}
+
+// CheckJobScope validates whether the key is allowed to act on job: its
+// namespace and alias must together match a scoped job entry ("namespace/alias",
+// or a bare alias in the default namespace) and its namespace must be among the
+// scoped namespaces, where either list left empty allows any.
+func CheckJobScope(scopeJSON []byte, job ScopedJob) bool {
 	scope, err := DecodeScope(scopeJSON)
 	if err != nil {
 		return false // malformed scope denies access
@@ -23,7 +34,8 @@ func CheckScope(scopeJSON []byte, jobAlias string) bool {
 	if scope == nil {
 		return true
 	}
-	return containsScopedJob(scope.Jobs, jobAlias)
+	return containsScopedJob(scope.Jobs, job) &&
+		containsScopedName(scope.Namespaces, jobdef.NamespaceOrDefault(job.Namespace))
 }
 
 // endpointPolicy maps "METHOD /path-pattern" to the minimum required role.
@@ -33,6 +45,8 @@ var endpointPolicy = map[string]models.Role{
 
 	// Viewer
 	"GET /metrics":                              models.RoleViewer,
+	"GET /gql":                                  models.RoleViewer,
+	"POST /gql":                                 models.RoleViewer,
 	"GET /auth/whoami":                          models.RoleViewer,
 	"POST /auth/logout":                         models.RoleViewer,
 	"GET /v1/jobs":                              models.RoleViewer,
@@ -50,6 +64,7 @@ var endpointPolicy = map[string]models.Role{
 	"GET /v1/jobs/:id/topology/history":         models.RoleViewer,
 	"GET /v1/jobs/:id/runs/:id/receipt":         models.RoleViewer,
 	"POST /v1/jobs/:id/runs/:id/receipt/verify": models.RoleViewer,
+	"GET /v1/jobs/:id/runs/:id/provenance":      models.RoleViewer,
 	"GET /v1/jobs/:id/cache":                    models.RoleViewer,
 	"GET /v1/jobs/:id/backfills":                models.RoleViewer,
 	"GET /v1/jobs/:id/backfills/:id":            models.RoleViewer,
@@ -72,9 +87,13 @@ var endpointPolicy = map[string]models.Role{
 	"GET /v1/notifications/policies/:id":        models.RoleViewer,
 	"GET /v1/agentprofiles":                     models.RoleViewer,
 	"GET /v1/agentprofiles/:id":                 models.RoleViewer,
+	"GET /v1/pools":                             models.RoleViewer,
+	"GET /v1/pools/:id":                         models.RoleViewer,
 	"POST /v1/jobdefs/lint":                     models.RoleViewer,
 	"POST /v1/jobdefs/diff":                     models.RoleViewer,
 	"GET /v1/lineage/impact":                    models.RoleViewer,
+	"GET /v1/lineage/upstream":                  models.RoleViewer,
+	"GET /v1/lineage/column-impact":             models.RoleViewer,
 
 	"GET /v1/jobs/:id/runs/:id/tasks/:id/descriptor": models.RoleViewer,
 	// Incident operator read API (agent-in-the-loop D2).
@@ -105,9 +124,11 @@ var endpointPolicy = map[string]models.Role{
 	"POST /v1/jobs/:id/run":                      models.RoleRunner,
 	"POST /v1/jobs/:id/runs/:id/replay":          models.RoleRunner,
 	"POST /v1/jobs/:id/runs/:id/retry":           models.RoleRunner,
+	"POST /v1/jobs/:id/runs/:id/cancel":          models.RoleRunner,
 	"POST /v1/jobs/:id/runs/:id/callbacks/retry": models.RoleRunner,
 	"POST /v1/jobs/:id/backfill":                 models.RoleRunner,
 	"POST /v1/events":                            models.RoleRunner,
+	"POST /v1/lineage":                           models.RoleRunner,
 	"POST /v1/triggers/:id/fire":                 models.RoleRunner,
 
 	// Operator
@@ -121,6 +142,8 @@ var endpointPolicy = map[string]models.Role{
 	"DELETE /v1/jobs/:id/cache":             models.RoleOperator,
 	"DELETE /v1/jobs/:id/cache/:id":         models.RoleOperator,
 	"PUT /v1/jobs/:id/backfills/:id/cancel": models.RoleOperator,
+	"PUT /v1/jobs/:id/backfills/:id/pause":  models.RoleOperator,
+	"PUT /v1/jobs/:id/backfills/:id/resume": models.RoleOperator,
 	"POST /v1/triggers":                     models.RoleOperator,
 	"PATCH /v1/triggers/:id":                models.RoleOperator,
 	"POST /v1/atoms":                        models.RoleOperator,
@@ -134,16 +157,27 @@ var endpointPolicy = map[string]models.Role{
 	"POST /v1/agentprofiles":                models.RoleOperator,
 	"PATCH /v1/agentprofiles/:id":           models.RoleOperator,
 	"DELETE /v1/agentprofiles/:id":          models.RoleOperator,
+	"PUT /v1/pools/:id":                     models.RoleOperator,
+	"DELETE /v1/pools/:id":                  models.RoleOperator,
+	"PUT /v1/system/nodes/:id/cordon":       models.RoleOperator,
+	"PUT /v1/system/nodes/:id/drain":        models.RoleOperator,
+	"PUT /v1/system/nodes/:id/uncordon":     models.RoleOperator,
 	// Tier-3 approval decisions (agent-in-the-loop D1). Operator-gated; agent
 	// session tokens are additionally rejected outright in authorizeScope.
 	"POST /v1/incidents/:id/approvals/:id/approve": models.RoleOperator,
 	"POST /v1/incidents/:id/approvals/:id/reject":  models.RoleOperator,
+	// Step approval gates. A gate naming approvers additionally requires an
+	// SSO user in one of those groups (checked in the controller).
+	"POST /v1/jobs/:id/runs/:id/tasks/:id/approve": models.RoleOperator,
+	"POST /v1/jobs/:id/runs/:id/tasks/:id/reject":  models.RoleOperator,
 
 	// Admin
 	"PUT /v1/logs/level":            models.RoleAdmin,
 	"GET /v1/logs/level":            models.RoleAdmin,
 	"GET /v1/logs/stream":           models.RoleAdmin,
 	"GET /v1/database/schema":       models.RoleAdmin,
+	"POST /v1/admin/backup":         models.RoleAdmin,
+	"GET /v1/admin/shards":          models.RoleAdmin,
 	"POST /v1/database/query":       models.RoleAdmin,
 	"GET /v1/auth/keys":             models.RoleAdmin,
 	"POST /v1/auth/keys":            models.RoleAdmin,
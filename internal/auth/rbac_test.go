@@ -50,6 +50,32 @@ func TestCheckScopeMalformedDenies(t *testing.T) {
 	require.False(t, CheckScope([]byte("{invalid"), "any-job"))
 }
 
+func TestCheckJobScopeNamespaces(t *testing.T) {
+	scope, _ := json.Marshal(models.KeyScope{Namespaces: []string{"team-a"}})
+	require.True(t, CheckJobScope(scope, ScopedJob{Namespace: "team-a", Alias: "etl-daily"}))
+	require.False(t, CheckJobScope(scope, ScopedJob{Namespace: "team-b", Alias: "etl-daily"}))
+	require.False(t, CheckScope(scope, "etl-daily"), "an alias-only check is in the default namespace")
+
+	both, _ := json.Marshal(models.KeyScope{Jobs: []string{"team-a/etl-daily", "etl-daily"}, Namespaces: []string{"team-a", "default"}})
+	require.True(t, CheckJobScope(both, ScopedJob{Namespace: "team-a", Alias: "etl-daily"}))
+	require.True(t, CheckScope(both, "etl-daily"))
+	require.False(t, CheckJobScope(both, ScopedJob{Namespace: "team-a", Alias: "etl-hourly"}))
+}
+
+func TestCheckJobScopeMatchesNamespaceAndAliasTogether(t *testing.T) {
+	scope, _ := json.Marshal(models.KeyScope{Jobs: []string{"etl-daily", "team-b/load", "default/report"}})
+	require.True(t, CheckJobScope(scope, ScopedJob{Alias: "etl-daily"}), "a bare alias names the default namespace")
+	require.False(t, CheckJobScope(scope, ScopedJob{Namespace: "team-a", Alias: "etl-daily"}))
+	require.True(t, CheckJobScope(scope, ScopedJob{Namespace: "team-b", Alias: "load"}))
+	require.False(t, CheckJobScope(scope, ScopedJob{Namespace: "team-a", Alias: "load"}))
+	require.False(t, CheckScope(scope, "load"))
+	require.True(t, CheckScope(scope, "report"))
+
+	jobs, err := ScopeJobs(scope)
+	require.NoError(t, err)
+	require.Equal(t, []string{"etl-daily", "report", "team-b/load"}, jobs)
+}
+
 func TestRequiredRoleKnownEndpoints(t *testing.T) {
 	role, ok := RequiredRole("GET", "/metrics")
 	require.True(t, ok)
@@ -94,6 +120,8 @@ func TestRequiredRoleBackfilledProtectedEndpoints(t *testing.T) {
 		{"GET", "/v1/jobs/:id/topology", models.RoleViewer},
 		{"GET", "/v1/jobs/:id/topology/history", models.RoleViewer},
 		{"GET", "/v1/lineage/impact", models.RoleViewer},
+		{"GET", "/v1/lineage/upstream", models.RoleViewer},
+		{"GET", "/v1/lineage/column-impact", models.RoleViewer},
 		{"GET", "/v1/stats/summary", models.RoleViewer},
 		{"GET", "/v1/system/features", models.RoleViewer},
 		{"GET", "/v1/system/nodes", models.RoleViewer},
@@ -115,7 +143,15 @@ func TestRequiredRoleBackfilledProtectedEndpoints(t *testing.T) {
 		{"POST", "/v1/agentprofiles", models.RoleOperator},
 		{"PATCH", "/v1/agentprofiles/:id", models.RoleOperator},
 		{"DELETE", "/v1/agentprofiles/:id", models.RoleOperator},
+		{"GET", "/v1/pools", models.RoleViewer},
+		{"GET", "/v1/pools/:id", models.RoleViewer},
+		{"PUT", "/v1/pools/:id", models.RoleOperator},
+		{"DELETE", "/v1/pools/:id", models.RoleOperator},
+		{"PUT", "/v1/system/nodes/:id/cordon", models.RoleOperator},
+		{"PUT", "/v1/system/nodes/:id/drain", models.RoleOperator},
+		{"PUT", "/v1/system/nodes/:id/uncordon", models.RoleOperator},
 		{"POST", "/v1/jobs/:id/runs/:id/replay", models.RoleRunner},
+		{"POST", "/v1/lineage", models.RoleRunner},
 	}
 
 	for _, tt := range tests {
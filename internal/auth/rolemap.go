@@ -2,24 +2,37 @@ package auth
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 )
 
 // RoleMapper resolves IdP groups to Caesium roles. Highest matched role wins.
 // The wildcard entry "*" matches every login; defaultRole is only a fallback
 // when no explicit or wildcard mapping applies.
+//
+// An entry may restrict its role to namespaces with a "@ns1,ns2" suffix. The
+// user is scoped to the namespaces of the matched entries that grant the
+// winning role; if any of them is unrestricted, so is the user.
 type RoleMapper struct {
-	byGroup     map[string]models.Role
-	wildcard    *models.Role
+	byGroup     map[string]roleGrant
+	wildcard    *roleGrant
 	defaultRole *models.Role
 }
 
-// NewRoleMapper parses a semicolon-separated group=role mapping and optional
-// default role. Entries split on the last '=' so LDAP DNs can be used as keys.
+// roleGrant is one parsed mapping entry. Nil namespaces are unrestricted.
+type roleGrant struct {
+	role       models.Role
+	namespaces []string
+}
+
+// NewRoleMapper parses a semicolon-separated group=role[@ns,...] mapping and
+// optional default role. Entries split on the last '=' so LDAP DNs can be used
+// as keys.
 func NewRoleMapper(mapping, defaultRole string) (*RoleMapper, error) {
-	m := &RoleMapper{byGroup: map[string]models.Role{}}
+	m := &RoleMapper{byGroup: map[string]roleGrant{}}
 	for _, entry := range strings.Split(mapping, ";") {
 		entry = strings.TrimSpace(entry)
 		if entry == "" {
@@ -30,16 +43,15 @@ func NewRoleMapper(mapping, defaultRole string) (*RoleMapper, error) {
 			return nil, fmt.Errorf("invalid role mapping entry %q (want group=role)", entry)
 		}
 		group := strings.TrimSpace(entry[:eq])
-		role := models.Role(strings.TrimSpace(entry[eq+1:]))
-		if !models.ValidRole(string(role)) {
-			return nil, fmt.Errorf("invalid role %q in mapping", role)
+		grant, err := parseRoleGrant(entry[eq+1:])
+		if err != nil {
+			return nil, err
 		}
 		if group == "*" {
-			r := role
-			m.wildcard = &r
+			m.wildcard = &grant
 			continue
 		}
-		m.byGroup[group] = role
+		m.byGroup[group] = grant
 	}
 
 	if dr := strings.TrimSpace(defaultRole); dr != "" {
@@ -52,29 +64,72 @@ func NewRoleMapper(mapping, defaultRole string) (*RoleMapper, error) {
 	return m, nil
 }
 
+func parseRoleGrant(value string) (roleGrant, error) {
+	rolePart, nsPart, scoped := strings.Cut(value, "@")
+	grant := roleGrant{role: models.Role(strings.TrimSpace(rolePart))}
+	if !models.ValidRole(string(grant.role)) {
+		return roleGrant{}, fmt.Errorf("invalid role %q in mapping", grant.role)
+	}
+	if !scoped {
+		return grant, nil
+	}
+	for _, ns := range strings.Split(nsPart, ",") {
+		ns = strings.TrimSpace(ns)
+		if !jobdef.ValidNamespace(ns) {
+			return roleGrant{}, fmt.Errorf("invalid namespace %q in mapping for role %q", ns, grant.role)
+		}
+		if !slices.Contains(grant.namespaces, ns) {
+			grant.namespaces = append(grant.namespaces, ns)
+		}
+	}
+	return grant, nil
+}
+
 // Resolve returns the effective role for groups and whether login is allowed.
 func (m *RoleMapper) Resolve(groups []string) (models.Role, bool) {
-	var best models.Role
-	matched := false
+	role, _, ok := m.ResolveScoped(groups)
+	return role, ok
+}
+
+// ResolveScoped is Resolve plus the namespaces the role is restricted to; nil
+// namespaces are unrestricted. The default role is always unrestricted.
+func (m *RoleMapper) ResolveScoped(groups []string) (models.Role, []string, bool) {
+	var matches []roleGrant
 	for _, g := range groups {
-		role, ok := m.byGroup[strings.TrimSpace(g)]
-		if !ok {
-			continue
-		}
-		if !matched || models.RoleLevel(role) > models.RoleLevel(best) {
-			best = role
-			matched = true
+		if grant, ok := m.byGroup[strings.TrimSpace(g)]; ok {
+			matches = append(matches, grant)
 		}
 	}
-	if m.wildcard != nil && (!matched || models.RoleLevel(*m.wildcard) > models.RoleLevel(best)) {
-		best = *m.wildcard
-		matched = true
+	if m.wildcard != nil {
+		matches = append(matches, *m.wildcard)
 	}
-	if matched {
-		return best, true
+	if len(matches) == 0 {
+		if m.defaultRole != nil {
+			return *m.defaultRole, nil, true
+		}
+		return "", nil, false
+	}
+
+	best := matches[0].role
+	for _, grant := range matches[1:] {
+		if models.RoleLevel(grant.role) > models.RoleLevel(best) {
+			best = grant.role
+		}
 	}
-	if m.defaultRole != nil {
-		return *m.defaultRole, true
+	var namespaces []string
+	for _, grant := range matches {
+		if grant.role != best {
+			continue
+		}
+		if grant.namespaces == nil {
+			return best, nil, true
+		}
+		for _, ns := range grant.namespaces {
+			if !slices.Contains(namespaces, ns) {
+				namespaces = append(namespaces, ns)
+			}
+		}
 	}
-	return "", false
+	slices.Sort(namespaces)
+	return best, namespaces, true
 }
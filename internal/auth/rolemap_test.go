@@ -72,3 +72,36 @@ func TestRoleMapperRejectsBadRole(t *testing.T) {
 	_, err := NewRoleMapper("g=superuser", "")
 	require.Error(t, err)
 }
+
+func TestRoleMapperResolveScoped(t *testing.T) {
+	m, err := NewRoleMapper("team-a=operator@team-a;team-b=operator@team-b,shared;admins=admin;*=viewer", "")
+	require.NoError(t, err)
+
+	r, ns, ok := m.ResolveScoped([]string{"team-a"})
+	require.True(t, ok)
+	require.Equal(t, models.RoleOperator, r)
+	require.Equal(t, []string{"team-a"}, ns)
+
+	r, ns, ok = m.ResolveScoped([]string{"team-a", "team-b"})
+	require.True(t, ok)
+	require.Equal(t, models.RoleOperator, r)
+	require.Equal(t, []string{"shared", "team-a", "team-b"}, ns)
+
+	// Only entries granting the winning role contribute namespaces.
+	r, ns, ok = m.ResolveScoped([]string{"team-a", "admins"})
+	require.True(t, ok)
+	require.Equal(t, models.RoleAdmin, r)
+	require.Nil(t, ns)
+
+	r, ns, ok = m.ResolveScoped([]string{"nobody"})
+	require.True(t, ok)
+	require.Equal(t, models.RoleViewer, r)
+	require.Nil(t, ns)
+}
+
+func TestRoleMapperRejectsInvalidNamespace(t *testing.T) {
+	_, err := NewRoleMapper("team-a=operator@Team_A", "")
+	require.ErrorContains(t, err, `invalid namespace "Team_A"`)
+	_, err = NewRoleMapper("team-a=operator@", "")
+	require.ErrorContains(t, err, `invalid namespace ""`)
+}
@@ -3,15 +3,23 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
 )
 
+// ScopedJob identifies the job a scoped request acts on.
+type ScopedJob struct {
+	Namespace string
+	Alias     string
+}
+
 // DecodeScope normalizes the persisted scope payload into a structured model.
-// Nil, empty, or empty-job scopes are treated as unrestricted.
+// Nil, empty, or scopes without jobs or namespaces are treated as unrestricted.
 func DecodeScope(scopeJSON []byte) (*models.KeyScope, error) {
 	if len(scopeJSON) == 0 {
 		return nil, nil
@@ -22,27 +30,50 @@ func DecodeScope(scopeJSON []byte) (*models.KeyScope, error) {
 		return nil, err
 	}
 
-	seen := make(map[string]struct{}, len(scope.Jobs))
-	jobs := make([]string, 0, len(scope.Jobs))
-	for _, alias := range scope.Jobs {
-		alias = strings.TrimSpace(alias)
+	scope.Jobs = normalizeScopeNames(qualifyScopeJobs(scope.Jobs))
+	scope.Namespaces = normalizeScopeNames(scope.Namespaces)
+	if len(scope.Jobs) == 0 && len(scope.Namespaces) == 0 {
+		return nil, nil
+	}
+	return &scope, nil
+}
+
+// qualifyScopeJobs rewrites job scope entries in QualifiedAlias form. An
+// entry is "namespace/alias", or a bare alias naming a job in the default
+// namespace.
+func qualifyScopeJobs(refs []string) []string {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		namespace, alias := jobdef.SplitQualifiedAlias(ref)
 		if alias == "" {
 			continue
 		}
-		if _, ok := seen[alias]; ok {
+		out = append(out, jobdef.QualifiedAlias(namespace, alias))
+	}
+	return out
+}
+
+// normalizeScopeNames trims, de-duplicates and sorts names, returning nil
+// when none remain.
+func normalizeScopeNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
 			continue
 		}
-		seen[alias] = struct{}{}
-		jobs = append(jobs, alias)
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
 	}
-
-	if len(jobs) == 0 {
-		return nil, nil
+	if len(out) == 0 {
+		return nil
 	}
-
-	sort.Strings(jobs)
-	scope.Jobs = jobs
-	return &scope, nil
+	sort.Strings(out)
+	return out
 }
 
 // AgentClaimView is the normalized, package-local view of an agent-session
@@ -87,7 +118,8 @@ func DecodeAgentClaim(scopeJSON []byte) (*AgentClaimView, error) {
 	return &AgentClaimView{IncidentID: scope.Agent.IncidentID, Jobs: jobs}, nil
 }
 
-// ScopeJobs returns the normalized scoped job aliases or nil when unrestricted.
+// ScopeJobs returns the scoped jobs, qualified as by jobdef.QualifiedAlias,
+// or nil when unrestricted.
 func ScopeJobs(scopeJSON []byte) ([]string, error) {
 	scope, err := DecodeScope(scopeJSON)
 	if err != nil {
@@ -99,52 +131,92 @@ func ScopeJobs(scopeJSON []byte) ([]string, error) {
 	return append([]string(nil), scope.Jobs...), nil
 }
 
-// IsScoped reports whether the scope payload restricts access to specific jobs.
+// ScopeNamespaces returns the normalized scoped namespaces or nil when the
+// scope does not restrict namespaces.
+func ScopeNamespaces(scopeJSON []byte) ([]string, error) {
+	scope, err := DecodeScope(scopeJSON)
+	if err != nil {
+		return nil, err
+	}
+	if scope == nil {
+		return nil, nil
+	}
+	return append([]string(nil), scope.Namespaces...), nil
+}
+
+// IsScoped reports whether the scope payload restricts access to specific
+// jobs or namespaces.
 func IsScoped(scopeJSON []byte) (bool, error) {
-	jobs, err := ScopeJobs(scopeJSON)
+	scope, err := DecodeScope(scopeJSON)
 	if err != nil {
 		return false, err
 	}
-	return len(jobs) > 0, nil
+	return scope != nil, nil
 }
 
-func containsScopedJob(allowed []string, jobAlias string) bool {
+// containsScopedJob reports whether job is among the qualified job refs in
+// allowed, matching namespace and alias together; an empty list allows any.
+func containsScopedJob(allowed []string, job ScopedJob) bool {
 	if len(allowed) == 0 {
 		return true
 	}
+	return slices.Contains(allowed, jobdef.QualifiedAlias(job.Namespace, strings.TrimSpace(job.Alias)))
+}
 
-	jobAlias = strings.TrimSpace(jobAlias)
-	for _, alias := range allowed {
-		if alias == jobAlias {
-			return true
-		}
-	}
-	return false
+func containsScopedName(allowed []string, name string) bool {
+	return len(allowed) == 0 || slices.Contains(allowed, strings.TrimSpace(name))
 }
 
 // JobAliasByID resolves the job alias for a job identifier.
 func (s *Service) JobAliasByID(ctx context.Context, id uuid.UUID) (string, error) {
-	var job models.Job
-	if err := s.db.WithContext(ctx).Select("alias").First(&job, "id = ?", id).Error; err != nil {
-		return "", err
-	}
-	return job.Alias, nil
+	job, err := s.ScopedJobByID(ctx, id)
+	return job.Alias, err
 }
 
 // JobAliasByRunID resolves the job alias for a job run identifier.
 func (s *Service) JobAliasByRunID(ctx context.Context, id uuid.UUID) (string, error) {
+	job, err := s.ScopedJobByRunID(ctx, id)
+	return job.Alias, err
+}
+
+// JobAliasByBackfillID resolves the job alias for a backfill identifier.
+func (s *Service) JobAliasByBackfillID(ctx context.Context, id uuid.UUID) (string, error) {
+	job, err := s.ScopedJobByBackfillID(ctx, id)
+	return job.Alias, err
+}
+
+// ScopedJobByID resolves the namespace and alias of a job identifier.
+func (s *Service) ScopedJobByID(ctx context.Context, id uuid.UUID) (ScopedJob, error) {
+	var job models.Job
+	if err := s.db.WithContext(ctx).Select("namespace", "alias").First(&job, "id = ?", id).Error; err != nil {
+		return ScopedJob{}, err
+	}
+	return ScopedJob{Namespace: job.Namespace, Alias: job.Alias}, nil
+}
+
+// ScopedJobByRunID resolves the namespace and alias of a job run's job.
+func (s *Service) ScopedJobByRunID(ctx context.Context, id uuid.UUID) (ScopedJob, error) {
 	var run models.JobRun
 	if err := s.db.WithContext(ctx).Select("job_id").First(&run, "id = ?", id).Error; err != nil {
-		return "", err
+		return ScopedJob{}, err
 	}
-	return s.JobAliasByID(ctx, run.JobID)
+	return s.ScopedJobByID(ctx, run.JobID)
 }
 
-// JobAliasByBackfillID resolves the job alias for a backfill identifier.
-func (s *Service) JobAliasByBackfillID(ctx context.Context, id uuid.UUID) (string, error) {
+// ScopedJobByTaskRunID resolves the namespace and alias of a task run's job.
+func (s *Service) ScopedJobByTaskRunID(ctx context.Context, id uuid.UUID) (ScopedJob, error) {
+	var taskRun models.TaskRun
+	if err := s.db.WithContext(ctx).Select("job_run_id").First(&taskRun, "id = ?", id).Error; err != nil {
+		return ScopedJob{}, err
+	}
+	return s.ScopedJobByRunID(ctx, taskRun.JobRunID)
+}
+
+// ScopedJobByBackfillID resolves the namespace and alias of a backfill's job.
+func (s *Service) ScopedJobByBackfillID(ctx context.Context, id uuid.UUID) (ScopedJob, error) {
 	var backfill models.Backfill
 	if err := s.db.WithContext(ctx).Select("job_id").First(&backfill, "id = ?", id).Error; err != nil {
-		return "", err
+		return ScopedJob{}, err
 	}
-	return s.JobAliasByID(ctx, backfill.JobID)
+	return s.ScopedJobByID(ctx, backfill.JobID)
 }
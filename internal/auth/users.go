@@ -10,6 +10,7 @@ import (
 
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -25,18 +26,25 @@ func NewUserStore(db *gorm.DB) *UserStore {
 }
 
 // Upsert provisions a user on first login and refreshes profile, role, and
-// last-login fields on subsequent logins, keyed on (issuer, subject).
+// last-login fields on subsequent logins, keyed on (issuer, subject). The user
+// is not restricted to any namespace.
 func (us *UserStore) Upsert(ctx context.Context, ext *ExternalIdentity, role models.Role) (*models.User, error) {
-	user, _, err := us.upsert(ctx, ext, role)
+	user, _, err := us.upsert(ctx, ext, role, nil)
 	return user, err
 }
 
-func (us *UserStore) upsert(ctx context.Context, ext *ExternalIdentity, role models.Role) (*models.User, bool, error) {
+func (us *UserStore) upsert(ctx context.Context, ext *ExternalIdentity, role models.Role, namespaces []string) (*models.User, bool, error) {
 	now := us.now().UTC()
 	groupsJSON, err := json.Marshal(ext.Groups)
 	if err != nil {
 		return nil, false, fmt.Errorf("marshal groups: %w", err)
 	}
+	var namespacesJSON datatypes.JSON
+	if len(namespaces) > 0 {
+		if namespacesJSON, err = json.Marshal(namespaces); err != nil {
+			return nil, false, fmt.Errorf("marshal namespaces: %w", err)
+		}
+	}
 
 	var user models.User
 	err = us.db.WithContext(ctx).Where("issuer = ? AND subject = ?", ext.Issuer, ext.Subject).First(&user).Error
@@ -49,6 +57,7 @@ func (us *UserStore) upsert(ctx context.Context, ext *ExternalIdentity, role mod
 			Email:       ext.Email,
 			DisplayName: ext.DisplayName,
 			Groups:      groupsJSON,
+			Namespaces:  namespacesJSON,
 			Role:        role,
 			CreatedAt:   now,
 			LastLoginAt: &now,
@@ -56,7 +65,7 @@ func (us *UserStore) upsert(ctx context.Context, ext *ExternalIdentity, role mod
 		if err := us.db.WithContext(ctx).Create(&user).Error; err != nil {
 			if isUniqueConstraintError(err) {
 				if existing, lookupErr := us.lookupByIdentity(ctx, ext); lookupErr == nil {
-					updated, err := us.updateExisting(ctx, &existing, ext, role, groupsJSON, now)
+					updated, err := us.updateExisting(ctx, &existing, ext, role, groupsJSON, namespacesJSON, now)
 					return updated, false, err
 				}
 			}
@@ -65,7 +74,7 @@ func (us *UserStore) upsert(ctx context.Context, ext *ExternalIdentity, role mod
 	case err != nil:
 		return nil, false, fmt.Errorf("lookup user: %w", err)
 	default:
-		updated, err := us.updateExisting(ctx, &user, ext, role, groupsJSON, now)
+		updated, err := us.updateExisting(ctx, &user, ext, role, groupsJSON, namespacesJSON, now)
 		return updated, false, err
 	}
 
@@ -78,7 +87,7 @@ func (us *UserStore) lookupByIdentity(ctx context.Context, ext *ExternalIdentity
 	return user, err
 }
 
-func (us *UserStore) updateExisting(ctx context.Context, user *models.User, ext *ExternalIdentity, role models.Role, groupsJSON []byte, now time.Time) (*models.User, error) {
+func (us *UserStore) updateExisting(ctx context.Context, user *models.User, ext *ExternalIdentity, role models.Role, groupsJSON []byte, namespacesJSON datatypes.JSON, now time.Time) (*models.User, error) {
 	if user.IsDisabled() {
 		return user, nil
 	}
@@ -87,6 +96,7 @@ func (us *UserStore) updateExisting(ctx context.Context, user *models.User, ext
 		"email":         ext.Email,
 		"display_name":  ext.DisplayName,
 		"groups":        groupsJSON,
+		"namespaces":    namespacesJSON,
 		"role":          role,
 		"last_login_at": now,
 	}
@@ -96,6 +106,7 @@ func (us *UserStore) updateExisting(ctx context.Context, user *models.User, ext
 	user.Email = ext.Email
 	user.DisplayName = ext.DisplayName
 	user.Groups = groupsJSON
+	user.Namespaces = namespacesJSON
 	user.Role = role
 	user.LastLoginAt = &now
 	return user, nil
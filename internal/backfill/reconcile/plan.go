@@ -0,0 +1,191 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	backfillstore "github.com/caesium-cloud/caesium/internal/backfill"
+	jobexec "github.com/caesium-cloud/caesium/internal/job"
+	"github.com/caesium-cloud/caesium/internal/models"
+	croncfg "github.com/caesium-cloud/caesium/internal/trigger/cron"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/robfig/cron"
+)
+
+// ErrInvalidPlan reports a backfill whose dates or params cannot be planned.
+var ErrInvalidPlan = errors.New("invalid backfill plan")
+
+// PlannedRun is one run of a backfill. It covers one logical date, or a batch
+// of consecutive ones, and is keyed by the last of them.
+type PlannedRun struct {
+	LogicalDate time.Time   `json:"logical_date"`
+	Dates       []time.Time `json:"dates"`
+}
+
+// Plan is a backfill's runs in launch order. It depends only on the backfill
+// row and its trigger, so every leader derives the same plan.
+type Plan struct {
+	Runs []PlannedRun
+
+	schedule   cron.Schedule
+	loc        *time.Location
+	jobAlias   string
+	templated  bool
+	defaults   map[string]string
+	params     map[string]string
+	dateParams map[string]map[string]string
+}
+
+// NewPlan enumerates b's logical dates from its explicit Dates or its cron
+// window, groups them into batches of BatchSize, and orders the batches.
+func NewPlan(b *models.Backfill, jobAlias, triggerConfiguration string) (*Plan, error) {
+	schedule, loc, err := croncfg.ParseSchedule(triggerConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := croncfg.ParseDefaultParams(triggerConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	templated, err := croncfg.ParseRunTemplates(triggerConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{schedule: schedule, loc: loc, jobAlias: jobAlias, templated: templated, defaults: defaults}
+	if err := decodeJSON(b.Params, &p.params); err != nil {
+		return nil, fmt.Errorf("%w: params: %v", ErrInvalidPlan, err)
+	}
+	if err := decodeJSON(b.DateParams, &p.dateParams); err != nil {
+		return nil, fmt.Errorf("%w: date_params: %v", ErrInvalidPlan, err)
+	}
+
+	dates, err := planDates(b, schedule, loc)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		known[d.UTC().Format(time.RFC3339)] = true
+	}
+	for key := range p.dateParams {
+		if !known[key] {
+			return nil, fmt.Errorf("%w: date_params key %q is not a logical date of this backfill", ErrInvalidPlan, key)
+		}
+	}
+
+	size := b.BatchSize
+	if size <= 0 {
+		size = 1
+	}
+	for i := 0; i < len(dates); i += size {
+		batch := dates[i:min(i+size, len(dates))]
+		p.Runs = append(p.Runs, PlannedRun{LogicalDate: batch[len(batch)-1], Dates: batch})
+	}
+	if b.Order == string(models.BackfillOrderNewestFirst) {
+		slices.Reverse(p.Runs)
+	}
+	return p, nil
+}
+
+// planDates returns b's logical dates in ascending order.
+func planDates(b *models.Backfill, schedule cron.Schedule, loc *time.Location) ([]time.Time, error) {
+	if len(b.Dates) == 0 {
+		return jobexec.EnumerateLogicalDates(schedule, b.Start, b.End, loc), nil
+	}
+	var raw []string
+	if err := json.Unmarshal(b.Dates, &raw); err != nil {
+		return nil, fmt.Errorf("%w: dates: %v", ErrInvalidPlan, err)
+	}
+	dates := make([]time.Time, 0, len(raw))
+	for _, value := range raw {
+		d, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dates: %v", ErrInvalidPlan, err)
+		}
+		dates = append(dates, d.In(loc))
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(dates, time.Time.Equal), nil
+}
+
+// Next returns the index of the first run after cursor, the logical date of
+// the last run launched or skipped.
+func (p *Plan) Next(cursor *time.Time) int {
+	if cursor == nil {
+		return 0
+	}
+	return slices.IndexFunc(p.Runs, func(run PlannedRun) bool { return run.LogicalDate.Equal(*cursor) }) + 1
+}
+
+// Kept returns the runs the backfill's reprocess policy would launch now.
+func (p *Plan) Kept(store *backfillstore.Store, b *models.Backfill) ([]PlannedRun, error) {
+	dates := make([]time.Time, len(p.Runs))
+	for i, run := range p.Runs {
+		dates[i] = run.LogicalDate
+	}
+	filtered, err := jobexec.FilterDates(store, b.JobID, dates, b.Reprocess)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[int64]bool, len(filtered))
+	for _, d := range filtered {
+		keep[d.Unix()] = true
+	}
+	kept := make([]PlannedRun, 0, len(filtered))
+	for _, run := range p.Runs {
+		if keep[run.LogicalDate.Unix()] {
+			kept = append(kept, run)
+		}
+	}
+	return kept, nil
+}
+
+// Params returns the params for run: the trigger's defaultParams rendered
+// against the run's data interval, overlaid with the interval params, the
+// backfill's params, and the params of each of the run's dates in order.
+// Defaults and params are only rendered for jobs with run templates enabled.
+func (p *Plan) Params(run PlannedRun) (map[string]string, error) {
+	scheduled := jobexec.LogicalDateParams(p.schedule, run.LogicalDate, p.loc)
+	if len(run.Dates) > 1 {
+		start := jobexec.PreviousFireTime(p.schedule, run.Dates[0], p.loc)
+		if start.IsZero() {
+			start = run.Dates[0]
+		}
+		scheduled[jobdefschema.ParamDataIntervalStart] = start.UTC().Format(time.RFC3339)
+	}
+
+	defaults, overrides := p.defaults, p.params
+	if p.templated {
+		data := jobdefschema.NewRunTemplateData("", p.jobAlias, scheduled, run.LogicalDate)
+		var err error
+		if defaults, err = jobdefschema.RenderRunValues("defaultParams", p.defaults, data); err != nil {
+			return nil, err
+		}
+		if overrides, err = jobdefschema.RenderRunValues("params", p.params, data); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make(map[string]string, len(defaults)+len(scheduled)+len(overrides))
+	for _, layer := range []map[string]string{defaults, scheduled, overrides} {
+		for k, v := range layer {
+			params[k] = v
+		}
+	}
+	for _, d := range run.Dates {
+		for k, v := range p.dateParams[d.UTC().Format(time.RFC3339)] {
+			params[k] = v
+		}
+	}
+	return params, nil
+}
+
+func decodeJSON(raw []byte, out any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
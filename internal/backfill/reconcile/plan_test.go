@@ -0,0 +1,68 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+const dailyTrigger = `{"cron":"0 0 * * *","timezone":"UTC","runTemplates":true,"defaultParams":{"region":"eu","day":"{{ ds .LogicalDate }}"}}`
+
+func TestNewPlanBatchesNewestFirst(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	plan, err := NewPlan(&models.Backfill{
+		Start:     start,
+		End:       start.AddDate(0, 0, 5),
+		BatchSize: 2,
+		Order:     string(models.BackfillOrderNewestFirst),
+	}, "nightly", dailyTrigger)
+	require.NoError(t, err)
+
+	// Batches are cut oldest first so their boundaries do not depend on order.
+	require.Len(t, plan.Runs, 3)
+	require.Equal(t, start.AddDate(0, 0, 4), plan.Runs[0].LogicalDate)
+	require.Len(t, plan.Runs[0].Dates, 1)
+	require.Equal(t, start.AddDate(0, 0, 3), plan.Runs[1].LogicalDate)
+	require.Equal(t, []time.Time{start, start.AddDate(0, 0, 1)}, plan.Runs[2].Dates)
+
+	require.Equal(t, 0, plan.Next(nil))
+	cursor := plan.Runs[1].LogicalDate
+	require.Equal(t, 2, plan.Next(&cursor))
+
+	params, err := plan.Params(plan.Runs[2])
+	require.NoError(t, err)
+	require.Equal(t, "2026-10-02T00:00:00Z", params["logical_date"])
+	require.Equal(t, "2026-09-30T00:00:00Z", params["data_interval_start"])
+	require.Equal(t, "2026-10-02T00:00:00Z", params["data_interval_end"])
+	require.Equal(t, "2026-10-02", params["day"])
+}
+
+func TestNewPlanExplicitDatesAndOverrides(t *testing.T) {
+	plan, err := NewPlan(&models.Backfill{
+		Dates:      datatypes.JSON(`["2026-10-09T00:00:00Z","2026-10-02T00:00:00Z","2026-10-09T00:00:00Z"]`),
+		Params:     datatypes.JSON(`{"region":"us","target":"warehouse_{{ ds_nodash .LogicalDate }}"}`),
+		DateParams: datatypes.JSON(`{"2026-10-09T00:00:00Z":{"region":"apac"}}`),
+	}, "nightly", dailyTrigger)
+	require.NoError(t, err)
+	require.Len(t, plan.Runs, 2)
+	require.Equal(t, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), plan.Runs[0].LogicalDate)
+
+	params, err := plan.Params(plan.Runs[0])
+	require.NoError(t, err)
+	require.Equal(t, "us", params["region"])
+	require.Equal(t, "warehouse_20261002", params["target"])
+	require.Equal(t, "2026-10-01T00:00:00Z", params["data_interval_start"])
+
+	params, err = plan.Params(plan.Runs[1])
+	require.NoError(t, err)
+	require.Equal(t, "apac", params["region"])
+
+	_, err = NewPlan(&models.Backfill{
+		Dates:      datatypes.JSON(`["2026-10-02T00:00:00Z"]`),
+		DateParams: datatypes.JSON(`{"2026-10-03T00:00:00Z":{"region":"apac"}}`),
+	}, "nightly", dailyTrigger)
+	require.ErrorIs(t, err, ErrInvalidPlan)
+}
@@ -0,0 +1,397 @@
+// Package reconcile drives backfills from their database rows. A leader-gated
+// Reconciler launches each running backfill's planned runs in order, up to
+// its max_concurrent, and records a date cursor on the backfill so a new
+// leader resumes exactly where the previous one stopped. Before starting a
+// run the reconciler writes a launch intent to the row, so a leader that dies
+// between starting a run and advancing the cursor leaves its successor enough
+// to settle the cursor rather than lose the date. Every pass adopts the
+// backfill's running runs that no goroutine in this process executes, so runs
+// whose executions died with a previous leader are run again from their
+// stored state instead of holding slots forever. Pause, resume, and cancel
+// are requests written to the row by any node; the reconciler observes them
+// on its next pass.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	backfillstore "github.com/caesium-cloud/caesium/internal/backfill"
+	jobexec "github.com/caesium-cloud/caesium/internal/job"
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LeaderCheck func(context.Context) (bool, error)
+type LaunchFunc func(context.Context, *models.Job, *runstorage.JobRun)
+
+type Config struct {
+	DB          *gorm.DB
+	Store       *backfillstore.Store
+	RunStore    *runstorage.Store
+	Interval    time.Duration
+	LeaderCheck LeaderCheck
+	// LaunchRun executes a started backfill run. It defaults to running the
+	// job on this node in the background.
+	LaunchRun LaunchFunc
+}
+
+type Reconciler struct {
+	db          *gorm.DB
+	store       *backfillstore.Store
+	runStore    *runstorage.Store
+	interval    time.Duration
+	leaderCheck LeaderCheck
+	launchRun   LaunchFunc
+	// plans caches each active backfill's plan; only Run's goroutine uses it.
+	plans map[uuid.UUID]cachedPlan
+
+	// launched maps each run this process is executing to its backfill, so
+	// only runs no local goroutine still owns are adopted.
+	mu       sync.Mutex
+	launched map[uuid.UUID]uuid.UUID
+}
+
+type cachedPlan struct {
+	configuration string
+	plan          *Plan
+}
+
+func NewReconciler(cfg Config) *Reconciler {
+	if cfg.DB == nil {
+		panic("backfill reconciler requires database connection")
+	}
+	store := cfg.Store
+	if store == nil {
+		store = backfillstore.NewStore(cfg.DB)
+	}
+	runStore := cfg.RunStore
+	if runStore == nil {
+		runStore = runstorage.NewStore(cfg.DB)
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Reconciler{
+		db:          cfg.DB,
+		store:       store,
+		runStore:    runStore,
+		interval:    interval,
+		leaderCheck: cfg.LeaderCheck,
+		launchRun:   cfg.LaunchRun,
+		plans:       make(map[uuid.UUID]cachedPlan),
+		launched:    make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+func (r *Reconciler) Run(ctx context.Context) {
+	if err := r.ReconcileOnce(ctx); err != nil && ctx.Err() == nil {
+		log.Error("backfill reconciler pass failed", "error", err)
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.ReconcileOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Error("backfill reconciler pass failed", "error", err)
+			}
+		}
+	}
+}
+
+// ReconcileOnce advances every running or paused backfill by one step. A
+// failure on one backfill is logged and does not hold up the others.
+func (r *Reconciler) ReconcileOnce(ctx context.Context) error {
+	if r.leaderCheck != nil {
+		leader, err := r.leaderCheck(ctx)
+		if err != nil {
+			return err
+		}
+		if !leader {
+			metrics.BackfillsActive.Reset()
+			return nil
+		}
+	}
+
+	backfills, err := r.store.ListActive()
+	if err != nil {
+		return err
+	}
+	active := make(map[string]float64)
+	seen := make(map[uuid.UUID]bool, len(backfills))
+	for _, b := range backfills {
+		seen[b.ID] = true
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		alias, err := r.reconcile(ctx, b)
+		if err != nil {
+			log.Error("backfill reconcile failed", "backfill_id", b.ID, "job_id", b.JobID, "error", err)
+		}
+		if alias != "" {
+			active[alias]++
+		}
+	}
+	for id := range r.plans {
+		if !seen[id] {
+			delete(r.plans, id)
+		}
+	}
+	metrics.BackfillsActive.Reset()
+	for alias, count := range active {
+		metrics.BackfillsActive.WithLabelValues(alias).Set(count)
+	}
+	return nil
+}
+
+// reconcile advances one backfill and returns its job alias while the
+// backfill remains active.
+func (r *Reconciler) reconcile(ctx context.Context, b *models.Backfill) (string, error) {
+	progress, err := r.store.RefreshProgress(b)
+	if err != nil {
+		return "", err
+	}
+
+	var job models.Job
+	if err := r.db.WithContext(ctx).First(&job, "id = ?", b.JobID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		if b.CancelRequestedAt != nil {
+			return "", r.cancelOnceDrained(b, progress)
+		}
+		log.Warn("backfill job no longer exists; failing backfill", "backfill_id", b.ID, "job_id", b.JobID)
+		return "", r.store.Complete(b.ID, true)
+	}
+	// Orphaned runs are adopted even while the backfill is paused or
+	// cancelling, since both wait for in-flight runs to drain.
+	if err := r.adoptOrphans(ctx, b, &job); err != nil {
+		return job.Alias, err
+	}
+	if b.CancelRequestedAt != nil {
+		return "", r.cancelOnceDrained(b, progress)
+	}
+
+	plan, err := r.plan(ctx, b, &job)
+	if err != nil {
+		log.Warn("backfill cannot be planned; failing backfill", "backfill_id", b.ID, "job_id", b.JobID, "error", err)
+		return "", r.store.Complete(b.ID, true)
+	}
+	if b.Status == string(models.BackfillStatusPaused) {
+		return job.Alias, nil
+	}
+	if b.LaunchDate != nil {
+		if err := r.resumeLaunch(b); err != nil {
+			return job.Alias, err
+		}
+	}
+
+	if b.Cursor == nil && b.TotalRuns == 0 {
+		// Runs are filtered again as they are launched, since other runs may
+		// land in the meantime.
+		kept, err := plan.Kept(r.store, b)
+		if err != nil {
+			return job.Alias, err
+		}
+		if err := r.store.SetTotalRuns(b.ID, len(kept)); err != nil {
+			return job.Alias, err
+		}
+		b.TotalRuns = len(kept)
+	}
+
+	maxConcurrent := b.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	// A backfill that reached its failure budget launches nothing more and
+	// fails once its in-flight runs drain.
+	stopped := b.MaxFailures > 0 && progress.Failed >= b.MaxFailures
+	inFlight := progress.Active
+	next := plan.Next(b.Cursor)
+	for !stopped && inFlight < maxConcurrent && next < len(plan.Runs) {
+		run := plan.Runs[next]
+		launched, err := r.startRun(ctx, b, &job, plan, run)
+		if errors.Is(err, runstorage.ErrNamespaceQuotaExceeded) {
+			// The job's namespace is at its quota; the launch intent stays and
+			// the run is started on a later pass once the namespace has room.
+			log.Debug("backfill run waits for namespace quota", "backfill_id", b.ID, "logical_date", run.LogicalDate)
+			break
+		}
+		if err != nil {
+			return job.Alias, err
+		}
+		if err := r.store.SetCursor(b.ID, run.LogicalDate); err != nil {
+			return job.Alias, err
+		}
+		cursor := run.LogicalDate
+		b.Cursor = &cursor
+		if launched {
+			inFlight++
+		}
+		next++
+	}
+
+	if (stopped || next >= len(plan.Runs)) && inFlight == 0 {
+		return "", r.store.Complete(b.ID, progress.Failed > 0)
+	}
+	return job.Alias, nil
+}
+
+// cancelOnceDrained marks a cancel-requested backfill cancelled once its
+// in-flight runs have drained; until then they finish normally.
+func (r *Reconciler) cancelOnceDrained(b *models.Backfill, progress backfillstore.Progress) error {
+	if progress.Active == 0 {
+		return r.store.MarkCancelled(b.ID)
+	}
+	return nil
+}
+
+// adoptOrphans executes again, from stored state, every running run of b that
+// no goroutine in this process owns. Such runs were launched by a leader that
+// stopped, and left alone they would stay running and hold b's slots forever.
+func (r *Reconciler) adoptOrphans(ctx context.Context, b *models.Backfill, job *models.Job) error {
+	ids, err := r.store.RunningRunIDs(b.ID)
+	if err != nil {
+		return err
+	}
+	running := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		running[id] = true
+	}
+
+	r.mu.Lock()
+	var orphans []uuid.UUID
+	for runID, backfillID := range r.launched {
+		if backfillID == b.ID && !running[runID] {
+			delete(r.launched, runID)
+		}
+	}
+	for _, id := range ids {
+		if _, ok := r.launched[id]; !ok {
+			orphans = append(orphans, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, id := range orphans {
+		run, err := r.runStore.Get(id)
+		if err != nil {
+			return fmt.Errorf("load run %s: %w", id, err)
+		}
+		log.Info("adopting orphaned backfill run", "backfill_id", b.ID, "run_id", run.ID)
+		r.launch(ctx, b.ID, job, run)
+	}
+	return nil
+}
+
+// plan returns the backfill's plan, reusing the one built on an earlier pass
+// while the job's trigger configuration is unchanged.
+func (r *Reconciler) plan(ctx context.Context, b *models.Backfill, job *models.Job) (*Plan, error) {
+	var trigger models.Trigger
+	if err := r.db.WithContext(ctx).First(&trigger, "id = ?", job.TriggerID).Error; err != nil {
+		return nil, err
+	}
+	if trigger.Type != models.TriggerTypeCron {
+		return nil, fmt.Errorf("trigger %s is %s, not cron", trigger.ID, trigger.Type)
+	}
+	if cached, ok := r.plans[b.ID]; ok && cached.configuration == trigger.Configuration {
+		return cached.plan, nil
+	}
+	plan, err := NewPlan(b, job.Alias, trigger.Configuration)
+	if err != nil {
+		return nil, err
+	}
+	r.plans[b.ID] = cachedPlan{configuration: trigger.Configuration, plan: plan}
+	return plan, nil
+}
+
+// startRun starts one planned run unless this backfill already started it or
+// the reprocess policy skips it, and reports whether it did.
+func (r *Reconciler) startRun(ctx context.Context, b *models.Backfill, job *models.Job, plan *Plan, planned PlannedRun) (bool, error) {
+	logicalDate := planned.LogicalDate.UTC().Format(time.RFC3339)
+	started, err := r.store.HasRun(b.ID, logicalDate)
+	if err != nil || started {
+		return false, err
+	}
+	keep, err := jobexec.FilterDates(r.store, b.JobID, []time.Time{planned.LogicalDate}, b.Reprocess)
+	if err != nil || len(keep) == 0 {
+		return false, err
+	}
+
+	params, err := plan.Params(planned)
+	if err != nil {
+		return false, fmt.Errorf("render params for %s: %w", logicalDate, err)
+	}
+	if err := r.store.SetLaunchIntent(b.ID, planned.LogicalDate); err != nil {
+		return false, fmt.Errorf("record launch intent for %s: %w", logicalDate, err)
+	}
+	run, err := r.runStore.StartForBackfill(job.ID, b.ID, params)
+	if err != nil {
+		return false, fmt.Errorf("start run for %s: %w", logicalDate, err)
+	}
+	metrics.BackfillRunsTotal.WithLabelValues(job.Alias, "started").Inc()
+	r.launch(ctx, b.ID, job, run)
+	return true, nil
+}
+
+// resumeLaunch settles the launch recorded by b's intent. A run that was
+// started for the intent belonged to a leader that stopped before advancing
+// the cursor; adoptOrphans has already executed it again, so only the cursor
+// is advanced past it. An intent whose run never started is left to the
+// launch loop.
+func (r *Reconciler) resumeLaunch(b *models.Backfill) error {
+	logicalDate := b.LaunchDate.UTC()
+	launched, err := r.store.LaunchedRun(b.ID, logicalDate.Format(time.RFC3339))
+	if err != nil || launched == nil {
+		return err
+	}
+	if err := r.store.SetCursor(b.ID, logicalDate); err != nil {
+		return err
+	}
+	b.Cursor = &logicalDate
+	b.LaunchDate = nil
+	return nil
+}
+
+func (r *Reconciler) launch(ctx context.Context, backfillID uuid.UUID, job *models.Job, run *runstorage.JobRun) {
+	r.mu.Lock()
+	r.launched[run.ID] = backfillID
+	r.mu.Unlock()
+	if r.launchRun != nil {
+		// The run stays owned until adoptOrphans sees it finish.
+		r.launchRun(ctx, job, run)
+		return
+	}
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.launched, run.ID)
+			r.mu.Unlock()
+		}()
+		runCtx := runstorage.WithContext(context.WithoutCancel(ctx), run.ID)
+		err := jobexec.New(
+			job,
+			jobexec.WithTriggerID(nil),
+			jobexec.WithRunStoreFactory(func() *runstorage.Store { return r.runStore }),
+			jobexec.WithParams(run.Params),
+		).Run(runCtx)
+		if err != nil {
+			log.Error("backfill run failed", "job_id", job.ID, "run_id", run.ID, "error", err)
+			metrics.BackfillRunsTotal.WithLabelValues(job.Alias, "failed").Inc()
+			return
+		}
+		metrics.BackfillRunsTotal.WithLabelValues(job.Alias, "succeeded").Inc()
+	}()
+}
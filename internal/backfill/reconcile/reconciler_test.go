@@ -0,0 +1,310 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	backfillstore "github.com/caesium-cloud/caesium/internal/backfill"
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	runstorage "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// launchRecorder stands in for job execution: it remembers each launched run
+// so the test decides when and how it finishes.
+type launchRecorder struct {
+	runs []*runstorage.JobRun
+}
+
+func (l *launchRecorder) launch(_ context.Context, _ *models.Job, run *runstorage.JobRun) {
+	l.runs = append(l.runs, run)
+}
+
+func TestReconcileLaunchesDatesInOrderUpToMaxConcurrent(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 4, 2)
+	ctx := context.Background()
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2)
+	require.Equal(t, "2026-10-01T00:00:00Z", launched.runs[0].Params["logical_date"])
+	require.Equal(t, "2026-10-02T00:00:00Z", launched.runs[1].Params["logical_date"])
+
+	stored := getBackfill(t, db, backfill.ID)
+	require.Equal(t, 4, stored.TotalRuns)
+	require.NotNil(t, stored.Cursor)
+	require.True(t, stored.Cursor.Equal(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)))
+
+	// Nothing finished, so the next pass launches nothing.
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2)
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[0].ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 3)
+	require.Equal(t, "2026-10-03T00:00:00Z", launched.runs[2].Params["logical_date"])
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[1].ID, errors.New("boom")))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 4)
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[2].ID, nil))
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[3].ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+
+	stored = getBackfill(t, db, backfill.ID)
+	require.Equal(t, string(models.BackfillStatusFailed), stored.Status)
+	require.Equal(t, 3, stored.CompletedRuns)
+	require.Equal(t, 1, stored.FailedRuns)
+	require.NotNil(t, stored.CompletedAt)
+}
+
+func TestReconcileAdoptsRunsStartedByPreviousLeader(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 3, 1)
+	ctx := context.Background()
+
+	// A previous leader started the first date but failed before it recorded
+	// the cursor.
+	job := getJob(t, db, backfill.JobID)
+	adopted, err := reconciler.runStore.StartForBackfill(job.ID, backfill.ID, map[string]string{"logical_date": "2026-10-01T00:00:00Z"})
+	require.NoError(t, err)
+	require.NoError(t, reconciler.store.SetTotalRuns(backfill.ID, 3))
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1, "the orphaned run is executed again and holds the only slot")
+	require.Equal(t, adopted.ID, launched.runs[0].ID)
+
+	// Once it finishes, the date is skipped rather than launched again.
+	require.NoError(t, reconciler.runStore.Complete(adopted.ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2)
+	require.Equal(t, "2026-10-02T00:00:00Z", launched.runs[1].Params["logical_date"])
+
+	stored := getBackfill(t, db, backfill.ID)
+	require.Equal(t, 1, stored.CompletedRuns)
+	require.True(t, stored.Cursor.Equal(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestReconcileResumesRunLaunchedByPreviousLeader(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 3, 1)
+	ctx := context.Background()
+
+	// A previous leader recorded its launch intent and started the run, then
+	// died before its goroutine ran the job or the cursor advanced.
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	job := getJob(t, db, backfill.JobID)
+	require.NoError(t, reconciler.store.SetLaunchIntent(backfill.ID, date))
+	orphan, err := reconciler.runStore.StartForBackfill(job.ID, backfill.ID, map[string]string{"logical_date": "2026-10-01T00:00:00Z"})
+	require.NoError(t, err)
+	require.NoError(t, reconciler.store.SetTotalRuns(backfill.ID, 3))
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1, "the orphaned run is executed again")
+	require.Equal(t, orphan.ID, launched.runs[0].ID)
+
+	stored := getBackfill(t, db, backfill.ID)
+	require.Nil(t, stored.LaunchDate)
+	require.True(t, stored.Cursor.Equal(date))
+
+	// The intent is complete, so later passes do not launch the run again.
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1)
+}
+
+func TestReconcileAdoptsEveryRunAfterLeaderFailover(t *testing.T) {
+	db, previous, previousLaunched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 3, 2)
+	ctx := context.Background()
+
+	// The previous leader launches two runs and records the cursor, then dies
+	// with both executions.
+	require.NoError(t, previous.ReconcileOnce(ctx))
+	require.Len(t, previousLaunched.runs, 2)
+	stored := getBackfill(t, db, backfill.ID)
+	require.Nil(t, stored.LaunchDate)
+	require.True(t, stored.Cursor.Equal(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)))
+
+	launched := &launchRecorder{}
+	reconciler := NewReconciler(Config{DB: db, LaunchRun: launched.launch})
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2, "both runs hold the slots, so no new date launches")
+	require.ElementsMatch(t,
+		[]uuid.UUID{previousLaunched.runs[0].ID, previousLaunched.runs[1].ID},
+		[]uuid.UUID{launched.runs[0].ID, launched.runs[1].ID},
+	)
+
+	// Adopted runs are owned now and are not executed again.
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2)
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[0].ID, nil))
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[1].ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 3)
+	require.Equal(t, "2026-10-03T00:00:00Z", launched.runs[2].Params["logical_date"])
+}
+
+func TestReconcileHoldsPausedBackfill(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 3, 1)
+	ctx := context.Background()
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1)
+
+	paused, err := reconciler.store.Pause(backfill.ID)
+	require.NoError(t, err)
+	require.True(t, paused)
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[0].ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1)
+	require.Equal(t, string(models.BackfillStatusPaused), getBackfill(t, db, backfill.ID).Status)
+
+	resumed, err := reconciler.store.Resume(backfill.ID)
+	require.NoError(t, err)
+	require.True(t, resumed)
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2)
+	require.Equal(t, "2026-10-02T00:00:00Z", launched.runs[1].Params["logical_date"])
+}
+
+func TestReconcileCancelsOnceInFlightRunsDrain(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 3, 1)
+	ctx := context.Background()
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1)
+
+	require.NoError(t, reconciler.store.RequestCancel(backfill.ID))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1)
+	require.Equal(t, string(models.BackfillStatusRunning), getBackfill(t, db, backfill.ID).Status)
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[0].ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1)
+	require.Equal(t, string(models.BackfillStatusCancelled), getBackfill(t, db, backfill.ID).Status)
+}
+
+func TestReconcileStopsAtMaxFailures(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 5, 2)
+	require.NoError(t, db.Model(backfill).Update("max_failures", 1).Error)
+	ctx := context.Background()
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2)
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[0].ID, errors.New("boom")))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 2, "no run launches once the failure budget is spent")
+	require.Equal(t, string(models.BackfillStatusRunning), getBackfill(t, db, backfill.ID).Status)
+
+	require.NoError(t, reconciler.runStore.Complete(launched.runs[1].ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	stored := getBackfill(t, db, backfill.ID)
+	require.Equal(t, string(models.BackfillStatusFailed), stored.Status)
+	require.Equal(t, 1, stored.CompletedRuns)
+	require.Equal(t, 1, stored.FailedRuns)
+}
+
+func TestReconcileWaitsForNamespaceQuota(t *testing.T) {
+	t.Cleanup(func() { _ = env.Process() })
+	t.Setenv("CAESIUM_NAMESPACE_QUOTAS", `{"default":{"maxRuns":1}}`)
+	require.NoError(t, env.Process())
+
+	db, reconciler, launched := newTestReconciler(t)
+	backfill := createBackfill(t, db, 3, 2)
+	ctx := context.Background()
+
+	// An ordinary run holds the namespace's only slot.
+	busy, err := reconciler.runStore.Start(backfill.JobID, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Empty(t, launched.runs)
+	require.Nil(t, getBackfill(t, db, backfill.ID).Cursor)
+
+	require.NoError(t, reconciler.runStore.Complete(busy.ID, nil))
+	require.NoError(t, reconciler.ReconcileOnce(ctx))
+	require.Len(t, launched.runs, 1, "the backfill run takes the freed slot, not maxConcurrent")
+	require.Equal(t, "2026-10-01T00:00:00Z", launched.runs[0].Params["logical_date"])
+}
+
+func TestReconcileSkipsWhenNotLeader(t *testing.T) {
+	db, reconciler, launched := newTestReconciler(t)
+	createBackfill(t, db, 2, 1)
+	reconciler.leaderCheck = func(context.Context) (bool, error) { return false, nil }
+
+	require.NoError(t, reconciler.ReconcileOnce(context.Background()))
+	require.Empty(t, launched.runs)
+}
+
+func newTestReconciler(t *testing.T) (*gorm.DB, *Reconciler, *launchRecorder) {
+	t.Helper()
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	launched := &launchRecorder{}
+	reconciler := NewReconciler(Config{
+		DB:        db,
+		Store:     backfillstore.NewStore(db),
+		LaunchRun: launched.launch,
+	})
+	return db, reconciler, launched
+}
+
+// createBackfill creates a daily cron job and a backfill covering days
+// logical dates from 2026-10-01.
+func createBackfill(t *testing.T, db *gorm.DB, days, maxConcurrent int) *models.Backfill {
+	t.Helper()
+	now := time.Now().UTC()
+	trigger := &models.Trigger{
+		ID:            uuid.New(),
+		Alias:         "daily-" + uuid.NewString(),
+		Type:          models.TriggerTypeCron,
+		Configuration: `{"cron":"0 0 * * *","timezone":"UTC"}`,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, db.Create(trigger).Error)
+	job := &models.Job{ID: uuid.New(), Alias: "nightly", TriggerID: trigger.ID, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(job).Error)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	backfill := &models.Backfill{
+		ID:            uuid.New(),
+		JobID:         job.ID,
+		Status:        string(models.BackfillStatusRunning),
+		Start:         start,
+		End:           start.AddDate(0, 0, days),
+		MaxConcurrent: maxConcurrent,
+		Reprocess:     string(models.ReprocessNone),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, db.Create(backfill).Error)
+	return backfill
+}
+
+func getBackfill(t *testing.T, db *gorm.DB, id uuid.UUID) *models.Backfill {
+	t.Helper()
+	var backfill models.Backfill
+	require.NoError(t, db.First(&backfill, "id = ?", id).Error)
+	return &backfill
+}
+
+func getJob(t *testing.T, db *gorm.DB, id uuid.UUID) *models.Job {
+	t.Helper()
+	var job models.Job
+	require.NoError(t, db.First(&job, "id = ?", id).Error)
+	return &job
+}
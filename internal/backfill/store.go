@@ -1,7 +1,6 @@
 package backfill
 
 import (
-	"encoding/json"
 	"errors"
 	"math/rand/v2"
 	"sync"
@@ -68,25 +67,65 @@ func (s *Store) List(jobID uuid.UUID) ([]*models.Backfill, error) {
 	return backfills, nil
 }
 
-// RequestCancel persists a cancellation request for a running backfill.
+// activeStatuses are the non-terminal backfill states the reconciler drives.
+var activeStatuses = []string{string(models.BackfillStatusRunning), string(models.BackfillStatusPaused)}
+
+// ListActive returns every running or paused backfill, oldest first.
+func (s *Store) ListActive() ([]*models.Backfill, error) {
+	var backfills []*models.Backfill
+	if err := s.db.Where("status IN ?", activeStatuses).Order("created_at asc").Find(&backfills).Error; err != nil {
+		return nil, err
+	}
+	return backfills, nil
+}
+
+// RequestCancel persists a cancellation request for a running or paused
+// backfill.
 func (s *Store) RequestCancel(id uuid.UUID) error {
 	now := time.Now().UTC()
 	return s.withBusyRetry(func() error {
 		return s.db.Model(&models.Backfill{}).
-			Where("id = ? AND status = ?", id, string(models.BackfillStatusRunning)).
+			Where("id = ? AND status IN ?", id, activeStatuses).
 			Updates(map[string]interface{}{
 				"cancel_requested_at": now,
 			}).Error
 	})
 }
 
-// MarkCancelled marks a running backfill as fully cancelled after in-flight
-// work has drained.
+// Pause stops a running backfill from launching further logical dates. It
+// reports false when the backfill is not running or is being cancelled.
+func (s *Store) Pause(id uuid.UUID) (bool, error) {
+	return s.transition(id, models.BackfillStatusRunning, models.BackfillStatusPaused)
+}
+
+// Resume lets a paused backfill launch logical dates again. It reports false
+// when the backfill is not paused or is being cancelled.
+func (s *Store) Resume(id uuid.UUID) (bool, error) {
+	return s.transition(id, models.BackfillStatusPaused, models.BackfillStatusRunning)
+}
+
+func (s *Store) transition(id uuid.UUID, from, to models.BackfillStatus) (bool, error) {
+	var changed bool
+	err := s.withBusyRetry(func() error {
+		result := s.db.Model(&models.Backfill{}).
+			Where("id = ? AND status = ? AND cancel_requested_at IS NULL", id, string(from)).
+			Updates(map[string]interface{}{
+				"status":     string(to),
+				"updated_at": time.Now().UTC(),
+			})
+		changed = result.RowsAffected > 0
+		return result.Error
+	})
+	return changed, err
+}
+
+// MarkCancelled marks a running or paused backfill as fully cancelled after
+// in-flight work has drained.
 func (s *Store) MarkCancelled(id uuid.UUID) error {
 	now := time.Now().UTC()
 	return s.withBusyRetry(func() error {
 		return s.db.Model(&models.Backfill{}).
-			Where("id = ? AND status = ?", id, string(models.BackfillStatusRunning)).
+			Where("id = ? AND status IN ?", id, activeStatuses).
 			Updates(map[string]interface{}{
 				"status":       string(models.BackfillStatusCancelled),
 				"completed_at": now,
@@ -94,6 +133,7 @@ func (s *Store) MarkCancelled(id uuid.UUID) error {
 	})
 }
 
+// Complete moves a running or paused backfill to succeeded or failed.
 func (s *Store) Complete(id uuid.UUID, failed bool) error {
 	now := time.Now().UTC()
 	status := models.BackfillStatusSucceeded
@@ -102,7 +142,7 @@ func (s *Store) Complete(id uuid.UUID, failed bool) error {
 	}
 	return s.withBusyRetry(func() error {
 		return s.db.Model(&models.Backfill{}).
-			Where("id = ? AND status = ?", id, string(models.BackfillStatusRunning)).
+			Where("id = ? AND status IN ?", id, activeStatuses).
 			Updates(map[string]interface{}{
 				"status":       string(status),
 				"completed_at": now,
@@ -110,33 +150,124 @@ func (s *Store) Complete(id uuid.UUID, failed bool) error {
 	})
 }
 
-// AddProgress applies accumulated run-completion counts to a backfill in a
-// single UPDATE. The executor batches per-run increments and flushes them
-// periodically: a large backfill otherwise issues one completed_runs/
-// failed_runs write per run, all on the same backfills row, which serialize
-// through dqlite's single writer and starve concurrent control-plane writes
-// (e.g. cancellation) past the busy-retry budget.
-//
-// completedDelta and failedDelta are the counts to add and must be
-// non-negative; the executor's source counters are increment-only.
-func (s *Store) AddProgress(id uuid.UUID, completedDelta, failedDelta int64) error {
-	if completedDelta == 0 && failedDelta == 0 {
-		return nil
+// Progress counts a backfill's runs from job_runs: active runs are still
+// running, completed ones succeeded, and failed ones ended any other way.
+type Progress struct {
+	Active    int
+	Completed int
+	Failed    int
+}
+
+// RefreshProgress recounts b's runs and persists completed_runs and
+// failed_runs when they changed. Counting from the runs themselves keeps the
+// counters exact across leader failover; the backfills row is only written
+// when a run finished since the last refresh, so idle ticks stay read-only.
+func (s *Store) RefreshProgress(b *models.Backfill) (Progress, error) {
+	var rows []struct {
+		Status string
+		Count  int
 	}
-	updates := make(map[string]interface{}, 2)
-	if completedDelta != 0 {
-		updates["completed_runs"] = gorm.Expr("completed_runs + ?", completedDelta)
+	if err := s.db.Model(&models.JobRun{}).
+		Select("status, count(*) AS count").
+		Where("backfill_id = ?", b.ID).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return Progress{}, err
 	}
-	if failedDelta != 0 {
-		updates["failed_runs"] = gorm.Expr("failed_runs + ?", failedDelta)
+
+	var p Progress
+	for _, row := range rows {
+		switch row.Status {
+		case "running":
+			p.Active += row.Count
+		case "succeeded":
+			p.Completed += row.Count
+		default:
+			p.Failed += row.Count
+		}
+	}
+	if p.Completed == b.CompletedRuns && p.Failed == b.FailedRuns {
+		return p, nil
 	}
+	err := s.withBusyRetry(func() error {
+		return s.db.Model(&models.Backfill{}).
+			Where("id = ?", b.ID).
+			UpdateColumns(map[string]interface{}{
+				"completed_runs": p.Completed,
+				"failed_runs":    p.Failed,
+			}).Error
+	})
+	if err == nil {
+		b.CompletedRuns, b.FailedRuns = p.Completed, p.Failed
+	}
+	return p, err
+}
+
+// SetCursor records that every logical date up to and including cursor has
+// been launched or skipped, which also completes any launch intent.
+func (s *Store) SetCursor(id uuid.UUID, cursor time.Time) error {
+	cursor = cursor.UTC()
 	return s.withBusyRetry(func() error {
 		return s.db.Model(&models.Backfill{}).
 			Where("id = ?", id).
-			UpdateColumns(updates).Error
+			UpdateColumns(map[string]interface{}{
+				"cursor":      cursor,
+				"launch_date": nil,
+			}).Error
 	})
 }
 
+// SetLaunchIntent records that the reconciler is about to start the run for
+// logicalDate. It is written before the run starts, so a leader that dies
+// before advancing the cursor leaves its successor a durable record of the
+// launch to finish.
+func (s *Store) SetLaunchIntent(id uuid.UUID, logicalDate time.Time) error {
+	logicalDate = logicalDate.UTC()
+	return s.withBusyRetry(func() error {
+		return s.db.Model(&models.Backfill{}).
+			Where("id = ?", id).
+			UpdateColumn("launch_date", logicalDate).Error
+	})
+}
+
+// LaunchedRun returns the run the backfill started for logicalDate, or nil
+// when it started none. Only the run's id and status are loaded.
+func (s *Store) LaunchedRun(id uuid.UUID, logicalDate string) (*models.JobRun, error) {
+	var runs []models.JobRun
+	if err := s.db.Model(&models.JobRun{}).
+		Select("id", "status").
+		Where("backfill_id = ? AND logical_date = ?", id, logicalDate).
+		Limit(1).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+// RunningRunIDs returns the ids of the backfill's runs that are still
+// running. After a leader failover these include runs whose executions died
+// with the previous leader, which the reconciler adopts.
+func (s *Store) RunningRunIDs(id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := s.db.Model(&models.JobRun{}).
+		Where("backfill_id = ? AND status = ?", id, "running").
+		Order("created_at").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// HasRun reports whether the backfill already launched a run for
+// logicalDate. The reconciler checks it before launching a date, so a leader
+// that dies between starting a run and advancing the cursor does not cause
+// its successor to start the date twice.
+func (s *Store) HasRun(id uuid.UUID, logicalDate string) (bool, error) {
+	run, err := s.LaunchedRun(id, logicalDate)
+	return run != nil, err
+}
+
 // SetTotalRuns updates the total_runs counter on a backfill.
 func (s *Store) SetTotalRuns(id uuid.UUID, total int) error {
 	return s.withBusyRetry(func() error {
@@ -174,31 +305,20 @@ func (s *Store) IsCancelRequested(id uuid.UUID) (bool, error) {
 }
 
 // LatestRunForLogicalDate returns the status of the most recent run for a job
-// whose params contain the given logical_date value, or "" if none exists.
-// It uses Go-side filtering to remain portable across SQLite and Postgres.
+// whose logical_date param is the given value, or "" if none exists.
 func (s *Store) LatestRunForLogicalDate(jobID uuid.UUID, logicalDate string) (string, error) {
-	type runRow struct {
-		Status string
-		Params []byte
-	}
-	var rows []runRow
-	err := s.db.Raw(
-		`SELECT status, params FROM job_runs WHERE job_id = ? AND params IS NOT NULL ORDER BY created_at DESC`,
-		jobID,
-	).Scan(&rows).Error
-	if err != nil {
+	var statuses []string
+	if err := s.db.Model(&models.JobRun{}).
+		Where("job_id = ? AND logical_date = ?", jobID, logicalDate).
+		Order("created_at DESC").
+		Limit(1).
+		Pluck("status", &statuses).Error; err != nil {
 		return "", err
 	}
-	for _, row := range rows {
-		var p map[string]string
-		if err := json.Unmarshal(row.Params, &p); err != nil {
-			continue
-		}
-		if p["logical_date"] == logicalDate {
-			return row.Status, nil
-		}
+	if len(statuses) == 0 {
+		return "", nil
 	}
-	return "", nil
+	return statuses[0], nil
 }
 
 // withBusyRetry retries the given operation when it fails with a transient
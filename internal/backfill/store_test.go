@@ -8,6 +8,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -180,20 +181,132 @@ func TestCompleteDoesNotOverwriteCancelledBackfill(t *testing.T) {
 	require.NotNil(t, backfill.CompletedAt)
 }
 
-func TestAddProgressAccumulatesBatchedCounts(t *testing.T) {
+func TestPauseAndResumeTransitionOnlyFromExpectedStatus(t *testing.T) {
 	store, db, backfillID := newBackfillTestStore(t)
 
-	// A zero delta is a no-op — the flusher calls AddProgress on every tick,
-	// including ticks where no run finished.
-	require.NoError(t, store.AddProgress(backfillID, 0, 0))
+	changed, err := store.Resume(backfillID)
+	require.NoError(t, err)
+	require.False(t, changed, "a running backfill cannot be resumed")
+
+	changed, err = store.Pause(backfillID)
+	require.NoError(t, err)
+	require.True(t, changed)
+	changed, err = store.Pause(backfillID)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	active, err := store.ListActive()
+	require.NoError(t, err)
+	require.Len(t, active, 1, "paused backfills stay active")
+
+	changed, err = store.Resume(backfillID)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	// Once cancellation is requested the backfill can no longer be paused.
+	require.NoError(t, store.RequestCancel(backfillID))
+	changed, err = store.Pause(backfillID)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	var backfill models.Backfill
+	require.NoError(t, db.First(&backfill, "id = ?", backfillID).Error)
+	require.Equal(t, string(models.BackfillStatusRunning), backfill.Status)
+}
+
+func TestRefreshProgressCountsBackfillRuns(t *testing.T) {
+	store, db, backfillID := newBackfillTestStore(t)
+
+	var backfill models.Backfill
+	require.NoError(t, db.First(&backfill, "id = ?", backfillID).Error)
+	for i, status := range []string{"running", "succeeded", "succeeded", "failed"} {
+		createBackfillRun(t, db, &backfill, status, time.Date(2026, 10, i+1, 0, 0, 0, 0, time.UTC))
+	}
+
+	progress, err := store.RefreshProgress(&backfill)
+	require.NoError(t, err)
+	require.Equal(t, Progress{Active: 1, Completed: 2, Failed: 1}, progress)
 
-	require.NoError(t, store.AddProgress(backfillID, 3, 0))
-	require.NoError(t, store.AddProgress(backfillID, 2, 1))
+	var stored models.Backfill
+	require.NoError(t, db.First(&stored, "id = ?", backfillID).Error)
+	require.Equal(t, 2, stored.CompletedRuns)
+	require.Equal(t, 1, stored.FailedRuns)
+}
+
+func TestRunningRunIDsListsOnlyRunningRuns(t *testing.T) {
+	store, db, backfillID := newBackfillTestStore(t)
 
 	var backfill models.Backfill
 	require.NoError(t, db.First(&backfill, "id = ?", backfillID).Error)
-	require.Equal(t, 5, backfill.CompletedRuns)
-	require.Equal(t, 1, backfill.FailedRuns)
+	first := createBackfillRun(t, db, &backfill, "running", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	createBackfillRun(t, db, &backfill, "succeeded", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+	third := createBackfillRun(t, db, &backfill, "running", time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+
+	ids, err := store.RunningRunIDs(backfillID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{first.ID, third.ID}, ids)
+}
+
+func TestHasRunMatchesLogicalDate(t *testing.T) {
+	store, db, backfillID := newBackfillTestStore(t)
+
+	var backfill models.Backfill
+	require.NoError(t, db.First(&backfill, "id = ?", backfillID).Error)
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	createBackfillRun(t, db, &backfill, "running", date)
+
+	started, err := store.HasRun(backfillID, date.Format(time.RFC3339))
+	require.NoError(t, err)
+	require.True(t, started)
+
+	started, err = store.HasRun(backfillID, date.AddDate(0, 0, 1).Format(time.RFC3339))
+	require.NoError(t, err)
+	require.False(t, started)
+}
+
+func TestSetCursorCompletesLaunchIntent(t *testing.T) {
+	store, db, backfillID := newBackfillTestStore(t)
+
+	date := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SetLaunchIntent(backfillID, date))
+
+	var backfill models.Backfill
+	require.NoError(t, db.First(&backfill, "id = ?", backfillID).Error)
+	require.NotNil(t, backfill.LaunchDate)
+	require.True(t, backfill.LaunchDate.Equal(date))
+
+	run, err := store.LaunchedRun(backfillID, date.Format(time.RFC3339))
+	require.NoError(t, err)
+	require.Nil(t, run)
+	createBackfillRun(t, db, &backfill, "running", date)
+	run, err = store.LaunchedRun(backfillID, date.Format(time.RFC3339))
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	require.Equal(t, "running", run.Status)
+
+	require.NoError(t, store.SetCursor(backfillID, date))
+	require.NoError(t, db.First(&backfill, "id = ?", backfillID).Error)
+	require.Nil(t, backfill.LaunchDate)
+	require.True(t, backfill.Cursor.Equal(date))
+}
+
+func createBackfillRun(t *testing.T, db *gorm.DB, backfill *models.Backfill, status string, logicalDate time.Time) models.JobRun {
+	t.Helper()
+	now := time.Now().UTC()
+	backfillID := backfill.ID
+	run := models.JobRun{
+		ID:          uuid.New(),
+		JobID:       backfill.JobID,
+		BackfillID:  &backfillID,
+		Status:      status,
+		Params:      datatypes.JSON(`{"logical_date":"` + logicalDate.Format(time.RFC3339) + `"}`),
+		LogicalDate: logicalDate.Format(time.RFC3339),
+		StartedAt:   now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, db.Create(&run).Error)
+	return run
 }
 
 func newBackfillTestStore(t *testing.T) (*Store, *gorm.DB, uuid.UUID) {
@@ -0,0 +1,297 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/dqlite"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ErrUnsupportedDatabase is returned when the server is not on the internal
+// dqlite database. PostgreSQL deployments back up with their own tooling.
+var ErrUnsupportedDatabase = errors.New("backups require the internal dqlite database; use pg_dump for PostgreSQL")
+
+// Dumper returns a consistent copy of the named database as the SQLite main
+// file followed by its write-ahead log. dqlite.Dump is the production Dumper.
+type Dumper func(ctx context.Context, name string) ([]dqlite.File, error)
+
+// Options configures Write.
+type Options struct {
+	Databases []db.NamedDatabase
+	Shards    int
+	Dump      Dumper
+	Now       func() time.Time
+}
+
+// Take backs up every database the default router opened.
+func Take(ctx context.Context, w io.Writer) (*Manifest, error) {
+	if !db.InternalDqlite() {
+		return nil, ErrUnsupportedDatabase
+	}
+	return Write(ctx, w, Options{
+		Databases: db.Databases(),
+		Shards:    db.DefaultRouter().ShardCount(),
+		Dump:      dqlite.Dump,
+	})
+}
+
+// Write dumps every database in opts and writes the archive to w. Each dump
+// is checkpointed into a single SQLite file and integrity-checked before it
+// is added.
+func Write(ctx context.Context, w io.Writer, opts Options) (*Manifest, error) {
+	if opts.Dump == nil {
+		return nil, errors.New("backup requires a database dumper")
+	}
+	if len(opts.Databases) == 0 {
+		return nil, errors.New("backup requires at least one database")
+	}
+	fingerprint, err := SchemaFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	shards := opts.Shards
+	if shards <= 0 {
+		shards = 1
+	}
+
+	workDir, err := os.MkdirTemp("", "caesium-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	manifest := &Manifest{
+		FormatVersion:    FormatVersion,
+		MigrationVersion: db.SchemaVersion,
+		SchemaVersion:    fingerprint,
+		CreatedAt:        now().UTC(),
+		Shards:           shards,
+	}
+	paths := make([]string, 0, len(opts.Databases))
+	for _, database := range opts.Databases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		file, err := dumpDatabase(ctx, opts.Dump, database.Name, filepath.Join(workDir, database.Name))
+		if err != nil {
+			return nil, fmt.Errorf("dump database %s: %w", database.Name, err)
+		}
+		entry, err := describeDatabase(file)
+		if err != nil {
+			return nil, fmt.Errorf("check database %s: %w", database.Name, err)
+		}
+		entry.Name = database.Name
+		entry.Role = database.Role
+		entry.File = path.Join(databaseDir, database.Name+".db")
+		manifest.Databases = append(manifest.Databases, entry)
+		paths = append(paths, file)
+	}
+
+	if err := writeArchive(w, manifest, paths); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// dumpDatabase writes the dump of name into dir and folds its write-ahead log
+// into the main file, returning the main file's path.
+func dumpDatabase(ctx context.Context, dump Dumper, name, dir string) (string, error) {
+	files, err := dump(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", errors.New("dump returned no files")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	// SQLite finds the log next to the main file by name, so keep the names
+	// the dump used.
+	main := filepath.Join(dir, filepath.Base(files[0].Name))
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(file.Name)), file.Data, 0o600); err != nil {
+			return "", err
+		}
+	}
+
+	conn, err := openSQLite(main, false)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	for _, stmt := range []string{
+		"PRAGMA wal_checkpoint(TRUNCATE)",
+		"PRAGMA journal_mode=DELETE",
+	} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return "", fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	return main, nil
+}
+
+// describeDatabase checks the SQLite file's integrity and returns its size,
+// checksum, and row counts.
+func describeDatabase(file string) (Database, error) {
+	conn, err := openSQLite(file, true)
+	if err != nil {
+		return Database{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := checkIntegrity(conn); err != nil {
+		return Database{}, err
+	}
+	tables, err := countRows(conn)
+	if err != nil {
+		return Database{}, err
+	}
+	size, sum, err := checksumFile(file)
+	if err != nil {
+		return Database{}, err
+	}
+	return Database{Size: size, SHA256: sum, Tables: tables}, nil
+}
+
+func writeArchive(w io.Writer, manifest *Manifest, paths []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	payload, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    manifestName,
+		Mode:    0o600,
+		Size:    int64(len(payload)),
+		ModTime: manifest.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(payload); err != nil {
+		return err
+	}
+
+	for idx, database := range manifest.Databases {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    database.File,
+			Mode:    0o600,
+			Size:    database.Size,
+			ModTime: manifest.CreatedAt,
+		}); err != nil {
+			return err
+		}
+		if err := copyFile(tw, paths[idx]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func copyFile(w io.Writer, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func checksumFile(name string) (int64, string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func openSQLite(file string, readOnly bool) (*sql.DB, error) {
+	dsn := "file:" + file
+	if readOnly {
+		dsn += "?mode=ro"
+	}
+	conn, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+	return conn, nil
+}
+
+func checkIntegrity(conn *sql.DB) error {
+	var result string
+	if err := conn.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+	return nil
+}
+
+// userTables lists the tables SQLite did not create for itself.
+func userTables(conn *sql.DB) ([]string, error) {
+	rows, err := conn.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func countRows(conn *sql.DB) (map[string]int64, error) {
+	tables, err := userTables(conn)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		if err := conn.QueryRow("SELECT COUNT(*) FROM " + quoteIdent(table)).Scan(&count); err != nil {
+			return nil, err
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
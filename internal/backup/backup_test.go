@@ -0,0 +1,234 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/dqlite"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// seedSourceDB creates a migrated SQLite file holding one job and one run
+// and returns its path.
+func seedSourceDB(t *testing.T) (string, uuid.UUID) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "caesium")
+	conn, err := gorm.Open(sqlite.Open(file), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, conn.AutoMigrate(models.All...))
+
+	now := time.Now().UTC()
+	trigger := &models.Trigger{ID: uuid.New(), Alias: "nightly-cron", Type: models.TriggerTypeCron, Configuration: `{"cron":"0 2 * * *"}`, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, conn.Create(trigger).Error)
+	job := &models.Job{ID: uuid.New(), Alias: "nightly", Namespace: "default", TriggerID: trigger.ID, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, conn.Create(job).Error)
+	require.NoError(t, conn.Create(&models.JobRun{ID: uuid.New(), JobID: job.ID, Status: "succeeded", StartedAt: now, CreatedAt: now, UpdatedAt: now}).Error)
+
+	sqlDB, err := conn.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	return file, job.ID
+}
+
+func fileDumper(files map[string]string) Dumper {
+	return func(_ context.Context, name string) ([]dqlite.File, error) {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return nil, err
+		}
+		return []dqlite.File{{Name: name, Data: data}}, nil
+	}
+}
+
+func writeTestBackup(t *testing.T, source string) ([]byte, *Manifest) {
+	t.Helper()
+	var archive bytes.Buffer
+	manifest, err := Write(context.Background(), &archive, Options{
+		Databases: []db.NamedDatabase{{Name: "caesium", Role: db.DatabaseRoleCatalog}},
+		Dump:      fileDumper(map[string]string{"caesium": source}),
+	})
+	require.NoError(t, err)
+	return archive.Bytes(), manifest
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	source, jobID := seedSourceDB(t)
+	archive, manifest := writeTestBackup(t, source)
+
+	fingerprint, err := SchemaFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, FormatVersion, manifest.FormatVersion)
+	require.Equal(t, db.SchemaVersion, manifest.MigrationVersion)
+	require.Equal(t, fingerprint, manifest.SchemaVersion)
+	require.Equal(t, 1, manifest.Shards)
+	catalog, ok := manifest.Database("caesium")
+	require.True(t, ok)
+	require.Equal(t, int64(1), catalog.Tables["jobs"])
+	require.Equal(t, int64(1), catalog.Tables["job_runs"])
+
+	opened, err := Open(bytes.NewReader(archive), t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, manifest.SchemaVersion, opened.SchemaVersion)
+
+	target := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(target) })
+	targets := []db.NamedDatabase{{Name: "caesium", Role: db.DatabaseRoleCatalog, Conn: target}}
+	require.NoError(t, opened.Compatible(db.SchemaVersion, targets))
+
+	dir := t.TempDir()
+	opened, err = Open(bytes.NewReader(archive), dir)
+	require.NoError(t, err)
+	require.NoError(t, Restore(context.Background(), dir, opened, targets))
+
+	var job models.Job
+	require.NoError(t, target.First(&job, "id = ?", jobID).Error)
+	require.Equal(t, "nightly", job.Alias)
+	testutil.AssertCount(t, target, &models.JobRun{}, 1)
+
+	// A second restore would duplicate everything, so it is refused.
+	require.ErrorContains(t, Restore(context.Background(), dir, opened, targets), "restore needs a fresh cluster")
+}
+
+func TestOpenRejectsTamperedArchive(t *testing.T) {
+	source, _ := seedSourceDB(t)
+	_, manifest := writeTestBackup(t, source)
+
+	// Rebuild the archive around a database that differs from the manifest.
+	other, _ := seedSourceDB(t)
+	var tampered bytes.Buffer
+	require.NoError(t, writeArchive(&tampered, manifest, []string{other}))
+
+	_, err := Open(bytes.NewReader(tampered.Bytes()), t.TempDir())
+	require.ErrorContains(t, err, "verify database caesium")
+}
+
+// schemaFingerprints pins SchemaFingerprint for each db.SchemaVersion. A
+// model change that fails TestSchemaFingerprintMatchesSchemaVersion needs a
+// db.SchemaVersion bump and a new entry here.
+var schemaFingerprints = map[int]string{
+	1: "8ee0dd074b7d3ae1",
+	2: "7a645fc83108e0ae",
+	3: "eb1e714ffb5500a6",
+}
+
+func TestSchemaFingerprintMatchesSchemaVersion(t *testing.T) {
+	fingerprint, err := SchemaFingerprint()
+	require.NoError(t, err)
+	require.Equal(t, schemaFingerprints[db.SchemaVersion], fingerprint,
+		"the migrated tables or columns changed: bump db.SchemaVersion and record the new fingerprint")
+}
+
+func TestRestoreMigratesOlderBackup(t *testing.T) {
+	source, jobID := seedSourceDB(t)
+	// The older build had a job_runs column and a table this one dropped.
+	conn, err := gorm.Open(sqlite.Open(source), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, conn.Exec("ALTER TABLE job_runs ADD COLUMN legacy_owner TEXT").Error)
+	require.NoError(t, conn.Exec("UPDATE job_runs SET legacy_owner = 'node-1'").Error)
+	require.NoError(t, conn.Exec("CREATE TABLE legacy_leases (id TEXT PRIMARY KEY)").Error)
+	require.NoError(t, conn.Exec("INSERT INTO legacy_leases (id) VALUES ('lease')").Error)
+	sqlDB, err := conn.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	archive, _ := writeTestBackup(t, source)
+	dir := t.TempDir()
+	opened, err := Open(bytes.NewReader(archive), dir)
+	require.NoError(t, err)
+	opened.MigrationVersion = db.SchemaVersion - 1
+	require.True(t, opened.Older(db.SchemaVersion))
+
+	target := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(target) })
+	targets := []db.NamedDatabase{{Name: "caesium", Role: db.DatabaseRoleCatalog, Conn: target}}
+	require.NoError(t, opened.Compatible(db.SchemaVersion, targets))
+	require.NoError(t, Restore(context.Background(), dir, opened, targets))
+
+	var job models.Job
+	require.NoError(t, target.First(&job, "id = ?", jobID).Error)
+	testutil.AssertCount(t, target, &models.JobRun{}, 1)
+	require.False(t, target.Migrator().HasTable("legacy_leases"))
+}
+
+func TestManifestCompatible(t *testing.T) {
+	manifest := &Manifest{
+		FormatVersion:    FormatVersion,
+		MigrationVersion: 2,
+		SchemaVersion:    "abc",
+		Shards:           2,
+		Databases: []Database{
+			{Name: "caesium", Role: db.DatabaseRoleCatalog},
+			{Name: "caesium_hot_00", Role: db.DatabaseRoleHot},
+			{Name: "caesium_hot_01", Role: db.DatabaseRoleHot},
+			{Name: "caesium_history", Role: db.DatabaseRoleCold},
+		},
+	}
+	sharded := []db.NamedDatabase{
+		{Name: "caesium", Role: db.DatabaseRoleCatalog},
+		{Name: "caesium_hot_00", Role: db.DatabaseRoleHot},
+		{Name: "caesium_hot_01", Role: db.DatabaseRoleHot},
+		{Name: "caesium_history", Role: db.DatabaseRoleCold},
+	}
+
+	require.NoError(t, manifest.Compatible(2, sharded))
+	require.NoError(t, manifest.Compatible(3, sharded), "a newer build restores an older backup")
+	require.ErrorContains(t, manifest.Compatible(1, sharded), "newer than this build's 1")
+	require.ErrorContains(t, manifest.Compatible(2, sharded[:1]), "set CAESIUM_DATABASE_SHARDS=2")
+	require.ErrorContains(t, manifest.Compatible(2, sharded[:3]), "set CAESIUM_RUN_ARCHIVE_ENABLED=true")
+}
+
+func TestSchedulerWritesAndPrunesArchives(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduler := NewScheduler(SchedulerConfig{
+		Dir:      dir,
+		Interval: time.Hour,
+		Keep:     2,
+		Backup: func(_ context.Context, w io.Writer) (*Manifest, error) {
+			_, err := io.WriteString(w, "archive")
+			return &Manifest{}, err
+		},
+		Now: func() time.Time { return now },
+	})
+
+	var written []string
+	for range 3 {
+		name, err := scheduler.BackupOnce(context.Background())
+		require.NoError(t, err)
+		written = append(written, filepath.Base(name))
+		now = now.Add(time.Hour)
+	}
+	require.Equal(t, "caesium-backup-20260101T000000Z.tar.gz", written[0])
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	require.Equal(t, written[1:], remaining, "only the newest Keep archives stay and no temp files are left")
+}
+
+func TestSchedulerSkipsFollowers(t *testing.T) {
+	dir := t.TempDir()
+	scheduler := NewScheduler(SchedulerConfig{
+		Dir:         dir,
+		Interval:    time.Hour,
+		Backup:      func(context.Context, io.Writer) (*Manifest, error) { panic("followers must not back up") },
+		LeaderCheck: func(context.Context) (bool, error) { return false, nil },
+	})
+	name, err := scheduler.BackupOnce(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, name)
+}
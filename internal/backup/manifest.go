@@ -0,0 +1,149 @@
+// Package backup takes online backups of Caesium's dqlite databases and
+// restores them into a fresh cluster.
+//
+// A backup is a gzip-compressed tar archive holding manifest.json and one
+// self-contained SQLite file per database under databases/. Each database is
+// dumped from the dqlite leader while the cluster keeps serving, so every
+// file is a consistent snapshot of its database. The databases are dumped
+// one after another, not at a single instant: a run the archiver moves in
+// between may appear in both a hot database and history, which is the same
+// state an interrupted archiver pass leaves and reads already handle.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"gorm.io/gorm/schema"
+)
+
+// FormatVersion is the version of the archive layout. Restore refuses
+// archives written in a different layout.
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	databaseDir  = "databases"
+)
+
+// Manifest describes a backup archive.
+type Manifest struct {
+	FormatVersion int `json:"formatVersion"`
+	// MigrationVersion is the db.SchemaVersion of the build that wrote the
+	// backup. Backups written before it was recorded have zero.
+	MigrationVersion int `json:"migrationVersion,omitempty"`
+	// SchemaVersion fingerprints the tables and columns of the build that
+	// wrote the backup; see SchemaFingerprint. It identifies the schema but
+	// does not decide whether a build can restore the backup.
+	SchemaVersion string    `json:"schemaVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	// Shards is CAESIUM_DATABASE_SHARDS at backup time. Runs are routed to hot
+	// databases by a hash of their ID, so a restore needs the same count.
+	Shards    int        `json:"shards"`
+	Databases []Database `json:"databases"`
+}
+
+// Database is one database in a backup archive.
+type Database struct {
+	Name string          `json:"name"`
+	Role db.DatabaseRole `json:"role"`
+	// File is the database's path inside the archive.
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Tables is the row count of every table in the database.
+	Tables map[string]int64 `json:"tables"`
+}
+
+// Database returns the manifest entry for the named database.
+func (m *Manifest) Database(name string) (Database, bool) {
+	for _, database := range m.Databases {
+		if database.Name == name {
+			return database, true
+		}
+	}
+	return Database{}, false
+}
+
+// CheckSchema reports whether a build at migrationVersion can restore the
+// backup: it must use the backup's archive format, and its migrations must
+// be at least as new as those of the build that wrote the backup.
+func (m *Manifest) CheckSchema(migrationVersion int) error {
+	if m.FormatVersion != FormatVersion {
+		return fmt.Errorf("backup format version %d is not supported (want %d)", m.FormatVersion, FormatVersion)
+	}
+	if m.MigrationVersion > migrationVersion {
+		return fmt.Errorf("backup schema version %d is newer than this build's %d; restore with the Caesium release that took the backup or a later one", m.MigrationVersion, migrationVersion)
+	}
+	return nil
+}
+
+// Older reports whether the backup was written before migrations this build
+// at migrationVersion runs, so its data must be migrated after a restore.
+func (m *Manifest) Older(migrationVersion int) bool {
+	return m.MigrationVersion < migrationVersion
+}
+
+// Compatible reports why the backup cannot be restored by this build into
+// targets, or nil when it can.
+func (m *Manifest) Compatible(migrationVersion int, targets []db.NamedDatabase) error {
+	if err := m.CheckSchema(migrationVersion); err != nil {
+		return err
+	}
+
+	shards := 0
+	byName := make(map[string]db.DatabaseRole, len(targets))
+	for _, target := range targets {
+		byName[target.Name] = target.Role
+		if target.Role == db.DatabaseRoleHot {
+			shards++
+		}
+	}
+	if shards == 0 {
+		shards = 1
+	}
+	if m.Shards != shards {
+		return fmt.Errorf("backup was taken with %d database shards but this node has %d; set CAESIUM_DATABASE_SHARDS=%d", m.Shards, shards, m.Shards)
+	}
+	for _, database := range m.Databases {
+		role, ok := byName[database.Name]
+		if !ok {
+			if database.Role == db.DatabaseRoleCold {
+				return fmt.Errorf("backup includes database %s but this node does not open it; set CAESIUM_RUN_ARCHIVE_ENABLED=true", database.Name)
+			}
+			return fmt.Errorf("backup includes database %s but this node does not open it", database.Name)
+		}
+		if role != database.Role {
+			return fmt.Errorf("backup database %s has role %s but this node uses it as %s", database.Name, database.Role, role)
+		}
+	}
+	return nil
+}
+
+// SchemaFingerprint fingerprints the tables and columns this build migrates.
+// Any model change that adds, removes, or renames a table or column changes
+// it, and must come with a db.SchemaVersion bump.
+func SchemaFingerprint() (string, error) {
+	cache := &sync.Map{}
+	tables := make([]string, 0, len(models.All))
+	for _, model := range models.All {
+		parsed, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			return "", err
+		}
+		columns := append([]string(nil), parsed.DBNames...)
+		sort.Strings(columns)
+		tables = append(tables, parsed.Table+"("+strings.Join(columns, ",")+")")
+	}
+	sort.Strings(tables)
+
+	sum := sha256.Sum256([]byte(strings.Join(tables, "\n")))
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,380 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"gorm.io/gorm"
+)
+
+// restoreBatchSize is how many rows one restore transaction inserts.
+const restoreBatchSize = 500
+
+// Open extracts the archive in r into dir and verifies it: every database
+// the manifest lists is present with the recorded size and checksum, passes
+// SQLite's integrity check, and holds the recorded row counts.
+func Open(r io.Reader, dir string) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read backup archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read backup archive: %w", err)
+	}
+	if header.Name != manifestName {
+		return nil, fmt.Errorf("backup archive must start with %s, found %s", manifestName, header.Name)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decode backup manifest: %w", err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("backup format version %d is not supported (want %d)", manifest.FormatVersion, FormatVersion)
+	}
+
+	byFile := make(map[string]Database, len(manifest.Databases))
+	for _, database := range manifest.Databases {
+		if database.Name == "" || filepath.Base(database.Name) != database.Name || strings.HasPrefix(database.Name, ".") {
+			return nil, fmt.Errorf("backup manifest has an invalid database name %q", database.Name)
+		}
+		byFile[database.File] = database
+	}
+
+	extracted := make(map[string]struct{}, len(manifest.Databases))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read backup archive: %w", err)
+		}
+		database, ok := byFile[header.Name]
+		if !ok {
+			return nil, fmt.Errorf("backup archive has unexpected entry %s", header.Name)
+		}
+		if err := extractFile(tr, databasePath(dir, database.Name), database.Size); err != nil {
+			return nil, fmt.Errorf("extract %s: %w", header.Name, err)
+		}
+		extracted[database.Name] = struct{}{}
+	}
+
+	for _, database := range manifest.Databases {
+		if _, ok := extracted[database.Name]; !ok {
+			return nil, fmt.Errorf("backup archive is missing database %s", database.Name)
+		}
+		if err := verifyDatabase(databasePath(dir, database.Name), database); err != nil {
+			return nil, fmt.Errorf("verify database %s: %w", database.Name, err)
+		}
+	}
+	return &manifest, nil
+}
+
+func databasePath(dir, name string) string {
+	return filepath.Join(dir, name+".db")
+}
+
+func extractFile(r io.Reader, name string, size int64) error {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	// Read one byte past the recorded size so an oversized entry is caught
+	// without extracting all of it.
+	written, err := io.Copy(file, io.LimitReader(r, size+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("size %d does not match the manifest's %d", written, size)
+	}
+	return nil
+}
+
+func verifyDatabase(file string, want Database) error {
+	got, err := describeDatabase(file)
+	if err != nil {
+		return err
+	}
+	if got.SHA256 != want.SHA256 {
+		return fmt.Errorf("checksum %s does not match the manifest's %s", got.SHA256, want.SHA256)
+	}
+	if len(got.Tables) != len(want.Tables) {
+		return fmt.Errorf("has %d tables but the manifest lists %d", len(got.Tables), len(want.Tables))
+	}
+	for table, count := range want.Tables {
+		if got.Tables[table] != count {
+			return fmt.Errorf("table %s has %d rows but the manifest records %d", table, got.Tables[table], count)
+		}
+	}
+	return nil
+}
+
+// Restore copies every database in an archive extracted by Open into the
+// target of the same name. Targets must already be migrated and must be
+// empty. Tables are copied parents first so foreign keys hold throughout.
+func Restore(ctx context.Context, dir string, manifest *Manifest, targets []db.NamedDatabase) error {
+	byName := make(map[string]*gorm.DB, len(targets))
+	for _, target := range targets {
+		byName[target.Name] = target.Conn
+	}
+
+	// A backup from before this build's migrations may hold tables that were
+	// since dropped; their rows have nowhere to go.
+	older := manifest.Older(db.SchemaVersion)
+
+	// Refuse before writing anything, so a bad target never ends up with a
+	// partial restore.
+	for _, database := range manifest.Databases {
+		target, ok := byName[database.Name]
+		if !ok {
+			return fmt.Errorf("no target database for %s", database.Name)
+		}
+		if err := requireEmpty(ctx, target, database, older); err != nil {
+			return err
+		}
+	}
+
+	for _, database := range manifest.Databases {
+		if err := restoreDatabase(ctx, databasePath(dir, database.Name), byName[database.Name], database); err != nil {
+			return fmt.Errorf("restore database %s: %w", database.Name, err)
+		}
+	}
+	return nil
+}
+
+func requireEmpty(ctx context.Context, target *gorm.DB, database Database, older bool) error {
+	for _, table := range sortedTables(database.Tables) {
+		if !target.Migrator().HasTable(table) {
+			if older {
+				continue
+			}
+			return fmt.Errorf("database %s has no table %s; run migrations before restoring", database.Name, table)
+		}
+		var count int64
+		if err := target.WithContext(ctx).Table(table).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("database %s is not empty (table %s has %d rows); restore needs a fresh cluster", database.Name, table, count)
+		}
+	}
+	return nil
+}
+
+func restoreDatabase(ctx context.Context, file string, target *gorm.DB, database Database) error {
+	source, err := openSQLite(file, true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = source.Close() }()
+
+	order, err := restoreOrder(source, sortedTables(database.Tables))
+	if err != nil {
+		return err
+	}
+	for _, table := range order {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !target.Migrator().HasTable(table) {
+			// Restore checked up front that only an older backup gets here.
+			log.Warn("restore skipped a table this build no longer has", "database", database.Name, "table", table, "rows", database.Tables[table])
+			continue
+		}
+		if err := copyTable(ctx, source, target, table); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+		var count int64
+		if err := target.WithContext(ctx).Table(table).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != database.Tables[table] {
+			return fmt.Errorf("table %s has %d rows after restore but the manifest records %d", table, count, database.Tables[table])
+		}
+	}
+	return nil
+}
+
+func copyTable(ctx context.Context, source *sql.DB, target *gorm.DB, table string) error {
+	rows, err := source.QueryContext(ctx, "SELECT * FROM "+quoteIdent(table))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	// Columns an older backup holds but this build dropped are left behind;
+	// columns it lacks take their defaults, and Migrate backfills them.
+	targetColumns, err := target.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+	kept := make(map[string]bool, len(targetColumns))
+	for _, column := range targetColumns {
+		kept[column.Name()] = true
+	}
+	var (
+		quoted  []string
+		indexes []int
+	)
+	for idx, column := range columns {
+		if !kept[column] {
+			continue
+		}
+		quoted = append(quoted, quoteIdent(column))
+		indexes = append(indexes, idx)
+	}
+	insert := "INSERT INTO " + quoteIdent(table) + " (" + strings.Join(quoted, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(quoted)), ", ") + ")"
+
+	batch := make([][]any, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := target.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, values := range batch {
+				if err := tx.Exec(insert, values...).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		batch = batch[:0]
+		return err
+	}
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for idx := range values {
+			pointers[idx] = &values[idx]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		row := make([]any, len(indexes))
+		for idx, column := range indexes {
+			row[idx] = values[column]
+		}
+		batch = append(batch, row)
+		if len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// restoreOrder sorts tables so each comes after the tables its foreign keys
+// reference. Tables caught in a reference cycle keep their name order at the
+// end.
+func restoreOrder(source *sql.DB, tables []string) ([]string, error) {
+	present := make(map[string]struct{}, len(tables))
+	for _, table := range tables {
+		present[table] = struct{}{}
+	}
+	parents := make(map[string]map[string]struct{}, len(tables))
+	for _, table := range tables {
+		refs, err := referencedTables(source, table)
+		if err != nil {
+			return nil, err
+		}
+		parents[table] = make(map[string]struct{})
+		for _, ref := range refs {
+			if _, ok := present[ref]; ok && ref != table {
+				parents[table][ref] = struct{}{}
+			}
+		}
+	}
+
+	order := make([]string, 0, len(tables))
+	placed := make(map[string]struct{}, len(tables))
+	for len(order) < len(tables) {
+		progressed := false
+		for _, table := range tables {
+			if _, ok := placed[table]; ok {
+				continue
+			}
+			ready := true
+			for parent := range parents[table] {
+				if _, ok := placed[parent]; !ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, table)
+				placed[table] = struct{}{}
+				progressed = true
+			}
+		}
+		if !progressed {
+			for _, table := range tables {
+				if _, ok := placed[table]; !ok {
+					order = append(order, table)
+					placed[table] = struct{}{}
+				}
+			}
+		}
+	}
+	return order, nil
+}
+
+func referencedTables(conn *sql.DB, table string) ([]string, error) {
+	rows, err := conn.Query("SELECT DISTINCT \"table\" FROM pragma_foreign_key_list(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+func sortedTables(tables map[string]int64) []string {
+	names := make([]string, 0, len(tables))
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/pkg/log"
+)
+
+const (
+	archivePrefix     = "caesium-backup-"
+	archiveSuffix     = ".tar.gz"
+	archiveTimeFormat = "20060102T150405Z"
+)
+
+// SchedulerConfig configures the leader-gated backup loop.
+type SchedulerConfig struct {
+	// Dir receives one archive per pass.
+	Dir      string
+	Interval time.Duration
+	// Keep is how many archives to keep in Dir; older ones are deleted after
+	// each successful pass. Zero keeps every archive.
+	Keep        int
+	Backup      func(context.Context, io.Writer) (*Manifest, error)
+	LeaderCheck func(context.Context) (bool, error)
+	Now         func() time.Time
+}
+
+// Scheduler writes a backup archive to a directory on a fixed interval.
+type Scheduler struct {
+	dir         string
+	interval    time.Duration
+	keep        int
+	backup      func(context.Context, io.Writer) (*Manifest, error)
+	leaderCheck func(context.Context) (bool, error)
+	now         func() time.Time
+}
+
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	if cfg.Backup == nil {
+		panic("backup scheduler requires a backup function")
+	}
+	if cfg.Interval <= 0 {
+		panic("backup scheduler requires a positive interval")
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Scheduler{
+		dir:         cfg.Dir,
+		interval:    cfg.Interval,
+		keep:        cfg.Keep,
+		backup:      cfg.Backup,
+		leaderCheck: cfg.LeaderCheck,
+		now:         now,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.BackupOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Error("scheduled backup failed", "dir", s.dir, "error", err)
+			}
+		}
+	}
+}
+
+// BackupOnce writes one archive into the directory and prunes old ones. It
+// returns the archive's path, or "" when this node is not the leader.
+func (s *Scheduler) BackupOnce(ctx context.Context) (string, error) {
+	if s.leaderCheck != nil {
+		leader, err := s.leaderCheck(ctx)
+		if err != nil {
+			return "", err
+		}
+		if !leader {
+			return "", nil
+		}
+	}
+
+	name, err := s.write(ctx)
+	if err != nil {
+		metrics.BackupsTotal.WithLabelValues("failure").Inc()
+		return "", err
+	}
+	metrics.BackupsTotal.WithLabelValues("success").Inc()
+	metrics.BackupLastSuccessTimestamp.SetToCurrentTime()
+	log.Info("scheduled backup written", "path", name)
+
+	if err := s.prune(); err != nil {
+		log.Warn("failed to prune old backups", "dir", s.dir, "error", err)
+	}
+	return name, nil
+}
+
+// write streams the archive into a temporary file and renames it into place,
+// so the directory never holds a partial archive under a final name.
+func (s *Scheduler) write(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", err
+	}
+	name := filepath.Join(s.dir, archivePrefix+s.now().UTC().Format(archiveTimeFormat)+archiveSuffix)
+
+	tmp, err := os.CreateTemp(s.dir, ".caesium-backup-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := s.backup(ctx, tmp); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", fmt.Errorf("move backup into place: %w", err)
+	}
+	return name, nil
+}
+
+func (s *Scheduler) prune() error {
+	if s.keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	var archives []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, archivePrefix) && strings.HasSuffix(name, archiveSuffix) {
+			archives = append(archives, name)
+		}
+	}
+	if len(archives) <= s.keep {
+		return nil
+	}
+	// Timestamps in the names sort chronologically.
+	sort.Strings(archives)
+	for _, name := range archives[:len(archives)-s.keep] {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
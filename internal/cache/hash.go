@@ -89,6 +89,7 @@ type HashInputBlob struct {
 	PredecessorHashes    []string                     `json:"predecessorHashes,omitempty"`
 	PredecessorOutputs   map[string]map[string]string `json:"predecessorOutputs,omitempty"`
 	RunParams            map[string]string            `json:"runParams,omitempty"`
+	MapItem              *string                      `json:"mapItem,omitempty"`
 	CacheVersion         int                          `json:"cacheVersion"`
 
 	// Oversized is set (with Digest/EnvCount/PredecessorOutputCount populated
@@ -172,6 +173,7 @@ func (h HashInput) CanonicalJSON(precomputed string) ([]byte, error) {
 		PredecessorHashes:    sortedCopy(h.PredecessorHashes),
 		PredecessorOutputs:   h.PredecessorOutputs,
 		RunParams:            h.RunParams,
+		MapItem:              h.MapItem,
 		CacheVersion:         h.CacheVersion,
 	}
 
@@ -191,6 +193,7 @@ func (h HashInput) CanonicalJSON(precomputed string) ([]byte, error) {
 		TaskName:            h.TaskName,
 		Image:               h.Image,
 		ResolvedImageDigest: h.ResolvedImageDigest,
+		MapItem:             h.MapItem,
 		CacheVersion:        h.CacheVersion,
 		Oversized: &oversizedBlob{
 			EnvCount:               len(h.Env),
@@ -263,6 +266,8 @@ func sortedVolumeMounts(mounts []container.VolumeMount) []container.VolumeMount
 // HashInput contains all fields that contribute to a task's identity hash.
 // Control-plane flags such as replaySafe are deliberately absent: they gate
 // orchestration decisions but are not execution inputs and must not bust cache.
+// Step resources (cpu, memory, ephemeralStorage, gpu-count) are likewise absent:
+// like the Kueue queue name they size the container, not what it computes.
 type HashInput struct {
 	JobAlias string
 	TaskName string
@@ -283,7 +288,11 @@ type HashInput struct {
 	PredecessorHashes    []string
 	PredecessorOutputs   map[string]map[string]string
 	RunParams            map[string]string
-	CacheVersion         int
+	// MapItem is the item a mapped-step instance runs for; nil for every
+	// unmapped task so their keys are unchanged. It is hashed as its own field
+	// rather than through Env, which excludes the volatile injected variables.
+	MapItem      *string
+	CacheVersion int
 }
 
 // Compute returns the SHA-256 hex digest of the canonicalized input.
@@ -393,6 +402,10 @@ func (h HashInput) Compute() string {
 		w(digest, "param:%s=%s\n", k, h.RunParams[k])
 	}
 
+	if h.MapItem != nil {
+		w(digest, "map_item:%s\n", *h.MapItem)
+	}
+
 	w(digest, "cache_version:%d\n", h.CacheVersion)
 
 	return hex.EncodeToString(digest.Sum(nil))
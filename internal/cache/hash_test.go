@@ -167,6 +167,29 @@ func TestCompute_DifferentCacheVersion(t *testing.T) {
 	assert.NotEqual(t, a.Compute(), b.Compute())
 }
 
+func TestCompute_MapItem(t *testing.T) {
+	plain := baseInput().Compute()
+
+	a, b, empty := "2026-10-01", "2026-10-02", ""
+	withA := baseInput()
+	withA.MapItem = &a
+	withB := baseInput()
+	withB.MapItem = &b
+	withEmpty := baseInput()
+	withEmpty.MapItem = &empty
+
+	assert.NotEqual(t, plain, withA.Compute(), "a mapped instance must not share the unmapped identity")
+	assert.NotEqual(t, withA.Compute(), withB.Compute(), "different items should produce different hashes")
+	assert.NotEqual(t, plain, withEmpty.Compute(), "an empty item is still an item")
+
+	blob, err := withA.CanonicalJSON(withA.Compute())
+	require.NoError(t, err)
+	var decoded HashInputBlob
+	require.NoError(t, json.Unmarshal(blob, &decoded))
+	require.NotNil(t, decoded.MapItem)
+	assert.Equal(t, a, *decoded.MapItem)
+}
+
 func TestCompute_EnvOrderDoesNotMatter(t *testing.T) {
 	a := HashInput{
 		Env: map[string]string{"A": "1", "B": "2", "C": "3"},
@@ -679,6 +702,87 @@ steps:
 		"dataset schema declarations are apply-time metadata and must not change the cache hash")
 }
 
+// TestCompute_StepResourcesExcluded asserts step and job-level resources are
+// scheduling metadata: resizing a step (or declaring resources at all) must not
+// change its cache identity.
+func TestCompute_StepResourcesExcluded(t *testing.T) {
+	const base = `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: sized
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: train
+    image: alpine:3.23
+    command: ["train"]
+`
+	const withResources = `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: sized
+  resources: {cpu: "2", memory: 4Gi}
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: train
+    image: alpine:3.23
+    command: ["train"]
+    resources: {memory: 16Gi, ephemeralStorage: 50Gi, gpu-count: 1}
+`
+
+	assert.Equal(t, taskHashFromDefinition(t, base), taskHashFromDefinition(t, withResources),
+		"resources are scheduling metadata and must not change the cache hash")
+}
+
+func TestCompute_StepTemplateMatchesInlineStep(t *testing.T) {
+	const inline = `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: analytics
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: models
+    image: ghcr.io/acme/dbt:1.7
+    command: ["dbt", "run", "--target", "PROD"]
+    env: {DBT_THREADS: "8"}
+`
+	const templated = `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt-run, version: "2"}
+parameters:
+  - {name: target, type: string, default: dev}
+  - {name: threads, type: integer, default: 4}
+spec:
+  image: ghcr.io/acme/dbt:1.7
+  command: ["dbt", "run", "--target", "{{ .target | upper }}"]
+  env: {DBT_THREADS: "{{ .threads }}"}
+---
+apiVersion: v1
+kind: Job
+metadata:
+  alias: analytics
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: models
+    uses: dbt-run@2
+    with: {target: prod, threads: 8}
+`
+
+	assert.Equal(t, taskHashFromDefinition(t, inline), taskHashFromDefinition(t, templated),
+		"a templated step must hash identically to the equivalent inline step")
+}
+
 func taskHashFromDefinition(t *testing.T, src string) string {
 	t.Helper()
 
@@ -0,0 +1,139 @@
+// Package cordon records which worker nodes an operator has taken out of
+// scheduling, so a node can be upgraded without killing it and waiting for
+// its task leases to expire.
+//
+// A cordoned node stops claiming tasks and the run-owner dispatch loop stops
+// sending it work. A draining node is cordoned and also gives up its running
+// tasks: it waits for them to finish, and once the drain deadline passes it
+// releases their claims so another node picks them up at once.
+package cordon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// State is a node's scheduling state.
+type State string
+
+const (
+	StateActive   State = "active"
+	StateCordoned State = "cordoned"
+	StateDraining State = "draining"
+)
+
+// Schedulable reports whether a node in this state may take new tasks.
+func (s State) Schedulable() bool {
+	return s == "" || s == StateActive
+}
+
+// ErrAddressRequired is returned for an empty node address.
+var ErrAddressRequired = errors.New("node address is required")
+
+// Store reads and writes node_states in the catalog database.
+type Store struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewStore(db *gorm.DB) *Store {
+	if db == nil {
+		panic("cordon store requires a database")
+	}
+	return &Store{db: db, now: time.Now}
+}
+
+// Cordon stops address from taking new tasks. Cordoning a draining node
+// keeps its running tasks and cancels the drain deadline.
+func (s *Store) Cordon(ctx context.Context, address, actor string) (*models.NodeState, error) {
+	return s.set(ctx, address, StateCordoned, actor, nil)
+}
+
+// Drain cordons address and hands its running tasks back: it waits for them
+// to finish and, once timeout has passed, releases the claims of any still
+// running. A zero timeout waits for them indefinitely.
+func (s *Store) Drain(ctx context.Context, address, actor string, timeout time.Duration) (*models.NodeState, error) {
+	var deadline *time.Time
+	if timeout > 0 {
+		at := s.now().UTC().Add(timeout)
+		deadline = &at
+	}
+	return s.set(ctx, address, StateDraining, actor, deadline)
+}
+
+// Uncordon makes address schedulable again.
+func (s *Store) Uncordon(ctx context.Context, address string) error {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return ErrAddressRequired
+	}
+	return s.db.WithContext(ctx).Where("address = ?", address).Delete(&models.NodeState{}).Error
+}
+
+// Get returns address's state. A node without a row is active.
+func (s *Store) Get(ctx context.Context, address string) (models.NodeState, error) {
+	var state models.NodeState
+	err := s.db.WithContext(ctx).Where("address = ?", strings.TrimSpace(address)).Limit(1).Find(&state).Error
+	if err != nil {
+		return models.NodeState{}, err
+	}
+	if state.Address == "" {
+		return models.NodeState{Address: address, State: string(StateActive)}, nil
+	}
+	return state, nil
+}
+
+// List returns every node that is not active, keyed by address.
+func (s *Store) List(ctx context.Context) (map[string]models.NodeState, error) {
+	var rows []models.NodeState
+	if err := s.db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	states := make(map[string]models.NodeState, len(rows))
+	for _, row := range rows {
+		states[row.Address] = row
+	}
+	return states, nil
+}
+
+// Unschedulable returns the addresses of every cordoned or draining node.
+// It satisfies the dispatch loop's node filter.
+func (s *Store) Unschedulable(ctx context.Context) (map[string]struct{}, error) {
+	var addresses []string
+	if err := s.db.WithContext(ctx).Model(&models.NodeState{}).Pluck("address", &addresses).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		out[address] = struct{}{}
+	}
+	return out, nil
+}
+
+func (s *Store) set(ctx context.Context, address string, state State, actor string, deadline *time.Time) (*models.NodeState, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, ErrAddressRequired
+	}
+	row := &models.NodeState{
+		Address:       address,
+		State:         string(state),
+		DrainDeadline: deadline,
+		UpdatedBy:     strings.TrimSpace(actor),
+		UpdatedAt:     s.now().UTC(),
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"state", "drain_deadline", "updated_by", "updated_at"}),
+	}).Create(row).Error
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
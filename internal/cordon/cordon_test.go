@@ -0,0 +1,78 @@
+package cordon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	require.NoError(t, db.AutoMigrate(&models.NodeState{}))
+	return NewStore(db)
+}
+
+func TestStoreCordonDrainUncordon(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	state, err := store.Get(ctx, "node-a:9001")
+	require.NoError(t, err)
+	require.Equal(t, string(StateActive), state.State, "a node without a row is active")
+
+	_, err = store.Cordon(ctx, " node-a:9001 ", "alice")
+	require.NoError(t, err)
+	state, err = store.Get(ctx, "node-a:9001")
+	require.NoError(t, err)
+	require.Equal(t, string(StateCordoned), state.State)
+	require.Equal(t, "alice", state.UpdatedBy)
+	require.Nil(t, state.DrainDeadline)
+	require.False(t, StateCordoned.Schedulable())
+
+	_, err = store.Drain(ctx, "node-a:9001", "bob", 10*time.Minute)
+	require.NoError(t, err)
+	state, err = store.Get(ctx, "node-a:9001")
+	require.NoError(t, err)
+	require.Equal(t, string(StateDraining), state.State)
+	require.NotNil(t, state.DrainDeadline)
+	require.True(t, state.DrainDeadline.Equal(now.Add(10*time.Minute)))
+
+	// Re-cordoning a draining node cancels its drain deadline.
+	_, err = store.Cordon(ctx, "node-a:9001", "alice")
+	require.NoError(t, err)
+	state, err = store.Get(ctx, "node-a:9001")
+	require.NoError(t, err)
+	require.Nil(t, state.DrainDeadline)
+
+	_, err = store.Drain(ctx, "node-b:9001", "bob", 0)
+	require.NoError(t, err)
+	unschedulable, err := store.Unschedulable(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"node-a:9001": {}, "node-b:9001": {}}, unschedulable)
+
+	require.NoError(t, store.Uncordon(ctx, "node-a:9001"))
+	states, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.Contains(t, states, "node-b:9001")
+	require.Nil(t, states["node-b:9001"].DrainDeadline, "a zero timeout waits for running tasks")
+}
+
+func TestStoreRejectsEmptyAddress(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	_, err := store.Cordon(ctx, " ", "alice")
+	require.ErrorIs(t, err, ErrAddressRequired)
+	_, err = store.Drain(ctx, "", "alice", time.Minute)
+	require.ErrorIs(t, err, ErrAddressRequired)
+	require.ErrorIs(t, store.Uncordon(ctx, ""), ErrAddressRequired)
+}
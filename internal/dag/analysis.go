@@ -32,10 +32,26 @@ func Analyze(def *jobdef.Definition) (*Analysis, error) {
 		return nil, err
 	}
 
+	names := make([]string, len(def.Steps))
+	for i, s := range def.Steps {
+		names[i] = s.Name
+	}
+	analysis := FromSuccessors(names, successors)
+	for i, s := range def.Steps {
+		analysis.Steps[i].Engine = s.Engine
+		analysis.Steps[i].Image = s.Image
+	}
+	return analysis, nil
+}
+
+// FromSuccessors computes the topology of a DAG given as step names, in
+// display order, and each step's successors. It serves DAGs that come from a
+// server rather than a definition, such as a persisted job's task graph.
+func FromSuccessors(names []string, successors map[string][]string) *Analysis {
 	// Build predecessor map from successors.
-	predecessors := make(map[string][]string, len(def.Steps))
-	for _, s := range def.Steps {
-		predecessors[s.Name] = nil
+	predecessors := make(map[string][]string, len(names))
+	for _, name := range names {
+		predecessors[name] = nil
 	}
 	for from, succs := range successors {
 		for _, to := range succs {
@@ -44,19 +60,19 @@ func Analyze(def *jobdef.Definition) (*Analysis, error) {
 	}
 
 	// BFS layer decomposition (Kahn's algorithm variant).
-	inDegree := make(map[string]int, len(def.Steps))
-	for _, s := range def.Steps {
-		inDegree[s.Name] = len(predecessors[s.Name])
+	inDegree := make(map[string]int, len(names))
+	for _, name := range names {
+		inDegree[name] = len(predecessors[name])
 	}
 
 	var roots []string
-	for _, s := range def.Steps {
-		if inDegree[s.Name] == 0 {
-			roots = append(roots, s.Name)
+	for _, name := range names {
+		if inDegree[name] == 0 {
+			roots = append(roots, name)
 		}
 	}
 
-	depth := make(map[string]int, len(def.Steps))
+	depth := make(map[string]int, len(names))
 	var layers [][]string
 	queue := make([]string, len(roots))
 	copy(queue, roots)
@@ -85,22 +101,20 @@ func Analyze(def *jobdef.Definition) (*Analysis, error) {
 
 	// Leaf steps: no successors.
 	var leaves []string
-	for _, s := range def.Steps {
-		if len(successors[s.Name]) == 0 {
-			leaves = append(leaves, s.Name)
+	for _, name := range names {
+		if len(successors[name]) == 0 {
+			leaves = append(leaves, name)
 		}
 	}
 
-	// Build StepInfo slice preserving definition order.
-	steps := make([]StepInfo, len(def.Steps))
-	for i, s := range def.Steps {
+	// Build StepInfo slice preserving the given order.
+	steps := make([]StepInfo, len(names))
+	for i, name := range names {
 		steps[i] = StepInfo{
-			Name:       s.Name,
-			Engine:     s.Engine,
-			Image:      s.Image,
-			DependsOn:  predecessors[s.Name],
-			Successors: successors[s.Name],
-			Depth:      depth[s.Name],
+			Name:       name,
+			DependsOn:  predecessors[name],
+			Successors: successors[name],
+			Depth:      depth[name],
 		}
 	}
 
@@ -110,14 +124,18 @@ func Analyze(def *jobdef.Definition) (*Analysis, error) {
 		MaxParallelism: maxParallel,
 		RootSteps:      roots,
 		LeafSteps:      leaves,
-	}, nil
+	}
 }
 
-// UniqueImages returns the deduplicated set of container images in definition order.
+// UniqueImages returns the deduplicated set of container images in definition
+// order. Sensor steps run no container and contribute none.
 func UniqueImages(def *jobdef.Definition) []string {
 	seen := make(map[string]struct{}, len(def.Steps))
 	var images []string
 	for _, s := range def.Steps {
+		if s.Image == "" {
+			continue
+		}
 		if _, ok := seen[s.Image]; ok {
 			continue
 		}
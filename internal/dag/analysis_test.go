@@ -82,3 +82,27 @@ func TestUniqueImages(t *testing.T) {
 		t.Errorf("UniqueImages = %v, want [alpine:3.23 python:3.12]", images)
 	}
 }
+
+func TestFromSuccessorsDiamond(t *testing.T) {
+	a := FromSuccessors([]string{"a", "b", "c", "d"}, map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"d"},
+	})
+
+	if a.MaxParallelism != 2 {
+		t.Errorf("MaxParallelism = %d, want 2", a.MaxParallelism)
+	}
+	if len(a.ExecutionOrder) != 3 {
+		t.Errorf("ExecutionOrder layers = %d, want 3", len(a.ExecutionOrder))
+	}
+	if len(a.RootSteps) != 1 || a.RootSteps[0] != "a" {
+		t.Errorf("RootSteps = %v, want [a]", a.RootSteps)
+	}
+	if len(a.LeafSteps) != 1 || a.LeafSteps[0] != "d" {
+		t.Errorf("LeafSteps = %v, want [d]", a.LeafSteps)
+	}
+	if a.Steps[3].Engine != "" || len(a.Steps[3].DependsOn) != 2 {
+		t.Errorf("Steps[3] = %+v, want two dependencies and no engine", a.Steps[3])
+	}
+}
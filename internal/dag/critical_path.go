@@ -0,0 +1,86 @@
+package dag
+
+import (
+	"sort"
+	"time"
+)
+
+// CriticalPath returns the longest duration-weighted path through a DAG and
+// the nodes along it in execution order. successors maps each node to its
+// direct successors and weights gives each node's duration; a node present in
+// either map is part of the graph, and a node missing from weights weighs
+// zero. successors must be acyclic. Ties resolve to the lexically smallest
+// node so the result is deterministic.
+func CriticalPath(successors map[string][]string, weights map[string]time.Duration) (time.Duration, []string) {
+	nodes := make(map[string]struct{}, len(weights))
+	for name, succs := range successors {
+		nodes[name] = struct{}{}
+		for _, succ := range succs {
+			nodes[succ] = struct{}{}
+		}
+	}
+	for name := range weights {
+		nodes[name] = struct{}{}
+	}
+	if len(nodes) == 0 {
+		return 0, nil
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// longest[n] is the heaviest path starting at n; next[n] is the successor
+	// that path continues through.
+	longest := make(map[string]time.Duration, len(names))
+	next := make(map[string]string, len(names))
+	visiting := make(map[string]bool, len(names))
+
+	var visit func(string) time.Duration
+	visit = func(name string) time.Duration {
+		if total, ok := longest[name]; ok {
+			return total
+		}
+		if visiting[name] {
+			// Defensive: a cycle contributes nothing rather than recursing
+			// forever. Validated job definitions never contain one.
+			return 0
+		}
+		visiting[name] = true
+
+		succs := append([]string(nil), successors[name]...)
+		sort.Strings(succs)
+		var best time.Duration
+		bestSucc := ""
+		for _, succ := range succs {
+			if total := visit(succ); bestSucc == "" || total > best {
+				best, bestSucc = total, succ
+			}
+		}
+
+		visiting[name] = false
+		longest[name] = weights[name] + best
+		if bestSucc != "" {
+			next[name] = bestSucc
+		}
+		return longest[name]
+	}
+
+	var (
+		total time.Duration
+		start string
+	)
+	for _, name := range names {
+		if path := visit(name); start == "" || path > total {
+			total, start = path, name
+		}
+	}
+
+	path := []string{start}
+	for cur := start; next[cur] != ""; cur = next[cur] {
+		path = append(path, next[cur])
+	}
+	return total, path
+}
@@ -0,0 +1,40 @@
+package dag
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCriticalPath(t *testing.T) {
+	successors := map[string][]string{
+		"start": {"a", "b"},
+		"a":     {"join"},
+		"b":     {"join"},
+	}
+	weights := map[string]time.Duration{
+		"start": time.Minute,
+		"a":     2 * time.Minute,
+		"b":     5 * time.Minute,
+		"join":  time.Minute,
+	}
+
+	total, path := CriticalPath(successors, weights)
+	if total != 7*time.Minute {
+		t.Errorf("total = %s, want 7m", total)
+	}
+	want := []string{"start", "b", "join"}
+	if strings.Join(path, ",") != strings.Join(want, ",") {
+		t.Errorf("path = %v, want %v", path, want)
+	}
+
+	// Disconnected nodes and nodes without weights still participate.
+	total, path = CriticalPath(map[string][]string{"x": {"y"}}, map[string]time.Duration{"y": time.Second, "z": 3 * time.Second})
+	if total != 3*time.Second || len(path) != 1 || path[0] != "z" {
+		t.Errorf("CriticalPath = (%s, %v), want (3s, [z])", total, path)
+	}
+
+	if total, path := CriticalPath(nil, nil); total != 0 || path != nil {
+		t.Errorf("empty graph = (%s, %v), want (0, nil)", total, path)
+	}
+}
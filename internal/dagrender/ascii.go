@@ -13,6 +13,13 @@ const connGap = 6 // horizontal gap between layer columns for connectors
 
 // Render writes an ASCII DAG visualization to the writer.
 func Render(analysis *dag.Analysis, w io.Writer) error {
+	return RenderLabeled(analysis, w, func(name string) string { return name })
+}
+
+// RenderLabeled writes the DAG with each step's box showing label(step)
+// instead of the bare step name, e.g. to annotate steps with run status.
+// Labels must be ASCII.
+func RenderLabeled(analysis *dag.Analysis, w io.Writer, label func(step string) string) error {
 	if len(analysis.Steps) == 0 || len(analysis.ExecutionOrder) == 0 {
 		_, err := fmt.Fprintln(w, "(empty DAG)")
 		return err
@@ -20,11 +27,11 @@ func Render(analysis *dag.Analysis, w io.Writer) error {
 
 	layers := analysis.ExecutionOrder
 
-	// Per-layer box content width (widest step name + 2 padding).
+	// Per-layer box content width (widest label + 2 padding).
 	layerWidths := make([]int, len(layers))
 	for i, layer := range layers {
 		for _, name := range layer {
-			if w := len(name) + 2; w > layerWidths[i] {
+			if w := len(label(name)) + 2; w > layerWidths[i] {
 				layerWidths[i] = w
 			}
 		}
@@ -74,7 +81,7 @@ func Render(analysis *dag.Analysis, w io.Writer) error {
 	for i, layer := range layers {
 		for j, name := range layer {
 			row := rowOffset[i] + j
-			c.writeBox(layerX[i], rowToY(row), name, layerWidths[i])
+			c.writeBox(layerX[i], rowToY(row), label(name), layerWidths[i])
 		}
 	}
 
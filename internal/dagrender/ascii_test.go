@@ -115,3 +115,25 @@ func TestRenderEmpty(t *testing.T) {
 		t.Errorf("expected empty message, got:\n%s", buf.String())
 	}
 }
+
+func TestRenderLabeledUsesLabels(t *testing.T) {
+	a := &dag.Analysis{
+		Steps: []dag.StepInfo{
+			{Name: "extract", Successors: []string{"load"}},
+			{Name: "load"},
+		},
+		ExecutionOrder: [][]string{{"extract"}, {"load"}},
+		MaxParallelism: 1,
+	}
+
+	var buf bytes.Buffer
+	err := RenderLabeled(a, &buf, func(step string) string { return step + " (running)" })
+	if err != nil {
+		t.Fatalf("RenderLabeled: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "│ extract (running) │") || !strings.Contains(out, "│ load (running) │") {
+		t.Errorf("expected labelled boxes in output, got:\n%s", out)
+	}
+}
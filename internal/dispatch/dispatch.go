@@ -31,9 +31,12 @@ import (
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -168,6 +171,9 @@ type InboundDispatch struct {
 	OwnerGeneration int64
 	Attempt         int
 	WorkerNode      string
+	// TraceParent is the W3C traceparent of the span that accepted the
+	// dispatch; the worker executes the task as its child.
+	TraceParent string
 }
 
 // WorkerSubmitter is the seam the dispatch handler uses to hand an accepted
@@ -359,13 +365,31 @@ func (h *Handler) HandleDispatch(w http.ResponseWriter, r *http.Request) {
 	// worker is not running.  On failure we MUST NOT leave the task
 	// claimed-but-orphaned — roll the claim back to pending so the owner's next
 	// dispatch tick re-dispatches it (here or to a peer), and reject with 409.
+	//
+	// The owner's task.dispatch span arrives in the request headers; a peer
+	// that sent none still joins the run's trace through the task row.
+	spanCtx, span := tracing.StartFrom(
+		tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header)),
+		taskRun.TraceParent,
+		"task.dispatch.accept",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			tracing.AttrRunID.String(req.RunID.String()),
+			tracing.AttrTaskID.String(req.TaskID.String()),
+			tracing.AttrAttempt.Int(req.Attempt),
+			tracing.AttrNode.String(h.nodeID),
+		),
+	)
+	defer span.End()
 	if submitErr := h.submitter.SubmitDispatched(InboundDispatch{
 		Task:            taskRun,
 		OwnerBaseURL:    req.OwnerBaseURL,
 		OwnerGeneration: req.OwnerGeneration,
 		Attempt:         req.Attempt,
 		WorkerNode:      h.nodeID,
+		TraceParent:     tracing.Traceparent(spanCtx),
 	}); submitErr != nil {
+		tracing.Fail(span, submitErr)
 		h.rollbackClaim(req)
 		log.Warn("dispatch: worker could not accept task; rolled back claim",
 			"run_id", req.RunID,
@@ -660,6 +684,7 @@ func PostDispatch(ctx context.Context, targetURL, token string, req DispatchRequ
 	}
 	httpReq.Header.Set("Authorization", "Bearer "+token)
 	httpReq.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
 
 	resp, err := internalClient.Do(httpReq)
 	if err != nil {
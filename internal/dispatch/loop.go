@@ -9,7 +9,9 @@
 //     no per-run goroutines are spawned.
 //   - Round-robin peer selection for Phase A2: least-loaded requires a
 //     worker-status RPC that doesn't exist yet.  The local node is included in
-//     the rotation so single-node setups work.
+//     the rotation so single-node setups work.  Tasks with a nodeSelector or
+//     nodeAffinity rotate only among the peers that satisfy it (see
+//     peerPicker).
 //   - On PostDispatch returns false (network error or 409): leave the task
 //     untouched (claimed_by="", status=pending) so ClaimNext recovery picks it up.
 //   - Batch cap (CAESIUM_RUN_OWNER_DISPATCH_BATCH, default 64): prevents a huge
@@ -31,10 +33,14 @@ import (
 
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/placement"
 	"github.com/caesium-cloud/caesium/internal/ratelimit"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -44,6 +50,8 @@ const (
 	DispatchReasonWorkerRejected     = "worker_rejected"
 	DispatchReasonNoPeers            = "no_peers"             // peer discovery returned empty list (bootstrap)
 	DispatchReasonPeerDiscoveryError = "peer_discovery_error" // peer discovery RPC failed
+	DispatchReasonAllCordoned        = "all_cordoned"         // every peer is cordoned or draining
+	DispatchReasonNoEligiblePeer     = "no_eligible_peer"     // no peer satisfies the task's nodeSelector/nodeAffinity
 )
 
 // PeerLister provides the current set of dispatch-eligible peer node addresses.
@@ -84,6 +92,12 @@ type peer struct {
 	baseURL string
 }
 
+// NodeFilter reports the nodes an operator has cordoned or drained.  The
+// production implementation is cordon.Store.
+type NodeFilter interface {
+	Unschedulable(ctx context.Context) (map[string]struct{}, error)
+}
+
 // TaskPendingReader provides pending-task queries used by the dispatch loop.
 type TaskPendingReader interface {
 	PendingTasksForDispatch(ctx context.Context, runID uuid.UUID, limit int) ([]models.TaskRun, error)
@@ -141,6 +155,13 @@ type DispatchLoopConfig struct {
 	// mux server. Production leaves it nil and the loop falls back to the
 	// default (build URL from APIPort).
 	PeerBaseURL func(nodeAddr string) string
+	// Nodes, when set, excludes cordoned and draining peers (self included)
+	// from the rotation.  Nil dispatches to every peer.
+	Nodes NodeFilter
+	// Placement, when set, restricts each task to peers whose registered
+	// labels satisfy its nodeSelector and nodeAffinity, and ranks them by
+	// preferred terms and spread.  Nil dispatches every task round-robin.
+	Placement Placement
 	// OwnerManager, when set (CAESIUM_RUN_OWNER_IN_MEMORY=true), is the source of
 	// truth for ready tasks: the loop dispatches from the in-memory ready queue
 	// and records dispatches/recoveries on it, instead of polling the DB for
@@ -269,6 +290,14 @@ func (l *DispatchLoop) tick(ctx context.Context) {
 	// still lingers in dqlite membership.  Falls back to the full list if every
 	// peer is benched, so a cluster-wide blip never starves dispatch entirely.
 	peers = l.healthyPeers(peers)
+	// Drop cordoned and draining peers.  Unlike benching there is no fallback:
+	// when every node is cordoned, tasks stay pending until one is uncordoned.
+	peers = l.schedulablePeers(ctx, peers)
+	if len(peers) == 0 {
+		// This run-set-wide control-plane metric has no per-task quarantine context.
+		metrics.DispatchRejectedTotal.WithLabelValues(DispatchReasonAllCordoned).Inc()
+		return
+	}
 
 	// 2. Find runs this node owns AND their current generation in one query
 	//    (avoids the N+1 GetLease pattern as the owned set grows).
@@ -327,6 +356,7 @@ func (l *DispatchLoop) dispatchRun(ctx context.Context, runID uuid.UUID, generat
 	}
 	sem := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
+	picker := l.newPeerPicker(runID, peers)
 
 	for i := range tasks {
 		if ctx.Err() != nil {
@@ -334,10 +364,13 @@ func (l *DispatchLoop) dispatchRun(ctx context.Context, runID uuid.UUID, generat
 		}
 		task := &tasks[i]
 
-		// Pick a peer via round-robin (atomic counter is per-loop, so per-task
-		// dispatch rotation is monotonic across runs and ticks).
-		idx := l.counter.Add(1) - 1
-		p := peers[idx%uint64(len(peers))]
+		// Pick a peer that satisfies the task's placement constraints; the
+		// round-robin counter is per-loop, so rotation among equally good
+		// peers is monotonic across runs and ticks.
+		p, ok := picker.pick(ctx, placement.SpecFor(task))
+		if !ok {
+			continue
+		}
 
 		req := DispatchRequest{
 			RunID:           runID,
@@ -362,7 +395,7 @@ func (l *DispatchLoop) dispatchRun(ctx context.Context, runID uuid.UUID, generat
 			if ok := l.acquireRateLimit(ctx, runID, req.TaskID); !ok {
 				return
 			}
-			l.postOne(ctx, runID, p, req, task.Quarantine)
+			l.postOne(ctx, runID, p, req, task.TraceParent, task.Quarantine)
 		}(p, req)
 	}
 	wg.Wait()
@@ -411,13 +444,20 @@ func (l *DispatchLoop) dispatchRunInMemory(ctx context.Context, runID uuid.UUID,
 	}
 	sem := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
+	picker := l.newPeerPicker(runID, peers)
 
 	for _, dt := range ready {
 		if ctx.Err() != nil {
 			break
 		}
-		idx := l.counter.Add(1) - 1
-		p := peers[idx%uint64(len(peers))]
+		spec, ok := l.taskSpec(ctx, runID, dt.TaskID)
+		if !ok {
+			continue
+		}
+		p, ok := picker.pick(ctx, spec)
+		if !ok {
+			continue
+		}
 		req := DispatchRequest{
 			RunID:           runID,
 			TaskID:          dt.TaskID,
@@ -435,7 +475,7 @@ func (l *DispatchLoop) dispatchRunInMemory(ctx context.Context, runID uuid.UUID,
 			if ok := l.acquireRateLimit(ctx, runID, req.TaskID); !ok {
 				return
 			}
-			l.postOne(ctx, runID, p, req, false)
+			l.postOne(ctx, runID, p, req, "", false)
 		}(p, req)
 	}
 	wg.Wait()
@@ -523,9 +563,26 @@ func (l *DispatchLoop) rateLimitDelayed(runID, taskID uuid.UUID, now time.Time)
 }
 
 // postOne does the actual HTTP call + metric/log accounting for one dispatch.
-func (l *DispatchLoop) postOne(ctx context.Context, runID uuid.UUID, p peer, req DispatchRequest, quarantined bool) {
+// traceparent is the task row's trace context; when it is known the post is
+// traced as a task.dispatch span, otherwise the worker joins the trace from
+// the task row it loads.
+func (l *DispatchLoop) postOne(ctx context.Context, runID uuid.UUID, p peer, req DispatchRequest, traceparent string, quarantined bool) {
+	span := trace.SpanFromContext(ctx)
+	if traceparent != "" {
+		ctx, span = tracing.StartFrom(ctx, traceparent, "task.dispatch",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				tracing.AttrRunID.String(runID.String()),
+				tracing.AttrTaskID.String(req.TaskID.String()),
+				tracing.AttrAttempt.Int(req.Attempt),
+				tracing.AttrNode.String(p.nodeID),
+			),
+		)
+		defer span.End()
+	}
 	dispatchURL := p.baseURL + "/internal/dispatch"
 	accepted, postErr := PostDispatch(ctx, dispatchURL, l.cfg.Token, req)
+	tracing.Fail(span, postErr)
 	if postErr != nil {
 		if ctx.Err() != nil {
 			return
@@ -545,6 +602,7 @@ func (l *DispatchLoop) postOne(ctx context.Context, runID uuid.UUID, p peer, req
 		}
 		return
 	}
+	span.SetAttributes(attribute.Bool("caesium.task.dispatch_accepted", accepted))
 	if !accepted {
 		log.Warn("dispatch loop: worker rejected dispatch",
 			"run_id", runID,
@@ -621,6 +679,33 @@ func (l *DispatchLoop) healthyPeers(peers []peer) []peer {
 	return out
 }
 
+// schedulablePeers returns peers that are neither cordoned nor draining.  A
+// failed lookup keeps the full list: a cordoned worker still refuses the
+// dispatch itself, so the owner falls back to its 409 handling.
+func (l *DispatchLoop) schedulablePeers(ctx context.Context, peers []peer) []peer {
+	if l.cfg.Nodes == nil {
+		return peers
+	}
+	unschedulable, err := l.cfg.Nodes.Unschedulable(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warn("dispatch loop: cordoned node lookup failed", "error", err)
+		}
+		return peers
+	}
+	if len(unschedulable) == 0 {
+		return peers
+	}
+	out := make([]peer, 0, len(peers))
+	for _, p := range peers {
+		if _, cordoned := unschedulable[p.nodeID]; cordoned {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
 // buildPeers normalises raw peer addresses (host:dqlitePort) into peer pairs
 // of {nodeID = host:dqlitePort, baseURL = http://host:apiPort}.  Self is
 // always appended at the end so the round-robin always has at least one target.
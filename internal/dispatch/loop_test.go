@@ -3,6 +3,7 @@ package dispatch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -15,7 +16,9 @@ import (
 	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/placement"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -552,6 +555,125 @@ func TestDispatchLoop_PeerCircuitBreaker(t *testing.T) {
 	require.Len(t, loop.healthyPeers(ext), 2, "all-benched falls back to the full list")
 }
 
+type testNodeFilter struct {
+	nodes map[string]struct{}
+	err   error
+}
+
+func (f *testNodeFilter) Unschedulable(context.Context) (map[string]struct{}, error) {
+	return f.nodes, f.err
+}
+
+// TestDispatchLoop_SkipsCordonedPeers verifies cordoned and draining peers
+// (self included) leave the rotation with no all-cordoned fallback, and that a
+// failed lookup keeps every peer.
+func TestDispatchLoop_SkipsCordonedPeers(t *testing.T) {
+	filter := &testNodeFilter{nodes: map[string]struct{}{"drain:9001": {}}}
+	loop := NewDispatchLoop(DispatchLoopConfig{
+		NodeID: "self:9001",
+		Peers:  &testPeerLister{},
+		Nodes:  filter,
+	})
+	ps := []peer{{nodeID: "drain:9001"}, {nodeID: "live:9001"}, {nodeID: "self:9001"}}
+	ctx := context.Background()
+
+	require.Equal(t, []string{"live:9001", "self:9001"}, peerIDs(loop.schedulablePeers(ctx, ps)))
+
+	filter.nodes["self:9001"] = struct{}{}
+	require.Equal(t, []string{"live:9001"}, peerIDs(loop.schedulablePeers(ctx, ps)), "a cordoned self is skipped too")
+
+	filter.nodes["live:9001"] = struct{}{}
+	require.Empty(t, loop.schedulablePeers(ctx, ps), "every node cordoned leaves nothing to dispatch to")
+
+	filter.err = errors.New("catalog unavailable")
+	require.Len(t, loop.schedulablePeers(ctx, ps), 3, "lookup failure keeps the full list")
+}
+
+type testPlacement struct {
+	nodes map[string]map[string]string
+	err   error
+}
+
+func (p *testPlacement) Nodes(context.Context) (map[string]map[string]string, error) {
+	return p.nodes, p.err
+}
+
+func (p *testPlacement) TaskSpec(context.Context, uuid.UUID) (placement.Spec, error) {
+	return placement.Spec{}, p.err
+}
+
+type testRunningCounter struct {
+	TaskPendingReader
+	running map[string]int
+}
+
+func (c *testRunningCounter) RunningTasksByNode(context.Context, uuid.UUID) (map[string]int, error) {
+	return c.running, nil
+}
+
+// TestDispatchLoop_PicksPeersByPlacement verifies constrained tasks go only to
+// peers whose registered labels satisfy them, highest preferred score first,
+// that spread tasks favour the peer running fewest of the run's tasks, and
+// that a task no peer satisfies is held back.
+func TestDispatchLoop_PicksPeersByPlacement(t *testing.T) {
+	metrics.Register()
+	nodes := &testPlacement{nodes: map[string]map[string]string{
+		"cpu:9001": {"arch": "amd64"},
+		"gpu:9001": {"arch": "amd64", "gpu": "a100"},
+		"arm:9001": {"arch": "arm64"},
+	}}
+	loop := NewDispatchLoop(DispatchLoopConfig{
+		NodeID:    "self:9001",
+		Peers:     &testPeerLister{},
+		Placement: nodes,
+		Store:     &testRunningCounter{running: map[string]int{"cpu:9001": 1}},
+	})
+	ps := []peer{{nodeID: "cpu:9001"}, {nodeID: "gpu:9001"}, {nodeID: "arm:9001"}, {nodeID: "self:9001"}}
+	ctx := context.Background()
+	picker := loop.newPeerPicker(uuid.New(), ps)
+
+	pickN := func(spec placement.Spec, n int) []string {
+		var out []string
+		for range n {
+			p, ok := picker.pick(ctx, spec)
+			require.True(t, ok)
+			out = append(out, p.nodeID)
+		}
+		return out
+	}
+
+	amd64 := []jobdef.NodeSelectorRequirement{{Key: "arch", Operator: jobdef.NodeOperatorIn, Values: []string{"amd64"}}}
+	require.ElementsMatch(t, []string{"cpu:9001", "gpu:9001", "cpu:9001", "gpu:9001"},
+		pickN(placement.Spec{Affinity: &jobdef.NodeAffinity{Required: amd64}}, 4),
+		"required expressions rotate among eligible peers only; the unregistered self has no labels")
+
+	preferGPU := &jobdef.NodeAffinity{Preferred: []jobdef.PreferredNodeTerm{{
+		Weight: 50,
+		Match:  []jobdef.NodeSelectorRequirement{{Key: "gpu", Operator: jobdef.NodeOperatorExists}},
+	}}}
+	require.Equal(t, []string{"gpu:9001", "gpu:9001"}, pickN(placement.Spec{Affinity: preferGPU}, 2))
+
+	require.Equal(t, []string{"arm:9001"}, pickN(placement.Spec{Selector: map[string]string{"arch": "arm64"}}, 1))
+
+	// cpu already runs one of the run's tasks and was picked twice above,
+	// while gpu was picked four times, so a spread task lands on cpu.
+	spread := &jobdef.NodeAffinity{Required: amd64, Spread: true}
+	require.Equal(t, []string{"cpu:9001"}, pickN(placement.Spec{Affinity: spread}, 1))
+
+	before := counterVecValue(t, metrics.DispatchRejectedTotal, DispatchReasonNoEligiblePeer)
+	_, ok := picker.pick(ctx, placement.Spec{Selector: map[string]string{"arch": "s390x"}})
+	require.False(t, ok)
+	require.Equal(t, before+1, counterVecValue(t, metrics.DispatchRejectedTotal, DispatchReasonNoEligiblePeer))
+
+	nodes.err = errors.New("catalog unavailable")
+	failing := loop.newPeerPicker(uuid.New(), ps)
+	_, ok = failing.pick(ctx, placement.Spec{Selector: map[string]string{"arch": "amd64"}})
+	require.False(t, ok, "a failed label lookup holds constrained tasks back")
+	p, ok := failing.pick(ctx, placement.Spec{})
+	require.True(t, ok, "unconstrained tasks still dispatch")
+	require.Contains(t, peerIDs(ps), p.nodeID)
+}
+
 // TestDispatchLoop_BenchesUnreachablePeer verifies the breaker end-to-end through
 // the loop: a peer that fails with a network error is benched after the first
 // failure, so subsequent ticks route to the reachable peer instead of repeatedly
@@ -0,0 +1,156 @@
+package dispatch
+
+import (
+	"context"
+
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/internal/placement"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/google/uuid"
+)
+
+// Placement resolves worker node labels and task placement constraints so
+// the loop honours nodeSelector and nodeAffinity.  The production
+// implementation is placement.Registry.
+type Placement interface {
+	Nodes(ctx context.Context) (map[string]map[string]string, error)
+	TaskSpec(ctx context.Context, taskID uuid.UUID) (placement.Spec, error)
+}
+
+// runningTaskCounter is implemented by run.Store; the loop uses it to spread
+// a run's nodeAffinity.spread tasks across nodes.
+type runningTaskCounter interface {
+	RunningTasksByNode(ctx context.Context, runID uuid.UUID) (map[string]int, error)
+}
+
+// peerPicker chooses the peer for each ready task of one run within a tick.
+// Unconstrained tasks keep the loop's round-robin.  A constrained task goes to
+// an eligible peer with the highest preferred-term score; among those, a
+// spread task goes to the peer running the fewest of the run's tasks, and any
+// remaining tie is broken round-robin.  Node labels and per-node running
+// counts are read at most once per run per tick.
+type peerPicker struct {
+	l     *DispatchLoop
+	runID uuid.UUID
+	peers []peer
+
+	labels       map[string]map[string]string
+	labelsLoaded bool
+	labelsOK     bool
+
+	running       map[string]int
+	runningLoaded bool
+	dispatched    map[string]int
+}
+
+func (l *DispatchLoop) newPeerPicker(runID uuid.UUID, peers []peer) *peerPicker {
+	return &peerPicker{l: l, runID: runID, peers: peers, dispatched: make(map[string]int)}
+}
+
+// pick returns the peer for a task with spec, or false when no peer may run
+// it.  Such a task stays pending until an eligible node joins or is
+// uncordoned.
+func (p *peerPicker) pick(ctx context.Context, spec placement.Spec) (peer, bool) {
+	if !spec.Constrained() || p.l.cfg.Placement == nil {
+		return p.record(p.roundRobin(p.peers)), true
+	}
+	labels, ok := p.nodeLabels(ctx)
+	if !ok {
+		return peer{}, false
+	}
+
+	var best []peer
+	bestScore := -1
+	for _, candidate := range p.peers {
+		nodeLabels := labels[candidate.nodeID]
+		if !spec.Eligible(nodeLabels) {
+			continue
+		}
+		switch score := spec.Score(nodeLabels); {
+		case score > bestScore:
+			best, bestScore = []peer{candidate}, score
+		case score == bestScore:
+			best = append(best, candidate)
+		}
+	}
+	if len(best) == 0 {
+		metrics.DispatchRejectedTotal.WithLabelValues(DispatchReasonNoEligiblePeer).Inc()
+		return peer{}, false
+	}
+	if spec.Spread() {
+		best = p.leastLoaded(ctx, best)
+	}
+	return p.record(p.roundRobin(best)), true
+}
+
+func (p *peerPicker) roundRobin(peers []peer) peer {
+	idx := p.l.counter.Add(1) - 1
+	return peers[idx%uint64(len(peers))]
+}
+
+func (p *peerPicker) record(chosen peer) peer {
+	p.dispatched[chosen.nodeID]++
+	return chosen
+}
+
+// nodeLabels loads the registered labels of every live node.  A failed
+// lookup holds back constrained tasks for this tick rather than guessing.
+func (p *peerPicker) nodeLabels(ctx context.Context) (map[string]map[string]string, bool) {
+	if !p.labelsLoaded {
+		p.labelsLoaded = true
+		labels, err := p.l.cfg.Placement.Nodes(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Warn("dispatch loop: node label lookup failed", "run_id", p.runID, "error", err)
+			}
+		} else {
+			p.labels, p.labelsOK = labels, true
+		}
+	}
+	return p.labels, p.labelsOK
+}
+
+// leastLoaded narrows peers to those running the fewest of the run's tasks,
+// counting both running task_runs and this tick's dispatches.
+func (p *peerPicker) leastLoaded(ctx context.Context, peers []peer) []peer {
+	if !p.runningLoaded {
+		p.runningLoaded = true
+		if counter, ok := p.l.cfg.Store.(runningTaskCounter); ok {
+			running, err := counter.RunningTasksByNode(ctx, p.runID)
+			if err != nil && ctx.Err() == nil {
+				log.Warn("dispatch loop: running task count failed", "run_id", p.runID, "error", err)
+			}
+			p.running = running
+		}
+	}
+
+	var out []peer
+	fewest := -1
+	for _, candidate := range peers {
+		load := p.running[candidate.nodeID] + p.dispatched[candidate.nodeID]
+		switch {
+		case fewest < 0 || load < fewest:
+			out, fewest = []peer{candidate}, load
+		case load == fewest:
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
+
+// taskSpec returns the placement constraints of a catalog task for the
+// in-memory owner path.  A failed lookup returns false so the task waits for
+// the next tick.
+func (l *DispatchLoop) taskSpec(ctx context.Context, runID, taskID uuid.UUID) (placement.Spec, bool) {
+	if l.cfg.Placement == nil {
+		return placement.Spec{}, true
+	}
+	spec, err := l.cfg.Placement.TaskSpec(ctx, taskID)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warn("dispatch loop: task placement lookup failed", "run_id", runID, "task_id", taskID, "error", err)
+		}
+		return placement.Spec{}, false
+	}
+	return spec, true
+}
@@ -17,22 +17,26 @@ const defaultSubscriberBuffer = 1000
 type Type string
 
 const (
-	TypeJobCreated             Type = "job_created"
-	TypeJobDeleted             Type = "job_deleted"
-	TypeRunStarted             Type = "run_started"
-	TypeRunCompleted           Type = "run_completed"
-	TypeRunFailed              Type = "run_failed"
-	TypeRunCancelled           Type = "run_cancelled"
-	TypeRunTerminal            Type = "run_terminal"
-	TypeTaskStarted            Type = "task_started"
-	TypeTaskSucceeded          Type = "task_succeeded"
-	TypeTaskFailed             Type = "task_failed"
-	TypeTaskSkipped            Type = "task_skipped"
-	TypeTaskRetrying           Type = "task_retrying"
-	TypeTaskReady              Type = "task_ready"
-	TypeTaskCached             Type = "task_cached"
-	TypeTaskClaimed            Type = "task_claimed"
-	TypeTaskLeaseExpired       Type = "task_lease_expired"
+	TypeJobCreated       Type = "job_created"
+	TypeJobDeleted       Type = "job_deleted"
+	TypeRunStarted       Type = "run_started"
+	TypeRunCompleted     Type = "run_completed"
+	TypeRunFailed        Type = "run_failed"
+	TypeRunCancelled     Type = "run_cancelled"
+	TypeRunTerminal      Type = "run_terminal"
+	TypeTaskStarted      Type = "task_started"
+	TypeTaskSucceeded    Type = "task_succeeded"
+	TypeTaskFailed       Type = "task_failed"
+	TypeTaskSkipped      Type = "task_skipped"
+	TypeTaskRetrying     Type = "task_retrying"
+	TypeTaskReady        Type = "task_ready"
+	TypeTaskCached       Type = "task_cached"
+	TypeTaskClaimed      Type = "task_claimed"
+	TypeTaskLeaseExpired Type = "task_lease_expired"
+	// TypeTaskAwaitingApproval fires when a gated step parks waiting for a
+	// human approve/reject decision; its payload is the task run with the
+	// open approval request attached.
+	TypeTaskAwaitingApproval   Type = "task_awaiting_approval"
 	TypeLogChunk               Type = "log_chunk"
 	TypeJobPaused              Type = "job_paused"
 	TypeJobUnpaused            Type = "job_unpaused"
@@ -43,6 +47,7 @@ const (
 	TypeRunRetried             Type = "run_retried"
 	TypeRunTimedOut            Type = "run_timed_out"
 	TypeSLAMissed              Type = "sla_missed"
+	TypeSLAAtRisk              Type = "sla_at_risk"
 	TypeFreshnessViolated      Type = "freshness_violated"
 	TypeDatasetFreshnessAtRisk Type = "dataset_freshness_at_risk"
 	// TypeDatasetAdvanced fires after a dataset's watermark is advanced or
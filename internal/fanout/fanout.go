@@ -0,0 +1,271 @@
+// Package fanout runs mapped steps. A step with a `map` block executes once
+// per item of a JSON array emitted by one of its predecessors: the package
+// resolves that list, materializes one instance per item under the step's
+// group task run, runs the instances with bounded parallelism through an
+// executor-supplied callback, and folds their outcomes into the single
+// status, output, and hash the rest of the DAG sees. The local executor and
+// run owners drive mapped steps through Run; distributed workers run each
+// instance as its own task run and fold the outcomes with Collect.
+package fanout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+)
+
+// Env vars injected into every instance of a mapped step. They are volatile
+// per-instance values and never enter the hashed env; the item is folded into
+// the cache key through cache.HashInput.MapItem instead.
+const (
+	EnvItem  = "CAESIUM_MAP_ITEM"
+	EnvIndex = "CAESIUM_MAP_INDEX"
+	EnvCount = "CAESIUM_MAP_COUNT"
+)
+
+// Spec decodes a task's persisted map configuration. It returns nil for tasks
+// that are not mapped.
+func Spec(task *models.Task) (*jobdefschema.StepMap, error) {
+	if task == nil || len(task.MapConfig) == 0 {
+		return nil, nil
+	}
+	var spec jobdefschema.StepMap
+	if err := json.Unmarshal(task.MapConfig, &spec); err != nil {
+		return nil, fmt.Errorf("task %s: decode map config: %w", task.ID, err)
+	}
+	return &spec, nil
+}
+
+// Items resolves the list a mapped step fans out over from its predecessors'
+// outputs (keyed by step name, as pkg/task.BuildOutputEnv takes them). The
+// output value must be a JSON array of at most jobdef.MaxMapItems elements;
+// string elements are used verbatim and any other element as compact JSON.
+func Items(spec jobdefschema.StepMap, predOutputs map[string]map[string]string) ([]string, error) {
+	step, key, ok := spec.Source(slices.Collect(maps.Keys(predOutputs)))
+	if !ok {
+		return nil, fmt.Errorf("map.over %q: predecessor emitted no outputs", spec.Over)
+	}
+	raw, ok := predOutputs[step][key]
+	if !ok {
+		return nil, fmt.Errorf("map.over %q: step %q emitted no output %q", spec.Over, step, key)
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &elems); err != nil || elems == nil {
+		return nil, fmt.Errorf("map.over %q: output must be a JSON array", spec.Over)
+	}
+	if len(elems) > jobdefschema.MaxMapItems {
+		return nil, fmt.Errorf("map.over %q: %d items exceeds the limit of %d", spec.Over, len(elems), jobdefschema.MaxMapItems)
+	}
+
+	items := make([]string, 0, len(elems))
+	for _, elem := range elems {
+		if len(elem) > 0 && elem[0] == '"' {
+			var s string
+			if err := json.Unmarshal(elem, &s); err != nil {
+				return nil, fmt.Errorf("map.over %q: %w", spec.Over, err)
+			}
+			items = append(items, s)
+			continue
+		}
+		var compact any
+		if err := json.Unmarshal(elem, &compact); err != nil {
+			return nil, fmt.Errorf("map.over %q: %w", spec.Over, err)
+		}
+		encoded, err := json.Marshal(compact)
+		if err != nil {
+			return nil, fmt.Errorf("map.over %q: %w", spec.Over, err)
+		}
+		items = append(items, string(encoded))
+	}
+	return items, nil
+}
+
+// InstanceOutputs returns predOutputs without the list the step maps over.
+// Instances see (and hash) only their own item, so adding an item to the
+// list leaves the other instances' cache keys untouched.
+func InstanceOutputs(spec jobdefschema.StepMap, predOutputs map[string]map[string]string) map[string]map[string]string {
+	step, key, ok := spec.Source(slices.Collect(maps.Keys(predOutputs)))
+	if !ok {
+		return predOutputs
+	}
+	out := make(map[string]map[string]string, len(predOutputs))
+	for name, outputs := range predOutputs {
+		if name != step {
+			out[name] = outputs
+			continue
+		}
+		trimmed := maps.Clone(outputs)
+		delete(trimmed, key)
+		if len(trimmed) > 0 {
+			out[name] = trimmed
+		}
+	}
+	return out
+}
+
+// InstanceEnv returns the env vars identifying one instance.
+func InstanceEnv(inst *run.TaskInstance, count int) map[string]string {
+	return map[string]string{
+		EnvItem:  inst.Item,
+		EnvIndex: strconv.Itoa(inst.Index),
+		EnvCount: strconv.Itoa(count),
+	}
+}
+
+// Outcome is what executing one instance produced. Hash is the instance's
+// cache identity, empty when caching is off.
+type Outcome struct {
+	run.TaskInstanceResult
+	Hash string
+}
+
+// Exec runs one instance to a terminal outcome, including its own cache
+// lookup and retries. count is the number of instances in the group.
+type Exec func(ctx context.Context, inst *run.TaskInstance, count int) Outcome
+
+// Group is the aggregate of a mapped step's instances.
+type Group struct {
+	Instances []*run.TaskInstance
+	// Output maps each output key any instance emitted to a JSON array of
+	// that key's values, aligned by instance index ("" where an instance did
+	// not emit the key).
+	Output map[string]string
+	// Hash combines the instance hashes in index order; it is empty when any
+	// instance has no hash.
+	Hash string
+	// Err is set when any instance did not succeed.
+	Err error
+}
+
+// Run expands the mapped task into one instance per item and executes every
+// instance that has not already succeeded, at most maxParallel at a time (0
+// means no limit). Instance outcomes are persisted as they land. The returned
+// error covers persistence failures and ctx cancellation; instance failures
+// are reported through Group.Err so the caller can fail the group row.
+func Run(ctx context.Context, store *run.Store, runID, taskID uuid.UUID, items []string, maxParallel int, exec Exec) (*Group, error) {
+	instances, err := store.ExpandMapTask(runID, taskID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]*run.TaskInstance, 0, len(instances))
+	for _, inst := range instances {
+		if !run.IsTerminalSuccess(inst.Status) {
+			pending = append(pending, inst)
+		}
+	}
+	if maxParallel <= 0 || maxParallel > len(pending) {
+		maxParallel = len(pending)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, max(maxParallel, 1))
+	for _, inst := range pending {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(inst *run.TaskInstance) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			outcome := exec(ctx, inst, len(instances))
+			if ctx.Err() != nil {
+				return
+			}
+			if err := store.CompleteTaskInstance(inst.ID, outcome.TaskInstanceResult); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("task %s instance %d: %w", taskID, inst.Index, err)
+				}
+				mu.Unlock()
+				return
+			}
+			inst.Status = outcome.Status
+			inst.Result = outcome.Result
+			inst.Output = outcome.Output
+			inst.Error = outcome.Error
+			inst.Hash = outcome.Hash
+			inst.CacheHit = outcome.Cache != nil
+		}(inst)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return Collect(instances), nil
+}
+
+// Collect folds a group's recorded instances, ordered by index, into the
+// aggregate the rest of the DAG sees. Workers call it once every instance of
+// an expanded group has run as its own task run.
+func Collect(instances []*run.TaskInstance) *Group {
+	group := &Group{Instances: instances}
+
+	keys := make(map[string]struct{})
+	var failed []*run.TaskInstance
+	hashes := sha256.New()
+	hashed := true
+	fmt.Fprintf(hashes, "map_count:%d\n", len(instances))
+	for _, inst := range instances {
+		for k := range inst.Output {
+			keys[k] = struct{}{}
+		}
+		if !run.IsTerminalSuccess(inst.Status) {
+			failed = append(failed, inst)
+		}
+		if inst.Hash == "" {
+			hashed = false
+		}
+		fmt.Fprintf(hashes, "instance:%d:%s\n", inst.Index, inst.Hash)
+	}
+	if hashed {
+		group.Hash = hex.EncodeToString(hashes.Sum(nil))
+	}
+
+	if len(keys) > 0 {
+		group.Output = make(map[string]string, len(keys))
+		for key := range keys {
+			values := make([]string, len(instances))
+			for i, inst := range instances {
+				values[i] = inst.Output[key]
+			}
+			encoded, _ := json.Marshal(values)
+			group.Output[key] = string(encoded)
+		}
+	}
+
+	if len(failed) > 0 {
+		first := failed[0]
+		reason := first.Error
+		if reason == "" {
+			reason = string(first.Status)
+		}
+		group.Err = fmt.Errorf("%d of %d mapped instances failed; first: item %d (%q): %s", len(failed), len(instances), first.Index, first.Item, reason)
+	}
+	return group
+}
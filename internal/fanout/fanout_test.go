@@ -0,0 +1,187 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestSpec(t *testing.T) {
+	spec, err := Spec(&models.Task{})
+	require.NoError(t, err)
+	require.Nil(t, spec)
+
+	spec, err = Spec(&models.Task{MapConfig: datatypes.JSON(`{"over":"list.files","maxParallel":3}`)})
+	require.NoError(t, err)
+	require.Equal(t, &jobdefschema.StepMap{Over: "list.files", MaxParallel: 3}, spec)
+}
+
+func TestItems(t *testing.T) {
+	spec := jobdefschema.StepMap{Over: "list.files"}
+	items, err := Items(spec, map[string]map[string]string{
+		"list": {"files": `["a.csv", 7, {"b": true}, null]`, "count": "4"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.csv", "7", `{"b":true}`, "null"}, items)
+
+	items, err = Items(spec, map[string]map[string]string{"list": {"files": "[]"}})
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	cases := map[string]map[string]map[string]string{
+		"predecessor emitted no outputs":   {"other": {"files": "[]"}},
+		`step "list" emitted no output`:    {"list": {"count": "4"}},
+		"output must be a JSON array":      {"list": {"files": `{"a": 1}`}},
+		"exceeds the limit of 1024":        {"list": {"files": "[" + repeatJSON("1", 1025) + "]"}},
+		`map.over "list.files": output mu`: {"list": {"files": "null"}},
+	}
+	for want, outputs := range cases {
+		_, err := Items(spec, outputs)
+		require.ErrorContains(t, err, want)
+	}
+}
+
+func TestInstanceOutputsDropsMappedList(t *testing.T) {
+	spec := jobdefschema.StepMap{Over: "list.files"}
+	out := InstanceOutputs(spec, map[string]map[string]string{
+		"list":   {"files": `["a"]`, "count": "1"},
+		"config": {"mode": "full"},
+	})
+	require.Equal(t, map[string]map[string]string{
+		"list":   {"count": "1"},
+		"config": {"mode": "full"},
+	}, out)
+
+	out = InstanceOutputs(spec, map[string]map[string]string{"list": {"files": `["a"]`}})
+	require.Empty(t, out, "a predecessor left with no outputs is dropped entirely")
+}
+
+func TestRunAggregatesInstances(t *testing.T) {
+	store, runID, taskID := newMappedGroup(t)
+
+	var inFlight, peak atomic.Int32
+	exec := func(_ context.Context, inst *run.TaskInstance, count int) Outcome {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		require.Equal(t, 3, count)
+		env := InstanceEnv(inst, count)
+		require.Equal(t, inst.Item, env[EnvItem])
+		if inst.Item == "b" {
+			return Outcome{TaskInstanceResult: run.TaskInstanceResult{Status: run.TaskStatusFailed, Error: "exit 2"}, Hash: "hb"}
+		}
+		return Outcome{
+			TaskInstanceResult: run.TaskInstanceResult{Status: run.TaskStatusSucceeded, Result: "success", Output: map[string]string{"rows": inst.Item + "-rows"}},
+			Hash:               "h" + inst.Item,
+		}
+	}
+
+	group, err := Run(context.Background(), store, runID, taskID, []string{"a", "b", "c"}, 2, exec)
+	require.NoError(t, err)
+	require.LessOrEqual(t, peak.Load(), int32(2))
+	require.EqualError(t, group.Err, `1 of 3 mapped instances failed; first: item 1 ("b"): exit 2`)
+	require.Equal(t, map[string]string{"rows": `["a-rows","","c-rows"]`}, group.Output)
+	require.NotEmpty(t, group.Hash)
+
+	persisted, err := store.TaskInstances(runID, taskID)
+	require.NoError(t, err)
+	require.Len(t, persisted, 3)
+	require.Equal(t, run.TaskStatusFailed, persisted[1].Status)
+	require.Equal(t, "exit 2", persisted[1].Error)
+
+	// A retry of the group only re-runs the failed instance.
+	var reran []string
+	group, err = Run(context.Background(), store, runID, taskID, []string{"a", "b", "c"}, 0, func(_ context.Context, inst *run.TaskInstance, _ int) Outcome {
+		reran = append(reran, inst.Item)
+		return Outcome{TaskInstanceResult: run.TaskInstanceResult{Status: run.TaskStatusSucceeded, Result: "success", Output: map[string]string{"rows": "b-rows"}}}
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, reran)
+	require.NoError(t, group.Err)
+	require.Equal(t, `["a-rows","b-rows","c-rows"]`, group.Output["rows"])
+	require.Empty(t, group.Hash, "an instance without a hash leaves the group unhashed")
+}
+
+func TestRunEmptyListSucceeds(t *testing.T) {
+	store, runID, taskID := newMappedGroup(t)
+
+	group, err := Run(context.Background(), store, runID, taskID, nil, 0, func(context.Context, *run.TaskInstance, int) Outcome {
+		t.Fatal("no instance should run")
+		return Outcome{}
+	})
+	require.NoError(t, err)
+	require.NoError(t, group.Err)
+	require.Empty(t, group.Instances)
+	require.Nil(t, group.Output)
+}
+
+func TestRunReturnsCancellationCause(t *testing.T) {
+	store, runID, taskID := newMappedGroup(t)
+	cause := errors.New("run cancelled")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	_, err := Run(ctx, store, runID, taskID, []string{"a", "b"}, 1, func(context.Context, *run.TaskInstance, int) Outcome {
+		cancel(cause)
+		return Outcome{}
+	})
+	require.ErrorIs(t, err, cause)
+}
+
+func newMappedGroup(t *testing.T) (*run.Store, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	now := time.Now().UTC()
+	runID, taskID := uuid.New(), uuid.New()
+	require.NoError(t, db.Create(&models.JobRun{
+		ID:        runID,
+		JobID:     uuid.New(),
+		Status:    string(run.StatusRunning),
+		StartedAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error)
+	require.NoError(t, db.Create(&models.TaskRun{
+		ID:        uuid.New(),
+		JobRunID:  runID,
+		TaskID:    taskID,
+		AtomID:    uuid.New(),
+		Engine:    models.AtomEngineDocker,
+		Image:     "alpine:3.23",
+		Command:   `["echo"]`,
+		Status:    string(run.TaskStatusRunning),
+		Attempt:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error)
+	return run.NewStore(db), runID, taskID
+}
+
+func repeatJSON(elem string, n int) string {
+	out := make([]byte, 0, n*(len(elem)+1))
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, elem...)
+	}
+	return string(out)
+}
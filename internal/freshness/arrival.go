@@ -151,7 +151,7 @@ func (o *ArrivalObserver) Observe(ctx context.Context, evt *models.IngestedEvent
 			// dataset_advanced (RunID nil — no producing run) so the evaluator
 			// derives downstream immediately, removing the up-to-a-full-tick
 			// latency the timer-only path would otherwise impose.
-			o.publishDatasetAdvanced(binding.namespace, binding.name)
+			o.publishDatasetAdvanced(binding.namespace, binding.name, uuid.Nil, uuid.Nil)
 		}
 	}
 
@@ -159,9 +159,9 @@ func (o *ArrivalObserver) Observe(ctx context.Context, evt *models.IngestedEvent
 }
 
 // publishDatasetAdvanced notifies the freshness evaluator that an arrival
-// advanced a source dataset. RunID is nil (no producing run) so the evaluator
-// starts downstream derivation at trigger depth 0.
-func (o *ArrivalObserver) publishDatasetAdvanced(namespace *string, name string) {
+// advanced a source dataset. For an event arrival RunID is nil (no producing
+// run) so the evaluator starts downstream derivation at trigger depth 0.
+func (o *ArrivalObserver) publishDatasetAdvanced(namespace *string, name string, jobID, runID uuid.UUID) {
 	if o.bus == nil {
 		return
 	}
@@ -171,6 +171,8 @@ func (o *ArrivalObserver) publishDatasetAdvanced(namespace *string, name string)
 	})
 	o.bus.Publish(event.Event{
 		Type:      event.TypeDatasetAdvanced,
+		JobID:     jobID,
+		RunID:     runID,
 		Timestamp: time.Now().UTC(),
 		Payload:   payload,
 	})
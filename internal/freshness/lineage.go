@@ -0,0 +1,132 @@
+package freshness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/google/uuid"
+)
+
+// LineageObservation is the set of datasets an external tool (dbt, Spark,
+// Airbyte, …) reported writing in an OpenLineage COMPLETE event that was
+// correlated to a Caesium run.
+type LineageObservation struct {
+	JobID uuid.UUID
+	RunID uuid.UUID
+
+	// Names are the reported output dataset names. Dataset state keys on name
+	// (v1), so the tool's namespace is not carried.
+	Names []string
+
+	// At is the event time reported by the tool; zero means now. It is clamped
+	// to the server's receive time when it runs ahead by more than
+	// MaxLineageClockSkew.
+	At time.Time
+}
+
+// MaxLineageClockSkew bounds how far a reported lineage event time may run
+// ahead of the server clock. A tool with a fast clock (or a forged eventTime)
+// would otherwise order its verify after every later run and keep the dataset
+// fresh past its SLA.
+const MaxLineageClockSkew = time.Minute
+
+// LineageAdvance records one reported output verified by ObserveLineage.
+type LineageAdvance struct {
+	Dataset string
+	Outcome Outcome
+}
+
+// ObserveLineage refreshes the state of each dataset an external tool reported
+// writing. The event carries no watermark, so this is degraded mode: verified_at
+// moves to the event time, or to the receive time when the event claims a time
+// further in the future than MaxLineageClockSkew.
+//
+// A dataset that some step declares in produces is skipped — its state follows
+// the declared watermark contract when the producing run completes (Capturer),
+// and a verify here could mark a stale value fresh. Outputs of backfill runs
+// are skipped as Advance drops them.
+func (o *ArrivalObserver) ObserveLineage(ctx context.Context, obs LineageObservation) ([]LineageAdvance, error) {
+	if o == nil || o.registry == nil || o.store == nil {
+		return nil, errors.New("freshness: arrival observer is not configured")
+	}
+	if obs.RunID == uuid.Nil {
+		return nil, errors.New("freshness: lineage observation requires a run id")
+	}
+
+	names := make([]string, 0, len(obs.Names))
+	seen := make(map[string]struct{}, len(obs.Names))
+	for _, name := range obs.Names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var declared []string
+	if err := o.registry.db.WithContext(ctx).Model(&models.DatasetDeclaration{}).
+		Where("direction = ? AND name IN ?", models.DatasetDirectionProduces, names).
+		Distinct().Pluck("name", &declared).Error; err != nil {
+		return nil, fmt.Errorf("load produced declarations: %w", err)
+	}
+	produced := make(map[string]struct{}, len(declared))
+	for _, name := range declared {
+		produced[name] = struct{}{}
+	}
+
+	var run struct {
+		BackfillID *uuid.UUID
+	}
+	if err := o.registry.db.WithContext(ctx).Table("job_runs").
+		Select("backfill_id").Where("id = ?", obs.RunID).Take(&run).Error; err != nil {
+		return nil, fmt.Errorf("load run %s: %w", obs.RunID, err)
+	}
+
+	at := lineageEventTime(obs.At, time.Now())
+
+	advances := make([]LineageAdvance, 0, len(names))
+	for _, name := range names {
+		if _, ok := produced[name]; ok {
+			continue
+		}
+		res, err := o.store.Advance(ctx, AdvanceInput{
+			Name:        name,
+			RunID:       obs.RunID,
+			RunOrder:    at,
+			CompletedAt: at,
+			Backfill:    run.BackfillID != nil,
+		})
+		if err != nil {
+			return advances, fmt.Errorf("verify lineage dataset %q for run %s: %w", name, obs.RunID, err)
+		}
+		advances = append(advances, LineageAdvance{Dataset: name, Outcome: res.Outcome})
+		if res.Outcome == OutcomeAdvanced || res.Outcome == OutcomeVerified {
+			log.Info("freshness: lineage event verified dataset",
+				"dataset", name, "run_id", obs.RunID, "outcome", string(res.Outcome))
+			o.publishDatasetAdvanced(nil, name, obs.JobID, obs.RunID)
+		}
+	}
+	return advances, nil
+}
+
+// lineageEventTime returns the time a lineage verify is recorded at: the
+// reported event time, unless it is missing or ahead of now by more than
+// MaxLineageClockSkew, in which case now.
+func lineageEventTime(reported, now time.Time) time.Time {
+	if reported.IsZero() || reported.After(now.Add(MaxLineageClockSkew)) {
+		return now.UTC()
+	}
+	return reported.UTC()
+}
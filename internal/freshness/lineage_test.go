@@ -0,0 +1,119 @@
+package freshness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestObserveLineageVerifiesUndeclaredOutputs(t *testing.T) {
+	db := openRegistryDB(t)
+	o := NewArrivalObserver(db)
+	ctx := context.Background()
+
+	jobID, runID := uuid.New(), uuid.New()
+	seedProducingRun(t, db, jobID, runID, map[string]string{}, nil, "")
+
+	at := t0.Add(2 * time.Hour)
+	advances, err := o.ObserveLineage(ctx, LineageObservation{
+		JobID: jobID,
+		RunID: runID,
+		// staging.orders is declared in produces, so the Capturer owns it.
+		Names: []string{"analytics.orders", "staging.orders", "analytics.orders", " "},
+		At:    at,
+	})
+	if err != nil {
+		t.Fatalf("observe: %v", err)
+	}
+	if len(advances) != 1 || advances[0].Dataset != "analytics.orders" || advances[0].Outcome != OutcomeVerified {
+		t.Fatalf("advances = %+v, want analytics.orders verified", advances)
+	}
+
+	st, ok, err := o.store.Get(ctx, nil, "analytics.orders")
+	if err != nil || !ok {
+		t.Fatalf("get: %v ok=%v", err, ok)
+	}
+	if st.VerifiedAt == nil || !st.VerifiedAt.Equal(at) {
+		t.Fatalf("verified_at = %v, want %v", st.VerifiedAt, at)
+	}
+	if st.LastRunID == nil || *st.LastRunID != runID {
+		t.Fatalf("last_run_id = %v, want %v", st.LastRunID, runID)
+	}
+	if _, ok, _ := o.store.Get(ctx, nil, "staging.orders"); ok {
+		t.Fatalf("declared produced dataset must not be verified by a lineage event")
+	}
+}
+
+func TestObserveLineageClampsFutureEventTime(t *testing.T) {
+	db := openRegistryDB(t)
+	o := NewArrivalObserver(db)
+	ctx := context.Background()
+
+	jobID, runID := uuid.New(), uuid.New()
+	seedProducingRun(t, db, jobID, runID, map[string]string{}, nil, "")
+
+	before := time.Now().UTC()
+	if _, err := o.ObserveLineage(ctx, LineageObservation{
+		JobID: jobID,
+		RunID: runID,
+		Names: []string{"analytics.orders"},
+		At:    before.Add(24 * time.Hour),
+	}); err != nil {
+		t.Fatalf("observe: %v", err)
+	}
+	after := time.Now().UTC()
+
+	st, ok, err := o.store.Get(ctx, nil, "analytics.orders")
+	if err != nil || !ok {
+		t.Fatalf("get: %v ok=%v", err, ok)
+	}
+	if st.VerifiedAt == nil || st.VerifiedAt.Before(before.Add(-time.Second)) || st.VerifiedAt.After(after.Add(time.Second)) {
+		t.Fatalf("verified_at = %v, want the receive time in [%v, %v]", st.VerifiedAt, before, after)
+	}
+}
+
+func TestLineageEventTimeAllowsSmallSkew(t *testing.T) {
+	now := time.Date(2026, 7, 3, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		reported time.Time
+		want     time.Time
+	}{
+		{"zero", time.Time{}, now},
+		{"past", now.Add(-time.Hour), now.Add(-time.Hour)},
+		{"within skew", now.Add(MaxLineageClockSkew), now.Add(MaxLineageClockSkew)},
+		{"beyond skew", now.Add(MaxLineageClockSkew + time.Second), now},
+	} {
+		if got := lineageEventTime(tc.reported, now); !got.Equal(tc.want) {
+			t.Errorf("%s: lineageEventTime = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestObserveLineageBackfillNeverVerifies(t *testing.T) {
+	db := openRegistryDB(t)
+	o := NewArrivalObserver(db)
+	ctx := context.Background()
+
+	jobID, runID, bf := uuid.New(), uuid.New(), uuid.New()
+	if err := db.Create(&models.JobRun{
+		ID: runID, JobID: jobID, TriggerID: uuid.New(), Status: "running",
+		BackfillID: &bf, StartedAt: t0, CreatedAt: t0, UpdatedAt: t0,
+	}).Error; err != nil {
+		t.Fatalf("seed run: %v", err)
+	}
+
+	advances, err := o.ObserveLineage(ctx, LineageObservation{JobID: jobID, RunID: runID, Names: []string{"analytics.orders"}})
+	if err != nil {
+		t.Fatalf("observe: %v", err)
+	}
+	if len(advances) != 1 || advances[0].Outcome != OutcomeBackfillDropped {
+		t.Fatalf("advances = %+v, want backfill dropped", advances)
+	}
+	if _, ok, _ := o.store.Get(ctx, nil, "analytics.orders"); ok {
+		t.Fatalf("backfill run must not create dataset state")
+	}
+}
@@ -89,6 +89,14 @@ func BuildDeclarations(def *schema.Definition, jobID uuid.UUID, jobAlias string)
 			if err != nil {
 				return nil, fmt.Errorf("steps[%d].datasets.produces[%d].schema: %w", i, j, err)
 			}
+			var columnLineage datatypes.JSON
+			if len(p.ColumnLineage) > 0 {
+				raw, err := json.Marshal(p.ColumnLineage)
+				if err != nil {
+					return nil, fmt.Errorf("steps[%d].datasets.produces[%d].columnLineage: %w", i, j, err)
+				}
+				columnLineage = datatypes.JSON(raw)
+			}
 			watermarkKey := ""
 			if p.Watermark != nil {
 				watermarkKey = strings.TrimSpace(p.Watermark.Key)
@@ -103,6 +111,7 @@ func BuildDeclarations(def *schema.Definition, jobID uuid.UUID, jobAlias string)
 				SchemaJSON:    schemaJSON,
 				SchemaFrom:    strings.TrimSpace(p.SchemaFrom),
 				SchemaVersion: p.Version,
+				ColumnLineage: columnLineage,
 				Freshness:     strings.TrimSpace(p.Freshness),
 				MaxStaleness:  strings.TrimSpace(p.MaxStaleness),
 				WatermarkKey:  watermarkKey,
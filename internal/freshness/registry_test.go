@@ -189,6 +189,45 @@ func TestBuildDeclarationsPersistsSchemaMetadata(t *testing.T) {
 	requireSchemaJSONRequired(t, inline.SchemaJSON, "order_id")
 }
 
+func TestBuildDeclarationsCarriesColumnLineage(t *testing.T) {
+	def, err := schema.Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: revenue
+trigger:
+  type: cron
+  configuration: {expression: "0 * * * *"}
+steps:
+  - name: aggregate
+    image: etl:1
+    datasets:
+      consumes: [raw.orders]
+      produces:
+        - name: mart.revenue
+          columnLineage:
+            total: {inputFields: [{dataset: raw.orders, field: amount}]}
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	decls, err := BuildDeclarations(def, uuid.New(), def.Metadata.Alias)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	produced := findDeclaration(t, decls, models.DatasetDirectionProduces, "mart.revenue")
+	var lineage map[string]schema.ColumnLineageField
+	if err := json.Unmarshal(produced.ColumnLineage, &lineage); err != nil {
+		t.Fatalf("decode column lineage %q: %v", produced.ColumnLineage, err)
+	}
+	if in := lineage["total"].InputFields; len(in) != 1 || in[0].Dataset != "raw.orders" || in[0].Field != "amount" || in[0].Type != schema.ColumnLineageDirect {
+		t.Fatalf("unexpected column lineage: %+v", lineage)
+	}
+	if consumed := findDeclaration(t, decls, models.DatasetDirectionConsumes, "raw.orders"); len(consumed.ColumnLineage) != 0 {
+		t.Fatalf("consumed declaration carries column lineage: %s", consumed.ColumnLineage)
+	}
+}
+
 func TestBuildDeclarationsCarriesSkipWhenFreshOptOut(t *testing.T) {
 	src := strings.Replace(registrySampleJob, "  datasets:\n    sources:", "  datasets:\n    skipWhenFresh: false\n    sources:", 1)
 	def, err := schema.Parse([]byte(src))
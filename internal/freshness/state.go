@@ -409,6 +409,17 @@ func orderableGreater(cur, next string) (greater, ok bool) {
 	return false, false
 }
 
+// WatermarkReached reports whether a dataset at watermark current has reached
+// target: the values are equal, or both are orderable and current is greater.
+// Opaque watermarks reach only an equal target.
+func WatermarkReached(current, target string) bool {
+	if strings.TrimSpace(current) == strings.TrimSpace(target) {
+		return true
+	}
+	greater, ok := orderableGreater(target, current)
+	return ok && greater
+}
+
 func parseRFC3339(v string) (time.Time, error) {
 	v = strings.TrimSpace(v)
 	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
@@ -202,6 +202,30 @@ func TestFreshAt(t *testing.T) {
 	}
 }
 
+// TestWatermarkReached covers the sensor comparison: equal values always
+// reach, orderable values reach when greater, and opaque values only on
+// equality.
+func TestWatermarkReached(t *testing.T) {
+	cases := []struct {
+		current, target string
+		want            bool
+	}{
+		{"42", "42", true},
+		{"43", "42", true},
+		{"41", "42", false},
+		{"2026-07-03T05:00:00Z", "2026-07-03T04:00:00Z", true},
+		{"2026-07-03T03:00:00Z", "2026-07-03T04:00:00Z", false},
+		{"abc123", "abc123", true},
+		{"def456", "abc123", false},
+		{"", "42", false},
+	}
+	for _, tc := range cases {
+		if got := WatermarkReached(tc.current, tc.target); got != tc.want {
+			t.Errorf("WatermarkReached(%q, %q) = %v, want %v", tc.current, tc.target, got, tc.want)
+		}
+	}
+}
+
 // TestAdvancePersistsAndVerifies drives Advance through the real store + SQLite
 // so the transaction, find-or-create, and monotonic guard are exercised end to
 // end (not just the pure contract).
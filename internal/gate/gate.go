@@ -0,0 +1,116 @@
+// Package gate runs approval gates. A step with a `gate` block parks in
+// awaiting_approval once its dependencies are satisfied and waits for an
+// approve/reject decision through the REST API; a leader-gated Sweeper
+// expires requests whose timeout passes, after which the step fails or is
+// skipped according to gate.onTimeout. The local executor holds a gated step
+// through Await; distributed workers Open the request and, while it is
+// pending, release the task rather than hold a claim for the whole wait.
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+)
+
+// DefaultPollInterval is how often Await re-reads a pending request when the
+// caller passes no interval.
+const DefaultPollInterval = 5 * time.Second
+
+// RecheckInterval is how long a worker leaves a released gated task before
+// claiming it to re-read its request. A decision or expiry makes the task
+// claimable at once, so this only bounds how long a missed one goes unseen.
+const RecheckInterval = time.Minute
+
+// Spec decodes a task's persisted gate configuration. It returns nil for
+// tasks that are not gated.
+func Spec(task *models.Task) (*jobdefschema.StepGate, error) {
+	if task == nil || len(task.GateConfig) == 0 {
+		return nil, nil
+	}
+	var spec jobdefschema.StepGate
+	if err := json.Unmarshal(task.GateConfig, &spec); err != nil {
+		return nil, fmt.Errorf("task %s: decode gate config: %w", task.ID, err)
+	}
+	return &spec, nil
+}
+
+// Await opens the task's approval request (or resumes the one left by an
+// earlier attempt) and blocks until it is approved, rejected, or expired, or
+// until ctx ends. claimedBy is the worker claim fencing the task's state
+// transitions; it is empty for the local executor.
+func Await(ctx context.Context, store *run.Store, runID, taskID uuid.UUID, claimedBy string, spec jobdefschema.StepGate, poll time.Duration) (*run.TaskGate, error) {
+	g, err := Open(store, runID, taskID, claimedBy, spec)
+	if err != nil {
+		return nil, err
+	}
+	if poll <= 0 {
+		poll = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for g.Pending() {
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-ticker.C:
+		}
+		if g, err = store.TaskGate(runID, taskID); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Open opens the task's approval request, or reads the one left by an
+// earlier attempt, without waiting for a decision. A pending request parks
+// the task run in awaiting_approval.
+func Open(store *run.Store, runID, taskID uuid.UUID, claimedBy string, spec jobdefschema.StepGate) (*run.TaskGate, error) {
+	return store.AwaitApproval(runID, taskID, claimedBy, run.GateRequest{
+		Approvers: spec.Approvers,
+		Timeout:   spec.Timeout,
+		OnTimeout: spec.OnTimeout,
+	})
+}
+
+// Failure returns the error a decided gate ends its step with: a rejection,
+// or an expiry when onTimeout is fail. It returns nil when the step should
+// run (approved) or be skipped (see Skipped).
+func Failure(g *run.TaskGate) error {
+	switch g.Decision {
+	case models.ApprovalDecisionRejected:
+		if g.Reason != "" {
+			return fmt.Errorf("approval rejected by %s: %s", deciderName(g), g.Reason)
+		}
+		return fmt.Errorf("approval rejected by %s", deciderName(g))
+	case models.ApprovalDecisionExpired:
+		if g.OnTimeout == jobdefschema.GateOnTimeoutSkip {
+			return nil
+		}
+		return fmt.Errorf("approval gate expired: %s", g.Reason)
+	}
+	return nil
+}
+
+// Skipped reports whether an expired gate skips its step, along with the
+// skip reason recorded on the task run.
+func Skipped(g *run.TaskGate) (string, bool) {
+	if g.Decision != models.ApprovalDecisionExpired || g.OnTimeout != jobdefschema.GateOnTimeoutSkip {
+		return "", false
+	}
+	return fmt.Sprintf("approval gate expired: %s", g.Reason), true
+}
+
+func deciderName(g *run.TaskGate) string {
+	if g.Decider == "" {
+		return "an operator"
+	}
+	return g.Decider
+}
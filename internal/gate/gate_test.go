@@ -0,0 +1,123 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func setupGatedRun(t *testing.T) (*run.Store, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := run.NewStore(db)
+
+	jobID := uuid.New()
+	runRecord, err := store.Start(jobID, nil)
+	require.NoError(t, err)
+
+	atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["echo","deploy"]`}
+	require.NoError(t, db.Create(atom).Error)
+	task := &models.Task{ID: uuid.New(), JobID: jobID, AtomID: atom.ID, Name: "deploy"}
+	require.NoError(t, db.Create(task).Error)
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+	return store, runRecord.ID, task.ID
+}
+
+func TestSpec(t *testing.T) {
+	spec, err := Spec(&models.Task{})
+	require.NoError(t, err)
+	require.Nil(t, spec)
+
+	spec, err = Spec(&models.Task{GateConfig: datatypes.JSON(`{"type":"approval","approvers":["sre"],"timeout":3600000000000,"onTimeout":"skip"}`)})
+	require.NoError(t, err)
+	require.NotNil(t, spec)
+	require.Equal(t, []string{"sre"}, spec.Approvers)
+	require.Equal(t, time.Hour, spec.Timeout)
+	require.Equal(t, jobdefschema.GateOnTimeoutSkip, spec.OnTimeout)
+
+	_, err = Spec(&models.Task{GateConfig: datatypes.JSON(`{`)})
+	require.Error(t, err)
+}
+
+func TestAwaitReturnsOnDecision(t *testing.T) {
+	store, runID, taskID := setupGatedRun(t)
+
+	go func() {
+		for {
+			if _, err := store.DecideGate(runID, taskID, models.ApprovalDecisionRejected, "alice@example.com", "not today"); err == nil {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	g, err := Await(context.Background(), store, runID, taskID, "", jobdefschema.StepGate{OnTimeout: jobdefschema.GateOnTimeoutFail}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalDecisionRejected, g.Decision)
+	require.EqualError(t, Failure(g), "approval rejected by alice@example.com: not today")
+	_, skipped := Skipped(g)
+	require.False(t, skipped)
+}
+
+func TestAwaitHonoursContextCancellation(t *testing.T) {
+	store, runID, taskID := setupGatedRun(t)
+	cause := errors.New("run cancelled")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	_, err := Await(ctx, store, runID, taskID, "", jobdefschema.StepGate{OnTimeout: jobdefschema.GateOnTimeoutFail}, time.Millisecond)
+	require.ErrorIs(t, err, cause)
+}
+
+func TestFailureAndSkipped(t *testing.T) {
+	require.NoError(t, Failure(&run.TaskGate{Decision: models.ApprovalDecisionApproved}))
+	require.EqualError(t, Failure(&run.TaskGate{Decision: models.ApprovalDecisionRejected}), "approval rejected by an operator")
+
+	expiredFail := &run.TaskGate{Decision: models.ApprovalDecisionExpired, OnTimeout: jobdefschema.GateOnTimeoutFail, Reason: "approval timed out"}
+	require.EqualError(t, Failure(expiredFail), "approval gate expired: approval timed out")
+	_, skipped := Skipped(expiredFail)
+	require.False(t, skipped)
+
+	expiredSkip := &run.TaskGate{Decision: models.ApprovalDecisionExpired, OnTimeout: jobdefschema.GateOnTimeoutSkip, Reason: "approval timed out"}
+	require.NoError(t, Failure(expiredSkip))
+	reason, skipped := Skipped(expiredSkip)
+	require.True(t, skipped)
+	require.Equal(t, "approval gate expired: approval timed out", reason)
+}
+
+func TestSweepOnceExpiresOverdueGatesOnLeader(t *testing.T) {
+	store, runID, taskID := setupGatedRun(t)
+	_, err := store.AwaitApproval(runID, taskID, "", run.GateRequest{Timeout: time.Minute, OnTimeout: jobdefschema.GateOnTimeoutFail})
+	require.NoError(t, err)
+
+	leader := false
+	sweeper := NewSweeper(store, func(context.Context) (bool, error) { return leader, nil }, 0)
+	sweeper.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	require.NoError(t, sweeper.SweepOnce(context.Background()))
+	g, err := store.TaskGate(runID, taskID)
+	require.NoError(t, err)
+	require.True(t, g.Pending(), "followers must not expire gates")
+
+	leader = true
+	require.NoError(t, sweeper.SweepOnce(context.Background()))
+	g, err = store.TaskGate(runID, taskID)
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalDecisionExpired, g.Decision)
+}
+
+func TestSweepOncePropagatesLeaderCheckError(t *testing.T) {
+	sweeper := NewSweeper(nil, func(context.Context) (bool, error) { return false, gorm.ErrInvalidDB }, time.Second)
+	require.ErrorIs(t, sweeper.SweepOnce(context.Background()), gorm.ErrInvalidDB)
+}
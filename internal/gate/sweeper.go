@@ -0,0 +1,76 @@
+package gate
+
+import (
+	"context"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/log"
+)
+
+// LeaderCheck reports whether this node should run the sweep. It matches
+// dqlite.IsLocalLeader so only one node in a cluster expires gates.
+type LeaderCheck func(context.Context) (bool, error)
+
+// Sweeper is the leader-gated gate-timeout enforcer. It periodically expires
+// pending approval requests whose deadline has passed; the executor waiting
+// on each one then applies the step's onTimeout action. Deadlines live on
+// the task_approvals rows, so a timeout survives restarts and failover.
+type Sweeper struct {
+	store       *run.Store
+	leaderCheck LeaderCheck
+	interval    time.Duration
+	now         func() time.Time
+}
+
+// NewSweeper constructs the sweeper. A zero interval defaults to 15s.
+func NewSweeper(store *run.Store, leaderCheck LeaderCheck, interval time.Duration) *Sweeper {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	return &Sweeper{
+		store:       store,
+		leaderCheck: leaderCheck,
+		interval:    interval,
+		now:         time.Now,
+	}
+}
+
+// Run drives the sweep loop until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SweepOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Error("approval gate sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// SweepOnce expires every overdue approval request once. It is a no-op on
+// nodes that are not the leader.
+func (s *Sweeper) SweepOnce(ctx context.Context) error {
+	if s.leaderCheck != nil {
+		leader, err := s.leaderCheck(ctx)
+		if err != nil {
+			return err
+		}
+		if !leader {
+			return nil
+		}
+	}
+
+	expired, err := s.store.ExpireGates(ctx, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		log.Info("expired approval gates", "count", expired)
+	}
+	return nil
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
 // ErrDigestUnavailable is returned when a digest cannot be resolved for an
@@ -274,6 +277,23 @@ func dockerDigestFunc(ctx context.Context, imageRef string) (string, error) {
 	return digest, nil
 }
 
+// RegistryDigest resolves a digest by asking the image's registry for the
+// manifest, with a HEAD request and no pull. Credentials come from the
+// Docker config keychain (~/.docker/config.json and credential helpers).
+// Unlike the Docker engine's digest, which may be a local config digest,
+// this is always the registry manifest digest, the one signatures name.
+func RegistryDigest(ctx context.Context, imageRef string) (string, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return "", fmt.Errorf("registry: %w", err)
+	}
+	return desc.Digest.String(), nil
+}
+
 // dockerInspectDigest returns a content-addressed digest for an image. It
 // prefers a RepoDigest (the registry manifest digest, stable across hosts), and
 // falls back to the image's own config digest (inspect.ID) when there are no
@@ -0,0 +1,115 @@
+package imagepolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/auth"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ActorRuntime is the audit actor for violations found at task start.
+const ActorRuntime = "system:image-policy"
+
+// WarnReportInterval is how often Admit reports the same warned violation.
+// Denials are always reported, since each one fails a task.
+const WarnReportInterval = time.Hour
+
+// Admit checks a task's image when its container spec is built, audits and
+// logs any violation as ActorRuntime, and returns the image the engine
+// should run. A warned violation is reported at most once per
+// WarnReportInterval on each node. The error wraps ErrDenied when an
+// enforcing policy rejects the image.
+func (p *Policy) Admit(ctx context.Context, db *gorm.DB, req Request) (string, error) {
+	d := p.Check(ctx, req)
+	if len(d.Violations) == 0 {
+		return d.Image, nil
+	}
+	reported := d
+	if !d.Denied {
+		reported.Violations = p.unreportedWarnings(d.Violations, time.Now())
+	}
+	p.Audit(db, ActorRuntime, reported)
+	for _, v := range reported.Violations {
+		log.Warn("image policy violation", "job", v.Job, "step", v.Step, "image", v.Image, "rule", v.Rule, "denied", d.Denied, "message", v.Message)
+	}
+	if err := d.Err(); err != nil {
+		return "", err
+	}
+	return d.Image, nil
+}
+
+// unreportedWarnings returns the violations not reported within
+// WarnReportInterval of now, and records them as reported at now.
+func (p *Policy) unreportedWarnings(violations []Violation, now time.Time) []Violation {
+	p.warnedMu.Lock()
+	defer p.warnedMu.Unlock()
+	if p.warned == nil {
+		p.warned = make(map[Violation]time.Time)
+	}
+	for v, at := range p.warned {
+		if now.Sub(at) >= WarnReportInterval {
+			delete(p.warned, v)
+		}
+	}
+	var out []Violation
+	for _, v := range violations {
+		if _, ok := p.warned[v]; ok {
+			continue
+		}
+		p.warned[v] = now
+		out = append(out, v)
+	}
+	return out
+}
+
+// Labels converts a job's stored labels to the map Request takes.
+func Labels(stored datatypes.JSONMap) map[string]string {
+	if len(stored) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(stored))
+	for key, value := range stored {
+		if s, ok := value.(string); ok {
+			labels[key] = s
+		} else {
+			labels[key] = fmt.Sprint(value)
+		}
+	}
+	return labels
+}
+
+// Audit writes one audit entry per violation in d. Violations are recorded
+// as denied under an enforcing policy and as warned otherwise. Write
+// failures are logged; auditing never changes the decision.
+func (p *Policy) Audit(db *gorm.DB, actor string, d Decision) {
+	if db == nil || len(d.Violations) == 0 {
+		return
+	}
+	outcome := auth.OutcomeWarned
+	if d.Denied {
+		outcome = auth.OutcomeDenied
+	}
+	logger := auth.NewAuditLogger(db)
+	for _, v := range d.Violations {
+		if err := logger.Log(auth.AuditEntry{
+			Actor:        actor,
+			Action:       auth.ActionImagePolicy,
+			ResourceType: "job",
+			ResourceID:   v.Job,
+			Outcome:      outcome,
+			Metadata: map[string]interface{}{
+				"rule":    string(v.Rule),
+				"step":    v.Step,
+				"image":   v.Image,
+				"mode":    string(p.Mode()),
+				"message": v.Message,
+			},
+		}); err != nil {
+			log.Warn("failed to write image policy audit entry", "job", v.Job, "step", v.Step, "error", err)
+		}
+	}
+}
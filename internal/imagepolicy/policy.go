@@ -0,0 +1,337 @@
+// Package imagepolicy decides which container images jobs may run. A policy
+// restricts images to allowed repositories, denies mutable tags such as
+// latest, requires digest pins for jobs carrying configured labels, and can
+// require a cosign signature from a configured public key. The same policy
+// is checked when a definition is applied (and linted), and again when a
+// task's container spec is built, so an image that passed apply but was
+// re-tagged or re-signed since is still caught before it is pulled.
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/distribution/reference"
+)
+
+// Mode selects what a policy does with a violation.
+type Mode string
+
+const (
+	// ModeOff disables the policy; every image is admitted unchecked.
+	ModeOff Mode = ""
+	// ModeWarn admits violating images, reporting violations as lint warnings
+	// and audit entries with outcome "warned".
+	ModeWarn Mode = "warn"
+	// ModeEnforce rejects violating definitions at apply time and fails
+	// tasks whose image violates the policy at task start.
+	ModeEnforce Mode = "enforce"
+)
+
+// Rule names the policy rule a violation breaks.
+type Rule string
+
+const (
+	RuleInvalidReference    Rule = "invalid_reference"
+	RuleRegistryNotAllowed  Rule = "registry_not_allowed"
+	RuleTagDenied           Rule = "tag_denied"
+	RuleDigestRequired      Rule = "digest_required"
+	RuleSignatureUnverified Rule = "signature_unverified"
+	RuleMisconfigured       Rule = "policy_misconfigured"
+)
+
+// ErrDenied is wrapped by Decision.Err when an enforcing policy rejects an
+// image.
+var ErrDenied = errors.New("image denied by admission policy")
+
+// Violation is one rule an image breaks.
+type Violation struct {
+	Rule    Rule   `json:"rule"`
+	Job     string `json:"job,omitempty"`
+	Step    string `json:"step,omitempty"`
+	Image   string `json:"image"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Step == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("step %q: %s", v.Step, v.Message)
+}
+
+// Request is one image to admit, with the job context the rules need.
+type Request struct {
+	Job    string
+	Step   string
+	Image  string
+	Labels map[string]string
+}
+
+// Decision is the outcome of checking one image.
+type Decision struct {
+	// Image is the reference to run. When a signature was verified it is
+	// pinned to the verified digest, so the engine pulls exactly what was
+	// checked even if the tag moves afterwards.
+	Image      string
+	Violations []Violation
+	// Denied is set when the policy enforces and found a violation.
+	Denied bool
+}
+
+// Err returns an error wrapping ErrDenied when the decision denies the
+// image, and nil otherwise.
+func (d Decision) Err() error {
+	if !d.Denied {
+		return nil
+	}
+	msgs := make([]string, 0, len(d.Violations))
+	for _, v := range d.Violations {
+		msgs = append(msgs, v.Message)
+	}
+	return fmt.Errorf("%w: %s", ErrDenied, strings.Join(msgs, "; "))
+}
+
+// Config is a policy's rules. Repository patterns match the normalized
+// repository name, so Docker Hub images are docker.io/library/nginx rather
+// than nginx. A pattern is a path.Match glob, and a trailing "/**" matches
+// any repository below the prefix.
+type Config struct {
+	Mode Mode
+	// AllowedRepos lists repository patterns images must match. Empty allows
+	// every repository.
+	AllowedRepos []string
+	// DeniedTags lists tags that may not be run. An untagged reference is
+	// the latest tag. Digest-pinned references are never tag-checked.
+	DeniedTags []string
+	// RequireDigestLabels maps job label keys to values. A job carrying any
+	// of these labels must pin every image by digest.
+	RequireDigestLabels map[string]string
+	// SignedRepos lists repository patterns whose images must carry a cosign
+	// signature verified by Verifier.
+	SignedRepos []string
+	Verifier    *Verifier
+}
+
+// Policy checks images against a Config. A nil *Policy admits everything.
+type Policy struct {
+	cfg Config
+	// err is set on a policy that failed to load. It denies every image
+	// rather than silently running unchecked ones.
+	err error
+
+	// warned records when Admit last reported each warned violation, so a
+	// job that runs every minute does not audit the same finding each time.
+	warnedMu sync.Mutex
+	warned   map[Violation]time.Time
+}
+
+// New validates cfg and builds a policy.
+func New(cfg Config) (*Policy, error) {
+	switch cfg.Mode {
+	case ModeOff, ModeWarn, ModeEnforce:
+	default:
+		return nil, fmt.Errorf("image policy: unknown mode %q", cfg.Mode)
+	}
+	for _, pattern := range append(slices.Clone(cfg.AllowedRepos), cfg.SignedRepos...) {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return nil, fmt.Errorf("image policy: invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+	if len(cfg.SignedRepos) > 0 && cfg.Verifier == nil {
+		return nil, errors.New("image policy: signed repositories require signature keys")
+	}
+	return &Policy{cfg: cfg}, nil
+}
+
+// FromEnv builds the policy configured by the CAESIUM_IMAGE_POLICY_*
+// variables. A disabled policy is returned as nil.
+func FromEnv(vars env.Environment) (*Policy, error) {
+	mode := Mode(strings.ToLower(strings.TrimSpace(vars.ImagePolicyMode)))
+	if mode == ModeOff {
+		return nil, nil
+	}
+	labels, err := parseLabels(vars.ImagePolicyRequireDigestLabels)
+	if err != nil {
+		return nil, err
+	}
+	cfg := Config{
+		Mode:                mode,
+		AllowedRepos:        splitList(vars.ImagePolicyAllowedRepos),
+		DeniedTags:          splitList(vars.ImagePolicyDeniedTags),
+		RequireDigestLabels: labels,
+		SignedRepos:         splitList(vars.ImagePolicySignedRepos),
+	}
+	if keys := splitList(vars.ImagePolicySignatureKeys); len(keys) > 0 {
+		if cfg.Verifier, err = LoadVerifier(keys); err != nil {
+			return nil, err
+		}
+	}
+	return New(cfg)
+}
+
+var (
+	defaultPolicy     *Policy
+	defaultPolicyOnce sync.Once
+)
+
+// Default returns the policy configured by the environment, built once. A
+// policy that fails to load is logged and denies every image, so a typo in
+// a key path never turns enforcement off.
+func Default() *Policy {
+	defaultPolicyOnce.Do(func() {
+		vars := env.Variables()
+		policy, err := FromEnv(vars)
+		if err != nil {
+			log.Error("image policy failed to load; denying all images", "error", err)
+			policy = &Policy{cfg: Config{Mode: ModeEnforce}, err: err}
+		}
+		defaultPolicy = policy
+	})
+	return defaultPolicy
+}
+
+// Mode reports the policy's mode; a nil policy is ModeOff.
+func (p *Policy) Mode() Mode {
+	if p == nil {
+		return ModeOff
+	}
+	return p.cfg.Mode
+}
+
+// Check evaluates one image. Empty images (sensor steps run no container)
+// and disabled policies yield no violations. Signature verification contacts
+// the image's registry, and is skipped for an image whose repository is not
+// allowed.
+func (p *Policy) Check(ctx context.Context, req Request) (d Decision) {
+	d.Image = req.Image
+	image := strings.TrimSpace(req.Image)
+	if p.Mode() == ModeOff || image == "" {
+		return d
+	}
+	violate := func(rule Rule, format string, args ...any) {
+		d.Violations = append(d.Violations, Violation{
+			Rule:    rule,
+			Job:     req.Job,
+			Step:    req.Step,
+			Image:   image,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	defer func() { d.Denied = len(d.Violations) > 0 && p.cfg.Mode == ModeEnforce }()
+
+	if p.err != nil {
+		violate(RuleMisconfigured, "image policy failed to load: %v", p.err)
+		return d
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		violate(RuleInvalidReference, "image %q is not a valid reference: %v", image, err)
+		return d
+	}
+	repo := named.Name()
+
+	allowed := len(p.cfg.AllowedRepos) == 0 || matchAny(p.cfg.AllowedRepos, repo)
+	if !allowed {
+		violate(RuleRegistryNotAllowed, "image %q is from repository %s, which is not in the allowed list", image, repo)
+	}
+
+	if _, pinned := named.(reference.Digested); !pinned {
+		tag := "latest"
+		if tagged, ok := named.(reference.Tagged); ok {
+			tag = tagged.Tag()
+		}
+		if slices.Contains(p.cfg.DeniedTags, tag) {
+			violate(RuleTagDenied, "image %q uses denied tag %q", image, tag)
+		}
+		if key, value, ok := p.requiresDigest(req.Labels); ok {
+			violate(RuleDigestRequired, "image %q is not pinned by digest; jobs labelled %s=%s must use image@sha256:...", image, key, value)
+		}
+	}
+
+	if allowed && matchAny(p.cfg.SignedRepos, repo) {
+		digest, err := p.cfg.Verifier.Verify(ctx, named)
+		if err != nil {
+			violate(RuleSignatureUnverified, "image %q has no valid signature: %v", image, err)
+		} else {
+			d.Image = pin(named, digest)
+		}
+	}
+	return d
+}
+
+// requiresDigest reports the first configured label the job carries that
+// requires digest pins.
+func (p *Policy) requiresDigest(labels map[string]string) (string, string, bool) {
+	keys := make([]string, 0, len(p.cfg.RequireDigestLabels))
+	for key := range p.cfg.RequireDigestLabels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if value, ok := labels[key]; ok && value == p.cfg.RequireDigestLabels[key] {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// pin returns named pinned to digest, keeping its tag for readability.
+func pin(named reference.Named, digest string) string {
+	if _, ok := named.(reference.Digested); ok {
+		return reference.FamiliarString(named)
+	}
+	return reference.FamiliarString(named) + "@" + digest
+}
+
+// matchAny reports whether repo matches one of patterns.
+func matchAny(patterns []string, repo string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if matched, _ := path.Match(prefix, repo); matched {
+				return true
+			}
+			for dir := path.Dir(repo); dir != "." && dir != "/"; dir = path.Dir(dir) {
+				if matched, _ := path.Match(prefix, dir); matched {
+					return true
+				}
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, repo); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func parseLabels(raw string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, item := range splitList(raw) {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("image policy: digest label %q must be key=value", item)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
@@ -0,0 +1,156 @@
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func testPolicy(t *testing.T, cfg Config) *Policy {
+	t.Helper()
+	p, err := New(cfg)
+	require.NoError(t, err)
+	return p
+}
+
+func rules(d Decision) []Rule {
+	out := make([]Rule, 0, len(d.Violations))
+	for _, v := range d.Violations {
+		out = append(out, v.Rule)
+	}
+	return out
+}
+
+func TestCheckAllowedRepos(t *testing.T) {
+	p := testPolicy(t, Config{
+		Mode:         ModeEnforce,
+		AllowedRepos: []string{"ghcr.io/acme/**", "docker.io/library/*"},
+	})
+
+	cases := map[string]bool{
+		"nginx:1.27":                         true,
+		"docker.io/library/python:3.12-slim": true,
+		"ghcr.io/acme/etl:1.4":               true,
+		"ghcr.io/acme/team/loader:2":         true,
+		"ghcr.io/other/etl:1.4":              false,
+		"quay.io/acme/etl:1.4":               false,
+		"bitnami/postgresql:16":              false,
+	}
+	for image, allowed := range cases {
+		t.Run(image, func(t *testing.T) {
+			d := p.Check(context.Background(), Request{Image: image})
+			if allowed {
+				require.Empty(t, d.Violations)
+				require.False(t, d.Denied)
+				return
+			}
+			require.Equal(t, []Rule{RuleRegistryNotAllowed}, rules(d))
+			require.True(t, d.Denied)
+			require.ErrorIs(t, d.Err(), ErrDenied)
+		})
+	}
+}
+
+func TestCheckDeniedTags(t *testing.T) {
+	p := testPolicy(t, Config{Mode: ModeEnforce, DeniedTags: []string{"latest", "dev"}})
+
+	require.Equal(t, []Rule{RuleTagDenied}, rules(p.Check(context.Background(), Request{Image: "nginx"})))
+	require.Equal(t, []Rule{RuleTagDenied}, rules(p.Check(context.Background(), Request{Image: "nginx:latest"})))
+	require.Equal(t, []Rule{RuleTagDenied}, rules(p.Check(context.Background(), Request{Image: "ghcr.io/acme/etl:dev"})))
+	require.Empty(t, p.Check(context.Background(), Request{Image: "nginx:1.27"}).Violations)
+	// A digest pin makes the tag irrelevant.
+	require.Empty(t, p.Check(context.Background(), Request{Image: "nginx:latest@" + testDigest}).Violations)
+}
+
+func TestCheckDigestRequiredByLabel(t *testing.T) {
+	p := testPolicy(t, Config{
+		Mode:                ModeWarn,
+		RequireDigestLabels: map[string]string{"environment": "production"},
+	})
+	prod := map[string]string{"environment": "production", "team": "data"}
+
+	d := p.Check(context.Background(), Request{Job: "nightly", Step: "load", Image: "nginx:1.27", Labels: prod})
+	require.Equal(t, []Rule{RuleDigestRequired}, rules(d))
+	require.False(t, d.Denied, "warn mode never denies")
+	require.NoError(t, d.Err())
+	require.Equal(t, "nightly", d.Violations[0].Job)
+	require.Contains(t, d.Violations[0].String(), `step "load"`)
+
+	require.Empty(t, p.Check(context.Background(), Request{Image: "nginx@" + testDigest, Labels: prod}).Violations)
+	require.Empty(t, p.Check(context.Background(), Request{Image: "nginx:1.27", Labels: map[string]string{"environment": "staging"}}).Violations)
+}
+
+func TestCheckSkipsEmptyImageAndDisabledPolicy(t *testing.T) {
+	p := testPolicy(t, Config{Mode: ModeEnforce, AllowedRepos: []string{"ghcr.io/acme/*"}})
+	require.Empty(t, p.Check(context.Background(), Request{Image: ""}).Violations)
+
+	var disabled *Policy
+	d := disabled.Check(context.Background(), Request{Image: "nginx:latest"})
+	require.Empty(t, d.Violations)
+	require.Equal(t, "nginx:latest", d.Image)
+	require.Equal(t, ModeOff, disabled.Mode())
+}
+
+func TestCheckInvalidReference(t *testing.T) {
+	p := testPolicy(t, Config{Mode: ModeEnforce})
+	require.Equal(t, []Rule{RuleInvalidReference}, rules(p.Check(context.Background(), Request{Image: "Not A Valid/Image"})))
+}
+
+func TestFailedPolicyDeniesEverything(t *testing.T) {
+	p := &Policy{cfg: Config{Mode: ModeEnforce}, err: errors.New("bad key")}
+	d := p.Check(context.Background(), Request{Image: "nginx:1.27"})
+	require.Equal(t, []Rule{RuleMisconfigured}, rules(d))
+	require.True(t, d.Denied)
+}
+
+func TestAdmitReportsWarningsOncePerInterval(t *testing.T) {
+	p := testPolicy(t, Config{Mode: ModeWarn, DeniedTags: []string{"latest"}})
+	image, err := p.Admit(context.Background(), nil, Request{Job: "nightly", Step: "load", Image: "nginx:latest"})
+	require.NoError(t, err)
+	require.Equal(t, "nginx:latest", image)
+
+	d := p.Check(context.Background(), Request{Job: "nightly", Step: "load", Image: "nginx:latest"})
+	other := p.Check(context.Background(), Request{Job: "nightly", Step: "extract", Image: "nginx:latest"})
+	now := time.Now()
+	require.Empty(t, p.unreportedWarnings(d.Violations, now), "Admit already reported it")
+	require.Equal(t, other.Violations, p.unreportedWarnings(other.Violations, now))
+	require.Empty(t, p.unreportedWarnings(d.Violations, now.Add(WarnReportInterval/2)))
+	require.Equal(t, d.Violations, p.unreportedWarnings(d.Violations, now.Add(WarnReportInterval)))
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+	_, err := New(Config{Mode: "block"})
+	require.Error(t, err)
+	_, err = New(Config{Mode: ModeEnforce, AllowedRepos: []string{"ghcr.io/[acme"}})
+	require.Error(t, err)
+	_, err = New(Config{Mode: ModeEnforce, SignedRepos: []string{"ghcr.io/acme/*"}})
+	require.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	p, err := FromEnv(env.Environment{})
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	p, err = FromEnv(env.Environment{
+		ImagePolicyMode:                "Enforce",
+		ImagePolicyAllowedRepos:        "ghcr.io/acme/*, docker.io/library/*",
+		ImagePolicyDeniedTags:          "latest",
+		ImagePolicyRequireDigestLabels: "environment=production,tier=critical",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ModeEnforce, p.Mode())
+	require.Equal(t, []string{"ghcr.io/acme/*", "docker.io/library/*"}, p.cfg.AllowedRepos)
+	require.Equal(t, map[string]string{"environment": "production", "tier": "critical"}, p.cfg.RequireDigestLabels)
+
+	_, err = FromEnv(env.Environment{ImagePolicyMode: "warn", ImagePolicyRequireDigestLabels: "production"})
+	require.Error(t, err)
+	_, err = FromEnv(env.Environment{ImagePolicyMode: "warn", ImagePolicySignatureKeys: "/nonexistent/cosign.pub"})
+	require.Error(t, err)
+}
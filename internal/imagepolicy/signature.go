@@ -0,0 +1,254 @@
+package imagepolicy
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/imagecheck"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/distribution/reference"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Signatures follow cosign's key-based layout: the signature for manifest
+// sha256:<hex> is an OCI image tagged sha256-<hex>.sig in the same
+// repository. Each of its layers is a "simple signing" JSON payload naming
+// the signed manifest digest, and the layer's cosign signature annotation
+// holds the base64 signature over that payload. Keyless (Fulcio/Rekor)
+// signatures are not supported.
+
+const (
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignSignatureType       = "cosign container image signature"
+
+	// registryEngine keys the verifier's digest resolver. Verification always
+	// resolves against the registry, never through a local engine, because a
+	// signature names the registry manifest digest.
+	registryEngine models.AtomEngine = "registry"
+
+	// digestTTL bounds how long a tag's resolved digest is reused.
+	digestTTL = time.Minute
+	// verifiedTTL bounds how long a verified digest skips re-verification.
+	verifiedTTL = 10 * time.Minute
+	// maxPayloadSize caps a signature payload read from a registry.
+	maxPayloadSize = 1 << 20
+)
+
+// Verifier checks cosign signatures on images against a set of public keys.
+// It is safe for concurrent use.
+type Verifier struct {
+	keys     []crypto.PublicKey
+	resolver *imagecheck.Resolver
+	now      func() time.Time
+
+	mu       sync.Mutex
+	verified map[string]time.Time
+}
+
+// NewVerifier builds a verifier for keys. Supported keys are ECDSA (cosign's
+// default), Ed25519, and RSA.
+func NewVerifier(keys ...crypto.PublicKey) (*Verifier, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("image policy: no signature keys")
+	}
+	for _, key := range keys {
+		switch key.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("image policy: unsupported signature key type %T", key)
+		}
+	}
+	return &Verifier{
+		keys:     keys,
+		resolver: imagecheck.NewResolver(imagecheck.WithEngineDigestFunc(registryEngine, imagecheck.RegistryDigest)),
+		now:      time.Now,
+		verified: make(map[string]time.Time),
+	}, nil
+}
+
+// LoadVerifier builds a verifier from PEM public key files, such as the
+// cosign.pub written by `cosign generate-key-pair`.
+func LoadVerifier(paths []string) (*Verifier, error) {
+	var keys []crypto.PublicKey
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("image policy: read signature key: %w", err)
+		}
+		parsed, err := ParsePublicKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("image policy: %s: %w", p, err)
+		}
+		keys = append(keys, parsed...)
+	}
+	return NewVerifier(keys...)
+}
+
+// ParsePublicKeys parses every PEM "PUBLIC KEY" block in data.
+func ParsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM public key found")
+	}
+	return keys, nil
+}
+
+// Verify resolves named to its registry manifest digest and checks that a
+// configured key signed that digest. It returns the verified digest.
+func (v *Verifier) Verify(ctx context.Context, named reference.Named) (string, error) {
+	digest, err := v.resolver.Resolve(ctx, registryEngine, named.String(), digestTTL)
+	if err != nil {
+		return "", fmt.Errorf("resolve digest: %w", err)
+	}
+
+	key := named.Name() + "@" + digest
+	v.mu.Lock()
+	verifiedAt, ok := v.verified[key]
+	v.mu.Unlock()
+	if ok && v.now().Sub(verifiedAt) < verifiedTTL {
+		return digest, nil
+	}
+
+	if err := v.verifyDigest(ctx, named.Name(), digest); err != nil {
+		return "", err
+	}
+	v.mu.Lock()
+	v.verified[key] = v.now()
+	v.mu.Unlock()
+	return digest, nil
+}
+
+func (v *Verifier) verifyDigest(ctx context.Context, repository, digest string) error {
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return err
+	}
+	tag := repo.Tag(strings.Replace(digest, ":", "-", 1) + ".sig")
+	img, err := remote.Image(tag, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("no signature for %s", digest)
+		}
+		return fmt.Errorf("fetch signature: %w", err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return fmt.Errorf("fetch signature: %w", err)
+	}
+
+	checked := false
+	for _, desc := range manifest.Layers {
+		encoded := desc.Annotations[cosignSignatureAnnotation]
+		if encoded == "" {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return fmt.Errorf("fetch signature payload: %w", err)
+		}
+		payload, err := readPayload(layer.Compressed)
+		if err != nil {
+			return fmt.Errorf("fetch signature payload: %w", err)
+		}
+		checked = true
+		if v.signedBy(payload, sig) && payloadNames(payload, digest) {
+			return nil
+		}
+	}
+	if !checked {
+		return fmt.Errorf("no signature for %s", digest)
+	}
+	return fmt.Errorf("no signature for %s verifies with a configured key", digest)
+}
+
+func readPayload(open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(io.LimitReader(rc, maxPayloadSize))
+}
+
+// signedBy reports whether any configured key produced sig over payload.
+func (v *Verifier) signedBy(payload, sig []byte) bool {
+	hash := sha256.Sum256(payload)
+	for _, key := range v.keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, hash[:], sig) {
+				return true
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, payload, sig) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], sig) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// simpleSigning is the part of a cosign signature payload the verifier
+// checks.
+type simpleSigning struct {
+	Critical struct {
+		Type  string `json:"type"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// payloadNames reports whether payload is a cosign signature payload for
+// digest. Without this check a valid signature of one image could be copied
+// onto another.
+func payloadNames(payload []byte, digest string) bool {
+	var p simpleSigning
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return false
+	}
+	return p.Critical.Type == cosignSignatureType && p.Critical.Image.DockerManifestDigest == digest
+}
@@ -0,0 +1,161 @@
+package imagepolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegistry is an in-memory OCI registry holding images and cosign-style
+// signatures.
+type testRegistry struct {
+	t    *testing.T
+	host string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(stdlog.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	return &testRegistry{t: t, host: strings.TrimPrefix(srv.URL, "http://")}
+}
+
+// push writes a random image to repo:tag and returns its manifest digest.
+func (r *testRegistry) push(repoTag string) v1.Hash {
+	r.t.Helper()
+	img, err := random.Image(256, 1)
+	require.NoError(r.t, err)
+	ref, err := name.ParseReference(r.host + "/" + repoTag)
+	require.NoError(r.t, err)
+	require.NoError(r.t, remote.Write(ref, img))
+	digest, err := img.Digest()
+	require.NoError(r.t, err)
+	return digest
+}
+
+// sign attaches a signature by key over a payload naming signedDigest to
+// the signature tag of target.
+func (r *testRegistry) sign(repo string, target, signedDigest v1.Hash, key *ecdsa.PrivateKey) {
+	r.t.Helper()
+	payload := fmt.Appendf(nil, `{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":%q},"optional":null}`,
+		r.host+"/"+repo, signedDigest.String(), cosignSignatureType)
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	require.NoError(r.t, err)
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, types.MediaType("application/vnd.dev.cosign.simplesigning.v1+json")),
+		Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+	})
+	require.NoError(r.t, err)
+	tag, err := name.NewTag(fmt.Sprintf("%s/%s:%s-%s.sig", r.host, repo, target.Algorithm, target.Hex))
+	require.NoError(r.t, err)
+	require.NoError(r.t, remote.Write(tag, img))
+}
+
+func testSigningKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func signedPolicy(t *testing.T, reg *testRegistry, key *ecdsa.PrivateKey) *Policy {
+	t.Helper()
+	verifier, err := NewVerifier(&key.PublicKey)
+	require.NoError(t, err)
+	return testPolicy(t, Config{
+		Mode:        ModeEnforce,
+		SignedRepos: []string{reg.host + "/acme/*"},
+		Verifier:    verifier,
+	})
+}
+
+func TestCheckVerifiesSignatureAndPinsDigest(t *testing.T) {
+	reg := newTestRegistry(t)
+	key := testSigningKey(t)
+	digest := reg.push("acme/etl:1.4")
+	reg.sign("acme/etl", digest, digest, key)
+
+	p := signedPolicy(t, reg, key)
+	image := reg.host + "/acme/etl:1.4"
+	d := p.Check(context.Background(), Request{Image: image})
+	require.Empty(t, d.Violations)
+	require.Equal(t, image+"@"+digest.String(), d.Image)
+
+	// A digest-pinned reference verifies without a tag lookup.
+	pinned := reg.host + "/acme/etl@" + digest.String()
+	d = p.Check(context.Background(), Request{Image: pinned})
+	require.Empty(t, d.Violations)
+	require.Equal(t, pinned, d.Image)
+
+	// Repositories outside SignedRepos are not checked.
+	reg.push("other/etl:1.4")
+	require.Empty(t, p.Check(context.Background(), Request{Image: reg.host + "/other/etl:1.4"}).Violations)
+}
+
+func TestCheckRejectsUnverifiedSignatures(t *testing.T) {
+	reg := newTestRegistry(t)
+	key := testSigningKey(t)
+	p := signedPolicy(t, reg, key)
+
+	reg.push("acme/unsigned:1")
+
+	wrongKey := reg.push("acme/wrong-key:1")
+	reg.sign("acme/wrong-key", wrongKey, wrongKey, testSigningKey(t))
+
+	// A genuine signature for one image copied onto another must not verify.
+	original := reg.push("acme/original:1")
+	copied := reg.push("acme/copied:1")
+	reg.sign("acme/copied", copied, original, key)
+
+	for _, repo := range []string{"acme/unsigned:1", "acme/wrong-key:1", "acme/copied:1"} {
+		t.Run(repo, func(t *testing.T) {
+			d := p.Check(context.Background(), Request{Image: reg.host + "/" + repo})
+			require.Equal(t, []Rule{RuleSignatureUnverified}, rules(d))
+			require.True(t, d.Denied)
+			require.Equal(t, reg.host+"/"+repo, d.Image)
+		})
+	}
+}
+
+func TestLoadVerifierFromPEM(t *testing.T) {
+	key := testSigningKey(t)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "cosign.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	verifier, err := LoadVerifier([]string{path})
+	require.NoError(t, err)
+	require.Len(t, verifier.keys, 1)
+
+	notKey := filepath.Join(t.TempDir(), "empty.pub")
+	require.NoError(t, os.WriteFile(notKey, []byte("not a key"), 0o600))
+	_, err = LoadVerifier([]string{notKey})
+	require.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/caesium-cloud/caesium/internal/lineage"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -28,15 +29,17 @@ import (
 //
 // FreezeAllowlist is best-effort with respect to lineage availability: if the
 // lineage graph is empty or unavailable (e.g. OpenLineage disabled), it returns
-// just the failing job's own alias rather than failing incident open.
+// just the failing job's own alias rather than failing incident open. Entries
+// are qualified as by jobdef.QualifiedAlias, since aliases are only unique
+// within a namespace.
 func FreezeAllowlist(ctx context.Context, db *gorm.DB, jobID uuid.UUID, failingRunID *uuid.UUID) []string {
 	allow := map[string]struct{}{}
 
 	// The incident's own job is always in scope.
 	var job models.Job
-	if err := db.WithContext(ctx).Select("alias").First(&job, "id = ?", jobID).Error; err == nil {
+	if err := db.WithContext(ctx).Select("namespace", "alias").First(&job, "id = ?", jobID).Error; err == nil {
 		if job.Alias != "" {
-			allow[job.Alias] = struct{}{}
+			allow[jobdef.QualifiedAlias(job.Namespace, job.Alias)] = struct{}{}
 		}
 	} else {
 		log.Debug("incident: freeze allowlist could not resolve job alias", "job_id", jobID, "error", err)
@@ -60,7 +63,7 @@ func FreezeAllowlist(ctx context.Context, db *gorm.DB, jobID uuid.UUID, failingR
 		}
 		for _, node := range impact.Downstream {
 			if node.JobAlias != "" {
-				allow[node.JobAlias] = struct{}{}
+				allow[jobdef.QualifiedAlias(node.JobNamespace, node.JobAlias)] = struct{}{}
 			}
 		}
 	}
@@ -30,8 +30,9 @@ const (
 	ClassDataUnavailable FailureClass = "data_unavailable"
 	// ClassAuthFailure covers credential/permission failures.
 	ClassAuthFailure FailureClass = "auth_failure"
-	// ClassOOM covers out-of-memory kills. Best-effort until the OOM-flag
-	// detection from design-resource-right-sizing lands.
+	// ClassOOM covers out-of-memory kills: engines report an OOM-flagged
+	// container as ResourceFailure with exit 137, and the exit-code and log
+	// rules catch the rest.
 	ClassOOM FailureClass = "oom"
 	// ClassQuota covers rate limits / quota exhaustion.
 	ClassQuota FailureClass = "quota"
@@ -137,7 +138,8 @@ func (c *Classifier) WithLogRule(pattern string, class FailureClass) (*Classifie
 //
 //  1. run_timed_out / sla_missed          → sla_risk
 //  2. schema_violation event / violations → schema_violation
-//  3. StartupFailure / ResourceFailure    → transient_infra
+//  3. ResourceFailure with exit 137       → oom
+//     StartupFailure / ResourceFailure    → transient_infra
 //  4. log-tail regex table                → data_unavailable|auth_failure|oom|quota
 //  5. exit-code table                     → (default 137 → oom)
 //  6. fallback                            → unknown
@@ -154,7 +156,12 @@ func (c *Classifier) Classify(sig Signal) FailureClass {
 	}
 
 	switch atom.Result(sig.Result) {
-	case atom.StartupFailure, atom.ResourceFailure:
+	case atom.ResourceFailure:
+		if sig.ExitCode != nil && *sig.ExitCode == 137 {
+			return ClassOOM
+		}
+		return ClassTransientInfra
+	case atom.StartupFailure:
 		return ClassTransientInfra
 	}
 
@@ -22,6 +22,7 @@ func TestClassifyStructuredSignals(t *testing.T) {
 		{"schema_flag", Signal{EventType: string(event.TypeTaskFailed), HasSchemaViolations: true}, ClassSchemaViolation},
 		{"startup_failure", Signal{EventType: string(event.TypeTaskFailed), Result: string(atom.StartupFailure)}, ClassTransientInfra},
 		{"resource_failure", Signal{EventType: string(event.TypeTaskFailed), Result: string(atom.ResourceFailure)}, ClassTransientInfra},
+		{"resource_failure_oom", Signal{EventType: string(event.TypeTaskFailed), Result: string(atom.ResourceFailure), ExitCode: intp(137)}, ClassOOM},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1,24 +1,15 @@
 package job
 
 import (
-	"context"
-	"errors"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	backfillstore "github.com/caesium-cloud/caesium/internal/backfill"
-	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
-	runstore "github.com/caesium-cloud/caesium/internal/run"
-	"github.com/caesium-cloud/caesium/pkg/log"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
 	"github.com/robfig/cron"
-	"golang.org/x/sync/semaphore"
 )
 
-const backfillAcquirePollInterval = 250 * time.Millisecond
-
 // EnumerateLogicalDates returns all cron fire times in [start, end).
 // loc sets the timezone used when computing schedule boundaries; pass time.UTC
 // when the trigger has no timezone configured.
@@ -35,6 +26,49 @@ func EnumerateLogicalDates(schedule cron.Schedule, start, end time.Time, loc *ti
 	return dates
 }
 
+// maxFireTimeLookback bounds the backward search in PreviousFireTime. Five
+// years covers every satisfiable five-field expression, including Feb 29.
+const maxFireTimeLookback = 5 * 366 * 24 * time.Hour
+
+// PreviousFireTime returns the last fire time of schedule strictly before t,
+// or the zero time when there is none within the lookback bound. Schedules
+// only enumerate forward, so it widens a window backwards until one fire
+// time lands in it and then walks forward to the last one before t.
+func PreviousFireTime(schedule cron.Schedule, t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	for window := time.Minute; window <= maxFireTimeLookback; window *= 2 {
+		prev := schedule.Next(t.Add(-window).Add(-time.Second))
+		if prev.IsZero() || !prev.Before(t) {
+			continue
+		}
+		for next := schedule.Next(prev); !next.IsZero() && next.Before(t); next = schedule.Next(next) {
+			prev = next
+		}
+		return prev
+	}
+	return time.Time{}
+}
+
+// LogicalDateParams returns the logical_date and data interval params for a
+// run scheduled at logicalDate. The interval ends at the logical date and
+// starts at the schedule's previous fire time; a schedule with no earlier
+// fire time yields an empty interval.
+func LogicalDateParams(schedule cron.Schedule, logicalDate time.Time, loc *time.Location) map[string]string {
+	start := PreviousFireTime(schedule, logicalDate, loc)
+	if start.IsZero() {
+		start = logicalDate
+	}
+	end := logicalDate.UTC().Format(time.RFC3339)
+	return map[string]string{
+		jobdefschema.ParamLogicalDate:       end,
+		jobdefschema.ParamDataIntervalStart: start.UTC().Format(time.RFC3339),
+		jobdefschema.ParamDataIntervalEnd:   end,
+	}
+}
+
 // FilterDates filters logical dates based on the reprocess policy:
 //
 //	"none"   — skip dates that have any existing run
@@ -67,225 +101,3 @@ func FilterDates(store *backfillstore.Store, jobID uuid.UUID, dates []time.Time,
 	}
 	return filtered, nil
 }
-
-type backfillStateReader interface {
-	IsRunning(id uuid.UUID) (bool, error)
-	IsCancelRequested(id uuid.UUID) (bool, error)
-}
-
-func shouldStopBackfill(ctx context.Context, store backfillStateReader, backfillID uuid.UUID) bool {
-	select {
-	case <-ctx.Done():
-		return true
-	default:
-	}
-
-	cancelRequested, err := store.IsCancelRequested(backfillID)
-	if err != nil {
-		log.Warn("backfill: failed to check cancel request", "backfill_id", backfillID, "error", err)
-	} else if cancelRequested {
-		return true
-	}
-
-	running, err := store.IsRunning(backfillID)
-	if err != nil {
-		log.Warn("backfill: failed to check running state", "backfill_id", backfillID, "error", err)
-		return false
-	}
-
-	return !running
-}
-
-func waitForBackfillSlot(
-	ctx context.Context,
-	sem *semaphore.Weighted,
-	shouldStop func() bool,
-) bool {
-	for {
-		if shouldStop() {
-			return false
-		}
-
-		acquireCtx, cancel := context.WithTimeout(ctx, backfillAcquirePollInterval)
-		err := sem.Acquire(acquireCtx, 1)
-		cancel()
-
-		switch {
-		case err == nil:
-			return true
-		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
-			continue
-		default:
-			log.Error("backfill: failed to acquire concurrency slot", "error", err)
-			return false
-		}
-	}
-}
-
-// RunBackfill executes a backfill by enumerating logical dates, filtering by
-// the reprocess policy, and running each date through the standard job executor
-// with a semaphore controlling max concurrency.
-//
-// It honours ctx cancellation: when cancelled, no new runs are started but
-// any in-flight runs are allowed to finish.
-func RunBackfill(
-	ctx context.Context,
-	b *models.Backfill,
-	j *models.Job,
-	schedule cron.Schedule,
-	loc *time.Location,
-) {
-	bStore := backfillstore.Default()
-	rStore := runstore.Default()
-
-	metrics.BackfillsActive.WithLabelValues(j.Alias).Inc()
-	defer metrics.BackfillsActive.WithLabelValues(j.Alias).Dec()
-
-	dates := EnumerateLogicalDates(schedule, b.Start, b.End, loc)
-
-	filtered, err := FilterDates(bStore, b.JobID, dates, b.Reprocess)
-	if err != nil {
-		log.Error("backfill: failed to filter dates", "backfill_id", b.ID, "error", err)
-		if completeErr := bStore.Complete(b.ID, true); completeErr != nil {
-			log.Error("backfill: failed to mark failed", "backfill_id", b.ID, "error", completeErr)
-		}
-		return
-	}
-
-	if err := bStore.SetTotalRuns(b.ID, len(filtered)); err != nil {
-		log.Error("backfill: failed to set total_runs", "backfill_id", b.ID, "error", err)
-	}
-
-	if len(filtered) == 0 {
-		log.Info("backfill: no dates to process", "backfill_id", b.ID)
-		if err := bStore.Complete(b.ID, false); err != nil {
-			log.Error("backfill: failed to complete", "backfill_id", b.ID, "error", err)
-		}
-		return
-	}
-
-	maxConcurrent := b.MaxConcurrent
-	if maxConcurrent <= 0 {
-		maxConcurrent = 1
-	}
-
-	sem := semaphore.NewWeighted(int64(maxConcurrent))
-	var wg sync.WaitGroup
-	var failCount atomic.Int64
-	var completed atomic.Int64
-	cancelled := false
-
-	// Coalesce per-run progress into periodic batched writes. One UPDATE per run
-	// on the same backfills row serializes through dqlite's single writer and can
-	// starve concurrent control-plane writes (e.g. cancellation) past the
-	// busy-retry budget. The flusher persists accumulated deltas ~once a second,
-	// with a final flush below, so the counters lag at most one interval during
-	// the run and are exact once it drains.
-	flushStop := make(chan struct{})
-	flusherDone := make(chan struct{})
-	go func() {
-		defer close(flusherDone)
-		const flushInterval = time.Second
-		ticker := time.NewTicker(flushInterval)
-		defer ticker.Stop()
-		var lastCompleted, lastFailed int64
-		flush := func() {
-			c, f := completed.Load(), failCount.Load()
-			dc, df := c-lastCompleted, f-lastFailed
-			if dc == 0 && df == 0 {
-				return
-			}
-			if err := bStore.AddProgress(b.ID, dc, df); err != nil {
-				log.Error("backfill: failed to flush progress", "backfill_id", b.ID, "error", err)
-				return // keep last* so the delta retries on the next flush
-			}
-			lastCompleted, lastFailed = c, f
-		}
-		for {
-			select {
-			case <-flushStop:
-				flush()
-				return
-			case <-ticker.C:
-				flush()
-			}
-		}
-	}()
-
-	for _, d := range filtered {
-		if shouldStopBackfill(ctx, bStore, b.ID) {
-			cancelled = true
-			break
-		}
-
-		if !waitForBackfillSlot(ctx, sem, func() bool {
-			return shouldStopBackfill(ctx, bStore, b.ID)
-		}) {
-			cancelled = true
-			break
-		}
-
-		if shouldStopBackfill(ctx, bStore, b.ID) {
-			sem.Release(1)
-			cancelled = true
-			break
-		}
-
-		logicalDate := d.UTC().Format(time.RFC3339)
-		params := map[string]string{"logical_date": logicalDate}
-
-		r, err := rStore.StartForBackfill(j.ID, b.ID, params)
-		if err != nil {
-			sem.Release(1)
-			log.Error("backfill: failed to create run", "backfill_id", b.ID, "logical_date", logicalDate, "error", err)
-			failCount.Add(1)
-			continue
-		}
-
-		metrics.BackfillRunsTotal.WithLabelValues(j.Alias, "started").Inc()
-
-		wg.Add(1)
-		runID := r.ID
-		go func(id uuid.UUID, ld string) {
-			defer wg.Done()
-			defer sem.Release(1)
-
-			runCtx := runstore.WithContext(context.Background(), id)
-			runErr := New(j, WithTriggerID(nil), WithParams(params)).Run(runCtx)
-			if runErr != nil {
-				log.Error("backfill: run failed", "backfill_id", b.ID, "logical_date", ld, "run_id", id, "error", runErr)
-				metrics.BackfillRunsTotal.WithLabelValues(j.Alias, "failed").Inc()
-				failCount.Add(1)
-			} else {
-				metrics.BackfillRunsTotal.WithLabelValues(j.Alias, "succeeded").Inc()
-				completed.Add(1)
-			}
-		}(runID, logicalDate)
-	}
-
-	wg.Wait()
-
-	// Stop the flusher and persist any remaining counts before the terminal
-	// status write, so completed_runs/failed_runs are exact once the backfill
-	// finishes.
-	close(flushStop)
-	<-flusherDone
-
-	if cancelled {
-		// Mark terminal cancellation only after the backfill has stopped
-		// launching new runs and all in-flight work has drained.
-		if cancelErr := bStore.MarkCancelled(b.ID); cancelErr != nil {
-			log.Error("backfill: failed to cancel", "backfill_id", b.ID, "error", cancelErr)
-		}
-		return
-	}
-
-	// Only mark complete if the record is still running (not externally cancelled).
-	if running, err := bStore.IsRunning(b.ID); err != nil || !running {
-		return
-	}
-
-	if err := bStore.Complete(b.ID, failCount.Load() > 0); err != nil {
-		log.Error("backfill: failed to complete", "backfill_id", b.ID, "error", err)
-	}
-}
@@ -1,9 +1,7 @@
 package job
 
 import (
-	"context"
 	"encoding/json"
-	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,23 +11,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/robfig/cron"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/sync/semaphore"
 	"gorm.io/datatypes"
 )
 
-type fakeBackfillStateReader struct {
-	running         bool
-	cancelRequested bool
-}
-
-func (f fakeBackfillStateReader) IsRunning(uuid.UUID) (bool, error) {
-	return f.running, nil
-}
-
-func (f fakeBackfillStateReader) IsCancelRequested(uuid.UUID) (bool, error) {
-	return f.cancelRequested, nil
-}
-
 func mustParseCron(t *testing.T, expr string) cron.Schedule {
 	t.Helper()
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
@@ -114,6 +98,41 @@ func TestEnumerateLogicalDates_ExactBoundary(t *testing.T) {
 	}
 }
 
+func TestPreviousFireTime(t *testing.T) {
+	cases := []struct {
+		expr string
+		at   string
+		want string
+	}{
+		{"0 * * * *", "2024-01-01T05:00:00Z", "2024-01-01T04:00:00Z"},
+		{"0 * * * *", "2024-01-01T05:30:00Z", "2024-01-01T05:00:00Z"},
+		{"*/5 * * * *", "2024-01-01T00:00:00Z", "2023-12-31T23:55:00Z"},
+		{"0 0 1 1 *", "2024-01-01T00:00:00Z", "2023-01-01T00:00:00Z"},
+		{"0 0 29 2 *", "2024-03-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+	}
+	for _, tc := range cases {
+		got := PreviousFireTime(mustParseCron(t, tc.expr), mustParseTime(t, tc.at), time.UTC)
+		require.True(t, got.Equal(mustParseTime(t, tc.want)), "%s before %s = %s, want %s", tc.expr, tc.at, got, tc.want)
+	}
+}
+
+func TestPreviousFireTimeHonoursLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// Midnight in New York is 05:00 UTC in winter.
+	got := PreviousFireTime(mustParseCron(t, "0 0 * * *"), mustParseTime(t, "2024-01-02T05:00:00Z"), loc)
+	require.True(t, got.Equal(mustParseTime(t, "2024-01-01T05:00:00Z")), got.String())
+}
+
+func TestLogicalDateParams(t *testing.T) {
+	params := LogicalDateParams(mustParseCron(t, "0 6 * * *"), mustParseTime(t, "2024-03-31T06:00:00Z"), time.UTC)
+	require.Equal(t, map[string]string{
+		"logical_date":        "2024-03-31T06:00:00Z",
+		"data_interval_start": "2024-03-30T06:00:00Z",
+		"data_interval_end":   "2024-03-31T06:00:00Z",
+	}, params)
+}
+
 func TestFilterDatesRespectsReprocessPolicies(t *testing.T) {
 	db := jobdeftestutil.OpenTestDB(t)
 	t.Cleanup(func() { jobdeftestutil.CloseDB(db) })
@@ -128,13 +147,14 @@ func TestFilterDatesRespectsReprocessPolicies(t *testing.T) {
 		require.NoError(t, err)
 
 		run := &models.JobRun{
-			ID:        uuid.New(),
-			JobID:     jobID,
-			Status:    status,
-			Params:    datatypes.JSON(params),
-			StartedAt: createdAt,
-			CreatedAt: createdAt,
-			UpdatedAt: createdAt,
+			ID:          uuid.New(),
+			JobID:       jobID,
+			Status:      status,
+			Params:      datatypes.JSON(params),
+			LogicalDate: logicalDate,
+			StartedAt:   createdAt,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
 		}
 		require.NoError(t, db.Create(run).Error)
 	}
@@ -161,52 +181,3 @@ func TestFilterDatesRespectsReprocessPolicies(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []time.Time{date2, date3}, failed)
 }
-
-func TestShouldStopBackfillHonorsCancelRequest(t *testing.T) {
-	stop := shouldStopBackfill(
-		context.Background(),
-		fakeBackfillStateReader{running: true, cancelRequested: true},
-		uuid.New(),
-	)
-	require.True(t, stop)
-}
-
-func TestShouldStopBackfillHonorsNotRunningState(t *testing.T) {
-	stop := shouldStopBackfill(
-		context.Background(),
-		fakeBackfillStateReader{running: false},
-		uuid.New(),
-	)
-	require.True(t, stop)
-}
-
-func TestWaitForBackfillSlotStopsWhileWaiting(t *testing.T) {
-	sem := semaphore.NewWeighted(1)
-	require.NoError(t, sem.Acquire(context.Background(), 1))
-	defer sem.Release(1)
-
-	var stopRequested atomic.Bool
-	go func() {
-		time.Sleep(75 * time.Millisecond)
-		stopRequested.Store(true)
-	}()
-
-	start := time.Now()
-	acquired := waitForBackfillSlot(context.Background(), sem, func() bool {
-		return stopRequested.Load()
-	})
-
-	require.False(t, acquired)
-	require.Less(t, time.Since(start), time.Second)
-}
-
-func TestWaitForBackfillSlotAcquiresPermit(t *testing.T) {
-	sem := semaphore.NewWeighted(1)
-
-	acquired := waitForBackfillSlot(context.Background(), sem, func() bool {
-		return false
-	})
-	require.True(t, acquired)
-
-	sem.Release(1)
-}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand/v2"
 	"runtime"
 	"slices"
@@ -23,13 +24,19 @@ import (
 	"github.com/caesium-cloud/caesium/internal/cache"
 	"github.com/caesium-cloud/caesium/internal/callback"
 	"github.com/caesium-cloud/caesium/internal/event"
+	"github.com/caesium-cloud/caesium/internal/fanout"
+	"github.com/caesium-cloud/caesium/internal/gate"
 	"github.com/caesium-cloud/caesium/internal/imagecheck"
+	"github.com/caesium-cloud/caesium/internal/imagepolicy"
 	jobdefruntime "github.com/caesium-cloud/caesium/internal/jobdef/runtime"
 	"github.com/caesium-cloud/caesium/internal/jobdef/secret"
+	"github.com/caesium-cloud/caesium/internal/logarchive"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/ratelimit"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/sensor"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/internal/worker"
 	"github.com/caesium-cloud/caesium/pkg/container"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
@@ -38,6 +45,8 @@ import (
 	"github.com/caesium-cloud/caesium/pkg/log"
 	pkgtask "github.com/caesium-cloud/caesium/pkg/task"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -63,12 +72,40 @@ type taskResult struct {
 	id              uuid.UUID
 	err             error
 	skippedByBranch []uuid.UUID
+	// heldSkipped is set when the task's approval gate or sensor timed out
+	// with onTimeout: skip; the task itself is then skipped, not succeeded.
+	heldSkipped bool
+	// rescheduleAt is set when a reschedule-mode sensor released the task
+	// until its next poke.
+	rescheduleAt time.Time
 }
 
 // ErrLocalQuarantinedReplayUnsupported is returned when a quarantined replay
 // reaches the in-process executor, which is not descriptor-aware.
 var ErrLocalQuarantinedReplayUnsupported = errors.New("replay requires the descriptor-aware executor")
 
+// errRunCancelled is the cancellation cause set on the run context when the
+// run is cancelled in the store while the in-process executor is running it.
+var errRunCancelled = errors.New("run cancelled")
+
+// errGateSkipped is returned by runTask when the task's approval gate expired
+// and the step skips on timeout.
+var errGateSkipped = errors.New("approval gate expired")
+
+// errSensorSkipped is returned by runTask when the task's sensor timed out
+// and the step skips on timeout.
+var errSensorSkipped = errors.New("sensor timed out")
+
+// sensorRescheduledError is returned by runTask when a reschedule-mode sensor
+// released its task until the next poke.
+type sensorRescheduledError struct {
+	at time.Time
+}
+
+func (e *sensorRescheduledError) Error() string {
+	return fmt.Sprintf("sensor rescheduled until %s", e.at.Format(time.RFC3339))
+}
+
 // retryOnContention runs fn, retrying only on transient dqlite contention.
 //
 // The global connection-pool retry (pkg/db) covers a contended statement at
@@ -137,6 +174,8 @@ type job struct {
 	schemaValidation       string
 	jobCacheConfig         interface{}
 	params                 map[string]string
+	labels                 map[string]string
+	runTemplates           bool
 	runStoreFactory        func() *run.Store
 	envVariables           func() env.Environment
 	taskServiceFactory     func(context.Context) task.Task
@@ -148,6 +187,7 @@ type job struct {
 	newPodmanEngine        func(context.Context) atom.Engine
 	atomPollInterval       time.Duration
 	secretResolver         secret.Resolver
+	logArchive             *logarchive.Archive
 }
 
 // JobOption configures a job before execution.
@@ -166,6 +206,8 @@ func New(m *models.Job, opts ...JobOption) Job {
 		rateLimits:             unmarshalRateLimits(m.RateLimits),
 		schemaValidation:       m.SchemaValidation,
 		jobCacheConfig:         unmarshalCacheConfig(m.CacheConfig),
+		labels:                 imagepolicy.Labels(m.Labels),
+		runTemplates:           m.RunTemplates,
 		runStoreFactory:        run.Default,
 		envVariables:           env.Variables,
 		taskServiceFactory:     task.Service,
@@ -321,6 +363,14 @@ func WithSecretResolver(resolver secret.Resolver) JobOption {
 	}
 }
 
+// WithLogArchive streams each task's complete log into archive. If omitted,
+// Run builds the archive from the processed environment.
+func WithLogArchive(archive *logarchive.Archive) JobOption {
+	return func(j *job) {
+		j.logArchive = archive
+	}
+}
+
 // buildParamEnv returns a map of environment variables derived from params.
 // It also injects CAESIUM_RUN_ID and CAESIUM_JOB_ALIAS.
 func buildParamEnv(runID uuid.UUID, jobAlias string, params map[string]string) map[string]string {
@@ -381,6 +431,13 @@ func (j *job) Run(ctx context.Context) error {
 			return fmt.Errorf("secret resolver configuration failure: %w", err)
 		}
 	}
+	logArchive := j.logArchive
+	if logArchive == nil {
+		var err error
+		if logArchive, err = logarchive.FromEnv(vars); err != nil {
+			log.Warn("task log archive disabled", "job_id", j.id, "error", err)
+		}
+	}
 
 	cacheConfig := cache.ConfigFromEnv()
 	var cacheStore *cache.Store
@@ -413,6 +470,13 @@ func (j *job) Run(ctx context.Context) error {
 	if maxParallel <= 0 {
 		maxParallel = vars.MaxParallelTasks
 	}
+
+	spanAttrs := []attribute.KeyValue{tracing.AttrJobID.String(j.id.String()), tracing.AttrJobAlias.String(j.alias)}
+	if j.triggerID != nil {
+		spanAttrs = append(spanAttrs, tracing.AttrTriggerID.String(j.triggerID.String()))
+	}
+	ctx, span := tracing.Start(ctx, "job.run", trace.WithAttributes(spanAttrs...))
+	defer span.End()
 	if maxParallel <= 0 {
 		maxParallel = runtime.NumCPU()
 	}
@@ -431,14 +495,14 @@ func (j *job) Run(ctx context.Context) error {
 			existing, err := store.Get(id)
 			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return store.Start(j.id, j.triggerID, startOpts...)
+					return store.StartWithContext(ctx, j.id, j.triggerID, startOpts...)
 				}
 				return nil, err
 			}
 			return existing, nil
 		}
 
-		if admitted, handled, err := store.AdmitRun(j.id, j.triggerID, startOpts...); handled || err != nil {
+		if admitted, handled, err := store.AdmitRunWithContext(ctx, j.id, j.triggerID, startOpts...); handled || err != nil {
 			return admitted, err
 		}
 
@@ -458,7 +522,7 @@ func (j *job) Run(ctx context.Context) error {
 			return store.Get(running.ID)
 		}
 
-		return store.Start(j.id, j.triggerID, startOpts...)
+		return store.StartWithContext(ctx, j.id, j.triggerID, startOpts...)
 	}
 
 	var snapshot *run.JobRun
@@ -478,10 +542,13 @@ func (j *job) Run(ctx context.Context) error {
 
 	runID := snapshot.ID
 	runQuarantined := snapshot.Quarantine
+	span.SetAttributes(tracing.AttrRunID.String(runID.String()))
+	templateData := jobdefschema.NewRunTemplateData(runID.String(), j.alias, snapshot.Params, snapshot.StartedAt)
 	ctx = run.WithContext(ctx, runID)
 
 	var runErr error
 	defer func() {
+		tracing.Fail(span, runErr)
 		if err := store.Complete(runID, runErr); err != nil {
 			log.Error("run completion persistence failure", "run_id", runID, "error", err)
 		}
@@ -525,11 +592,39 @@ func (j *job) Run(ctx context.Context) error {
 	tasksByID := make(map[uuid.UUID]*models.Task, len(tasks))
 	runners := make(map[uuid.UUID]*atomRunner, len(tasks))
 	triggerRuleByTask := make(map[uuid.UUID]string, len(tasks))
+	mapSpecs := make(map[uuid.UUID]*jobdefschema.StepMap)
+	gateSpecs := make(map[uuid.UUID]*jobdefschema.StepGate)
+	sensorSpecs := make(map[uuid.UUID]*jobdefschema.StepSensor)
 
 	for idx, t := range tasks {
 		taskOrder[t.ID] = idx
 		tasksByID[t.ID] = t
 
+		mapSpec, err := fanout.Spec(t)
+		if err != nil {
+			runErr = err
+			return err
+		}
+		if mapSpec != nil {
+			mapSpecs[t.ID] = mapSpec
+		}
+		gateSpec, err := gate.Spec(t)
+		if err != nil {
+			runErr = err
+			return err
+		}
+		if gateSpec != nil {
+			gateSpecs[t.ID] = gateSpec
+		}
+		sensorSpec, err := sensor.Spec(t)
+		if err != nil {
+			runErr = err
+			return err
+		}
+		if sensorSpec != nil {
+			sensorSpecs[t.ID] = sensorSpec
+		}
+
 		rule := t.TriggerRule
 		if rule == "" {
 			rule = jobdefschema.TriggerRuleAllSuccess
@@ -552,10 +647,30 @@ func (j *job) Run(ctx context.Context) error {
 			continue
 		}
 
+		command, spec, err := run.RenderTaskSpec(modelAtom, j.runTemplates, templateData)
+		if err != nil {
+			runErr = fmt.Errorf("task %s: %w", t.Name, err)
+			return runErr
+		}
+		// Image admission runs before the image is pulled. A verified
+		// signature pins the image to the checked digest for this run.
+		image := modelAtom.Image
+		if imagepolicy.Default().Mode() != imagepolicy.ModeOff {
+			image, err = imagepolicy.Default().Admit(ctx, store.DB(), imagepolicy.Request{
+				Job:    j.alias,
+				Step:   t.Name,
+				Image:  modelAtom.Image,
+				Labels: j.labels,
+			})
+			if err != nil {
+				runErr = fmt.Errorf("task %s: %w", t.Name, err)
+				return runErr
+			}
+		}
 		runner := &atomRunner{
-			image:   modelAtom.Image,
-			command: modelAtom.Cmd(),
-			spec:    modelAtom.ContainerSpec(),
+			image:   image,
+			command: command,
+			spec:    spec,
 		}
 
 		log.Info("evaluating task atom", "job_id", j.id, "task_id", t.ID, "engine", modelAtom.Engine, "atom_id", modelAtom.ID)
@@ -662,6 +777,12 @@ func (j *job) Run(ctx context.Context) error {
 		return runErr
 	}
 
+	// The cancel request may be served by any node, so watch the store rather
+	// than the in-process bus. Once the run is cancelled the run context is
+	// cancelled with errRunCancelled, which stops every in-flight atom.
+	ctx, stopCancelWatch := watchRunCancellation(ctx, store, runID, vars.RunCancelCheckInterval)
+	defer stopCancelWatch()
+
 	queue := make([]uuid.UUID, 0, len(tasks))
 	inQueue := make(map[uuid.UUID]bool, len(tasks))
 	processed := make(map[uuid.UUID]bool, len(tasks))
@@ -771,8 +892,21 @@ func (j *job) Run(ctx context.Context) error {
 	// executeAtom creates, monitors, and stops a container for one execution attempt.
 	// It returns the atom result string, any parsed task outputs, any branch
 	// selections (for branch-type tasks), a persisted log snapshot, and any error.
-	executeAtom := func(taskCtx context.Context, taskID uuid.UUID, attempt int, runner *atomRunner, extraEnv map[string]string) (string, map[string]string, []string, *run.TaskLogSnapshot, error) {
+	// inst is set when the attempt runs one instance of a mapped step; its
+	// lifecycle is then recorded on the instance rather than the task run.
+	executeAtom := func(taskCtx context.Context, taskID uuid.UUID, attempt int, runner *atomRunner, extraEnv map[string]string, inst *run.TaskInstance) (_ string, _ map[string]string, _ []string, _ *run.TaskLogSnapshot, err error) {
+		taskCtx, span := tracing.Start(taskCtx, "task.execute", trace.WithAttributes(
+			tracing.AttrRunID.String(runID.String()),
+			tracing.AttrTaskID.String(taskID.String()),
+			tracing.AttrAttempt.Int(attempt),
+			tracing.AttrImage.String(runner.image),
+		))
+		defer func() { tracing.End(span, err) }()
+
 		atomName := fmt.Sprintf("%s-%s", taskID, runID)
+		if inst != nil {
+			atomName = fmt.Sprintf("%s-%d-%s", taskID, inst.Index, runID)
+		}
 		if attempt > 1 {
 			atomName = fmt.Sprintf("%s-attempt%d", atomName, attempt)
 		}
@@ -788,7 +922,7 @@ func (j *job) Run(ctx context.Context) error {
 		if err != nil {
 			return "", nil, nil, nil, err
 		}
-		if len(secretIdentities) > 0 {
+		if len(secretIdentities) > 0 && inst == nil {
 			refs := make([]models.TaskExecutionSecretRef, 0, len(secretIdentities))
 			for _, resolved := range secretIdentities {
 				refs = append(refs, run.SecretIdentityDescriptorRef(resolved.EnvKey, resolved.Ref, resolved.Identity))
@@ -797,8 +931,9 @@ func (j *job) Run(ctx context.Context) error {
 				log.Warn("failed to persist task execution descriptor secret identity", "task_id", taskID, "error", err)
 			}
 		}
-		if len(paramEnv) > 0 || len(extraEnv) > 0 {
-			merged := make(map[string]string, len(spec.Env)+len(paramEnv)+len(extraEnv))
+		traceparent := tracing.Traceparent(taskCtx)
+		if len(paramEnv) > 0 || len(extraEnv) > 0 || traceparent != "" {
+			merged := make(map[string]string, len(spec.Env)+len(paramEnv)+len(extraEnv)+1)
 			for k, v := range spec.Env {
 				merged[k] = v
 			}
@@ -808,23 +943,51 @@ func (j *job) Run(ctx context.Context) error {
 			for k, v := range extraEnv {
 				merged[k] = v
 			}
+			if traceparent != "" {
+				merged[tracing.EnvTraceparent] = traceparent
+			}
 			spec.Env = merged
 		}
 
+		// A retry re-enters the pool: the failed attempt released its slots.
+		if attempt > 1 && inst == nil {
+			if err := store.WaitForPoolSlot(taskCtx, runID, taskID, vars.PoolPollInterval); err != nil {
+				return "", nil, nil, nil, err
+			}
+		}
+
+		_, createSpan := tracing.Start(taskCtx, "atom.create", trace.WithAttributes(tracing.AttrImage.String(runner.image)))
 		a, err := runner.engine.Create(&atom.EngineCreateRequest{
 			Name:    atomName,
 			Image:   runner.image,
 			Command: runner.command,
 			Spec:    spec,
 		})
+		tracing.End(createSpan, err)
 		if err != nil {
 			return "", nil, nil, nil, err
 		}
 
-		if err := store.StartTask(runID, taskID, a.ID()); err != nil {
+		if inst != nil {
+			if err := store.StartTaskInstance(inst.ID, a.ID(), attempt); err != nil {
+				return "", nil, nil, nil, err
+			}
+		} else if err := store.StartTask(runID, taskID, a.ID()); err != nil {
 			return "", nil, nil, nil, err
 		}
 
+		// Stream the complete log into the archive while the atom runs; it
+		// is sealed before Stop removes the container.
+		logKey := logarchive.TaskKey{RunID: runID, TaskID: taskID}
+		if inst != nil {
+			logKey.InstanceID, logKey.Index = inst.ID, inst.Index
+		}
+		taskLog := logArchive.RecordTask(taskCtx, store, logKey, func() (io.ReadCloser, error) {
+			return runner.engine.Logs(&atom.EngineLogsRequest{ID: a.ID()})
+		})
+		defer taskLog.Finish()
+
+		_, waitSpan := tracing.Start(taskCtx, "atom.wait")
 		waitResult := make(chan struct {
 			atom atom.Atom
 			err  error
@@ -839,6 +1002,16 @@ func (j *job) Run(ctx context.Context) error {
 
 		select {
 		case <-taskCtx.Done():
+			tracing.End(waitSpan, taskCtx.Err())
+			if errors.Is(context.Cause(taskCtx), errRunCancelled) {
+				if stopErr := runner.engine.Stop(&atom.EngineStopRequest{
+					ID:    a.ID(),
+					Force: true,
+				}); stopErr != nil {
+					log.Warn("failed to stop atom after run cancellation", "task_id", taskID, "atom_id", a.ID(), "error", stopErr)
+				}
+				return "", nil, nil, nil, fmt.Errorf("task %s: %w", taskID, errRunCancelled)
+			}
 			if errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
 				if stopErr := runner.engine.Stop(&atom.EngineStopRequest{
 					ID:    a.ID(),
@@ -854,16 +1027,20 @@ func (j *job) Run(ctx context.Context) error {
 			}
 			return "", nil, nil, nil, taskCtx.Err()
 		case result := <-waitResult:
+			tracing.End(waitSpan, result.err)
 			if result.err != nil {
 				return "", nil, nil, nil, result.err
 			}
 			a = result.atom
 			log.Info("atom finished", "job_id", j.id, "task_id", taskID, "atom_id", a.ID(), "result", a.Result())
+			taskLog.Finish()
 
 			// Capture the raw exit code before Result() folds it into a coarse
 			// status and the incident classifier loses it. Best-effort.
-			if exitErr := store.SetTaskExitCode(runID, taskID, a.ExitCode()); exitErr != nil {
-				log.Warn("failed to persist task exit code", "task_id", taskID, "error", exitErr)
+			if inst == nil {
+				if exitErr := store.SetTaskExitCode(runID, taskID, a.ExitCode()); exitErr != nil {
+					log.Warn("failed to persist task exit code", "task_id", taskID, "error", exitErr)
+				}
 			}
 
 			// Parse both structured outputs and branch markers in a single
@@ -899,12 +1076,324 @@ func (j *job) Run(ctx context.Context) error {
 		}
 	}
 
+	// taskHashInput assembles a task's cache identity inputs: its runner, the
+	// predecessor outputs it consumes (also present in outputEnv), the
+	// in-memory predecessor hashes, and — when pinning is on — the image's
+	// content digest. The predecessor hashes are also returned keyed by task
+	// for the execution descriptor.
+	taskHashInput := func(taskID uuid.UUID, taskName string, runner *atomRunner, cacheCfg jobdefschema.CacheConfig, outputEnv map[string]string, predOutputs map[string]map[string]string) (cache.HashInput, map[uuid.UUID]string) {
+		// Build merged env for hashing, excluding volatile per-run vars.
+		mergedEnv := make(map[string]string, len(runner.spec.Env)+len(outputEnv))
+		for k, v := range runner.spec.Env {
+			mergedEnv[k] = v
+		}
+		for k, v := range outputEnv {
+			mergedEnv[k] = v
+		}
+
+		// Collect predecessor hashes.
+		var predHashes []string
+		predHashByID := make(map[uuid.UUID]string)
+		for _, predID := range predecessors[taskID] {
+			if h, ok := taskHashes[predID]; ok {
+				predHashes = append(predHashes, h)
+				predHashByID[predID] = h
+			}
+		}
+
+		// When digest pinning is enabled, resolve the image tag to its
+		// content digest and fold the digest (not the mutable tag) into the
+		// cache key. Resolution failures fall back to the tag — a cache miss
+		// is always safe, so an unresolved digest never serves a stale hit.
+		var resolvedImageDigest string
+		if cacheCfg.PinDigests {
+			engineKind := models.AtomEngineDocker
+			if a := atomsByTask[taskID]; a != nil {
+				engineKind = a.Engine
+			}
+			if digest, derr := imagecheck.Default().Resolve(ctx, engineKind, runner.image, cacheCfg.DigestTTL); derr == nil {
+				resolvedImageDigest = digest
+			}
+		}
+
+		return cache.HashInput{
+			JobAlias:             j.alias,
+			TaskName:             taskName,
+			Image:                runner.image,
+			ResolvedImageDigest:  resolvedImageDigest,
+			Command:              runner.command,
+			Env:                  mergedEnv,
+			WorkDir:              runner.spec.WorkDir,
+			Mounts:               runner.spec.Mounts,
+			ResolvedVolumeMounts: runner.spec.ResolvedVolumeMounts,
+			Kubernetes:           runner.spec.Kubernetes,
+			PredecessorHashes:    predHashes,
+			PredecessorOutputs:   predOutputs,
+			RunParams:            snapshot.Params,
+			CacheVersion:         cacheCfg.Version,
+		}, predHashByID
+	}
+
+	// runMapped executes a mapped step: one instance per item of the list its
+	// map.over output names, each with its own cache identity and retries.
+	// The group row stays running until every instance is terminal and then
+	// completes with the instances' outputs aggregated into JSON arrays, so
+	// successors and trigger rules see a single status.
+	runMapped := func(taskID uuid.UUID, taskModel *models.Task, spec jobdefschema.StepMap, runner *atomRunner, predOutputs map[string]map[string]string, cacheCfg jobdefschema.CacheConfig, taskQuarantined bool) ([]uuid.UUID, error) {
+		failGroup := func(err error) ([]uuid.UUID, error) {
+			if persistErr := store.FailTask(runID, taskID, err); persistErr != nil {
+				log.Error("failed to persist task failure", "run_id", runID, "task_id", taskID, "error", persistErr)
+			}
+			return nil, err
+		}
+
+		items, err := fanout.Items(spec, predOutputs)
+		if err != nil {
+			return failGroup(fmt.Errorf("task %s: %w", taskID, err))
+		}
+		if err := store.StartTask(runID, taskID, ""); err != nil {
+			return nil, err
+		}
+
+		taskName := taskModel.Name
+		instOutputs := fanout.InstanceOutputs(spec, predOutputs)
+		instOutputEnv := pkgtask.BuildOutputEnv(instOutputs)
+		maxAttempts := taskModel.Retries + 1
+
+		exec := func(ctx context.Context, inst *run.TaskInstance, count int) fanout.Outcome {
+			env := fanout.InstanceEnv(inst, count)
+			for k, v := range instOutputEnv {
+				env[k] = v
+			}
+
+			var outcome fanout.Outcome
+			var hashInputBlob []byte
+			var resolvedImageDigest string
+			if cacheCfg.Enabled {
+				hashInput, _ := taskHashInput(taskID, taskName, runner, cacheCfg, instOutputEnv, instOutputs)
+				item := inst.Item
+				hashInput.MapItem = &item
+				resolvedImageDigest = hashInput.ResolvedImageDigest
+				outcome.Hash = hashInput.Compute()
+				blob, blobErr := hashInput.CanonicalJSON(outcome.Hash)
+				if blobErr != nil {
+					log.Warn("failed to serialize hash-input blob", "task", taskName, "index", inst.Index, "error", blobErr)
+					blob = nil
+				}
+				hashInputBlob = blob
+				if err := store.SetTaskInstanceHash(inst.ID, outcome.Hash, hashInputBlob); err != nil {
+					log.Warn("failed to persist instance hash", "task", taskName, "index", inst.Index, "error", err)
+				}
+
+				entry, found, err := getCacheStore().Get(outcome.Hash)
+				switch {
+				case err != nil:
+					log.Warn("cache lookup failed", "task", taskName, "index", inst.Index, "error", err)
+				case found && run.IsSuccessfulTaskResult(entry.Result):
+					if !taskQuarantined {
+						metrics.TaskCacheHitsTotal.WithLabelValues(j.alias, taskName).Inc()
+					}
+					outcome.Status = run.TaskStatusCached
+					outcome.Result = entry.Result
+					outcome.Output = entry.Output
+					outcome.Cache = &run.CacheHitSource{
+						RunID:     entry.RunID,
+						CreatedAt: entry.CreatedAt,
+						ExpiresAt: entry.ExpiresAt,
+					}
+					return outcome
+				default:
+					if !taskQuarantined {
+						metrics.TaskCacheMissesTotal.WithLabelValues(j.alias, taskName).Inc()
+					}
+				}
+			}
+
+			var lastErr error
+			for attempt := 1; attempt <= maxAttempts; attempt++ {
+				taskCtx := ctx
+				cancel := func() {}
+				if taskTimeout > 0 {
+					taskCtx, cancel = context.WithTimeout(ctx, taskTimeout)
+				}
+				result, output, _, _, execErr := executeAtom(taskCtx, taskID, attempt, runner, env, inst)
+				cancel()
+
+				if execErr == nil {
+					execErr = run.ValidateTaskOutputSchema(store, runID, taskModel.ID, output, taskModel.OutputSchema, j.schemaValidation)
+				}
+				if execErr == nil && !run.IsSuccessfulTaskResult(result) {
+					execErr = fmt.Errorf("instance %d failed with result %q", inst.Index, result)
+				}
+				if execErr == nil {
+					outcome.Status = run.TaskStatusSucceeded
+					outcome.Result = result
+					outcome.Output = output
+					if outcome.Hash != "" && !taskQuarantined {
+						var expiresAt *time.Time
+						if cacheCfg.TTL > 0 {
+							t := time.Now().Add(cacheCfg.TTL)
+							expiresAt = &t
+						}
+						if putErr := getCacheStore().Put(&cache.Entry{
+							Hash:                outcome.Hash,
+							JobID:               j.id,
+							TaskName:            taskName,
+							Result:              result,
+							Output:              output,
+							RunID:               runID,
+							TaskRunID:           inst.ID,
+							ResolvedImageDigest: resolvedImageDigest,
+							HashInputBlob:       hashInputBlob,
+							CreatedAt:           time.Now(),
+							ExpiresAt:           expiresAt,
+						}); putErr != nil {
+							log.Warn("failed to store cache entry", "task", taskName, "index", inst.Index, "error", putErr)
+						}
+					}
+					return outcome
+				}
+				lastErr = execErr
+				if errors.Is(execErr, errRunCancelled) || attempt >= maxAttempts {
+					break
+				}
+
+				delay := computeRetryDelay(taskModel, attempt)
+				log.Info("retrying mapped instance", "job_id", j.id, "task_id", taskID, "index", inst.Index, "attempt", attempt, "next_attempt", attempt+1, "delay", delay, "error", lastErr)
+				if !taskQuarantined {
+					metrics.TaskRetriesTotal.WithLabelValues(j.alias, taskID.String(), strconv.Itoa(attempt)).Inc()
+				}
+				if delay > 0 {
+					select {
+					case <-ctx.Done():
+						return outcome
+					case <-time.After(delay):
+					}
+				}
+			}
+
+			outcome.Status = run.TaskStatusFailed
+			outcome.Error = lastErr.Error()
+			return outcome
+		}
+
+		group, err := fanout.Run(ctx, store, runID, taskID, items, spec.MaxParallel, exec)
+		if err != nil {
+			if errors.Is(err, errRunCancelled) {
+				return nil, fmt.Errorf("task %s: %w", taskID, errRunCancelled)
+			}
+			return nil, err
+		}
+		if group.Hash != "" {
+			taskHashes[taskID] = group.Hash
+			if err := store.SetTaskHashWithBlob(runID, taskID, group.Hash, "", nil); err != nil {
+				log.Warn("failed to persist task hash", "task", taskName, "error", err)
+			}
+		}
+		if group.Err != nil {
+			return failGroup(fmt.Errorf("task %s: %w", taskID, group.Err))
+		}
+
+		completeResult, err := store.CompleteTaskWithResult(runID, taskID, string(atom.Success), group.Output, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(group.Output) > 0 {
+			taskOutputs[taskID] = group.Output
+		}
+		if completeResult != nil {
+			return completeResult.SkippedTaskIDs, nil
+		}
+		return nil, nil
+	}
+
+	// runSensor pokes a sensor step's probe in place of running a container
+	// and completes, fails, skips, or reschedules the task by the outcome.
+	runSensor := func(taskID uuid.UUID, spec jobdefschema.StepSensor) ([]uuid.UUID, error) {
+		if err := store.StartTask(runID, taskID, ""); err != nil {
+			return nil, err
+		}
+		outcome, err := sensor.Await(ctx, store, runID, taskID, spec, templateData, j.alias)
+		if err != nil {
+			if ctx.Err() == nil {
+				if persistErr := store.FailTask(runID, taskID, err); persistErr != nil {
+					log.Error("failed to persist task failure", "run_id", runID, "task_id", taskID, "error", persistErr)
+				}
+			}
+			return nil, fmt.Errorf("task %s: %w", taskID, err)
+		}
+		if !outcome.RescheduleAt.IsZero() {
+			if err := store.RescheduleSensorTask(ctx, runID, taskID, "", outcome.RescheduleAt); err != nil {
+				return nil, err
+			}
+			return nil, &sensorRescheduledError{at: outcome.RescheduleAt}
+		}
+		if reason, ok := sensor.Skipped(spec, outcome); ok {
+			skipped, err := store.SkipSensorTask(runID, taskID, reason, "")
+			if err != nil {
+				return nil, err
+			}
+			return skipped, errSensorSkipped
+		}
+		if failure := sensor.Failure(spec, outcome); failure != nil {
+			if persistErr := store.FailTask(runID, taskID, failure); persistErr != nil {
+				log.Error("failed to persist task failure", "run_id", runID, "task_id", taskID, "error", persistErr)
+			}
+			return nil, fmt.Errorf("task %s: %w", taskID, failure)
+		}
+
+		completeResult, err := store.CompleteTaskWithResult(runID, taskID, string(atom.Success), outcome.Output, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(outcome.Output) > 0 {
+			taskOutputs[taskID] = outcome.Output
+		}
+		return completeResult.SkippedTaskIDs, nil
+	}
+
 	runTask := func(taskID uuid.UUID) ([]uuid.UUID, error) {
 		runner := runners[taskID]
 		if runner == nil {
 			return nil, fmt.Errorf("missing runner for task %s", taskID)
 		}
 
+		// A pooled step waits for room in its pool before anything else, so
+		// a gated step holds its pool slots while it awaits approval, just as
+		// it does on a worker.
+		if err := store.WaitForPoolSlot(ctx, runID, taskID, vars.PoolPollInterval); err != nil {
+			return nil, fmt.Errorf("task %s: %w", taskID, err)
+		}
+
+		// A gated step holds its slot until the approval request is decided.
+		if spec := gateSpecs[taskID]; spec != nil {
+			decided, err := gate.Await(ctx, store, runID, taskID, "", *spec, vars.GatePollInterval)
+			if err != nil {
+				return nil, fmt.Errorf("task %s: %w", taskID, err)
+			}
+			if reason, ok := gate.Skipped(decided); ok {
+				skipped, err := store.SkipGatedTask(runID, taskID, reason, "")
+				if err != nil {
+					return nil, err
+				}
+				return skipped, errGateSkipped
+			}
+			if failure := gate.Failure(decided); failure != nil {
+				if persistErr := store.FailTask(runID, taskID, failure); persistErr != nil {
+					log.Error("failed to persist task failure", "run_id", runID, "task_id", taskID, "error", persistErr)
+				}
+				return nil, fmt.Errorf("task %s: %w", taskID, failure)
+			}
+			if err := store.ResumeApprovedTask(runID, taskID, ""); err != nil {
+				return nil, err
+			}
+		}
+
+		// A sensor holds no container; it pokes until its condition holds.
+		if spec := sensorSpecs[taskID]; spec != nil {
+			return runSensor(taskID, *spec)
+		}
+
 		// Build predecessor output env vars for this task.
 		predOutputs := make(map[string]map[string]string)
 		predOutputsByID := make(map[uuid.UUID]map[string]string)
@@ -945,6 +1434,10 @@ func (j *job) Run(ctx context.Context) error {
 		}
 		cacheCfg = jobdefschema.ResolveCacheConfig(stepCache, j.jobCacheConfig, cacheConfig.Enabled, cacheConfig.TTL, cacheConfig.PinDigests, cacheConfig.DigestTTL)
 
+		if spec := mapSpecs[taskID]; spec != nil && taskModel != nil {
+			return runMapped(taskID, taskModel, *spec, runner, predOutputs, cacheCfg, taskQuarantined)
+		}
+
 		if cacheCfg.Enabled {
 			cacheStore := getCacheStore()
 			taskName := ""
@@ -952,55 +1445,8 @@ func (j *job) Run(ctx context.Context) error {
 				taskName = taskModel.Name
 			}
 
-			// Build merged env for hashing, excluding volatile per-run vars.
-			mergedEnv := make(map[string]string, len(runner.spec.Env)+len(outputEnv))
-			for k, v := range runner.spec.Env {
-				mergedEnv[k] = v
-			}
-			for k, v := range outputEnv {
-				mergedEnv[k] = v
-			}
-
-			// Collect predecessor hashes.
-			var predHashes []string
-			predHashByID := make(map[uuid.UUID]string)
-			for _, predID := range predecessors[taskID] {
-				if h, ok := taskHashes[predID]; ok {
-					predHashes = append(predHashes, h)
-					predHashByID[predID] = h
-				}
-			}
-
-			// When digest pinning is enabled, resolve the image tag to its
-			// content digest and fold the digest (not the mutable tag) into the
-			// cache key. Resolution failures fall back to the tag — a cache miss
-			// is always safe, so an unresolved digest never serves a stale hit.
-			if cacheCfg.PinDigests {
-				engineKind := models.AtomEngineDocker
-				if a := atomsByTask[taskID]; a != nil {
-					engineKind = a.Engine
-				}
-				if digest, derr := imagecheck.Default().Resolve(ctx, engineKind, runner.image, cacheCfg.DigestTTL); derr == nil {
-					resolvedImageDigest = digest
-				}
-			}
-
-			hashInput := cache.HashInput{
-				JobAlias:             j.alias,
-				TaskName:             taskName,
-				Image:                runner.image,
-				ResolvedImageDigest:  resolvedImageDigest,
-				Command:              runner.command,
-				Env:                  mergedEnv,
-				WorkDir:              runner.spec.WorkDir,
-				Mounts:               runner.spec.Mounts,
-				ResolvedVolumeMounts: runner.spec.ResolvedVolumeMounts,
-				Kubernetes:           runner.spec.Kubernetes,
-				PredecessorHashes:    predHashes,
-				PredecessorOutputs:   predOutputs,
-				RunParams:            snapshot.Params,
-				CacheVersion:         cacheCfg.Version,
-			}
+			hashInput, predHashByID := taskHashInput(taskID, taskName, runner, cacheCfg, outputEnv, predOutputs)
+			resolvedImageDigest = hashInput.ResolvedImageDigest
 			inputHash = hashInput.Compute()
 			// Serialize the decomposed input to a canonical, secret-redacted
 			// blob so `caesium why` can later diff this run field-by-field. A
@@ -1071,7 +1517,7 @@ func (j *job) Run(ctx context.Context) error {
 				taskCtx, cancel = context.WithTimeout(ctx, taskTimeout)
 			}
 
-			result, output, branchNames, logSnapshot, execErr := executeAtom(taskCtx, taskID, attempt, runner, outputEnv)
+			result, output, branchNames, logSnapshot, execErr := executeAtom(taskCtx, taskID, attempt, runner, outputEnv, nil)
 			cancel()
 
 			if execErr == nil {
@@ -1171,6 +1617,12 @@ func (j *job) Run(ctx context.Context) error {
 			}
 			lastErr = execErr
 
+			// The store already marked the task cancelled; neither retry nor
+			// overwrite that with a failure.
+			if errors.Is(execErr, errRunCancelled) {
+				return nil, execErr
+			}
+
 			// No more attempts — mark as permanently failed.
 			if attempt >= maxAttempts {
 				break
@@ -1275,7 +1727,16 @@ func (j *job) Run(ctx context.Context) error {
 		active++
 		if err := taskPool.Submit(ctx, func() {
 			skipped, err := runTask(taskID)
-			results <- taskResult{id: taskID, err: err, skippedByBranch: skipped}
+			var rescheduled *sensorRescheduledError
+			if errors.As(err, &rescheduled) {
+				results <- taskResult{id: taskID, rescheduleAt: rescheduled.at}
+				return
+			}
+			heldSkipped := errors.Is(err, errGateSkipped) || errors.Is(err, errSensorSkipped)
+			if heldSkipped {
+				err = nil
+			}
+			results <- taskResult{id: taskID, err: err, skippedByBranch: skipped, heldSkipped: heldSkipped}
 		}); err != nil {
 			active--
 			return err
@@ -1369,6 +1830,11 @@ func (j *job) Run(ctx context.Context) error {
 			active--
 		}
 
+		if !result.rescheduleAt.IsZero() {
+			deferred[result.id] = result.rescheduleAt
+			continue
+		}
+
 		if processed[result.id] {
 			continue
 		}
@@ -1381,7 +1847,7 @@ func (j *job) Run(ctx context.Context) error {
 			if runErr == nil {
 				runErr = result.err
 			}
-			if !continueOnFailure {
+			if !continueOnFailure || errors.Is(result.err, errRunCancelled) {
 				halt = true
 				queue = queue[:0]
 				continue
@@ -1471,6 +1937,9 @@ func (j *job) Run(ctx context.Context) error {
 		}
 
 		taskOutcomes[result.id] = run.TaskStatusSucceeded
+		if result.heldSkipped {
+			taskOutcomes[result.id] = run.TaskStatusSkipped
+		}
 
 		// Update local state for any tasks the run store skipped while
 		// resolving branch filtering or trigger-rule evaluation.
@@ -1565,6 +2034,40 @@ func (j *job) Run(ctx context.Context) error {
 	return nil
 }
 
+// watchRunCancellation derives a context from parent that is cancelled with
+// errRunCancelled once the store reports the run as cancelled. The returned
+// stop func ends the watch and releases the context.
+func watchRunCancellation(parent context.Context, store *run.Store, runID uuid.UUID, interval time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cancelled, err := store.IsRunCancelled(ctx, runID)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Warn("failed to check run cancellation", "run_id", runID, "error", err)
+					}
+					continue
+				}
+				if cancelled {
+					log.Info("run cancelled; stopping in-flight tasks", "run_id", runID)
+					cancel(errRunCancelled)
+					return
+				}
+			}
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
 func normalizeExecutionMode(value string) string {
 	switch strings.ToLower(strings.TrimSpace(value)) {
 	case executionModeDistributed:
@@ -1660,7 +2163,7 @@ func waitForRunCompletion(ctx context.Context, store *run.Store, runID uuid.UUID
 				switch taskState.Status {
 				case run.TaskStatusFailed:
 					failed++
-				case run.TaskStatusRunning:
+				case run.TaskStatusRunning, run.TaskStatusAwaitingApproval:
 					running++
 				case run.TaskStatusSucceeded:
 					succeeded++
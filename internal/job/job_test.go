@@ -283,6 +283,65 @@ func TestRunLocalRunTimeoutFailsRun(t *testing.T) {
 	require.Equal(t, run.StatusFailed, snapshot.Status)
 }
 
+func TestRunLocalCancelledRunStopsAtom(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() { jobdeftestutil.CloseDB(db) })
+
+	store := run.NewStore(db)
+	engine := newFakeEngine()
+
+	jobID := uuid.New()
+	taskID := uuid.New()
+	atomID := uuid.New()
+
+	taskSvc := &fakeTaskService{tasks: models.Tasks{
+		{ID: taskID, JobID: jobID, AtomID: atomID},
+	}}
+	persistGraph(t, db, taskSvc.tasks, nil)
+	atomSvc := &fakeAtomService{atoms: map[uuid.UUID]*models.Atom{
+		atomID: fakeModelAtom(atomID),
+	}}
+
+	engine.runDurationByName[taskID.String()] = 10 * time.Second
+
+	opts := withTestDeps(store, env.Environment{
+		MaxParallelTasks:       1,
+		TaskFailurePolicy:      taskFailurePolicyHalt,
+		ExecutionMode:          executionModeLocal,
+		RunCancelCheckInterval: 10 * time.Millisecond,
+	}, taskSvc, atomSvc, &fakeTaskEdgeService{}, engine)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- New(&models.Job{ID: jobID}, opts...).Run(context.Background())
+	}()
+
+	var runID uuid.UUID
+	require.Eventually(t, func() bool {
+		var row models.TaskRun
+		if err := db.Where("task_id = ? AND status = ?", taskID, string(run.TaskStatusRunning)).Take(&row).Error; err != nil {
+			return false
+		}
+		runID = row.JobRunID
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, store.CancelRunWithReason(context.Background(), runID, "cancelled by test"))
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, errRunCancelled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop after cancellation")
+	}
+
+	snapshot := latestRunSnapshot(t, store, jobID)
+	require.Equal(t, run.StatusCancelled, snapshot.Status)
+	require.Equal(t, "cancelled by test", snapshot.Error)
+	require.Equal(t, run.TaskStatusCancelled, taskStatusByID(snapshot)[taskID])
+	require.True(t, engine.wasForceStopped(taskID.String()))
+}
+
 func TestRunLocalFailedAtomResultFailsRun(t *testing.T) {
 	db := jobdeftestutil.OpenTestDB(t)
 	t.Cleanup(func() { jobdeftestutil.CloseDB(db) })
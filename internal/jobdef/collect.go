@@ -18,13 +18,15 @@ import (
 // CollectDefinitions walks the given paths, reads YAML files, and returns
 // all valid Caesium job definitions found. Non-Caesium YAML documents
 // (e.g. Helm charts, Kubernetes manifests) are silently skipped.
+// StepTemplate documents found anywhere under the paths are loaded first and
+// expanded into the steps that use them before any definition is validated.
 // If validate is true, each definition is validated and errors are returned.
 func CollectDefinitions(paths []string, validate bool) ([]schema.Definition, error) {
 	if len(paths) == 0 {
 		paths = []string{"."}
 	}
 
-	var defs []schema.Definition
+	c := &collector{templates: schema.NewTemplateRegistry()}
 	for _, p := range paths {
 		info, err := os.Stat(p)
 		if err != nil {
@@ -38,7 +40,7 @@ func CollectDefinitions(paths []string, validate bool) ([]schema.Definition, err
 				if d.IsDir() || !IsYAML(path) {
 					return nil
 				}
-				return appendDefinitions(path, &defs, validate)
+				return c.appendDefinitions(path)
 			}); err != nil {
 				return nil, err
 			}
@@ -46,15 +48,39 @@ func CollectDefinitions(paths []string, validate bool) ([]schema.Definition, err
 			if !IsYAML(p) {
 				return nil, fmt.Errorf("%s is not a YAML file", p)
 			}
-			if err := appendDefinitions(p, &defs, validate); err != nil {
+			if err := c.appendDefinitions(p); err != nil {
 				return nil, err
 			}
 		}
 	}
+
+	defs := make([]schema.Definition, 0, len(c.defs))
+	for i := range c.defs {
+		def := &c.defs[i].def
+		if err := def.ExpandTemplates(c.templates); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.defs[i].path, err)
+		}
+		if validate {
+			if err := def.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", c.defs[i].path, err)
+			}
+		}
+		defs = append(defs, *def)
+	}
 	return defs, nil
 }
 
-func appendDefinitions(path string, defs *[]schema.Definition, validate bool) error {
+type collectedDefinition struct {
+	path string
+	def  schema.Definition
+}
+
+type collector struct {
+	defs      []collectedDefinition
+	templates *schema.TemplateRegistry
+}
+
+func (c *collector) appendDefinitions(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -62,11 +88,26 @@ func appendDefinitions(path string, defs *[]schema.Definition, validate bool) er
 
 	dec := yaml.NewDecoder(bytes.NewReader(data))
 	for {
-		var def schema.Definition
-		if err := dec.Decode(&def); err != nil {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		tmpl, err := schema.DecodeStepTemplate(&node)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if tmpl != nil {
+			if err := c.templates.Add(tmpl); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			continue
+		}
+
+		var def schema.Definition
+		if err := node.Decode(&def); err != nil {
 			// Distinguish YAML syntax errors (which should be surfaced) from
 			// type-mismatch errors caused by non-Caesium YAML (which should
 			// be skipped). Re-decode the same document into a generic map: if
@@ -84,12 +125,7 @@ func appendDefinitions(path string, defs *[]schema.Definition, validate bool) er
 		if !isCaesiumDefinition(&def) {
 			continue
 		}
-		if validate {
-			if err := def.Validate(); err != nil {
-				return fmt.Errorf("%s: %w", path, err)
-			}
-		}
-		*defs = append(*defs, def)
+		c.defs = append(c.defs, collectedDefinition{path: path, def: def})
 	}
 
 	return nil
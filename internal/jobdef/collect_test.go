@@ -132,3 +132,58 @@ func writeFile(t *testing.T, dir, name, content string) {
 	t.Helper()
 	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
 }
+
+func TestCollectDefinitions_ExpandsStepTemplatesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "templates.yaml", `
+apiVersion: v1
+kind: StepTemplate
+metadata:
+  name: pg-dump
+  version: "1"
+parameters:
+  - {name: database, type: string, required: true}
+spec:
+  image: postgres:16
+  command: ["pg_dump", "{{ .database }}"]
+`)
+	writeFile(t, dir, "job.yaml", `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: nightly-dump
+trigger:
+  type: cron
+  configuration:
+    cron: "0 2 * * *"
+steps:
+  - name: dump
+    uses: pg-dump@1
+    with: {database: orders}
+`)
+	defs, err := CollectDefinitions([]string{dir}, true)
+	require.NoError(t, err)
+	require.Len(t, defs, 1, "the StepTemplate document is not a job definition")
+	step := defs[0].Steps[0]
+	assert.Equal(t, "postgres:16", step.Image)
+	assert.Equal(t, []string{"pg_dump", "orders"}, step.Command)
+	assert.Empty(t, step.Uses)
+}
+
+func TestCollectDefinitions_UnknownStepTemplate(t *testing.T) {
+	dir := writeTestFile(t, "job.yaml", `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: nightly-dump
+trigger:
+  type: cron
+  configuration:
+    cron: "0 2 * * *"
+steps:
+  - name: dump
+    uses: pg-dump@1
+`)
+	_, err := CollectDefinitions([]string{dir}, false)
+	require.ErrorContains(t, err, `step template "pg-dump@1" not found`)
+}
@@ -14,6 +14,7 @@ type Diff struct {
 
 // Update captures the differences for an existing job.
 type Update struct {
+	// Alias is the job's JobSpec.Key.
 	Alias string `json:"alias"`
 	Diff  string `json:"diff"`
 }
@@ -12,6 +12,7 @@ func FuzzDecodeDefinitions(f *testing.F) {
 	f.Add([]byte(""))
 	f.Add([]byte("not yaml at all: [[["))
 	f.Add([]byte("apiVersion: v1\nkind: Job\n"))
+	f.Add([]byte("apiVersion: v1\nkind: StepTemplate\nmetadata:\n  name: echo\n  version: \"1\"\nparameters:\n  - {name: msg, type: string, required: true}\nspec:\n  image: alpine:3.23\n  command: [echo, \"{{ .msg }}\"]\n---\napiVersion: v1\nkind: Job\nmetadata:\n  alias: test\ntrigger:\n  type: cron\n  configuration:\n    cron: \"* * * * *\"\nsteps:\n  - name: step1\n    uses: echo@1\n    with: {msg: hi}\n"))
 	f.Fuzz(func(t *testing.T, data []byte) {
 		tmp, err := os.CreateTemp("", "fuzz-def-*.yaml")
 		if err != nil {
@@ -25,6 +26,11 @@ func FuzzDecodeDefinitions(f *testing.F) {
 		tmp.Close()
 
 		// Must not panic
-		_ = decodeDefinitions(tmp.Name(), func(_ *schema.Definition) error { return nil })
+		templates := schema.NewTemplateRegistry()
+		_ = decodeDefinitions(tmp.Name(), templates, func(_ string, def *schema.Definition) {
+			if def.ExpandTemplates(templates) == nil {
+				_ = def.Validate()
+			}
+		})
 	})
 }
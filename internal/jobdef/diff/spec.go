@@ -24,6 +24,7 @@ import (
 
 // JobSpec captures the fields that participate in diffing.
 type JobSpec struct {
+	Namespace   string            `json:"namespace"`
 	Alias       string            `json:"alias"`
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
@@ -49,9 +50,15 @@ type StepSpec struct {
 	OutputSchema map[string]any `json:"outputSchema"`
 }
 
+// Key identifies the spec across namespaces; diffs are keyed by it.
+func (s JobSpec) Key() string {
+	return schema.QualifiedAlias(s.Namespace, s.Alias)
+}
+
 // FromDefinition normalises a job definition into a JobSpec.
 func FromDefinition(def *schema.Definition) JobSpec {
 	return JobSpec{
+		Namespace:   schema.NamespaceOrDefault(def.Metadata.Namespace),
 		Alias:       def.Metadata.Alias,
 		Labels:      cloneStringMap(def.Metadata.Labels),
 		Annotations: cloneStringMap(def.Metadata.Annotations),
@@ -69,23 +76,41 @@ func LoadDefinitions(paths []string) (map[string]JobSpec, error) {
 	if len(paths) == 0 {
 		paths = []string{"."}
 	}
-	specs := make(map[string]JobSpec)
+	var docs []pathDefinition
+	templates := schema.NewTemplateRegistry()
 	for _, p := range paths {
-		if err := collectPath(p, func(def *schema.Definition) error {
-			alias := def.Metadata.Alias
-			if _, exists := specs[alias]; exists {
-				return fmt.Errorf("duplicate job alias %q", alias)
-			}
-			specs[alias] = FromDefinition(def)
-			return nil
+		if err := collectPath(p, templates, func(path string, def *schema.Definition) {
+			docs = append(docs, pathDefinition{path: path, def: *def})
 		}); err != nil {
 			return nil, err
 		}
 	}
+
+	specs := make(map[string]JobSpec)
+	for i := range docs {
+		def := &docs[i].def
+		if err := def.ExpandTemplates(templates); err != nil {
+			return nil, fmt.Errorf("%s: %w", docs[i].path, err)
+		}
+		if err := def.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", docs[i].path, err)
+		}
+		spec := FromDefinition(def)
+		if _, exists := specs[spec.Key()]; exists {
+			return nil, fmt.Errorf("duplicate job alias %q", spec.Key())
+		}
+		specs[spec.Key()] = spec
+	}
 	return specs, nil
 }
 
-// LoadDatabaseSpecs loads all jobs from the database into specs keyed by alias.
+type pathDefinition struct {
+	path string
+	def  schema.Definition
+}
+
+// LoadDatabaseSpecs loads all jobs from the database into specs keyed by
+// JobSpec.Key.
 func LoadDatabaseSpecs(ctx context.Context, db *gorm.DB) (map[string]JobSpec, error) {
 	var jobs []models.Job
 	if err := db.WithContext(ctx).Find(&jobs).Error; err != nil {
@@ -99,13 +124,14 @@ func LoadDatabaseSpecs(ctx context.Context, db *gorm.DB) (map[string]JobSpec, er
 		if err != nil {
 			return nil, err
 		}
-		specs[job.Alias] = spec
+		specs[spec.Key()] = spec
 	}
 	return specs, nil
 }
 
 func buildJobSpec(ctx context.Context, db *gorm.DB, job *models.Job) (JobSpec, error) {
 	spec := JobSpec{
+		Namespace:   schema.NamespaceOrDefault(job.Namespace),
 		Alias:       job.Alias,
 		Labels:      jsonMapToStringMap(job.Labels),
 		Annotations: jsonMapToStringMap(job.Annotations),
@@ -199,7 +225,7 @@ func loadSteps(ctx context.Context, db *gorm.DB, jobID uuid.UUID) ([]StepSpec, e
 	return steps, nil
 }
 
-func collectPath(path string, fn func(*schema.Definition) error) error {
+func collectPath(path string, templates *schema.TemplateRegistry, fn func(string, *schema.Definition)) error {
 	info, err := os.Stat(path)
 	if err != nil {
 		return err
@@ -215,38 +241,50 @@ func collectPath(path string, fn func(*schema.Definition) error) error {
 			if !isYAML(p) {
 				return nil
 			}
-			return decodeDefinitions(p, fn)
+			return decodeDefinitions(p, templates, fn)
 		})
 	}
 	if !isYAML(path) {
 		return fmt.Errorf("%s is not a YAML file", path)
 	}
-	return decodeDefinitions(path, fn)
+	return decodeDefinitions(path, templates, fn)
 }
 
-func decodeDefinitions(path string, fn func(*schema.Definition) error) error {
+// decodeDefinitions registers the StepTemplate documents in path and hands
+// every other non-blank document to fn. Validation is deferred until every
+// template is loaded so a job may use a template declared in another file.
+func decodeDefinitions(path string, templates *schema.TemplateRegistry, fn func(string, *schema.Definition)) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
 	dec := yaml.NewDecoder(bytes.NewReader(data))
 	for {
-		var def schema.Definition
-		if err := dec.Decode(&def); err != nil {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
 			return fmt.Errorf("%s: %w", path, err)
 		}
-		if isBlankDefinition(&def) {
+		tmpl, err := schema.DecodeStepTemplate(&node)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if tmpl != nil {
+			if err := templates.Add(tmpl); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
 			continue
 		}
-		if err := def.Validate(); err != nil {
+		var def schema.Definition
+		if err := node.Decode(&def); err != nil {
 			return fmt.Errorf("%s: %w", path, err)
 		}
-		if err := fn(&def); err != nil {
-			return err
+		if isBlankDefinition(&def) {
+			continue
 		}
+		fn(path, &def)
 	}
 	return nil
 }
@@ -60,17 +60,27 @@ func (s *ExamplesSuite) TestExampleManifestsConformToSchema() {
 		s.Require().NoErrorf(err, "read example %s", path)
 
 		dec := yaml.NewDecoder(bytes.NewReader(data))
+		templates := schema.NewTemplateRegistry()
 		docCount := 0
 		for {
-			var def schema.Definition
-			err := dec.Decode(&def)
+			var node yaml.Node
+			err := dec.Decode(&node)
 			if errors.Is(err, io.EOF) {
 				break
 			}
 			s.Require().NoErrorf(err, "decode %s doc %d", path, docCount)
+			tmpl, err := schema.DecodeStepTemplate(&node)
+			s.Require().NoErrorf(err, "decode %s doc %d", path, docCount)
+			if tmpl != nil {
+				s.Require().NoErrorf(templates.Add(tmpl), "register %s doc %d", path, docCount)
+				continue
+			}
+			var def schema.Definition
+			s.Require().NoErrorf(node.Decode(&def), "decode %s doc %d", path, docCount)
 			if def.APIVersion == "" && def.Kind == "" && def.Metadata.Alias == "" && len(def.Steps) == 0 {
 				continue
 			}
+			s.Require().NoErrorf(def.ExpandTemplates(templates), "expand %s doc %d", path, docCount)
 			s.Require().NoErrorf(def.Validate(), "validate %s doc %d", path, docCount)
 			s.NotEmptyf(def.Metadata.Alias, "alias required in %s doc %d", path, docCount)
 
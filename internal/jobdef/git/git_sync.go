@@ -252,6 +252,7 @@ func (s *Source) applyDir(ctx context.Context, importer *jobdef.Importer, dir st
 	plans := make([]applyFilePlan, 0)
 	defs := make([]schema.Definition, 0)
 	desiredAliases := make([]string, 0)
+	templates := schema.NewTemplateRegistry()
 	for _, path := range files {
 		relToRepo, err := filepath.Rel(dir, path)
 		if err != nil {
@@ -266,21 +267,36 @@ func (s *Source) applyDir(ctx context.Context, importer *jobdef.Importer, dir st
 		}
 
 		opts := &jobdef.ApplyOptions{Provenance: prov}
-		fileDefs, err := collectFileDefinitions(path)
+		fileDefs, err := collectFileDefinitions(path, templates)
 		if err != nil {
 			return err
 		}
 		for _, def := range fileDefs {
 			def := def
-			desiredAliases = append(desiredAliases, def.Metadata.Alias)
-			defs = append(defs, def)
 			plans = append(plans, applyFilePlan{def: &def, opts: opts})
 		}
 	}
 
+	// Templates may live in any file of the source, so steps are expanded only
+	// once the whole tree has been read.
+	for _, plan := range plans {
+		if err := plan.def.ExpandTemplates(templates); err != nil {
+			return fmt.Errorf("%s: %w", plan.opts.Provenance.Path, err)
+		}
+		desiredAliases = append(desiredAliases, schema.QualifiedAlias(plan.def.Metadata.Namespace, plan.def.Metadata.Alias))
+		defs = append(defs, *plan.def)
+	}
+
 	if err := importer.ValidateBatch(ctx, defs); err != nil {
 		return err
 	}
+	actor := "git-sync"
+	if id := strings.TrimSpace(s.SourceID); id != "" {
+		actor += ":" + id
+	}
+	if err := importer.ValidateImagePolicy(ctx, defs, actor); err != nil {
+		return err
+	}
 
 	for _, plan := range plans {
 		if _, err := importer.ApplyWithOptions(ctx, plan.def, plan.opts); err != nil {
@@ -297,7 +313,9 @@ func (s *Source) applyDir(ctx context.Context, importer *jobdef.Importer, dir st
 	return nil
 }
 
-func collectFileDefinitions(path string) ([]schema.Definition, error) {
+// collectFileDefinitions returns the job definitions in path and registers any
+// StepTemplate documents it declares with templates.
+func collectFileDefinitions(path string, templates *schema.TemplateRegistry) ([]schema.Definition, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -306,14 +324,28 @@ func collectFileDefinitions(path string) ([]schema.Definition, error) {
 	dec := yamlNewDecoder(data)
 	defs := make([]schema.Definition, 0)
 	for {
-		def, err := dec()
+		node, err := dec()
 		if errors.Is(err, errEOF) {
 			return defs, nil
 		}
 		if err != nil {
 			return nil, err
 		}
-		defs = append(defs, *def)
+		tmpl, err := schema.DecodeStepTemplate(node)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl != nil {
+			if err := templates.Add(tmpl); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var def schema.Definition
+		if err := node.Decode(&def); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
 	}
 }
 
@@ -667,17 +699,17 @@ func (s *Source) shouldInclude(fullPath, relative string) bool {
 
 var errEOF = errors.New("eof")
 
-func yamlNewDecoder(data []byte) func() (*schema.Definition, error) {
+func yamlNewDecoder(data []byte) func() (*yaml.Node, error) {
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 
-	return func() (*schema.Definition, error) {
-		var def schema.Definition
-		if err := decoder.Decode(&def); err != nil {
+	return func() (*yaml.Node, error) {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
 			if errors.Is(err, io.EOF) {
 				return nil, errEOF
 			}
 			return nil, err
 		}
-		return &def, nil
+		return &node, nil
 	}
 }
@@ -0,0 +1,60 @@
+package jobdef
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/internal/imagepolicy"
+	schema "github.com/caesium-cloud/caesium/pkg/jobdef"
+)
+
+// CheckImagePolicy evaluates every step image in defs against policy. It
+// performs no auditing, so POST /v1/jobdefs/lint can report findings without
+// recording a lint as an attempted apply.
+func CheckImagePolicy(ctx context.Context, policy *imagepolicy.Policy, defs []schema.Definition) []imagepolicy.Decision {
+	if policy.Mode() == imagepolicy.ModeOff {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var decisions []imagepolicy.Decision
+	for i := range defs {
+		def := &defs[i]
+		for _, step := range def.Steps {
+			d := policy.Check(ctx, imagepolicy.Request{
+				Job:    schema.QualifiedAlias(def.Metadata.Namespace, def.Metadata.Alias),
+				Step:   step.Name,
+				Image:  step.Image,
+				Labels: def.Metadata.Labels,
+			})
+			if len(d.Violations) > 0 {
+				decisions = append(decisions, d)
+			}
+		}
+	}
+	return decisions
+}
+
+// ValidateImagePolicy checks defs against the process image policy before
+// they are applied and audits every violation as actor. Under an enforcing
+// policy any violation rejects the batch; under a warning policy the batch
+// is applied and only the audit entries remain. Called from POST
+// /v1/jobdefs/apply and git sync after ValidateBatch.
+func (i *Importer) ValidateImagePolicy(ctx context.Context, defs []schema.Definition, actor string) error {
+	policy := imagepolicy.Default()
+	var denied []string
+	for _, d := range CheckImagePolicy(ctx, policy, defs) {
+		policy.Audit(i.db, actor, d)
+		if d.Denied {
+			for _, v := range d.Violations {
+				denied = append(denied, v.Job+": "+v.String())
+			}
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", imagepolicy.ErrDenied, strings.Join(denied, "; "))
+}
@@ -125,7 +125,7 @@ func (i *Importer) ApplyWithOptions(ctx context.Context, def *schema.Definition,
 	err := withImporterBusyRetry(ctx, func() error {
 		var attemptResult *models.Job
 		err := i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			existing, err := i.findJobByAliasTx(tx, def.Metadata.Alias)
+			existing, err := i.findJobByAliasTx(tx, def.Metadata.Namespace, def.Metadata.Alias)
 			if err != nil {
 				return err
 			}
@@ -220,8 +220,9 @@ func (i *Importer) enforceContractsWithOptionsTx(ctx context.Context, tx *gorm.D
 	return nil
 }
 
-// PruneMissing retires active jobs that are absent from the desired alias set.
-// When opts.SourceID is set, pruning is scoped to jobs imported by that source.
+// PruneMissing retires active jobs that are absent from the desired alias set,
+// whose entries are namespace-qualified as by schema.QualifiedAlias. When
+// opts.SourceID is set, pruning is scoped to jobs imported by that source.
 func (i *Importer) PruneMissing(ctx context.Context, desiredAliases []string, opts *PruneOptions) (int, error) {
 	desiredSet := make(map[string]struct{}, len(desiredAliases))
 	for _, alias := range desiredAliases {
@@ -249,7 +250,7 @@ func (i *Importer) PruneMissing(ctx context.Context, desiredAliases []string, op
 			toRetire := make([]*models.Job, 0, len(jobs))
 			for idx := range jobs {
 				jobModel := &jobs[idx]
-				if _, ok := desiredSet[jobModel.Alias]; ok {
+				if _, ok := desiredSet[schema.QualifiedAlias(jobModel.Namespace, jobModel.Alias)]; ok {
 					continue
 				}
 				toRetire = append(toRetire, jobModel)
@@ -333,9 +334,9 @@ func isImporterContentionErr(err error) bool {
 	return dqlite.IsContentionError(err)
 }
 
-func (i *Importer) findJobByAliasTx(tx *gorm.DB, alias string) (*models.Job, error) {
+func (i *Importer) findJobByAliasTx(tx *gorm.DB, namespace, alias string) (*models.Job, error) {
 	var jobModel models.Job
-	err := tx.Unscoped().Where("alias = ?", alias).First(&jobModel).Error
+	err := tx.Unscoped().Where("namespace = ? AND alias = ?", schema.NamespaceOrDefault(namespace), alias).First(&jobModel).Error
 	switch {
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		return nil, nil
@@ -426,7 +427,7 @@ func (i *Importer) ensureJobsNotRunningTx(tx *gorm.DB, jobs []*models.Job) error
 }
 
 func (i *Importer) upsertJobAndTriggerTx(tx *gorm.DB, existing *models.Job, def *schema.Definition, opts *ApplyOptions) (*models.Job, *models.Trigger, error) {
-	triggerModel, err := i.upsertTriggerTx(tx, existing, def.Metadata.Alias, &def.Trigger, opts)
+	triggerModel, err := i.upsertTriggerTx(tx, existing, def.Metadata.Namespace, def.Metadata.Alias, &def.Trigger, def.Metadata.RunTemplates, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -443,10 +444,15 @@ func (i *Importer) upsertJobAndTriggerTx(tx *gorm.DB, existing *models.Job, def
 	if err != nil {
 		return nil, nil, fmt.Errorf("metadata.rateLimits: %w", err)
 	}
+	retention, err := marshalOptionalJSON(def.Metadata.Retention)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metadata.retention: %w", err)
+	}
 
 	if existing == nil {
 		jobModel := &models.Job{
 			ID:               uuid.New(),
+			Namespace:        schema.NamespaceOrDefault(def.Metadata.Namespace),
 			Alias:            def.Metadata.Alias,
 			TriggerID:        triggerModel.ID,
 			Labels:           jsonmap.FromStringMap(def.Metadata.Labels),
@@ -458,8 +464,10 @@ func (i *Importer) upsertJobAndTriggerTx(tx *gorm.DB, existing *models.Job, def
 			Concurrency:      concurrency,
 			RateLimits:       rateLimits,
 			SLA:              marshalSLA(def.Metadata.SLA),
+			Retention:        retention,
 			SchemaValidation: def.Metadata.SchemaValidation,
 			ReplaySafe:       def.Metadata.ReplaySafe,
+			RunTemplates:     def.Metadata.RunTemplates,
 			CacheConfig:      cacheConfig,
 		}
 		applyJobProvenance(jobModel, opts)
@@ -480,8 +488,10 @@ func (i *Importer) upsertJobAndTriggerTx(tx *gorm.DB, existing *models.Job, def
 	existing.Concurrency = concurrency
 	existing.RateLimits = rateLimits
 	existing.SLA = marshalSLA(def.Metadata.SLA)
+	existing.Retention = retention
 	existing.SchemaValidation = def.Metadata.SchemaValidation
 	existing.ReplaySafe = def.Metadata.ReplaySafe
+	existing.RunTemplates = def.Metadata.RunTemplates
 	existing.CacheConfig = cacheConfig
 	applyJobProvenance(existing, opts)
 
@@ -497,8 +507,10 @@ func (i *Importer) upsertJobAndTriggerTx(tx *gorm.DB, existing *models.Job, def
 		"concurrency":          existing.Concurrency,
 		"rate_limits":          existing.RateLimits,
 		"sla":                  existing.SLA,
+		"retention":            existing.Retention,
 		"schema_validation":    existing.SchemaValidation,
 		"replay_safe":          existing.ReplaySafe,
+		"run_templates":        existing.RunTemplates,
 		"cache_config":         existing.CacheConfig,
 		"provenance_source_id": existing.ProvenanceSourceID,
 		"provenance_repo":      existing.ProvenanceRepo,
@@ -514,7 +526,7 @@ func (i *Importer) upsertJobAndTriggerTx(tx *gorm.DB, existing *models.Job, def
 	return existing, triggerModel, nil
 }
 
-func (i *Importer) upsertTriggerTx(tx *gorm.DB, existingJob *models.Job, alias string, trig *schema.Trigger, opts *ApplyOptions) (*models.Trigger, error) {
+func (i *Importer) upsertTriggerTx(tx *gorm.DB, existingJob *models.Job, namespace, alias string, trig *schema.Trigger, runTemplates bool, opts *ApplyOptions) (*models.Trigger, error) {
 	cfgMap := cloneAnyMap(trig.Configuration)
 	if cfgMap == nil {
 		cfgMap = make(map[string]any)
@@ -522,6 +534,12 @@ func (i *Importer) upsertTriggerTx(tx *gorm.DB, existingJob *models.Job, alias s
 	if len(trig.DefaultParams) > 0 {
 		cfgMap["defaultParams"] = trig.DefaultParams
 	}
+	// Triggers render defaultParams only for jobs that opted in.
+	if runTemplates {
+		cfgMap["runTemplates"] = true
+	} else {
+		delete(cfgMap, "runTemplates")
+	}
 	cfg, err := jsonutil.MarshalMapString(cfgMap)
 	if err != nil {
 		return nil, err
@@ -541,6 +559,7 @@ func (i *Importer) upsertTriggerTx(tx *gorm.DB, existingJob *models.Job, alias s
 		triggerModel = &models.Trigger{ID: uuid.New()}
 	}
 
+	triggerModel.Namespace = schema.NamespaceOrDefault(namespace)
 	triggerModel.Alias = alias
 	triggerModel.Type = models.TriggerType(trig.Type)
 	triggerModel.Configuration = cfg
@@ -557,6 +576,7 @@ func (i *Importer) upsertTriggerTx(tx *gorm.DB, existingJob *models.Job, alias s
 	}
 
 	updates := map[string]any{
+		"namespace":            triggerModel.Namespace,
 		"alias":                triggerModel.Alias,
 		"type":                 triggerModel.Type,
 		"normalized_path":      triggerModel.NormalizedPath,
@@ -671,6 +691,7 @@ func (i *Importer) reconcileTasksTx(tx *gorm.DB, jobModel *models.Job, def *sche
 				"name":                taskModel.Name,
 				"type":                taskModel.Type,
 				"node_selector":       taskModel.NodeSelector,
+				"node_affinity":       taskModel.NodeAffinity,
 				"retries":             taskModel.Retries,
 				"retry_delay":         taskModel.RetryDelay,
 				"retry_backoff":       taskModel.RetryBackoff,
@@ -678,9 +699,14 @@ func (i *Importer) reconcileTasksTx(tx *gorm.DB, jobModel *models.Job, def *sche
 				"replay_safe":         taskModel.ReplaySafe,
 				"rate_limit_resource": taskModel.RateLimitResource,
 				"rate_limit_units":    taskModel.RateLimitUnits,
+				"pool_name":           taskModel.PoolName,
+				"pool_slots":          taskModel.PoolSlots,
 				"cache_config":        taskModel.CacheConfig,
 				"output_schema":       taskModel.OutputSchema,
 				"input_schema":        taskModel.InputSchema,
+				"map_config":          taskModel.MapConfig,
+				"gate_config":         taskModel.GateConfig,
+				"sensor_config":       taskModel.SensorConfig,
 				"position":            taskModel.Position,
 				"deleted_at":          nil,
 			}
@@ -1081,11 +1107,30 @@ func populateTaskFromStep(taskModel *models.Task, atomID uuid.UUID, step *schema
 	if err != nil {
 		return fmt.Errorf("step %s: inputSchema: %w", step.Name, err)
 	}
+	mapConfig, err := marshalOptionalJSON(step.Map)
+	if err != nil {
+		return fmt.Errorf("step %s: map: %w", step.Name, err)
+	}
+	gateConfig, err := marshalOptionalJSON(step.Gate)
+	if err != nil {
+		return fmt.Errorf("step %s: gate: %w", step.Name, err)
+	}
+	sensorConfig, err := marshalOptionalJSON(step.Sensor)
+	if err != nil {
+		return fmt.Errorf("step %s: sensor: %w", step.Name, err)
+	}
+	var nodeAffinity datatypes.JSON
+	if !step.NodeAffinity.IsZero() {
+		if nodeAffinity, err = marshalOptionalJSON(step.NodeAffinity); err != nil {
+			return fmt.Errorf("step %s: nodeAffinity: %w", step.Name, err)
+		}
+	}
 
 	taskModel.AtomID = atomID
 	taskModel.Name = step.Name
 	taskModel.Type = stepType
 	taskModel.NodeSelector = jsonmap.FromStringMap(step.NodeSelector)
+	taskModel.NodeAffinity = nodeAffinity
 	taskModel.Retries = step.Retries
 	taskModel.RetryDelay = step.RetryDelay
 	taskModel.RetryBackoff = step.RetryBackoff
@@ -1097,9 +1142,18 @@ func populateTaskFromStep(taskModel *models.Task, atomID uuid.UUID, step *schema
 		taskModel.RateLimitResource = strings.TrimSpace(step.RateLimit.Resource)
 		taskModel.RateLimitUnits = step.RateLimit.Units
 	}
+	taskModel.PoolName = ""
+	taskModel.PoolSlots = 0
+	if step.Pool != nil {
+		taskModel.PoolName = strings.TrimSpace(step.Pool.Name)
+		taskModel.PoolSlots = max(step.Pool.Slots, 1)
+	}
 	taskModel.CacheConfig = cacheConfig
 	taskModel.OutputSchema = outputSchema
 	taskModel.InputSchema = inputSchema
+	taskModel.MapConfig = mapConfig
+	taskModel.GateConfig = gateConfig
+	taskModel.SensorConfig = sensorConfig
 	return nil
 }
 
@@ -154,6 +154,58 @@ steps:
 	s.True(atoms[1].ReplaySafe)
 }
 
+func (s *ImporterTestSuite) TestApplyKeepsLegacyTemplateSyntaxVerbatim() {
+	const legacy = `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: dbt-models
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+  defaultParams: {target: "{{ env_var('DBT_TARGET') }}"}
+steps:
+  - name: run
+    image: ghcr.io/dbt-labs/dbt-core:1.8.0
+    command: ["dbt", "run", "--vars", "{day: '{{ var(\"day\") }}'}"]
+    env: {SELECTOR: "{{ ref('orders') }}"}
+`
+	def, err := schema.Parse([]byte(legacy))
+	s.Require().NoError(err)
+
+	job, err := s.importer.Apply(context.Background(), def)
+	s.Require().NoError(err)
+	s.False(job.RunTemplates)
+
+	var trigger models.Trigger
+	s.Require().NoError(s.db.First(&trigger, "id = ?", job.TriggerID).Error)
+	var cfg map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(trigger.Configuration), &cfg))
+	s.NotContains(cfg, "runTemplates")
+	s.Equal(map[string]any{"target": "{{ env_var('DBT_TARGET') }}"}, cfg["defaultParams"])
+
+	var task models.Task
+	s.Require().NoError(s.db.First(&task, "job_id = ?", job.ID).Error)
+	var atom models.Atom
+	s.Require().NoError(s.db.First(&atom, "id = ?", task.AtomID).Error)
+	s.Equal([]string{"dbt", "run", "--vars", `{day: '{{ var("day") }}'}`}, atom.Cmd())
+	s.Equal("{{ ref('orders') }}", atom.ContainerSpec().Env["SELECTOR"])
+
+	// Opting in marks the trigger so it renders defaultParams.
+	optIn := strings.Replace(legacy, "alias: dbt-models", "alias: dbt-models\n  runTemplates: true", 1)
+	optIn = strings.Replace(optIn, "{{ env_var('DBT_TARGET') }}", "{{ ds .LogicalDate }}", 1)
+	optIn = strings.Replace(optIn, `"{day: '{{ var(\"day\") }}'}"`, `"{{ .JobAlias }}"`, 1)
+	optIn = strings.Replace(optIn, "{{ ref('orders') }}", "{{ .Params.target }}", 1)
+	def, err = schema.Parse([]byte(optIn))
+	s.Require().NoError(err)
+	job, err = s.importer.Apply(context.Background(), def)
+	s.Require().NoError(err)
+	s.True(job.RunTemplates)
+	s.Require().NoError(s.db.First(&trigger, "id = ?", job.TriggerID).Error)
+	s.Require().NoError(json.Unmarshal([]byte(trigger.Configuration), &cfg))
+	s.Equal(true, cfg["runTemplates"])
+}
+
 func (s *ImporterTestSuite) TestApplyUpdatesSchedulingMetadata() {
 	const initial = `
 apiVersion: v1
@@ -207,6 +259,7 @@ steps:
     rateLimit:
       resource: database
       units: 3
+    pool: {name: warehouse, slots: 2}
 `
 	def, err = schema.Parse([]byte(updated))
 	s.Require().NoError(err)
@@ -231,6 +284,8 @@ steps:
 	s.Require().Len(tasks, 1)
 	s.Equal("database", tasks[0].RateLimitResource)
 	s.Equal(3, tasks[0].RateLimitUnits)
+	s.Equal("warehouse", tasks[0].PoolName)
+	s.Equal(2, tasks[0].PoolSlots)
 }
 
 func (s *ImporterTestSuite) TestApplyStoresUnsetSchedulingMetadataAsSQLNull() {
@@ -424,6 +479,35 @@ func (s *ImporterTestSuite) TestPruneMissingSoftDeletesJobs() {
 	s.Equal(int64(2), totalJobs)
 }
 
+func (s *ImporterTestSuite) TestApplyScopesAliasesByNamespace() {
+	ctx := context.Background()
+	def, err := schema.Parse([]byte(testutil.SampleJob))
+	s.Require().NoError(err)
+	defaultJob, err := s.importer.Apply(ctx, def)
+	s.Require().NoError(err)
+	s.Equal(schema.DefaultNamespace, defaultJob.Namespace)
+
+	teamA := strings.Replace(testutil.SampleJob, "  alias: csv-to-parquet", "  alias: csv-to-parquet\n  namespace: team-a", 1)
+	def, err = schema.Parse([]byte(teamA))
+	s.Require().NoError(err)
+	teamJob, err := s.importer.Apply(ctx, def)
+	s.Require().NoError(err)
+	s.NotEqual(defaultJob.ID, teamJob.ID)
+	s.Equal("team-a", teamJob.Namespace)
+
+	var trigger models.Trigger
+	s.Require().NoError(s.db.First(&trigger, "id = ?", teamJob.TriggerID).Error)
+	s.Equal("team-a", trigger.Namespace)
+	testutil.AssertCount(s.T(), s.db, &models.Job{}, 2)
+
+	pruned, err := s.importer.PruneMissing(ctx, []string{"team-a/csv-to-parquet"}, nil)
+	s.Require().NoError(err)
+	s.Equal(1, pruned)
+	var remaining models.Job
+	s.Require().NoError(s.db.First(&remaining).Error)
+	s.Equal(teamJob.ID, remaining.ID)
+}
+
 func (s *ImporterTestSuite) TestApplyRestoresRetiredTasksAndCallbacks() {
 	def, err := schema.Parse([]byte(testutil.SampleJob))
 	s.Require().NoError(err)
@@ -654,6 +738,41 @@ steps:
 	}, tasks[0].NodeSelector)
 }
 
+func (s *ImporterTestSuite) TestApplyPersistsStepNodeAffinity() {
+	const manifest = `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: affinity-job
+trigger:
+  type: cron
+  configuration:
+    cron: "* * * * *"
+steps:
+  - name: build
+    image: repo/build
+    nodeAffinity:
+      required:
+        - {key: arch, operator: In, values: [amd64, arm64]}
+      spread: true
+  - name: publish
+    image: repo/publish
+    nodeAffinity: {}
+`
+
+	def, err := schema.Parse([]byte(manifest))
+	s.Require().NoError(err)
+
+	job, err := s.importer.Apply(context.Background(), def)
+	s.Require().NoError(err)
+
+	var tasks []models.Task
+	s.Require().NoError(s.db.Where("job_id = ?", job.ID).Order("position").Find(&tasks).Error)
+	s.Require().Len(tasks, 2)
+	s.JSONEq(`{"required":[{"key":"arch","operator":"In","values":["amd64","arm64"]}],"spread":true}`, string(tasks[0].NodeAffinity))
+	s.Empty(tasks[1].NodeAffinity, "an empty nodeAffinity block is not stored")
+}
+
 // TestDagSnapshotWrittenOnApply verifies that a DagSnapshot row is written when
 // a job is first applied.
 func (s *ImporterTestSuite) TestDagSnapshotWrittenOnApply() {
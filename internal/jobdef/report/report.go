@@ -74,16 +74,21 @@ func Markdown() string {
 	b.WriteString("| Field | Type | Required | Notes |\n")
 	b.WriteString("|-------|------|----------|-------|\n")
 	b.WriteString("| `alias` | string | required | Unique identifier used across APIs and web UI. |\n")
+	b.WriteString("| `namespace` | string | optional | Namespace the job belongs to; defaults to `default`. Aliases are unique per namespace. Lowercase alphanumerics separated by `-`, max 63 characters. |\n")
 	b.WriteString("| `labels` | map[string]string | optional | Attach metadata for filtering. |\n")
 	b.WriteString("| `annotations` | map[string]string | optional | Free-form metadata surfaced to clients. |\n")
 	b.WriteString("| `maxParallelTasks` | integer | optional | Caps concurrent runnable steps for a single job run. |\n")
 	b.WriteString("| `taskTimeout` | duration | optional | Default timeout applied to each step unless overridden by runtime configuration. |\n")
 	b.WriteString("| `runTimeout` | duration | optional | Maximum total wall-clock time for the job run. |\n")
+	b.WriteString("| `sla` | object | optional | Alerting deadlines: `duration` (measured from run start) and `completedBy` (`HH:MM` UTC). Emits `sla_missed` on a breach and `sla_at_risk` when the run's predicted completion falls after a deadline. Never cancels the run. |\n")
+	b.WriteString("| `retention` | object | optional | Run retention: `keepRuns` (newest runs always kept), `keepDays`, and `keepFailedDays` (longer window for failed runs; must be >= `keepDays`). A finished run is purged, from hot and history alike, once it is outside both `keepRuns` and its age window. Overrides the server default. |\n")
 	b.WriteString("| `priority` | string | optional | Run and task scheduling priority: `high`, `normal`, or `low`. Scheduling metadata excluded from the cache identity hash. |\n")
 	b.WriteString("| `concurrency` | object | optional | Run-level concurrency control with `maxRuns` and `strategy` (`queue`, `replace`, `skip`, or `fail`); `strategy` defaults to `queue`. Scheduling metadata excluded from the cache identity hash. |\n")
 	b.WriteString("| `rateLimits` | array[object] | optional | Shared resource budgets declared as `{resource, limit, window}`. `window` is a duration string. Scheduling metadata excluded from the cache identity hash. |\n")
+	b.WriteString("| `resources` | object | optional | Default compute for every step: `cpu`, `memory`, `ephemeralStorage`, `gpu-count`. Step-level `resources` override these field by field. Scheduling metadata excluded from the cache identity hash. |\n")
 	b.WriteString("| `schemaValidation` | string | optional | Runtime output validation mode: `warn` or `fail`. Empty disables validation. |\n")
 	b.WriteString("| `replaySafe` | boolean | optional | Marks every step in this job as eligible for quarantined what-if replay. Recorded on each baseline task run; excluded from the cache identity hash. |\n")
+	b.WriteString("| `runTemplates` | boolean | optional | Renders Go template expressions in step `command` and `env` values and `trigger.defaultParams`. Off by default, so `{{` passes through verbatim. |\n")
 	b.WriteString("| `cache` | boolean or object | optional | Job-level cache defaults; accepts `true`, `{ttl: \"24h\"}`, or `{pinDigests: true}`. Step-level `cache` overrides these defaults. |\n")
 	b.WriteString("| `serviceAccountName` | string | optional | Default Kubernetes ServiceAccount for Kubernetes steps. |\n")
 	b.WriteString("| `podAnnotations` | map[string]string | optional | Default annotations applied to Kubernetes step pods. |\n")
@@ -96,7 +101,7 @@ func Markdown() string {
 	b.WriteString("### Common Trigger Fields\n")
 	b.WriteString("| Field | Type | Required | Notes |\n")
 	b.WriteString("|-------|------|----------|-------|\n")
-	b.WriteString("| `defaultParams` | map[string]string | optional | Seeds run parameters when a trigger fires. |\n\n")
+	b.WriteString("| `defaultParams` | map[string]string | optional | Seeds run parameters when a trigger fires. Values may be run templates. Cron fires also set `logical_date`, `data_interval_start`, and `data_interval_end`. |\n\n")
 	b.WriteString("### Cron Trigger\n")
 	b.WriteString("| Field | Type | Required | Notes |\n")
 	b.WriteString("|-------|------|----------|-------|\n")
@@ -144,32 +149,87 @@ func Markdown() string {
 	b.WriteString("| Field | Type | Required | Notes |\n")
 	b.WriteString("|-------|------|----------|-------|\n")
 	b.WriteString("| `name` | string | required | Unique within the job; used for DAG references. |\n")
-	b.WriteString("| `type` | string | optional | Step kind. Defaults to `task`; `branch` enables conditional fan-out. |\n")
+	b.WriteString("| `type` | string | optional | Step kind. Defaults to `task`; `branch` enables conditional fan-out; `sensor` waits for an external condition without running a container. |\n")
 	b.WriteString("| `engine` | string | optional | One of `docker`, `podman`, `kubernetes`. Defaults to `docker`. |\n")
-	b.WriteString("| `image` | string | required | Container image reference. |\n")
-	b.WriteString("| `command` | array[string] | optional | Executed command; defaults to entrypoint. |\n")
-	b.WriteString("| `env` | map[string]string | optional | Environment variables passed to the runtime. |\n")
+	b.WriteString("| `image` | string | required | Container image reference. Supplied by the template when `uses` is set. Not used by `sensor` steps. |\n")
+	b.WriteString("| `command` | array[string] | optional | Executed command; defaults to entrypoint. Elements may be run templates such as `{{ .Params.region }}`. |\n")
+	b.WriteString("| `env` | map[string]string | optional | Environment variables passed to the runtime. Values may be run templates. |\n")
 	b.WriteString("| `workdir` | string | optional | Working directory inside the container runtime. |\n")
 	b.WriteString("| `mounts` | array[object] | optional | Bind mounts with `source`, `target`, and optional `readOnly`. |\n")
 	b.WriteString("| `volumeMounts` | array[object] | optional | Declared volume mounts with `volume`, `path`, optional `readOnly`, and optional `subPath`. |\n")
 	b.WriteString("| `nodeSelector` | map[string]string | optional | Node labels required for claiming this step in distributed mode. |\n")
+	b.WriteString("| `nodeAffinity` | object | optional | Expression-based node placement in distributed mode: `required` and `preferred` match expressions plus `spread`. Applies alongside `nodeSelector`. See [Node Affinity](#node-affinity). Scheduling metadata excluded from the cache identity hash. |\n")
 	b.WriteString("| `serviceAccountName` | string | optional | Kubernetes ServiceAccount for this step's pod. |\n")
 	b.WriteString("| `podAnnotations` | map[string]string | optional | Kubernetes pod annotations for this step. |\n")
 	b.WriteString("| `automountServiceAccountToken` | boolean | optional | Kubernetes pod service-account token setting for this step. |\n")
 	b.WriteString("| `kueue` | object | optional | Delegate this step's admission to a Kueue LocalQueue (kubernetes engine only). See [Kueue](#kueue) below. Excluded from the cache identity hash — it is scheduling metadata, not an execution input. |\n")
 	b.WriteString("| `rateLimit` | object | optional | Consume units from a job-level `metadata.rateLimits` resource: `{resource, units}`. Scheduling metadata excluded from the cache identity hash. |\n")
+	b.WriteString("| `pool` | object | optional | Occupy slots of a cluster-wide task pool managed with `caesium pool`: `{name, slots}`. `slots` defaults to `1`. The task waits until the pool has room. Scheduling metadata excluded from the cache identity hash. |\n")
+	b.WriteString("| `resources` | object | optional | Compute requested for this step's container: `cpu` (`500m`, `2`), `memory` and `ephemeralStorage` (`512Mi`, `1G`), and `gpu-count`. Merged over `metadata.resources`. Scheduling metadata excluded from the cache identity hash. |\n")
 	b.WriteString("| `replaySafe` | boolean | optional | Marks this step as eligible for quarantined what-if replay. The effective value (`metadata.replaySafe` or this field) is recorded on the baseline task run and excluded from the cache identity hash. |\n")
+	b.WriteString("| `uses` | string | optional | Expands a `StepTemplate` referenced as `<name>@<version>`. The step may not also set `image` or `command`. See [Step Templates](#step-templates). |\n")
+	b.WriteString("| `with` | map[string]any | optional | Parameter bindings for the `uses` template; each value must match the declared parameter type. |\n")
 	b.WriteString("| `next` | array[string] | optional | Successor steps triggered when this step completes. Accepts either a string or list in manifests. |\n")
 	b.WriteString("| `dependsOn` | array[string] | optional | Predecessor steps that must complete before this step can run. |\n")
 	b.WriteString("| `retries` | integer | optional | Number of retry attempts after the initial failure. |\n")
 	b.WriteString("| `retryDelay` | duration | optional | Base delay between retry attempts. |\n")
 	b.WriteString("| `retryBackoff` | boolean | optional | Doubles `retryDelay` for each retry attempt when enabled. |\n")
 	b.WriteString("| `triggerRule` | string | optional | Upstream completion policy such as `all_success`, `all_done`, or `one_success`. |\n")
+	b.WriteString("| `map` | object | optional | Run the step once per element of a JSON array output: `{over: \"<step>.<output key>\", maxParallel: N}`. See [Mapped Steps](#mapped-steps). |\n")
+	b.WriteString("| `gate` | object | optional | Hold the step for human approval once its dependencies are satisfied: `{type: approval, approvers: [...], timeout: 4h, onTimeout: fail}`. See [Approval Gates](#approval-gates). Excluded from the cache identity hash. |\n")
+	b.WriteString("| `sensor` | object | optional | Probe a `type: sensor` step evaluates until its condition holds: exactly one of `http`, `job`, `dataset`, or `file`, plus `pokeInterval`, `timeout`, `mode`, and `onTimeout`. See [Sensors](#sensors). |\n")
 	b.WriteString("| `outputSchema` | object | optional | JSON Schema fragment describing this step's emitted outputs. |\n")
 	b.WriteString("| `inputSchema` | map[string]object | optional | Required output keys per predecessor step for contract validation. |\n")
 	b.WriteString("| `datasets` | object | optional | Per-step dataset surface: `consumes` (legacy dataset names or objects with `name`/`schema`) and `produces` (datasets with freshness SLOs and optional contract schemas). See [Datasets & Freshness](#datasets--freshness). Scheduling and apply-time contract metadata are excluded from the cache identity hash. |\n")
 	b.WriteString("| `cache` | boolean or object | optional | Enable task caching; accepts `true`, `false`, `{ttl: \"12h\"}`, `{ttl: \"12h\", version: 2}`, `{pinDigests: true}`, or `{pinDigests: true, digestTTL: 0}`. |\n\n")
 
+	b.WriteString("### Step Templates\n\n")
+	b.WriteString("A `StepTemplate` document (`apiVersion: v1`, `kind: StepTemplate`) publishes a reusable step. Loaders (`caesium job apply`, `lint`, `diff`, and Git sync) expand every `uses` reference into an inline step before validation, so the server and the cache identity hash only see the expanded step.\n\n")
+	b.WriteString("| Field | Type | Required | Notes |\n")
+	b.WriteString("|-------|------|----------|-------|\n")
+	b.WriteString("| `metadata.name` | string | required | Lowercase DNS-1123 label. |\n")
+	b.WriteString("| `metadata.version` | string | required | Version referenced after the `@` in `uses`. |\n")
+	b.WriteString("| `metadata.description` | string | optional | Free-form description. |\n")
+	b.WriteString("| `parameters` | array[object] | optional | Typed inputs `{name, type, required, default, description}`; `type` is `string`, `integer`, `number`, or `boolean`. A required parameter may not declare a default. |\n")
+	b.WriteString("| `spec` | object | required | Step body with any step field except `name`, `next`, `dependsOn`, `triggerRule`, `uses`, and `with`; must set `image` unless `type` is `sensor`. Scalars may use Go template expressions over the parameters with the `default`, `upper`, and `lower` functions. |\n\n")
+
+	b.WriteString("### Mapped Steps\n\n")
+	b.WriteString("A step with `map` fans out at runtime over a list emitted by one of its direct predecessors. Each element becomes one instance with its own status, retries, and cache identity; successors still see the step as a single node whose outputs are JSON arrays aligned by instance index.\n\n")
+	b.WriteString("| Field | Type | Required | Notes |\n")
+	b.WriteString("|-------|------|----------|-------|\n")
+	b.WriteString("| `over` | string | required | `<step>.<output key>` naming a direct predecessor's output whose value is a JSON array, encoded as a string, of at most 1024 elements. The predecessor may not itself be mapped. |\n")
+	b.WriteString("| `maxParallel` | integer | optional | Maximum instances running at once. `0` (the default) runs every instance concurrently. |\n\n")
+	b.WriteString("Instances receive `CAESIUM_MAP_ITEM` (string elements verbatim, other elements as compact JSON), `CAESIUM_MAP_INDEX`, and `CAESIUM_MAP_COUNT`. `map` is not allowed on `branch` steps.\n\n")
+
+	b.WriteString("### Node Affinity\n\n")
+	b.WriteString("`nodeAffinity` places a step by the `CAESIUM_NODE_LABELS` of distributed workers. A node must satisfy both `nodeSelector` and every `required` expression to run the step.\n\n")
+	b.WriteString("| Field | Type | Required | Notes |\n")
+	b.WriteString("|-------|------|----------|-------|\n")
+	b.WriteString("| `required` | array[object] | optional | Match expressions `{key, operator, values}` that must all hold on the node. |\n")
+	b.WriteString("| `preferred` | array[object] | optional | Weighted terms `{weight, match}`. `weight` is 1-100; a node scores the summed weight of the terms whose `match` expressions all hold, and higher-scoring nodes are preferred. |\n")
+	b.WriteString("| `spread` | boolean | optional | Prefer a node that is not already running another task of the same run. |\n\n")
+	b.WriteString("Operators: `In` and `NotIn` compare the label value against `values` (at least one required); `Exists` and `DoesNotExist` test only the key and take no `values`. A node without the key satisfies `NotIn`.\n\n")
+
+	b.WriteString("### Approval Gates\n\n")
+	b.WriteString("A step with `gate` parks in `awaiting_approval` when it becomes ready and emits a `task_awaiting_approval` event. It runs once an operator calls `POST /v1/jobs/:id/runs/:run_id/tasks/:task/approve`; `.../reject` fails it. Pending requests survive restarts and are expired by the leader.\n\n")
+	b.WriteString("| Field | Type | Required | Notes |\n")
+	b.WriteString("|-------|------|----------|-------|\n")
+	b.WriteString("| `type` | string | optional | Only `approval` is supported (the default). |\n")
+	b.WriteString("| `approvers` | array[string] | optional | SSO groups allowed to decide. When empty, any principal with the `operator` role may decide. |\n")
+	b.WriteString("| `timeout` | duration | optional | How long the request stays open. `0` (the default) waits indefinitely. |\n")
+	b.WriteString("| `onTimeout` | string | optional | `fail` (the default) fails the step when the request expires; `skip` skips it, and descendants follow their trigger rules as for any skipped step. |\n\n")
+	b.WriteString("### Sensors\n\n")
+	b.WriteString("A `type: sensor` step runs no container: Caesium evaluates its probe every `pokeInterval` until the condition holds, then succeeds with the probe's details as step outputs. Every poke is recorded and shown by `caesium why`. Sensors may not set `command`, `map`, `gate`, or `cache`.\n\n")
+	b.WriteString("| Field | Type | Required | Notes |\n")
+	b.WriteString("|-------|------|----------|-------|\n")
+	b.WriteString("| `http` | object | one probe | `{url, method, headers, expectStatus}`. Satisfied when a `GET` (default) or `HEAD` answers with one of `expectStatus` (default `[200]`). `url` and header values may be run templates. |\n")
+	b.WriteString("| `job` | object | one probe | `{alias, namespace, maxAge, sameLogicalDate}`. Satisfied by a succeeded run of job `alias` in `namespace` (default: the sensing job's), optionally completed within `maxAge` and with this run's `logical_date`. |\n")
+	b.WriteString("| `dataset` | object | one probe | `{name, namespace, watermark}`. Satisfied once the dataset's watermark reaches `watermark` (equal, or greater for orderable values), or without `watermark` once the dataset is fresh. `watermark` may be a run template. |\n")
+	b.WriteString("| `file` | object | one probe | `{volume, path}`. Satisfied once `path`, relative to the bind source of job volume `volume`, exists on the node evaluating the sensor. `path` may be a run template. |\n")
+	b.WriteString("| `pokeInterval` | duration | optional | Wait between pokes. Defaults to `1m`. |\n")
+	b.WriteString("| `timeout` | duration | optional | How long the sensor may wait, measured from its first poke. `0` (the default) waits indefinitely. |\n")
+	b.WriteString("| `mode` | string | optional | `poke` (the default) holds the worker claim between pokes; `reschedule` releases it and re-queues the step when the next poke is due. |\n")
+	b.WriteString("| `onTimeout` | string | optional | `fail` (the default) fails the step when the timeout passes; `skip` skips it, and descendants follow their trigger rules as for any skipped step. |\n\n")
 	b.WriteString("### Cache\n\n")
 	b.WriteString("| Field | Type | Required | Notes |\n")
 	b.WriteString("|-------|------|----------|-------|\n")
@@ -204,7 +264,8 @@ func Markdown() string {
 	b.WriteString("| `produces[].version` | integer | optional | Bump when an intentional dataset contract break is introduced. |\n")
 	b.WriteString("| `produces[].freshness` | duration | optional | Target staleness SLO as a Go duration (e.g. `6h`) — how stale consumers tolerate this dataset being. |\n")
 	b.WriteString("| `produces[].maxStaleness` | duration | optional | Hard bound; a breach emits `freshness_violated`. |\n")
-	b.WriteString("| `produces[].watermark` | object | optional | `{key: <output-key>}` names the `##caesium::output` key this step emits to advance the dataset's watermark. It is an output key on the existing zero-SDK output contract, not a JSONPath. |\n\n")
+	b.WriteString("| `produces[].watermark` | object | optional | `{key: <output-key>}` names the `##caesium::output` key this step emits to advance the dataset's watermark. It is an output key on the existing zero-SDK output contract, not a JSONPath. |\n")
+	b.WriteString("| `produces[].columnLineage` | map[string]object | optional | Maps each output column to `{inputFields: [{dataset, field, type, subtype, description}]}`. Each `dataset` must be listed in this step's `consumes`; `type` is `DIRECT` (default) or `INDIRECT`. Emitted as the OpenLineage `columnLineage` facet and queried by `GET /v1/lineage/column-impact` and `GET /v1/lineage/upstream?field=`. |\n\n")
 
 	b.WriteString("### Source Datasets (`metadata.datasets.sources`)\n")
 	b.WriteString("External datasets nobody in Caesium produces — the upstreams a consuming step depends on. A late arrival surfaces as stale-upstream rather than a failed run.\n\n")
@@ -62,14 +62,19 @@ func triggerChainNodes(ctx context.Context, conn *gorm.DB, defs []schema.Definit
 	nodes := make([]triggerChainNode, 0, len(defs))
 	incomingAliases := make(map[string]struct{}, len(defs))
 	incomingAliasIndexes := make(map[string]int, len(defs))
+	incomingKeys := make(map[string]struct{}, len(defs))
 	for idx := range defs {
 		alias := strings.TrimSpace(defs[idx].Metadata.Alias)
 		if alias == "" {
 			return nil, fmt.Errorf("definition %d: metadata.alias is required", idx)
 		}
-		if _, ok := incomingAliases[alias]; ok {
-			return nil, fmt.Errorf("%w: %s", ErrDuplicateJob, alias)
+		// Aliases are unique per namespace. Event filters match job_alias in
+		// every namespace, so the chain graph itself stays keyed by alias.
+		key := schema.QualifiedAlias(defs[idx].Metadata.Namespace, alias)
+		if _, ok := incomingKeys[key]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateJob, key)
 		}
+		incomingKeys[key] = struct{}{}
 		incomingAliases[alias] = struct{}{}
 		incomingAliasIndexes[alias] = len(nodes)
 		nodes = append(nodes, triggerChainNode{
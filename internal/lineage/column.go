@@ -0,0 +1,172 @@
+package lineage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ColumnEdge is one declared column lineage edge: OutputField of
+// OutputDataset is derived from InputField of InputDataset by ProducingStep.
+// Column lineage is declared by name, so datasets here carry no namespace.
+type ColumnEdge struct {
+	InputDataset  string `json:"input_dataset"`
+	InputField    string `json:"input_field"`
+	OutputDataset string `json:"output_dataset"`
+	OutputField   string `json:"output_field"`
+
+	// Type is DIRECT or INDIRECT; Subtype and Description are passed through
+	// from the declaration.
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	ProducingStep string    `json:"producing_step"`
+	JobID         uuid.UUID `json:"job_id"`
+	JobAlias      string    `json:"job_alias"`
+
+	// Depth is the hop count from the queried column (0 = adjacent edge).
+	Depth int `json:"depth"`
+}
+
+// ColumnImpactResult is the response shape returned by QueryColumnImpact.
+type ColumnImpactResult struct {
+	RootName  string `json:"root_name"`
+	RootField string `json:"root_field"`
+
+	// Downstream lists every edge transitively downstream of the root column,
+	// ordered breadth-first.
+	Downstream []ColumnEdge `json:"downstream"`
+}
+
+// QueryColumnImpact returns every column transitively derived from field of
+// dataset name, following produces[].columnLineage declarations across jobs.
+// maxDepth follows QueryImpact: 0 means 10, capped at 20.
+func QueryColumnImpact(ctx context.Context, db *gorm.DB, name, field string, maxDepth int) (*ColumnImpactResult, error) {
+	edges, err := loadColumnEdges(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	byInput := make(map[string][]ColumnEdge)
+	for _, e := range edges {
+		key := columnKey(e.InputDataset, e.InputField)
+		byInput[key] = append(byInput[key], e)
+	}
+	return &ColumnImpactResult{
+		RootName:   name,
+		RootField:  field,
+		Downstream: walkColumns(byInput, name, field, maxDepth, func(e ColumnEdge) (string, string) { return e.OutputDataset, e.OutputField }),
+	}, nil
+}
+
+// QueryColumnUpstream returns every declared edge transitively upstream of
+// field of dataset name: the columns it is derived from, and theirs.
+func QueryColumnUpstream(ctx context.Context, db *gorm.DB, name, field string, maxDepth int) ([]ColumnEdge, error) {
+	edges, err := loadColumnEdges(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	byOutput := make(map[string][]ColumnEdge)
+	for _, e := range edges {
+		key := columnKey(e.OutputDataset, e.OutputField)
+		byOutput[key] = append(byOutput[key], e)
+	}
+	return walkColumns(byOutput, name, field, maxDepth, func(e ColumnEdge) (string, string) { return e.InputDataset, e.InputField }), nil
+}
+
+// walkColumns runs a breadth-first walk over index from (name, field). next
+// returns the column at the far end of an edge, which becomes the following
+// hop.
+func walkColumns(index map[string][]ColumnEdge, name, field string, maxDepth int, next func(ColumnEdge) (string, string)) []ColumnEdge {
+	const defaultMaxDepth = 10
+	const absoluteMaxDepth = 20
+
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if maxDepth > absoluteMaxDepth {
+		maxDepth = absoluteMaxDepth
+	}
+
+	out := []ColumnEdge{}
+	visited := map[string]bool{columnKey(name, field): true}
+	frontier := []string{columnKey(name, field)}
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var nextFrontier []string
+		for _, key := range frontier {
+			for _, e := range index[key] {
+				e.Depth = depth
+				out = append(out, e)
+				dataset, column := next(e)
+				if k := columnKey(dataset, column); !visited[k] {
+					visited[k] = true
+					nextFrontier = append(nextFrontier, k)
+				}
+			}
+		}
+		frontier = nextFrontier
+	}
+	return out
+}
+
+func columnKey(dataset, field string) string {
+	return dataset + "\x00" + field
+}
+
+// loadColumnEdges flattens every produced declaration's column lineage into
+// edges, sorted for a deterministic walk. The declared graph is one row per
+// produced dataset, so it is read whole rather than hop by hop.
+func loadColumnEdges(ctx context.Context, db *gorm.DB) ([]ColumnEdge, error) {
+	var decls []models.DatasetDeclaration
+	if err := db.WithContext(ctx).
+		Select("job_id", "job_alias", "step_name", "name", "column_lineage").
+		Where("direction = ? AND column_lineage IS NOT NULL", models.DatasetDirectionProduces).
+		Find(&decls).Error; err != nil {
+		return nil, err
+	}
+	var edges []ColumnEdge
+	for _, decl := range decls {
+		var declared map[string]jobdef.ColumnLineageField
+		if len(decl.ColumnLineage) == 0 || json.Unmarshal(decl.ColumnLineage, &declared) != nil {
+			continue
+		}
+		for column, field := range declared {
+			for _, in := range field.InputFields {
+				edges = append(edges, ColumnEdge{
+					InputDataset:  in.Dataset,
+					InputField:    in.Field,
+					OutputDataset: decl.Name,
+					OutputField:   column,
+					Type:          in.Type,
+					Subtype:       in.Subtype,
+					Description:   in.Description,
+					ProducingStep: decl.StepName,
+					JobID:         decl.JobID,
+					JobAlias:      decl.JobAlias,
+				})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.OutputDataset != b.OutputDataset {
+			return a.OutputDataset < b.OutputDataset
+		}
+		if a.OutputField != b.OutputField {
+			return a.OutputField < b.OutputField
+		}
+		if a.InputDataset != b.InputDataset {
+			return a.InputDataset < b.InputDataset
+		}
+		if a.InputField != b.InputField {
+			return a.InputField < b.InputField
+		}
+		return a.JobAlias < b.JobAlias
+	})
+	return edges, nil
+}
@@ -27,10 +27,11 @@ type ImpactNode struct {
 	// FacetSummary.caesium_dataset.step_name when available.
 	ProducingStep string `json:"producing_step,omitempty"`
 
-	// JobID and JobAlias identify the job that contains the producing step.
-	// Populated via a join from task_runs → job_runs → jobs.
-	JobID    uuid.UUID `json:"job_id"`
-	JobAlias string    `json:"job_alias"`
+	// JobID, JobNamespace, and JobAlias identify the job that contains the
+	// producing step. Populated via a join from task_runs → job_runs → jobs.
+	JobID        uuid.UUID `json:"job_id"`
+	JobNamespace string    `json:"job_namespace,omitempty"`
+	JobAlias     string    `json:"job_alias"`
 
 	// ProvenanceCommit and ProvenanceRepo carry git provenance from the job
 	// at the time the dataset row was last written.
@@ -193,6 +194,7 @@ func findConsumers(ctx context.Context, db *gorm.DB, frontier []datasetRef) ([]I
 		Name             string
 		FacetSummary     []byte
 		JobID            string
+		JobNamespace     string
 		JobAlias         string
 		ProvenanceCommit string
 		ProvenanceRepo   string
@@ -204,7 +206,7 @@ func findConsumers(ctx context.Context, db *gorm.DB, frontier []datasetRef) ([]I
 		Table("lineage_datasets ld").
 		Select(
 			"ld.namespace, ld.name, ld.facet_summary, ld.created_at,"+
-				" j.id as job_id, j.alias as job_alias,"+
+				" j.id as job_id, j.namespace as job_namespace, j.alias as job_alias,"+
 				" j.provenance_commit, j.provenance_repo",
 		).
 		Joins("JOIN task_runs tr ON tr.id = ld.task_run_id").
@@ -232,6 +234,7 @@ func findConsumers(ctx context.Context, db *gorm.DB, frontier []datasetRef) ([]I
 			Direction:        "output",
 			ProducingStep:    stepNameFromFacet(row.FacetSummary),
 			JobID:            jobID,
+			JobNamespace:     row.JobNamespace,
 			JobAlias:         row.JobAlias,
 			ProvenanceCommit: row.ProvenanceCommit,
 			ProvenanceRepo:   row.ProvenanceRepo,
@@ -242,13 +245,16 @@ func findConsumers(ctx context.Context, db *gorm.DB, frontier []datasetRef) ([]I
 }
 
 // stepNameFromFacet extracts the step_name field from the FacetSummary JSON
-// blob stored on the lineage_dataset row.  Returns "" on any parse error so
+// blob stored on the lineage_dataset row, either nested under caesium_dataset
+// or at the top level as the mapper writes it.  Returns "" on any parse error so
 // the caller degrades gracefully.
 func stepNameFromFacet(raw []byte) string {
 	if len(raw) == 0 {
 		return ""
 	}
 	var summary struct {
+		// StepName is the flat shape persistTaskDatasets writes.
+		StepName       string `json:"step_name"`
 		CaesiumDataset struct {
 			StepName string `json:"step_name"`
 		} `json:"caesium_dataset"`
@@ -256,5 +262,8 @@ func stepNameFromFacet(raw []byte) string {
 	if err := json.Unmarshal(raw, &summary); err != nil {
 		return ""
 	}
-	return summary.CaesiumDataset.StepName
+	if summary.CaesiumDataset.StepName != "" {
+		return summary.CaesiumDataset.StepName
+	}
+	return summary.StepName
 }
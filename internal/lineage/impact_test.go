@@ -250,6 +250,7 @@ func (s *ImpactSuite) TestStepNameFromFacet() {
 		},
 	})
 	s.Equal("my-step", stepNameFromFacet(raw))
+	s.Equal("flat-step", stepNameFromFacet([]byte(`{"step_name":"flat-step"}`)))
 	s.Equal("", stepNameFromFacet(nil))
 	s.Equal("", stepNameFromFacet([]byte(`{}`)))
 	s.Equal("", stepNameFromFacet([]byte(`not-json`)))
@@ -0,0 +1,240 @@
+package lineage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// taskRunStatusRunning is the TaskRun.Status value of a step that is
+// executing. Duplicated here (rather than importing internal/run) so the
+// lineage package stays free of a run-store dependency.
+const taskRunStatusRunning = "running"
+
+// IngestSource marks lineage_datasets rows merged from an external
+// OpenLineage event, in the facet summary's "source" key.
+const IngestSource = "openlineage"
+
+// ErrInvalidRunEvent is returned by Ingest for an event that is not a usable
+// OpenLineage RunEvent.
+var ErrInvalidRunEvent = errors.New("invalid OpenLineage run event")
+
+// IngestResult reports how an external RunEvent was correlated with a Caesium
+// task run and which of its datasets were merged into lineage_datasets.
+type IngestResult struct {
+	// Correlated is false when the event's parent run facet does not resolve
+	// to a single Caesium task run; Reason then says why and nothing is
+	// stored.
+	Correlated bool   `json:"correlated"`
+	Reason     string `json:"reason,omitempty"`
+
+	JobID     *uuid.UUID `json:"job_id,omitempty"`
+	JobAlias  string     `json:"job_alias,omitempty"`
+	RunID     *uuid.UUID `json:"run_id,omitempty"`
+	TaskRunID *uuid.UUID `json:"task_run_id,omitempty"`
+	StepName  string     `json:"step_name,omitempty"`
+
+	// Inputs and Outputs are the datasets merged, without their facets.
+	Inputs  []Dataset `json:"inputs"`
+	Outputs []Dataset `json:"outputs"`
+}
+
+// ingestTarget is the task run an external event was correlated to.
+type ingestTarget struct {
+	TaskRunID uuid.UUID
+	JobRunID  uuid.UUID
+	JobID     uuid.UUID
+	JobAlias  string
+	TaskID    uuid.UUID
+	StepName  string
+	Status    string
+}
+
+// Ingest merges an OpenLineage RunEvent emitted by a tool running inside a
+// step (dbt, Spark, Airbyte, …) into the dataset graph. The event's parent run
+// facet must name the Caesium run that launched the tool:
+//
+//   - a task run ID correlates directly; or
+//   - a job run ID (CAESIUM_RUN_ID) correlates to the step named by the
+//     parent job name — the step name, "<alias>.<step>", or the
+//     "<alias>.task.<task_id>" name Caesium itself emits — or, when the name
+//     does not identify a step, to the run's only running step.
+//
+// The correlated task run's inputs and outputs are upserted into
+// lineage_datasets under the namespaces the tool reported, so they take part
+// in QueryImpact, QueryUpstream and the contract graph's lineage evidence.
+// An event that cannot be correlated is not an error: it is returned with
+// Correlated false.
+func Ingest(ctx context.Context, db *gorm.DB, evt RunEvent) (*IngestResult, error) {
+	if err := validateRunEvent(evt); err != nil {
+		return nil, err
+	}
+	parent, ok, err := parentRunFacet(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IngestResult{Inputs: []Dataset{}, Outputs: []Dataset{}}
+	if !ok {
+		result.Reason = "event has no parent run facet"
+		return result, nil
+	}
+
+	target, reason, err := resolveIngestTarget(ctx, db, parent)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		result.Reason = reason
+		return result, nil
+	}
+
+	result.Inputs = stripFacets(evt.Inputs)
+	result.Outputs = stripFacets(evt.Outputs)
+	summary, _ := json.Marshal(map[string]string{
+		"step_name": target.StepName,
+		"source":    IngestSource,
+		"producer":  evt.Producer,
+	})
+	if err := upsertTaskDatasets(db.WithContext(ctx), target.TaskRunID, summary, result.Inputs, result.Outputs); err != nil {
+		return nil, err
+	}
+
+	result.Correlated = true
+	result.JobID = &target.JobID
+	result.JobAlias = target.JobAlias
+	result.RunID = &target.JobRunID
+	result.TaskRunID = &target.TaskRunID
+	result.StepName = target.StepName
+	return result, nil
+}
+
+func validateRunEvent(evt RunEvent) error {
+	if evt.Run.RunID == uuid.Nil {
+		return fmt.Errorf("%w: run.runId is required", ErrInvalidRunEvent)
+	}
+	if strings.TrimSpace(evt.Job.Namespace) == "" || strings.TrimSpace(evt.Job.Name) == "" {
+		return fmt.Errorf("%w: job.namespace and job.name are required", ErrInvalidRunEvent)
+	}
+	for _, group := range []struct {
+		field    string
+		datasets []Dataset
+	}{{"inputs", evt.Inputs}, {"outputs", evt.Outputs}} {
+		for i, ds := range group.datasets {
+			if strings.TrimSpace(ds.Namespace) == "" || strings.TrimSpace(ds.Name) == "" {
+				return fmt.Errorf("%w: %s[%d] requires namespace and name", ErrInvalidRunEvent, group.field, i)
+			}
+		}
+	}
+	return nil
+}
+
+// parentRunFacet decodes run.facets.parent. ok is false when the facet is
+// absent; a facet that is present but malformed is an ErrInvalidRunEvent.
+func parentRunFacet(evt RunEvent) (ParentRunFacet, bool, error) {
+	raw, present := evt.Run.Facets["parent"]
+	if !present || raw == nil {
+		return ParentRunFacet{}, false, nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return ParentRunFacet{}, false, fmt.Errorf("%w: parent facet: %v", ErrInvalidRunEvent, err)
+	}
+	var parent ParentRunFacet
+	if err := json.Unmarshal(encoded, &parent); err != nil {
+		return ParentRunFacet{}, false, fmt.Errorf("%w: parent facet: %v", ErrInvalidRunEvent, err)
+	}
+	if parent.Run.RunID == uuid.Nil {
+		return ParentRunFacet{}, false, fmt.Errorf("%w: parent facet requires run.runId", ErrInvalidRunEvent)
+	}
+	return parent, true, nil
+}
+
+// resolveIngestTarget finds the task run the parent facet points at. A nil
+// target with a reason means the parent is not a single Caesium task run.
+func resolveIngestTarget(ctx context.Context, db *gorm.DB, parent ParentRunFacet) (*ingestTarget, string, error) {
+	base := func() *gorm.DB {
+		return db.WithContext(ctx).Table("task_runs").
+			Select("task_runs.id AS task_run_id, task_runs.job_run_id, job_runs.job_id," +
+				" jobs.alias AS job_alias, task_runs.task_id, tasks.name AS step_name, task_runs.status").
+			Joins("JOIN job_runs ON job_runs.id = task_runs.job_run_id").
+			Joins("JOIN jobs ON jobs.id = job_runs.job_id").
+			Joins("JOIN tasks ON tasks.id = task_runs.task_id")
+	}
+
+	var direct []ingestTarget
+	if err := base().Where("task_runs.id = ?", parent.Run.RunID).Limit(1).Scan(&direct).Error; err != nil {
+		return nil, "", err
+	}
+	if len(direct) == 1 {
+		return &direct[0], "", nil
+	}
+
+	var candidates []ingestTarget
+	if err := base().Where("task_runs.job_run_id = ?", parent.Run.RunID).
+		Order("task_runs.created_at DESC").
+		Scan(&candidates).Error; err != nil {
+		return nil, "", err
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Sprintf("parent run %s is not a Caesium job run or task run", parent.Run.RunID), nil
+	}
+
+	// Candidates are newest first, so a retried step resolves to its latest
+	// attempt.
+	alias := candidates[0].JobAlias
+	if step, taskID, named := parentStep(parent.Job.Name, alias); named {
+		for i := range candidates {
+			c := &candidates[i]
+			if (taskID != uuid.Nil && c.TaskID == taskID) || (taskID == uuid.Nil && c.StepName == step) {
+				return c, "", nil
+			}
+		}
+		return nil, fmt.Sprintf("parent job %q names no step of run %s", parent.Job.Name, parent.Run.RunID), nil
+	}
+
+	var running []*ingestTarget
+	for i := range candidates {
+		if candidates[i].Status == taskRunStatusRunning {
+			running = append(running, &candidates[i])
+		}
+	}
+	if len(running) == 1 {
+		return running[0], "", nil
+	}
+	return nil, fmt.Sprintf("run %s has %d running steps; set the parent job name to the step name", parent.Run.RunID, len(running)), nil
+}
+
+// parentStep reads the step a parent job name identifies. named is false for
+// an empty name or the bare job alias, which leave the step to be inferred.
+func parentStep(name, alias string) (step string, taskID uuid.UUID, named bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == alias {
+		return "", uuid.Nil, false
+	}
+	if rest, ok := strings.CutPrefix(name, alias+".task."); ok {
+		if id, err := uuid.Parse(rest); err == nil {
+			return "", id, true
+		}
+	}
+	if rest, ok := strings.CutPrefix(name, alias+"."); ok && rest != "" {
+		return rest, uuid.Nil, true
+	}
+	return name, uuid.Nil, true
+}
+
+func stripFacets(datasets []Dataset) []Dataset {
+	out := make([]Dataset, 0, len(datasets))
+	for _, ds := range datasets {
+		out = append(out, Dataset{
+			Namespace: strings.TrimSpace(ds.Namespace),
+			Name:      strings.TrimSpace(ds.Name),
+		})
+	}
+	return out
+}
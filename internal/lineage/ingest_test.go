@@ -0,0 +1,198 @@
+package lineage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/contract"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+)
+
+// externalEvent decodes a RunEvent the way POST /v1/lineage does, as a tool
+// such as dbt-ol would send it with parentJob naming the Caesium run.
+func (s *ImpactSuite) externalEvent(parentRunID uuid.UUID, parentJob string, inputs, outputs string) RunEvent {
+	body := fmt.Sprintf(`{
+		"eventTime": %q,
+		"eventType": "COMPLETE",
+		"producer": "https://github.com/OpenLineage/OpenLineage/tree/1.30.0/integration/dbt",
+		"schemaURL": "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunEvent",
+		"run": {
+			"runId": %q,
+			"facets": {"parent": {"run": {"runId": %q}, "job": {"namespace": "caesium", "name": %q}}}
+		},
+		"job": {"namespace": "dbt", "name": "analytics.orders"},
+		"inputs": %s,
+		"outputs": %s
+	}`, time.Now().UTC().Format(time.RFC3339), uuid.NewString(), parentRunID, parentJob, inputs, outputs)
+	var evt RunEvent
+	s.Require().NoError(json.Unmarshal([]byte(body), &evt))
+	return evt
+}
+
+// TestIngestCorrelatesTaskRunAndFeedsImpact: an event whose parent is a task
+// run lands on that task run and links into QueryImpact like declared rows.
+func (s *ImpactSuite) TestIngestCorrelatesTaskRunAndFeedsImpact() {
+	const warehouse = "postgres://warehouse:5432"
+	etl, etlRun := s.createJobAndRun("etl", "")
+	report, reportRun := s.createJobAndRun("report", "")
+
+	res, err := Ingest(s.ctx, s.db, s.externalEvent(etlRun.ID, "",
+		`[{"namespace": "postgres://warehouse:5432", "name": "raw.orders"}]`,
+		`[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders", "facets": {"schema": {"fields": []}}}]`))
+	s.Require().NoError(err)
+	s.Require().True(res.Correlated, res.Reason)
+	s.Equal(etlRun.ID, *res.TaskRunID)
+	s.Equal(etlRun.JobRunID, *res.RunID)
+	s.Equal(etl.ID, *res.JobID)
+	s.Equal("etl-task", res.StepName)
+	s.Equal([]Dataset{{Namespace: warehouse, Name: "analytics.orders"}}, res.Outputs)
+
+	var rows []models.LineageDataset
+	s.Require().NoError(s.db.Where("task_run_id = ?", etlRun.ID).Order("direction").Find(&rows).Error)
+	s.Require().Len(rows, 2)
+	s.Equal("input", rows[0].Direction)
+	s.Equal("output", rows[1].Direction)
+	s.JSONEq(`{"step_name":"etl-task","source":"openlineage","producer":"https://github.com/OpenLineage/OpenLineage/tree/1.30.0/integration/dbt"}`,
+		string(rows[1].FacetSummary))
+
+	_, err = Ingest(s.ctx, s.db, s.externalEvent(reportRun.ID, "",
+		`[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders"}]`,
+		`[{"namespace": "s3://reports", "name": "daily/orders.csv"}]`))
+	s.Require().NoError(err)
+
+	impact, err := QueryImpact(s.ctx, s.db, warehouse, "analytics.orders", 0)
+	s.Require().NoError(err)
+	s.Require().Len(impact.Downstream, 1)
+	s.Equal("daily/orders.csv", impact.Downstream[0].DatasetName)
+	s.Equal(report.ID, impact.Downstream[0].JobID)
+	s.Equal("report-task", impact.Downstream[0].ProducingStep)
+
+	// Re-sending the same event is idempotent.
+	_, err = Ingest(s.ctx, s.db, s.externalEvent(etlRun.ID, "", `[]`,
+		`[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders"}]`))
+	s.Require().NoError(err)
+	var count int64
+	s.Require().NoError(s.db.Model(&models.LineageDataset{}).Where("task_run_id = ?", etlRun.ID).Count(&count).Error)
+	s.Equal(int64(2), count)
+}
+
+// TestIngestFeedsContractEvidence: datasets merged from external events link
+// the producing and consuming jobs in the contract graph as lineage evidence.
+func (s *ImpactSuite) TestIngestFeedsContractEvidence() {
+	const warehouse = "postgres://warehouse:5432"
+	_, etlRun := s.createJobAndRun("etl", "")
+	_, reportRun := s.createJobAndRun("report", "")
+
+	before := time.Now().UTC()
+	for _, evt := range []RunEvent{
+		s.externalEvent(etlRun.ID, "", `[]`,
+			`[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders"}]`),
+		s.externalEvent(reportRun.ID, "",
+			`[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders"}]`, `[]`),
+	} {
+		res, err := Ingest(s.ctx, s.db, evt)
+		s.Require().NoError(err)
+		s.Require().True(res.Correlated, res.Reason)
+	}
+
+	graph, err := contract.NewGORMDeriver(s.db).DeriveGraph(s.ctx, nil)
+	s.Require().NoError(err)
+
+	var evidence []contract.Edge
+	for _, edge := range graph.Edges {
+		if edge.Class == contract.EdgeClassEvidence {
+			evidence = append(evidence, edge)
+		}
+	}
+	s.Require().Len(evidence, 1)
+	s.Equal("job:etl", evidence[0].From)
+	s.Equal("job:report", evidence[0].To)
+	s.Require().NotNil(evidence[0].Dataset)
+	s.Equal(contract.DatasetRef{Namespace: warehouse, Name: "analytics.orders"}, *evidence[0].Dataset)
+	s.Require().NotNil(evidence[0].LastSeen)
+	s.WithinDuration(before, *evidence[0].LastSeen, time.Minute)
+
+	var datasetNodes []contract.DatasetRef
+	for _, node := range graph.Nodes {
+		if node.Kind == contract.NodeKindDataset && node.Dataset != nil {
+			datasetNodes = append(datasetNodes, *node.Dataset)
+		}
+	}
+	s.Contains(datasetNodes, contract.DatasetRef{Namespace: warehouse, Name: "analytics.orders"})
+}
+
+// TestIngestResolvesJobRunParent: a job run parent (CAESIUM_RUN_ID) resolves
+// to the step named by the parent job, or to the run's only running step.
+func (s *ImpactSuite) TestIngestResolvesJobRunParent() {
+	job, first := s.createJobAndRun("warehouse", "")
+	dbt := s.createTaskWithRun(job.ID, first.JobRunID, "dbt", nil, nil)
+	outputs := `[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders"}]`
+
+	for _, name := range []string{"dbt", "warehouse.dbt", "warehouse.task." + dbt.TaskID.String()} {
+		res, err := Ingest(s.ctx, s.db, s.externalEvent(first.JobRunID, name, `[]`, outputs))
+		s.Require().NoError(err)
+		s.Require().True(res.Correlated, "%s: %s", name, res.Reason)
+		s.Equal(dbt.ID, *res.TaskRunID, name)
+		s.Equal("dbt", res.StepName)
+	}
+
+	res, err := Ingest(s.ctx, s.db, s.externalEvent(first.JobRunID, "warehouse.missing", `[]`, outputs))
+	s.Require().NoError(err)
+	s.False(res.Correlated)
+	s.Contains(res.Reason, "names no step")
+
+	// Unnamed: no running step, then exactly one, then two.
+	res, err = Ingest(s.ctx, s.db, s.externalEvent(first.JobRunID, "warehouse", `[]`, outputs))
+	s.Require().NoError(err)
+	s.False(res.Correlated)
+	s.Contains(res.Reason, "0 running steps")
+
+	s.Require().NoError(s.db.Model(&models.TaskRun{}).Where("id = ?", dbt.ID).Update("status", taskRunStatusRunning).Error)
+	res, err = Ingest(s.ctx, s.db, s.externalEvent(first.JobRunID, "", `[]`, outputs))
+	s.Require().NoError(err)
+	s.Require().True(res.Correlated, res.Reason)
+	s.Equal(dbt.ID, *res.TaskRunID)
+
+	s.Require().NoError(s.db.Model(&models.TaskRun{}).Where("id = ?", first.ID).Update("status", taskRunStatusRunning).Error)
+	res, err = Ingest(s.ctx, s.db, s.externalEvent(first.JobRunID, "", `[]`, outputs))
+	s.Require().NoError(err)
+	s.False(res.Correlated)
+	s.Contains(res.Reason, "2 running steps")
+}
+
+// TestIngestUncorrelatedAndInvalid: events that name no Caesium run are
+// accepted but not stored; malformed events are rejected.
+func (s *ImpactSuite) TestIngestUncorrelatedAndInvalid() {
+	outputs := `[{"namespace": "postgres://warehouse:5432", "name": "analytics.orders"}]`
+
+	res, err := Ingest(s.ctx, s.db, s.externalEvent(uuid.New(), "", `[]`, outputs))
+	s.Require().NoError(err)
+	s.False(res.Correlated)
+	s.Contains(res.Reason, "is not a Caesium job run or task run")
+
+	orphan := s.externalEvent(uuid.New(), "", `[]`, outputs)
+	orphan.Run.Facets = nil
+	res, err = Ingest(s.ctx, s.db, orphan)
+	s.Require().NoError(err)
+	s.False(res.Correlated)
+	s.Equal("event has no parent run facet", res.Reason)
+
+	var count int64
+	s.Require().NoError(s.db.Model(&models.LineageDataset{}).Count(&count).Error)
+	s.Zero(count)
+
+	for name, mutate := range map[string]func(*RunEvent){
+		"no run id":       func(e *RunEvent) { e.Run.RunID = uuid.Nil },
+		"no job name":     func(e *RunEvent) { e.Job.Name = "" },
+		"unnamed output":  func(e *RunEvent) { e.Outputs[0].Name = "" },
+		"bad parent":      func(e *RunEvent) { e.Run.Facets["parent"] = map[string]any{"run": map[string]any{"runId": "nope"}} },
+		"parent no runId": func(e *RunEvent) { e.Run.Facets["parent"] = map[string]any{"job": map[string]any{"name": "x"}} },
+	} {
+		evt := s.externalEvent(uuid.New(), "", `[]`, outputs)
+		mutate(&evt)
+		_, err := Ingest(s.ctx, s.db, evt)
+		s.Require().ErrorIs(err, ErrInvalidRunEvent, name)
+	}
+}
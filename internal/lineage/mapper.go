@@ -9,6 +9,7 @@ import (
 
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -16,10 +17,11 @@ import (
 )
 
 const (
-	parentFacetSchema     = "https://openlineage.io/spec/facets/1-0-1/ParentRunFacet.json"
-	errorFacetSchema      = "https://openlineage.io/spec/facets/1-0-1/ErrorMessageRunFacet.json"
-	jobTypeFacetSchema    = "https://openlineage.io/spec/facets/2-0-3/JobTypeJobFacet.json"
-	sourceCodeFacetSchema = "https://openlineage.io/spec/facets/1-0-1/SourceCodeLocationJobFacet.json"
+	parentFacetSchema        = "https://openlineage.io/spec/facets/1-0-1/ParentRunFacet.json"
+	errorFacetSchema         = "https://openlineage.io/spec/facets/1-0-1/ErrorMessageRunFacet.json"
+	jobTypeFacetSchema       = "https://openlineage.io/spec/facets/2-0-3/JobTypeJobFacet.json"
+	sourceCodeFacetSchema    = "https://openlineage.io/spec/facets/1-0-1/SourceCodeLocationJobFacet.json"
+	columnLineageFacetSchema = "https://openlineage.io/spec/facets/1-2-0/ColumnLineageDatasetFacet.json"
 
 	defaultCacheTTL = 5 * time.Minute
 )
@@ -68,6 +70,17 @@ type taskRunPayload struct {
 	// describing which keys this step consumes.  Non-nil when the step declares
 	// inputSchema in the job manifest.
 	InputSchema map[string]map[string]interface{} `json:"input_schema,omitempty"`
+
+	// Declared holds the step's datasets.produces / datasets.consumes
+	// declarations, loaded from dataset_declarations. Never part of the event.
+	Declared []declaredDataset `json:"-"`
+}
+
+// declaredDataset is one dataset_declarations row for the task's step.
+type declaredDataset struct {
+	Name          string `gorm:"column:name"`
+	Direction     string `gorm:"column:direction"`
+	ColumnLineage []byte `gorm:"column:column_lineage"`
 }
 
 type jobRecord struct {
@@ -519,6 +532,7 @@ func (m *mapper) enrichTaskPayload(payload *taskRunPayload) {
 	if m.db == nil || payload.TaskID == uuid.Nil {
 		return
 	}
+	m.loadDeclaredDatasets(payload)
 	if payload.TaskName != "" && payload.OutputSchema != nil && payload.InputSchema != nil {
 		return
 	}
@@ -544,6 +558,24 @@ func (m *mapper) enrichTaskPayload(payload *taskRunPayload) {
 	}
 }
 
+// loadDeclaredDatasets loads the datasets the task's step declares under
+// datasets.produces and datasets.consumes, so declared datasets (and their
+// column lineage) join the observed graph even when the step emits no
+// path-like outputs. A lookup failure leaves Declared empty.
+func (m *mapper) loadDeclaredDatasets(payload *taskRunPayload) {
+	var rows []declaredDataset
+	if err := m.db.Table("dataset_declarations dd").
+		Select("dd.name, dd.direction, dd.column_lineage").
+		Joins("JOIN tasks t ON t.job_id = dd.job_id AND t.name = dd.step_name").
+		Where("t.id = ? AND dd.direction IN ?", payload.TaskID,
+			[]string{models.DatasetDirectionProduces, models.DatasetDirectionConsumes}).
+		Order("dd.direction, dd.name").
+		Scan(&rows).Error; err != nil {
+		return
+	}
+	payload.Declared = rows
+}
+
 // persistTaskDatasets writes the task's input/output datasets to the bounded
 // lineage_datasets graph that the cross-job impact query (QueryImpact) reads.
 // It is best-effort: any failure leaves the graph as-is rather than disrupting
@@ -564,6 +596,13 @@ func (m *mapper) persistTaskDatasets(payload taskRunPayload, inputs, outputs []D
 	}
 
 	summary, _ := json.Marshal(map[string]string{"step_name": payload.TaskName})
+	_ = upsertTaskDatasets(m.db, taskRunID, summary, inputs, outputs)
+}
+
+// upsertTaskDatasets writes inputs and outputs as lineage_datasets rows of
+// taskRunID, each carrying summary as its facet summary. Existing rows for the
+// same (task_run, namespace, name, direction) are left as they are.
+func upsertTaskDatasets(db *gorm.DB, taskRunID uuid.UUID, summary []byte, inputs, outputs []Dataset) error {
 	rows := make([]models.LineageDataset, 0, len(inputs)+len(outputs))
 	// Deduplicate within the batch: distinct output keys can resolve to the same
 	// dataset value (e.g. the same file path), and a duplicate
@@ -596,9 +635,9 @@ func (m *mapper) persistTaskDatasets(payload taskRunPayload, inputs, outputs []D
 		appendRow(ds, "output")
 	}
 	if len(rows) == 0 {
-		return
+		return nil
 	}
-	_ = m.db.Clauses(clause.OnConflict{
+	return db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{
 			{Name: "task_run_id"}, {Name: "namespace"}, {Name: "name"}, {Name: "direction"},
 		},
@@ -687,6 +726,45 @@ func (m *mapper) buildTaskDatasets(jobAlias string, payload taskRunPayload) (inp
 		})
 	}
 
+	// --- Declared datasets ---
+	// Datasets declared under the step's datasets block are emitted under their
+	// declared names, so a consumer's declared input links to the producing
+	// job's declared output. Produced datasets carry the columnLineage facet
+	// when the declaration maps columns.
+	emitted := make(map[string]struct{}, len(inputs)+len(outputs))
+	for _, ds := range inputs {
+		emitted["input\x00"+ds.Name] = struct{}{}
+	}
+	for _, ds := range outputs {
+		emitted["output\x00"+ds.Name] = struct{}{}
+	}
+	for _, decl := range payload.Declared {
+		direction := "input"
+		if decl.Direction == models.DatasetDirectionProduces {
+			direction = "output"
+		}
+		if _, dup := emitted[direction+"\x00"+decl.Name]; dup {
+			continue
+		}
+		emitted[direction+"\x00"+decl.Name] = struct{}{}
+		facets := map[string]interface{}{
+			"caesium_dataset": CaesiumDatasetFacet{
+				BaseFacet: newCaesiumBaseFacet("CaesiumDatasetFacet"),
+				StepName:  stepName,
+				Direction: direction,
+			},
+		}
+		ds := Dataset{Namespace: m.namespace, Name: decl.Name, Facets: facets}
+		if direction == "input" {
+			inputs = append(inputs, ds)
+			continue
+		}
+		if facet, ok := m.columnLineageFacet(decl.ColumnLineage); ok {
+			facets["columnLineage"] = facet
+		}
+		outputs = append(outputs, ds)
+	}
+
 	// Ensure non-nil slices so JSON serializes as [] rather than null, which
 	// is required by the OpenLineage RunEvent spec.
 	if inputs == nil {
@@ -698,6 +776,40 @@ func (m *mapper) buildTaskDatasets(jobAlias string, payload taskRunPayload) (inp
 	return inputs, outputs
 }
 
+// columnLineageFacet converts a declaration's stored column lineage into the
+// OpenLineage columnLineage facet. Input datasets are declared by name and
+// share the mapper's namespace.
+func (m *mapper) columnLineageFacet(raw []byte) (ColumnLineageFacet, bool) {
+	if len(raw) == 0 {
+		return ColumnLineageFacet{}, false
+	}
+	var declared map[string]jobdef.ColumnLineageField
+	if err := json.Unmarshal(raw, &declared); err != nil || len(declared) == 0 {
+		return ColumnLineageFacet{}, false
+	}
+	facet := ColumnLineageFacet{
+		BaseFacet: newBaseFacet(columnLineageFacetSchema),
+		Fields:    make(map[string]ColumnLineageFacetField, len(declared)),
+	}
+	for column, field := range declared {
+		inputs := make([]ColumnLineageInputField, 0, len(field.InputFields))
+		for _, in := range field.InputFields {
+			inputs = append(inputs, ColumnLineageInputField{
+				Namespace: m.namespace,
+				Name:      in.Dataset,
+				Field:     in.Field,
+				Transformations: []ColumnLineageTransformation{{
+					Type:        in.Type,
+					Subtype:     in.Subtype,
+					Description: in.Description,
+				}},
+			})
+		}
+		facet.Fields[column] = ColumnLineageFacetField{InputFields: inputs}
+	}
+	return facet, true
+}
+
 // pathLikeKeys returns the keys from output whose values look like file paths,
 // URIs, or table references — the types of values that form meaningful dataset
 // identities.  Scalar summaries (numbers, short words) are excluded to keep
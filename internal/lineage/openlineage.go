@@ -106,3 +106,27 @@ type NominalTimeFacet struct {
 	NominalStartTime string `json:"nominalStartTime"`
 	NominalEndTime   string `json:"nominalEndTime,omitempty"`
 }
+
+// ColumnLineageFacet is the OpenLineage columnLineage dataset facet: each
+// output column maps to the input columns it was derived from.
+type ColumnLineageFacet struct {
+	BaseFacet
+	Fields map[string]ColumnLineageFacetField `json:"fields"`
+}
+
+type ColumnLineageFacetField struct {
+	InputFields []ColumnLineageInputField `json:"inputFields"`
+}
+
+type ColumnLineageInputField struct {
+	Namespace       string                        `json:"namespace"`
+	Name            string                        `json:"name"`
+	Field           string                        `json:"field"`
+	Transformations []ColumnLineageTransformation `json:"transformations,omitempty"`
+}
+
+type ColumnLineageTransformation struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,401 @@
+package lineage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Upstream node sources: where the producing run of a dataset version was
+// found.
+const (
+	// UpstreamSourceLineage marks a producer found in the observed
+	// lineage_datasets graph.
+	UpstreamSourceLineage = "lineage"
+	// UpstreamSourceDerivation marks a producer found only in the freshness
+	// evaluator's dataset_derivations audit (a `derived` decision).
+	UpstreamSourceDerivation = "derivation"
+)
+
+// UpstreamNode is one dataset version in the provenance of a root dataset:
+// the dataset, the run that produced that version, and the dataset it fed.
+type UpstreamNode struct {
+	// DatasetNamespace and DatasetName are the OpenLineage identity of the
+	// upstream dataset.
+	DatasetNamespace string `json:"dataset_namespace"`
+	DatasetName      string `json:"dataset_name"`
+
+	// FeedsNamespace and FeedsName identify the downstream dataset whose
+	// producing run read this version. Empty on UpstreamResult.Producer.
+	FeedsNamespace string `json:"feeds_namespace,omitempty"`
+	FeedsName      string `json:"feeds_name,omitempty"`
+
+	// ProducingStep, JobID and JobAlias identify the step that wrote this
+	// version. Empty for an External node.
+	ProducingStep string    `json:"producing_step,omitempty"`
+	JobID         uuid.UUID `json:"job_id"`
+	JobAlias      string    `json:"job_alias,omitempty"`
+
+	// RunID is the job run that produced this version. TaskRunID and
+	// TaskStatus are set when the producer was observed in lineage_datasets.
+	RunID      *uuid.UUID `json:"run_id,omitempty"`
+	TaskRunID  *uuid.UUID `json:"task_run_id,omitempty"`
+	TaskStatus string     `json:"task_status,omitempty"`
+
+	// ProvenanceCommit and ProvenanceRepo carry the producing job's git
+	// provenance.
+	ProvenanceCommit string `json:"provenance_commit,omitempty"`
+	ProvenanceRepo   string `json:"provenance_repo,omitempty"`
+
+	// ProducedAt is when the producing run wrote (or was derived to write)
+	// this version.
+	ProducedAt *time.Time `json:"produced_at,omitempty"`
+
+	// Watermark is the watermark of this dataset that the downstream run
+	// consumed, from the derivation's consumed-watermark snapshot.
+	Watermark string `json:"watermark,omitempty"`
+
+	// Source is UpstreamSourceLineage or UpstreamSourceDerivation. External is
+	// set when no producing run was found: the dataset is a source, or its
+	// producer predates the retained history.
+	Source   string `json:"source,omitempty"`
+	External bool   `json:"external,omitempty"`
+
+	// Depth is the hop count from the root (0 = direct input of the root's
+	// producing run).
+	Depth int `json:"depth"`
+}
+
+// UpstreamResult is the response shape returned by QueryUpstream.
+type UpstreamResult struct {
+	RootNamespace string `json:"root_namespace"`
+	RootName      string `json:"root_name"`
+	// RootRunID is the requested producing run, when the query was pinned to
+	// one.
+	RootRunID *uuid.UUID `json:"root_run_id,omitempty"`
+
+	// Producer is the run that produced the root version; nil when no
+	// producing run is recorded.
+	Producer *UpstreamNode `json:"producer"`
+
+	// Upstream lists the datasets that fed Producer, transitively, ordered
+	// breadth-first.
+	Upstream []UpstreamNode `json:"upstream"`
+
+	// Columns lists the declared column lineage edges transitively upstream of
+	// RootField (see QueryColumnUpstream). Only set when the caller names a
+	// field.
+	RootField string       `json:"root_field,omitempty"`
+	Columns   []ColumnEdge `json:"columns,omitempty"`
+}
+
+// QueryUpstream returns the provenance of a dataset version: the run that
+// produced it, the datasets that run read, the runs that produced those
+// versions, and so on. It is the reverse of QueryImpact.
+//
+// The root version is the latest production of (namespace, name), or the
+// production by runID when it is set. Each input is resolved to the latest
+// production at or before the time the consuming run read it, so the walk
+// follows the run IDs that actually fed the root rather than today's
+// producers.
+//
+// Producers are found in lineage_datasets output rows first; a dataset with
+// no observed producer falls back to the most recent `derived` decision in
+// dataset_derivations. Inputs are the producing task run's lineage_datasets
+// input rows plus the consumed datasets in its derivation's watermark
+// snapshot. maxDepth follows QueryImpact: 0 means 10, capped at 20.
+func QueryUpstream(ctx context.Context, db *gorm.DB, namespace, name string, runID *uuid.UUID, maxDepth int) (*UpstreamResult, error) {
+	const defaultMaxDepth = 10
+	const absoluteMaxDepth = 20
+
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if maxDepth > absoluteMaxDepth {
+		maxDepth = absoluteMaxDepth
+	}
+
+	result := &UpstreamResult{
+		RootNamespace: namespace,
+		RootName:      name,
+		RootRunID:     runID,
+		Upstream:      []UpstreamNode{},
+	}
+
+	root, err := findProducer(ctx, db, namespace, name, runID, nil)
+	if err != nil || root == nil {
+		return result, err
+	}
+	result.Producer = root
+
+	// visited is keyed on the dataset version (dataset + producing run), so a
+	// dataset read at two different versions is walked twice but a cycle in
+	// the run graph still terminates.
+	visited := map[string]bool{versionKey(root): true}
+	frontier := []*UpstreamNode{root}
+
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var next []*UpstreamNode
+		for _, produced := range frontier {
+			inputs, err := findInputs(ctx, db, produced)
+			if err != nil {
+				return nil, err
+			}
+			for _, in := range inputs {
+				node, err := findProducer(ctx, db, in.namespace, in.name, nil, &in.readAt)
+				if err != nil {
+					return nil, err
+				}
+				if node == nil {
+					node = &UpstreamNode{
+						DatasetNamespace: in.namespace,
+						DatasetName:      in.name,
+						External:         true,
+					}
+				}
+				node.FeedsNamespace = produced.DatasetNamespace
+				node.FeedsName = produced.DatasetName
+				node.Watermark = in.watermark
+				node.Depth = depth
+				result.Upstream = append(result.Upstream, *node)
+
+				if node.External {
+					continue
+				}
+				if key := versionKey(node); !visited[key] {
+					visited[key] = true
+					next = append(next, node)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return result, nil
+}
+
+func versionKey(n *UpstreamNode) string {
+	key := datasetKey(n.DatasetNamespace, n.DatasetName)
+	if n.RunID != nil {
+		key += "\x00" + n.RunID.String()
+	}
+	return key
+}
+
+// datasetKey identifies a dataset: the same name in two namespaces is two
+// datasets.
+func datasetKey(namespace, name string) string {
+	return namespace + "\x00" + name
+}
+
+// upstreamInput is one dataset a producing run read, and when it read it.
+type upstreamInput struct {
+	namespace string
+	name      string
+	readAt    time.Time
+	watermark string
+}
+
+// findProducer resolves the run that produced (namespace, name). runID pins
+// the producing job run; asOf excludes productions after the consumer read
+// the dataset. Returns nil when no production is recorded.
+func findProducer(ctx context.Context, db *gorm.DB, namespace, name string, runID *uuid.UUID, asOf *time.Time) (*UpstreamNode, error) {
+	type producerRow struct {
+		TaskRunID        string
+		TaskStatus       string
+		RunID            string
+		FacetSummary     []byte
+		JobID            string
+		JobAlias         string
+		ProvenanceCommit string
+		ProvenanceRepo   string
+		CreatedAt        time.Time
+	}
+	q := db.WithContext(ctx).
+		Table("lineage_datasets ld").
+		Select(
+			"ld.task_run_id, tr.status as task_status, jr.id as run_id,"+
+				" ld.facet_summary, ld.created_at,"+
+				" j.id as job_id, j.alias as job_alias,"+
+				" j.provenance_commit, j.provenance_repo",
+		).
+		Joins("JOIN task_runs tr ON tr.id = ld.task_run_id").
+		Joins("JOIN job_runs jr ON jr.id = tr.job_run_id").
+		Joins("JOIN jobs j ON j.id = jr.job_id").
+		Where("ld.direction = ? AND ld.namespace = ? AND ld.name = ?", "output", namespace, name)
+	if runID != nil {
+		q = q.Where("jr.id = ?", *runID)
+	}
+	if asOf != nil {
+		q = q.Where("ld.created_at <= ?", *asOf)
+	}
+	var rows []producerRow
+	if err := q.Order("ld.created_at DESC, ld.id DESC").Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 {
+		row := rows[0]
+		producedAt := row.CreatedAt
+		return &UpstreamNode{
+			DatasetNamespace: namespace,
+			DatasetName:      name,
+			ProducingStep:    stepNameFromFacet(row.FacetSummary),
+			JobID:            parseUUIDOrNil(row.JobID),
+			JobAlias:         row.JobAlias,
+			RunID:            parseUUIDPtr(row.RunID),
+			TaskRunID:        parseUUIDPtr(row.TaskRunID),
+			TaskStatus:       row.TaskStatus,
+			ProvenanceCommit: row.ProvenanceCommit,
+			ProvenanceRepo:   row.ProvenanceRepo,
+			ProducedAt:       &producedAt,
+			Source:           UpstreamSourceLineage,
+		}, nil
+	}
+	return findDerivedProducer(ctx, db, namespace, name, runID, asOf)
+}
+
+// findDerivedProducer resolves a producer from the freshness evaluator's
+// derivation audit. The run and job joins are outer joins: derivations are
+// never pruned with their runs, and a pruned run is still worth reporting.
+// Derivations key on name (their namespace is unused in v1), so a row with no
+// namespace matches any.
+func findDerivedProducer(ctx context.Context, db *gorm.DB, namespace, name string, runID *uuid.UUID, asOf *time.Time) (*UpstreamNode, error) {
+	type derivationRow struct {
+		RunID            string
+		CreatedAt        time.Time
+		StepName         string
+		JobID            string
+		JobAlias         string
+		ProvenanceCommit string
+		ProvenanceRepo   string
+	}
+	q := db.WithContext(ctx).
+		Table("dataset_derivations dd").
+		Select(
+			"dd.run_id, dd.created_at, decl.step_name,"+
+				" j.id as job_id, j.alias as job_alias,"+
+				" j.provenance_commit, j.provenance_repo",
+		).
+		Joins("LEFT JOIN job_runs jr ON jr.id = dd.run_id").
+		Joins("LEFT JOIN jobs j ON j.id = jr.job_id").
+		Joins("LEFT JOIN dataset_declarations decl ON decl.job_id = jr.job_id AND decl.name = dd.name AND decl.direction = ?", models.DatasetDirectionProduces).
+		Where("dd.decision = ? AND dd.run_id IS NOT NULL AND dd.name = ?", models.DatasetDecisionDerived, name).
+		Where("(dd.namespace IS NULL OR dd.namespace = '' OR dd.namespace = ?)", namespace)
+	if runID != nil {
+		q = q.Where("dd.run_id = ?", *runID)
+	}
+	if asOf != nil {
+		q = q.Where("dd.created_at <= ?", *asOf)
+	}
+	var rows []derivationRow
+	if err := q.Order("dd.created_at DESC, dd.id DESC").Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	row := rows[0]
+	producedAt := row.CreatedAt
+	return &UpstreamNode{
+		DatasetNamespace: namespace,
+		DatasetName:      name,
+		ProducingStep:    row.StepName,
+		JobID:            parseUUIDOrNil(row.JobID),
+		JobAlias:         row.JobAlias,
+		RunID:            parseUUIDPtr(row.RunID),
+		ProvenanceCommit: row.ProvenanceCommit,
+		ProvenanceRepo:   row.ProvenanceRepo,
+		ProducedAt:       &producedAt,
+		Source:           UpstreamSourceDerivation,
+	}, nil
+}
+
+// findInputs returns the datasets the run behind produced read: the producing
+// task run's observed inputs, merged with the consumed-watermark snapshot of
+// the derivation that started the run. Consumed watermarks name datasets in
+// the produced dataset's namespace. Inputs are sorted by name and namespace
+// so the result order is deterministic.
+func findInputs(ctx context.Context, db *gorm.DB, produced *UpstreamNode) ([]upstreamInput, error) {
+	byKey := make(map[string]*upstreamInput)
+	var inputs []*upstreamInput
+
+	if produced.TaskRunID != nil {
+		var rows []models.LineageDataset
+		if err := db.WithContext(ctx).
+			Where("task_run_id = ? AND direction = ?", *produced.TaskRunID, "input").
+			Order("name, namespace").
+			Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			in := &upstreamInput{namespace: row.Namespace, name: row.Name, readAt: row.CreatedAt}
+			byKey[datasetKey(row.Namespace, row.Name)] = in
+			inputs = append(inputs, in)
+		}
+	}
+
+	if produced.RunID != nil {
+		var derivations []models.DatasetDerivation
+		if err := db.WithContext(ctx).
+			Where("run_id = ? AND name = ? AND decision = ?", *produced.RunID, produced.DatasetName, models.DatasetDecisionDerived).
+			Order("created_at DESC").
+			Limit(1).
+			Find(&derivations).Error; err != nil {
+			return nil, err
+		}
+		for _, derivation := range derivations {
+			var consumed map[string]string
+			if len(derivation.ConsumedWatermarks) > 0 {
+				_ = json.Unmarshal(derivation.ConsumedWatermarks, &consumed)
+			}
+			for consumedName, watermark := range consumed {
+				key := datasetKey(produced.DatasetNamespace, consumedName)
+				if in, ok := byKey[key]; ok {
+					in.watermark = watermark
+					continue
+				}
+				in := &upstreamInput{
+					namespace: produced.DatasetNamespace,
+					name:      consumedName,
+					readAt:    derivation.CreatedAt,
+					watermark: watermark,
+				}
+				byKey[key] = in
+				inputs = append(inputs, in)
+			}
+		}
+	}
+
+	sort.SliceStable(inputs, func(i, j int) bool {
+		if inputs[i].name != inputs[j].name {
+			return inputs[i].name < inputs[j].name
+		}
+		return inputs[i].namespace < inputs[j].namespace
+	})
+	out := make([]upstreamInput, 0, len(inputs))
+	for _, in := range inputs {
+		out = append(out, *in)
+	}
+	return out, nil
+}
+
+func parseUUIDOrNil(raw string) uuid.UUID {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+func parseUUIDPtr(raw string) *uuid.UUID {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
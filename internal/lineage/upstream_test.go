@@ -0,0 +1,310 @@
+package lineage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/event"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// TestUpstreamFollowsRunsThatFed: the walk resolves each input to the version
+// produced before the consumer read it, not the latest production.
+func (s *ImpactSuite) TestUpstreamFollowsRunsThatFed() {
+	ns := "test"
+	t0 := time.Now().UTC().Add(-time.Hour)
+
+	ingest, ingest1 := s.createJobAndRun("ingest", "sha-1")
+	s.createDatasetAt(ingest1, ns, "raw.orders", "output", "extract", t0)
+
+	_, build := s.createJobAndRun("build", "sha-build")
+	s.createDatasetAt(build, ns, "raw.orders", "input", "aggregate", t0.Add(10*time.Minute))
+	s.createDatasetAt(build, ns, "mart.revenue", "output", "aggregate", t0.Add(10*time.Minute))
+
+	// A later ingest run must not be reported as the producer that fed build.
+	ingest2 := s.createRunForJob(ingest)
+	s.createDatasetAt(ingest2, ns, "raw.orders", "output", "extract", t0.Add(20*time.Minute))
+
+	result, err := QueryUpstream(s.ctx, s.db, ns, "mart.revenue", nil, 0)
+	s.Require().NoError(err)
+	s.Require().NotNil(result.Producer)
+	s.Equal("build", result.Producer.JobAlias)
+	s.Equal(build.JobRunID, *result.Producer.RunID)
+	s.Equal(build.ID, *result.Producer.TaskRunID)
+	s.Equal(UpstreamSourceLineage, result.Producer.Source)
+
+	s.Require().Len(result.Upstream, 1)
+	node := result.Upstream[0]
+	s.Equal("raw.orders", node.DatasetName)
+	s.Equal("mart.revenue", node.FeedsName)
+	s.Equal("extract", node.ProducingStep)
+	s.Equal(ingest1.JobRunID, *node.RunID)
+	s.Equal("sha-1", node.ProvenanceCommit)
+	s.Equal(ingest.ID, node.JobID)
+	s.Equal(0, node.Depth)
+	s.False(node.External)
+}
+
+// TestUpstreamPinnedRun: run_id selects which production of the root to walk.
+func (s *ImpactSuite) TestUpstreamPinnedRun() {
+	ns := "test"
+	t0 := time.Now().UTC().Add(-time.Hour)
+
+	ingest, ingest1 := s.createJobAndRun("ingest", "")
+	s.createDatasetAt(ingest1, ns, "raw.orders", "output", "extract", t0)
+	build, build1 := s.createJobAndRun("build", "")
+	s.createDatasetAt(build1, ns, "raw.orders", "input", "aggregate", t0.Add(5*time.Minute))
+	s.createDatasetAt(build1, ns, "mart.revenue", "output", "aggregate", t0.Add(5*time.Minute))
+
+	ingest2 := s.createRunForJob(ingest)
+	s.createDatasetAt(ingest2, ns, "raw.orders", "output", "extract", t0.Add(10*time.Minute))
+	build2 := s.createRunForJob(build)
+	s.createDatasetAt(build2, ns, "raw.orders", "input", "aggregate", t0.Add(15*time.Minute))
+	s.createDatasetAt(build2, ns, "mart.revenue", "output", "aggregate", t0.Add(15*time.Minute))
+
+	latest, err := QueryUpstream(s.ctx, s.db, ns, "mart.revenue", nil, 0)
+	s.Require().NoError(err)
+	s.Equal(build2.JobRunID, *latest.Producer.RunID)
+	s.Require().Len(latest.Upstream, 1)
+	s.Equal(ingest2.JobRunID, *latest.Upstream[0].RunID)
+
+	pinned, err := QueryUpstream(s.ctx, s.db, ns, "mart.revenue", &build1.JobRunID, 0)
+	s.Require().NoError(err)
+	s.Equal(build1.JobRunID, *pinned.Producer.RunID)
+	s.Require().Len(pinned.Upstream, 1)
+	s.Equal(ingest1.JobRunID, *pinned.Upstream[0].RunID)
+}
+
+// TestUpstreamTransitiveAndExternal: a chain is walked to its external source.
+func (s *ImpactSuite) TestUpstreamTransitiveAndExternal() {
+	ns := "test"
+	t0 := time.Now().UTC().Add(-time.Hour)
+
+	_, a := s.createJobAndRun("job-a", "")
+	s.createDatasetAt(a, ns, "vendor.feed", "input", "extract", t0)
+	s.createDatasetAt(a, ns, "raw.a", "output", "extract", t0)
+	_, b := s.createJobAndRun("job-b", "")
+	s.createDatasetAt(b, ns, "raw.a", "input", "transform", t0.Add(time.Minute))
+	s.createDatasetAt(b, ns, "mart.b", "output", "transform", t0.Add(time.Minute))
+
+	result, err := QueryUpstream(s.ctx, s.db, ns, "mart.b", nil, 0)
+	s.Require().NoError(err)
+	s.Require().Len(result.Upstream, 2)
+	s.Equal("raw.a", result.Upstream[0].DatasetName)
+	s.Equal(0, result.Upstream[0].Depth)
+	s.Equal("vendor.feed", result.Upstream[1].DatasetName)
+	s.Equal("raw.a", result.Upstream[1].FeedsName)
+	s.Equal(1, result.Upstream[1].Depth)
+	s.True(result.Upstream[1].External)
+
+	shallow, err := QueryUpstream(s.ctx, s.db, ns, "mart.b", nil, 1)
+	s.Require().NoError(err)
+	s.Len(shallow.Upstream, 1)
+}
+
+// TestUpstreamKeepsSameNameInputsApart: inputs that share a name across
+// namespaces are distinct datasets with their own producers, and a consumed
+// watermark belongs to the one in the produced dataset's namespace.
+func (s *ImpactSuite) TestUpstreamKeepsSameNameInputsApart() {
+	t0 := time.Now().UTC().Add(-time.Hour)
+
+	_, east := s.createJobAndRun("ingest-east", "")
+	s.createDatasetAt(east, "east", "raw.orders", "output", "extract", t0)
+	_, west := s.createJobAndRun("ingest-west", "")
+	s.createDatasetAt(west, "west", "raw.orders", "output", "extract", t0)
+
+	_, build := s.createJobAndRun("build", "")
+	s.createDatasetAt(build, "east", "raw.orders", "input", "merge", t0.Add(time.Minute))
+	s.createDatasetAt(build, "west", "raw.orders", "input", "merge", t0.Add(time.Minute))
+	s.createDatasetAt(build, "east", "mart.orders", "output", "merge", t0.Add(time.Minute))
+	s.Require().NoError(s.db.Create(&models.DatasetDerivation{
+		ID: uuid.New(), Name: "mart.orders", Decision: models.DatasetDecisionDerived,
+		ConsumedWatermarks: datatypes.JSON(`{"raw.orders":"2026-10-16"}`),
+		RunID:              &build.JobRunID, CreatedAt: t0.Add(time.Minute),
+	}).Error)
+
+	result, err := QueryUpstream(s.ctx, s.db, "east", "mart.orders", nil, 0)
+	s.Require().NoError(err)
+	s.Require().Len(result.Upstream, 2)
+	s.Equal("east", result.Upstream[0].DatasetNamespace)
+	s.Equal(east.JobRunID, *result.Upstream[0].RunID)
+	s.Equal("2026-10-16", result.Upstream[0].Watermark)
+	s.Equal("west", result.Upstream[1].DatasetNamespace)
+	s.Equal(west.JobRunID, *result.Upstream[1].RunID)
+	s.Empty(result.Upstream[1].Watermark)
+}
+
+// TestUpstreamFromDerivation: a dataset derived by the freshness evaluator
+// but never observed in lineage_datasets is attributed through
+// dataset_derivations, and its consumed watermarks become inputs.
+func (s *ImpactSuite) TestUpstreamFromDerivation() {
+	ns := "test"
+	job, run := s.createJobAndRun("derived-job", "sha-derived")
+	s.Require().NoError(s.db.Create(&models.DatasetDeclaration{
+		ID: uuid.New(), JobID: job.ID, JobAlias: job.Alias, StepName: "load",
+		Name: "mart.daily", Direction: models.DatasetDirectionProduces,
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}).Error)
+	s.Require().NoError(s.db.Create(&models.DatasetDerivation{
+		ID: uuid.New(), Name: "mart.daily", Decision: models.DatasetDecisionDerived,
+		ConsumedWatermarks: datatypes.JSON(`{"raw.events":"2026-10-16"}`),
+		RunID:              &run.JobRunID, CreatedAt: time.Now().UTC(),
+	}).Error)
+	// Skipped decisions never attribute a producer.
+	s.Require().NoError(s.db.Create(&models.DatasetDerivation{
+		ID: uuid.New(), Name: "mart.daily", Decision: models.DatasetDecisionSkippedFresh,
+		CreatedAt: time.Now().UTC(),
+	}).Error)
+
+	result, err := QueryUpstream(s.ctx, s.db, ns, "mart.daily", nil, 0)
+	s.Require().NoError(err)
+	s.Require().NotNil(result.Producer)
+	s.Equal(UpstreamSourceDerivation, result.Producer.Source)
+	s.Equal("load", result.Producer.ProducingStep)
+	s.Equal("derived-job", result.Producer.JobAlias)
+	s.Equal("sha-derived", result.Producer.ProvenanceCommit)
+	s.Equal(run.JobRunID, *result.Producer.RunID)
+	s.Nil(result.Producer.TaskRunID)
+
+	s.Require().Len(result.Upstream, 1)
+	s.Equal("raw.events", result.Upstream[0].DatasetName)
+	s.Equal("2026-10-16", result.Upstream[0].Watermark)
+	s.True(result.Upstream[0].External)
+}
+
+// TestUpstreamUnknownDataset: no recorded production yields no producer.
+func (s *ImpactSuite) TestUpstreamUnknownDataset() {
+	result, err := QueryUpstream(s.ctx, s.db, "test", "nope", nil, 0)
+	s.Require().NoError(err)
+	s.Nil(result.Producer)
+	s.Empty(result.Upstream)
+}
+
+// TestColumnLineageQueries: declared column lineage is walked both ways
+// across jobs.
+func (s *ImpactSuite) TestColumnLineageQueries() {
+	staging, _ := s.createJobAndRun("staging", "")
+	mart, _ := s.createJobAndRun("mart", "")
+	s.createColumnDeclaration(staging, "clean", "staging.orders", `{
+		"amount_usd": {"inputFields": [{"dataset": "raw.orders", "field": "amount", "type": "DIRECT"}]},
+		"status": {"inputFields": [{"dataset": "raw.orders", "field": "status", "type": "DIRECT"}]}}`)
+	s.createColumnDeclaration(mart, "aggregate", "mart.revenue", `{
+		"total": {"inputFields": [
+			{"dataset": "staging.orders", "field": "amount_usd", "type": "DIRECT", "subtype": "AGGREGATION"},
+			{"dataset": "staging.orders", "field": "status", "type": "INDIRECT", "subtype": "FILTER"}]}}`)
+
+	impact, err := QueryColumnImpact(s.ctx, s.db, "raw.orders", "amount", 0)
+	s.Require().NoError(err)
+	s.Require().Len(impact.Downstream, 2)
+	s.Equal("staging.orders", impact.Downstream[0].OutputDataset)
+	s.Equal("amount_usd", impact.Downstream[0].OutputField)
+	s.Equal("staging", impact.Downstream[0].JobAlias)
+	s.Equal(0, impact.Downstream[0].Depth)
+	s.Equal("mart.revenue", impact.Downstream[1].OutputDataset)
+	s.Equal("total", impact.Downstream[1].OutputField)
+	s.Equal("AGGREGATION", impact.Downstream[1].Subtype)
+	s.Equal(1, impact.Downstream[1].Depth)
+
+	upstream, err := QueryColumnUpstream(s.ctx, s.db, "mart.revenue", "total", 0)
+	s.Require().NoError(err)
+	s.Require().Len(upstream, 4)
+	fields := make([]string, 0, len(upstream))
+	for _, e := range upstream {
+		fields = append(fields, e.InputDataset+"."+e.InputField)
+	}
+	s.Equal([]string{"staging.orders.amount_usd", "staging.orders.status", "raw.orders.amount", "raw.orders.status"}, fields)
+	s.Equal(jobdef.ColumnLineageIndirect, upstream[1].Type)
+}
+
+// TestMapperEmitsDeclaredColumnLineage: a step's declared datasets are emitted
+// and persisted, with the columnLineage facet on produced datasets.
+func (s *ImpactSuite) TestMapperEmitsDeclaredColumnLineage() {
+	const ns = "caesium"
+	job, _ := s.createJobAndRun("revenue", "")
+	var jobRun models.JobRun
+	s.Require().NoError(s.db.Where("job_id = ?", job.ID).First(&jobRun).Error)
+	tr := s.createTaskWithRun(job.ID, jobRun.ID, "aggregate", nil, nil)
+
+	s.createColumnDeclaration(job, "aggregate", "mart.revenue", `{
+		"total": {"inputFields": [{"dataset": "raw.orders", "field": "amount", "type": "DIRECT", "subtype": "AGGREGATION"}]}}`)
+	s.Require().NoError(s.db.Create(&models.DatasetDeclaration{
+		ID: uuid.New(), JobID: job.ID, JobAlias: job.Alias, StepName: "aggregate",
+		Name: "raw.orders", Direction: models.DatasetDirectionConsumes,
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}).Error)
+
+	m := newMapper(ns, s.db)
+	runEvent, err := m.mapEvent(event.Event{
+		Type:      event.TypeTaskSucceeded,
+		JobID:     job.ID,
+		RunID:     jobRun.ID,
+		TaskID:    tr.TaskID,
+		Timestamp: time.Now().UTC(),
+		Payload:   marshalFacet(taskRunPayload{ID: tr.ID, JobRunID: jobRun.ID, TaskID: tr.TaskID, Status: "succeeded"}),
+	})
+	s.Require().NoError(err)
+	s.Require().Len(runEvent.Inputs, 1)
+	s.Equal("raw.orders", runEvent.Inputs[0].Name)
+	s.Require().Len(runEvent.Outputs, 1)
+	s.Equal("mart.revenue", runEvent.Outputs[0].Name)
+
+	raw, err := json.Marshal(runEvent.Outputs[0].Facets["columnLineage"])
+	s.Require().NoError(err)
+	s.JSONEq(`{
+		"_producer": "`+producerURI+`",
+		"_schemaURL": "`+columnLineageFacetSchema+`",
+		"fields": {"total": {"inputFields": [{
+			"namespace": "caesium", "name": "raw.orders", "field": "amount",
+			"transformations": [{"type": "DIRECT", "subtype": "AGGREGATION"}]}]}}}`, string(raw))
+
+	result, err := QueryUpstream(s.ctx, s.db, ns, "mart.revenue", nil, 0)
+	s.Require().NoError(err)
+	s.Require().NotNil(result.Producer)
+	s.Equal("aggregate", result.Producer.ProducingStep)
+	s.Require().Len(result.Upstream, 1)
+	s.Equal("raw.orders", result.Upstream[0].DatasetName)
+}
+
+func (s *ImpactSuite) createDatasetAt(run *models.TaskRun, ns, name, direction, stepName string, at time.Time) {
+	facet := marshalFacet(map[string]interface{}{
+		"caesium_dataset": map[string]interface{}{"step_name": stepName, "direction": direction},
+	})
+	s.Require().NoError(s.db.Create(&models.LineageDataset{
+		ID:           uuid.New(),
+		TaskRunID:    run.ID,
+		Namespace:    ns,
+		Name:         name,
+		Direction:    direction,
+		FacetSummary: datatypes.JSON(facet),
+		CreatedAt:    at,
+	}).Error)
+}
+
+// createRunForJob seeds another succeeded job run and task run for job.
+func (s *ImpactSuite) createRunForJob(job *models.Job) *models.TaskRun {
+	var task models.Task
+	s.Require().NoError(s.db.Where("job_id = ?", job.ID).First(&task).Error)
+	jobRun := &models.JobRun{
+		ID: uuid.New(), JobID: job.ID, TriggerID: job.TriggerID, Status: "succeeded",
+		StartedAt: time.Now().UTC(), CompletedAt: ptrTime(time.Now().UTC()),
+	}
+	s.Require().NoError(s.db.Create(jobRun).Error)
+	taskRun := &models.TaskRun{
+		ID: uuid.New(), JobRunID: jobRun.ID, TaskID: task.ID, AtomID: task.AtomID,
+		Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: "[]", Status: "succeeded",
+	}
+	s.Require().NoError(s.db.Create(taskRun).Error)
+	return taskRun
+}
+
+func (s *ImpactSuite) createColumnDeclaration(job *models.Job, step, name, columnLineage string) {
+	s.Require().NoError(s.db.Create(&models.DatasetDeclaration{
+		ID: uuid.New(), JobID: job.ID, JobAlias: job.Alias, StepName: step,
+		Name: name, Direction: models.DatasetDirectionProduces,
+		ColumnLineage: datatypes.JSON(columnLineage),
+		CreatedAt:     time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}).Error)
+}
@@ -0,0 +1,59 @@
+package logarchive
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/caesium-cloud/caesium/pkg/log"
+)
+
+var (
+	defaultArchive     *Archive
+	defaultArchiveOnce sync.Once
+)
+
+// FromEnv builds the archive configured by the CAESIUM_LOG_ARCHIVE_*
+// variables. It returns nil when archiving is disabled.
+func FromEnv(vars env.Environment) (*Archive, error) {
+	var (
+		store Store
+		err   error
+	)
+	switch backend := strings.ToLower(strings.TrimSpace(vars.LogArchiveBackend)); backend {
+	case "":
+		return nil, nil
+	case "filesystem":
+		store, err = NewFileStore(strings.TrimSpace(vars.LogArchiveDir))
+	case "s3":
+		store, err = NewS3Store(S3Config{
+			Endpoint:        strings.TrimSpace(vars.LogArchiveS3Endpoint),
+			Bucket:          strings.TrimSpace(vars.LogArchiveS3Bucket),
+			Region:          strings.TrimSpace(vars.LogArchiveS3Region),
+			AccessKeyID:     vars.LogArchiveS3AccessKeyID,
+			SecretAccessKey: vars.LogArchiveS3SecretAccessKey,
+			Prefix:          vars.LogArchiveS3Prefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown log archive backend %q", backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return New(store, int(vars.LogArchiveChunkSize.Int64())), nil
+}
+
+// Default returns the process-wide archive built from the environment, or
+// nil when archiving is disabled or misconfigured.
+func Default() *Archive {
+	defaultArchiveOnce.Do(func() {
+		archive, err := FromEnv(env.Variables())
+		if err != nil {
+			log.Error("task log archive disabled", "error", err)
+			return
+		}
+		defaultArchive = archive
+	})
+	return defaultArchive
+}
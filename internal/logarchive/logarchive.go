@@ -0,0 +1,381 @@
+// Package logarchive keeps the complete log of every task in a blob store,
+// beyond the capped snapshot persisted on the task run.
+//
+// A task's log is archived as a sequence of independently gzip-compressed
+// chunks plus a JSON manifest that records each chunk's uncompressed offset
+// and size. Chunks are uploaded as the log is written, and the manifest is
+// rewritten after every chunk, so the archive of a running task is readable
+// up to its last full chunk. Reads decompress only the chunks a byte range
+// covers.
+package logarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DefaultChunkSize is the uncompressed size at which a chunk is sealed and
+// uploaded.
+const DefaultChunkSize = 8 << 20
+
+const (
+	manifestName    = "manifest.json"
+	manifestVersion = 1
+)
+
+// ErrNotFound is returned by a Store when an object does not exist.
+var ErrNotFound = errors.New("log archive object not found")
+
+// Store is a flat blob store addressed by slash-separated keys.
+// FileStore and S3Store are the built-in backends.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Ref returns the archive reference of a task run's log.
+func Ref(runID, taskID uuid.UUID) string {
+	return path.Join("runs", runID.String(), "tasks", taskID.String())
+}
+
+// InstanceRef returns the archive reference of one mapped instance's log.
+func InstanceRef(runID, taskID uuid.UUID, index int) string {
+	return path.Join(Ref(runID, taskID), "instances", strconv.Itoa(index))
+}
+
+// Manifest lists the chunks of one archived log.
+type Manifest struct {
+	Version  int     `json:"version"`
+	Size     int64   `json:"size"`
+	Complete bool    `json:"complete"`
+	Chunks   []Chunk `json:"chunks"`
+}
+
+// Chunk is one gzip-compressed slice of the log.
+type Chunk struct {
+	Key            string `json:"key"`
+	Offset         int64  `json:"offset"`
+	Size           int64  `json:"size"`
+	CompressedSize int64  `json:"compressed_size"`
+}
+
+// Archive writes and reads task logs in a Store.
+type Archive struct {
+	store     Store
+	chunkSize int
+}
+
+// New returns an archive over store. A chunkSize of zero or less uses
+// DefaultChunkSize.
+func New(store Store, chunkSize int) *Archive {
+	if store == nil {
+		panic("log archive requires a store")
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &Archive{store: store, chunkSize: chunkSize}
+}
+
+// Create starts a new archive at ref, replacing any archive already there,
+// such as one left by an earlier attempt of the same task.
+func (a *Archive) Create(ctx context.Context, ref string) (*Writer, error) {
+	if err := a.Remove(ctx, ref); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		ctx:      ctx,
+		archive:  a,
+		ref:      ref,
+		manifest: Manifest{Version: manifestVersion},
+	}, nil
+}
+
+// Open reads the manifest of the archive at ref.
+func (a *Archive) Open(ctx context.Context, ref string) (*Object, error) {
+	body, err := a.store.Get(ctx, manifestKey(ref))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+
+	var manifest Manifest
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decode log archive manifest %s: %w", ref, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("log archive %s has unsupported manifest version %d", ref, manifest.Version)
+	}
+	return &Object{archive: a, manifest: manifest}, nil
+}
+
+// Remove deletes the archive at ref and every chunk it lists. A missing
+// archive is not an error.
+func (a *Archive) Remove(ctx context.Context, ref string) error {
+	object, err := a.Open(ctx, ref)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// The manifest goes first so a partial removal never leaves a manifest
+	// pointing at deleted chunks.
+	if err := a.store.Delete(ctx, manifestKey(ref)); err != nil {
+		return err
+	}
+	for _, chunk := range object.manifest.Chunks {
+		if err := a.store.Delete(ctx, chunk.Key); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func manifestKey(ref string) string {
+	return path.Join(ref, manifestName)
+}
+
+func chunkKey(ref string, index int) string {
+	return path.Join(ref, fmt.Sprintf("%08d.log.gz", index))
+}
+
+// Writer appends to an archive. Bytes are buffered until a chunk fills, so
+// at most one chunk of the log is held in memory. Close seals the last chunk
+// and marks the archive complete. A Writer is safe for concurrent use.
+type Writer struct {
+	ctx     context.Context
+	archive *Archive
+	ref     string
+
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	manifest Manifest
+	err      error
+	closed   bool
+}
+
+// Ref returns the archive reference the writer is filling.
+func (w *Writer) Ref() string {
+	return w.ref
+}
+
+// Write appends p to the log. It fails once an upload has failed.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("log archive writer is closed")
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		room := w.archive.chunkSize - w.buf.Len()
+		n := min(room, len(p))
+		w.buf.Write(p[:n])
+		written += n
+		p = p[n:]
+		if w.buf.Len() >= w.archive.chunkSize {
+			if err := w.flushLocked(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close uploads any buffered bytes and writes the final manifest.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	if w.buf.Len() > 0 {
+		if err := w.flushLocked(); err != nil {
+			return err
+		}
+	}
+	w.manifest.Complete = true
+	w.err = w.putManifestLocked()
+	return w.err
+}
+
+// Size returns the number of log bytes accepted so far.
+func (w *Writer) Size() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.manifest.Size + int64(w.buf.Len())
+}
+
+func (w *Writer) flushLocked() error {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(w.buf.Bytes()); err != nil {
+		w.err = err
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		w.err = err
+		return err
+	}
+
+	chunk := Chunk{
+		Key:            chunkKey(w.ref, len(w.manifest.Chunks)),
+		Offset:         w.manifest.Size,
+		Size:           int64(w.buf.Len()),
+		CompressedSize: int64(compressed.Len()),
+	}
+	if err := w.archive.store.Put(w.ctx, chunk.Key, compressed.Bytes()); err != nil {
+		w.err = fmt.Errorf("upload log chunk %s: %w", chunk.Key, err)
+		return w.err
+	}
+	w.manifest.Chunks = append(w.manifest.Chunks, chunk)
+	w.manifest.Size += chunk.Size
+	w.buf.Reset()
+	if err := w.putManifestLocked(); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) putManifestLocked() error {
+	data, err := json.Marshal(w.manifest)
+	if err != nil {
+		return err
+	}
+	if err := w.archive.store.Put(w.ctx, manifestKey(w.ref), data); err != nil {
+		return fmt.Errorf("upload log manifest %s: %w", w.ref, err)
+	}
+	return nil
+}
+
+// Object is an archived log as of the moment its manifest was read.
+type Object struct {
+	archive  *Archive
+	manifest Manifest
+}
+
+// Size returns the number of archived log bytes.
+func (o *Object) Size() int64 {
+	return o.manifest.Size
+}
+
+// Complete reports whether the task finished writing the log. An incomplete
+// archive belongs to a running task, or to one whose worker died, and holds
+// the log up to its last full chunk.
+func (o *Object) Complete() bool {
+	return o.manifest.Complete
+}
+
+// NewReader returns a reader over the log from offset to the end.
+func (o *Object) NewReader(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	if offset < 0 || offset > o.manifest.Size {
+		return nil, fmt.Errorf("offset %d is outside the archived log of %d bytes", offset, o.manifest.Size)
+	}
+	first := len(o.manifest.Chunks)
+	for idx, chunk := range o.manifest.Chunks {
+		if offset < chunk.Offset+chunk.Size {
+			first = idx
+			break
+		}
+	}
+	return &chunkReader{ctx: ctx, object: o, next: first, skip: offset}, nil
+}
+
+// chunkReader decompresses the chunks of an object one after another,
+// fetching each only when the previous one is exhausted.
+type chunkReader struct {
+	ctx    context.Context
+	object *Object
+	next   int
+	skip   int64
+
+	body io.ReadCloser
+	zr   *gzip.Reader
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.zr == nil {
+			if r.next >= len(r.object.manifest.Chunks) {
+				return 0, io.EOF
+			}
+			if err := r.openNext(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.zr.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.closeCurrent()
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) openNext() error {
+	chunk := r.object.manifest.Chunks[r.next]
+	r.next++
+	body, err := r.object.archive.store.Get(r.ctx, chunk.Key)
+	if err != nil {
+		return fmt.Errorf("read log chunk %s: %w", chunk.Key, err)
+	}
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		_ = body.Close()
+		return fmt.Errorf("read log chunk %s: %w", chunk.Key, err)
+	}
+	r.body, r.zr = body, zr
+	if skip := r.skip - chunk.Offset; skip > 0 {
+		if _, err := io.CopyN(io.Discard, zr, skip); err != nil {
+			r.closeCurrent()
+			return fmt.Errorf("seek log chunk %s: %w", chunk.Key, err)
+		}
+	}
+	return nil
+}
+
+func (r *chunkReader) closeCurrent() {
+	if r.body != nil {
+		_ = r.body.Close()
+	}
+	r.body, r.zr = nil, nil
+}
+
+func (r *chunkReader) Close() error {
+	r.closeCurrent()
+	r.next = len(r.object.manifest.Chunks)
+	return nil
+}
+
+// validKey rejects keys that could escape a store's root.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid log archive key %q", key)
+	}
+	return nil
+}
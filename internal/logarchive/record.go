@@ -0,0 +1,245 @@
+package logarchive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/google/uuid"
+)
+
+// DefaultDrainTimeout bounds how long Finish waits for a task's log stream
+// to end once the task has exited.
+const DefaultDrainTimeout = 30 * time.Second
+
+// maxGrepLineBytes caps how much of one log line Grep matches and returns.
+const maxGrepLineBytes = 64 << 10
+
+// Opener opens a task's log stream. Engine log streams follow the container
+// and end when it exits.
+type Opener func() (io.ReadCloser, error)
+
+// Recorder copies a task's log into an archive while the task runs.
+type Recorder struct {
+	writer *Writer
+	open   Opener
+
+	mu      sync.Mutex
+	stream  io.ReadCloser
+	done    chan struct{}
+	copyErr error
+}
+
+// Record creates the archive at ref and copies the task's log into it in the
+// background as the task writes it. When the stream cannot be opened yet, as
+// for a Kubernetes pod that is still pending, Finish opens it after the task
+// exits. Record on a nil Archive returns a nil Recorder, whose Finish is a
+// no-op.
+func (a *Archive) Record(ctx context.Context, ref string, open Opener) (*Recorder, error) {
+	if a == nil {
+		return nil, nil
+	}
+	writer, err := a.Create(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{writer: writer, open: open}
+	if stream, err := open(); err == nil {
+		r.start(stream)
+	}
+	return r, nil
+}
+
+// Ref returns the archive reference, or "" for a nil Recorder.
+func (r *Recorder) Ref() string {
+	if r == nil {
+		return ""
+	}
+	return r.writer.Ref()
+}
+
+func (r *Recorder) start(stream io.ReadCloser) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stream = stream
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		_, err := io.Copy(r.writer, stream)
+		r.copyErr = err
+	}()
+}
+
+// Finish waits up to drain for the log stream to end, then seals the archive
+// and returns its size. Call it once the task has exited.
+func (r *Recorder) Finish(drain time.Duration) (int64, error) {
+	if r == nil {
+		return 0, nil
+	}
+	r.mu.Lock()
+	started := r.done != nil
+	r.mu.Unlock()
+	if !started {
+		stream, err := r.open()
+		if err != nil {
+			metrics.LogArchivesTotal.WithLabelValues("incomplete").Inc()
+			return 0, errors.Join(fmt.Errorf("open log stream: %w", err), r.writer.Close())
+		}
+		r.start(stream)
+	}
+
+	timer := time.NewTimer(drain)
+	defer timer.Stop()
+	var timedOut bool
+	select {
+	case <-r.done:
+	case <-timer.C:
+		timedOut = true
+	}
+	// Closing the stream unblocks a copy that is still waiting on it.
+	_ = r.stream.Close()
+	<-r.done
+
+	var copyErr error
+	switch {
+	case timedOut:
+		copyErr = fmt.Errorf("log stream did not end within %s; archive holds the log up to that point", drain)
+	case r.copyErr != nil:
+		copyErr = fmt.Errorf("copy log stream: %w", r.copyErr)
+	}
+	err := errors.Join(copyErr, r.writer.Close())
+	size := r.writer.Size()
+	metrics.LogArchiveBytesTotal.Add(float64(size))
+	if err != nil {
+		metrics.LogArchivesTotal.WithLabelValues("incomplete").Inc()
+	} else {
+		metrics.LogArchivesTotal.WithLabelValues("complete").Inc()
+	}
+	return size, err
+}
+
+// TaskKey identifies the task run, or the one instance of a mapped step,
+// whose log is archived.
+type TaskKey struct {
+	RunID  uuid.UUID
+	TaskID uuid.UUID
+	// InstanceID is set for an instance of a mapped step, at position Index
+	// in the step's item list.
+	InstanceID uuid.UUID
+	Index      int
+}
+
+// Ref returns where the key's log is archived.
+func (k TaskKey) Ref() string {
+	if k.InstanceID == uuid.Nil {
+		return Ref(k.RunID, k.TaskID)
+	}
+	return InstanceRef(k.RunID, k.TaskID, k.Index)
+}
+
+func (k TaskKey) attrs() []any {
+	attrs := []any{"run_id", k.RunID, "task_id", k.TaskID}
+	if k.InstanceID != uuid.Nil {
+		attrs = append(attrs, "index", k.Index)
+	}
+	return attrs
+}
+
+// RefStore records where task and instance logs are archived. The run store
+// implements it.
+type RefStore interface {
+	SetTaskLogArchiveRef(runID, taskID uuid.UUID, ref string) error
+	SetTaskInstanceLogArchiveRef(id uuid.UUID, ref string) error
+}
+
+// TaskLog archives one task's log while its atom runs. Archiving is
+// best-effort: failures are logged and never fail the task.
+type TaskLog struct {
+	recorder *Recorder
+	key      TaskKey
+	once     sync.Once
+}
+
+// RecordTask starts archiving the log of key's atom and records the archive
+// reference in refs, so readers find the partial log while the task runs.
+// The archive outlives a cancelled or timed-out ctx so the log is kept.
+// RecordTask on a nil Archive returns a nil TaskLog, whose Finish is a no-op.
+func (a *Archive) RecordTask(ctx context.Context, refs RefStore, key TaskKey, open Opener) *TaskLog {
+	if a == nil {
+		return nil
+	}
+	ref := key.Ref()
+	recorder, err := a.Record(context.WithoutCancel(ctx), ref, open)
+	if err != nil {
+		log.Warn("failed to start task log archive", append(key.attrs(), "error", err)...)
+		return nil
+	}
+	if key.InstanceID != uuid.Nil {
+		err = refs.SetTaskInstanceLogArchiveRef(key.InstanceID, ref)
+	} else {
+		err = refs.SetTaskLogArchiveRef(key.RunID, key.TaskID, ref)
+	}
+	if err != nil {
+		log.Warn("failed to persist task log archive reference", append(key.attrs(), "error", err)...)
+	}
+	return &TaskLog{recorder: recorder, key: key}
+}
+
+// Finish seals the archive once the atom has exited, before it is removed.
+// Only the first call does anything, so Finish can also be deferred.
+func (t *TaskLog) Finish() {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		if size, err := t.recorder.Finish(DefaultDrainTimeout); err != nil {
+			log.Warn("task log archive is incomplete", append(t.key.attrs(), "ref", t.recorder.Ref(), "bytes", size, "error", err)...)
+		}
+	})
+}
+
+// Grep writes each line of r that matches re to w as "<line number>:<line>",
+// like grep -n, and stops after maxMatches matches. Lines longer than 64 KiB
+// are matched and returned truncated. It returns the number of matches.
+func Grep(r io.Reader, w io.Writer, re *regexp.Regexp, maxMatches int) (int, error) {
+	br := bufio.NewReaderSize(r, maxGrepLineBytes)
+	line := make([]byte, 0, 4096)
+	lineNo, matches := 0, 0
+	for {
+		fragment, err := br.ReadSlice('\n')
+		if room := maxGrepLineBytes - len(line); room > 0 {
+			line = append(line, fragment[:min(room, len(fragment))]...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if len(line) > 0 {
+			lineNo++
+			text := bytes.TrimRight(line, "\r\n")
+			if re.Match(text) {
+				if _, writeErr := fmt.Fprintf(w, "%d:%s\n", lineNo, text); writeErr != nil {
+					return matches, writeErr
+				}
+				matches++
+				if matches >= maxMatches {
+					return matches, nil
+				}
+			}
+			line = line[:0]
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return matches, nil
+			}
+			return matches, err
+		}
+	}
+}
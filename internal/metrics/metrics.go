@@ -133,6 +133,14 @@ var (
 		[]string{"node_id"},
 	)
 
+	WorkerDrainReleasedTasksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_worker_drain_released_tasks_total",
+			Help: "Total number of running task claims a draining worker released after its drain deadline, by node.",
+		},
+		[]string{"node_id"},
+	)
+
 	TaskRetriesTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "caesium_task_retries_total",
@@ -245,6 +253,99 @@ var (
 		[]string{"job_alias"},
 	)
 
+	PoolSlots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "caesium_pool_slots",
+			Help: "Configured slot capacity by pool.",
+		},
+		[]string{"pool"},
+	)
+
+	PoolOccupiedSlots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "caesium_pool_occupied_slots",
+			Help: "Slots currently held by running or gated tasks by pool.",
+		},
+		[]string{"pool"},
+	)
+
+	PoolQueuedTasks = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "caesium_pool_queued_tasks",
+			Help: "Ready tasks waiting for a pool slot by pool.",
+		},
+		[]string{"pool"},
+	)
+
+	SensorPokesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_sensor_pokes_total",
+			Help: "Total sensor probe evaluations by job, probe kind, and result.",
+		},
+		[]string{"job_alias", "probe", "result"},
+	)
+
+	SensorTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_sensor_timeouts_total",
+			Help: "Total sensor steps whose timeout passed before their condition held, by job and probe kind.",
+		},
+		[]string{"job_alias", "probe"},
+	)
+
+	RunsArchivedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "caesium_runs_archived_total",
+			Help: "Total terminal runs moved to the cold-history database.",
+		},
+	)
+
+	RunsPurgedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_runs_purged_total",
+			Help: "Total runs deleted by retention policy, by the database they were deleted from.",
+		},
+		[]string{"tier"},
+	)
+
+	BackupsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_backups_total",
+			Help: "Total scheduled database backups, by result.",
+		},
+		[]string{"result"},
+	)
+
+	BackupLastSuccessTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "caesium_backup_last_success_timestamp_seconds",
+			Help: "Unix time of the last scheduled backup that completed.",
+		},
+	)
+
+	LogArchivesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_log_archives_total",
+			Help: "Total task logs streamed to the log archive, by result (complete, incomplete).",
+		},
+		[]string{"result"},
+	)
+
+	LogArchiveBytesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "caesium_log_archive_bytes_total",
+			Help: "Total uncompressed task log bytes written to the log archive.",
+		},
+	)
+
+	NamespaceQuotaRejectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_namespace_quota_rejections_total",
+			Help: "Total run admissions rejected by a namespace quota, by namespace.",
+		},
+		[]string{"namespace"},
+	)
+
 	DatasetStalenessSeconds = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "caesium_dataset_staleness_seconds",
@@ -477,6 +578,7 @@ var (
 	//   network_error    – PostDispatch returned a non-nil error (network / timeout)
 	//   worker_rejected  – worker returned 409 (busy, claim mismatch, etc.)
 	//   no_peers         – peer discovery returned an empty list or failed
+	//   no_eligible_peer – no peer's labels satisfy the task's nodeSelector/nodeAffinity
 	DispatchRejectedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "caesium_dispatch_rejected_total",
@@ -586,6 +688,7 @@ func Register() {
 			DBBusyRetriesTotal,
 			ReclaimDurationSeconds,
 			WorkerLeaseExpirationsTotal,
+			WorkerDrainReleasedTasksTotal,
 			TaskRetriesTotal,
 			BackfillRunsTotal,
 			BackfillsActive,
@@ -599,6 +702,18 @@ func Register() {
 			RunReplacedTotal,
 			RunQueueDepth,
 			RunQueueWaitSeconds,
+			PoolSlots,
+			PoolOccupiedSlots,
+			PoolQueuedTasks,
+			SensorPokesTotal,
+			SensorTimeoutsTotal,
+			RunsArchivedTotal,
+			RunsPurgedTotal,
+			BackupsTotal,
+			BackupLastSuccessTimestamp,
+			LogArchivesTotal,
+			LogArchiveBytesTotal,
+			NamespaceQuotaRejectionsTotal,
 			DatasetStalenessSeconds,
 			DatasetDerivationsTotal,
 			FreshnessViolationsTotal,
@@ -34,6 +34,7 @@ func (s *MetricsSuite) SetupTest() {
 		DBBusyRetriesTotal,
 		ReclaimDurationSeconds,
 		WorkerLeaseExpirationsTotal,
+		WorkerDrainReleasedTasksTotal,
 		TaskRetriesTotal,
 		WebhookAuthFailuresTotal,
 		EventBusDroppedTotal,
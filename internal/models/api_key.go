@@ -73,14 +73,16 @@ func (k *APIKey) IsExpired() bool {
 
 // KeyScope represents optional resource scoping for an API key.
 //
-// A key carries at most one kind of restriction. Jobs restricts a normal
-// principal to a set of job aliases (checked by the deny-by-default route-scope
-// switch). Agent, when present, marks the key as a short-lived agent-session
+// Jobs restricts a normal principal to a set of job aliases and Namespaces to
+// the jobs of a set of namespaces (both checked by the deny-by-default
+// route-scope switch); a key with both may act only on jobs that satisfy
+// each. Agent, when present, marks the key as a short-lived agent-session
 // credential bound to exactly one incident's /v1/agent/* tool surface; an agent
-// key is valid for nothing else, regardless of its Jobs field.
+// key is valid for nothing else, regardless of its Jobs or Namespaces.
 type KeyScope struct {
-	Jobs  []string    `json:"jobs,omitempty"`
-	Agent *AgentClaim `json:"agent,omitempty"`
+	Jobs       []string    `json:"jobs,omitempty"`
+	Namespaces []string    `json:"namespaces,omitempty"`
+	Agent      *AgentClaim `json:"agent,omitempty"`
 }
 
 // AgentClaim binds an API key to a single incident's agent tool surface. It is
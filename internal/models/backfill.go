@@ -4,12 +4,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 type BackfillStatus string
 
 const (
 	BackfillStatusRunning   BackfillStatus = "running"
+	BackfillStatusPaused    BackfillStatus = "paused"
 	BackfillStatusSucceeded BackfillStatus = "succeeded"
 	BackfillStatusFailed    BackfillStatus = "failed"
 	BackfillStatusCancelled BackfillStatus = "cancelled"
@@ -23,6 +25,18 @@ const (
 	ReprocessAll    ReprocessPolicy = "all"
 )
 
+// BackfillOrder is the order in which a backfill launches its runs.
+type BackfillOrder string
+
+const (
+	BackfillOrderOldestFirst BackfillOrder = "oldest_first"
+	BackfillOrderNewestFirst BackfillOrder = "newest_first"
+)
+
+// Backfill is a durable request to replay a cron job over [Start, End), or
+// over an explicit list of Dates. The leader's reconciler launches its runs in
+// Order, advancing Cursor past each run it has launched or skipped, so any node
+// can resume it.
 type Backfill struct {
 	ID                uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
 	JobID             uuid.UUID  `gorm:"type:uuid;index;not null" json:"job_id"`
@@ -35,8 +49,30 @@ type Backfill struct {
 	TotalRuns         int        `gorm:"not null;default:0" json:"total_runs"`
 	CompletedRuns     int        `gorm:"not null;default:0" json:"completed_runs"`
 	FailedRuns        int        `gorm:"not null;default:0" json:"failed_runs"`
+	Cursor            *time.Time `json:"cursor,omitempty"`
 	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty"`
 	CompletedAt       *time.Time `json:"completed_at,omitempty"`
 	CreatedAt         time.Time  `gorm:"not null" json:"created_at"`
 	UpdatedAt         time.Time  `gorm:"not null" json:"updated_at"`
+
+	// Order is oldest_first or newest_first.
+	Order string `gorm:"column:date_order;type:text;not null;default:'oldest_first'" json:"order"`
+	// Dates, when set, lists the RFC3339 logical dates to replay instead of
+	// every fire time between Start and End; Start and End then record the
+	// earliest and latest of them.
+	Dates datatypes.JSON `json:"dates,omitempty"`
+	// BatchSize groups this many consecutive logical dates into one run whose
+	// data interval spans all of them.
+	BatchSize int `gorm:"not null;default:1" json:"batch_size"`
+	// Params are merged over the trigger's defaultParams for every run;
+	// DateParams, keyed by RFC3339 logical date, are merged over those.
+	Params     datatypes.JSON `json:"params,omitempty"`
+	DateParams datatypes.JSON `json:"date_params,omitempty"`
+	// MaxFailures stops launching new runs once this many runs have failed.
+	// Zero never stops early.
+	MaxFailures int `gorm:"not null;default:0" json:"max_failures,omitempty"`
+	// LaunchDate is the launch intent: the logical date the reconciler is
+	// starting a run for, recorded before the run starts and cleared when the
+	// cursor passes it. A leader that finds it set finishes that launch.
+	LaunchDate *time.Time `json:"-"`
 }
@@ -77,6 +77,11 @@ type DatasetDeclaration struct {
 	// breaks. It is zero when unset and unused for consumed/source declarations.
 	SchemaVersion int `json:"schema_version,omitempty"`
 
+	// ColumnLineage is produces[].columnLineage as JSON: output column →
+	// {inputFields: [{dataset, field, type, subtype, description}]}. Empty for
+	// consumed/source declarations and producers without column lineage.
+	ColumnLineage datatypes.JSON `gorm:"type:json" json:"column_lineage,omitempty"`
+
 	// SkipWhenFresh carries metadata.datasets.skipWhenFresh after defaulting.
 	// It is evaluated at the cron scheduling seam only and never affects a task's
 	// cache identity. Pointer form preserves an explicit false across GORM's
@@ -11,7 +11,8 @@ import (
 
 type Job struct {
 	ID                 uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
-	Alias              string            `gorm:"uniqueIndex" json:"alias"`
+	Namespace          string            `gorm:"type:text;not null;default:'default';uniqueIndex:idx_jobs_namespace_alias,priority:1" json:"namespace"`
+	Alias              string            `gorm:"uniqueIndex:idx_jobs_namespace_alias,priority:2" json:"alias"`
 	TriggerID          uuid.UUID         `gorm:"type:uuid;index;not null" json:"trigger_id"`
 	Trigger            Trigger           `gorm:"constraint:OnDelete:CASCADE" json:"-"`
 	Labels             datatypes.JSONMap `gorm:"type:json" json:"labels"`
@@ -28,15 +29,19 @@ type Job struct {
 	Concurrency        datatypes.JSON    `gorm:"type:json" json:"concurrency,omitempty"`
 	RateLimits         datatypes.JSON    `gorm:"type:json" json:"rate_limits,omitempty"`
 	SLA                datatypes.JSON    `gorm:"type:json" json:"sla,omitempty"`
+	Retention          datatypes.JSON    `gorm:"type:json" json:"retention,omitempty"`
 	// SchemaValidation controls runtime output schema validation for this job's tasks.
 	// Values: "" (disabled), "warn" (log violations), "fail" (fail task on violation).
-	SchemaValidation string         `gorm:"type:text;not null;default:''" json:"schema_validation,omitempty"`
-	ReplaySafe       bool           `gorm:"not null;default:false" json:"replay_safe"`
-	CacheConfig      datatypes.JSON `gorm:"type:json" json:"cache_config,omitempty"`
-	Paused           bool           `gorm:"not null;default:false" json:"paused"`
-	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
-	CreatedAt        time.Time      `gorm:"not null" json:"created_at"`
-	UpdatedAt        time.Time      `gorm:"not null" json:"updated_at"`
+	SchemaValidation string `gorm:"type:text;not null;default:''" json:"schema_validation,omitempty"`
+	ReplaySafe       bool   `gorm:"not null;default:false" json:"replay_safe"`
+	// RunTemplates enables run-time templating of this job's step command and
+	// env values.
+	RunTemplates bool           `gorm:"not null;default:false" json:"run_templates"`
+	CacheConfig  datatypes.JSON `gorm:"type:json" json:"cache_config,omitempty"`
+	Paused       bool           `gorm:"not null;default:false" json:"paused"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	CreatedAt    time.Time      `gorm:"not null" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"not null" json:"updated_at"`
 
 	LatestRun *JobRun `gorm:"-" json:"latest_run,omitempty"`
 }
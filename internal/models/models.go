@@ -16,6 +16,9 @@ var All = []interface{}{
 	&Backfill{},
 	&JobRun{},
 	&TaskRun{},
+	&TaskRunInstance{},
+	&TaskApproval{},
+	&SensorPoke{},
 	&LineageDataset{},
 	&ContractAck{},
 	&TaskCache{},
@@ -30,10 +33,21 @@ var All = []interface{}{
 	&NotificationChannel{},
 	&NotificationPolicy{},
 	&RateLimitToken{},
+	&Pool{},
+	// sla_alerts is the notification watcher's durable dedup state. A small
+	// catalog table keyed by alert, with no FK so pruning never races run or
+	// job deletion.
+	&SLAAlert{},
 	// Phase 2 run-owner coordination tables (catalog DB, cross-run, low-volume).
 	&RunLease{},
 	&InternalCAGeneration{},
 	&InternalNodeEnrollment{},
+	// node_states holds operator cordon/drain decisions (catalog DB, one row
+	// per unschedulable node).
+	&NodeState{},
+	// worker_nodes holds the labels each worker last advertised (catalog DB,
+	// one row per node).
+	&WorkerNode{},
 	// run_checkpoints is per-run and lives with task_runs (catalog when
 	// unsharded, hot shard when sharded — see hotPathModels), so it is listed
 	// here for the unsharded case and in hotPathModels for the sharded case.
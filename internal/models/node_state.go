@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// NodeState records an operator's scheduling decision for one worker node.
+// A node without a row is schedulable. Cordoned nodes stop claiming and are
+// skipped by the run-owner dispatch loop; draining nodes additionally hand
+// their running tasks back once DrainDeadline passes.
+//
+// This table lives in the catalog DB (cross-run, low-volume) so every node
+// sees the same decision.
+type NodeState struct {
+	// Address is the node's CAESIUM_NODE_ADDRESS, the same identity task_runs
+	// record in claimed_by.
+	Address string `gorm:"type:text;primaryKey" json:"address"`
+
+	// State is "cordoned" or "draining".
+	State string `gorm:"type:text;not null" json:"state"`
+
+	// DrainDeadline is when a draining node stops waiting for its running
+	// tasks and releases their claims. Nil waits for them to finish.
+	DrainDeadline *time.Time `json:"drain_deadline,omitempty"`
+
+	// UpdatedBy is the principal that cordoned or drained the node.
+	UpdatedBy string    `gorm:"type:text;not null;default:''" json:"updated_by"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+}
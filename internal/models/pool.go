@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Pool is a named, cluster-wide slot budget. Steps that declare `pool` occupy
+// their slots while running, and workers admit a pooled task only while the
+// pool's running tasks leave room for it. This is a catalog table: pools are
+// operator-managed and low-cardinality.
+type Pool struct {
+	Name        string    `gorm:"type:text;primaryKey" json:"name"`
+	Slots       int       `gorm:"not null;default:0" json:"slots"`
+	Description string    `gorm:"type:text;not null;default:''" json:"description,omitempty"`
+	CreatedAt   time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null" json:"updated_at"`
+}
+
+func (Pool) TableName() string {
+	return "pools"
+}
@@ -15,9 +15,10 @@ const (
 
 type JobRun struct {
 	ID           uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	JobID        uuid.UUID      `gorm:"type:uuid;index;not null" json:"job_id"`
+	JobID        uuid.UUID      `gorm:"type:uuid;index;index:idx_job_runs_job_logical_date,priority:1;not null" json:"job_id"`
 	Job          Job            `gorm:"constraint:OnDelete:CASCADE" json:"-"`
-	BackfillID   *uuid.UUID     `gorm:"type:uuid;index" json:"backfill_id,omitempty"`
+	Namespace    string         `gorm:"type:text;not null;default:'default';index" json:"namespace"`
+	BackfillID   *uuid.UUID     `gorm:"type:uuid;index;index:idx_job_runs_backfill_logical_date,priority:1" json:"backfill_id,omitempty"`
 	Backfill     *Backfill      `gorm:"constraint:OnDelete:SET NULL" json:"-"`
 	TriggerID    uuid.UUID      `gorm:"type:uuid;index" json:"trigger_id"`
 	TriggerType  string         `gorm:"type:text" json:"trigger_type"`
@@ -26,7 +27,16 @@ type JobRun struct {
 	Priority     int            `gorm:"not null;default:2" json:"priority"`
 	Error        string         `json:"error,omitempty"`
 	Params       datatypes.JSON `gorm:"type:json" json:"params,omitempty"`
-	Quarantine   bool           `gorm:"not null;default:false;index" json:"quarantine"`
+	// LogicalDate copies the logical_date param into an indexed column so
+	// backfills and reprocess policies find a date's runs without decoding
+	// every run's params. Quarantined replays leave it empty so they never
+	// count as a date's run.
+	LogicalDate string `gorm:"type:text;not null;default:'';index:idx_job_runs_job_logical_date,priority:2;index:idx_job_runs_backfill_logical_date,priority:2" json:"-"`
+	Quarantine  bool   `gorm:"not null;default:false;index" json:"quarantine"`
+	// TraceParent is the W3C traceparent of the span that started the run
+	// (internal/tracing). Task runs copy it at registration so every node that
+	// executes one of the run's tasks continues the same trace.
+	TraceParent string `gorm:"type:text;not null;default:''" json:"-"`
 	// ReplayFingerprint is the scoped, server-derived idempotency fingerprint
 	// for quarantined replay creation. It is nullable so ordinary runs do not
 	// participate in the unique index.
@@ -52,18 +62,22 @@ type TaskRun struct {
 	Engine         AtomEngine `gorm:"type:text;not null" json:"engine"`
 	Image          string     `gorm:"not null" json:"image"`
 	Command        string     `gorm:"not null" json:"command"`
-	Status         string     `gorm:"type:text;index;index:idx_taskrun_claim_priority,priority:1;not null" json:"status"`
+	Status         string     `gorm:"type:text;index;index:idx_taskrun_claim_priority,priority:1;index:idx_taskrun_pool_status,priority:2;not null" json:"status"`
 	ClaimedBy      string     `gorm:"type:text;index;index:idx_taskrun_claim_priority,priority:5;not null;default:''" json:"claimed_by"`
 	ClaimExpiresAt *time.Time `gorm:"index" json:"claim_expires_at,omitempty"`
 	ClaimAttempt   int        `gorm:"not null;default:0" json:"claim_attempt"`
 	// RateLimitRetryAfter keeps over-limit tasks pending without letting worker
 	// claims or owner dispatch pick them back up before the current window rolls.
+	// Reschedule-mode sensors reuse it to wait out the gap between pokes.
 	RateLimitRetryAfter *time.Time        `gorm:"index" json:"rate_limit_retry_after,omitempty"`
 	Attempt             int               `gorm:"not null;default:1" json:"attempt"`
 	MaxAttempts         int               `gorm:"not null;default:1" json:"max_attempts"`
 	Priority            int               `gorm:"not null;default:2;index:idx_taskrun_claim_priority,priority:3,sort:desc" json:"priority"`
 	NodeSelector        datatypes.JSONMap `gorm:"type:json" json:"node_selector,omitempty"`
-	Hash                string            `gorm:"type:text;index" json:"-"`
+	// NodeAffinity snapshots the task's nodeAffinity block so worker claims
+	// and owner dispatch can place the task without reloading the catalog.
+	NodeAffinity datatypes.JSON `gorm:"type:json" json:"node_affinity,omitempty"`
+	Hash         string         `gorm:"type:text;index" json:"-"`
 	// EffectiveHash is the identity this task presents to its DOWNSTREAM
 	// consumers when a value-verified short-circuit was proven (design Component
 	// 5 / D2). Nullable: empty means "use Hash" — the common case. When this
@@ -118,6 +132,11 @@ type TaskRun struct {
 	SchemaValidation string `gorm:"type:text;not null;default:''" json:"-"`
 	// SchemaViolations stores any output schema violations detected at runtime.
 	SchemaViolations datatypes.JSON `gorm:"type:json" json:"schema_violations,omitempty"`
+	// Pool and PoolSlots snapshot the task's pool at registration. A running
+	// task run occupies PoolSlots of its pool, and claims admit a pooled task
+	// only while the pool has room for it.
+	Pool      string `gorm:"type:text;not null;default:'';index:idx_taskrun_pool_status,priority:1" json:"pool,omitempty"`
+	PoolSlots int    `gorm:"not null;default:0" json:"pool_slots,omitempty"`
 	// ExitCode is the raw process exit code the container/pod reported at task
 	// completion. Every engine folds this code into an atom.Result and discards
 	// it today; this column preserves it so the incident classifier can map
@@ -125,13 +144,32 @@ type TaskRun struct {
 	// unset (NULL) when the task never produced an exit code (engine wait error,
 	// startup failure before a code was assigned). A value of 0 is a real,
 	// captured success code — distinct from NULL "never captured".
-	ExitCode                *int           `gorm:"type:integer" json:"exit_code,omitempty"`
-	ExecutionDescriptor     datatypes.JSON `gorm:"type:json" json:"-"`
-	LogText                 string         `gorm:"type:text" json:"-"`
-	LogTruncated            bool           `gorm:"not null;default:false" json:"-"`
-	Error                   string         `json:"error,omitempty"`
-	RuntimeID               string         `json:"runtime_id,omitempty"`
-	OutstandingPredecessors int            `gorm:"not null;index:idx_taskrun_claim_priority,priority:2" json:"outstanding_predecessors"`
+	ExitCode            *int           `gorm:"type:integer" json:"exit_code,omitempty"`
+	ExecutionDescriptor datatypes.JSON `gorm:"type:json" json:"-"`
+	LogText             string         `gorm:"type:text" json:"-"`
+	LogTruncated        bool           `gorm:"not null;default:false" json:"-"`
+	// TraceID identifies the run's trace so a task can be looked up in the
+	// tracing backend; TraceParent is the run span the task's spans descend
+	// from. Both are empty when the run was started without a trace.
+	TraceID     string `gorm:"type:text;not null;default:'';index" json:"trace_id,omitempty"`
+	TraceParent string `gorm:"type:text;not null;default:''" json:"-"`
+	// LogArchiveRef locates the task's complete log in the log archive
+	// (internal/logarchive) when CAESIUM_LOG_ARCHIVE_BACKEND is set. LogText
+	// keeps only the capped snapshot.
+	LogArchiveRef           string `json:"-"`
+	Error                   string `json:"error,omitempty"`
+	RuntimeID               string `json:"runtime_id,omitempty"`
+	OutstandingPredecessors int    `gorm:"not null;index:idx_taskrun_claim_priority,priority:2" json:"outstanding_predecessors"`
+	// MapParentID is set on the task runs a mapped step's group expands into
+	// on distributed workers: one per instance (sharing the instance's ID),
+	// claimed and executed like any other task run. The row is deleted once
+	// its outcome is recorded on the instance or the run ends, so
+	// (job_run_id, task_id) lookups see only the group outside that window.
+	MapParentID *uuid.UUID `gorm:"type:uuid;index" json:"map_parent_id,omitempty"`
+	// MapExpanded marks a group whose instances run as their own task runs
+	// for its current attempt. The group then holds no claim or pool slots,
+	// and its next claim collects the instances' outcomes.
+	MapExpanded bool `gorm:"not null;default:false" json:"-"`
 	// OwnerGeneration is set to the RunLease.Generation of the owning node when
 	// run-owner mode is active.  Every coordination write by the owner
 	// includes AND (owner_generation = ? OR owner_generation = 0) in its WHERE
@@ -224,6 +262,7 @@ type TaskExecutionRuntime struct {
 	WorkDir             string            `json:"workdir,omitempty"`
 	TaskType            string            `json:"taskType,omitempty"`
 	NodeSelector        map[string]string `json:"nodeSelector,omitempty"`
+	NodeAffinity        datatypes.JSON    `json:"nodeAffinity,omitempty"`
 	RetryCount          int               `json:"retryCount"`
 	RetryDelay          time.Duration     `json:"retryDelay"`
 	RetryBackoff        bool              `json:"retryBackoff"`
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SensorPoke records one evaluation of a sensor step's probe within one run.
+// The first poke of a task run anchors the sensor's timeout, so the deadline
+// survives reschedules, restarts, and failover; the rows also back the pokes
+// shown by `caesium why`.
+type SensorPoke struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TaskRunID uuid.UUID `gorm:"type:uuid;index:idx_sensor_poke_task_run,priority:1;not null" json:"task_run_id"`
+	TaskRun   TaskRun   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	JobRunID  uuid.UUID `gorm:"type:uuid;index;not null" json:"job_run_id"`
+	TaskID    uuid.UUID `gorm:"type:uuid;not null" json:"task_id"`
+	// Probe is the sensor's probe kind (http, job, dataset, or file).
+	Probe     string `gorm:"type:text;not null" json:"probe"`
+	Satisfied bool   `gorm:"not null;default:false" json:"satisfied"`
+	// Detail describes what the probe observed, or why it could not tell.
+	Detail    string    `gorm:"type:text" json:"detail,omitempty"`
+	CreatedAt time.Time `gorm:"index:idx_sensor_poke_task_run,priority:2;not null" json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SLAAlertKind identifies which watcher alert an SLAAlert row records.
+type SLAAlertKind string
+
+const (
+	// SLAAlertRunTimedOut records a run_timed_out alert for a run.
+	SLAAlertRunTimedOut SLAAlertKind = "run_timed_out"
+	// SLAAlertDurationMissed records a duration sla_missed alert for a run.
+	SLAAlertDurationMissed SLAAlertKind = "sla_duration_missed"
+	// SLAAlertAtRisk records an sla_at_risk alert for a run.
+	SLAAlertAtRisk SLAAlertKind = "sla_at_risk"
+	// SLAAlertCompletedByMissed records a completedBy sla_missed alert for a
+	// job's calendar day.
+	SLAAlertCompletedByMissed SLAAlertKind = "sla_completed_by_missed"
+	// SLAAlertCompletedByMet records that a job's calendar day met its
+	// completedBy deadline, so the watcher stops re-checking it.
+	SLAAlertCompletedByMet SLAAlertKind = "sla_completed_by_met"
+)
+
+// SLAAlert is the notification watcher's dedup record: one row per alert it
+// has raised, keyed so that each run-scoped alert fires once per run and each
+// completedBy alert once per job and day. The row is inserted in the same
+// transaction that persists the alert's event, so the dedup survives restarts
+// and leader failover, and concurrent watchers on different nodes cannot both
+// emit. Rows are pruned once they can no longer match.
+type SLAAlert struct {
+	Key       string       `gorm:"type:text;primaryKey" json:"key"`
+	Kind      SLAAlertKind `gorm:"type:text;not null" json:"kind"`
+	JobID     uuid.UUID    `gorm:"type:uuid;index;not null" json:"job_id"`
+	RunID     *uuid.UUID   `gorm:"type:uuid;index" json:"run_id,omitempty"`
+	CreatedAt time.Time    `gorm:"not null;index" json:"created_at"`
+}
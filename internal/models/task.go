@@ -18,24 +18,38 @@ type Task struct {
 	Position     int               `gorm:"not null;default:0" json:"-"`
 	Type         string            `gorm:"type:text;not null;default:'task'" json:"type"`
 	NodeSelector datatypes.JSONMap `gorm:"type:json" json:"node_selector,omitempty"`
-	Retries      int               `gorm:"not null;default:0" json:"retries"`
-	RetryDelay   time.Duration     `gorm:"not null;default:0" json:"retry_delay"`
-	RetryBackoff bool              `gorm:"not null;default:false" json:"retry_backoff"`
-	TriggerRule  string            `gorm:"type:text;not null;default:'all_success'" json:"trigger_rule"`
-	ReplaySafe   bool              `gorm:"not null;default:false" json:"replay_safe"`
-	// RateLimitResource and RateLimitUnits carry step scheduling metadata from
-	// the job definition into the durable task catalog.
+	// NodeAffinity is the step's nodeAffinity block (pkg/jobdef.NodeAffinity);
+	// set only for steps with expression-based node constraints.
+	NodeAffinity datatypes.JSON `gorm:"type:json" json:"node_affinity,omitempty"`
+	Retries      int            `gorm:"not null;default:0" json:"retries"`
+	RetryDelay   time.Duration  `gorm:"not null;default:0" json:"retry_delay"`
+	RetryBackoff bool           `gorm:"not null;default:false" json:"retry_backoff"`
+	TriggerRule  string         `gorm:"type:text;not null;default:'all_success'" json:"trigger_rule"`
+	ReplaySafe   bool           `gorm:"not null;default:false" json:"replay_safe"`
+	// RateLimitResource, RateLimitUnits, PoolName, and PoolSlots carry step
+	// scheduling metadata from the job definition into the durable task catalog.
 	RateLimitResource string         `gorm:"type:text;not null;default:''" json:"rate_limit_resource,omitempty"`
 	RateLimitUnits    int            `gorm:"not null;default:0" json:"rate_limit_units,omitempty"`
+	PoolName          string         `gorm:"type:text;not null;default:''" json:"pool_name,omitempty"`
+	PoolSlots         int            `gorm:"not null;default:0" json:"pool_slots,omitempty"`
 	CacheConfig       datatypes.JSON `gorm:"type:json" json:"cache_config,omitempty"`
 	// OutputSchema is a JSON Schema describing this task's expected output keys.
 	OutputSchema datatypes.JSON `gorm:"type:json" json:"output_schema,omitempty"`
 	// InputSchema maps predecessor task names to JSON Schema fragments describing
 	// required keys from each predecessor's output.
 	InputSchema datatypes.JSON `gorm:"type:json" json:"input_schema,omitempty"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	CreatedAt   time.Time      `gorm:"not null"`
-	UpdatedAt   time.Time      `gorm:"not null"`
+	// MapConfig is the step's map block (pkg/jobdef.StepMap); set only for
+	// steps that fan out over a predecessor's output list.
+	MapConfig datatypes.JSON `gorm:"type:json" json:"map_config,omitempty"`
+	// GateConfig is the step's gate block (pkg/jobdef.StepGate); set only for
+	// steps that wait for human approval before running.
+	GateConfig datatypes.JSON `gorm:"type:json" json:"gate_config,omitempty"`
+	// SensorConfig is the step's sensor block (pkg/jobdef.StepSensor); set
+	// only for sensor steps.
+	SensorConfig datatypes.JSON `gorm:"type:json" json:"sensor_config,omitempty"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	CreatedAt    time.Time      `gorm:"not null"`
+	UpdatedAt    time.Time      `gorm:"not null"`
 }
 
 type Tasks []*Task
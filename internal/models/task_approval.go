@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// TaskApproval records the human decision on a gated step (a step with a
+// `gate:` block) within one run. It is created when the step's dependencies
+// are satisfied and the task run parks in awaiting_approval, and resolved by
+// an approve/reject call or by the gate sweeper once ExpiresAt passes. It
+// shares ApprovalDecision with the incident tier-3 ApprovalRequest but lives
+// in its own run-scoped table next to task_runs.
+type TaskApproval struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TaskRunID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"task_run_id"`
+	TaskRun   TaskRun   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	JobRunID  uuid.UUID `gorm:"type:uuid;index;not null" json:"job_run_id"`
+	TaskID    uuid.UUID `gorm:"type:uuid;index;not null" json:"task_id"`
+	// Approvers and OnTimeout are copied from the step's gate when the
+	// request is opened, so a re-applied definition cannot change the terms
+	// of a pending request.
+	Approvers datatypes.JSON   `gorm:"type:json" json:"approvers,omitempty"`
+	OnTimeout string           `gorm:"type:text;not null;default:'fail'" json:"on_timeout"`
+	Decision  ApprovalDecision `gorm:"type:text;index;not null;default:'pending'" json:"decision"`
+	// Decider records the identity that resolved the request; empty for an
+	// expired gate.
+	Decider   string     `gorm:"type:text" json:"decider,omitempty"`
+	Reason    string     `gorm:"type:text" json:"reason,omitempty"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+	CreatedAt time.Time  `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null" json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// TaskRunInstance is one materialized instance of a mapped step: the step's
+// own TaskRun row carries the aggregate (group) status that successors and
+// trigger rules see, and each item of the list it maps over gets one
+// instance row here. Instances live in their own table so every existing
+// (job_run_id, task_id) lookup keeps addressing exactly one task run.
+type TaskRunInstance struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TaskRunID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_task_run_instance_index,priority:1;not null" json:"task_run_id"`
+	TaskRun   TaskRun   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	JobRunID  uuid.UUID `gorm:"type:uuid;index;not null" json:"job_run_id"`
+	TaskID    uuid.UUID `gorm:"type:uuid;index;not null" json:"task_id"`
+	// Index is the item's position in the mapped list; Item is its value
+	// (strings verbatim, anything else as compact JSON).
+	Index     int            `gorm:"column:item_index;uniqueIndex:idx_task_run_instance_index,priority:2;not null" json:"index"`
+	Item      string         `gorm:"type:text;not null;default:''" json:"item"`
+	Status    string         `gorm:"type:text;index;not null" json:"status"`
+	Attempt   int            `gorm:"not null;default:1" json:"attempt"`
+	RuntimeID string         `gorm:"type:text;not null;default:''" json:"runtime_id,omitempty"`
+	Result    string         `json:"result,omitempty"`
+	Output    datatypes.JSON `gorm:"type:json" json:"output,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	// Hash is the instance's own cache identity: the step's inputs plus its
+	// item. HashInputBlob decomposes it for `caesium why`.
+	Hash             string         `gorm:"type:text;index" json:"-"`
+	HashInputBlob    datatypes.JSON `gorm:"type:json" json:"-"`
+	CacheHit         bool           `gorm:"not null;default:false" json:"cache_hit"`
+	CacheOriginRunID *uuid.UUID     `gorm:"type:uuid" json:"cache_origin_run_id,omitempty"`
+	// LogArchiveRef locates the instance's complete log in the log archive
+	// (internal/logarchive) when CAESIUM_LOG_ARCHIVE_BACKEND is set.
+	LogArchiveRef string     `gorm:"type:text;not null;default:''" json:"-"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"not null" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"not null" json:"updated_at"`
+}
@@ -20,6 +20,7 @@ const (
 
 type Trigger struct {
 	ID                 uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Namespace          string         `gorm:"type:text;not null;default:'default';index" json:"namespace"`
 	Alias              string         `gorm:"index" json:"alias"`
 	Type               TriggerType    `gorm:"index;not null" json:"type"`
 	NormalizedPath     string         `gorm:"index" json:"-"`
@@ -15,6 +15,9 @@ type User struct {
 	Email       string         `gorm:"type:text;index" json:"email"`
 	DisplayName string         `gorm:"type:text" json:"display_name,omitempty"`
 	Groups      datatypes.JSON `gorm:"type:json" json:"groups,omitempty"`
+	// Namespaces restricts the user to the listed namespaces, as mapped from
+	// their groups at last login. Empty is unrestricted.
+	Namespaces  datatypes.JSON `gorm:"type:json" json:"namespaces,omitempty"`
 	Role        Role           `gorm:"type:text;not null" json:"role"`
 	CreatedAt   time.Time      `gorm:"not null" json:"created_at"`
 	LastLoginAt *time.Time     `json:"last_login_at,omitempty"`
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// WorkerNode records the labels a worker node last advertised. Workers
+// refresh their row while they run, so placement checks (the run-owner
+// dispatcher and nodeAffinity lint) can tell which labels are live.
+//
+// This table lives in the catalog DB (cross-run, low-volume) alongside
+// node_states.
+type WorkerNode struct {
+	// Address is the node's CAESIUM_NODE_ADDRESS, the same identity task_runs
+	// record in claimed_by.
+	Address string `gorm:"type:text;primaryKey" json:"address"`
+
+	// Labels is the node's parsed CAESIUM_NODE_LABELS.
+	Labels datatypes.JSONMap `gorm:"type:json" json:"labels,omitempty"`
+
+	// SeenAt is when the node last refreshed its row.
+	SeenAt time.Time `gorm:"not null;index" json:"seen_at"`
+}
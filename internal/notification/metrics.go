@@ -63,6 +63,16 @@ var (
 		},
 		[]string{"job_alias"},
 	)
+
+	// SLAAtRiskTotal counts predictive SLA at-risk events. A run counted here
+	// may still finish in time.
+	SLAAtRiskTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "caesium_sla_at_risk_total",
+			Help: "Total SLA at-risk events observed.",
+		},
+		[]string{"job_alias"},
+	)
 )
 
 // RegisterMetrics registers all notification metrics with the default Prometheus registry.
@@ -75,6 +85,7 @@ func RegisterMetrics() {
 			RunFailuresTotal,
 			RunTimeoutsTotal,
 			SLAMissesTotal,
+			SLAAtRiskTotal,
 		)
 	})
 }
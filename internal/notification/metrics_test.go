@@ -13,6 +13,7 @@ func TestRecordEventMetric(t *testing.T) {
 		{Type: event.TypeRunFailed, Payload: mustJSON(map[string]string{"job_alias": "b"})},
 		{Type: event.TypeRunTimedOut, Payload: mustJSON(map[string]string{"job_alias": "c"})},
 		{Type: event.TypeSLAMissed, Payload: mustJSON(map[string]string{"job_alias": "d"})},
+		{Type: event.TypeSLAAtRisk, Payload: mustJSON(map[string]string{"job_alias": "e"})},
 		{Type: event.TypeRunCompleted},
 		{Type: event.TypeTaskSucceeded},
 	}
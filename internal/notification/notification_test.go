@@ -199,9 +199,11 @@ func TestValidEventTypes(t *testing.T) {
 		event.TypeRunFailed,
 		event.TypeRunTimedOut,
 		event.TypeSLAMissed,
+		event.TypeSLAAtRisk,
 		event.TypeRunCompleted,
 		event.TypeTaskSucceeded,
 		event.TypeContractBreakDeclared,
+		event.TypeTaskAwaitingApproval,
 	}
 
 	for _, et := range expected {
@@ -155,7 +155,7 @@ func defaultPDSeverity(t event.Type) string {
 		return "error"
 	case event.TypeRunTimedOut:
 		return "error"
-	case event.TypeSLAMissed:
+	case event.TypeSLAMissed, event.TypeSLAAtRisk:
 		return "warning"
 	case event.TypeRunCompleted, event.TypeTaskSucceeded:
 		return "info"
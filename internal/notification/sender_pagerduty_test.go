@@ -71,6 +71,7 @@ func TestBuildPagerDutyEvent_DefaultSeverity(t *testing.T) {
 		{event.TypeRunFailed, "error"},
 		{event.TypeRunTimedOut, "error"},
 		{event.TypeSLAMissed, "warning"},
+		{event.TypeSLAAtRisk, "warning"},
 		{event.TypeRunCompleted, "info"},
 		{event.TypeTaskSucceeded, "info"},
 	}
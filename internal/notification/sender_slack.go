@@ -158,10 +158,14 @@ func eventEmoji(t event.Type) string {
 		return "⏱️"
 	case event.TypeSLAMissed:
 		return "⚠️"
+	case event.TypeSLAAtRisk:
+		return "⏳"
 	case event.TypeRunCompleted:
 		return "✅"
 	case event.TypeTaskSucceeded:
 		return "✅"
+	case event.TypeTaskAwaitingApproval:
+		return "✋"
 	default:
 		return "📢"
 	}
@@ -177,10 +181,14 @@ func friendlyEventName(t event.Type) string {
 		return "Run Timed Out"
 	case event.TypeSLAMissed:
 		return "SLA Missed"
+	case event.TypeSLAAtRisk:
+		return "SLA At Risk"
 	case event.TypeRunCompleted:
 		return "Run Completed"
 	case event.TypeTaskSucceeded:
 		return "Task Succeeded"
+	case event.TypeTaskAwaitingApproval:
+		return "Approval Required"
 	default:
 		return string(t)
 	}
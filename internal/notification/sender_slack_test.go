@@ -158,8 +158,10 @@ func TestFriendlyEventName(t *testing.T) {
 		{event.TypeRunFailed, "Run Failed"},
 		{event.TypeRunTimedOut, "Run Timed Out"},
 		{event.TypeSLAMissed, "SLA Missed"},
+		{event.TypeSLAAtRisk, "SLA At Risk"},
 		{event.TypeRunCompleted, "Run Completed"},
 		{event.TypeTaskSucceeded, "Task Succeeded"},
+		{event.TypeTaskAwaitingApproval, "Approval Required"},
 		{event.Type("unknown"), "unknown"},
 	}
 
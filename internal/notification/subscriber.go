@@ -18,9 +18,11 @@ var notifiableTypes = []event.Type{
 	event.TypeRunFailed,
 	event.TypeRunTimedOut,
 	event.TypeSLAMissed,
+	event.TypeSLAAtRisk,
 	event.TypeRunCompleted,
 	event.TypeTaskSucceeded,
 	event.TypeContractBreakDeclared,
+	event.TypeTaskAwaitingApproval,
 }
 
 // Subscriber listens to the event bus and dispatches notifications
@@ -366,6 +368,8 @@ func recordEventMetric(evt event.Event) {
 		RunTimeoutsTotal.WithLabelValues(alias).Inc()
 	case event.TypeSLAMissed:
 		SLAMissesTotal.WithLabelValues(alias).Inc()
+	case event.TypeSLAAtRisk:
+		SLAAtRiskTotal.WithLabelValues(alias).Inc()
 	}
 }
 
@@ -3,17 +3,33 @@ package notification
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/sla"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// alertRetention bounds how long sla_alerts rows outlive the runs and
+// calendar days they dedup. completedBy keys only ever match today's date, so
+// two days is enough; rows for runs still running are kept regardless.
+const alertRetention = 48 * time.Hour
+
+// alertPruneInterval is how often a watcher deletes expired dedup rows.
+// Retention is measured in days, so pruning on every scan only adds writes.
+const alertPruneInterval = time.Hour
+
+// errAlreadyAlerted rolls back an emit whose dedup row already exists,
+// because an earlier scan or another node raised the alert first.
+var errAlreadyAlerted = errors.New("sla alert already raised")
+
 // slaConfig mirrors pkg/jobdef.SLAConfig for deserialization without
 // importing the jobdef package (which would create an import cycle for
 // callers that also import models).
@@ -22,40 +38,41 @@ type slaConfig struct {
 	CompletedBy string        `json:"completedBy,omitempty"`
 }
 
-// Watcher periodically scans for timeout and SLA violations,
-// publishing the appropriate events on the bus.
+// Watcher periodically scans for timeout and SLA violations, and for runs
+// predicted to miss an SLA, publishing the appropriate events on the bus.
+// Each alert fires once: its dedup row in sla_alerts is written in the same
+// transaction as its event, so restarts, leader failover, and watchers on
+// other nodes never repeat it.
 type Watcher struct {
-	db       *gorm.DB
-	bus      event.Bus
-	store    *event.Store
-	interval time.Duration
-
-	// alertedRuns tracks run-scoped alerts (timeout + duration SLA)
-	// to avoid duplicate notifications within a single process lifetime.
-	alertedRuns map[uuid.UUID]runAlertState
-
-	// alertedJobs tracks job-scoped alerts (completedBy SLA) keyed
-	// by "jobID|YYYY-MM-DD" to dedup per calendar day.
-	alertedJobs map[string]struct{}
-}
-
-type runAlertState struct {
-	timedOut    bool
-	slaDuration bool
+	db        *gorm.DB
+	bus       event.Bus
+	store     *event.Store
+	interval  time.Duration
+	predictor *sla.Predictor
+
+	// lastPrune is when pruneAlerts last ran; scans run on one goroutine.
+	lastPrune time.Time
 }
 
-// NewWatcher creates a new timeout/SLA watcher.
+// NewWatcher creates a new timeout/SLA watcher. ETAs use the default
+// task-duration percentile until SetPredictor overrides it.
 func NewWatcher(db *gorm.DB, bus event.Bus, store *event.Store, interval time.Duration) *Watcher {
 	if interval <= 0 {
 		interval = 15 * time.Second
 	}
 	return &Watcher{
-		db:          db,
-		bus:         bus,
-		store:       store,
-		interval:    interval,
-		alertedRuns: make(map[uuid.UUID]runAlertState),
-		alertedJobs: make(map[string]struct{}),
+		db:        db,
+		bus:       bus,
+		store:     store,
+		interval:  interval,
+		predictor: sla.NewPredictor(db, sla.DefaultPercentile),
+	}
+}
+
+// SetPredictor replaces the ETA predictor behind sla_at_risk alerts.
+func (w *Watcher) SetPredictor(p *sla.Predictor) {
+	if p != nil {
+		w.predictor = p
 	}
 }
 
@@ -79,9 +96,14 @@ func (w *Watcher) scan(ctx context.Context) {
 
 	w.scanRunningRuns(ctx, now)
 	w.scanCompletedBySLA(ctx, now)
+	if now.Sub(w.lastPrune) >= alertPruneInterval {
+		w.pruneAlerts(ctx, now)
+		w.lastPrune = now
+	}
 }
 
-// scanRunningRuns checks active runs for timeout and duration-based SLA violations.
+// scanRunningRuns checks active runs for timeout and duration-based SLA
+// violations, and for completion predicted past an SLA deadline.
 func (w *Watcher) scanRunningRuns(ctx context.Context, now time.Time) {
 	var runs []models.JobRun
 	if err := w.db.WithContext(ctx).
@@ -92,43 +114,74 @@ func (w *Watcher) scanRunningRuns(ctx context.Context, now time.Time) {
 		return
 	}
 
-	// Clean up alerted state for runs that are no longer running.
-	runningIDs := make(map[uuid.UUID]struct{}, len(runs))
-	for _, r := range runs {
-		runningIDs[r.ID] = struct{}{}
+	if len(runs) == 0 {
+		return
 	}
-	for id := range w.alertedRuns {
-		if _, ok := runningIDs[id]; !ok {
-			delete(w.alertedRuns, id)
-		}
+
+	runIDs := make([]uuid.UUID, len(runs))
+	for i, r := range runs {
+		runIDs[i] = r.ID
+	}
+	alerted, err := w.alertedKeys(ctx, "run_id IN ?", runIDs)
+	if err != nil {
+		log.Error("notification watcher: failed to load alert state", "error", err)
+		return
 	}
 
 	for _, r := range runs {
-		state := w.alertedRuns[r.ID]
-
 		// Check run timeout.
-		if !state.timedOut && r.Job.RunTimeout > 0 {
+		timeoutKey := runAlertKey(models.SLAAlertRunTimedOut, r.ID)
+		if _, done := alerted[timeoutKey]; !done && r.Job.RunTimeout > 0 {
 			deadline := r.StartedAt.Add(r.Job.RunTimeout)
 			if now.After(deadline) {
 				w.emitTimeoutEvent(ctx, r, now)
-				state.timedOut = true
 			}
 		}
 
+		cfg := parseSLA(r.Job.SLA)
+		if cfg == nil {
+			continue
+		}
+
 		// Check duration-based SLA.
-		sla := parseSLA(r.Job.SLA)
-		if !state.slaDuration && sla != nil && sla.Duration > 0 {
-			slaDeadline := r.StartedAt.Add(sla.Duration)
+		durationKey := runAlertKey(models.SLAAlertDurationMissed, r.ID)
+		if _, done := alerted[durationKey]; !done && cfg.Duration > 0 {
+			slaDeadline := r.StartedAt.Add(cfg.Duration)
 			if now.After(slaDeadline) {
 				w.emitSLAEvent(ctx, r.JobID, &r, now,
 					fmt.Sprintf("SLA duration exceeded: run has been running for %s (limit %s)",
-						now.Sub(r.StartedAt).Truncate(time.Second), sla.Duration))
-				state.slaDuration = true
+						now.Sub(r.StartedAt).Truncate(time.Second), cfg.Duration))
 			}
 		}
 
-		w.alertedRuns[r.ID] = state
+		// Predict completion against the deadlines that have not passed yet.
+		if _, done := alerted[runAlertKey(models.SLAAlertAtRisk, r.ID)]; !done {
+			w.checkAtRisk(ctx, r, cfg, now)
+		}
+	}
+}
+
+// checkAtRisk estimates the run's completion and raises sla_at_risk when the
+// ETA falls after the earliest SLA deadline still ahead of now.
+func (w *Watcher) checkAtRisk(ctx context.Context, r models.JobRun, cfg *slaConfig, now time.Time) {
+	deadline, ok := upcomingDeadline(cfg, r.StartedAt, now)
+	if !ok {
+		return
+	}
+
+	est, err := w.predictor.Estimate(ctx, r.JobID, r.ID, now)
+	if err != nil {
+		log.Error("notification watcher: failed to estimate run completion",
+			"run_id", r.ID,
+			"error", err,
+		)
+		return
+	}
+	if est == nil || !est.ETA.After(deadline) {
+		return
 	}
+
+	w.emitAtRiskEvent(ctx, r, est, deadline, now)
 }
 
 // scanCompletedBySLA checks all jobs with a completedBy SLA to see if any
@@ -152,22 +205,21 @@ func (w *Watcher) scanCompletedBySLA(ctx context.Context, now time.Time) {
 		alertKey    string
 	}
 	var candidates []candidate
-	var jobIDs []uuid.UUID
 	// earliestWindow tracks the oldest window start across all candidates
 	// so we can bound the batch query.
 	var earliestWindow time.Time
 
 	for _, job := range jobs {
-		sla := parseSLA(job.SLA)
-		if sla == nil || sla.CompletedBy == "" {
+		cfg := parseSLA(job.SLA)
+		if cfg == nil || cfg.CompletedBy == "" {
 			continue
 		}
 
-		deadline, err := resolveCompletedBy(sla.CompletedBy, now)
+		deadline, err := resolveCompletedBy(cfg.CompletedBy, now)
 		if err != nil {
 			log.Error("notification watcher: invalid completedBy value",
 				"job_alias", job.Alias,
-				"completed_by", sla.CompletedBy,
+				"completed_by", cfg.CompletedBy,
 				"error", err,
 			)
 			continue
@@ -177,11 +229,7 @@ func (w *Watcher) scanCompletedBySLA(ctx context.Context, now time.Time) {
 			continue
 		}
 
-		alertKey := fmt.Sprintf("%s|%s", job.ID, deadline.Format("2006-01-02"))
-		if _, alerted := w.alertedJobs[alertKey]; alerted {
-			continue
-		}
-
+		alertKey := completedByAlertKey(job.ID, deadline)
 		windowStart := deadline.Add(-24 * time.Hour)
 		if earliestWindow.IsZero() || windowStart.Before(earliestWindow) {
 			earliestWindow = windowStart
@@ -193,13 +241,36 @@ func (w *Watcher) scanCompletedBySLA(ctx context.Context, now time.Time) {
 			windowStart: windowStart,
 			alertKey:    alertKey,
 		})
-		jobIDs = append(jobIDs, job.ID)
 	}
 
 	if len(candidates) == 0 {
 		return
 	}
 
+	// Drop jobs whose day was already settled, met or missed.
+	keys := make([]string, len(candidates))
+	for i, c := range candidates {
+		keys[i] = c.alertKey
+	}
+	alerted, err := w.alertedKeys(ctx, "key IN ?", keys)
+	if err != nil {
+		log.Error("notification watcher: failed to load alert state", "error", err)
+		return
+	}
+	var jobIDs []uuid.UUID
+	pending := candidates[:0]
+	for _, c := range candidates {
+		if _, done := alerted[c.alertKey]; done {
+			continue
+		}
+		pending = append(pending, c)
+		jobIDs = append(jobIDs, c.job.ID)
+	}
+	candidates = pending
+	if len(candidates) == 0 {
+		return
+	}
+
 	// Single batch query: for each candidate job, find the latest
 	// successful run completion time since the earliest window.
 	type jobLatest struct {
@@ -226,22 +297,11 @@ func (w *Watcher) scanCompletedBySLA(ctx context.Context, now time.Time) {
 	// its specific [windowStart, deadline] range.
 	for _, c := range candidates {
 		if runMetSLA(latestByJob, c.job.ID, c.windowStart, c.deadline) {
-			w.alertedJobs[c.alertKey] = struct{}{}
+			w.recordMet(ctx, c.job.ID, c.alertKey, now)
 			continue
 		}
 
-		w.emitCompletedBySLAEvent(ctx, c.job, c.deadline, now)
-		w.alertedJobs[c.alertKey] = struct{}{}
-	}
-
-	// Prune old alert keys (keep only today and yesterday).
-	today := now.Format("2006-01-02")
-	yesterday := now.Add(-24 * time.Hour).Format("2006-01-02")
-	for key := range w.alertedJobs {
-		parts := strings.SplitN(key, "|", 2)
-		if len(parts) == 2 && parts[1] != today && parts[1] != yesterday {
-			delete(w.alertedJobs, key)
-		}
+		w.emitCompletedBySLAEvent(ctx, c.job, c.deadline, c.alertKey, now)
 	}
 }
 
@@ -255,7 +315,9 @@ func (w *Watcher) emitTimeoutEvent(ctx context.Context, r models.JobRun, now tim
 		Payload:   payload,
 	}
 
-	w.persistAndPublish(ctx, &evt)
+	if !w.emitOnce(ctx, runAlert(models.SLAAlertRunTimedOut, r, now), &evt) {
+		return
+	}
 	log.Warn("notification watcher: run timed out",
 		"run_id", r.ID,
 		"job_alias", r.Job.Alias,
@@ -281,14 +343,44 @@ func (w *Watcher) emitSLAEvent(ctx context.Context, jobID uuid.UUID, r *models.J
 		Timestamp: now,
 		Payload:   payload,
 	}
-	if r != nil {
-		evt.RunID = r.ID
+	if r == nil {
+		w.persistAndPublish(ctx, &evt)
+		return
 	}
+	evt.RunID = r.ID
+	w.emitOnce(ctx, runAlert(models.SLAAlertDurationMissed, *r, now), &evt)
+}
 
-	w.persistAndPublish(ctx, &evt)
+func (w *Watcher) emitAtRiskEvent(ctx context.Context, r models.JobRun, est *sla.Estimate, deadline, now time.Time) {
+	msg := fmt.Sprintf("SLA at risk: predicted completion %s UTC is %s past the %s UTC deadline",
+		est.ETA.UTC().Format("15:04"), est.ETA.Sub(deadline).Truncate(time.Second), deadline.UTC().Format("15:04"))
+	p := runEventPayload(r, msg)
+	p["eta"] = est.ETA.UTC()
+	p["deadline"] = deadline.UTC()
+	p["remaining_seconds"] = est.RemainingSeconds
+	p["critical_path"] = est.CriticalPath
+	p["percentile"] = est.Percentile
+	payload, _ := json.Marshal(p)
+
+	evt := event.Event{
+		Type:      event.TypeSLAAtRisk,
+		JobID:     r.JobID,
+		RunID:     r.ID,
+		Timestamp: now,
+		Payload:   payload,
+	}
+	if !w.emitOnce(ctx, runAlert(models.SLAAlertAtRisk, r, now), &evt) {
+		return
+	}
+	log.Warn("notification watcher: run at risk of missing SLA",
+		"run_id", r.ID,
+		"job_alias", r.Job.Alias,
+		"eta", est.ETA.UTC(),
+		"deadline", deadline.UTC(),
+	)
 }
 
-func (w *Watcher) emitCompletedBySLAEvent(ctx context.Context, job models.Job, deadline, now time.Time) {
+func (w *Watcher) emitCompletedBySLAEvent(ctx context.Context, job models.Job, deadline time.Time, alertKey string, now time.Time) {
 	msg := fmt.Sprintf("SLA missed: job %q did not complete by %s UTC", job.Alias, deadline.Format("15:04"))
 	data, _ := json.Marshal(map[string]interface{}{
 		"job_id":     job.ID,
@@ -305,7 +397,10 @@ func (w *Watcher) emitCompletedBySLAEvent(ctx context.Context, job models.Job, d
 		Payload:   data,
 	}
 
-	w.persistAndPublish(ctx, &evt)
+	alert := models.SLAAlert{Key: alertKey, Kind: models.SLAAlertCompletedByMissed, JobID: job.ID, CreatedAt: now}
+	if !w.emitOnce(ctx, alert, &evt) {
+		return
+	}
 	log.Warn("notification watcher: completedBy SLA missed",
 		"job_alias", job.Alias,
 		"deadline", deadline.Format("15:04"),
@@ -327,7 +422,136 @@ func (w *Watcher) persistAndPublish(ctx context.Context, evt *event.Event) {
 	event.PublishAndMarkBusDispatched(ctx, w.bus, w.store, *evt)
 }
 
+// emitOnce persists evt together with its dedup row and publishes it. It
+// reports whether the alert was raised: false when the row already existed or
+// the write failed, in which case nothing is published.
+func (w *Watcher) emitOnce(ctx context.Context, alert models.SLAAlert, evt *event.Event) bool {
+	err := w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		claimed, err := claimAlert(tx, alert)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errAlreadyAlerted
+		}
+		if w.store == nil {
+			return nil
+		}
+		return w.store.AppendTx(tx, evt)
+	})
+	if errors.Is(err, errAlreadyAlerted) {
+		return false
+	}
+	if err != nil {
+		log.Error("notification watcher: failed to persist event",
+			"event_type", string(evt.Type),
+			"error", err,
+		)
+		return false
+	}
+	event.PublishAndMarkBusDispatched(ctx, w.bus, w.store, *evt)
+	return true
+}
+
+// recordMet settles a job's completedBy day without alerting, so later scans
+// skip it.
+func (w *Watcher) recordMet(ctx context.Context, jobID uuid.UUID, alertKey string, now time.Time) {
+	alert := models.SLAAlert{Key: alertKey, Kind: models.SLAAlertCompletedByMet, JobID: jobID, CreatedAt: now}
+	if _, err := claimAlert(w.db.WithContext(ctx), alert); err != nil {
+		log.Error("notification watcher: failed to record met SLA", "job_id", jobID, "error", err)
+	}
+}
+
+// alertedKeys returns the sla_alerts keys matching the given condition.
+func (w *Watcher) alertedKeys(ctx context.Context, query string, args ...interface{}) (map[string]struct{}, error) {
+	var keys []string
+	if err := w.db.WithContext(ctx).
+		Model(&models.SLAAlert{}).
+		Where(query, args...).
+		Pluck("key", &keys).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		out[key] = struct{}{}
+	}
+	return out, nil
+}
+
+// pruneAlerts deletes dedup rows past alertRetention, keeping those of runs
+// that are still running so a long run never alerts twice.
+func (w *Watcher) pruneAlerts(ctx context.Context, now time.Time) {
+	running := w.db.Model(&models.JobRun{}).Select("id").Where("status = ?", "running")
+	if err := w.db.WithContext(ctx).
+		Where("created_at < ? AND (run_id IS NULL OR run_id NOT IN (?))", now.Add(-alertRetention), running).
+		Delete(&models.SLAAlert{}).Error; err != nil {
+		log.Error("notification watcher: failed to prune alert state", "error", err)
+	}
+}
+
+// claimAlert inserts the dedup row and reports whether this call created it.
+func claimAlert(tx *gorm.DB, alert models.SLAAlert) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func runAlert(kind models.SLAAlertKind, r models.JobRun, now time.Time) models.SLAAlert {
+	runID := r.ID
+	return models.SLAAlert{
+		Key:       runAlertKey(kind, r.ID),
+		Kind:      kind,
+		JobID:     r.JobID,
+		RunID:     &runID,
+		CreatedAt: now,
+	}
+}
+
+func runAlertKey(kind models.SLAAlertKind, runID uuid.UUID) string {
+	return fmt.Sprintf("%s|%s", kind, runID)
+}
+
+// completedByAlertKey dedups a job's completedBy check per calendar day; the
+// met and missed outcomes share it.
+func completedByAlertKey(jobID uuid.UUID, deadline time.Time) string {
+	return fmt.Sprintf("completed_by|%s|%s", jobID, deadline.Format("2006-01-02"))
+}
+
+// upcomingDeadline returns the earliest SLA deadline for a run that is still
+// ahead of now: the duration limit measured from the run's start, or today's
+// completedBy time.
+func upcomingDeadline(cfg *slaConfig, startedAt, now time.Time) (time.Time, bool) {
+	var (
+		deadline time.Time
+		found    bool
+	)
+	consider := func(candidate time.Time) {
+		if candidate.After(now) && (!found || candidate.Before(deadline)) {
+			deadline, found = candidate, true
+		}
+	}
+	if cfg.Duration > 0 {
+		consider(startedAt.Add(cfg.Duration))
+	}
+	if cfg.CompletedBy != "" {
+		if completedBy, err := resolveCompletedBy(cfg.CompletedBy, now); err == nil {
+			consider(completedBy)
+		}
+	}
+	return deadline, found
+}
+
 func buildRunEventPayload(r models.JobRun, errorMsg string) json.RawMessage {
+	data, err := json.Marshal(runEventPayload(r, errorMsg))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func runEventPayload(r models.JobRun, errorMsg string) map[string]interface{} {
 	p := map[string]interface{}{
 		"id":           r.ID,
 		"job_id":       r.JobID,
@@ -347,11 +571,7 @@ func buildRunEventPayload(r models.JobRun, errorMsg string) json.RawMessage {
 			p["params"] = params
 		}
 	}
-	data, err := json.Marshal(p)
-	if err != nil {
-		return nil
-	}
-	return data
+	return p
 }
 
 // runMetSLA checks whether a job's latest successful run completed within
@@ -2,14 +2,17 @@ package notification
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/sla"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -76,3 +79,133 @@ func seedWatcherRun(t *testing.T, db *gorm.DB, alias string, now time.Time, quar
 	}).Error)
 	return runID
 }
+
+func TestWatcherDedupSurvivesRestart(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	bus := event.New()
+	store := event.NewStore(db)
+	now := time.Now().UTC()
+	runID := seedWatcherRun(t, db, "watcher-dedup", now, false)
+
+	NewWatcher(db, bus, store, time.Second).scanRunningRuns(context.Background(), now)
+	// A fresh watcher stands in for a restarted process or a new leader.
+	NewWatcher(db, bus, store, time.Second).scanRunningRuns(context.Background(), now.Add(time.Minute))
+
+	var events int64
+	require.NoError(t, db.Model(&models.ExecutionEvent{}).
+		Where("run_id = ? AND type = ?", runID, string(event.TypeRunTimedOut)).
+		Count(&events).Error)
+	require.EqualValues(t, 1, events)
+
+	var alert models.SLAAlert
+	require.NoError(t, db.First(&alert, "key = ?", runAlertKey(models.SLAAlertRunTimedOut, runID)).Error)
+	require.Equal(t, models.SLAAlertRunTimedOut, alert.Kind)
+}
+
+func TestWatcherPrunesAlertsAtMostOncePerInterval(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	now := time.Now().UTC()
+	seedExpired := func(key string) {
+		t.Helper()
+		require.NoError(t, db.Create(&models.SLAAlert{
+			Key:       key,
+			Kind:      models.SLAAlertCompletedByMissed,
+			JobID:     uuid.New(),
+			CreatedAt: now.Add(-alertRetention - time.Hour),
+		}).Error)
+	}
+	remaining := func() int64 {
+		t.Helper()
+		var count int64
+		require.NoError(t, db.Model(&models.SLAAlert{}).Count(&count).Error)
+		return count
+	}
+
+	w := NewWatcher(db, event.New(), event.NewStore(db), time.Second)
+	seedExpired("first")
+	w.scan(context.Background())
+	require.Zero(t, remaining())
+
+	seedExpired("second")
+	w.scan(context.Background())
+	require.EqualValues(t, 1, remaining())
+
+	w.lastPrune = w.lastPrune.Add(-alertPruneInterval)
+	w.scan(context.Background())
+	require.Zero(t, remaining())
+}
+
+func TestWatcherEmitsSLAAtRisk(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	bus := event.New()
+	store := event.NewStore(db)
+	watcher := NewWatcher(db, bus, store, time.Second)
+	now := time.Now().UTC()
+
+	// Both runs have a 30m duration SLA and one pending task; only the slow
+	// task's history predicts an overrun.
+	slowRun := seedAtRiskRun(t, db, "watcher-at-risk-slow", 45*time.Minute, now)
+	fastRun := seedAtRiskRun(t, db, "watcher-at-risk-fast", 5*time.Minute, now)
+
+	watcher.scanRunningRuns(context.Background(), now)
+	watcher.scanRunningRuns(context.Background(), now.Add(time.Minute))
+
+	var atRisk []models.ExecutionEvent
+	require.NoError(t, db.Where("type = ?", string(event.TypeSLAAtRisk)).Find(&atRisk).Error)
+	require.Len(t, atRisk, 1)
+	require.NotNil(t, atRisk[0].RunID)
+	require.Equal(t, slowRun, *atRisk[0].RunID)
+	require.NotEqual(t, fastRun, *atRisk[0].RunID)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(atRisk[0].Payload, &payload))
+	require.Contains(t, payload, "eta")
+	require.Contains(t, payload, "deadline")
+	require.EqualValues(t, (45 * time.Minute).Seconds(), payload["remaining_seconds"])
+}
+
+func seedAtRiskRun(t *testing.T, db *gorm.DB, alias string, taskDuration time.Duration, now time.Time) uuid.UUID {
+	t.Helper()
+	runID := seedWatcherRun(t, db, alias, now, false)
+	var run models.JobRun
+	require.NoError(t, db.First(&run, "id = ?", runID).Error)
+	require.NoError(t, db.Model(&models.Job{}).Where("id = ?", run.JobID).Updates(map[string]any{
+		"run_timeout": 0,
+		"sla":         datatypes.JSON(`{"duration":1800000000000}`),
+	}).Error)
+
+	taskID := uuid.New()
+	for i := range sla.MinSamples {
+		completed := now.Add(-time.Duration(i+1) * time.Hour)
+		started := completed.Add(-taskDuration)
+		seedWatcherTaskRun(t, db, uuid.New(), taskID, "succeeded", &started, &completed)
+	}
+	seedWatcherTaskRun(t, db, runID, taskID, "pending", nil, nil)
+	return runID
+}
+
+func seedWatcherTaskRun(t *testing.T, db *gorm.DB, runID, taskID uuid.UUID, status string, started, completed *time.Time) {
+	t.Helper()
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.TaskRun{
+		ID:          uuid.New(),
+		JobRunID:    runID,
+		TaskID:      taskID,
+		AtomID:      uuid.New(),
+		Engine:      models.AtomEngineDocker,
+		Image:       "alpine:3.23",
+		Status:      status,
+		StartedAt:   started,
+		CompletedAt: completed,
+		Attempt:     1,
+		MaxAttempts: 1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}).Error)
+}
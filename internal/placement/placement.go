@@ -0,0 +1,182 @@
+// Package placement decides which worker nodes may run a task, from the
+// step's exact-match nodeSelector and its expression-based nodeAffinity.
+//
+// Worker claims evaluate the same rules in SQL (see worker.Claimer); this
+// package serves the run-owner dispatch loop and the job lint, which choose
+// among other nodes and so need every node's labels. Workers advertise their
+// CAESIUM_NODE_LABELS in the worker_nodes catalog table through Registry.
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/caesium-cloud/caesium/pkg/jsonmap"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultNodeTTL is how long a worker_nodes row counts as live after the
+// node last refreshed it.
+const DefaultNodeTTL = 5 * time.Minute
+
+// ErrAddressRequired is returned when registering a node without an address.
+var ErrAddressRequired = errors.New("node address is required")
+
+// Spec is a task's placement constraints.
+type Spec struct {
+	Selector map[string]string
+	Affinity *jobdef.NodeAffinity
+}
+
+// SpecFor returns the placement constraints snapshotted on a task run. A
+// malformed nodeAffinity document is ignored, matching the claim predicate,
+// which treats it as absent.
+func SpecFor(task *models.TaskRun) Spec {
+	if task == nil {
+		return Spec{}
+	}
+	return Spec{
+		Selector: jsonmap.ToStringMap(task.NodeSelector),
+		Affinity: decodeAffinity(task.NodeAffinity),
+	}
+}
+
+// Constrained reports whether the spec restricts or ranks nodes at all.
+// Unconstrained tasks keep the dispatcher's plain round-robin.
+func (s Spec) Constrained() bool {
+	return len(s.Selector) > 0 || !s.Affinity.IsZero()
+}
+
+// Eligible reports whether a node with labels may run the task. A node that
+// never registered is treated as having no labels.
+func (s Spec) Eligible(labels map[string]string) bool {
+	return jobdef.MatchesNodeSelector(s.Selector, labels) && s.Affinity.Matches(labels)
+}
+
+// Score ranks an eligible node by the preferred terms it satisfies.
+func (s Spec) Score(labels map[string]string) int {
+	return s.Affinity.Score(labels)
+}
+
+// Spread reports whether the task prefers a node not already running
+// another task of the same run.
+func (s Spec) Spread() bool {
+	return s.Affinity != nil && s.Affinity.Spread
+}
+
+// Registry reads and writes worker_nodes in the catalog database.
+type Registry struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewRegistry(db *gorm.DB) *Registry {
+	if db == nil {
+		panic("placement registry requires a database")
+	}
+	return &Registry{db: db, ttl: DefaultNodeTTL, now: time.Now}
+}
+
+// Register records address's labels and marks it live.
+func (r *Registry) Register(ctx context.Context, address string, labels map[string]string) error {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return ErrAddressRequired
+	}
+	row := models.WorkerNode{
+		Address: address,
+		Labels:  jsonmap.FromStringMap(labels),
+		SeenAt:  r.now().UTC(),
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"labels", "seen_at"}),
+	}).Create(&row).Error
+}
+
+// Nodes returns the labels of every node that refreshed its row within the
+// registry's TTL, keyed by node address.
+func (r *Registry) Nodes(ctx context.Context) (map[string]map[string]string, error) {
+	var rows []models.WorkerNode
+	cutoff := r.now().UTC().Add(-r.ttl)
+	if err := r.db.WithContext(ctx).Where("seen_at >= ?", cutoff).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]map[string]string, len(rows))
+	for _, row := range rows {
+		labels := jsonmap.ToStringMap(row.Labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		nodes[row.Address] = labels
+	}
+	return nodes, nil
+}
+
+// TaskSpec returns the placement constraints of a catalog task. The
+// in-memory owner path dispatches from task IDs alone and reads them here.
+func (r *Registry) TaskSpec(ctx context.Context, taskID uuid.UUID) (Spec, error) {
+	var task models.Task
+	err := r.db.WithContext(ctx).
+		Select("id", "node_selector", "node_affinity").
+		First(&task, "id = ?", taskID).Error
+	if err != nil {
+		return Spec{}, err
+	}
+	return Spec{
+		Selector: jsonmap.ToStringMap(task.NodeSelector),
+		Affinity: decodeAffinity(task.NodeAffinity),
+	}, nil
+}
+
+// AnyEligible reports whether at least one of nodes satisfies spec.
+func AnyEligible(spec Spec, nodes map[string]map[string]string) bool {
+	for _, labels := range nodes {
+		if spec.Eligible(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeAffinity(raw datatypes.JSON) *jobdef.NodeAffinity {
+	if len(raw) == 0 {
+		return nil
+	}
+	var affinity jobdef.NodeAffinity
+	if err := json.Unmarshal(raw, &affinity); err != nil {
+		return nil
+	}
+	return &affinity
+}
+
+// Warnings returns a lint warning for every step of defs that no live
+// registered node can run. It returns none while no node has registered,
+// e.g. on a server without distributed workers, since labels are unknown.
+func (r *Registry) Warnings(ctx context.Context, defs []jobdef.Definition) ([]string, error) {
+	nodes, err := r.Nodes(ctx)
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+	var warnings []string
+	for _, def := range defs {
+		for _, step := range def.Steps {
+			spec := Spec{Selector: step.NodeSelector, Affinity: step.NodeAffinity}
+			if !spec.Constrained() || AnyEligible(spec, nodes) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("job %q step %q: no registered worker node satisfies its nodeSelector and nodeAffinity; its tasks will stay pending", def.Metadata.Alias, step.Name))
+		}
+	}
+	return warnings, nil
+}
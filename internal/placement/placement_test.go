@@ -0,0 +1,99 @@
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	require.NoError(t, db.AutoMigrate(&models.WorkerNode{}))
+	return NewRegistry(db)
+}
+
+func TestRegistryNodesSkipsStaleRows(t *testing.T) {
+	registry := newTestRegistry(t)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, registry.Register(ctx, "gpu:9001", map[string]string{"gpu": "a100"}))
+	require.NoError(t, registry.Register(ctx, "bare:9001", nil))
+	now = now.Add(DefaultNodeTTL - time.Second)
+	require.NoError(t, registry.Register(ctx, "arm:9001", map[string]string{"arch": "arm64"}))
+	require.NoError(t, registry.Register(ctx, "gpu:9001", map[string]string{"gpu": "h100"}))
+	require.ErrorIs(t, registry.Register(ctx, " ", nil), ErrAddressRequired)
+
+	now = now.Add(2 * time.Second)
+	nodes, err := registry.Nodes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]string{
+		"gpu:9001": {"gpu": "h100"},
+		"arm:9001": {"arch": "arm64"},
+	}, nodes, "bare:9001 stopped refreshing and is no longer live")
+}
+
+func TestSpecForDecodesTaskRun(t *testing.T) {
+	spec := SpecFor(&models.TaskRun{
+		NodeSelector: datatypes.JSONMap{"zone": "a"},
+		NodeAffinity: datatypes.JSON(`{"required":[{"key":"arch","operator":"In","values":["amd64","arm64"]}],"preferred":[{"weight":30,"match":[{"key":"gpu","operator":"Exists"}]}],"spread":true}`),
+	})
+	require.True(t, spec.Constrained())
+	require.True(t, spec.Spread())
+	require.True(t, spec.Eligible(map[string]string{"zone": "a", "arch": "arm64"}))
+	require.False(t, spec.Eligible(map[string]string{"zone": "b", "arch": "arm64"}), "nodeSelector still applies")
+	require.False(t, spec.Eligible(map[string]string{"zone": "a", "arch": "s390x"}))
+	require.Equal(t, 30, spec.Score(map[string]string{"gpu": "a100"}))
+
+	require.False(t, SpecFor(&models.TaskRun{}).Constrained())
+	require.False(t, SpecFor(&models.TaskRun{NodeAffinity: datatypes.JSON(`not json`)}).Constrained())
+}
+
+func TestRegistryTaskSpecAndWarnings(t *testing.T) {
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+	require.NoError(t, registry.db.AutoMigrate(&models.Task{}))
+
+	gpuOnly := &jobdef.NodeAffinity{Required: []jobdef.NodeSelectorRequirement{{Key: "gpu", Operator: jobdef.NodeOperatorExists}}}
+	defs := []jobdef.Definition{{
+		Metadata: jobdef.Metadata{Alias: "train"},
+		Steps: []jobdef.Step{
+			{Name: "prep"},
+			{Name: "fit", NodeAffinity: gpuOnly},
+			{Name: "edge", NodeSelector: map[string]string{"zone": "edge"}},
+			{Name: "prefer", NodeAffinity: &jobdef.NodeAffinity{Preferred: []jobdef.PreferredNodeTerm{{Weight: 10, Match: gpuOnly.Required}}}},
+		},
+	}}
+
+	warnings, err := registry.Warnings(ctx, defs)
+	require.NoError(t, err)
+	require.Empty(t, warnings, "no registered node means labels are unknown")
+
+	require.NoError(t, registry.Register(ctx, "cpu:9001", map[string]string{"zone": "edge"}))
+	warnings, err = registry.Warnings(ctx, defs)
+	require.NoError(t, err)
+	require.Equal(t, []string{`job "train" step "fit": no registered worker node satisfies its nodeSelector and nodeAffinity; its tasks will stay pending`}, warnings)
+
+	taskID := uuid.New()
+	require.NoError(t, registry.db.Create(&models.Task{
+		ID:           taskID,
+		JobID:        uuid.New(),
+		AtomID:       uuid.New(),
+		Name:         "fit",
+		NodeAffinity: datatypes.JSON(`{"required":[{"key":"gpu","operator":"Exists"}]}`),
+	}).Error)
+	spec, err := registry.TaskSpec(ctx, taskID)
+	require.NoError(t, err)
+	require.Equal(t, gpuOnly, spec.Affinity)
+	require.Empty(t, spec.Selector)
+}
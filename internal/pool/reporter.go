@@ -0,0 +1,86 @@
+// Package pool publishes cluster-wide task pool occupancy.
+package pool
+
+import (
+	"context"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"gorm.io/gorm"
+)
+
+// LeaderCheck reports whether this node should publish pool gauges. It
+// matches dqlite.IsLocalLeader so only one node in a cluster reports them.
+type LeaderCheck func(context.Context) (bool, error)
+
+// Reporter is the leader-gated pool metrics publisher. Pool occupancy is
+// cluster-wide state, so a single node exports it to avoid every scrape
+// target reporting the same slots.
+type Reporter struct {
+	db          *gorm.DB
+	leaderCheck LeaderCheck
+	interval    time.Duration
+}
+
+// NewReporter constructs the reporter. A zero interval defaults to 15s.
+func NewReporter(db *gorm.DB, leaderCheck LeaderCheck, interval time.Duration) *Reporter {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	return &Reporter{
+		db:          db,
+		leaderCheck: leaderCheck,
+		interval:    interval,
+	}
+}
+
+// Run drives the report loop until ctx is cancelled.
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.ReportOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Error("pool metrics report failed", "error", err)
+			}
+		}
+	}
+}
+
+// ReportOnce refreshes the pool gauges. Nodes that are not the leader clear
+// theirs so a former leader stops exporting stale occupancy.
+func (r *Reporter) ReportOnce(ctx context.Context) error {
+	if r.leaderCheck != nil {
+		leader, err := r.leaderCheck(ctx)
+		if err != nil {
+			return err
+		}
+		if !leader {
+			resetGauges()
+			return nil
+		}
+	}
+
+	usages, err := run.PoolUsages(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	resetGauges()
+	for _, usage := range usages {
+		metrics.PoolSlots.WithLabelValues(usage.Name).Set(float64(usage.Slots))
+		metrics.PoolOccupiedSlots.WithLabelValues(usage.Name).Set(float64(usage.OccupiedSlots))
+		metrics.PoolQueuedTasks.WithLabelValues(usage.Name).Set(float64(usage.QueuedTasks))
+	}
+	return nil
+}
+
+func resetGauges() {
+	metrics.PoolSlots.Reset()
+	metrics.PoolOccupiedSlots.Reset()
+	metrics.PoolQueuedTasks.Reset()
+}
@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	metrictestutil "github.com/caesium-cloud/caesium/internal/metrics/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporterPublishesOnLeaderOnly(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := run.NewStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.Pool{Name: "warehouse", Slots: 4, CreatedAt: now, UpdatedAt: now}).Error)
+
+	jobID := uuid.New()
+	runRecord, err := store.Start(jobID, nil)
+	require.NoError(t, err)
+	atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["echo","load"]`}
+	require.NoError(t, db.Create(atom).Error)
+	task := &models.Task{ID: uuid.New(), JobID: jobID, AtomID: atom.ID, Name: "load", PoolName: "warehouse", PoolSlots: 3}
+	require.NoError(t, db.Create(task).Error)
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+
+	leader := true
+	reporter := NewReporter(db, func(context.Context) (bool, error) { return leader, nil }, 0)
+
+	require.NoError(t, reporter.ReportOnce(ctx))
+	require.Equal(t, float64(4), metrictestutil.GaugeValue(t, metrics.PoolSlots.WithLabelValues("warehouse")))
+	require.Equal(t, float64(0), metrictestutil.GaugeValue(t, metrics.PoolOccupiedSlots.WithLabelValues("warehouse")))
+	require.Equal(t, float64(1), metrictestutil.GaugeValue(t, metrics.PoolQueuedTasks.WithLabelValues("warehouse")))
+
+	admitted, err := store.AdmitPoolTask(ctx, runRecord.ID, task.ID)
+	require.NoError(t, err)
+	require.True(t, admitted)
+	require.NoError(t, reporter.ReportOnce(ctx))
+	require.Equal(t, float64(3), metrictestutil.GaugeValue(t, metrics.PoolOccupiedSlots.WithLabelValues("warehouse")))
+	require.Equal(t, float64(0), metrictestutil.GaugeValue(t, metrics.PoolQueuedTasks.WithLabelValues("warehouse")))
+
+	leader = false
+	require.NoError(t, reporter.ReportOnce(ctx))
+	require.False(t, metrics.PoolSlots.DeleteLabelValues("warehouse"), "followers must not export pool gauges")
+}
@@ -0,0 +1,279 @@
+package receipt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Provenance export renders a run as an in-toto Statement carrying a SLSA v1
+// provenance predicate, wrapped in a DSSE envelope signed by the same keyring
+// as receipts. The subject is the run's receipt (addressed by ReceiptDigest),
+// and the resolved dependencies are exactly what the receipt attests: each
+// task's image digest, the manifest content hash, and the git commit. Tools
+// that consume SLSA provenance (policy engines, cosign/in-toto verifiers) can
+// then check a run without understanding the receipt format.
+
+const (
+	// StatementType is the in-toto Statement v1 type URI.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// ProvenancePredicateType is the SLSA provenance v1 predicate type URI.
+	ProvenancePredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType identifies how a caesium run is interpreted as a SLSA build.
+	BuildType = "https://github.com/caesium-cloud/caesium/receipt/v1"
+	// EnvelopePayloadType is the DSSE payload type for in-toto statements.
+	EnvelopePayloadType = "application/vnd.in-toto+json"
+)
+
+// Statement is an in-toto v1 statement over a run's receipt.
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     Provenance           `json:"predicate"`
+}
+
+// ResourceDescriptor is the in-toto v1 ResourceDescriptor, trimmed to the
+// fields provenance export fills.
+type ResourceDescriptor struct {
+	Name        string            `json:"name,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]any    `json:"annotations,omitempty"`
+}
+
+// Provenance is the SLSA v1 provenance predicate.
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs a run executed with.
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	InternalParameters   map[string]any       `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies"`
+}
+
+// RunDetails identifies the cluster that executed a run and when.
+type RunDetails struct {
+	Builder  Builder       `json:"builder"`
+	Metadata BuildMetadata `json:"metadata"`
+}
+
+// Builder names the entity trusted to have produced the provenance.
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// BuildMetadata is the SLSA run metadata for one run.
+type BuildMetadata struct {
+	InvocationID string     `json:"invocationId"`
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+// BuildProvenance re-derives the receipt for a run and renders it as an
+// in-toto statement with a SLSA provenance predicate. builderID is the
+// cluster's SLSA builder id (CAESIUM_RECEIPT_BUILDER_ID).
+//
+// Tasks that ran on an unpinned tag are still listed as dependencies, by tag
+// and without a digest, and the receipt's degraded summary is carried in the
+// internal parameters: the export is as honest as the receipt it renders.
+func BuildProvenance(ctx context.Context, db *gorm.DB, runID uuid.UUID, builderID string) (*Statement, error) {
+	r, err := Build(ctx, db, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := db.WithContext(ctx)
+	var run models.JobRun
+	if err := conn.Where("id = ?", runID).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRunNotFound
+		}
+		return nil, fmt.Errorf("receipt: load run: %w", err)
+	}
+	// Unscoped for the same reason as Build: provenance describes the past.
+	var job models.Job
+	if err := conn.Unscoped().Where("id = ?", run.JobID).First(&job).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("receipt: load job: %w", err)
+	}
+
+	external := map[string]any{
+		"job_id": r.JobID.String(),
+	}
+	if r.JobAlias != "" {
+		external["job_alias"] = r.JobAlias
+	}
+	if len(run.Params) > 0 && string(run.Params) != "null" {
+		external["params"] = json.RawMessage(run.Params)
+	}
+	if job.ProvenanceRepo != "" {
+		external["source"] = map[string]string{
+			"repo": job.ProvenanceRepo,
+			"ref":  job.ProvenanceRef,
+			"path": job.ProvenancePath,
+		}
+	}
+
+	internal := map[string]any{
+		"receipt_version": r.ReceiptVersion,
+		"degraded":        r.Degraded,
+	}
+	if len(r.DegradedTasks) > 0 {
+		internal["degraded_tasks"] = r.DegradedTasks
+	}
+
+	metadata := BuildMetadata{InvocationID: r.RunID.String()}
+	if !run.StartedAt.IsZero() {
+		started := run.StartedAt.UTC()
+		metadata.StartedOn = &started
+	}
+	if run.CompletedAt != nil {
+		finished := run.CompletedAt.UTC()
+		metadata.FinishedOn = &finished
+	}
+
+	return &Statement{
+		Type: StatementType,
+		Subject: []ResourceDescriptor{{
+			Name:   "receipt:" + r.RunID.String(),
+			Digest: map[string]string{"sha256": r.ReceiptDigest},
+		}},
+		PredicateType: ProvenancePredicateType,
+		Predicate: Provenance{
+			BuildDefinition: BuildDefinition{
+				BuildType:            BuildType,
+				ExternalParameters:   external,
+				InternalParameters:   internal,
+				ResolvedDependencies: resolvedDependencies(r, job),
+			},
+			RunDetails: RunDetails{
+				Builder:  Builder{ID: builderID},
+				Metadata: metadata,
+			},
+		},
+	}, nil
+}
+
+// resolvedDependencies lists every input the receipt digest folds in: one
+// entry per task image, then the manifest, then the git commit.
+func resolvedDependencies(r *Receipt, job models.Job) []ResourceDescriptor {
+	deps := make([]ResourceDescriptor, 0, len(r.Tasks)+2)
+	for _, t := range r.Tasks {
+		dep := ResourceDescriptor{
+			Name: t.TaskName,
+			URI:  "docker://" + t.Image,
+			Annotations: map[string]any{
+				"identity_hash": t.IdentityHash,
+			},
+		}
+		if algo, value, ok := strings.Cut(t.ResolvedImageDigest, ":"); ok {
+			dep.Digest = map[string]string{algo: value}
+		}
+		if t.Degraded {
+			dep.Annotations["degraded_reason"] = t.DegradedReason
+		}
+		deps = append(deps, dep)
+	}
+	if r.ManifestContentHash != "" {
+		deps = append(deps, ResourceDescriptor{
+			Name:   "manifest",
+			Digest: map[string]string{"sha256": r.ManifestContentHash},
+		})
+	}
+	if r.GitCommit != "" {
+		dep := ResourceDescriptor{
+			Name:   "source",
+			Digest: map[string]string{"gitCommit": r.GitCommit},
+		}
+		if job.ProvenanceRepo != "" {
+			dep.URI = "git+" + job.ProvenanceRepo
+			if job.ProvenanceRef != "" {
+				dep.URI += "@" + job.ProvenanceRef
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// Envelope is a DSSE envelope carrying a base64-encoded in-toto statement.
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is one DSSE signature.
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Envelope wraps statement in a DSSE envelope signed with the current key.
+// A nil keyring yields an envelope with no signatures.
+func (k *Keyring) Envelope(statement *Statement) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("receipt: marshal statement: %w", err)
+	}
+	env := &Envelope{
+		PayloadType: EnvelopePayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []EnvelopeSignature{},
+	}
+	if k != nil {
+		sig := ed25519.Sign(k.private[k.currentKeyID], pae(EnvelopePayloadType, payload))
+		env.Signatures = append(env.Signatures, EnvelopeSignature{
+			KeyID: k.currentKeyID,
+			Sig:   base64.StdEncoding.EncodeToString(sig),
+		})
+	}
+	return env, nil
+}
+
+// CheckEnvelope verifies a DSSE envelope's signatures against keys, with the
+// same statuses CheckSignatures reports for receipts.
+func CheckEnvelope(env *Envelope, keys PublicKeySet) SignatureCheck {
+	if env == nil || len(env.Signatures) == 0 {
+		return SignatureCheck{Status: SignatureUnsigned, Detail: "envelope is not signed; its issuer cannot be verified"}
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return SignatureCheck{Status: SignatureInvalid, Detail: "envelope payload is not valid base64"}
+	}
+	public := keys.decode()
+	message := pae(env.PayloadType, payload)
+	ids := make([]string, 0, len(env.Signatures))
+	for _, sig := range env.Signatures {
+		ids = append(ids, sig.KeyID)
+		key, ok := public[sig.KeyID]
+		if !ok {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(sig.Sig)
+		if err != nil || !ed25519.Verify(key, message, raw) {
+			return SignatureCheck{Status: SignatureInvalid, KeyID: sig.KeyID, Detail: fmt.Sprintf("signature by key %q does not verify", sig.KeyID)}
+		}
+		return SignatureCheck{Status: SignatureValid, KeyID: sig.KeyID, Detail: fmt.Sprintf("signed by key %q", sig.KeyID)}
+	}
+	return SignatureCheck{Status: SignatureUnknownKey, Detail: fmt.Sprintf("signed by key(s) %s, none of which is in the public key set", strings.Join(ids, ", "))}
+}
+
+// pae is the DSSE v1 pre-authentication encoding, the byte sequence DSSE
+// signatures cover.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
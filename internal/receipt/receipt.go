@@ -130,6 +130,11 @@ type Receipt struct {
 	// per-task lines + manifest content hash + git commit. It is the receipt's
 	// content address and the value `verify` re-derives.
 	ReceiptDigest string `json:"receipt_digest"`
+
+	// Signatures are cluster signatures over ReceiptDigest and the run it
+	// attests (see sign.go). They are NOT folded into ReceiptDigest, so signing
+	// or re-signing under a rotated key never changes the content address.
+	Signatures []Signature `json:"signatures,omitempty"`
 }
 
 // canonicalTaskLine renders a single task entry to the exact byte sequence
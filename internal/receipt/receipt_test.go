@@ -2,6 +2,8 @@ package receipt
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -402,6 +404,77 @@ func (s *ReceiptSuite) TestSoftDeletedTaskNamePreserved() {
 	s.Equal("extract", r.Tasks[0].TaskName, "soft-deleted task must still resolve its name")
 }
 
+// TestBuildProvenanceListsDependencies: the SLSA statement's subject is the
+// receipt digest and its resolved dependencies are the image digests, the
+// manifest hash, and the git commit.
+func (s *ReceiptSuite) TestBuildProvenanceListsDependencies() {
+	runID := s.seedRun("etl", "commit-1", "manifest-1", []taskSpec{
+		{name: "extract", image: "alpine:3.23", hash: "hash-extract", digest: "sha256:aaa", pinRequested: true},
+		{name: "load", image: "python:latest", hash: "hash-load"},
+	})
+	var run models.JobRun
+	s.Require().NoError(s.db.Where("id = ?", runID).First(&run).Error)
+	s.Require().NoError(s.db.Model(&models.Job{}).Where("id = ?", run.JobID).Updates(map[string]any{
+		"provenance_repo": "https://github.com/acme/pipelines",
+		"provenance_ref":  "refs/heads/main",
+	}).Error)
+
+	r, err := Build(s.ctx, s.db, runID)
+	s.Require().NoError(err)
+	stmt, err := BuildProvenance(s.ctx, s.db, runID, "https://caesium.example.com")
+	s.Require().NoError(err)
+
+	s.Equal(StatementType, stmt.Type)
+	s.Equal(ProvenancePredicateType, stmt.PredicateType)
+	s.Require().Len(stmt.Subject, 1)
+	s.Equal(r.ReceiptDigest, stmt.Subject[0].Digest["sha256"])
+	s.Equal("https://caesium.example.com", stmt.Predicate.RunDetails.Builder.ID)
+	s.Equal(runID.String(), stmt.Predicate.RunDetails.Metadata.InvocationID)
+	s.Equal(true, stmt.Predicate.BuildDefinition.InternalParameters["degraded"])
+
+	deps := stmt.Predicate.BuildDefinition.ResolvedDependencies
+	s.Require().Len(deps, 4)
+	s.Equal("docker://alpine:3.23", deps[0].URI)
+	s.Equal(map[string]string{"sha256": "aaa"}, deps[0].Digest)
+	s.Empty(deps[1].Digest, "an unpinned image has no digest to attest")
+	s.Contains(deps[1].Annotations, "degraded_reason")
+	s.Equal(map[string]string{"sha256": "manifest-1"}, deps[2].Digest)
+	s.Equal("git+https://github.com/acme/pipelines@refs/heads/main", deps[3].URI)
+	s.Equal(map[string]string{"gitCommit": "commit-1"}, deps[3].Digest)
+
+	_, err = BuildProvenance(s.ctx, s.db, uuid.New(), "")
+	s.ErrorIs(err, ErrRunNotFound)
+}
+
+// TestProvenanceEnvelopeSignature: the DSSE envelope verifies against the
+// keyring's public keys and fails once its payload is altered.
+func (s *ReceiptSuite) TestProvenanceEnvelopeSignature() {
+	runID := s.seedRun("etl", "commit-1", "manifest-1", pinnedSpecs())
+	stmt, err := BuildProvenance(s.ctx, s.db, runID, "builder")
+	s.Require().NoError(err)
+
+	keyring := testKeyring(s.T(), "", "k1")
+	envelope, err := keyring.Envelope(stmt)
+	s.Require().NoError(err)
+	s.Equal(EnvelopePayloadType, envelope.PayloadType)
+	s.Equal(SignatureValid, CheckEnvelope(envelope, keyring.PublicKeys()).Status)
+
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	s.Require().NoError(err)
+	var decoded Statement
+	s.Require().NoError(json.Unmarshal(payload, &decoded))
+	s.Equal(stmt.Subject, decoded.Subject)
+
+	envelope.Payload = base64.StdEncoding.EncodeToString(append(payload, ' '))
+	s.Equal(SignatureInvalid, CheckEnvelope(envelope, keyring.PublicKeys()).Status)
+
+	var unsigned *Keyring
+	envelope, err = unsigned.Envelope(stmt)
+	s.Require().NoError(err)
+	s.Empty(envelope.Signatures)
+	s.Equal(SignatureUnsigned, CheckEnvelope(envelope, keyring.PublicKeys()).Status)
+}
+
 // --- helpers ---
 
 func (s *ReceiptSuite) indexOf(r *Receipt, name string) int {
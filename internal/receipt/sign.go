@@ -0,0 +1,355 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/caesium-cloud/caesium/pkg/log"
+)
+
+// A ReceiptDigest proves nothing about who issued a receipt: anyone holding
+// the run's inputs can recompute it. A signature binds the digest, together
+// with the run and job it attests, to a cluster key. Keys are offline ed25519
+// keys held in a versioned keyring (the same id=value shape as
+// secret.IdentityKeyring): the current key signs, and every key the keyring
+// has ever held stays in the published public key set so receipts signed
+// before a rotation keep verifying.
+
+// SignatureAlgorithm is the only signature algorithm receipts use.
+const SignatureAlgorithm = "ed25519"
+
+// Signature is one cluster signature over a receipt's signing payload.
+type Signature struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	// Signature is the base64-encoded ed25519 signature.
+	Signature string `json:"signature"`
+}
+
+// PublicKey is one entry of a published public key set.
+type PublicKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	// PublicKey is the base64-encoded 32-byte ed25519 public key.
+	PublicKey string `json:"public_key"`
+}
+
+// PublicKeySet is the document a cluster publishes so receipts can be
+// verified offline, without access to the cluster's database or private keys.
+type PublicKeySet struct {
+	Keys []PublicKey `json:"keys"`
+}
+
+// Keyring holds the cluster's receipt signing keys. Only the current key
+// signs; every key, including verify-only keys retired from signing, verifies.
+type Keyring struct {
+	currentKeyID string
+	private      map[string]ed25519.PrivateKey
+	public       map[string]ed25519.PublicKey
+}
+
+var (
+	defaultKeyring     *Keyring
+	defaultKeyringOnce sync.Once
+)
+
+// KeyringFromEnv builds the keyring configured by the CAESIUM_RECEIPT_*_KEYS
+// variables. It returns nil when no signing key is configured.
+func KeyringFromEnv(vars env.Environment) (*Keyring, error) {
+	return ParseKeyring(vars.ReceiptSigningKeyID, vars.ReceiptSigningKeys, vars.ReceiptVerifyKeys)
+}
+
+// DefaultKeyring returns the keyring configured by the environment, built
+// once. The server refuses to start with a misconfigured keyring; any other
+// caller gets it logged and receipts left unsigned.
+func DefaultKeyring() *Keyring {
+	defaultKeyringOnce.Do(func() {
+		keyring, err := KeyringFromEnv(env.Variables())
+		if err != nil {
+			log.Error("receipt signing disabled", "error", err)
+			return
+		}
+		defaultKeyring = keyring
+	})
+	return defaultKeyring
+}
+
+// NewKeyring validates and builds a signing keyring. verifyKeys are public
+// keys of retired signing keys; an id may not appear in both maps.
+func NewKeyring(currentKeyID string, signingKeys map[string]ed25519.PrivateKey, verifyKeys map[string]ed25519.PublicKey) (*Keyring, error) {
+	currentKeyID = strings.TrimSpace(currentKeyID)
+	if len(signingKeys) == 0 {
+		if len(verifyKeys) > 0 {
+			return nil, errors.New("receipt verify keys require a signing key")
+		}
+		return nil, nil
+	}
+	k := &Keyring{
+		private: make(map[string]ed25519.PrivateKey, len(signingKeys)),
+		public:  make(map[string]ed25519.PublicKey, len(signingKeys)+len(verifyKeys)),
+	}
+	for id, key := range signingKeys {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, errors.New("receipt signing key id cannot be empty")
+		}
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("receipt signing key %q is not an ed25519 private key", id)
+		}
+		k.private[id] = append(ed25519.PrivateKey(nil), key...)
+		k.public[id] = k.private[id].Public().(ed25519.PublicKey)
+	}
+	for id, key := range verifyKeys {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, errors.New("receipt verify key id cannot be empty")
+		}
+		if _, ok := k.public[id]; ok {
+			return nil, fmt.Errorf("receipt key %q is configured as both a signing and a verify key", id)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("receipt verify key %q is not an ed25519 public key", id)
+		}
+		k.public[id] = append(ed25519.PublicKey(nil), key...)
+	}
+	if currentKeyID == "" {
+		if len(k.private) != 1 {
+			return nil, errors.New("receipt signing current key id is required when multiple signing keys are configured")
+		}
+		for id := range k.private {
+			currentKeyID = id
+		}
+	}
+	if _, ok := k.private[currentKeyID]; !ok {
+		return nil, fmt.Errorf("receipt signing current key %q is not a signing key in the keyring", currentKeyID)
+	}
+	k.currentKeyID = currentKeyID
+	return k, nil
+}
+
+// ParseKeyring parses comma-separated id=value lists of signing keys and
+// verify-only public keys. Values are base64:<data>, hex:<data>, or bare
+// standard base64. A signing key is a 32-byte seed or a 64-byte private key;
+// a verify key is a 32-byte public key.
+func ParseKeyring(currentKeyID, signingKeys, verifyKeys string) (*Keyring, error) {
+	rawSigning, err := parseKeyList("receipt signing key", signingKeys)
+	if err != nil {
+		return nil, err
+	}
+	private := make(map[string]ed25519.PrivateKey, len(rawSigning))
+	for id, key := range rawSigning {
+		switch len(key) {
+		case ed25519.SeedSize:
+			private[id] = ed25519.NewKeyFromSeed(key)
+		case ed25519.PrivateKeySize:
+			private[id] = ed25519.PrivateKey(key)
+		default:
+			return nil, fmt.Errorf("receipt signing key %q must be a %d-byte ed25519 seed or %d-byte private key, got %d bytes",
+				id, ed25519.SeedSize, ed25519.PrivateKeySize, len(key))
+		}
+	}
+	rawVerify, err := parseKeyList("receipt verify key", verifyKeys)
+	if err != nil {
+		return nil, err
+	}
+	public := make(map[string]ed25519.PublicKey, len(rawVerify))
+	for id, key := range rawVerify {
+		public[id] = ed25519.PublicKey(key)
+	}
+	return NewKeyring(currentKeyID, private, public)
+}
+
+func parseKeyList(kind, raw string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s %q must be id=value", kind, part)
+		}
+		id = strings.TrimSpace(id)
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("%s %q is listed more than once", kind, id)
+		}
+		key, err := decodeKey(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %w", kind, id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+func decodeKey(value string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(value, "hex:"):
+		return hex.DecodeString(strings.TrimPrefix(value, "hex:"))
+	case strings.HasPrefix(value, "base64:"):
+		return base64.StdEncoding.DecodeString(strings.TrimPrefix(value, "base64:"))
+	default:
+		return base64.StdEncoding.DecodeString(value)
+	}
+}
+
+// CurrentKeyID returns the id of the key that signs new receipts.
+func (k *Keyring) CurrentKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.currentKeyID
+}
+
+// PublicKeys returns the keyring's public key set, sorted by key id. A nil
+// keyring yields an empty set.
+func (k *Keyring) PublicKeys() PublicKeySet {
+	set := PublicKeySet{Keys: []PublicKey{}}
+	if k == nil {
+		return set
+	}
+	for id, key := range k.public {
+		set.Keys = append(set.Keys, PublicKey{
+			KeyID:     id,
+			Algorithm: SignatureAlgorithm,
+			PublicKey: base64.StdEncoding.EncodeToString(key),
+		})
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// Sign signs r with the current key, replacing any earlier signature by the
+// same key. r must be finalized. A nil keyring leaves r unsigned.
+func (k *Keyring) Sign(r *Receipt) {
+	if k == nil || r == nil {
+		return
+	}
+	sig := Signature{
+		KeyID:     k.currentKeyID,
+		Algorithm: SignatureAlgorithm,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(k.private[k.currentKeyID], signingPayload(r))),
+	}
+	kept := make([]Signature, 0, len(r.Signatures)+1)
+	for _, existing := range r.Signatures {
+		if existing.KeyID != sig.KeyID {
+			kept = append(kept, existing)
+		}
+	}
+	r.Signatures = append(kept, sig)
+}
+
+// signingPayload is the byte sequence a receipt signature covers. The receipt
+// digest already commits to every task, the manifest, and the git commit; the
+// run and job IDs are added here because the digest deliberately omits them,
+// and a signature must not be transplantable onto another run's receipt.
+func signingPayload(r *Receipt) []byte {
+	return fmt.Appendf(nil, "caesium-receipt-signature\x00v1\nreceipt_version\x00%d\nrun\x00%s\njob\x00%s\ndigest\x00%s\n",
+		r.ReceiptVersion, r.RunID, r.JobID, r.ReceiptDigest)
+}
+
+// SignatureStatus summarizes a receipt's signature check.
+type SignatureStatus string
+
+const (
+	// SignatureValid means a signature by a key in the set verified over a
+	// receipt whose contents match its digest.
+	SignatureValid SignatureStatus = "valid"
+	// SignatureUnsigned means the receipt carries no signature.
+	SignatureUnsigned SignatureStatus = "unsigned"
+	// SignatureUnknownKey means every signature names a key absent from the
+	// set, so none could be checked.
+	SignatureUnknownKey SignatureStatus = "unknown_key"
+	// SignatureInvalid means a signature failed to verify, or the receipt's
+	// contents no longer hash to its signed digest: it was altered after
+	// signing or was never issued by the key's holder.
+	SignatureInvalid SignatureStatus = "invalid"
+)
+
+// SignatureCheck is the outcome of verifying a receipt's signatures.
+type SignatureCheck struct {
+	Status SignatureStatus `json:"status"`
+	// KeyID is the key whose signature verified, when Status is valid.
+	KeyID  string `json:"key_id,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// CheckSignatures verifies r's signatures against keys. It needs nothing but
+// the receipt and the published key set, so it runs offline in `caesium
+// verify`. Besides each signature it re-derives the digest from the receipt's
+// own task entries, so an edited task list cannot ride on a valid signature.
+func CheckSignatures(r *Receipt, keys PublicKeySet) SignatureCheck {
+	if r == nil || len(r.Signatures) == 0 {
+		return SignatureCheck{Status: SignatureUnsigned, Detail: "receipt is not signed; its issuer cannot be verified"}
+	}
+	if r.ReceiptVersion != Version {
+		return SignatureCheck{Status: SignatureInvalid, Detail: fmt.Sprintf("receipt version v%d cannot be checked by a v%d verifier", r.ReceiptVersion, Version)}
+	}
+	if recomputed := contentDigest(r); recomputed != r.ReceiptDigest {
+		return SignatureCheck{Status: SignatureInvalid, Detail: "receipt contents do not match its receipt digest; it was altered after signing"}
+	}
+
+	public := keys.decode()
+	payload := signingPayload(r)
+	for _, sig := range r.Signatures {
+		key, ok := public[sig.KeyID]
+		if !ok || sig.Algorithm != SignatureAlgorithm {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+		if err != nil || !ed25519.Verify(key, payload, raw) {
+			return SignatureCheck{Status: SignatureInvalid, KeyID: sig.KeyID, Detail: fmt.Sprintf("signature by key %q does not verify", sig.KeyID)}
+		}
+		return SignatureCheck{Status: SignatureValid, KeyID: sig.KeyID, Detail: fmt.Sprintf("signed by key %q", sig.KeyID)}
+	}
+
+	ids := make([]string, 0, len(r.Signatures))
+	for _, sig := range r.Signatures {
+		ids = append(ids, sig.KeyID)
+	}
+	return SignatureCheck{Status: SignatureUnknownKey, Detail: fmt.Sprintf("signed by key(s) %s, none of which is in the public key set", strings.Join(ids, ", "))}
+}
+
+// decode returns the set's well-formed ed25519 keys by id. Malformed entries
+// are skipped, so a signature naming one reports as an unknown key.
+func (set PublicKeySet) decode() map[string]ed25519.PublicKey {
+	public := make(map[string]ed25519.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Algorithm != "" && key.Algorithm != SignatureAlgorithm {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			continue
+		}
+		public[key.KeyID] = ed25519.PublicKey(decoded)
+	}
+	return public
+}
+
+// contentDigest re-derives r's digest from its own task entries without
+// mutating r, re-classifying each task so a flipped degraded bit is caught
+// along with any other edit.
+func contentDigest(r *Receipt) string {
+	copied := Receipt{
+		GitCommit:           r.GitCommit,
+		ManifestContentHash: r.ManifestContentHash,
+		Tasks:               append([]TaskEntry(nil), r.Tasks...),
+	}
+	for i := range copied.Tasks {
+		markDegraded(&copied.Tasks[i])
+	}
+	copied.finalize()
+	if copied.Degraded != r.Degraded {
+		return ""
+	}
+	return copied.ReceiptDigest
+}
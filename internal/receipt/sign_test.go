@@ -0,0 +1,180 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeed(b byte) []byte {
+	return []byte(strings.Repeat(string(rune('a'+b)), ed25519.SeedSize))
+}
+
+func testKeyring(t *testing.T, current string, ids ...string) *Keyring {
+	t.Helper()
+	parts := make([]string, 0, len(ids))
+	for i, id := range ids {
+		parts = append(parts, id+"=base64:"+base64.StdEncoding.EncodeToString(testSeed(byte(i))))
+	}
+	k, err := ParseKeyring(current, strings.Join(parts, ","), "")
+	require.NoError(t, err)
+	return k
+}
+
+func signedReceipt(t *testing.T, k *Keyring) *Receipt {
+	t.Helper()
+	r := makeReceipt("g", "m", []TaskEntry{
+		pinnedEntry("extract", "ha", "sha256:a"),
+		pinnedEntry("load", "hb", "sha256:b"),
+	})
+	k.Sign(r)
+	return r
+}
+
+func TestSignAndCheck(t *testing.T) {
+	k := testKeyring(t, "", "k1")
+	r := signedReceipt(t, k)
+
+	require.Len(t, r.Signatures, 1)
+	require.Equal(t, "k1", r.Signatures[0].KeyID)
+	require.Equal(t, SignatureAlgorithm, r.Signatures[0].Algorithm)
+
+	check := CheckSignatures(r, k.PublicKeys())
+	require.Equal(t, SignatureValid, check.Status)
+	require.Equal(t, "k1", check.KeyID)
+
+	// A signature survives a JSON round trip, the path a committed receipt
+	// takes through git and `caesium verify`.
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	var loaded Receipt
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	require.Equal(t, SignatureValid, CheckSignatures(&loaded, k.PublicKeys()).Status)
+}
+
+func TestSignatureDoesNotChangeDigest(t *testing.T) {
+	unsigned := makeReceipt("g", "m", []TaskEntry{pinnedEntry("a", "ha", "sha256:a")})
+	digest := unsigned.ReceiptDigest
+	testKeyring(t, "", "k1").Sign(unsigned)
+	require.Equal(t, digest, unsigned.ReceiptDigest)
+}
+
+func TestCheckDetectsTampering(t *testing.T) {
+	k := testKeyring(t, "", "k1")
+
+	cases := map[string]func(r *Receipt){
+		"image digest":   func(r *Receipt) { r.Tasks[0].ResolvedImageDigest = "sha256:evil" },
+		"receipt digest": func(r *Receipt) { r.ReceiptDigest = strings.Repeat("0", 64) },
+		"run id":         func(r *Receipt) { r.RunID = uuid.New() },
+		"job id":         func(r *Receipt) { r.JobID = uuid.New() },
+		"degraded bit":   func(r *Receipt) { r.Degraded = true },
+		"dropped task":   func(r *Receipt) { r.Tasks = r.Tasks[:1] },
+		"signature": func(r *Receipt) {
+			r.Signatures[0].Signature = base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+		},
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := signedReceipt(t, k)
+			tamper(r)
+			require.Equal(t, SignatureInvalid, CheckSignatures(r, k.PublicKeys()).Status)
+		})
+	}
+}
+
+func TestCheckUnsignedAndUnknownKey(t *testing.T) {
+	unsigned := makeReceipt("g", "m", nil)
+	require.Equal(t, SignatureUnsigned, CheckSignatures(unsigned, PublicKeySet{}).Status)
+
+	r := signedReceipt(t, testKeyring(t, "", "k1"))
+	other, err := ParseKeyring("", "k2=base64:"+base64.StdEncoding.EncodeToString(testSeed(5)), "")
+	require.NoError(t, err)
+	require.Equal(t, SignatureUnknownKey, CheckSignatures(r, other.PublicKeys()).Status)
+}
+
+func TestKeyRotationKeepsOldReceiptsVerifying(t *testing.T) {
+	oldRing := testKeyring(t, "", "2025")
+	old := signedReceipt(t, oldRing)
+
+	// Rotate: the old key is retired to a verify-only public key.
+	retired := base64.StdEncoding.EncodeToString(oldRing.public["2025"])
+	newSeed := "hex:" + hex.EncodeToString(testSeed(9))
+	rotated, err := ParseKeyring("2026", "2026="+newSeed, "2025=base64:"+retired)
+	require.NoError(t, err)
+
+	fresh := signedReceipt(t, rotated)
+	require.Equal(t, "2026", fresh.Signatures[0].KeyID)
+
+	keys := rotated.PublicKeys()
+	require.Len(t, keys.Keys, 2)
+	require.Equal(t, "2025", keys.Keys[0].KeyID)
+	require.Equal(t, "2026", keys.Keys[1].KeyID)
+
+	require.Equal(t, SignatureValid, CheckSignatures(old, keys).Status)
+	require.Equal(t, SignatureValid, CheckSignatures(fresh, keys).Status)
+
+	// Re-signing replaces the receipt's signature by the same key only.
+	rotated.Sign(old)
+	require.Len(t, old.Signatures, 2)
+	rotated.Sign(old)
+	require.Len(t, old.Signatures, 2)
+}
+
+func TestParseKeyringErrors(t *testing.T) {
+	seed := base64.StdEncoding.EncodeToString(testSeed(0))
+
+	k, err := ParseKeyring("", "", "")
+	require.NoError(t, err)
+	require.Nil(t, k)
+
+	cases := map[string][3]string{
+		"missing separator":     {"", "k1", ""},
+		"bad base64":            {"", "k1=base64:!!", ""},
+		"wrong length":          {"", "k1=hex:abcd", ""},
+		"duplicate id":          {"", "k1=" + seed + ",k1=" + seed, ""},
+		"ambiguous current":     {"", "k1=" + seed + ",k2=" + seed, ""},
+		"unknown current":       {"k9", "k1=" + seed, ""},
+		"verify without signer": {"", "", "k1=" + seed},
+		"id reused":             {"", "k1=" + seed, "k1=" + seed},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseKeyring(tc[0], tc[1], tc[2])
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNilKeyringIsUnsigned(t *testing.T) {
+	var k *Keyring
+	r := makeReceipt("g", "m", nil)
+	k.Sign(r)
+	require.Empty(t, r.Signatures)
+	require.Empty(t, k.PublicKeys().Keys)
+	require.Empty(t, k.CurrentKeyID())
+}
+
+func TestVerifyResultCheckSignatures(t *testing.T) {
+	k := testKeyring(t, "", "k1")
+	r := signedReceipt(t, k)
+	r.RunID = uuid.New()
+
+	result := &VerifyResult{Match: true}
+	result.CheckSignatures(r, k.PublicKeys())
+	require.False(t, result.Match)
+	require.Equal(t, SignatureInvalid, result.Signature.Status)
+	require.Len(t, result.Drifts, 1)
+	require.Equal(t, DriftSignature, result.Drifts[0].Kind)
+
+	// An unsigned receipt is reported but does not fail the match on its own.
+	result = &VerifyResult{Match: true}
+	result.CheckSignatures(makeReceipt("g", "m", nil), k.PublicKeys())
+	require.True(t, result.Match)
+	require.Equal(t, SignatureUnsigned, result.Signature.Status)
+}
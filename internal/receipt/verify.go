@@ -40,6 +40,10 @@ const (
 	// DriftVersion means the two receipts were produced by different schema
 	// versions and are not directly comparable.
 	DriftVersion DriftKind = "receipt_version_mismatch"
+	// DriftSignature means the committed receipt carries a signature that does
+	// not verify against the cluster's public keys, or its contents were edited
+	// after signing. The receipt was not issued by this cluster as presented.
+	DriftSignature DriftKind = "signature_invalid"
 )
 
 // Drift is one detected divergence between a committed receipt and the
@@ -84,6 +88,10 @@ type VerifyResult struct {
 	// Drifts enumerates every divergence found, empty when Match is true.
 	Drifts []Drift `json:"drifts,omitempty"`
 
+	// Signature is the outcome of checking the committed receipt's signatures,
+	// nil when no public key set was consulted.
+	Signature *SignatureCheck `json:"signature,omitempty"`
+
 	// Rederived is the receipt Build produced from current state, for callers
 	// that want to inspect or re-commit it.
 	Rederived *Receipt `json:"rederived"`
@@ -123,6 +131,23 @@ func Verify(ctx context.Context, db *gorm.DB, committed *Receipt) (*VerifyResult
 	return result, nil
 }
 
+// CheckSignatures verifies the committed receipt's signatures against keys
+// and records the outcome. An invalid signature is drift and clears Match; an
+// unsigned receipt or one signed by an unknown key is reported but left to the
+// caller's policy, since receipts committed before signing was enabled carry
+// no signature at all.
+func (r *VerifyResult) CheckSignatures(committed *Receipt, keys PublicKeySet) {
+	check := CheckSignatures(committed, keys)
+	r.Signature = &check
+	if check.Status == SignatureInvalid {
+		r.Match = false
+		r.Drifts = append(r.Drifts, Drift{
+			Kind:   DriftSignature,
+			Detail: check.Detail,
+		})
+	}
+}
+
 // diff compares a committed receipt against a re-derived one and returns every
 // divergence. It is exported-package-internal so build and verify share one
 // definition of "what counts as drift," and so tests can exercise it directly
@@ -775,6 +775,7 @@ func (c *Constructor) materialize(ctx context.Context, baseline models.JobRun, p
 		model := models.JobRun{
 			ID:                replayID,
 			JobID:             baseline.JobID,
+			Namespace:         baseline.Namespace,
 			Status:            status,
 			Params:            datatypes.JSON(encodedParams),
 			Priority:          priority,
@@ -840,6 +841,7 @@ func taskRunRecord(replayID uuid.UUID, plan plannedTask, now time.Time, priority
 		Status:                  string(run.TaskStatusPending),
 		Priority:                priority,
 		NodeSelector:            datatypes.JSONMap(stringMapToAny(desc.Runtime.NodeSelector)),
+		NodeAffinity:            desc.Runtime.NodeAffinity,
 		Attempt:                 1,
 		MaxAttempts:             maxAttempts,
 		Hash:                    plan.replayHash,
@@ -194,6 +194,7 @@ type Descriptor struct {
 		WorkDir             string            `json:"workdir,omitempty"`
 		TaskType            string            `json:"taskType,omitempty"`
 		NodeSelector        map[string]string `json:"nodeSelector,omitempty"`
+		NodeAffinity        json.RawMessage   `json:"nodeAffinity,omitempty"`
 		RetryCount          int               `json:"retryCount"`
 		RetryDelay          time.Duration     `json:"retryDelay"`
 		RetryBackoff        bool              `json:"retryBackoff"`
@@ -764,6 +765,9 @@ func workloadIdentityDetails(desc *Descriptor) []string {
 	if len(desc.Runtime.NodeSelector) > 0 {
 		details = append(details, "node selector "+formatStringMap(desc.Runtime.NodeSelector))
 	}
+	if len(desc.Runtime.NodeAffinity) > 0 {
+		details = append(details, "node affinity "+string(desc.Runtime.NodeAffinity))
+	}
 	if k8s := firstKubernetesSpec(desc); k8s != nil {
 		if k8s.ServiceAccountName != "" {
 			details = append(details, "serviceAccountName "+k8s.ServiceAccountName)
@@ -0,0 +1,574 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/logarchive"
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/internal/models"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultArchiveInterval  = 5 * time.Minute
+	defaultArchiveBatchSize = 100
+	archiveInsertBatchSize  = 100
+)
+
+// errRunChanged reports a run that left its terminal status while it was
+// being copied to another database, such as a retry from failure.
+var errRunChanged = errors.New("run changed while moving")
+
+// ArchiverConfig configures the leader-gated run archiver.
+type ArchiverConfig struct {
+	// Store owns the hot run tables and the catalog.
+	Store *Store
+	// History is the cold-history database. Archival is skipped when it is
+	// nil or aliases the store's database; retention still applies.
+	History *gorm.DB
+	// ArchiveAfter is how long a terminal run stays hot after it completes.
+	// Zero disables archival.
+	ArchiveAfter time.Duration
+	Interval     time.Duration
+	// BatchSize bounds the runs archived, and separately the runs purged, in
+	// one pass.
+	BatchSize int
+	// Retention applies to jobs that do not declare metadata.retention.
+	Retention jobdefschema.Retention
+	// LogArchive, when set, is where purged runs' archived task logs are
+	// deleted from.
+	LogArchive  *logarchive.Archive
+	LeaderCheck func(context.Context) (bool, error)
+	Now         func() time.Time
+}
+
+// Archiver moves terminal runs to the cold-history database once they are
+// older than ArchiveAfter and deletes runs that fall outside their job's
+// retention policy.
+//
+// Runs move with moveRun. A crash between its copy and delete leaves the run
+// in both databases; reads prefer hot and the next pass finishes the move.
+type Archiver struct {
+	store        *Store
+	history      *gorm.DB
+	archiveAfter time.Duration
+	interval     time.Duration
+	batchSize    int
+	retention    jobdefschema.Retention
+	logArchive   *logarchive.Archive
+	leaderCheck  func(context.Context) (bool, error)
+	now          func() time.Time
+}
+
+func NewArchiver(cfg ArchiverConfig) *Archiver {
+	if cfg.Store == nil {
+		panic("run archiver requires a run store")
+	}
+	history := cfg.History
+	if history == cfg.Store.db {
+		history = nil
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultArchiveInterval
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Archiver{
+		store:        cfg.Store,
+		history:      history,
+		archiveAfter: cfg.ArchiveAfter,
+		interval:     interval,
+		batchSize:    batchSize,
+		retention:    cfg.Retention,
+		logArchive:   cfg.LogArchive,
+		leaderCheck:  cfg.LeaderCheck,
+		now:          now,
+	}
+}
+
+func (a *Archiver) Run(ctx context.Context) {
+	if err := a.ArchiveOnce(ctx); err != nil && ctx.Err() == nil {
+		log.Error("run archiver pass failed", "error", err)
+	}
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.ArchiveOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Error("run archiver pass failed", "error", err)
+			}
+		}
+	}
+}
+
+// ArchiveOnce archives up to BatchSize runs and then purges up to BatchSize
+// runs. A failure on one run is logged and does not hold up the others.
+func (a *Archiver) ArchiveOnce(ctx context.Context) error {
+	if a.leaderCheck != nil {
+		leader, err := a.leaderCheck(ctx)
+		if err != nil {
+			return err
+		}
+		if !leader {
+			return nil
+		}
+	}
+
+	if a.history != nil && a.archiveAfter > 0 {
+		archived, err := a.archive(ctx)
+		if err != nil {
+			return err
+		}
+		if archived > 0 {
+			log.Info("run archiver moved runs to history", "count", archived)
+		}
+	}
+
+	purged, err := a.purge(ctx)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Info("run archiver purged runs outside retention", "count", purged)
+	}
+	return nil
+}
+
+func archivableStatuses() []string {
+	return []string{string(StatusSucceeded), string(StatusFailed), string(StatusCancelled)}
+}
+
+func (a *Archiver) archive(ctx context.Context) (int, error) {
+	hot := a.store.db.WithContext(ctx)
+
+	// Backfill progress is counted from hot job_runs, so a running or paused
+	// backfill keeps its runs hot until it finishes.
+	var activeBackfills []uuid.UUID
+	if err := hot.Model(&models.Backfill{}).
+		Where("status IN ?", []string{string(models.BackfillStatusRunning), string(models.BackfillStatusPaused)}).
+		Pluck("id", &activeBackfills).Error; err != nil {
+		return 0, err
+	}
+
+	query := hot.Model(&models.JobRun{}).
+		Where("status IN ? AND completed_at IS NOT NULL AND completed_at < ?", archivableStatuses(), a.now().Add(-a.archiveAfter))
+	if len(activeBackfills) > 0 {
+		query = query.Where("backfill_id IS NULL OR backfill_id NOT IN ?", activeBackfills)
+	}
+	var candidates []models.JobRun
+	if err := query.Select("id").
+		Order("completed_at ASC").
+		Limit(a.batchSize).
+		Find(&candidates).Error; err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			return archived, ctx.Err()
+		}
+		err := a.archiveRun(ctx, candidate.ID)
+		switch {
+		case errors.Is(err, errRunChanged):
+			log.Info("run archiver skipped a run that changed while archiving", "run_id", candidate.ID)
+		case err != nil:
+			log.Error("run archive failed", "run_id", candidate.ID, "error", err)
+		default:
+			archived++
+			metrics.RunsArchivedTotal.Inc()
+		}
+	}
+	return archived, nil
+}
+
+// runRows is every row that belongs to one run in the run-scoped tables.
+type runRows struct {
+	run       models.JobRun
+	tasks     []models.TaskRun
+	instances []models.TaskRunInstance
+	approvals []models.TaskApproval
+	pokes     []models.SensorPoke
+	callbacks []models.CallbackRun
+	events    []models.ExecutionEvent
+}
+
+func loadRunRows(conn *gorm.DB, runID uuid.UUID) (*runRows, error) {
+	rows := &runRows{}
+	if err := conn.First(&rows.run, "id = ?", runID).Error; err != nil {
+		return nil, err
+	}
+	for _, load := range []struct {
+		dest  any
+		query string
+	}{
+		{&rows.tasks, "job_run_id = ?"},
+		{&rows.instances, "job_run_id = ?"},
+		{&rows.approvals, "job_run_id = ?"},
+		{&rows.pokes, "job_run_id = ?"},
+		{&rows.callbacks, "job_run_id = ?"},
+		{&rows.events, "run_id = ?"},
+	} {
+		if err := conn.Where(load.query, runID).Find(load.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (a *Archiver) archiveRun(ctx context.Context, runID uuid.UUID) error {
+	if err := moveRun(ctx, a.store.db, a.history, runID); err != nil {
+		if errors.Is(err, errRunChanged) {
+			return err
+		}
+		return fmt.Errorf("archive run: %w", err)
+	}
+	return nil
+}
+
+// moveRun moves a terminal run's rows from src to dst. dqlite has no
+// cross-database transactions, so the rows are copied into dst in one
+// transaction, replacing any partial copy, checked against what was read, and
+// only then deleted from src in a second transaction that re-checks the run's
+// status. A run that changed in between stays in src and its copy is dropped.
+// Checkpoints only matter to in-flight runs, so they are not copied.
+func moveRun(ctx context.Context, src, dst *gorm.DB, runID uuid.UUID) error {
+	rows, err := loadRunRows(src.WithContext(ctx), runID)
+	if err != nil {
+		return err
+	}
+
+	err = dst.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteRunRowsTx(tx, runID); err != nil {
+			return err
+		}
+		if err := insertRunRowsTx(tx, rows); err != nil {
+			return err
+		}
+		return verifyRunRowsTx(tx, rows)
+	})
+	if err != nil {
+		return fmt.Errorf("copy run: %w", err)
+	}
+
+	err = withStoreBusyRetryContext(ctx, func() error {
+		return src.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Claim the row in its copied status before deleting anything, so
+			// a retry that reopened the run keeps it where it is.
+			res := tx.Exec("UPDATE job_runs SET status = status WHERE id = ? AND status = ?", runID, rows.run.Status)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errRunChanged
+			}
+			return deleteRunRowsTx(tx, runID)
+		})
+	})
+	if errors.Is(err, errRunChanged) {
+		if cleanupErr := dst.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return deleteRunRowsTx(tx, runID)
+		}); cleanupErr != nil {
+			return errors.Join(err, cleanupErr)
+		}
+	}
+	return err
+}
+
+// verifyRunRowsTx checks that every run-scoped table in tx holds exactly the
+// rows that were copied for the run.
+func verifyRunRowsTx(tx *gorm.DB, rows *runRows) error {
+	runID := rows.run.ID
+	for _, check := range []struct {
+		table string
+		model any
+		query string
+		want  int
+	}{
+		{"job_runs", &models.JobRun{}, "id = ?", 1},
+		{"task_runs", &models.TaskRun{}, "job_run_id = ?", len(rows.tasks)},
+		{"task_run_instances", &models.TaskRunInstance{}, "job_run_id = ?", len(rows.instances)},
+		{"task_approvals", &models.TaskApproval{}, "job_run_id = ?", len(rows.approvals)},
+		{"sensor_pokes", &models.SensorPoke{}, "job_run_id = ?", len(rows.pokes)},
+		{"callback_runs", &models.CallbackRun{}, "job_run_id = ?", len(rows.callbacks)},
+		{"execution_events", &models.ExecutionEvent{}, "run_id = ?", len(rows.events)},
+	} {
+		var count int64
+		if err := tx.Model(check.model).Where(check.query, runID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(check.want) {
+			return fmt.Errorf("verify %s: copied %d rows, found %d", check.table, check.want, count)
+		}
+	}
+	return nil
+}
+
+func insertRunRowsTx(tx *gorm.DB, rows *runRows) error {
+	tx = tx.Omit(clause.Associations).Session(&gorm.Session{})
+	if err := tx.Create(&rows.run).Error; err != nil {
+		return err
+	}
+	if err := createRows(tx, rows.tasks); err != nil {
+		return err
+	}
+	if err := createRows(tx, rows.instances); err != nil {
+		return err
+	}
+	if err := createRows(tx, rows.approvals); err != nil {
+		return err
+	}
+	if err := createRows(tx, rows.pokes); err != nil {
+		return err
+	}
+	if err := createRows(tx, rows.callbacks); err != nil {
+		return err
+	}
+	return createRows(tx, rows.events)
+}
+
+func createRows[T any](tx *gorm.DB, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&rows, archiveInsertBatchSize).Error
+}
+
+// deleteRunRowsTx deletes a run and its rows from every run-scoped table,
+// children first so it works with and without foreign keys. The run's task
+// logs go with its task runs.
+func deleteRunRowsTx(tx *gorm.DB, runID uuid.UUID) error {
+	for _, del := range []struct {
+		model any
+		query string
+		arg   any
+	}{
+		{&models.TaskRunInstance{}, "job_run_id = ?", runID},
+		{&models.TaskApproval{}, "job_run_id = ?", runID},
+		{&models.SensorPoke{}, "job_run_id = ?", runID},
+		{&models.CallbackRun{}, "job_run_id = ?", runID},
+		{&models.TaskRun{}, "job_run_id = ?", runID},
+		{&models.ExecutionEvent{}, "run_id = ?", runID},
+		{&models.RunCheckpoint{}, "run_id = ?", runID.String()},
+		{&models.JobRun{}, "id = ?", runID},
+	} {
+		if err := tx.Where(del.query, del.arg).Delete(del.model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retainedRun is the part of a run that retention decides on.
+type retainedRun struct {
+	ID          uuid.UUID
+	JobID       uuid.UUID
+	Status      string
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	Quarantine  bool
+	archived    bool
+}
+
+func (a *Archiver) purge(ctx context.Context) (int, error) {
+	catalog := a.store.db.WithContext(ctx)
+	var jobs []models.Job
+	if err := catalog.Unscoped().Select("id", "retention").Find(&jobs).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, job := range jobs {
+		if purged >= a.batchSize {
+			break
+		}
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		policy, err := a.policyFor(job.Retention)
+		if err != nil {
+			log.Warn("run archiver ignored an unreadable retention policy", "job_id", job.ID, "error", err)
+			continue
+		}
+		if policy.KeepRuns == 0 && policy.KeepDays == 0 {
+			continue
+		}
+		expired, err := a.expiredRuns(ctx, job.ID, policy, a.batchSize-purged)
+		if err != nil {
+			log.Error("run retention scan failed", "job_id", job.ID, "error", err)
+			continue
+		}
+		for _, run := range expired {
+			if purged >= a.batchSize {
+				break
+			}
+			conn, tier := a.store.db, "hot"
+			if run.archived {
+				conn, tier = a.history, "history"
+			}
+			var logRefs []string
+			if err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				if a.logArchive != nil {
+					if err := tx.Model(&models.TaskRun{}).
+						Where("job_run_id = ? AND log_archive_ref <> ''", run.ID).
+						Pluck("log_archive_ref", &logRefs).Error; err != nil {
+						return err
+					}
+					var instanceRefs []string
+					if err := tx.Model(&models.TaskRunInstance{}).
+						Where("job_run_id = ? AND log_archive_ref <> ''", run.ID).
+						Pluck("log_archive_ref", &instanceRefs).Error; err != nil {
+						return err
+					}
+					logRefs = append(logRefs, instanceRefs...)
+				}
+				return deleteRunRowsTx(tx, run.ID)
+			}); err != nil {
+				log.Error("run purge failed", "run_id", run.ID, "error", err)
+				continue
+			}
+			purged++
+			metrics.RunsPurgedTotal.WithLabelValues(tier).Inc()
+			a.removeLogArchives(ctx, run.ID, logRefs)
+		}
+	}
+	return purged, nil
+}
+
+// removeLogArchives deletes a purged run's archived task logs. A failure
+// only leaks blob storage, so it is logged rather than retried.
+func (a *Archiver) removeLogArchives(ctx context.Context, runID uuid.UUID, refs []string) {
+	for _, ref := range refs {
+		if err := a.logArchive.Remove(ctx, ref); err != nil {
+			log.Warn("failed to remove a purged run's task log archive", "run_id", runID, "ref", ref, "error", err)
+		}
+	}
+}
+
+func (a *Archiver) policyFor(raw []byte) (jobdefschema.Retention, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return a.retention, nil
+	}
+	var policy jobdefschema.Retention
+	if err := json.Unmarshal(raw, &policy); err != nil {
+		return jobdefschema.Retention{}, err
+	}
+	return policy, nil
+}
+
+// expiredRuns returns up to limit of the job's finished runs that policy no
+// longer keeps, oldest first. Each database is asked only for the newest
+// KeepRuns runs and for runs past the policy's age cutoffs, so a job's whole
+// history is never loaded.
+func (a *Archiver) expiredRuns(ctx context.Context, jobID uuid.UUID, policy jobdefschema.Retention, limit int) ([]retainedRun, error) {
+	tiers := []*gorm.DB{a.store.db.WithContext(ctx)}
+	if a.history != nil {
+		tiers = append(tiers, a.history.WithContext(ctx))
+	}
+
+	kept, err := newestRunIDs(tiers, jobID, policy.KeepRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	failedDays := policy.KeepDays
+	if policy.KeepFailedDays > 0 {
+		failedDays = policy.KeepFailedDays
+	}
+	var expired []retainedRun
+	// A run caught mid-move is in both; only its hot copy counts.
+	seen := make(map[uuid.UUID]struct{})
+	for idx, conn := range tiers {
+		query := conn.Model(&models.JobRun{}).
+			Select("id", "job_id", "status", "started_at", "completed_at", "quarantine").
+			Where("job_id = ? AND status IN ? AND completed_at IS NOT NULL", jobID, archivableStatuses())
+		if len(kept) > 0 {
+			query = query.Where("id NOT IN ?", kept)
+		}
+		if policy.KeepDays > 0 {
+			query = query.Where("(status = ? OR completed_at <= ?)", string(StatusFailed), now.AddDate(0, 0, -policy.KeepDays))
+		}
+		if failedDays > 0 {
+			query = query.Where("(status <> ? OR completed_at <= ?)", string(StatusFailed), now.AddDate(0, 0, -failedDays))
+		}
+		var runs []retainedRun
+		if err := query.Order("started_at ASC").Limit(limit).Find(&runs).Error; err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			if _, ok := seen[run.ID]; ok {
+				continue
+			}
+			seen[run.ID] = struct{}{}
+			run.archived = idx > 0
+			expired = append(expired, run)
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].StartedAt.Before(expired[j].StartedAt)
+	})
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+// newestRunIDs returns the IDs of the job's keep newest runs across tiers,
+// which retention keeps whatever their age. Quarantined replays are kept by
+// age alone so what-if runs never push real ones out of the newest runs.
+func newestRunIDs(tiers []*gorm.DB, jobID uuid.UUID, keep int) ([]uuid.UUID, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	var newest []retainedRun
+	for _, conn := range tiers {
+		var runs []retainedRun
+		if err := conn.Model(&models.JobRun{}).
+			Select("id", "started_at").
+			Where("job_id = ? AND quarantine IS NOT TRUE", jobID).
+			Order("started_at DESC").
+			Limit(keep).
+			Find(&runs).Error; err != nil {
+			return nil, err
+		}
+		newest = append(newest, runs...)
+	}
+	sort.SliceStable(newest, func(i, j int) bool {
+		return newest[i].StartedAt.After(newest[j].StartedAt)
+	})
+
+	ids := make([]uuid.UUID, 0, keep)
+	seen := make(map[uuid.UUID]struct{}, keep)
+	for _, run := range newest {
+		if len(ids) == keep {
+			break
+		}
+		if _, ok := seen[run.ID]; ok {
+			continue
+		}
+		seen[run.ID] = struct{}{}
+		ids = append(ids, run.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,245 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func seedArchiveRun(t *testing.T, db *gorm.DB, jobID uuid.UUID, status Status, completedAt time.Time) uuid.UUID {
+	t.Helper()
+	runID := uuid.New()
+	startedAt := completedAt.Add(-time.Minute)
+	require.NoError(t, db.Create(&models.JobRun{
+		ID:          runID,
+		JobID:       jobID,
+		Status:      string(status),
+		StartedAt:   startedAt,
+		CompletedAt: &completedAt,
+		CreatedAt:   startedAt,
+		UpdatedAt:   completedAt,
+	}).Error)
+	return runID
+}
+
+func TestArchiverMovesOldTerminalRunsToHistory(t *testing.T) {
+	hot := testutil.OpenTestDB(t)
+	history := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(hot)
+		testutil.CloseDB(history)
+	})
+	store := NewStore(hot).WithHistory(history)
+
+	job := createConcurrencyJob(t, hot, "archived", jobdef.ConcurrencyStrategyQueue, 1)
+	now := time.Now().UTC()
+	oldRun := seedArchiveRun(t, hot, job.ID, StatusSucceeded, now.Add(-48*time.Hour))
+	require.NoError(t, hot.Create(&models.TaskRun{
+		ID:        uuid.New(),
+		JobRunID:  oldRun,
+		TaskID:    uuid.New(),
+		AtomID:    uuid.New(),
+		Engine:    models.AtomEngineDocker,
+		Image:     "alpine:3.23",
+		Status:    string(TaskStatusSucceeded),
+		CreatedAt: now.Add(-48 * time.Hour),
+		UpdatedAt: now.Add(-48 * time.Hour),
+	}).Error)
+	recentRun := seedArchiveRun(t, hot, job.ID, StatusSucceeded, now.Add(-time.Hour))
+	runningRun := seedRunningRun(t, hot, job.ID)
+
+	archiver := NewArchiver(ArchiverConfig{
+		Store:        store,
+		History:      history,
+		ArchiveAfter: 24 * time.Hour,
+	})
+	require.NoError(t, archiver.ArchiveOnce(context.Background()))
+
+	testutil.AssertCount(t, hot, &models.JobRun{}, 2)
+	testutil.AssertCount(t, hot, &models.TaskRun{}, 0)
+	testutil.AssertCount(t, history, &models.JobRun{}, 1)
+	testutil.AssertCount(t, history, &models.TaskRun{}, 1)
+
+	archived, err := store.Get(oldRun)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, archived.Status)
+	require.Equal(t, "archived", archived.JobAlias)
+	require.Len(t, archived.Tasks, 1)
+
+	runs, err := store.List(job.ID, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	require.Equal(t, oldRun, runs[0].ID)
+
+	byID, err := store.ListByIDs([]uuid.UUID{oldRun, recentRun, runningRun})
+	require.NoError(t, err)
+	require.Len(t, byID, 3)
+
+	// A second pass has nothing left to move.
+	require.NoError(t, archiver.ArchiveOnce(context.Background()))
+	testutil.AssertCount(t, history, &models.JobRun{}, 1)
+}
+
+func TestArchiverLatestFallsThroughToHistory(t *testing.T) {
+	hot := testutil.OpenTestDB(t)
+	history := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(hot)
+		testutil.CloseDB(history)
+	})
+	store := NewStore(hot).WithHistory(history)
+
+	job := createConcurrencyJob(t, hot, "latest-archived", jobdef.ConcurrencyStrategyQueue, 1)
+	runID := seedArchiveRun(t, hot, job.ID, StatusFailed, time.Now().UTC().Add(-48*time.Hour))
+
+	require.NoError(t, NewArchiver(ArchiverConfig{
+		Store:        store,
+		History:      history,
+		ArchiveAfter: 24 * time.Hour,
+	}).ArchiveOnce(context.Background()))
+
+	latest, err := store.Latest(job.ID)
+	require.NoError(t, err)
+	require.Equal(t, runID, latest.ID)
+	require.Equal(t, StatusFailed, latest.Status)
+}
+
+func TestArchiverPurgesRunsOutsideRetention(t *testing.T) {
+	hot := testutil.OpenTestDB(t)
+	history := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(hot)
+		testutil.CloseDB(history)
+	})
+	store := NewStore(hot).WithHistory(history)
+
+	job := createConcurrencyJob(t, hot, "retained", jobdef.ConcurrencyStrategyQueue, 1)
+	raw, err := json.Marshal(&jobdef.Retention{KeepRuns: 1, KeepDays: 7, KeepFailedDays: 30})
+	require.NoError(t, err)
+	require.NoError(t, hot.Model(&models.Job{}).Where("id = ?", job.ID).Update("retention", datatypes.JSON(raw)).Error)
+
+	now := time.Now().UTC()
+	newest := seedArchiveRun(t, history, job.ID, StatusSucceeded, now.AddDate(0, 0, -20))
+	oldSuccess := seedArchiveRun(t, history, job.ID, StatusSucceeded, now.AddDate(0, 0, -21))
+	oldFailure := seedArchiveRun(t, history, job.ID, StatusFailed, now.AddDate(0, 0, -22))
+	expiredFailure := seedArchiveRun(t, hot, job.ID, StatusFailed, now.AddDate(0, 0, -40))
+
+	require.NoError(t, NewArchiver(ArchiverConfig{Store: store, History: history}).ArchiveOnce(context.Background()))
+
+	var historyIDs []uuid.UUID
+	require.NoError(t, history.Model(&models.JobRun{}).Order("started_at DESC").Pluck("id", &historyIDs).Error)
+	require.Equal(t, []uuid.UUID{newest, oldFailure}, historyIDs, "the newest run and a failure within keepFailedDays survive")
+	require.NotContains(t, historyIDs, oldSuccess)
+	testutil.AssertCount(t, hot, &models.JobRun{}, 0)
+
+	_, err = store.Get(expiredFailure)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestArchiverFallsBackToDefaultRetention(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	job := createConcurrencyJob(t, db, "default-retention", jobdef.ConcurrencyStrategyQueue, 1)
+	now := time.Now().UTC()
+	kept := seedArchiveRun(t, db, job.ID, StatusSucceeded, now.Add(-time.Hour))
+	seedArchiveRun(t, db, job.ID, StatusSucceeded, now.Add(-2*time.Hour))
+	seedArchiveRun(t, db, job.ID, StatusCancelled, now.Add(-3*time.Hour))
+	running := seedRunningRun(t, db, job.ID)
+
+	archiver := NewArchiver(ArchiverConfig{
+		Store:     store,
+		History:   db,
+		Retention: jobdef.Retention{KeepRuns: 2},
+	})
+	require.NoError(t, archiver.ArchiveOnce(context.Background()))
+
+	var ids []uuid.UUID
+	require.NoError(t, db.Model(&models.JobRun{}).Pluck("id", &ids).Error)
+	require.ElementsMatch(t, []uuid.UUID{kept, running}, ids, "running runs count toward keepRuns but are never purged")
+}
+
+func TestArchiverPurgeIsBoundedByBatchSize(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	job := createConcurrencyJob(t, db, "bounded-purge", jobdef.ConcurrencyStrategyQueue, 1)
+	now := time.Now().UTC()
+	var oldest []uuid.UUID
+	for day := 10; day >= 1; day-- {
+		id := seedArchiveRun(t, db, job.ID, StatusSucceeded, now.AddDate(0, 0, -day))
+		if day > 8 {
+			oldest = append(oldest, id)
+		}
+	}
+
+	archiver := NewArchiver(ArchiverConfig{
+		Store:     store,
+		Retention: jobdef.Retention{KeepRuns: 1},
+		BatchSize: 2,
+	})
+	require.NoError(t, archiver.ArchiveOnce(context.Background()))
+
+	testutil.AssertCount(t, db, &models.JobRun{}, 8)
+	for _, id := range oldest {
+		_, err := store.Get(id)
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound, "the oldest expired runs go first")
+	}
+}
+
+func TestListPagesNewestRunsAcrossHistory(t *testing.T) {
+	hot := testutil.OpenTestDB(t)
+	history := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(hot)
+		testutil.CloseDB(history)
+	})
+	store := NewStore(hot).WithHistory(history)
+
+	job := createConcurrencyJob(t, hot, "paged", jobdef.ConcurrencyStrategyQueue, 1)
+	now := time.Now().UTC()
+	var ids []uuid.UUID
+	for day := 5; day >= 1; day-- {
+		// The two oldest runs are archived; the rest are hot.
+		conn := hot
+		if day > 3 {
+			conn = history
+		}
+		ids = append(ids, seedArchiveRun(t, conn, job.ID, StatusSucceeded, now.AddDate(0, 0, -day)))
+	}
+
+	page, err := store.List(job.ID, ListOptions{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, ids[3:], runIDs(page), "the newest runs, oldest first")
+
+	page, err = store.List(job.ID, ListOptions{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Equal(t, ids[1:3], runIDs(page), "the page spans history and hot runs")
+
+	page, err = store.List(job.ID, ListOptions{Limit: 2, Offset: 4})
+	require.NoError(t, err)
+	require.Equal(t, ids[:1], runIDs(page))
+
+	all, err := store.List(job.ID, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, ids, runIDs(all))
+}
+
+func runIDs(runs []*JobRun) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(runs))
+	for _, run := range runs {
+		ids = append(ids, run.ID)
+	}
+	return ids
+}
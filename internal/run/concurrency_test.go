@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	backfillstore "github.com/caesium-cloud/caesium/internal/backfill"
 	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/pkg/jobdef"
@@ -40,6 +41,27 @@ func TestStartAdmissionConditionalInsertRowsAffected(t *testing.T) {
 	require.Equal(t, int64(1), active)
 }
 
+func TestStartAdmissionConditionalInsertStoresLogicalDate(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	store := NewStore(db)
+	job := createConcurrencyJob(t, db, "admit-logical-date", jobdef.ConcurrencyStrategyFail, 1)
+	const logicalDate = "2026-10-01T00:00:00Z"
+
+	admitted, err := store.Start(job.ID, nil, WithStartParams(map[string]string{jobdef.ParamLogicalDate: logicalDate}))
+	require.NoError(t, err)
+
+	var stored models.JobRun
+	require.NoError(t, db.First(&stored, "id = ?", admitted.ID).Error)
+	require.Equal(t, logicalDate, stored.LogicalDate)
+
+	// Reprocess policies find the admitted run by its logical date.
+	status, err := backfillstore.NewStore(db).LatestRunForLogicalDate(job.ID, logicalDate)
+	require.NoError(t, err)
+	require.Equal(t, string(StatusRunning), status)
+}
+
 func TestCancelRunTransitionsRunTasksAndLease(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 	t.Cleanup(func() { testutil.CloseDB(db) })
@@ -123,6 +145,71 @@ func TestCancelRunTransitionsRunTasksAndLease(t *testing.T) {
 	require.Zero(t, leases)
 }
 
+func TestCancelRunWithReasonRecordsReasonAndRejectsTerminalRuns(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	store := NewStore(db)
+	now := time.Now().UTC()
+	job := createConcurrencyJob(t, db, "cancel-reason", jobdef.ConcurrencyStrategyFail, 1)
+	runID := uuid.New()
+	taskID := uuid.New()
+	atomID := uuid.New()
+	require.NoError(t, db.Create(&models.Atom{
+		ID:        atomID,
+		Engine:    models.AtomEngineDocker,
+		Image:     "alpine:3.23",
+		Command:   `["true"]`,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error)
+	require.NoError(t, db.Create(&models.Task{
+		ID:        taskID,
+		JobID:     job.ID,
+		AtomID:    atomID,
+		Name:      "running",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error)
+	require.NoError(t, db.Create(&models.JobRun{
+		ID:        runID,
+		JobID:     job.ID,
+		Status:    string(StatusRunning),
+		StartedAt: now.Add(-time.Minute),
+		CreatedAt: now.Add(-time.Minute),
+		UpdatedAt: now.Add(-time.Minute),
+	}).Error)
+	taskRunID := uuid.New()
+	otherTaskRunID := uuid.New()
+	require.NoError(t, db.Create(&models.TaskRun{ID: taskRunID, JobRunID: runID, TaskID: taskID, AtomID: atomID, Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["true"]`, Status: string(TaskStatusRunning), ClaimedBy: "node-a", CreatedAt: now, UpdatedAt: now}).Error)
+
+	cancelled, err := store.IsRunCancelled(context.Background(), runID)
+	require.NoError(t, err)
+	require.False(t, cancelled)
+
+	require.NoError(t, store.CancelRunWithReason(context.Background(), runID, "cancelled by alice"))
+
+	var runRow models.JobRun
+	require.NoError(t, db.First(&runRow, "id = ?", runID).Error)
+	require.Equal(t, string(StatusCancelled), runRow.Status)
+	require.Equal(t, "cancelled by alice", runRow.Error)
+
+	cancelled, err = store.IsRunCancelled(context.Background(), runID)
+	require.NoError(t, err)
+	require.True(t, cancelled)
+
+	ids, err := store.CancelledTaskRuns(context.Background(), []uuid.UUID{taskRunID, otherTaskRunID})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{taskRunID}, ids)
+
+	err = store.CancelRunWithReason(context.Background(), runID, "again")
+	require.ErrorIs(t, err, ErrRunNotActive)
+	var notActive *RunNotActiveError
+	require.ErrorAs(t, err, &notActive)
+	require.Equal(t, StatusCancelled, notActive.Status, "the conflict reports the status the cancel observed")
+	require.NoError(t, store.CancelRun(context.Background(), runID), "CancelRun stays a no-op for terminal runs")
+}
+
 func TestDequeueNextRunClaimsOneRowByPriority(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 	t.Cleanup(func() { testutil.CloseDB(db) })
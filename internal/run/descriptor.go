@@ -5,9 +5,31 @@ import (
 
 	"github.com/caesium-cloud/caesium/internal/jobdef/secret"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/container"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"gorm.io/datatypes"
 )
 
+// RenderTaskSpec renders run templates in an atom's command and env values
+// when templated is set, and returns them as written otherwise. Registration
+// records the result on the task run and in its execution descriptor;
+// executors render the atom again when building the container, which agrees
+// because rendering depends only on the run's stored fields.
+func RenderTaskSpec(atom *models.Atom, templated bool, data jobdefschema.RunTemplateData) ([]string, container.Spec, error) {
+	spec := atom.ContainerSpec()
+	if !templated {
+		return atom.Cmd(), spec, nil
+	}
+	command, err := jobdefschema.RenderRunCommand(atom.Cmd(), data)
+	if err != nil {
+		return nil, container.Spec{}, err
+	}
+	if spec.Env, err = jobdefschema.RenderRunValues("env", spec.Env, data); err != nil {
+		return nil, container.Spec{}, err
+	}
+	return command, spec, nil
+}
+
 // SecretIdentityDescriptorRef converts a resolved secret identity into the
 // immutable TaskRun descriptor shape. It intentionally omits the secret value.
 func SecretIdentityDescriptorRef(envKey, ref string, identity secret.Identity) models.TaskExecutionSecretRef {
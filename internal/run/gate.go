@@ -0,0 +1,457 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/event"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// gate.go persists the approval requests of gated steps (`gate:` in the job
+// definition). When a gated step's dependencies are satisfied its executor
+// opens a task_approvals row and parks the task run in awaiting_approval; the
+// approve/reject API or the gate sweeper resolves the row, and the executor
+// then resumes, fails, or skips the step through the ordinary task paths.
+
+var (
+	// ErrGateNotFound is returned when a task run has no approval request.
+	ErrGateNotFound = errors.New("run: task has no approval gate")
+	// ErrGateNotPending is returned by DecideGate when the request was
+	// already approved, rejected, or expired.
+	ErrGateNotPending = errors.New("run: approval gate is not pending")
+)
+
+// GateRequest carries the terms of an approval request, copied from the
+// step's gate block when the request is opened.
+type GateRequest struct {
+	Approvers []string
+	Timeout   time.Duration
+	OnTimeout string
+}
+
+// TaskGate is the run-payload view of a gated task's approval request.
+type TaskGate struct {
+	ID        uuid.UUID               `json:"id"`
+	Approvers []string                `json:"approvers,omitempty"`
+	OnTimeout string                  `json:"on_timeout"`
+	Decision  models.ApprovalDecision `json:"decision"`
+	Decider   string                  `json:"decider,omitempty"`
+	Reason    string                  `json:"reason,omitempty"`
+	ExpiresAt *time.Time              `json:"expires_at,omitempty"`
+	DecidedAt *time.Time              `json:"decided_at,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// Pending reports whether the request is still waiting for a decision.
+func (g *TaskGate) Pending() bool {
+	return g.Decision == models.ApprovalDecisionPending
+}
+
+// AwaitApproval opens the approval request for a gated task, or resumes the
+// existing one when the task is re-executed after a reclaim, and parks the
+// task run in awaiting_approval while the request is pending. claimedBy fences
+// the transition for worker-claimed tasks; it is empty for the local
+// executor. A task_awaiting_approval event is recorded only when the request
+// is first opened, so a resumed wait does not notify twice.
+func (s *Store) AwaitApproval(runID, taskID uuid.UUID, claimedBy string, req GateRequest) (*TaskGate, error) {
+	approvers, err := json.Marshal(req.Approvers)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling gate approvers: %w", err)
+	}
+
+	var (
+		gate          *TaskGate
+		pendingEvents []event.Event
+		counts        dbWriteCounts
+	)
+	err = withStoreBusyRetry(func() error {
+		counts.reset()
+		attemptEvents := make([]event.Event, 0, 1)
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var taskRun models.TaskRun
+			query := tx.Select("id", "status").Where("job_run_id = ? AND task_id = ?", runID, taskID)
+			if claimedBy != "" {
+				query = query.Where("claimed_by = ?", claimedBy)
+			}
+			if err := query.First(&taskRun).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) && claimedBy != "" {
+					return ErrTaskClaimMismatch
+				}
+				return err
+			}
+
+			var row models.TaskApproval
+			opened := false
+			err := tx.Where("task_run_id = ?", taskRun.ID).First(&row).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				now := time.Now().UTC()
+				row = models.TaskApproval{
+					ID:        uuid.New(),
+					TaskRunID: taskRun.ID,
+					JobRunID:  runID,
+					TaskID:    taskID,
+					Approvers: datatypes.JSON(approvers),
+					OnTimeout: req.OnTimeout,
+					Decision:  models.ApprovalDecisionPending,
+					CreatedAt: now,
+					UpdatedAt: now,
+				}
+				if req.Timeout > 0 {
+					expiresAt := now.Add(req.Timeout)
+					row.ExpiresAt = &expiresAt
+				}
+				if err := tx.Create(&row).Error; err != nil {
+					return err
+				}
+				opened = true
+			case err != nil:
+				return err
+			}
+			gate = convertTaskApprovalModel(&row)
+			if !gate.Pending() {
+				return nil
+			}
+
+			update := tx.Model(&models.TaskRun{}).
+				Where("id = ? AND status IN ?", taskRun.ID, []string{string(TaskStatusPending), string(TaskStatusRunning)})
+			if claimedBy != "" {
+				update = update.Where("claimed_by = ?", claimedBy)
+			}
+			result := update.Update("status", string(TaskStatusAwaitingApproval))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				if TaskStatus(taskRun.Status) == TaskStatusAwaitingApproval {
+					return nil
+				}
+				return ErrTaskClaimMismatch
+			}
+			counts.addTaskRunStatus(1)
+
+			if opened && s.eventStore != nil {
+				evt, err := s.recordTaskEventTx(tx, event.TypeTaskAwaitingApproval, runID, taskID, &counts)
+				if err != nil {
+					return err
+				}
+				attemptEvents = append(attemptEvents, *evt)
+			}
+			return nil
+		})
+		if err == nil {
+			pendingEvents = attemptEvents
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts.commit()
+	s.publishEvents(pendingEvents...)
+	return gate, nil
+}
+
+// ReleaseGatedTask releases a worker-claimed task whose approval request is
+// still pending, so the wait holds no worker or claim: the task stays in
+// awaiting_approval (holding its pool slot, as under the local executor) with
+// its claim cleared, and claims leave it alone until retryAfter. A decision or
+// expiry makes it claimable again at once. It returns ErrTaskClaimMismatch
+// when the task is terminal or was reclaimed.
+func (s *Store) ReleaseGatedTask(ctx context.Context, runID, taskID uuid.UUID, claimedBy string, retryAfter time.Time) error {
+	return withStoreBusyRetryContext(ctx, func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			query := tx.Model(&models.TaskRun{}).
+				Where("job_run_id = ? AND task_id = ? AND status IN ?", runID, taskID,
+					[]string{string(TaskStatusRunning), string(TaskStatusAwaitingApproval)})
+			if claimedBy != "" {
+				query = query.Where("claimed_by = ?", claimedBy)
+			}
+			result := query.Updates(map[string]interface{}{
+				"status":                 string(TaskStatusAwaitingApproval),
+				"claimed_by":             "",
+				"claim_expires_at":       nil,
+				"runtime_id":             "",
+				"started_at":             nil,
+				"rate_limit_retry_after": retryAfter.UTC(),
+				"updated_at":             time.Now().UTC(),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrTaskClaimMismatch
+			}
+			// A decision that landed since the executor last read the request
+			// must not wait out retryAfter.
+			var row models.TaskApproval
+			if err := tx.Select("task_run_id", "decision").
+				Where("job_run_id = ? AND task_id = ?", runID, taskID).
+				First(&row).Error; err != nil {
+				return err
+			}
+			if row.Decision == models.ApprovalDecisionPending {
+				return nil
+			}
+			return clearGateRetryAfterTx(tx, []uuid.UUID{row.TaskRunID})
+		})
+	})
+}
+
+// clearGateRetryAfterTx makes released gated tasks claimable once their
+// requests are decided or expired.
+func clearGateRetryAfterTx(tx *gorm.DB, taskRunIDs []uuid.UUID) error {
+	if len(taskRunIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.TaskRun{}).
+		Where("id IN ? AND status = ? AND claimed_by = ''", taskRunIDs, string(TaskStatusAwaitingApproval)).
+		Update("rate_limit_retry_after", nil).Error
+}
+
+// ClaimableTaskStatuses are the statuses a worker claim or an owner dispatch
+// takes a ready task from: pending, and awaiting_approval for a gated task
+// released while its request was pending.
+func ClaimableTaskStatuses() []string {
+	return []string{string(TaskStatusPending), string(TaskStatusAwaitingApproval)}
+}
+
+// ClaimedTaskStatusSQL returns the SET expression for the status of a task
+// being claimed: running, except that a released gated task stays in
+// awaiting_approval until its executor re-checks the request. Its two bound
+// parameters are returned with it.
+func ClaimedTaskStatusSQL(column string) (string, []any) {
+	return "CASE WHEN " + column + " = ? THEN " + column + " ELSE ? END",
+		[]any{string(TaskStatusAwaitingApproval), string(TaskStatusRunning)}
+}
+
+// TaskGate returns the approval request of a task in a run, or
+// ErrGateNotFound when the task has not reached its gate (or has none).
+func (s *Store) TaskGate(runID, taskID uuid.UUID) (*TaskGate, error) {
+	var row models.TaskApproval
+	if err := s.db.Where("job_run_id = ? AND task_id = ?", runID, taskID).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGateNotFound
+		}
+		return nil, err
+	}
+	return convertTaskApprovalModel(&row), nil
+}
+
+// DecideGate records an approve or reject decision on a pending approval
+// request. It returns ErrGateNotPending when the request was already decided
+// or expired, so concurrent deciders cannot overwrite each other.
+func (s *Store) DecideGate(runID, taskID uuid.UUID, decision models.ApprovalDecision, decider, reason string) (*TaskGate, error) {
+	if decision != models.ApprovalDecisionApproved && decision != models.ApprovalDecisionRejected {
+		return nil, fmt.Errorf("run: invalid gate decision %q", decision)
+	}
+
+	var gate *TaskGate
+	err := withStoreBusyRetry(func() error {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			now := time.Now().UTC()
+			result := tx.Model(&models.TaskApproval{}).
+				Where("job_run_id = ? AND task_id = ? AND decision = ?", runID, taskID, models.ApprovalDecisionPending).
+				Updates(map[string]interface{}{
+					"decision":   decision,
+					"decider":    decider,
+					"reason":     reason,
+					"decided_at": now,
+					"updated_at": now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+
+			var row models.TaskApproval
+			if err := tx.Where("job_run_id = ? AND task_id = ?", runID, taskID).First(&row).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrGateNotFound
+				}
+				return err
+			}
+			if result.RowsAffected == 0 {
+				return ErrGateNotPending
+			}
+			gate = convertTaskApprovalModel(&row)
+			return clearGateRetryAfterTx(tx, []uuid.UUID{row.TaskRunID})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gate, nil
+}
+
+// ExpireGates marks every pending approval request whose deadline is at or
+// before now as expired and returns how many it resolved. The executors
+// waiting on those gates, or the workers that claim their released tasks
+// next, apply the step's onTimeout action.
+func (s *Store) ExpireGates(ctx context.Context, now time.Time) (int64, error) {
+	var expired int64
+	err := withStoreBusyRetryContext(ctx, func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var taskRunIDs []uuid.UUID
+			if err := tx.Model(&models.TaskApproval{}).
+				Where("decision = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.ApprovalDecisionPending, now.UTC()).
+				Pluck("task_run_id", &taskRunIDs).Error; err != nil {
+				return err
+			}
+			if len(taskRunIDs) == 0 {
+				expired = 0
+				return nil
+			}
+			result := tx.Model(&models.TaskApproval{}).
+				Where("task_run_id IN ? AND decision = ?", taskRunIDs, models.ApprovalDecisionPending).
+				Updates(map[string]interface{}{
+					"decision":   models.ApprovalDecisionExpired,
+					"reason":     "approval timed out",
+					"decided_at": now.UTC(),
+					"updated_at": now.UTC(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			expired = result.RowsAffected
+			return clearGateRetryAfterTx(tx, taskRunIDs)
+		})
+	})
+	return expired, err
+}
+
+// ResumeApprovedTask moves an approved task out of awaiting_approval: back to
+// running for a worker-claimed task (keeping its claim), or to pending for the
+// local executor, which starts the task itself. It returns
+// ErrTaskClaimMismatch when the task is terminal or was reclaimed.
+func (s *Store) ResumeApprovedTask(runID, taskID uuid.UUID, claimedBy string) error {
+	status := TaskStatusPending
+	if claimedBy != "" {
+		status = TaskStatusRunning
+	}
+	return withStoreBusyRetry(func() error {
+		query := s.db.Model(&models.TaskRun{}).
+			Where("job_run_id = ? AND task_id = ? AND status NOT IN ?", runID, taskID, terminalTaskStatuses())
+		if claimedBy != "" {
+			query = query.Where("claimed_by = ?", claimedBy)
+		}
+		result := query.Update("status", string(status))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskClaimMismatch
+		}
+		return nil
+	})
+}
+
+// SkipGatedTask skips a task whose gate expired with onTimeout: skip, then
+// skips any descendants whose trigger rules can no longer be satisfied, as a
+// branch skip would. It returns every task it skipped, the gated task first.
+func (s *Store) SkipGatedTask(runID, taskID uuid.UUID, reason, claimedBy string) ([]uuid.UUID, error) {
+	return s.skipHeldTask(runID, taskID, reason, claimedBy)
+}
+
+// skipHeldTask skips a task its executor is holding (a gate or sensor wait)
+// along with any descendants whose trigger rules can no longer be satisfied.
+// claimedBy fences the transition for worker-claimed tasks.
+func (s *Store) skipHeldTask(runID, taskID uuid.UUID, reason, claimedBy string) ([]uuid.UUID, error) {
+	var (
+		skipped       []uuid.UUID
+		pendingEvents []event.Event
+		counts        dbWriteCounts
+	)
+	err := withStoreBusyRetry(func() error {
+		counts.reset()
+		attemptEvents := make([]event.Event, 0, 1)
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			query := tx.Model(&models.TaskRun{}).
+				Where("job_run_id = ? AND task_id = ? AND status NOT IN ?", runID, taskID, terminalTaskStatuses())
+			if claimedBy != "" {
+				query = query.Where("claimed_by = ?", claimedBy)
+			}
+			result := query.Updates(map[string]interface{}{
+				"status":           string(TaskStatusPending),
+				"claimed_by":       "",
+				"claim_expires_at": nil,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrTaskClaimMismatch
+			}
+
+			var err error
+			skipped, err = s.skipTaskAndDescendantsTx(tx, runID, taskID, reason, &attemptEvents, &counts)
+			return err
+		})
+		if err == nil {
+			pendingEvents = attemptEvents
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts.commit()
+	s.publishEvents(pendingEvents...)
+	return skipped, nil
+}
+
+// expireRunGatesTx resolves a run's pending approval requests as expired, so
+// a cancelled run leaves no request open in the approvals queue.
+func expireRunGatesTx(tx *gorm.DB, runID uuid.UUID, reason string, now time.Time) error {
+	return tx.Model(&models.TaskApproval{}).
+		Where("job_run_id = ? AND decision = ?", runID, models.ApprovalDecisionPending).
+		Updates(map[string]interface{}{
+			"decision":   models.ApprovalDecisionExpired,
+			"reason":     reason,
+			"decided_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+// loadRunTaskGatesWithDB returns every approval request in the run keyed by
+// task ID.
+func loadRunTaskGatesWithDB(conn *gorm.DB, runID uuid.UUID) (map[uuid.UUID]*TaskGate, error) {
+	var rows []models.TaskApproval
+	if err := conn.Where("job_run_id = ?", runID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	byTask := make(map[uuid.UUID]*TaskGate, len(rows))
+	for i := range rows {
+		byTask[rows[i].TaskID] = convertTaskApprovalModel(&rows[i])
+	}
+	return byTask, nil
+}
+
+func convertTaskApprovalModel(model *models.TaskApproval) *TaskGate {
+	gate := &TaskGate{
+		ID:        model.ID,
+		OnTimeout: model.OnTimeout,
+		Decision:  model.Decision,
+		Decider:   model.Decider,
+		Reason:    model.Reason,
+		ExpiresAt: model.ExpiresAt,
+		DecidedAt: model.DecidedAt,
+		CreatedAt: model.CreatedAt,
+	}
+	if len(model.Approvers) > 0 {
+		var approvers []string
+		if err := json.Unmarshal(model.Approvers, &approvers); err == nil && len(approvers) > 0 {
+			gate.Approvers = approvers
+		}
+	}
+	return gate
+}
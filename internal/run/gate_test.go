@@ -0,0 +1,239 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwaitApprovalLifecycle(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	runID, taskID := registerSingleTaskRun(t, store, db)
+
+	_, err := store.TaskGate(runID, taskID)
+	require.ErrorIs(t, err, ErrGateNotFound)
+
+	gate, err := store.AwaitApproval(runID, taskID, "", GateRequest{
+		Approvers: []string{"release-managers"},
+		Timeout:   time.Hour,
+		OnTimeout: "skip",
+	})
+	require.NoError(t, err)
+	require.True(t, gate.Pending())
+	require.Equal(t, []string{"release-managers"}, gate.Approvers)
+	require.NotNil(t, gate.ExpiresAt)
+
+	runRecord, err := store.Get(runID)
+	require.NoError(t, err)
+	require.Equal(t, TaskStatusAwaitingApproval, runRecord.Tasks[0].Status)
+	require.NotNil(t, runRecord.Tasks[0].Gate)
+	require.Equal(t, gate.ID, runRecord.Tasks[0].Gate.ID)
+
+	// Resuming the wait (e.g. after a reclaim) reuses the open request.
+	resumed, err := store.AwaitApproval(runID, taskID, "", GateRequest{OnTimeout: "fail"})
+	require.NoError(t, err)
+	require.Equal(t, gate.ID, resumed.ID)
+	require.Equal(t, "skip", resumed.OnTimeout)
+
+	decided, err := store.DecideGate(runID, taskID, models.ApprovalDecisionApproved, "alice@example.com", "ship it")
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalDecisionApproved, decided.Decision)
+	require.Equal(t, "alice@example.com", decided.Decider)
+	require.NotNil(t, decided.DecidedAt)
+
+	_, err = store.DecideGate(runID, taskID, models.ApprovalDecisionRejected, "bob@example.com", "")
+	require.ErrorIs(t, err, ErrGateNotPending)
+	_, err = store.DecideGate(runID, uuid.New(), models.ApprovalDecisionApproved, "bob@example.com", "")
+	require.ErrorIs(t, err, ErrGateNotFound)
+
+	require.NoError(t, store.ResumeApprovedTask(runID, taskID, ""))
+	runRecord, err = store.Get(runID)
+	require.NoError(t, err)
+	require.Equal(t, TaskStatusPending, runRecord.Tasks[0].Status)
+}
+
+func TestExpireGatesOnlyExpiresOverdueRequests(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	overdueRun, overdueTask := registerSingleTaskRun(t, store, db)
+	openRun, openTask := registerSingleTaskRun(t, store, db)
+
+	_, err := store.AwaitApproval(overdueRun, overdueTask, "", GateRequest{Timeout: time.Minute, OnTimeout: "fail"})
+	require.NoError(t, err)
+	_, err = store.AwaitApproval(openRun, openTask, "", GateRequest{OnTimeout: "fail"})
+	require.NoError(t, err)
+
+	expired, err := store.ExpireGates(context.Background(), time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, expired)
+
+	gate, err := store.TaskGate(overdueRun, overdueTask)
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalDecisionExpired, gate.Decision)
+	gate, err = store.TaskGate(openRun, openTask)
+	require.NoError(t, err)
+	require.True(t, gate.Pending())
+}
+
+func TestSkipGatedTaskSkipsDescendants(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	jobID := uuid.New()
+
+	runRecord, err := store.Start(jobID, nil)
+	require.NoError(t, err)
+
+	atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["echo","ok"]`}
+	require.NoError(t, db.Create(atom).Error)
+	deploy := &models.Task{ID: uuid.New(), JobID: jobID, AtomID: atom.ID, Name: "deploy"}
+	verify := &models.Task{ID: uuid.New(), JobID: jobID, AtomID: atom.ID, Name: "verify", TriggerRule: "all_success"}
+	require.NoError(t, db.Create([]*models.Task{deploy, verify}).Error)
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.TaskEdge{ID: uuid.New(), JobID: jobID, FromTaskID: deploy.ID, ToTaskID: verify.ID, CreatedAt: now, UpdatedAt: now}).Error)
+	require.NoError(t, store.RegisterTask(runRecord.ID, deploy, atom, 0))
+	require.NoError(t, store.RegisterTask(runRecord.ID, verify, atom, 1))
+
+	_, err = store.AwaitApproval(runRecord.ID, deploy.ID, "", GateRequest{Timeout: time.Minute, OnTimeout: "skip"})
+	require.NoError(t, err)
+
+	skipped, err := store.SkipGatedTask(runRecord.ID, deploy.ID, "approval gate expired", "")
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{deploy.ID, verify.ID}, skipped)
+
+	loaded, err := store.Get(runRecord.ID)
+	require.NoError(t, err)
+	for _, task := range loaded.Tasks {
+		require.Equal(t, TaskStatusSkipped, task.Status, task.TaskID)
+	}
+}
+
+func TestCancelRunExpiresPendingGates(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	runID, taskID := registerSingleTaskRun(t, store, db)
+
+	_, err := store.AwaitApproval(runID, taskID, "", GateRequest{OnTimeout: "fail"})
+	require.NoError(t, err)
+	require.NoError(t, store.CancelRunWithReason(context.Background(), runID, "cancelled by alice"))
+
+	gate, err := store.TaskGate(runID, taskID)
+	require.NoError(t, err)
+	require.Equal(t, models.ApprovalDecisionExpired, gate.Decision)
+	require.Equal(t, "cancelled by alice", gate.Reason)
+
+	runRecord, err := store.Get(runID)
+	require.NoError(t, err)
+	require.Equal(t, TaskStatusCancelled, runRecord.Tasks[0].Status)
+}
+
+func TestAwaitApprovalRejectsForeignClaim(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	runID, taskID := registerSingleTaskRun(t, store, db)
+	var taskRun models.TaskRun
+	require.NoError(t, db.First(&taskRun, "job_run_id = ? AND task_id = ?", runID, taskID).Error)
+	require.NoError(t, db.Model(&taskRun).Updates(map[string]any{
+		"status":     string(TaskStatusRunning),
+		"claimed_by": "node-a",
+	}).Error)
+	taskRunID := taskRun.ID
+
+	_, err := store.AwaitApproval(taskRun.JobRunID, taskRun.TaskID, "node-b", GateRequest{OnTimeout: "fail"})
+	require.ErrorIs(t, err, ErrTaskClaimMismatch)
+
+	gate, err := store.AwaitApproval(taskRun.JobRunID, taskRun.TaskID, "node-a", GateRequest{OnTimeout: "fail"})
+	require.NoError(t, err)
+	require.True(t, gate.Pending())
+	require.NoError(t, db.First(&taskRun, "id = ?", taskRunID).Error)
+	require.Equal(t, string(TaskStatusAwaitingApproval), taskRun.Status)
+	require.Equal(t, "node-a", taskRun.ClaimedBy)
+
+	require.ErrorIs(t, store.ResumeApprovedTask(taskRun.JobRunID, taskRun.TaskID, "node-b"), ErrTaskClaimMismatch)
+	require.NoError(t, store.ResumeApprovedTask(taskRun.JobRunID, taskRun.TaskID, "node-a"))
+	require.NoError(t, db.First(&taskRun, "id = ?", taskRunID).Error)
+	require.Equal(t, string(TaskStatusRunning), taskRun.Status)
+}
+
+func TestReleaseGatedTaskRequeuesUntilDecided(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	ctx := context.Background()
+
+	runID, taskID := registerSingleTaskRun(t, store, db)
+	var taskRun models.TaskRun
+	require.NoError(t, db.First(&taskRun, "job_run_id = ? AND task_id = ?", runID, taskID).Error)
+	claim := func() {
+		require.NoError(t, db.Model(&models.TaskRun{}).Where("id = ?", taskRun.ID).Updates(map[string]any{
+			"status":     string(TaskStatusRunning),
+			"claimed_by": "node-a",
+		}).Error)
+	}
+	claim()
+
+	_, err := store.AwaitApproval(runID, taskID, "node-a", GateRequest{Timeout: time.Hour, OnTimeout: "fail"})
+	require.NoError(t, err)
+	retryAfter := time.Now().Add(time.Hour)
+	require.ErrorIs(t, store.ReleaseGatedTask(ctx, runID, taskID, "node-b", retryAfter), ErrTaskClaimMismatch)
+	require.NoError(t, store.ReleaseGatedTask(ctx, runID, taskID, "node-a", retryAfter))
+
+	require.NoError(t, db.First(&taskRun, "id = ?", taskRun.ID).Error)
+	require.Equal(t, string(TaskStatusAwaitingApproval), taskRun.Status)
+	require.Empty(t, taskRun.ClaimedBy)
+	require.NotNil(t, taskRun.RateLimitRetryAfter)
+
+	// The owner does not dispatch the released task before retryAfter.
+	ready, err := store.PendingTasksForDispatch(ctx, runID, 0)
+	require.NoError(t, err)
+	require.Empty(t, ready)
+
+	// Deciding the request makes the released task claimable at once.
+	_, err = store.DecideGate(runID, taskID, models.ApprovalDecisionApproved, "alice@example.com", "")
+	require.NoError(t, err)
+	require.NoError(t, db.First(&taskRun, "id = ?", taskRun.ID).Error)
+	require.Nil(t, taskRun.RateLimitRetryAfter)
+
+	// Claiming it keeps the status until the executor re-checks the request.
+	require.NoError(t, store.ClaimTaskForDispatch(runID, taskID, "node-a", 0, time.Minute, false))
+	require.NoError(t, db.First(&taskRun, "id = ?", taskRun.ID).Error)
+	require.Equal(t, string(TaskStatusAwaitingApproval), taskRun.Status)
+	require.Equal(t, "node-a", taskRun.ClaimedBy)
+
+	// A release that races a decision does not wait out retryAfter.
+	require.NoError(t, store.ReleaseGatedTask(ctx, runID, taskID, "node-a", retryAfter))
+	require.NoError(t, db.First(&taskRun, "id = ?", taskRun.ID).Error)
+	require.Nil(t, taskRun.RateLimitRetryAfter)
+}
+
+func TestExpireGatesMakesReleasedTasksClaimable(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	ctx := context.Background()
+
+	runID, taskID := registerSingleTaskRun(t, store, db)
+	_, err := store.AwaitApproval(runID, taskID, "", GateRequest{Timeout: time.Minute, OnTimeout: "fail"})
+	require.NoError(t, err)
+	require.NoError(t, store.ReleaseGatedTask(ctx, runID, taskID, "", time.Now().Add(time.Hour)))
+
+	expired, err := store.ExpireGates(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), expired)
+
+	var taskRun models.TaskRun
+	require.NoError(t, db.First(&taskRun, "job_run_id = ? AND task_id = ?", runID, taskID).Error)
+	require.Equal(t, string(TaskStatusAwaitingApproval), taskRun.Status)
+	require.Nil(t, taskRun.RateLimitRetryAfter)
+}
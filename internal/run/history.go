@@ -0,0 +1,174 @@
+package run
+
+import (
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/jsonmap"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// WithHistory lets the store's read methods fall through to the cold-history
+// database for runs the archiver has moved out of the store's own database.
+// A nil connection, or one that aliases the store's database, leaves reads on
+// the store's database only.
+func (s *Store) WithHistory(cold *gorm.DB) *Store {
+	s.history = nil
+	if cold != nil && cold != s.db {
+		s.history = cold
+	}
+	return s
+}
+
+// History returns the cold-history database, or nil when the store has none.
+func (s *Store) History() *gorm.DB {
+	return s.history
+}
+
+// loadHistoryRun loads an archived run with its tasks, instances, gates, and
+// callbacks. The history database holds no catalog tables, so job and
+// trigger details come from the store's database.
+func (s *Store) loadHistoryRun(runID uuid.UUID) (*JobRun, error) {
+	var model models.JobRun
+	if err := s.history.Preload("Tasks").First(&model, "id = ?", runID).Error; err != nil {
+		return nil, err
+	}
+	runValue, err := s.convertRunModelWithDB(s.history, &model)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.describeHistoryRuns([]*models.JobRun{&model}, []*JobRun{runValue}); err != nil {
+		return nil, err
+	}
+	return runValue, nil
+}
+
+// listHistoryRuns returns the archived runs matching query, oldest first,
+// limited to the newest limit of them when limit is positive. When full is false the runs carry their task runs but not instances, gates,
+// or callbacks, as ListByIDs does for the hot database.
+func (s *Store) listHistoryRuns(full bool, limit int, query string, args ...any) ([]*JobRun, error) {
+	var rows []*models.JobRun
+	tx := s.history.Where(query, args...).Order("started_at DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	if err := tx.Preload("Tasks").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	slices.Reverse(rows)
+
+	runs := make([]*JobRun, 0, len(rows))
+	for _, row := range rows {
+		var runValue *JobRun
+		if full {
+			var err error
+			if runValue, err = s.convertRunModelWithDB(s.history, row); err != nil {
+				return nil, err
+			}
+		} else {
+			runValue = newJobRun(row)
+			runValue.CacheHits, runValue.ExecutedTasks, runValue.TotalTasks = summarizeTasks(runValue.Tasks)
+		}
+		runs = append(runs, runValue)
+	}
+	if err := s.describeHistoryRuns(rows, runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// describeHistoryRuns fills the job alias and labels and the trigger details
+// that hot reads get by joining jobs and triggers. A job or trigger that was
+// since hard-deleted leaves the run's own snapshot of them in place.
+func (s *Store) describeHistoryRuns(rows []*models.JobRun, runs []*JobRun) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	jobIDs := make([]uuid.UUID, 0, len(rows))
+	triggerIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		jobIDs = append(jobIDs, row.JobID)
+		if row.TriggerID != uuid.Nil {
+			triggerIDs = append(triggerIDs, row.TriggerID)
+		}
+	}
+
+	var jobs []struct {
+		ID     uuid.UUID
+		Alias  string
+		Labels datatypes.JSONMap
+	}
+	if err := s.db.Unscoped().Model(&models.Job{}).
+		Select("id, alias, labels").
+		Where("id IN ?", jobIDs).
+		Find(&jobs).Error; err != nil {
+		return err
+	}
+	jobsByID := make(map[uuid.UUID]int, len(jobs))
+	for idx := range jobs {
+		jobsByID[jobs[idx].ID] = idx
+	}
+
+	triggersByID := make(map[uuid.UUID]models.Trigger)
+	if len(triggerIDs) > 0 {
+		var triggers []models.Trigger
+		if err := s.db.Unscoped().
+			Select("id, type, alias").
+			Where("id IN ?", triggerIDs).
+			Find(&triggers).Error; err != nil {
+			return err
+		}
+		for _, trigger := range triggers {
+			triggersByID[trigger.ID] = trigger
+		}
+	}
+
+	for idx, row := range rows {
+		runValue := runs[idx]
+		runValue.TriggerType = row.TriggerType
+		runValue.TriggerAlias = row.TriggerAlias
+		if trigger, ok := triggersByID[row.TriggerID]; ok {
+			runValue.TriggerType = string(trigger.Type)
+			runValue.TriggerAlias = trigger.Alias
+		}
+		if jobIdx, ok := jobsByID[row.JobID]; ok {
+			runValue.JobAlias = jobs[jobIdx].Alias
+			runValue.JobLabels = jsonmap.ToStringMap(jobs[jobIdx].Labels)
+		}
+		for _, task := range runValue.Tasks {
+			task.JobAlias = runValue.JobAlias
+			task.JobLabels = runValue.JobLabels
+		}
+	}
+	return nil
+}
+
+// mergeHistoryRuns adds archived runs that are not also in hot, which happens
+// briefly while the archiver moves a run, and keeps the result oldest first.
+func mergeHistoryRuns(hot, archived []*JobRun) []*JobRun {
+	if len(archived) == 0 {
+		return hot
+	}
+	seen := make(map[uuid.UUID]struct{}, len(hot))
+	for _, runValue := range hot {
+		seen[runValue.ID] = struct{}{}
+	}
+	merged := append([]*JobRun(nil), hot...)
+	for _, runValue := range archived {
+		if _, ok := seen[runValue.ID]; !ok {
+			merged = append(merged, runValue)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].StartedAt.Before(merged[j].StartedAt)
+	})
+	return merged
+}
+
+func (s *Store) fallsThroughToHistory(err error) bool {
+	return s.history != nil && errors.Is(err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,587 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/event"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// mapped.go persists the instances of mapped steps (`map:` in the job
+// definition). The step's TaskRun row stays the single group row the DAG,
+// trigger rules, and successors address; each item of the mapped list gets a
+// task_run_instances row that records its own status, attempt, output, and
+// cache identity. Executors expand the group once its predecessors are done,
+// run the instances, and then complete the group row through the ordinary
+// task completion paths.
+
+// TaskInstance is the run-payload view of one mapped-step instance.
+type TaskInstance struct {
+	ID               uuid.UUID         `json:"id"`
+	Index            int               `json:"index"`
+	Item             string            `json:"item"`
+	Status           TaskStatus        `json:"status"`
+	Attempt          int               `json:"attempt"`
+	RuntimeID        string            `json:"runtime_id,omitempty"`
+	Result           string            `json:"result,omitempty"`
+	Output           map[string]string `json:"output,omitempty"`
+	Error            string            `json:"error,omitempty"`
+	Hash             string            `json:"-"`
+	CacheHit         bool              `json:"cache_hit"`
+	CacheOriginRunID *uuid.UUID        `json:"cache_origin_run_id,omitempty"`
+	StartedAt        *time.Time        `json:"started_at,omitempty"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty"`
+}
+
+// TaskInstanceResult is the terminal outcome an executor records for one
+// instance. Cache is set when the instance was satisfied from the cache.
+type TaskInstanceResult struct {
+	Status TaskStatus
+	Result string
+	Output map[string]string
+	Error  string
+	Cache  *CacheHitSource
+}
+
+// ExpandMapTask materializes one instance per item under the mapped task's
+// group row and returns them ordered by index. It is idempotent: when the
+// group was already expanded with the same items (a resumed or retried run),
+// succeeded and cached instances are kept and every other instance is reset
+// to pending, so only unfinished items run again. A different item list
+// replaces the previous instances.
+func (s *Store) ExpandMapTask(runID, taskID uuid.UUID, items []string) ([]*TaskInstance, error) {
+	var expanded []models.TaskRunInstance
+	err := withStoreBusyRetry(func() error {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			var group models.TaskRun
+			if err := tx.Select("id", "job_run_id", "task_id").
+				Where("job_run_id = ? AND task_id = ? AND map_parent_id IS NULL", runID, taskID).
+				First(&group).Error; err != nil {
+				return fmt.Errorf("run: task %s not registered in run %s: %w", taskID, runID, err)
+			}
+			var err error
+			expanded, err = expandMapTaskTx(tx, &group, items)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertTaskInstanceModels(expanded), nil
+}
+
+// expandMapTaskTx is ExpandMapTask's body for an already loaded group row.
+func expandMapTaskTx(tx *gorm.DB, group *models.TaskRun, items []string) ([]models.TaskRunInstance, error) {
+	var existing []models.TaskRunInstance
+	if err := tx.Where("task_run_id = ?", group.ID).
+		Order("item_index ASC").
+		Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	if sameInstanceItems(existing, items) {
+		if err := tx.Model(&models.TaskRunInstance{}).
+			Where("task_run_id = ? AND status NOT IN ?", group.ID, []string{string(TaskStatusSucceeded), string(TaskStatusCached)}).
+			Updates(map[string]interface{}{
+				"status":              string(TaskStatusPending),
+				"attempt":             1,
+				"runtime_id":          "",
+				"result":              "",
+				"output":              nil,
+				"error":               "",
+				"cache_hit":           false,
+				"cache_origin_run_id": nil,
+				"started_at":          nil,
+				"completed_at":        nil,
+			}).Error; err != nil {
+			return nil, err
+		}
+		var expanded []models.TaskRunInstance
+		err := tx.Where("task_run_id = ?", group.ID).
+			Order("item_index ASC").
+			Find(&expanded).Error
+		return expanded, err
+	}
+
+	if len(existing) > 0 {
+		if err := tx.Where("task_run_id = ?", group.ID).
+			Delete(&models.TaskRunInstance{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	records := make([]models.TaskRunInstance, 0, len(items))
+	for idx, item := range items {
+		records = append(records, models.TaskRunInstance{
+			ID:        uuid.New(),
+			TaskRunID: group.ID,
+			JobRunID:  group.JobRunID,
+			TaskID:    group.TaskID,
+			Index:     idx,
+			Item:      item,
+			Status:    string(TaskStatusPending),
+			Attempt:   1,
+		})
+	}
+	if err := tx.CreateInBatches(&records, 200).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func sameInstanceItems(existing []models.TaskRunInstance, items []string) bool {
+	if len(existing) == 0 || len(existing) != len(items) {
+		return false
+	}
+	for idx := range existing {
+		if existing[idx].Index != idx || existing[idx].Item != items[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// StartTaskInstance marks an instance running on the given runtime and records
+// the attempt it is executing.
+func (s *Store) StartTaskInstance(id uuid.UUID, runtimeID string, attempt int) error {
+	now := time.Now().UTC()
+	return withStoreBusyRetry(func() error {
+		return s.db.Model(&models.TaskRunInstance{}).
+			Where("id = ? AND status NOT IN ?", id, terminalTaskStatuses()).
+			Updates(map[string]interface{}{
+				"status":     string(TaskStatusRunning),
+				"runtime_id": runtimeID,
+				"attempt":    attempt,
+				"started_at": now,
+			}).Error
+	})
+}
+
+// SetTaskInstanceHash persists an instance's cache identity and the
+// canonical hash-input blob that decomposes it.
+func (s *Store) SetTaskInstanceHash(id uuid.UUID, hash string, blob []byte) error {
+	updates := map[string]interface{}{"hash": hash}
+	if len(blob) > 0 {
+		updates["hash_input_blob"] = datatypes.JSON(blob)
+	}
+	return withStoreBusyRetry(func() error {
+		return s.db.Model(&models.TaskRunInstance{}).
+			Where("id = ?", id).
+			Updates(updates).Error
+	})
+}
+
+// SetTaskInstanceLogArchiveRef records where an instance's complete log is
+// archived. Like SetTaskLogArchiveRef it is set when archiving starts.
+func (s *Store) SetTaskInstanceLogArchiveRef(id uuid.UUID, ref string) error {
+	return withStoreBusyRetry(func() error {
+		return s.db.Model(&models.TaskRunInstance{}).
+			Where("id = ?", id).
+			Update("log_archive_ref", ref).Error
+	})
+}
+
+// CompleteTaskInstance records an instance's terminal outcome. An instance
+// that is already terminal (for example cancelled with its run) is left as is.
+func (s *Store) CompleteTaskInstance(id uuid.UUID, result TaskInstanceResult) error {
+	updates, err := taskInstanceResultUpdates(result)
+	if err != nil {
+		return err
+	}
+	return withStoreBusyRetry(func() error {
+		return s.db.Model(&models.TaskRunInstance{}).
+			Where("id = ? AND status NOT IN ?", id, terminalTaskStatuses()).
+			Updates(updates).Error
+	})
+}
+
+// taskInstanceResultUpdates is the column set that records an instance's
+// terminal outcome.
+func taskInstanceResultUpdates(result TaskInstanceResult) (map[string]interface{}, error) {
+	if !IsTerminal(result.Status) {
+		return nil, fmt.Errorf("run: instance status %q is not terminal", result.Status)
+	}
+	updates := map[string]interface{}{
+		"status":              string(result.Status),
+		"result":              result.Result,
+		"error":               result.Error,
+		"completed_at":        time.Now().UTC(),
+		"cache_hit":           result.Cache != nil,
+		"cache_origin_run_id": nil,
+	}
+	if len(result.Output) > 0 {
+		encoded, err := json.Marshal(result.Output)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling instance output: %w", err)
+		}
+		updates["output"] = datatypes.JSON(encoded)
+	}
+	if result.Cache != nil {
+		originRunID := result.Cache.RunID
+		updates["cache_origin_run_id"] = &originRunID
+	}
+	return updates, nil
+}
+
+// ExpandMapTaskRuns expands a claimed group like ExpandMapTask and gives each
+// unfinished instance its own task run (MapParentID set, ID shared with the
+// instance) that workers claim one at a time through Claimer.ClaimNext, so
+// every instance goes through pool admission, node placement, and lease
+// recovery on its own. At most maxParallel instance task runs exist at once
+// (0 means no limit); CompleteMapInstance materializes the next pending
+// instance as each one finishes. When any were created the group gives up its
+// claim and is marked MapExpanded. pending is the number created; with none
+// the group keeps its claim and the caller completes it directly.
+//
+// group.ClaimedBy fences the expansion: it returns ErrTaskClaimMismatch when
+// the group is no longer running under that claim.
+func (s *Store) ExpandMapTaskRuns(ctx context.Context, group *models.TaskRun, items []string, maxParallel int) ([]*TaskInstance, int, error) {
+	if group == nil {
+		return nil, 0, fmt.Errorf("run: map group is required")
+	}
+	var (
+		expanded      []models.TaskRunInstance
+		pending       int
+		pendingEvents []event.Event
+		counts        dbWriteCounts
+	)
+	err := withStoreBusyRetryContext(ctx, func() error {
+		expanded, pending, pendingEvents = nil, 0, nil
+		counts.reset()
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var current models.TaskRun
+			if err := tx.Where("id = ? AND status = ? AND claimed_by = ? AND map_parent_id IS NULL",
+				group.ID, string(TaskStatusRunning), group.ClaimedBy).
+				Take(&current).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrTaskClaimMismatch
+				}
+				return err
+			}
+			var err error
+			if expanded, err = expandMapTaskTx(tx, &current, items); err != nil {
+				return err
+			}
+
+			var unfinished []uuid.UUID
+			for i := range expanded {
+				if !IsTerminalSuccess(TaskStatus(expanded[i].Status)) {
+					unfinished = append(unfinished, expanded[i].ID)
+				}
+			}
+			if maxParallel > 0 && len(unfinished) > maxParallel {
+				unfinished = unfinished[:maxParallel]
+			}
+			if len(unfinished) == 0 {
+				return nil
+			}
+
+			now := time.Now().UTC()
+			if err := tx.Where("map_parent_id = ?", current.ID).
+				Delete(&models.TaskRun{}).Error; err != nil {
+				return err
+			}
+			result := tx.Model(&models.TaskRun{}).
+				Where("id = ? AND claimed_by = ?", current.ID, current.ClaimedBy).
+				Updates(map[string]interface{}{
+					"claimed_by":       "",
+					"claim_expires_at": nil,
+					"map_expanded":     true,
+					"updated_at":       now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrTaskClaimMismatch
+			}
+			rows := make([]models.TaskRun, 0, len(unfinished))
+			for _, id := range unfinished {
+				rows = append(rows, newMapInstanceRun(&current, id, now))
+			}
+			if err := tx.CreateInBatches(&rows, 200).Error; err != nil {
+				return err
+			}
+			counts.addTaskRunInsert(len(rows))
+			pending = len(rows)
+			return s.appendTaskReadyEventTx(tx, current.JobRunID, current.TaskID, &pendingEvents, &counts)
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	counts.commit()
+	s.publishEvents(pendingEvents...)
+	return convertTaskInstanceModels(expanded), pending, nil
+}
+
+// newMapInstanceRun clones a group row into the pending task run that executes
+// one of its instances. The clone keeps the group's placement, pool, cache,
+// and retry settings and starts with no claim or outcome of its own.
+func newMapInstanceRun(group *models.TaskRun, instanceID uuid.UUID, now time.Time) models.TaskRun {
+	row := *group
+	parentID := group.ID
+	row.ID = instanceID
+	row.JobRun = models.JobRun{}
+	row.Task = models.Task{}
+	row.MapParentID = &parentID
+	row.MapExpanded = false
+	row.Status = string(TaskStatusPending)
+	row.ClaimedBy = ""
+	row.ClaimExpiresAt = nil
+	row.ClaimAttempt = 0
+	row.RateLimitRetryAfter = nil
+	row.Attempt = 1
+	row.OutstandingPredecessors = 0
+	row.RuntimeID = ""
+	row.Hash = ""
+	row.EffectiveHash = ""
+	row.HashInputBlob = nil
+	row.ResolvedImageDigest = ""
+	row.Result = ""
+	row.Error = ""
+	row.Output = nil
+	row.BranchSelections = nil
+	row.SchemaViolations = nil
+	row.ExitCode = nil
+	row.CacheHit = false
+	row.CacheOriginRunID = nil
+	row.CacheCreatedAt = nil
+	row.CacheExpiresAt = nil
+	row.LogText = ""
+	row.LogTruncated = false
+	row.LogArchiveRef = ""
+	row.TerminalSequence = 0
+	row.StartedAt = nil
+	row.CompletedAt = nil
+	row.CreatedAt = now
+	row.UpdatedAt = now
+	return row
+}
+
+// MapInstance returns the instance an instance task run executes and the
+// number of instances in its group.
+func (s *Store) MapInstance(ctx context.Context, id uuid.UUID) (*TaskInstance, int, error) {
+	var row models.TaskRunInstance
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Take(&row).Error; err != nil {
+		return nil, 0, err
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.TaskRunInstance{}).
+		Where("task_run_id = ?", row.TaskRunID).
+		Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	return convertTaskInstanceModel(&row), int(count), nil
+}
+
+// CompleteMapInstance records the outcome of an instance executed through its
+// own task run, deletes that task run, and materializes the group's next
+// pending instance. When no instance task runs remain the group goes back to
+// pending with MapExpanded still set, so its next claim collects the
+// outcomes and completes it. A crash between instances therefore loses no
+// work: unfinished instance task runs are reclaimed like any other task.
+//
+// The instance task run's claim fences the write; it returns
+// ErrTaskClaimMismatch when the row was reclaimed or removed with its run.
+func (s *Store) CompleteMapInstance(ctx context.Context, instanceRun *models.TaskRun, result TaskInstanceResult) error {
+	if instanceRun == nil || instanceRun.MapParentID == nil {
+		return fmt.Errorf("run: task run is not a map instance")
+	}
+	updates, err := taskInstanceResultUpdates(result)
+	if err != nil {
+		return err
+	}
+	parentID := *instanceRun.MapParentID
+
+	var (
+		pendingEvents []event.Event
+		counts        dbWriteCounts
+	)
+	err = withStoreBusyRetryContext(ctx, func() error {
+		pendingEvents = nil
+		counts.reset()
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			now := time.Now().UTC()
+			// Touching the group first serializes concurrent completions of
+			// its instances, so exactly one of them sees the last one finish.
+			live := tx.Model(&models.TaskRun{}).
+				Where("id = ? AND status = ? AND map_expanded = ?", parentID, string(TaskStatusRunning), true).
+				Update("updated_at", now)
+			if live.Error != nil {
+				return live.Error
+			}
+
+			deleted := tx.Where("id = ? AND claimed_by = ?", instanceRun.ID, instanceRun.ClaimedBy).
+				Delete(&models.TaskRun{})
+			if deleted.Error != nil {
+				return deleted.Error
+			}
+			if deleted.RowsAffected == 0 {
+				return ErrTaskClaimMismatch
+			}
+			if err := tx.Model(&models.TaskRunInstance{}).
+				Where("id = ? AND status NOT IN ?", instanceRun.ID, terminalTaskStatuses()).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+			if live.RowsAffected == 0 {
+				// The group was cancelled or completed; nothing waits on it.
+				return nil
+			}
+
+			var group models.TaskRun
+			if err := tx.Where("id = ?", parentID).Take(&group).Error; err != nil {
+				return err
+			}
+			var next models.TaskRunInstance
+			err := tx.Where("task_run_id = ? AND status = ? AND id NOT IN (?)",
+				group.ID, string(TaskStatusPending),
+				tx.Model(&models.TaskRun{}).Select("id").Where("map_parent_id = ?", group.ID)).
+				Order("item_index ASC").
+				Take(&next).Error
+			switch {
+			case err == nil:
+				row := newMapInstanceRun(&group, next.ID, now)
+				if err := tx.Create(&row).Error; err != nil {
+					return err
+				}
+				counts.addTaskRunInsert(1)
+				return s.appendTaskReadyEventTx(tx, group.JobRunID, group.TaskID, &pendingEvents, &counts)
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return err
+			}
+
+			var remaining int64
+			if err := tx.Model(&models.TaskRun{}).
+				Where("map_parent_id = ?", group.ID).
+				Count(&remaining).Error; err != nil {
+				return err
+			}
+			if remaining > 0 {
+				return nil
+			}
+			if err := tx.Model(&models.TaskRun{}).
+				Where("id = ?", group.ID).
+				Updates(map[string]interface{}{
+					"status":           string(TaskStatusPending),
+					"claimed_by":       "",
+					"claim_expires_at": nil,
+					"updated_at":       now,
+				}).Error; err != nil {
+				return err
+			}
+			counts.addTaskRunStatus(1)
+			return s.appendTaskReadyEventTx(tx, group.JobRunID, group.TaskID, &pendingEvents, &counts)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	counts.commit()
+	s.publishEvents(pendingEvents...)
+	return nil
+}
+
+// deleteMapInstanceRunsTx removes the instance task runs left in a run that
+// is ending; their instances keep the recorded outcomes.
+func deleteMapInstanceRunsTx(tx *gorm.DB, runID uuid.UUID) error {
+	return tx.Where("job_run_id = ? AND map_parent_id IS NOT NULL", runID).
+		Delete(&models.TaskRun{}).Error
+}
+
+// TaskInstances returns the instances of a mapped task in a run, ordered by
+// index. It is empty for unmapped tasks and for groups not yet expanded.
+func (s *Store) TaskInstances(runID, taskID uuid.UUID) ([]*TaskInstance, error) {
+	var rows []models.TaskRunInstance
+	if err := s.db.Where("job_run_id = ? AND task_id = ?", runID, taskID).
+		Order("item_index ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return convertTaskInstanceModels(rows), nil
+}
+
+// loadRunTaskInstancesWithDB returns every instance in the run grouped by
+// task ID, each group ordered by index.
+func loadRunTaskInstancesWithDB(conn *gorm.DB, runID uuid.UUID) (map[uuid.UUID][]*TaskInstance, error) {
+	var rows []models.TaskRunInstance
+	if err := conn.Where("job_run_id = ?", runID).
+		Order("task_id ASC, item_index ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	byTask := make(map[uuid.UUID][]*TaskInstance)
+	for i := range rows {
+		byTask[rows[i].TaskID] = append(byTask[rows[i].TaskID], convertTaskInstanceModel(&rows[i]))
+	}
+	return byTask, nil
+}
+
+func convertTaskInstanceModels(rows []models.TaskRunInstance) []*TaskInstance {
+	out := make([]*TaskInstance, 0, len(rows))
+	for i := range rows {
+		out = append(out, convertTaskInstanceModel(&rows[i]))
+	}
+	return out
+}
+
+func convertTaskInstanceModel(model *models.TaskRunInstance) *TaskInstance {
+	inst := &TaskInstance{
+		ID:          model.ID,
+		Index:       model.Index,
+		Item:        model.Item,
+		Status:      TaskStatus(model.Status),
+		Attempt:     model.Attempt,
+		RuntimeID:   model.RuntimeID,
+		Result:      model.Result,
+		Error:       model.Error,
+		Hash:        model.Hash,
+		CacheHit:    model.CacheHit || TaskStatus(model.Status) == TaskStatusCached,
+		StartedAt:   model.StartedAt,
+		CompletedAt: model.CompletedAt,
+	}
+	if model.CacheOriginRunID != nil {
+		originRunID := *model.CacheOriginRunID
+		inst.CacheOriginRunID = &originRunID
+	}
+	if len(model.Output) > 0 {
+		var out map[string]string
+		if err := json.Unmarshal(model.Output, &out); err == nil {
+			inst.Output = out
+		}
+	}
+	return inst
+}
+
+// SummarizeInstances counts a group's instances by outcome: succeeded
+// (including cached), of which cached, failed, and everything else still
+// pending or running.
+func SummarizeInstances(instances []*TaskInstance) (succeeded, cached, failed, remaining int) {
+	for _, inst := range instances {
+		switch {
+		case inst.Status == TaskStatusCached || (inst.CacheHit && IsTerminalSuccess(inst.Status)):
+			succeeded++
+			cached++
+		case inst.Status == TaskStatusSucceeded:
+			succeeded++
+		case IsTerminal(inst.Status):
+			failed++
+		default:
+			remaining++
+		}
+	}
+	return succeeded, cached, failed, remaining
+}
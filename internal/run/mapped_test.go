@@ -0,0 +1,282 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/cache"
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestExpandMapTaskIsIdempotent(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	jobID := uuid.New()
+	taskID := seedRunDiffTask(t, db, jobID, "process")
+	runID := seedRunDiffRun(t, db, jobID, time.Now().UTC(), "manual", "", nil)
+	seedRunDiffTaskRun(t, db, runID, taskID, 1, cache.HashInput{TaskName: "process"}, string(TaskStatusRunning), nil)
+
+	instances, err := store.ExpandMapTask(runID, taskID, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Len(t, instances, 3)
+	for idx, inst := range instances {
+		require.Equal(t, idx, inst.Index)
+		require.Equal(t, TaskStatusPending, inst.Status)
+	}
+	require.Equal(t, "b", instances[1].Item)
+
+	require.ErrorContains(t, store.CompleteTaskInstance(instances[0].ID, TaskInstanceResult{Status: TaskStatusRunning}), "not terminal")
+	require.NoError(t, store.StartTaskInstance(instances[0].ID, "atom-0", 1))
+	require.NoError(t, store.CompleteTaskInstance(instances[0].ID, TaskInstanceResult{Status: TaskStatusSucceeded, Result: "success", Output: map[string]string{"rows": "3"}}))
+	require.NoError(t, store.CompleteTaskInstance(instances[1].ID, TaskInstanceResult{Status: TaskStatusFailed, Error: "boom"}))
+
+	// Re-expanding with the same items keeps finished work and resets the rest.
+	again, err := store.ExpandMapTask(runID, taskID, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Len(t, again, 3)
+	require.Equal(t, instances[0].ID, again[0].ID)
+	require.Equal(t, TaskStatusSucceeded, again[0].Status)
+	require.Equal(t, map[string]string{"rows": "3"}, again[0].Output)
+	require.Equal(t, instances[1].ID, again[1].ID)
+	require.Equal(t, TaskStatusPending, again[1].Status)
+	require.Empty(t, again[1].Error)
+
+	// A different list replaces the instances.
+	replaced, err := store.ExpandMapTask(runID, taskID, []string{"a", "d"})
+	require.NoError(t, err)
+	require.Len(t, replaced, 2)
+	require.NotEqual(t, instances[0].ID, replaced[0].ID)
+	require.Equal(t, TaskStatusPending, replaced[0].Status)
+
+	runRecord, err := store.Get(runID)
+	require.NoError(t, err)
+	require.Len(t, runRecord.Tasks, 1)
+	require.Len(t, runRecord.Tasks[0].Instances, 2)
+	require.Equal(t, "d", runRecord.Tasks[0].Instances[1].Item)
+
+	succeeded, cached, failed, remaining := SummarizeInstances(again)
+	require.Equal(t, []int{1, 0, 0, 2}, []int{succeeded, cached, failed, remaining})
+}
+
+func TestExpandMapTaskEmptyList(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	jobID := uuid.New()
+	taskID := seedRunDiffTask(t, db, jobID, "process")
+	runID := seedRunDiffRun(t, db, jobID, time.Now().UTC(), "manual", "", nil)
+	seedRunDiffTaskRun(t, db, runID, taskID, 1, cache.HashInput{TaskName: "process"}, string(TaskStatusRunning), nil)
+
+	instances, err := store.ExpandMapTask(runID, taskID, nil)
+	require.NoError(t, err)
+	require.Empty(t, instances)
+
+	_, err = store.ExpandMapTask(runID, uuid.New(), []string{"a"})
+	require.ErrorContains(t, err, "not registered")
+}
+
+func TestExpandMapTaskRunsCompletesThroughInstanceTaskRuns(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	ctx := context.Background()
+
+	jobID := uuid.New()
+	taskID := seedRunDiffTask(t, db, jobID, "process")
+	runID := seedRunDiffRun(t, db, jobID, time.Now().UTC(), "manual", "", nil)
+	require.NoError(t, db.Model(&models.JobRun{}).Where("id = ?", runID).Update("status", string(StatusRunning)).Error)
+	groupID := seedRunDiffTaskRun(t, db, runID, taskID, 1, cache.HashInput{TaskName: "process"}, string(TaskStatusRunning), nil)
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("id = ?", groupID).Update("claimed_by", "node-a").Error)
+
+	var group models.TaskRun
+	require.NoError(t, db.First(&group, "id = ?", groupID).Error)
+	stale := group
+	stale.ClaimedBy = "node-b"
+	_, _, err := store.ExpandMapTaskRuns(ctx, &stale, []string{"a", "b"}, 0)
+	require.ErrorIs(t, err, ErrTaskClaimMismatch)
+
+	instances, pending, err := store.ExpandMapTaskRuns(ctx, &group, []string{"a", "b", "c"}, 2)
+	require.NoError(t, err)
+	require.Len(t, instances, 3)
+	require.Equal(t, 2, pending)
+
+	require.NoError(t, db.First(&group, "id = ?", groupID).Error)
+	require.True(t, group.MapExpanded)
+	require.Empty(t, group.ClaimedBy)
+
+	var children []models.TaskRun
+	require.NoError(t, db.Where("map_parent_id = ?", groupID).Order("created_at ASC").Find(&children).Error)
+	require.Len(t, children, 2)
+	for _, child := range children {
+		require.Equal(t, string(TaskStatusPending), child.Status)
+		require.Equal(t, taskID, child.TaskID)
+	}
+
+	// The run payload keeps reporting one task per step.
+	runRecord, err := store.Get(runID)
+	require.NoError(t, err)
+	require.Len(t, runRecord.Tasks, 1)
+	require.Equal(t, groupID, runRecord.Tasks[0].ID)
+
+	// Completing an instance needs its task run's claim, and queues the next
+	// pending instance in its place.
+	first := children[0]
+	require.ErrorIs(t, store.CompleteMapInstance(ctx, &first, TaskInstanceResult{Status: TaskStatusSucceeded}), ErrTaskClaimMismatch)
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("id = ?", first.ID).Updates(map[string]interface{}{"status": string(TaskStatusRunning), "claimed_by": "node-a"}).Error)
+	first.ClaimedBy = "node-a"
+	require.NoError(t, store.CompleteMapInstance(ctx, &first, TaskInstanceResult{Status: TaskStatusSucceeded, Result: "success", Output: map[string]string{"rows": "1"}}))
+
+	require.NoError(t, db.Where("map_parent_id = ?", groupID).Order("created_at ASC").Find(&children).Error)
+	require.Len(t, children, 2)
+	require.NotContains(t, []uuid.UUID{children[0].ID, children[1].ID}, first.ID)
+	require.Contains(t, []uuid.UUID{children[0].ID, children[1].ID}, instances[2].ID)
+
+	for _, child := range children {
+		require.NoError(t, db.Model(&models.TaskRun{}).Where("id = ?", child.ID).Updates(map[string]interface{}{"status": string(TaskStatusRunning), "claimed_by": "node-b"}).Error)
+		child.ClaimedBy = "node-b"
+		require.NoError(t, store.CompleteMapInstance(ctx, &child, TaskInstanceResult{Status: TaskStatusFailed, Error: "boom"}))
+	}
+
+	// With every instance recorded the group is queued again to collect.
+	var count int64
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("map_parent_id = ?", groupID).Count(&count).Error)
+	require.Zero(t, count)
+	require.NoError(t, db.First(&group, "id = ?", groupID).Error)
+	require.Equal(t, string(TaskStatusPending), group.Status)
+	require.True(t, group.MapExpanded)
+
+	recorded, err := store.TaskInstances(runID, taskID)
+	require.NoError(t, err)
+	succeeded, _, failed, remaining := SummarizeInstances(recorded)
+	require.Equal(t, []int{1, 2, 0}, []int{succeeded, failed, remaining})
+}
+
+func TestCancelRunDeletesMapInstanceTaskRuns(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	ctx := context.Background()
+
+	jobID := uuid.New()
+	taskID := seedRunDiffTask(t, db, jobID, "process")
+	runID := seedRunDiffRun(t, db, jobID, time.Now().UTC(), "manual", "", nil)
+	require.NoError(t, db.Model(&models.JobRun{}).Where("id = ?", runID).Update("status", string(StatusRunning)).Error)
+	groupID := seedRunDiffTaskRun(t, db, runID, taskID, 1, cache.HashInput{TaskName: "process"}, string(TaskStatusRunning), nil)
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("id = ?", groupID).Update("claimed_by", "node-a").Error)
+
+	var group models.TaskRun
+	require.NoError(t, db.First(&group, "id = ?", groupID).Error)
+	instances, pending, err := store.ExpandMapTaskRuns(ctx, &group, []string{"a", "b"}, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, pending)
+
+	require.NoError(t, store.CancelRun(ctx, runID))
+
+	var count int64
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("job_run_id = ? AND map_parent_id IS NOT NULL", runID).Count(&count).Error)
+	require.Zero(t, count)
+
+	// Workers still executing a deleted instance task run see it as cancelled.
+	cancelled, err := store.CancelledTaskRuns(ctx, []uuid.UUID{instances[0].ID, instances[1].ID, groupID})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{instances[0].ID, instances[1].ID, groupID}, cancelled)
+
+	recorded, err := store.TaskInstances(runID, taskID)
+	require.NoError(t, err)
+	for _, inst := range recorded {
+		require.Equal(t, TaskStatusCancelled, inst.Status)
+	}
+}
+
+func TestDiffRuns_MappedPairsInstancesByItem(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	ctx := context.Background()
+
+	jobID := uuid.New()
+	taskID := seedRunDiffTask(t, db, jobID, "process")
+	base := time.Now().UTC()
+	leftRunID := seedRunDiffRun(t, db, jobID, base, "manual", "", nil)
+	rightRunID := seedRunDiffRun(t, db, jobID, base.Add(time.Hour), "manual", "", nil)
+	group := cache.HashInput{TaskName: "process", Image: "alpine:3.23"}
+	leftGroupID := seedRunDiffTaskRun(t, db, leftRunID, taskID, 1, group, string(TaskStatusSucceeded), nil)
+	rightGroupID := seedRunDiffTaskRun(t, db, rightRunID, taskID, 1, group, string(TaskStatusSucceeded), nil)
+
+	instanceInput := func(item, image string) cache.HashInput {
+		return cache.HashInput{TaskName: "process", Image: image, MapItem: &item}
+	}
+	seedRunDiffInstance(t, db, leftRunID, taskID, leftGroupID, 0, instanceInput("a", "alpine:3.23"))
+	seedRunDiffInstance(t, db, leftRunID, taskID, leftGroupID, 1, instanceInput("b", "alpine:3.23"))
+	seedRunDiffInstance(t, db, leftRunID, taskID, leftGroupID, 2, instanceInput("gone", "alpine:3.23"))
+	// The producer reordered its list: indexes shift but items still pair.
+	seedRunDiffInstance(t, db, rightRunID, taskID, rightGroupID, 0, instanceInput("b", "alpine:3.23"))
+	seedRunDiffInstance(t, db, rightRunID, taskID, rightGroupID, 1, instanceInput("a", "example.com/etl:v2"))
+	seedRunDiffInstance(t, db, rightRunID, taskID, rightGroupID, 2, instanceInput("new", "alpine:3.23"))
+
+	diff, err := store.DiffRuns(ctx, jobID, leftRunID, rightRunID)
+	require.NoError(t, err)
+	require.Len(t, diff.Tasks, 1)
+	task := diff.Tasks[0]
+	require.Equal(t, RunDiffVerdictReran, task.Verdict)
+	require.False(t, task.HashEqual)
+	require.Empty(t, task.Degraded)
+	require.Equal(t, []string{"new"}, task.ItemsAdded)
+	require.Equal(t, []string{"gone"}, task.ItemsRemoved)
+	require.Len(t, task.Instances, 2)
+
+	require.Equal(t, "b", task.Instances[0].Item)
+	require.Equal(t, 1, task.Instances[0].LeftIndex)
+	require.Equal(t, 0, task.Instances[0].RightIndex)
+	require.Equal(t, RunDiffVerdictWouldCacheHit, task.Instances[0].Verdict)
+
+	require.Equal(t, "a", task.Instances[1].Item)
+	require.Equal(t, RunDiffVerdictReran, task.Instances[1].Verdict)
+	change, ok := findChange(task.Instances[1].Changes, "image")
+	require.True(t, ok, "expected image change, got %+v", task.Instances[1].Changes)
+	require.Equal(t, "example.com/etl:v2", change.After)
+
+	exp, err := store.WhyTask(ctx, rightRunID, "process")
+	require.NoError(t, err)
+	require.Len(t, exp.Instances, 3)
+	require.Equal(t, `mapped step "process" has 3 instance(s): 0 cached, 3 executed, 0 failed`, exp.Summary)
+	a := exp.Instances[1]
+	require.Equal(t, "a", a.Item)
+	require.Equal(t, VerdictCacheMiss, a.Verdict)
+	require.Equal(t, "prior_run", a.Baseline.Kind)
+	require.Equal(t, leftRunID, *a.Baseline.RunID)
+	_, ok = findChange(a.Diff.Changes, "image")
+	require.True(t, ok, "expected image change, got %+v", a.Diff.Changes)
+	require.Equal(t, "none", exp.Instances[2].Baseline.Kind, "a new item has no prior instance")
+}
+
+func seedRunDiffInstance(t *testing.T, db *gorm.DB, runID, taskID, groupID uuid.UUID, index int, input cache.HashInput) {
+	t.Helper()
+
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.TaskRunInstance{
+		ID:            uuid.New(),
+		TaskRunID:     groupID,
+		JobRunID:      runID,
+		TaskID:        taskID,
+		Index:         index,
+		Item:          *input.MapItem,
+		Status:        string(TaskStatusSucceeded),
+		Attempt:       1,
+		Hash:          input.Compute(),
+		HashInputBlob: datatypes.JSON(blobBytes(t, input)),
+		StartedAt:     &now,
+		CompletedAt:   &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}).Error)
+}
@@ -0,0 +1,142 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStartEnforcesNamespaceRunQuota(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	setNamespaceQuotas(t, `{"team-a":{"maxRuns":2}}`)
+
+	store := NewStore(db)
+	first := createNamespacedJob(t, db, "team-a", "extract", 1)
+	second := createNamespacedJob(t, db, "team-a", "load", 1)
+	other := createNamespacedJob(t, db, "team-b", "extract", 1)
+
+	firstRun, err := store.Start(first.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, "team-a", firstRun.Namespace)
+	_, err = store.Start(second.ID, nil)
+	require.NoError(t, err)
+
+	_, err = store.Start(first.ID, nil)
+	require.ErrorIs(t, err, ErrNamespaceQuotaExceeded)
+	require.ErrorIs(t, err, ErrMaxConcurrentRunsReached)
+
+	// The quota is per namespace.
+	_, err = store.Start(other.ID, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Complete(firstRun.ID, nil))
+	_, err = store.Start(first.ID, nil)
+	require.NoError(t, err)
+}
+
+func TestStartEnforcesNamespaceTaskQuota(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	setNamespaceQuotas(t, `{"team-a":{"maxTasks":4}}`)
+
+	store := NewStore(db)
+	wide := createNamespacedJob(t, db, "team-a", "wide", 3)
+	narrow := createNamespacedJob(t, db, "team-a", "narrow", 1)
+
+	_, err := store.Start(wide.ID, nil)
+	require.NoError(t, err)
+	// The first run has not registered its tasks yet, so it holds all three.
+	_, err = store.Start(wide.ID, nil)
+	require.ErrorIs(t, err, ErrNamespaceQuotaExceeded)
+	_, err = store.Start(narrow.ID, nil)
+	require.NoError(t, err)
+	_, err = store.Start(narrow.ID, nil)
+	require.ErrorIs(t, err, ErrNamespaceQuotaExceeded)
+}
+
+func TestStartSkipsOnNamespaceQuota(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	setNamespaceQuotas(t, `{"team-a":{"maxRuns":1}}`)
+
+	store := NewStore(db)
+	busy := createNamespacedJob(t, db, "team-a", "busy", 1)
+	job := createNamespacedJob(t, db, "team-a", "skipper", 1)
+	require.NoError(t, db.Model(job).Update("concurrency", `{"maxRuns":2,"strategy":"skip"}`).Error)
+
+	_, err := store.Start(busy.ID, nil)
+	require.NoError(t, err)
+	_, err = store.Start(job.ID, nil)
+	require.ErrorIs(t, err, ErrRunSkipped)
+}
+
+func TestStartForBackfillCountsAgainstNamespaceQuota(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	setNamespaceQuotas(t, `{"team-a":{"maxRuns":2}}`)
+
+	store := NewStore(db)
+	job := createNamespacedJob(t, db, "team-a", "backfilled", 1)
+	// The job's own maxRuns still excludes backfill runs.
+	require.NoError(t, db.Model(job).Update("concurrency", `{"maxRuns":1,"strategy":"fail"}`).Error)
+
+	backfillRun, err := store.StartForBackfill(job.ID, uuid.New(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "team-a", backfillRun.Namespace)
+	_, err = store.Start(job.ID, nil)
+	require.NoError(t, err)
+
+	_, err = store.StartForBackfill(job.ID, uuid.New(), nil)
+	require.ErrorIs(t, err, ErrNamespaceQuotaExceeded)
+
+	require.NoError(t, store.Complete(backfillRun.ID, nil))
+	_, err = store.StartForBackfill(job.ID, uuid.New(), nil)
+	require.NoError(t, err)
+}
+
+// setNamespaceQuotas applies CAESIUM_NAMESPACE_QUOTAS for the test and
+// reprocesses the environment once it is restored.
+func setNamespaceQuotas(t *testing.T, value string) {
+	t.Helper()
+	t.Cleanup(func() { _ = env.Process() })
+	t.Setenv("CAESIUM_NAMESPACE_QUOTAS", value)
+	require.NoError(t, env.Process())
+}
+
+func createNamespacedJob(t *testing.T, db *gorm.DB, namespace, alias string, tasks int) *models.Job {
+	t.Helper()
+	now := time.Now().UTC()
+	trigger := &models.Trigger{
+		ID:            uuid.New(),
+		Namespace:     namespace,
+		Alias:         alias + "-trigger",
+		Type:          models.TriggerTypeCron,
+		Configuration: `{"cron":"0 * * * *","timezone":"UTC"}`,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, db.Create(trigger).Error)
+	job := &models.Job{
+		ID:        uuid.New(),
+		Namespace: jobdef.NamespaceOrDefault(namespace),
+		Alias:     alias,
+		TriggerID: trigger.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(job).Error)
+	for i := 0; i < tasks; i++ {
+		atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(atom).Error)
+		require.NoError(t, db.Create(&models.Task{ID: uuid.New(), JobID: job.ID, AtomID: atom.ID, CreatedAt: now, UpdatedAt: now}).Error)
+	}
+	return job
+}
@@ -0,0 +1,179 @@
+package run
+
+import (
+	"context"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PoolOccupancySQL returns a predicate over the task run aliased as alias
+// that is true while the task run holds slots of its pool: it is running or
+// parked at an approval gate, or pending between retry attempts under a live
+// claim. An expanded map group holds no slots; its instance task runs do.
+// Its single bound parameter is now.
+func PoolOccupancySQL(alias string) string {
+	return "(" + alias + ".map_expanded IS NOT TRUE AND (" + alias + ".status IN ('" + string(TaskStatusRunning) + "', '" + string(TaskStatusAwaitingApproval) + "')" +
+		" OR (" + alias + ".status = '" + string(TaskStatusPending) + "' AND " + alias + ".claimed_by <> '' AND " + alias + ".claim_expires_at > ?)))"
+}
+
+// PoolAdmissionSQL returns a predicate over the task run aliased as alias
+// that is true when the task may start under its pool. A task outside any
+// pool is always admitted. A pooled task is admitted only when its pool
+// exists, the slots held by the pool's other task runs leave room for
+// PoolSlots, and no other ready task in the pool outranks it by priority,
+// then age. Tasks that ask for more slots than the pool has never outrank
+// anyone, so an oversized task cannot stall its pool. An expanded map group
+// is admitted without a slot check: claiming it only collects the outcomes
+// of its instance task runs, which were admitted on their own.
+//
+// The predicate is evaluated inside the claiming UPDATE, and dqlite
+// serializes writes, so concurrent claims on different nodes can never
+// over-commit a pool.
+func PoolAdmissionSQL(alias string, now time.Time) (string, []any) {
+	sql := "(" + alias + ".pool = '' OR " + alias + ".map_expanded IS TRUE OR EXISTS (SELECT 1 FROM pools AS p WHERE p.name = " + alias + ".pool" +
+		" AND " + alias + ".pool_slots + (SELECT COALESCE(SUM(o.pool_slots), 0) FROM task_runs AS o" +
+		" WHERE o.pool = " + alias + ".pool AND o.id <> " + alias + ".id AND " + PoolOccupancySQL("o") + ") <= p.slots" +
+		" AND NOT EXISTS (SELECT 1 FROM task_runs AS w JOIN job_runs AS wjr ON wjr.id = w.job_run_id" +
+		" WHERE w.pool = " + alias + ".pool AND w.id <> " + alias + ".id AND w.pool_slots <= p.slots" +
+		" AND wjr.status = ? AND w.status = ? AND w.outstanding_predecessors = 0 AND w.map_expanded IS NOT TRUE" +
+		" AND (w.claimed_by = '' OR w.claim_expires_at IS NULL OR w.claim_expires_at < ?)" +
+		" AND (w.rate_limit_retry_after IS NULL OR w.rate_limit_retry_after <= ?)" +
+		" AND (w.priority > " + alias + ".priority OR (w.priority = " + alias + ".priority AND w.created_at < " + alias + ".created_at)))))"
+	return sql, []any{now, string(StatusRunning), string(TaskStatusPending), now, now}
+}
+
+// AdmitPoolTask marks a pending or running task as running once its pool has
+// room for it, and reports whether the task may start. Tasks outside any pool
+// are always admitted. The local executor calls it before each attempt; a
+// task that is already running re-checks against the other holders only, so
+// re-admission is a no-op unless the pool shrank.
+func (s *Store) AdmitPoolTask(ctx context.Context, runID, taskID uuid.UUID) (bool, error) {
+	var admitted bool
+	err := withStoreBusyRetry(func() error {
+		var taskRun models.TaskRun
+		if err := s.db.WithContext(ctx).Select("pool").
+			Where("job_run_id = ? AND task_id = ?", runID, taskID).
+			Take(&taskRun).Error; err != nil {
+			return err
+		}
+		if taskRun.Pool == "" {
+			admitted = true
+			return nil
+		}
+
+		now := time.Now().UTC()
+		admission, args := PoolAdmissionSQL("task_runs", now)
+		result := s.db.WithContext(ctx).Model(&models.TaskRun{}).
+			Where("job_run_id = ? AND task_id = ? AND status IN ?", runID, taskID, []string{string(TaskStatusPending), string(TaskStatusRunning)}).
+			Where(admission, args...).
+			Updates(map[string]interface{}{
+				"status":     string(TaskStatusRunning),
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		admitted = result.RowsAffected > 0
+		return nil
+	})
+	return admitted, err
+}
+
+// WaitForPoolSlot blocks until AdmitPoolTask admits the task or ctx ends,
+// polling every interval.
+func (s *Store) WaitForPoolSlot(ctx context.Context, runID, taskID uuid.UUID, interval time.Duration) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		admitted, err := s.AdmitPoolTask(ctx, runID, taskID)
+		if err != nil {
+			return err
+		}
+		if admitted {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// poolSlots returns the slots a task occupies in its pool.
+func poolSlots(task *models.Task) int {
+	if task.PoolName == "" {
+		return 0
+	}
+	return max(task.PoolSlots, 1)
+}
+
+// PoolUsage is the occupancy of one pool.
+type PoolUsage struct {
+	Name          string `json:"name"`
+	Slots         int    `json:"slots"`
+	OccupiedSlots int    `json:"occupied_slots"`
+	RunningTasks  int    `json:"running_tasks"`
+	QueuedTasks   int    `json:"queued_tasks"`
+}
+
+// PoolUsages reports every pool's capacity, occupied slots, and queue depth:
+// the ready, unclaimed tasks of running runs that are waiting on the pool.
+func PoolUsages(ctx context.Context, db *gorm.DB) ([]PoolUsage, error) {
+	var pools []models.Pool
+	if err := db.WithContext(ctx).Order("name ASC").Find(&pools).Error; err != nil {
+		return nil, err
+	}
+	if len(pools) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	var held []struct {
+		Pool  string
+		Slots int
+		Tasks int
+	}
+	if err := db.WithContext(ctx).Table("task_runs AS tr").
+		Select("tr.pool AS pool, COALESCE(SUM(tr.pool_slots), 0) AS slots, COUNT(*) AS tasks").
+		Where("tr.pool <> '' AND "+PoolOccupancySQL("tr"), now).
+		Group("tr.pool").
+		Scan(&held).Error; err != nil {
+		return nil, err
+	}
+	var queued []struct {
+		Pool  string
+		Tasks int
+	}
+	if err := db.WithContext(ctx).Table("task_runs AS tr").
+		Select("tr.pool AS pool, COUNT(*) AS tasks").
+		Joins("JOIN job_runs AS jr ON jr.id = tr.job_run_id").
+		Where("tr.pool <> '' AND jr.status = ? AND tr.status = ? AND tr.claimed_by = '' AND tr.outstanding_predecessors = 0", string(StatusRunning), string(TaskStatusPending)).
+		Group("tr.pool").
+		Scan(&queued).Error; err != nil {
+		return nil, err
+	}
+
+	usages := make([]PoolUsage, len(pools))
+	index := make(map[string]int, len(pools))
+	for i, pool := range pools {
+		usages[i] = PoolUsage{Name: pool.Name, Slots: pool.Slots}
+		index[pool.Name] = i
+	}
+	for _, row := range held {
+		if i, ok := index[row.Pool]; ok {
+			usages[i].OccupiedSlots = row.Slots
+			usages[i].RunningTasks = row.Tasks
+		}
+	}
+	for _, row := range queued {
+		if i, ok := index[row.Pool]; ok {
+			usages[i].QueuedTasks = row.Tasks
+		}
+	}
+	return usages, nil
+}
@@ -0,0 +1,90 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func registerPooledTaskRun(t *testing.T, store *Store, db *gorm.DB, pool string, slots int) (runID, taskID uuid.UUID) {
+	t.Helper()
+	jobID := uuid.New()
+	runRecord, err := store.Start(jobID, nil)
+	require.NoError(t, err)
+
+	atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["echo","load"]`}
+	require.NoError(t, db.Create(atom).Error)
+	task := &models.Task{ID: uuid.New(), JobID: jobID, AtomID: atom.ID, Name: "load", PoolName: pool, PoolSlots: slots}
+	require.NoError(t, db.Create(task).Error)
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+	return runRecord.ID, task.ID
+}
+
+func TestRegisterTaskSnapshotsPool(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	runID, taskID := registerPooledTaskRun(t, store, db, "warehouse", 0)
+	var taskRun models.TaskRun
+	require.NoError(t, db.First(&taskRun, "job_run_id = ? AND task_id = ?", runID, taskID).Error)
+	require.Equal(t, "warehouse", taskRun.Pool)
+	require.Equal(t, 1, taskRun.PoolSlots, "a pooled task occupies at least one slot")
+}
+
+func TestAdmitPoolTaskHonoursSlots(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.Pool{Name: "warehouse", Slots: 2, CreatedAt: now, UpdatedAt: now}).Error)
+
+	firstRun, firstTask := registerPooledTaskRun(t, store, db, "warehouse", 2)
+	secondRun, secondTask := registerPooledTaskRun(t, store, db, "warehouse", 1)
+	plainRun, plainTask := registerSingleTaskRun(t, store, db)
+
+	admitted, err := store.AdmitPoolTask(ctx, plainRun, plainTask)
+	require.NoError(t, err)
+	require.True(t, admitted)
+
+	admitted, err = store.AdmitPoolTask(ctx, firstRun, firstTask)
+	require.NoError(t, err)
+	require.True(t, admitted)
+	admitted, err = store.AdmitPoolTask(ctx, secondRun, secondTask)
+	require.NoError(t, err)
+	require.False(t, admitted)
+
+	// Re-admitting a running task checks it against the other holders only.
+	admitted, err = store.AdmitPoolTask(ctx, firstRun, firstTask)
+	require.NoError(t, err)
+	require.True(t, admitted)
+
+	usages, err := PoolUsages(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []PoolUsage{{Name: "warehouse", Slots: 2, OccupiedSlots: 2, RunningTasks: 1, QueuedTasks: 1}}, usages)
+
+	err = store.ClaimTaskForDispatch(secondRun, secondTask, "node-b", 0, time.Minute, false)
+	require.ErrorIs(t, err, ErrTaskClaimMismatch, "owner dispatch must respect pool slots")
+
+	require.NoError(t, store.CompleteTask(firstRun, firstTask, "success", nil, nil))
+	require.NoError(t, store.ClaimTaskForDispatch(secondRun, secondTask, "node-b", 0, time.Minute, false))
+}
+
+func TestWaitForPoolSlotHonoursContext(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+
+	// No pool named "missing" exists, so the task can never be admitted.
+	runID, taskID := registerPooledTaskRun(t, store, db, "missing", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, store.WaitForPoolSlot(ctx, runID, taskID, 10*time.Millisecond), context.DeadlineExceeded)
+}
@@ -71,6 +71,26 @@ type RunDiffTask struct {
 	HashEqual bool           `json:"hashEqual"`
 	Changes   []FieldChange  `json:"changes,omitempty"`
 	Degraded  string         `json:"degraded,omitempty"`
+
+	// Instances pairs a mapped step's instances by item value (indexes shift
+	// whenever the producer reorders its list). ItemsAdded and ItemsRemoved
+	// list items present on only one side.
+	Instances    []RunDiffInstance `json:"instances,omitempty"`
+	ItemsAdded   []string          `json:"itemsAdded,omitempty"`
+	ItemsRemoved []string          `json:"itemsRemoved,omitempty"`
+}
+
+// RunDiffInstance is one paired mapped-step instance in a RunDiffTask.
+type RunDiffInstance struct {
+	Item        string         `json:"item"`
+	LeftIndex   int            `json:"leftIndex"`
+	RightIndex  int            `json:"rightIndex"`
+	LeftStatus  TaskStatus     `json:"leftStatus"`
+	RightStatus TaskStatus     `json:"rightStatus"`
+	Verdict     RunDiffVerdict `json:"verdict"`
+	HashEqual   bool           `json:"hashEqual"`
+	Changes     []FieldChange  `json:"changes,omitempty"`
+	Degraded    string         `json:"degraded,omitempty"`
 }
 
 var (
@@ -113,6 +133,15 @@ func (s *Store) DiffRuns(ctx context.Context, jobID, leftRunID, rightRunID uuid.
 		return nil, err
 	}
 
+	leftInstances, err := loadRunDiffInstances(db, leftRunID)
+	if err != nil {
+		return nil, err
+	}
+	rightInstances, err := loadRunDiffInstances(db, rightRunID)
+	if err != nil {
+		return nil, err
+	}
+
 	leftTrigger := s.loadTrigger(ctx, leftRun)
 	rightTrigger := s.loadTrigger(ctx, rightRun)
 
@@ -139,7 +168,12 @@ func (s *Store) DiffRuns(ctx context.Context, jobID, leftRunID, rightRunID uuid.
 		case !rightOK:
 			out.TasksRemoved = append(out.TasksRemoved, taskName)
 		default:
-			out.Tasks = append(out.Tasks, diffRunTask(taskName, leftTask.TaskRun, rightTask.TaskRun))
+			task := diffRunTask(taskName, leftTask.TaskRun, rightTask.TaskRun)
+			leftGroup, rightGroup := leftInstances[leftTask.ID], rightInstances[rightTask.ID]
+			if len(leftGroup) > 0 || len(rightGroup) > 0 {
+				diffMappedRunTask(&task, leftGroup, rightGroup)
+			}
+			out.Tasks = append(out.Tasks, task)
 		}
 	}
 
@@ -283,6 +317,92 @@ func diffRunTask(taskName string, left, right models.TaskRun) RunDiffTask {
 	return base
 }
 
+// loadRunDiffInstances returns the run's mapped-step instances grouped by
+// their group task-run ID, each group ordered by index.
+func loadRunDiffInstances(db *gorm.DB, runID uuid.UUID) (map[uuid.UUID][]models.TaskRunInstance, error) {
+	var rows []models.TaskRunInstance
+	if err := db.Where("job_run_id = ?", runID).
+		Order("item_index ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byGroup := make(map[uuid.UUID][]models.TaskRunInstance)
+	for _, row := range rows {
+		byGroup[row.TaskRunID] = append(byGroup[row.TaskRunID], row)
+	}
+	return byGroup, nil
+}
+
+// diffMappedRunTask pairs the instances of a mapped step by item and diffs
+// each pair's blob. The group rows carry no blob of their own, so the group
+// verdict is derived: it would have cache-hit only when the item sets match
+// and every pair is hash-equal.
+func diffMappedRunTask(task *RunDiffTask, left, right []models.TaskRunInstance) {
+	// Items may repeat; pair the n-th occurrence on each side.
+	leftByItem := make(map[string][]models.TaskRunInstance, len(left))
+	for _, inst := range left {
+		leftByItem[inst.Item] = append(leftByItem[inst.Item], inst)
+	}
+
+	allEqual := true
+	degraded := false
+	for _, r := range right {
+		queue := leftByItem[r.Item]
+		if len(queue) == 0 {
+			task.ItemsAdded = append(task.ItemsAdded, r.Item)
+			continue
+		}
+		l := queue[0]
+		leftByItem[r.Item] = queue[1:]
+
+		pair := RunDiffInstance{
+			Item:        r.Item,
+			LeftIndex:   l.Index,
+			RightIndex:  r.Index,
+			LeftStatus:  TaskStatus(l.Status),
+			RightStatus: TaskStatus(r.Status),
+		}
+		// Right is the subject, left the baseline; see diffRunTask.
+		diff, err := DiffHashInputBlobs(r.HashInputBlob, l.HashInputBlob)
+		if err != nil {
+			pair.Verdict = RunDiffVerdictDegraded
+			pair.Degraded = fmt.Sprintf("decode hash-input blob: %v", err)
+		} else {
+			pair.Verdict = classifyRunDiffVerdict(diff)
+			pair.HashEqual = diff.HashEqual
+			pair.Changes = diff.Changes
+			pair.Degraded = diff.Degraded
+		}
+		switch pair.Verdict {
+		case RunDiffVerdictDegraded:
+			degraded = true
+			allEqual = false
+		case RunDiffVerdictReran:
+			allEqual = false
+		}
+		task.Instances = append(task.Instances, pair)
+	}
+	for _, l := range left {
+		if queue := leftByItem[l.Item]; len(queue) > 0 && queue[0].ID == l.ID {
+			task.ItemsRemoved = append(task.ItemsRemoved, l.Item)
+			leftByItem[l.Item] = queue[1:]
+		}
+	}
+
+	task.Changes = nil
+	task.Degraded = ""
+	task.HashEqual = allEqual && len(task.ItemsAdded) == 0 && len(task.ItemsRemoved) == 0
+	switch {
+	case task.HashEqual:
+		task.Verdict = RunDiffVerdictWouldCacheHit
+	case degraded && len(task.ItemsAdded) == 0 && len(task.ItemsRemoved) == 0:
+		task.Verdict = RunDiffVerdictDegraded
+		task.Degraded = "one or more mapped instances could not be diffed"
+	default:
+		task.Verdict = RunDiffVerdictReran
+	}
+}
+
 func classifyRunDiffVerdict(diff *BlobDiff) RunDiffVerdict {
 	if diff == nil || diff.Degraded != "" {
 		return RunDiffVerdictDegraded
@@ -0,0 +1,141 @@
+package run
+
+import (
+	"context"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sensor.go persists the pokes of sensor steps (`type: sensor`). Each probe
+// evaluation appends a sensor_pokes row to the task run; the earliest row
+// anchors the sensor's timeout, so a sensor that releases its claim between
+// pokes (mode: reschedule) or moves to another node keeps its deadline.
+
+// SensorPoke is the run-payload view of one evaluation of a sensor's probe.
+type SensorPoke struct {
+	Probe     string    `json:"probe"`
+	Satisfied bool      `json:"satisfied"`
+	Detail    string    `json:"detail,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// RecordSensorPoke appends a poke to the task's run and returns the time of
+// the task run's first poke, which is poke.At when this is the first.
+func (s *Store) RecordSensorPoke(ctx context.Context, runID, taskID uuid.UUID, poke SensorPoke) (time.Time, error) {
+	if poke.At.IsZero() {
+		poke.At = time.Now()
+	}
+	poke.At = poke.At.UTC()
+
+	var first time.Time
+	err := withStoreBusyRetryContext(ctx, func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var taskRun models.TaskRun
+			if err := tx.Select("id").Where("job_run_id = ? AND task_id = ?", runID, taskID).Take(&taskRun).Error; err != nil {
+				return err
+			}
+			row := models.SensorPoke{
+				ID:        uuid.New(),
+				TaskRunID: taskRun.ID,
+				JobRunID:  runID,
+				TaskID:    taskID,
+				Probe:     poke.Probe,
+				Satisfied: poke.Satisfied,
+				Detail:    poke.Detail,
+				CreatedAt: poke.At,
+			}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+
+			var earliest models.SensorPoke
+			if err := tx.Select("created_at").Where("task_run_id = ?", taskRun.ID).
+				Order("created_at ASC").Take(&earliest).Error; err != nil {
+				return err
+			}
+			first = earliest.CreatedAt.UTC()
+			return nil
+		})
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return first, nil
+}
+
+// SensorPokes returns how many times a task was poked in the run and up to
+// limit of its most recent pokes, oldest first. A limit <= 0 returns them all.
+func (s *Store) SensorPokes(ctx context.Context, runID, taskID uuid.UUID, limit int) (int64, []SensorPoke, error) {
+	db := s.db.WithContext(ctx)
+	var count int64
+	if err := db.Model(&models.SensorPoke{}).
+		Where("job_run_id = ? AND task_id = ?", runID, taskID).
+		Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	query := db.Where("job_run_id = ? AND task_id = ?", runID, taskID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var rows []models.SensorPoke
+	if err := query.Find(&rows).Error; err != nil {
+		return 0, nil, err
+	}
+	pokes := make([]SensorPoke, len(rows))
+	for i := range rows {
+		row := &rows[len(rows)-1-i]
+		pokes[i] = SensorPoke{
+			Probe:     row.Probe,
+			Satisfied: row.Satisfied,
+			Detail:    row.Detail,
+			At:        row.CreatedAt.UTC(),
+		}
+	}
+	return count, pokes, nil
+}
+
+// RescheduleSensorTask releases a sensor task between pokes: the task goes
+// back to pending with no claim, and worker claims and owner dispatch leave it
+// alone until retryAfter, as they do for a rate-limited task. claimedBy fences
+// the transition for worker-claimed tasks; it returns ErrTaskClaimMismatch
+// when the task is terminal or was reclaimed.
+func (s *Store) RescheduleSensorTask(ctx context.Context, runID, taskID uuid.UUID, claimedBy string, retryAfter time.Time) error {
+	return withStoreBusyRetryContext(ctx, func() error {
+		query := s.db.WithContext(ctx).Model(&models.TaskRun{}).
+			Where("job_run_id = ? AND task_id = ? AND status IN ?", runID, taskID,
+				[]string{string(TaskStatusPending), string(TaskStatusRunning)})
+		if claimedBy != "" {
+			query = query.Where("claimed_by = ?", claimedBy)
+		}
+		result := query.Updates(map[string]interface{}{
+			"status":                 string(TaskStatusPending),
+			"claimed_by":             "",
+			"claim_expires_at":       nil,
+			"runtime_id":             "",
+			"started_at":             nil,
+			"rate_limit_retry_after": retryAfter.UTC(),
+			"updated_at":             time.Now().UTC(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskClaimMismatch
+		}
+		return nil
+	})
+}
+
+// SkipSensorTask skips a sensor task whose timeout passed with onTimeout:
+// skip, then skips any descendants whose trigger rules can no longer be
+// satisfied. It returns every task it skipped, the sensor task first.
+func (s *Store) SkipSensorTask(runID, taskID uuid.UUID, reason, claimedBy string) ([]uuid.UUID, error) {
+	return s.skipHeldTask(runID, taskID, reason, claimedBy)
+}
@@ -0,0 +1,81 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSensorPokeAnchorsOnFirstPoke(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	runID, taskID := registerSingleTaskRun(t, store, db)
+	ctx := context.Background()
+
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		first, err := store.RecordSensorPoke(ctx, runID, taskID, SensorPoke{
+			Probe:  "http",
+			Detail: "GET https://example.com returned 503",
+			At:     start.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+		require.True(t, first.Equal(start), "first poke %s, want %s", first, start)
+	}
+	_, err := store.RecordSensorPoke(ctx, runID, taskID, SensorPoke{Probe: "http", Satisfied: true, At: start.Add(3 * time.Minute)})
+	require.NoError(t, err)
+
+	count, pokes, err := store.SensorPokes(ctx, runID, taskID, 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, count)
+	require.Len(t, pokes, 2)
+	require.False(t, pokes[0].Satisfied)
+	require.True(t, pokes[1].Satisfied)
+	require.True(t, pokes[1].At.Equal(start.Add(3*time.Minute)))
+
+	exp, err := store.WhyTask(ctx, runID, taskID.String())
+	require.NoError(t, err)
+	require.EqualValues(t, 4, exp.PokeCount)
+	require.Len(t, exp.Pokes, 4)
+	require.Contains(t, exp.Summary, "after 4 http poke(s); last poke satisfied")
+
+	// A retry starts the sensor's timeout afresh.
+	require.NoError(t, store.RetryTask(runID, taskID, 2))
+	count, _, err = store.SensorPokes(ctx, runID, taskID, 0)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestRescheduleSensorTaskReleasesClaim(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := NewStore(db)
+	runID, taskID := registerSingleTaskRun(t, store, db)
+	ctx := context.Background()
+
+	require.NoError(t, db.Model(&models.TaskRun{}).
+		Where("job_run_id = ? AND task_id = ?", runID, taskID).
+		Updates(map[string]interface{}{"status": string(TaskStatusRunning), "claimed_by": "node-a"}).Error)
+
+	retryAfter := time.Now().UTC().Add(5 * time.Minute)
+	require.ErrorIs(t, store.RescheduleSensorTask(ctx, runID, taskID, "node-b", retryAfter), ErrTaskClaimMismatch)
+	require.NoError(t, store.RescheduleSensorTask(ctx, runID, taskID, "node-a", retryAfter))
+
+	var taskRun models.TaskRun
+	require.NoError(t, db.Where("job_run_id = ? AND task_id = ?", runID, taskID).Take(&taskRun).Error)
+	require.Equal(t, string(TaskStatusPending), taskRun.Status)
+	require.Empty(t, taskRun.ClaimedBy)
+	require.Nil(t, taskRun.StartedAt)
+	require.NotNil(t, taskRun.RateLimitRetryAfter)
+	require.WithinDuration(t, retryAfter, *taskRun.RateLimitRetryAfter, time.Second)
+
+	skipped, err := store.SkipSensorTask(runID, taskID, "sensor timed out after 1h0m0s: no succeeded run", "")
+	require.NoError(t, err)
+	require.Equal(t, taskID, skipped[0])
+	require.ErrorIs(t, store.RescheduleSensorTask(ctx, runID, taskID, "", retryAfter), ErrTaskClaimMismatch)
+}
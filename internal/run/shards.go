@@ -0,0 +1,59 @@
+package run
+
+import (
+	"context"
+
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/pkg/db"
+)
+
+// ShardReport is the row counts of the databases that can hold hot run rows.
+type ShardReport struct {
+	// Shards is the current CAESIUM_DATABASE_SHARDS.
+	Shards    int            `json:"shards"`
+	Databases []ShardSummary `json:"databases"`
+	// Runs counts every run in the reported databases.
+	Runs int64 `json:"runs"`
+}
+
+// ShardSummary is one database in a ShardReport.
+type ShardSummary struct {
+	Name string `json:"name"`
+	// Tables is the row count of every hot run table in the database.
+	Tables map[string]int64 `json:"tables"`
+}
+
+// ReportShards counts the hot run tables in the catalog and in every hot
+// shard among databases. The run store still keeps every run in the catalog,
+// so with more than one shard the hot shards report no rows. Cold history is
+// not reported.
+func ReportShards(ctx context.Context, shards int, databases []db.NamedDatabase) (*ShardReport, error) {
+	report := &ShardReport{Shards: shards}
+	for _, database := range databases {
+		if database.Role == db.DatabaseRoleCold {
+			continue
+		}
+		summary := ShardSummary{Name: database.Name, Tables: make(map[string]int64)}
+		for _, table := range []struct {
+			name  string
+			model any
+		}{
+			{"job_runs", &models.JobRun{}},
+			{"task_runs", &models.TaskRun{}},
+			{"task_run_instances", &models.TaskRunInstance{}},
+			{"task_approvals", &models.TaskApproval{}},
+			{"sensor_pokes", &models.SensorPoke{}},
+			{"callback_runs", &models.CallbackRun{}},
+			{"execution_events", &models.ExecutionEvent{}},
+		} {
+			var count int64
+			if err := database.Conn.WithContext(ctx).Model(table.model).Count(&count).Error; err != nil {
+				return nil, err
+			}
+			summary.Tables[table.name] = count
+		}
+		report.Runs += summary.Tables["job_runs"]
+		report.Databases = append(report.Databases, summary)
+	}
+	return report, nil
+}
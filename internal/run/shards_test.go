@@ -0,0 +1,41 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/pkg/db"
+	"github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportShardsCountsCatalogAndHotShards(t *testing.T) {
+	catalog := testutil.OpenTestDB(t)
+	hot := testutil.OpenTestDB(t)
+	history := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(catalog)
+		testutil.CloseDB(hot)
+		testutil.CloseDB(history)
+	})
+
+	job := createConcurrencyJob(t, catalog, "sharded", jobdef.ConcurrencyStrategyQueue, 1)
+	seedArchiveRun(t, catalog, job.ID, StatusSucceeded, time.Now().UTC().Add(-time.Hour))
+	seedRunningRun(t, catalog, job.ID)
+
+	report, err := ReportShards(context.Background(), 2, []db.NamedDatabase{
+		{Name: "caesium", Role: db.DatabaseRoleCatalog, Conn: catalog},
+		{Name: "caesium_hot_00", Role: db.DatabaseRoleHot, Conn: hot},
+		{Name: "caesium_history", Role: db.DatabaseRoleCold, Conn: history},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Shards)
+	require.EqualValues(t, 2, report.Runs)
+	require.Len(t, report.Databases, 2, "cold history is not reported")
+	require.Equal(t, "caesium", report.Databases[0].Name)
+	require.EqualValues(t, 2, report.Databases[0].Tables["job_runs"])
+	require.Equal(t, "caesium_hot_00", report.Databases[1].Name)
+	require.Zero(t, report.Databases[1].Tables["job_runs"])
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"maps"
 	"math/rand/v2"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/container"
 	"github.com/caesium-cloud/caesium/pkg/db"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
@@ -26,6 +28,8 @@ import (
 	"github.com/caesium-cloud/caesium/pkg/log"
 	pkgtask "github.com/caesium-cloud/caesium/pkg/task"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -51,6 +55,9 @@ const (
 	TaskStatusSkipped   TaskStatus = "skipped"
 	TaskStatusCached    TaskStatus = "cached"
 	TaskStatusCancelled TaskStatus = TaskStatus(models.TaskRunStatusCancelled)
+	// TaskStatusAwaitingApproval marks a gated step whose dependencies are
+	// satisfied but which is parked until its approval request is decided.
+	TaskStatusAwaitingApproval TaskStatus = "awaiting_approval"
 )
 
 // IsTerminalSuccess returns true for task statuses that represent successful completion.
@@ -102,41 +109,50 @@ type CallbackRun struct {
 }
 
 type TaskRun struct {
-	ID                      uuid.UUID                 `json:"id"`
-	JobRunID                uuid.UUID                 `json:"job_run_id"`
-	TaskID                  uuid.UUID                 `json:"task_id"`
-	JobAlias                string                    `json:"job_alias,omitempty"`
-	JobLabels               map[string]string         `json:"job_labels,omitempty"`
-	AtomID                  uuid.UUID                 `json:"atom_id"`
-	Engine                  models.AtomEngine         `json:"engine"`
-	Image                   string                    `json:"image"`
-	Command                 []string                  `json:"command"`
-	RuntimeID               string                    `json:"runtime_id,omitempty"`
-	Status                  TaskStatus                `json:"status"`
-	Priority                int                       `json:"priority"`
-	NodeSelector            map[string]string         `json:"node_selector,omitempty"`
-	ClaimedBy               string                    `json:"claimed_by,omitempty"`
-	ClaimExpiresAt          *time.Time                `json:"claim_expires_at,omitempty"`
-	ClaimAttempt            int                       `json:"claim_attempt"`
-	Attempt                 int                       `json:"attempt"`
-	MaxAttempts             int                       `json:"max_attempts"`
-	Result                  string                    `json:"result,omitempty"`
-	Output                  map[string]string         `json:"output,omitempty"`
-	SchemaViolations        []pkgtask.SchemaViolation `json:"schema_violations,omitempty"`
-	BranchSelections        []string                  `json:"branch_selections,omitempty"`
-	Quarantine              bool                      `json:"quarantine"`
-	CacheHit                bool                      `json:"cache_hit"`
-	ReplaySafe              bool                      `json:"replay_safe"`
-	CacheOriginRunID        *uuid.UUID                `json:"cache_origin_run_id,omitempty"`
-	CacheCreatedAt          *time.Time                `json:"cache_created_at,omitempty"`
-	CacheExpiresAt          *time.Time                `json:"cache_expires_at,omitempty"`
-	RateLimitRetryAfter     *time.Time                `json:"rate_limit_retry_after,omitempty"`
-	StartedAt               *time.Time                `json:"started_at,omitempty"`
-	CompletedAt             *time.Time                `json:"completed_at,omitempty"`
-	Error                   string                    `json:"error,omitempty"`
-	OutstandingPredecessors int                       `json:"outstanding_predecessors"`
-	CreatedAt               time.Time                 `json:"created_at"`
-	UpdatedAt               time.Time                 `json:"updated_at"`
+	ID                      uuid.UUID                  `json:"id"`
+	JobRunID                uuid.UUID                  `json:"job_run_id"`
+	TaskID                  uuid.UUID                  `json:"task_id"`
+	JobAlias                string                     `json:"job_alias,omitempty"`
+	JobLabels               map[string]string          `json:"job_labels,omitempty"`
+	AtomID                  uuid.UUID                  `json:"atom_id"`
+	Engine                  models.AtomEngine          `json:"engine"`
+	Image                   string                     `json:"image"`
+	Command                 []string                   `json:"command"`
+	RuntimeID               string                     `json:"runtime_id,omitempty"`
+	Status                  TaskStatus                 `json:"status"`
+	Priority                int                        `json:"priority"`
+	NodeSelector            map[string]string          `json:"node_selector,omitempty"`
+	NodeAffinity            *jobdefschema.NodeAffinity `json:"node_affinity,omitempty"`
+	ClaimedBy               string                     `json:"claimed_by,omitempty"`
+	ClaimExpiresAt          *time.Time                 `json:"claim_expires_at,omitempty"`
+	ClaimAttempt            int                        `json:"claim_attempt"`
+	Attempt                 int                        `json:"attempt"`
+	MaxAttempts             int                        `json:"max_attempts"`
+	Result                  string                     `json:"result,omitempty"`
+	Output                  map[string]string          `json:"output,omitempty"`
+	SchemaViolations        []pkgtask.SchemaViolation  `json:"schema_violations,omitempty"`
+	BranchSelections        []string                   `json:"branch_selections,omitempty"`
+	Quarantine              bool                       `json:"quarantine"`
+	CacheHit                bool                       `json:"cache_hit"`
+	ReplaySafe              bool                       `json:"replay_safe"`
+	CacheOriginRunID        *uuid.UUID                 `json:"cache_origin_run_id,omitempty"`
+	CacheCreatedAt          *time.Time                 `json:"cache_created_at,omitempty"`
+	CacheExpiresAt          *time.Time                 `json:"cache_expires_at,omitempty"`
+	RateLimitRetryAfter     *time.Time                 `json:"rate_limit_retry_after,omitempty"`
+	StartedAt               *time.Time                 `json:"started_at,omitempty"`
+	CompletedAt             *time.Time                 `json:"completed_at,omitempty"`
+	Error                   string                     `json:"error,omitempty"`
+	OutstandingPredecessors int                        `json:"outstanding_predecessors"`
+	// TraceID identifies the run's distributed trace, when one was recorded.
+	TraceID string `json:"trace_id,omitempty"`
+	// Instances lists a mapped task's per-item instances; the task's own
+	// status is the aggregate across them.
+	Instances []*TaskInstance `json:"instances,omitempty"`
+	// Gate is a gated task's approval request, set once the task has
+	// reached its gate.
+	Gate      *TaskGate `json:"gate,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type JobRun struct {
@@ -144,6 +160,7 @@ type JobRun struct {
 	JobID         uuid.UUID         `json:"job_id"`
 	JobAlias      string            `json:"job_alias,omitempty"`
 	JobLabels     map[string]string `json:"job_labels,omitempty"`
+	Namespace     string            `json:"namespace,omitempty"`
 	BackfillID    *uuid.UUID        `json:"backfill_id,omitempty"`
 	TriggerType   string            `json:"trigger_type,omitempty"`
 	TriggerAlias  string            `json:"trigger_alias,omitempty"`
@@ -175,6 +192,10 @@ type Store struct {
 	// for runs it actually incremented.
 	startedRuns map[uuid.UUID]struct{}
 
+	// history is the cold-history database that archived runs are read back
+	// from. It is nil when runs are never moved out of db.
+	history *gorm.DB
+
 	// leaseStore is non-nil only when CAESIUM_RUN_OWNER_ENABLED=true.
 	// When nil, no run_leases rows are written and the system behaves
 	// byte-identically to Phase 1.
@@ -244,12 +265,34 @@ var (
 	ErrQueuedRunUnavailable     = errors.New("run: queued run already claimed or unavailable")
 	ErrQueuedRunNotFound        = errors.New("run: queued run not found")
 	ErrMaxConcurrentRunsReached = errors.New("run: max concurrent runs reached")
+	// ErrNamespaceQuotaExceeded is returned when starting a run would exceed
+	// its namespace's quota. It wraps ErrMaxConcurrentRunsReached so callers
+	// treat a full namespace like a full job.
+	ErrNamespaceQuotaExceeded = fmt.Errorf("%w: namespace quota exceeded", ErrMaxConcurrentRunsReached)
+	// ErrRunNotActive is returned by CancelRunWithReason when the run has
+	// already reached a terminal status.
+	ErrRunNotActive = errors.New("run: run is not active")
 	// ErrJobPaused is returned by RetryFromFailureAdmitted when an agent-initiated
 	// retry is refused because the job is paused. A human pause outranks an agent
 	// retry (design-agent-in-the-loop.md, retry safety valves).
 	ErrJobPaused = errors.New("run: cannot retry while job is paused")
 )
 
+// RunNotActiveError is returned by CancelRunWithReason when the conditional
+// cancel matched no running run. Status is the run status the cancel
+// transaction observed. It matches ErrRunNotActive under errors.Is.
+type RunNotActiveError struct {
+	Status Status
+}
+
+func (e *RunNotActiveError) Error() string {
+	return fmt.Sprintf("%s (status %s)", ErrRunNotActive, e.Status)
+}
+
+func (e *RunNotActiveError) Unwrap() error {
+	return ErrRunNotActive
+}
+
 type admissionDecision int
 
 const (
@@ -273,6 +316,7 @@ type admissionResult struct {
 	decision           admissionDecision
 	jobAlias           string
 	skipReason         string
+	quotaExceeded      bool
 	replaced           bool
 	cancelledRun       *cancelledRunInfo
 	cancelledRunEvents []event.Event
@@ -319,7 +363,7 @@ func (s *Store) LeaseStore() *LeaseStore {
 
 func Default() *Store {
 	defaultStoreOnce.Do(func() {
-		defaultStore = NewStore(db.Connection())
+		defaultStore = NewStore(db.Connection()).WithHistory(db.DefaultRouter().Cold())
 	})
 	return defaultStore
 }
@@ -414,6 +458,18 @@ func decodeCacheConfig(raw []byte) interface{} {
 	return decoded
 }
 
+func decodeNodeAffinity(raw []byte) *jobdefschema.NodeAffinity {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var affinity jobdefschema.NodeAffinity
+	if err := json.Unmarshal(raw, &affinity); err != nil {
+		return nil
+	}
+	return &affinity
+}
+
 // RenewLeases extends claim_expires_at for all task runs identified by ids that
 // are still claimed by nodeID. The WHERE clause on claimed_by ensures that any
 // task whose claim was reassigned after expiry is not accidentally extended.
@@ -630,6 +686,7 @@ func newStartRunModel(req startRunRequest) (*models.JobRun, error) {
 			return nil, fmt.Errorf("run: failed to marshal params: %w", err)
 		}
 		model.Params = encoded
+		model.LogicalDate = req.params[jobdefschema.ParamLogicalDate]
 	}
 	return model, nil
 }
@@ -666,9 +723,10 @@ func (s *Store) appendRunStartedEventTx(tx *gorm.DB, model *models.JobRun) (*eve
 }
 
 type concurrencyConfig struct {
-	jobAlias string
-	maxRuns  int
-	strategy string
+	jobAlias  string
+	namespace string
+	maxRuns   int
+	strategy  string
 }
 
 func concurrencyFromJSON(raw []byte) (*jobdefschema.Concurrency, error) {
@@ -685,10 +743,11 @@ func concurrencyFromJSON(raw []byte) (*jobdefschema.Concurrency, error) {
 func (s *Store) concurrencyConfigTx(tx *gorm.DB, jobID uuid.UUID) (concurrencyConfig, bool, error) {
 	var row struct {
 		Alias       string
+		Namespace   string
 		Concurrency datatypes.JSON
 	}
 	err := tx.Model(&models.Job{}).
-		Select("alias", "concurrency").
+		Select("alias", "namespace", "concurrency").
 		Where("id = ?", jobID).
 		Take(&row).Error
 	if err != nil {
@@ -702,16 +761,17 @@ func (s *Store) concurrencyConfigTx(tx *gorm.DB, jobID uuid.UUID) (concurrencyCo
 		return concurrencyConfig{}, false, fmt.Errorf("run: decode job concurrency metadata: %w", err)
 	}
 	if cfg == nil || cfg.MaxRuns <= 0 {
-		return concurrencyConfig{jobAlias: row.Alias}, false, nil
+		return concurrencyConfig{jobAlias: row.Alias, namespace: row.Namespace}, false, nil
 	}
 	strategy := strings.ToLower(strings.TrimSpace(cfg.Strategy))
 	if strategy == "" {
 		strategy = jobdefschema.ConcurrencyStrategyQueue
 	}
 	return concurrencyConfig{
-		jobAlias: row.Alias,
-		maxRuns:  cfg.MaxRuns,
-		strategy: strategy,
+		jobAlias:  row.Alias,
+		namespace: row.Namespace,
+		maxRuns:   cfg.MaxRuns,
+		strategy:  strategy,
 	}, true, nil
 }
 
@@ -723,18 +783,39 @@ func (s *Store) admit(tx *gorm.DB, model *models.JobRun, req startRunRequest) (a
 	if err != nil {
 		return admissionResult{}, err
 	}
+	if cfg.namespace != "" {
+		model.Namespace = cfg.namespace
+	}
+	namespace := jobdefschema.NamespaceOrDefault(model.Namespace)
+	quota, hasQuota := env.Variables().NamespaceQuotas.For(namespace)
 	result := admissionResult{decision: admissionNoPolicy, jobAlias: cfg.jobAlias}
-	if !ok {
+	if !ok && !hasQuota {
 		return result, nil
 	}
 	if model.BackfillID != nil {
 		// Backfills use their own MaxConcurrent semaphore and run_queue does not
-		// carry backfill_id, so ordinary run concurrency deliberately excludes
-		// backfill rows via backfill_id IS NULL in the active-count predicates.
+		// carry backfill_id, so the job's maxRuns deliberately excludes backfill
+		// rows via backfill_id IS NULL in its active-count predicate. Namespace
+		// quotas count them: a backfill run is admitted only while its namespace
+		// has room, and the backfill reconciler retries it on a later pass.
+		if !hasQuota {
+			return result, nil
+		}
+		inserted, err := s.insertRunIfSlotTx(tx, model, 0, quota)
+		if err != nil {
+			return result, err
+		}
+		if inserted {
+			result.decision = admissionCreated
+			return result, nil
+		}
+		metrics.NamespaceQuotaRejectionsTotal.WithLabelValues(namespace).Inc()
+		result.quotaExceeded = true
+		result.decision = admissionFailed
 		return result, nil
 	}
 
-	inserted, err := s.insertRunIfSlotTx(tx, model, cfg.maxRuns)
+	inserted, err := s.insertRunIfSlotTx(tx, model, cfg.maxRuns, quota)
 	if err != nil {
 		return result, err
 	}
@@ -742,11 +823,25 @@ func (s *Store) admit(tx *gorm.DB, model *models.JobRun, req startRunRequest) (a
 		result.decision = admissionCreated
 		return result, nil
 	}
+	if result.quotaExceeded, err = s.namespaceQuotaRejectedTx(tx, model.JobID, cfg.maxRuns); err != nil {
+		return result, err
+	}
+	if result.quotaExceeded {
+		metrics.NamespaceQuotaRejectionsTotal.WithLabelValues(namespace).Inc()
+	}
+	if !ok {
+		// A job without a concurrency policy has no strategy to fall back on.
+		result.decision = admissionFailed
+		return result, nil
+	}
 
 	switch cfg.strategy {
 	case jobdefschema.ConcurrencyStrategySkip:
 		result.decision = admissionSkipped
 		result.skipReason = "max_concurrency"
+		if result.quotaExceeded {
+			result.skipReason = "namespace_quota"
+		}
 		return result, nil
 	case jobdefschema.ConcurrencyStrategyFail:
 		result.decision = admissionFailed
@@ -766,7 +861,7 @@ func (s *Store) admit(tx *gorm.DB, model *models.JobRun, req startRunRequest) (a
 		if err != nil {
 			return result, err
 		}
-		inserted, err := s.insertRunIfSlotTx(tx, model, cfg.maxRuns)
+		inserted, err := s.insertRunIfSlotTx(tx, model, cfg.maxRuns, quota)
 		if err != nil {
 			return result, err
 		}
@@ -784,8 +879,82 @@ func (s *Store) admit(tx *gorm.DB, model *models.JobRun, req startRunRequest) (a
 	}
 }
 
-func (s *Store) insertRunIfSlotTx(tx *gorm.DB, model *models.JobRun, maxRuns int) (bool, error) {
+// namespaceQuotaRejectedTx reports whether a rejected admission was refused
+// by the namespace quota rather than the job's own maxRuns: the job still has
+// a free slot.
+func (s *Store) namespaceQuotaRejectedTx(tx *gorm.DB, jobID uuid.UUID, maxRuns int) (bool, error) {
 	if maxRuns <= 0 {
+		return true, nil
+	}
+	var active int64
+	err := tx.Model(&models.JobRun{}).
+		Where("job_id = ? AND status = ? AND quarantine <> true AND backfill_id IS NULL", jobID, string(StatusRunning)).
+		Count(&active).Error
+	return active < int64(maxRuns), err
+}
+
+func (s *Store) insertRunIfSlotTx(tx *gorm.DB, model *models.JobRun, maxRuns int, quota env.NamespaceQuota) (bool, error) {
+	var (
+		conditions []string
+		condArgs   []any
+	)
+	if maxRuns > 0 {
+		conditions = append(conditions, `(
+	SELECT count(*)
+	FROM job_runs
+	WHERE job_id = ?
+		AND status = ?
+		AND quarantine <> true
+		AND backfill_id IS NULL
+) < ?`)
+		condArgs = append(condArgs, model.JobID, string(StatusRunning), maxRuns)
+	}
+	namespace := jobdefschema.NamespaceOrDefault(model.Namespace)
+	model.Namespace = namespace
+	// Namespace quotas count backfill runs alongside ordinary ones.
+	if quota.MaxRuns > 0 {
+		conditions = append(conditions, `(
+	SELECT count(*)
+	FROM job_runs
+	WHERE namespace = ?
+		AND status = ?
+		AND quarantine <> true
+) < ?`)
+		condArgs = append(condArgs, namespace, string(StatusRunning), quota.MaxRuns)
+	}
+	if quota.MaxTasks > 0 {
+		// A running run holds its unfinished task runs; one that has not
+		// registered its tasks yet holds its job's full task count.
+		conditions = append(conditions, `(
+	SELECT count(*)
+	FROM task_runs AS tr
+	JOIN job_runs AS jr ON jr.id = tr.job_run_id
+	WHERE jr.namespace = ?
+		AND jr.status = ?
+		AND jr.quarantine <> true
+		AND tr.status IN (?, ?, ?)
+) + (
+	SELECT count(*)
+	FROM tasks AS t
+	JOIN job_runs AS jr ON jr.job_id = t.job_id
+	WHERE jr.namespace = ?
+		AND jr.status = ?
+		AND jr.quarantine <> true
+		AND t.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM task_runs AS registered WHERE registered.job_run_id = jr.id)
+) + (
+	SELECT count(*)
+	FROM tasks
+	WHERE job_id = ?
+		AND deleted_at IS NULL
+) <= ?`)
+		condArgs = append(condArgs,
+			namespace, string(StatusRunning),
+			string(TaskStatusPending), string(TaskStatusRunning), string(TaskStatusAwaitingApproval),
+			namespace, string(StatusRunning),
+			model.JobID, quota.MaxTasks)
+	}
+	if len(conditions) == 0 {
 		return true, tx.Create(model).Error
 	}
 	var backfillID any
@@ -799,36 +968,35 @@ func (s *Store) insertRunIfSlotTx(tx *gorm.DB, model *models.JobRun, maxRuns int
 	// This must remain one conditional INSERT statement: dqlite serializes the
 	// statement through Raft, so concurrent nodes derive admission from
 	// RowsAffected instead of racing through CountActive-then-Create. Backfill
-	// rows are intentionally excluded; they are governed by backfill maxConcurrent.
-	result := tx.Exec(`
-INSERT INTO job_runs (
-	id, job_id, backfill_id, trigger_id, status, priority, params, quarantine,
-	started_at, created_at, updated_at
-)
-SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
-WHERE (
-	SELECT count(*)
-	FROM job_runs
-	WHERE job_id = ?
-		AND status = ?
-		AND quarantine <> true
-		AND backfill_id IS NULL
-) < ?`,
+	// rows are excluded from the job's maxRuns, which backfill maxConcurrent
+	// replaces, but not from namespace quotas.
+	// Every column tx.Create would write is listed, so an admitted run is
+	// stored exactly like an unconditional one.
+	args := []any{
 		model.ID,
 		model.JobID,
+		namespace,
 		backfillID,
 		model.TriggerID,
+		model.TriggerType,
+		model.TriggerAlias,
 		model.Status,
 		model.Priority,
 		params,
+		model.LogicalDate,
 		model.Quarantine,
+		model.TraceParent,
 		model.StartedAt,
 		model.CreatedAt,
 		model.UpdatedAt,
-		model.JobID,
-		string(StatusRunning),
-		maxRuns,
-	)
+	}
+	result := tx.Exec(`
+INSERT INTO job_runs (
+	id, job_id, namespace, backfill_id, trigger_id, trigger_type, trigger_alias, status, priority,
+	params, logical_date, quarantine, trace_parent, started_at, created_at, updated_at
+)
+SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+WHERE `+strings.Join(conditions, "\nAND "), append(args, condArgs...)...)
 	if result.Error != nil {
 		return false, result.Error
 	}
@@ -842,7 +1010,7 @@ func metricJobAlias(jobID uuid.UUID, alias string) string {
 	return jobID.String()
 }
 
-func (s *Store) startRun(req startRunRequest) (*JobRun, error) {
+func (s *Store) startRun(req startRunRequest) (started *JobRun, err error) {
 	ctx := req.ctx
 	if ctx == nil {
 		ctx = context.Background()
@@ -850,12 +1018,24 @@ func (s *Store) startRun(req startRunRequest) (*JobRun, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+
+	// The run's tasks descend from the caller's span, not from run.start,
+	// which only covers admission.
+	traceparent := tracing.Traceparent(ctx)
+	ctx, span := tracing.Start(ctx, "run.start", trace.WithAttributes(tracing.AttrJobID.String(req.jobID.String())))
+	defer func() {
+		if !errors.Is(err, ErrRunSkipped) && !errors.Is(err, ErrRunQueued) {
+			tracing.Fail(span, err)
+		}
+		span.End()
+	}()
 	conn := s.db.WithContext(ctx)
 
 	model, err := newStartRunModel(req)
 	if err != nil {
 		return nil, err
 	}
+	model.TraceParent = traceparent
 
 	var (
 		pendingEvents []event.Event
@@ -927,17 +1107,24 @@ func (s *Store) startRun(req startRunRequest) (*JobRun, error) {
 		}
 		log.Info("run skipped by concurrency policy", "job_id", req.jobID, "job_alias", admission.jobAlias, "reason", reason)
 		metrics.RunSkippedTotal.WithLabelValues(metricJobAlias(req.jobID, admission.jobAlias), reason).Inc()
+		span.SetAttributes(attribute.String("caesium.run.admission", "skipped"))
 		return nil, ErrRunSkipped
 	case admissionFailed:
+		if admission.quotaExceeded {
+			return nil, ErrNamespaceQuotaExceeded
+		}
 		return nil, ErrMaxConcurrentRunsReached
 	case admissionQueued:
 		log.Info("run queued by concurrency policy", "job_id", req.jobID, "job_alias", admission.jobAlias)
 		if err := s.observeRunQueueDepth(req.jobID); err != nil {
 			log.Warn("run queue: failed to observe depth", "job_id", req.jobID, "error", err)
 		}
+		span.SetAttributes(attribute.String("caesium.run.admission", "queued"))
 		return nil, ErrRunQueued
 	}
 
+	span.SetAttributes(attribute.String("caesium.run.admission", "created"), tracing.AttrRunID.String(model.ID.String()))
+
 	// Publish events immediately after commit, before loadRun, so that
 	// run_started reaches the bus before any task events that the executor
 	// may emit once Start returns.
@@ -1063,8 +1250,13 @@ func (s *Store) StartWithContext(ctx context.Context, jobID uuid.UUID, triggerID
 }
 
 func (s *Store) AdmitRun(jobID uuid.UUID, triggerID *uuid.UUID, opts ...StartOption) (*JobRun, bool, error) {
+	return s.AdmitRunWithContext(context.Background(), jobID, triggerID, opts...)
+}
+
+func (s *Store) AdmitRunWithContext(ctx context.Context, jobID uuid.UUID, triggerID *uuid.UUID, opts ...StartOption) (*JobRun, bool, error) {
 	startOpts := startOptionsFrom(opts)
 	r, err := s.startRun(startRunRequest{
+		ctx:              ctx,
 		jobID:            jobID,
 		triggerID:        triggerID,
 		params:           startOpts.Params,
@@ -1132,7 +1324,7 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 	}
 
 	var jobRun models.JobRun
-	if err := s.db.Select("id", "job_id", "params", "trigger_id", "trigger_type", "trigger_alias", "priority", "quarantine").First(&jobRun, "id = ?", runID).Error; err != nil {
+	if err := s.db.Select("id", "job_id", "params", "trigger_id", "trigger_type", "trigger_alias", "priority", "quarantine", "started_at", "trace_parent").First(&jobRun, "id = ?", runID).Error; err != nil {
 		return fmt.Errorf("run: job run %s not found: %w", runID, err)
 	}
 	jobID := jobRun.JobID
@@ -1142,7 +1334,7 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 
 	var job models.Job
 	jobFound := true
-	if err := s.db.Select("id", "alias", "labels", "annotations", "schema_validation", "cache_config", "replay_safe", "max_parallel_tasks", "task_timeout", "run_timeout", "sla").First(&job, "id = ?", jobID).Error; err != nil {
+	if err := s.db.Select("id", "alias", "labels", "annotations", "schema_validation", "cache_config", "replay_safe", "run_templates", "max_parallel_tasks", "task_timeout", "run_timeout", "sla").First(&job, "id = ?", jobID).Error; err != nil {
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return err
 		}
@@ -1154,6 +1346,8 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 	if jobFound {
 		jobCacheConfig = decodeCacheConfig(job.CacheConfig)
 	}
+	templateData := jobdefschema.NewRunTemplateData(runID.String(), job.Alias, decodeRunParams(jobRun.Params), jobRun.StartedAt)
+	traceID := tracing.TraceID(jobRun.TraceParent)
 
 	var pendingEvents []event.Event
 	var counts dbWriteCounts
@@ -1188,12 +1382,14 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 				}
 				seenNewTaskIDs[task.ID] = struct{}{}
 
+				renderedCommand, renderedSpec, renderErr := RenderTaskSpec(atom, job.RunTemplates, templateData)
+				if renderErr != nil {
+					return fmt.Errorf("run: render task %s: %w", task.Name, renderErr)
+				}
 				command := atom.Command
-				if command == "" {
-					if cmd := atom.Cmd(); len(cmd) > 0 {
-						if encoded, marshalErr := json.Marshal(cmd); marshalErr == nil {
-							command = string(encoded)
-						}
+				if len(renderedCommand) > 0 && (command == "" || !slices.Equal(renderedCommand, atom.Cmd())) {
+					if encoded, marshalErr := json.Marshal(renderedCommand); marshalErr == nil {
+						command = string(encoded)
 					}
 				}
 
@@ -1226,6 +1422,8 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 					jobFound,
 					task,
 					atom,
+					renderedCommand,
+					renderedSpec,
 					input.OutstandingPredecessors,
 					resolvedCache,
 					replaySafe,
@@ -1245,6 +1443,9 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 					Status:                  string(TaskStatusPending),
 					Priority:                jobRun.Priority,
 					NodeSelector:            maps.Clone(task.NodeSelector),
+					NodeAffinity:            slices.Clone(task.NodeAffinity),
+					Pool:                    task.PoolName,
+					PoolSlots:               poolSlots(task),
 					Attempt:                 1,
 					MaxAttempts:             maxAttempts,
 					OutstandingPredecessors: input.OutstandingPredecessors,
@@ -1258,6 +1459,8 @@ func (s *Store) RegisterTasks(runID uuid.UUID, inputs []RegisterTaskInput) error
 					SchemaValidation:        schemaValidation,
 					Quarantine:              jobRun.Quarantine,
 					ExecutionDescriptor:     descriptor,
+					TraceID:                 traceID,
+					TraceParent:             jobRun.TraceParent,
 				})
 
 				if input.OutstandingPredecessors == 0 && s.eventStore != nil {
@@ -1342,6 +1545,8 @@ func (s *Store) initialTaskExecutionDescriptorTx(
 	jobFound bool,
 	task *models.Task,
 	atom *models.Atom,
+	command []string,
+	spec container.Spec,
 	outstanding int,
 	cacheCfg jobdefschema.CacheConfig,
 	replaySafe bool,
@@ -1350,7 +1555,6 @@ func (s *Store) initialTaskExecutionDescriptorTx(
 		return nil, errors.New("run: descriptor requires task and atom")
 	}
 
-	spec := atom.ContainerSpec()
 	trigger := models.Trigger{}
 	if jobRun.TriggerID != uuid.Nil {
 		_ = tx.Select("id", "type", "alias", "configuration").First(&trigger, "id = ?", jobRun.TriggerID).Error
@@ -1368,7 +1572,6 @@ func (s *Store) initialTaskExecutionDescriptorTx(
 	}
 
 	runParams := decodeRunParams(jobRun.Params)
-	command := atom.Cmd()
 	if len(command) == 0 && atom.Command != "" {
 		command = []string{atom.Command}
 	}
@@ -1434,6 +1637,7 @@ func (s *Store) initialTaskExecutionDescriptorTx(
 			WorkDir:      spec.WorkDir,
 			TaskType:     taskType,
 			NodeSelector: jsonmap.ToStringMap(task.NodeSelector),
+			NodeAffinity: slices.Clone(task.NodeAffinity),
 			RetryCount:   task.Retries,
 			RetryDelay:   task.RetryDelay,
 			RetryBackoff: task.RetryBackoff,
@@ -1646,7 +1850,9 @@ func (s *Store) StartTask(runID, taskID uuid.UUID, runtimeID string) error {
 // a specific task.  It atomically transitions a pending task from
 // (status=pending, claimed_by="") → (status=running, claimed_by=workerNode)
 // in a single UPDATE, mirroring what ClaimNext does but targeting a known task
-// rather than picking the next available one.
+// rather than picking the next available one.  A gated task released while its
+// approval request was pending is claimed the same way but stays in
+// awaiting_approval until its executor re-checks the request.
 //
 // The ownerGeneration argument is stamped onto owner_generation so subsequent
 // coordination writes can fence against a stale owner.  The WHERE clause
@@ -1678,14 +1884,20 @@ func (s *Store) ClaimTaskForDispatch(runID, taskID uuid.UUID, workerNode string,
 			// in memory and did NOT decrement the DB counter, so the dispatched
 			// successor still shows outstanding>0 here — trustOwnerReadiness drops
 			// the predecessor check so the claim reflects the owner's decision.
-			where := "job_run_id = ? AND task_id = ? AND status = ? AND claimed_by = '' AND outstanding_predecessors = 0 AND owner_generation <= ? AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)"
+			where := "job_run_id = ? AND task_id = ? AND status IN ? AND claimed_by = '' AND outstanding_predecessors = 0 AND owner_generation <= ? AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)"
 			if trustOwnerReadiness {
-				where = "job_run_id = ? AND task_id = ? AND status = ? AND claimed_by = '' AND owner_generation <= ? AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)"
+				where = "job_run_id = ? AND task_id = ? AND status IN ? AND claimed_by = '' AND owner_generation <= ? AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)"
 			}
+			// Pool admission applies to pushed tasks exactly as it does to
+			// ClaimNext; a full pool rejects the claim and the owner retries on
+			// a later tick.
+			admission, admissionArgs := PoolAdmissionSQL("task_runs", now)
+			claimedStatus, claimedStatusArgs := ClaimedTaskStatusSQL("status")
 			result := tx.Model(&models.TaskRun{}).
-				Where(where, runID, taskID, string(TaskStatusPending), ownerGeneration, now).
+				Where(where, runID, taskID, ClaimableTaskStatuses(), ownerGeneration, now).
+				Where(admission, admissionArgs...).
 				Updates(map[string]interface{}{
-					"status":                 string(TaskStatusRunning),
+					"status":                 gorm.Expr(claimedStatus, claimedStatusArgs...),
 					"claimed_by":             workerNode,
 					"claim_expires_at":       leaseExpiry,
 					"claim_attempt":          gorm.Expr("claim_attempt + 1"),
@@ -1723,8 +1935,8 @@ func (s *Store) ClaimTaskForDispatch(runID, taskID uuid.UUID, workerNode string,
 }
 
 // PendingTasksForDispatch returns up to limit task_runs rows for runID that
-// are ready for owner-push dispatch: status=pending, claimed_by="", and
-// outstanding_predecessors=0.  The caller (the dispatch loop) uses this to
+// are ready for owner-push dispatch: pending (or a released gated task in
+// awaiting_approval), claimed_by="", and outstanding_predecessors=0.  The caller (the dispatch loop) uses this to
 // find the next batch of tasks to push to workers each tick.
 //
 // The result is ordered by created_at ASC so earlier-registered tasks are
@@ -1736,8 +1948,8 @@ func (s *Store) PendingTasksForDispatch(ctx context.Context, runID uuid.UUID, li
 	}
 	var tasks []models.TaskRun
 	err := s.db.WithContext(ctx).
-		Where("job_run_id = ? AND status = ? AND claimed_by = '' AND outstanding_predecessors = 0 AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)",
-			runID, string(TaskStatusPending), time.Now().UTC()).
+		Where("job_run_id = ? AND status IN ? AND claimed_by = '' AND outstanding_predecessors = 0 AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)",
+			runID, ClaimableTaskStatuses(), time.Now().UTC()).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&tasks).Error
@@ -1747,6 +1959,30 @@ func (s *Store) PendingTasksForDispatch(ctx context.Context, runID uuid.UUID, li
 	return tasks, nil
 }
 
+// RunningTasksByNode counts runID's running task_runs per claiming node.  The
+// dispatch loop uses it to spread a run's nodeAffinity.spread tasks across
+// nodes.
+func (s *Store) RunningTasksByNode(ctx context.Context, runID uuid.UUID) (map[string]int, error) {
+	var rows []struct {
+		ClaimedBy string
+		Tasks     int
+	}
+	err := s.db.WithContext(ctx).
+		Model(&models.TaskRun{}).
+		Select("claimed_by, COUNT(*) AS tasks").
+		Where("job_run_id = ? AND status = ? AND claimed_by <> ''", runID, string(TaskStatusRunning)).
+		Group("claimed_by").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.ClaimedBy] = row.Tasks
+	}
+	return counts, nil
+}
+
 // LoadDispatchedTaskRun loads the full task_runs row for a task that was just
 // claimed for dispatch.  The (claimedBy, status=running) predicate ensures the
 // row really is the one this node claimed via ClaimTaskForDispatch and not a
@@ -1833,6 +2069,36 @@ func (s *Store) RateLimitTask(ctx context.Context, runID, taskID uuid.UUID, retr
 	})
 }
 
+// RateLimitTaskRun is RateLimitTask for one task run by ID, so a rejected
+// map instance task run leaves its group and sibling instances alone.
+func (s *Store) RateLimitTaskRun(ctx context.Context, id uuid.UUID, retryAfter time.Time) error {
+	if retryAfter.IsZero() {
+		retryAfter = time.Now().UTC()
+	}
+	retryAfter = retryAfter.UTC()
+
+	return withStoreBusyRetry(func() error {
+		result := s.db.WithContext(ctx).Model(&models.TaskRun{}).
+			Where("id = ? AND status IN ?", id, []string{string(TaskStatusPending), string(TaskStatusRunning)}).
+			Updates(map[string]interface{}{
+				"status":                 string(TaskStatusPending),
+				"claimed_by":             "",
+				"claim_expires_at":       nil,
+				"runtime_id":             "",
+				"started_at":             nil,
+				"rate_limit_retry_after": retryAfter,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			metrics.DBWritesTotal.WithLabelValues(metrics.DBWriteCategoryTaskRunStatus).Add(float64(result.RowsAffected))
+			metrics.DBStatementsTotal.WithLabelValues(metrics.DBWriteCategoryTaskRunStatus).Inc()
+		}
+		return nil
+	})
+}
+
 func (s *Store) StartTaskClaimed(runID, taskID uuid.UUID, runtimeID, claimedBy string) error {
 	var pendingEvents []event.Event
 	var counts dbWriteCounts
@@ -1891,6 +2157,9 @@ type CompleteTaskResult struct {
 type TaskLogSnapshot struct {
 	Text      string
 	Truncated bool
+	// ArchiveRef locates the complete log in the log archive, when the task
+	// ran with one configured.
+	ArchiveRef string
 }
 
 type CacheHitSource struct {
@@ -2157,6 +2426,15 @@ func (s *Store) SaveTaskLogSnapshot(runID, taskID uuid.UUID, snapshot *TaskLogSn
 		}).Error
 }
 
+// SetTaskLogArchiveRef records where the task's complete log is archived.
+// It is set when archiving starts, so a running task's partial log can be
+// read from the archive too.
+func (s *Store) SetTaskLogArchiveRef(runID, taskID uuid.UUID, ref string) error {
+	return s.db.Model(&models.TaskRun{}).
+		Where("job_run_id = ? AND task_id = ?", runID, taskID).
+		Update("log_archive_ref", ref).Error
+}
+
 // SetTaskExitCode persists the raw process exit code the engine reported at
 // task completion onto the task run. The incident classifier reads this
 // alongside SchemaViolations/Result to bucket a failure into a failure_class.
@@ -2183,20 +2461,28 @@ func (s *Store) SaveSchemaViolations(runID, taskID uuid.UUID, violations []pkgta
 
 func (s *Store) GetTaskLogSnapshot(runID, taskID uuid.UUID) (*TaskLogSnapshot, error) {
 	var task models.TaskRun
-	if err := s.db.
-		Select("log_text", "log_truncated").
+	err := s.db.
+		Select("log_text", "log_truncated", "log_archive_ref").
 		Where("job_run_id = ? AND task_id = ?", runID, taskID).
-		First(&task).Error; err != nil {
+		First(&task).Error
+	if s.fallsThroughToHistory(err) {
+		err = s.history.
+			Select("log_text", "log_truncated", "log_archive_ref").
+			Where("job_run_id = ? AND task_id = ?", runID, taskID).
+			First(&task).Error
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	if task.LogText == "" && !task.LogTruncated {
+	if task.LogText == "" && !task.LogTruncated && task.LogArchiveRef == "" {
 		return nil, nil
 	}
 
 	return &TaskLogSnapshot{
-		Text:      task.LogText,
-		Truncated: task.LogTruncated,
+		Text:       task.LogText,
+		Truncated:  task.LogTruncated,
+		ArchiveRef: task.LogArchiveRef,
 	}, nil
 }
 
@@ -3274,6 +3560,7 @@ func (s *Store) retryTask(runID, taskID uuid.UUID, attempt int, claimedBy string
 				"branch_selections":      nil,
 				"log_text":               "",
 				"log_truncated":          false,
+				"log_archive_ref":        "",
 				"error":                  "",
 				"rate_limit_retry_after": nil,
 				"cache_hit":              false,
@@ -3289,6 +3576,12 @@ func (s *Store) retryTask(runID, taskID uuid.UUID, attempt int, claimedBy string
 		}
 		counts.addTaskRunStatus(1)
 
+		// A sensor's next attempt starts a fresh timeout.
+		if err := tx.Where("job_run_id = ? AND task_id = ?", runID, taskID).
+			Delete(&models.SensorPoke{}).Error; err != nil {
+			return err
+		}
+
 		if s.eventStore != nil {
 			// Build retrying event payload.
 			var taskRunModel models.TaskRun
@@ -3433,6 +3726,9 @@ func (s *Store) Complete(runID uuid.UUID, result error) error {
 				// Already finalized by another path; nothing more to do.
 				return errRunAlreadyTerminal
 			}
+			if err := deleteMapInstanceRunsTx(tx, runID); err != nil {
+				return err
+			}
 
 			// Read jobID + startedAt inside the same retried transaction so the
 			// post-commit metrics/gauge bookkeeping always has them.
@@ -3529,6 +3825,20 @@ func (s *Store) Complete(runID uuid.UUID, result error) error {
 }
 
 func (s *Store) CancelRun(ctx context.Context, runID uuid.UUID) error {
+	err := s.CancelRunWithReason(ctx, runID, "cancelled by concurrency replacement")
+	if errors.Is(err, ErrRunNotActive) {
+		return nil
+	}
+	return err
+}
+
+// CancelRunWithReason cancels a running run on operator request: the run and
+// every non-terminal task run transition to cancelled, task claims are cleared
+// so the claiming worker's cancellation watch stops its in-flight atoms, the
+// run lease is released, and run_cancelled/run_terminal are emitted. reason is
+// recorded as the run and task error. A *RunNotActiveError, which matches
+// ErrRunNotActive, is returned when the run is not currently running.
+func (s *Store) CancelRunWithReason(ctx context.Context, runID uuid.UUID, reason string) error {
 	var (
 		pendingEvents []event.Event
 		cancelled     *cancelledRunInfo
@@ -3537,7 +3847,7 @@ func (s *Store) CancelRun(ctx context.Context, runID uuid.UUID) error {
 		attemptEvents := make([]event.Event, 0, 2)
 		var attemptCancelled *cancelledRunInfo
 		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			info, events, err := s.cancelRunTx(tx, runID, "cancelled by concurrency replacement")
+			info, events, err := s.cancelRunTx(tx, runID, reason)
 			if err != nil {
 				return err
 			}
@@ -3553,13 +3863,57 @@ func (s *Store) CancelRun(ctx context.Context, runID uuid.UUID) error {
 	}); err != nil {
 		return err
 	}
-	s.publishEvents(pendingEvents...)
-	if cancelled != nil {
-		s.recordCancelledRunMetrics(*cancelled)
+	if cancelled == nil {
+		return ErrRunNotActive
 	}
+	s.publishEvents(pendingEvents...)
+	s.recordCancelledRunMetrics(*cancelled)
 	return nil
 }
 
+// IsRunCancelled reports whether the run has been cancelled. Executors poll it
+// to notice a cancellation issued on another node.
+func (s *Store) IsRunCancelled(ctx context.Context, runID uuid.UUID) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).
+		Model(&models.JobRun{}).
+		Where("id = ? AND status = ?", runID, string(StatusCancelled)).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CancelledTaskRuns returns the subset of the given task run IDs whose status
+// is cancelled. Workers poll it for their in-flight claims so a run cancelled
+// on any node stops the atoms executing here. An ID whose row no longer exists
+// counts as cancelled: map instance task runs are deleted when their run ends.
+func (s *Store) CancelledTaskRuns(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var rows []models.TaskRun
+	if err := s.db.WithContext(ctx).
+		Select("id", "status").
+		Where("id IN ?", ids).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	live := make(map[uuid.UUID]struct{}, len(rows))
+	for i := range rows {
+		if rows[i].Status != string(TaskStatusCancelled) {
+			live[rows[i].ID] = struct{}{}
+		}
+	}
+	var cancelled []uuid.UUID
+	for _, id := range ids {
+		if _, ok := live[id]; !ok {
+			cancelled = append(cancelled, id)
+		}
+	}
+	return cancelled, nil
+}
+
 func (s *Store) cancelOldestActiveRunTx(tx *gorm.DB, jobID uuid.UUID) (*cancelledRunInfo, []event.Event, error) {
 	var model models.JobRun
 	err := tx.
@@ -3572,7 +3926,12 @@ func (s *Store) cancelOldestActiveRunTx(tx *gorm.DB, jobID uuid.UUID) (*cancelle
 		}
 		return nil, nil, err
 	}
-	return s.cancelRunTx(tx, model.ID, "cancelled by concurrency replacement")
+	info, events, err := s.cancelRunTx(tx, model.ID, "cancelled by concurrency replacement")
+	if errors.Is(err, ErrRunNotActive) {
+		// The run finished between the select and the cancel.
+		return nil, nil, nil
+	}
+	return info, events, err
 }
 
 func (s *Store) cancelRunTx(tx *gorm.DB, runID uuid.UUID, reason string) (*cancelledRunInfo, []event.Event, error) {
@@ -3591,7 +3950,13 @@ func (s *Store) cancelRunTx(tx *gorm.DB, runID uuid.UUID, reason string) (*cance
 		return nil, nil, res.Error
 	}
 	if res.RowsAffected == 0 {
-		return nil, nil, nil
+		// Report the status that made the conditional update miss, read in
+		// the same transaction rather than from before the attempt.
+		var current models.JobRun
+		if err := tx.Select("status").Where("id = ?", runID).Take(&current).Error; err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, &RunNotActiveError{Status: Status(current.Status)}
 	}
 
 	taskRes := tx.Model(&models.TaskRun{}).
@@ -3608,6 +3973,21 @@ func (s *Store) cancelRunTx(tx *gorm.DB, runID uuid.UUID, reason string) (*cance
 	if taskRes.Error != nil {
 		return nil, nil, taskRes.Error
 	}
+	if err := tx.Model(&models.TaskRunInstance{}).
+		Where("job_run_id = ? AND status NOT IN ?", runID, terminalTaskStatuses()).
+		Updates(map[string]interface{}{
+			"status":       string(TaskStatusCancelled),
+			"completed_at": now,
+			"error":        reason,
+		}).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := deleteMapInstanceRunsTx(tx, runID); err != nil {
+		return nil, nil, err
+	}
+	if err := expireRunGatesTx(tx, runID, reason, now); err != nil {
+		return nil, nil, err
+	}
 	if err := deleteRunLeaseTx(tx, runID); err != nil {
 		return nil, nil, err
 	}
@@ -3690,8 +4070,10 @@ func (s *Store) recordCancelledRunMetrics(info cancelledRunInfo) {
 }
 
 func (s *Store) ResetInFlightTasks(runID uuid.UUID) error {
+	// Tasks parked at an approval gate are in flight too: their approval
+	// request survives the reset, so re-executing them resumes the wait.
 	return s.db.Model(&models.TaskRun{}).
-		Where("job_run_id = ? AND status = ?", runID, string(TaskStatusRunning)).
+		Where("job_run_id = ? AND status IN ?", runID, []string{string(TaskStatusRunning), string(TaskStatusAwaitingApproval)}).
 		Updates(map[string]interface{}{
 			"status": string(TaskStatusPending),
 			// Clear the claim too, so a new owner taking over a run can re-claim
@@ -3916,10 +4298,42 @@ func (s *Store) FindRunning(jobID uuid.UUID) (*JobRun, error) {
 }
 
 func (s *Store) Get(runID uuid.UUID) (*JobRun, error) {
-	return s.loadRun(runID)
+	runValue, err := s.loadRun(runID)
+	if s.fallsThroughToHistory(err) {
+		return s.loadHistoryRun(runID)
+	}
+	return runValue, err
 }
 
-func (s *Store) List(jobID uuid.UUID) ([]*JobRun, error) {
+// ListOptions pages Store.List. Pages count back from the newest run:
+// Offset skips the most recent runs and Limit caps how many older runs
+// follow. A zero Limit lists every run.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+// window is how many of the newest runs each database must return for the
+// page to be complete, or 0 when the page is unbounded.
+func (opts ListOptions) window() int {
+	if opts.Limit <= 0 {
+		return 0
+	}
+	return max(opts.Offset, 0) + opts.Limit
+}
+
+// page cuts the requested page from runs ordered oldest first.
+func (opts ListOptions) page(runs []*JobRun) []*JobRun {
+	end := max(len(runs)-max(opts.Offset, 0), 0)
+	start := 0
+	if opts.Limit > 0 {
+		start = max(end-opts.Limit, 0)
+	}
+	return runs[start:end]
+}
+
+// List returns a page of the job's runs, oldest first.
+func (s *Store) List(jobID uuid.UUID, opts ListOptions) ([]*JobRun, error) {
 	var results []struct {
 		models.JobRun
 		JobAlias     string
@@ -3927,18 +4341,19 @@ func (s *Store) List(jobID uuid.UUID) ([]*JobRun, error) {
 		TriggerAlias string
 	}
 
-	err := s.db.Table("job_runs").
+	query := s.db.Table("job_runs").
 		Select("job_runs.*, jobs.alias as job_alias, triggers.type as trigger_type, triggers.alias as trigger_alias").
 		Joins("join jobs on jobs.id = job_runs.job_id").
 		Joins("left join triggers on triggers.id = job_runs.trigger_id").
 		Where("job_runs.job_id = ? AND job_runs.quarantine IS NOT TRUE", jobID).
-		Order("job_runs.started_at ASC").
-		Preload("Tasks").
-		Scan(&results).Error
-
-	if err != nil {
+		Order("job_runs.started_at DESC")
+	if window := opts.window(); window > 0 {
+		query = query.Limit(window)
+	}
+	if err := query.Preload("Tasks").Scan(&results).Error; err != nil {
 		return nil, err
 	}
+	slices.Reverse(results)
 
 	runs := make([]*JobRun, 0, len(results))
 	for i := range results {
@@ -3952,7 +4367,15 @@ func (s *Store) List(jobID uuid.UUID) ([]*JobRun, error) {
 		runs = append(runs, runValue)
 	}
 
-	return runs, nil
+	if s.history != nil {
+		archived, err := s.listHistoryRuns(true, opts.window(), "job_id = ? AND quarantine IS NOT TRUE", jobID)
+		if err != nil {
+			return nil, err
+		}
+		runs = mergeHistoryRuns(runs, archived)
+	}
+
+	return opts.page(runs), nil
 }
 
 func (s *Store) Latest(jobID uuid.UUID) (*JobRun, error) {
@@ -3960,12 +4383,78 @@ func (s *Store) Latest(jobID uuid.UUID) (*JobRun, error) {
 	err := s.db.Where("job_id = ? AND quarantine IS NOT TRUE", jobID).
 		Order("started_at DESC").
 		First(&model).Error
+	if s.fallsThroughToHistory(err) {
+		// Only terminal runs are archived, so a job whose every run has been
+		// moved has its latest run in history.
+		err = s.history.Where("job_id = ? AND quarantine IS NOT TRUE", jobID).
+			Order("started_at DESC").
+			First(&model).Error
+		if err != nil {
+			return nil, err
+		}
+		return s.loadHistoryRun(model.ID)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return s.loadRun(model.ID)
 }
 
+// ListByIDs returns the runs with the given IDs, keyed by ID, in two queries
+// however many runs are asked for. Task runs are included, but not their
+// instances, gates, or the run's callbacks.
+func (s *Store) ListByIDs(ids []uuid.UUID) (map[uuid.UUID]*JobRun, error) {
+	runs := make(map[uuid.UUID]*JobRun, len(ids))
+	if len(ids) == 0 {
+		return runs, nil
+	}
+
+	var results []struct {
+		models.JobRun
+		JobAlias     string
+		JobLabels    datatypes.JSONMap
+		TriggerType  string
+		TriggerAlias string
+	}
+	err := s.db.Table("job_runs").
+		Select("job_runs.*, jobs.alias as job_alias, jobs.labels as job_labels, triggers.type as trigger_type, triggers.alias as trigger_alias").
+		Joins("left join jobs on jobs.id = job_runs.job_id").
+		Joins("left join triggers on triggers.id = job_runs.trigger_id").
+		Where("job_runs.id IN ?", ids).
+		Preload("Tasks").
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		runValue := newJobRun(&results[i].JobRun)
+		runValue.JobAlias = results[i].JobAlias
+		runValue.JobLabels = jsonmap.ToStringMap(results[i].JobLabels)
+		runValue.TriggerType = results[i].TriggerType
+		runValue.TriggerAlias = results[i].TriggerAlias
+		runValue.CacheHits, runValue.ExecutedTasks, runValue.TotalTasks = summarizeTasks(runValue.Tasks)
+		runs[runValue.ID] = runValue
+	}
+
+	if s.history != nil && len(runs) < len(ids) {
+		missing := make([]uuid.UUID, 0, len(ids)-len(runs))
+		for _, id := range ids {
+			if _, ok := runs[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+		archived, err := s.listHistoryRuns(false, 0, "id IN ?", missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, runValue := range archived {
+			runs[runValue.ID] = runValue
+		}
+	}
+	return runs, nil
+}
+
 // LatestSuccessfulCronRun returns the most recent cron-triggered run for a job
 // that completed with status "succeeded". It returns gorm.ErrRecordNotFound
 // when no such run exists.
@@ -4034,9 +4523,37 @@ func (s *Store) convertRunModelWithDB(conn *gorm.DB, model *models.JobRun) (*Job
 		return nil, nil
 	}
 
+	runValue := newJobRun(model)
+	instances, err := loadRunTaskInstancesWithDB(conn, model.ID)
+	if err != nil {
+		return nil, err
+	}
+	gates, err := loadRunTaskGatesWithDB(conn, model.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range runValue.Tasks {
+		task.Instances = instances[task.TaskID]
+		task.Gate = gates[task.TaskID]
+	}
+	runValue.CacheHits, runValue.ExecutedTasks, runValue.TotalTasks = summarizeTasks(runValue.Tasks)
+
+	callbackRuns, err := s.loadCallbackRunsWithDB(conn, model.ID)
+	if err != nil {
+		return nil, err
+	}
+	runValue.Callbacks = callbackRuns
+
+	return runValue, nil
+}
+
+// newJobRun converts a run row and its preloaded task runs, leaving out the
+// instances, gates, and callbacks that take further queries.
+func newJobRun(model *models.JobRun) *JobRun {
 	runValue := &JobRun{
 		ID:         model.ID,
 		JobID:      model.JobID,
+		Namespace:  model.Namespace,
 		BackfillID: model.BackfillID,
 		Status:     Status(model.Status),
 		Priority:   model.Priority,
@@ -4061,20 +4578,14 @@ func (s *Store) convertRunModelWithDB(conn *gorm.DB, model *models.JobRun) (*Job
 
 	runValue.Tasks = make([]*TaskRun, 0, len(model.Tasks))
 	for _, task := range model.Tasks {
-		if task == nil {
+		// Instance task runs of an expanded map group are reported through
+		// the group's Instances, not as tasks of their own.
+		if task == nil || task.MapParentID != nil {
 			continue
 		}
 		runValue.Tasks = append(runValue.Tasks, convertRunTaskModel(task))
 	}
-	runValue.CacheHits, runValue.ExecutedTasks, runValue.TotalTasks = summarizeTasks(runValue.Tasks)
-
-	callbackRuns, err := s.loadCallbackRunsWithDB(conn, model.ID)
-	if err != nil {
-		return nil, err
-	}
-	runValue.Callbacks = callbackRuns
-
-	return runValue, nil
+	return runValue
 }
 
 func convertRunTaskModel(model *models.TaskRun) *TaskRun {
@@ -4101,6 +4612,7 @@ func convertRunTaskModel(model *models.TaskRun) *TaskRun {
 		Status:                  TaskStatus(model.Status),
 		Priority:                model.Priority,
 		NodeSelector:            jsonmap.ToStringMap(model.NodeSelector),
+		NodeAffinity:            decodeNodeAffinity(model.NodeAffinity),
 		ClaimedBy:               model.ClaimedBy,
 		ClaimAttempt:            model.ClaimAttempt,
 		Attempt:                 model.Attempt,
@@ -4113,6 +4625,7 @@ func convertRunTaskModel(model *models.TaskRun) *TaskRun {
 		CacheHit:                model.CacheHit || TaskStatus(model.Status) == TaskStatusCached,
 		Quarantine:              model.Quarantine,
 		ReplaySafe:              model.ReplaySafe,
+		TraceID:                 model.TraceID,
 	}
 
 	if len(model.Output) > 0 {
@@ -4235,6 +4748,12 @@ func (s *Store) recordTaskEventTx(db *gorm.DB, eventType event.Type, runID, task
 	// Use task-run row ID for event payloads so downstream consumers can identify
 	// each task execution uniquely across retries/runs.
 	taskPayload.ID = taskRun.ID
+	if eventType == event.TypeTaskAwaitingApproval {
+		var approval models.TaskApproval
+		if err := db.Where("task_run_id = ?", taskRun.ID).First(&approval).Error; err == nil {
+			taskPayload.Gate = convertTaskApprovalModel(&approval)
+		}
+	}
 
 	payload, err := json.Marshal(taskPayload)
 	if err != nil {
@@ -4860,6 +5379,7 @@ func (s *Store) retryFromFailure(runID uuid.UUID, admit bool) (*JobRun, error) {
 		// 4. Reset failed and skipped tasks to pending.
 		// Leave succeeded and cached tasks as-is.
 		resetTaskIDs := make([]uuid.UUID, 0)
+		resetTaskRunIDs := make([]uuid.UUID, 0)
 		for i := range taskRuns {
 			tr := &taskRuns[i]
 			status := TaskStatus(tr.Status)
@@ -4878,11 +5398,27 @@ func (s *Store) retryFromFailure(runID uuid.UUID, admit bool) (*JobRun, error) {
 					"cache_origin_run_id": nil,
 					"cache_created_at":    nil,
 					"cache_expires_at":    nil,
+					"map_expanded":        false,
 				}
 				if err := tx.Model(tr).Updates(updates).Error; err != nil {
 					return err
 				}
 				resetTaskIDs = append(resetTaskIDs, tr.TaskID)
+				resetTaskRunIDs = append(resetTaskRunIDs, tr.ID)
+			}
+		}
+
+		// A retried gated step asks for approval again rather than reusing
+		// the decision (or expiry) that ended its previous attempt, and a
+		// retried sensor starts a fresh timeout.
+		if len(resetTaskRunIDs) > 0 {
+			if err := tx.Where("task_run_id IN ?", resetTaskRunIDs).
+				Delete(&models.TaskApproval{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_run_id IN ?", resetTaskRunIDs).
+				Delete(&models.SensorPoke{}).Error; err != nil {
+				return err
 			}
 		}
 
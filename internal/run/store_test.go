@@ -13,6 +13,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
 	"github.com/caesium-cloud/caesium/internal/lineage"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/container"
 	"github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/google/uuid"
@@ -172,6 +173,56 @@ func TestStartAndRegisterTasksPropagatePriority(t *testing.T) {
 	require.Equal(t, PriorityHighValue, taskRun.Priority)
 }
 
+func TestStartWithContextPropagatesTraceToTaskRuns(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(db)
+	})
+
+	store := NewStore(db)
+	now := time.Now().UTC()
+	job := &models.Job{
+		ID:        uuid.New(),
+		Alias:     "traced-job",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(job).Error)
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	runRecord, err := store.StartWithContext(tracing.WithTraceparent(context.Background(), traceparent), job.ID, nil)
+	require.NoError(t, err)
+
+	var jobRun models.JobRun
+	require.NoError(t, db.First(&jobRun, "id = ?", runRecord.ID).Error)
+	require.Equal(t, traceparent, jobRun.TraceParent)
+
+	atom := &models.Atom{
+		ID:        uuid.New(),
+		Engine:    models.AtomEngineDocker,
+		Image:     "alpine:3.23",
+		Command:   `["echo","traced"]`,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	task := &models.Task{
+		ID:        uuid.New(),
+		JobID:     job.ID,
+		AtomID:    atom.ID,
+		Name:      "traced-step",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(atom).Error)
+	require.NoError(t, db.Create(task).Error)
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+
+	var taskRun models.TaskRun
+	require.NoError(t, db.First(&taskRun, "job_run_id = ? AND task_id = ?", runRecord.ID, task.ID).Error)
+	require.Equal(t, traceparent, taskRun.TraceParent)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", taskRun.TraceID)
+}
+
 func TestTaskExecutionDescriptorCaptureCoversContainerSpecFields(t *testing.T) {
 	descriptorType := reflect.TypeOf(models.TaskExecutionDescriptor{})
 
@@ -186,7 +237,7 @@ func TestTaskExecutionDescriptorCaptureCoversContainerSpecFields(t *testing.T) {
 	// These explicit lists intentionally force a descriptor review when either
 	// container carrier grows, even though v1 currently stores the structs whole.
 	require.ElementsMatch(t,
-		[]string{"Env", "WorkDir", "Mounts", "ResolvedVolumeMounts", "Kubernetes"},
+		[]string{"Env", "WorkDir", "Mounts", "ResolvedVolumeMounts", "Kubernetes", "Resources"},
 		exportedFieldNames(reflect.TypeOf(container.Spec{})),
 	)
 	require.ElementsMatch(t,
@@ -382,6 +433,101 @@ func TestRegisterTaskPersistsInitialExecutionDescriptorEnvelope(t *testing.T) {
 	require.Contains(t, descriptor.SecretRefs[0].UnverifiableReason, "not resolved yet")
 }
 
+func TestRegisterTaskRendersRunTemplates(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(db)
+	})
+
+	store := NewStore(db)
+	now := time.Now().UTC()
+	job := &models.Job{ID: uuid.New(), Alias: "nightly-load", RunTemplates: true, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(job).Error)
+
+	runRecord, err := store.Start(job.ID, nil, WithStartParams(map[string]string{
+		"region":              "eu-west-1",
+		"logical_date":        "2026-03-31T06:00:00Z",
+		"data_interval_start": "2026-03-30T06:00:00Z",
+		"data_interval_end":   "2026-03-31T06:00:00Z",
+	}))
+	require.NoError(t, err)
+
+	specJSON, err := json.Marshal(container.Spec{Env: map[string]string{
+		"PARTITION": "dt={{ ds_add .LogicalDate -1 }}",
+		"MODE":      "batch",
+	}})
+	require.NoError(t, err)
+	atom := &models.Atom{
+		ID:      uuid.New(),
+		Engine:  models.AtomEngineDocker,
+		Image:   "alpine:3.23",
+		Command: `["load","--region","{{ .Params.region }}","--job","{{ .JobAlias }}","--from","{{ ts .DataIntervalStart }}"]`,
+		Spec:    specJSON,
+	}
+	require.NoError(t, db.Create(atom).Error)
+	task := &models.Task{ID: uuid.New(), JobID: job.ID, AtomID: atom.ID, Name: "load"}
+	require.NoError(t, db.Create(task).Error)
+
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+
+	var persisted models.TaskRun
+	require.NoError(t, db.First(&persisted, "job_run_id = ? AND task_id = ?", runRecord.ID, task.ID).Error)
+	want := []string{"load", "--region", "eu-west-1", "--job", "nightly-load", "--from", "2026-03-30T06:00:00Z"}
+	require.JSONEq(t, `["load","--region","eu-west-1","--job","nightly-load","--from","2026-03-30T06:00:00Z"]`, persisted.Command)
+
+	var descriptor models.TaskExecutionDescriptor
+	require.NoError(t, json.Unmarshal(persisted.ExecutionDescriptor, &descriptor))
+	require.Equal(t, want, descriptor.Runtime.Command)
+	require.Equal(t, atom.Command, descriptor.Runtime.CommandRaw)
+	require.Equal(t, map[string]string{"PARTITION": "dt=2026-03-30", "MODE": "batch"}, descriptor.ContainerSpec.Env)
+
+	// A reference to a param the run does not carry fails registration.
+	broken := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["echo","{{ .Params.table }}"]`}
+	require.NoError(t, db.Create(broken).Error)
+	brokenTask := &models.Task{ID: uuid.New(), JobID: job.ID, AtomID: broken.ID, Name: "broken"}
+	require.NoError(t, db.Create(brokenTask).Error)
+	require.ErrorContains(t, store.RegisterTask(runRecord.ID, brokenTask, broken, 0), "run: render task broken: command[1]")
+}
+
+func TestRegisterTaskLeavesTemplatesVerbatimWithoutOptIn(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		testutil.CloseDB(db)
+	})
+
+	store := NewStore(db)
+	now := time.Now().UTC()
+	job := &models.Job{ID: uuid.New(), Alias: "dbt-models", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(job).Error)
+
+	runRecord, err := store.Start(job.ID, nil)
+	require.NoError(t, err)
+
+	specJSON, err := json.Marshal(container.Spec{Env: map[string]string{"SELECTOR": "{{ ref('orders') }}"}})
+	require.NoError(t, err)
+	atom := &models.Atom{
+		ID:      uuid.New(),
+		Engine:  models.AtomEngineDocker,
+		Image:   "alpine:3.23",
+		Command: `["dbt","run","--vars","{{ var('day') }}"]`,
+		Spec:    specJSON,
+	}
+	require.NoError(t, db.Create(atom).Error)
+	task := &models.Task{ID: uuid.New(), JobID: job.ID, AtomID: atom.ID, Name: "run"}
+	require.NoError(t, db.Create(task).Error)
+
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+
+	var persisted models.TaskRun
+	require.NoError(t, db.First(&persisted, "job_run_id = ? AND task_id = ?", runRecord.ID, task.ID).Error)
+	require.JSONEq(t, atom.Command, persisted.Command)
+
+	var descriptor models.TaskExecutionDescriptor
+	require.NoError(t, json.Unmarshal(persisted.ExecutionDescriptor, &descriptor))
+	require.Equal(t, []string{"dbt", "run", "--vars", "{{ var('day') }}"}, descriptor.Runtime.Command)
+	require.Equal(t, map[string]string{"SELECTOR": "{{ ref('orders') }}"}, descriptor.ContainerSpec.Env)
+}
+
 func TestTaskExecutionDescriptorMutationRetriesConcurrentChange(t *testing.T) {
 	db := testutil.OpenTestDB(t)
 	t.Cleanup(func() { testutil.CloseDB(db) })
@@ -2027,3 +2173,31 @@ func TestSetTaskHashWithDigestLeavesBlobNull(t *testing.T) {
 	require.Equal(t, "sha256:cafe", got.ResolvedImageDigest)
 	require.Empty(t, got.HashInputBlob)
 }
+
+func TestListByIDsLoadsRunsWithTasks(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+
+	store := NewStore(db)
+	job := createNamespacedJob(t, db, "team-a", "extract", 2)
+	first, err := store.Start(job.ID, nil)
+	require.NoError(t, err)
+	second, err := store.Start(job.ID, nil)
+	require.NoError(t, err)
+
+	var tasks []models.Task
+	require.NoError(t, db.Where("job_id = ?", job.ID).Find(&tasks).Error)
+	for i := range tasks {
+		var atom models.Atom
+		require.NoError(t, db.First(&atom, "id = ?", tasks[i].AtomID).Error)
+		require.NoError(t, store.RegisterTask(first.ID, &tasks[i], &atom, 0))
+	}
+
+	runs, err := store.ListByIDs([]uuid.UUID{first.ID, second.ID, uuid.New()})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, "extract", runs[first.ID].JobAlias)
+	require.Len(t, runs[first.ID].Tasks, 2)
+	require.Equal(t, 2, runs[first.ID].TotalTasks)
+	require.Empty(t, runs[second.ID].Tasks)
+}
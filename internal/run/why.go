@@ -105,12 +105,38 @@ type WhyExplanation struct {
 	Trigger  WhyTrigger  `json:"trigger"`
 	Baseline WhyBaseline `json:"baseline"`
 	Diff     *BlobDiff   `json:"diff,omitempty"`
+
+	// Instances explains each instance of a mapped step in index order. The
+	// group row itself carries no hash-input blob, so for a mapped step the
+	// per-instance diffs are where the discriminating inputs show up.
+	Instances []WhyInstance `json:"instances,omitempty"`
+
+	// PokeCount is how many times a sensor step's probe was evaluated in the
+	// run; Pokes holds the most recent of them, oldest first.
+	PokeCount int64        `json:"pokeCount,omitempty"`
+	Pokes     []SensorPoke `json:"pokes,omitempty"`
+}
+
+// WhyInstance is the explanation for one instance of a mapped step. Its
+// baseline is the instance with the same item in the cache-origin run (for a
+// hit) or in the most-recent earlier run (otherwise).
+type WhyInstance struct {
+	Index    int         `json:"index"`
+	Item     string      `json:"item"`
+	Status   string      `json:"status"`
+	Verdict  WhyVerdict  `json:"verdict"`
+	Baseline WhyBaseline `json:"baseline"`
+	Diff     *BlobDiff   `json:"diff,omitempty"`
 }
 
 // ErrTaskRunNotFound is returned when no task matching the given id/name exists
 // in the run.
 var ErrTaskRunNotFound = errors.New("run: task not found in run")
 
+// whyPokeLimit caps how many of a sensor's most recent pokes an explanation
+// lists.
+const whyPokeLimit = 20
+
 // WhyTask explains why the task identified by taskRef (a task UUID or a task
 // name) in run runID executed / hit cache / re-ran. taskRef is matched first as
 // a UUID against task_id, then as a task name within the run's job.
@@ -151,10 +177,92 @@ func (s *Store) WhyTask(ctx context.Context, runID uuid.UUID, taskRef string) (*
 	}
 	exp.Diff = diff
 
+	instances, err := s.whyInstances(ctx, taskRun, jobRun.JobID, jobRun.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	exp.Instances = instances
+
+	pokeCount, pokes, err := s.SensorPokes(ctx, runID, taskRun.TaskID, whyPokeLimit)
+	if err != nil {
+		return nil, err
+	}
+	exp.PokeCount = pokeCount
+	exp.Pokes = pokes
+
 	exp.Summary = summarize(exp)
 	return exp, nil
 }
 
+// whyInstances explains every instance of a mapped task run; it returns nil
+// for unmapped tasks.
+func (s *Store) whyInstances(ctx context.Context, group *models.TaskRun, jobID uuid.UUID, subjectStartedAt time.Time) ([]WhyInstance, error) {
+	db := s.db.WithContext(ctx)
+
+	var rows []models.TaskRunInstance
+	if err := db.Where("task_run_id = ?", group.ID).
+		Order("item_index ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	out := make([]WhyInstance, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		inst := WhyInstance{
+			Index:    row.Index,
+			Item:     row.Item,
+			Status:   row.Status,
+			Baseline: WhyBaseline{Kind: "none"},
+		}
+		switch TaskStatus(row.Status) {
+		case TaskStatusCached:
+			inst.Verdict = VerdictCacheHit
+		case TaskStatusSucceeded, TaskStatusFailed:
+			if row.Hash != "" {
+				inst.Verdict = VerdictCacheMiss
+			} else {
+				inst.Verdict = VerdictCacheOff
+			}
+		default:
+			inst.Verdict = VerdictUnknown
+		}
+
+		baseline := db.Model(&models.TaskRunInstance{}).
+			Joins("JOIN job_runs ON job_runs.id = task_run_instances.job_run_id").
+			Where("task_run_instances.task_id = ? AND task_run_instances.item = ? AND task_run_instances.hash_input_blob IS NOT NULL", row.TaskID, row.Item)
+		if inst.Verdict == VerdictCacheHit && row.CacheOriginRunID != nil {
+			baseline = baseline.Where("task_run_instances.job_run_id = ?", *row.CacheOriginRunID)
+		} else {
+			baseline = baseline.Where("job_runs.job_id = ? AND task_run_instances.job_run_id <> ? AND job_runs.started_at < ?", jobID, row.JobRunID, subjectStartedAt).
+				Order("job_runs.started_at DESC")
+		}
+		var prior models.TaskRunInstance
+		err := baseline.Select("task_run_instances.*").First(&prior).Error
+		switch {
+		case err == nil:
+			kind := "prior_run"
+			if inst.Verdict == VerdictCacheHit {
+				kind = "cache_origin"
+			}
+			inst.Baseline = WhyBaseline{Kind: kind, RunID: &prior.JobRunID, TaskRunID: &prior.TaskRunID, StartedAt: prior.StartedAt}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+
+		diff, err := DiffHashInputBlobs(row.HashInputBlob, prior.HashInputBlob)
+		if err != nil {
+			return nil, err
+		}
+		inst.Diff = diff
+		out = append(out, inst)
+	}
+	return out, nil
+}
+
 // resolveTaskRun finds the task_runs row for taskRef in the run, returning the
 // row and the resolved task name. taskRef is tried as a task UUID first, then as
 // a task name (looked up via the job's tasks table).
@@ -266,6 +374,7 @@ func (s *Store) loadTrigger(ctx context.Context, jobRun *models.JobRun) WhyTrigg
 //     hash) if the origin task-run row is gone.
 //   - Cache MISS / OFF: the most-recent earlier run of the same task that has a
 //     persisted blob, so the diff names what changed and forced the re-run.
+//
 // subjectStartedAt is the subject run's start time (already loaded by the
 // caller); the prior-run lookup uses it to consider only strictly-earlier runs,
 // avoiding a redundant re-query.
@@ -339,6 +448,29 @@ func (s *Store) resolveBaseline(ctx context.Context, subject *models.TaskRun, jo
 // explanation: the headline discriminating field for a miss, or the
 // identical-inputs proof for a hit.
 func summarize(exp *WhyExplanation) string {
+	if len(exp.Instances) > 0 {
+		var cached, executed, failed int
+		for _, inst := range exp.Instances {
+			switch TaskStatus(inst.Status) {
+			case TaskStatusCached:
+				cached++
+			case TaskStatusSucceeded:
+				executed++
+			case TaskStatusFailed:
+				executed++
+				failed++
+			}
+		}
+		return fmt.Sprintf("mapped step %q has %d instance(s): %d cached, %d executed, %d failed", exp.TaskName, len(exp.Instances), cached, executed, failed)
+	}
+	if len(exp.Pokes) > 0 {
+		last := exp.Pokes[len(exp.Pokes)-1]
+		state := "not satisfied"
+		if last.Satisfied {
+			state = "satisfied"
+		}
+		return fmt.Sprintf("sensor %q is %s after %d %s poke(s); last poke %s: %s", exp.TaskName, exp.Status, exp.PokeCount, last.Probe, state, last.Detail)
+	}
 	switch exp.Verdict {
 	case VerdictCacheHit:
 		if exp.Diff != nil && exp.Diff.HashEqual {
@@ -89,6 +89,7 @@ type hashInputBlob struct {
 	PredecessorHashes    []string                     `json:"predecessorHashes,omitempty"`
 	PredecessorOutputs   map[string]map[string]string `json:"predecessorOutputs,omitempty"`
 	RunParams            map[string]string            `json:"runParams,omitempty"`
+	MapItem              *string                      `json:"mapItem,omitempty"`
 	CacheVersion         int                          `json:"cacheVersion"`
 
 	Oversized *oversizedBlob `json:"oversized,omitempty"`
@@ -234,6 +235,7 @@ func diffBlobs(before, after *hashInputBlob) []FieldChange {
 	addScalar("resolvedImageDigest", before.ResolvedImageDigest, after.ResolvedImageDigest)
 	addScalar("command", joinCommand(before.Command), joinCommand(after.Command))
 	addScalar("workDir", before.WorkDir, after.WorkDir)
+	addScalar("mapItem", derefString(before.MapItem), derefString(after.MapItem))
 	if before.CacheVersion != after.CacheVersion {
 		changes = append(changes, FieldChange{
 			Field:  "cacheVersion",
@@ -271,6 +273,15 @@ func joinCommand(cmd []string) string {
 	return string(b)
 }
 
+// derefString renders an optional blob string; absent and empty compare equal,
+// which only matters for a mapped instance whose item is itself empty.
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
 func diffEnv(before, after map[string]envBlobValue) []FieldChange {
 	var changes []FieldChange
 	for _, key := range unionKeysEnv(before, after) {
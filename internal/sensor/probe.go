@@ -0,0 +1,229 @@
+package sensor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/freshness"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// httpProbeTimeout bounds a single HTTP poke.
+	httpProbeTimeout = 30 * time.Second
+	// httpProbeBodyLimit caps how much of a response body is drained so the
+	// connection can be reused.
+	httpProbeBodyLimit = 64 << 10
+	// jobProbeRunLimit caps how many recent succeeded runs a job probe scans
+	// for a matching logical date.
+	jobProbeRunLimit = 100
+)
+
+// httpClient performs HTTP pokes. Tests replace it.
+var httpClient = &http.Client{Timeout: httpProbeTimeout}
+
+// Result is what one poke observed.
+type Result struct {
+	Satisfied bool
+	// Detail describes the observation, or why the probe could not tell.
+	Detail string
+	// Output is the step output of a satisfied poke.
+	Output map[string]string
+}
+
+// Probe evaluates the sensor's probe once against data. It returns an error
+// only when the probe's configuration cannot be rendered for this run.
+func Probe(ctx context.Context, db *gorm.DB, spec jobdefschema.StepSensor, data jobdefschema.RunTemplateData) (Result, error) {
+	switch {
+	case spec.HTTP != nil:
+		return probeHTTP(ctx, *spec.HTTP, data)
+	case spec.Job != nil:
+		return probeJob(ctx, db, *spec.Job, data), nil
+	case spec.Dataset != nil:
+		return probeDataset(ctx, db, *spec.Dataset, data)
+	case spec.File != nil:
+		return probeFile(*spec.File, data)
+	}
+	return Result{}, errors.New("sensor has no probe")
+}
+
+func probeHTTP(ctx context.Context, probe jobdefschema.SensorHTTP, data jobdefschema.RunTemplateData) (Result, error) {
+	target, err := jobdefschema.RenderRunTemplate("sensor.http.url", probe.URL, data)
+	if err != nil {
+		return Result{}, err
+	}
+	headers, err := jobdefschema.RenderRunValues("sensor.http.headers", probe.Headers, data)
+	if err != nil {
+		return Result{}, err
+	}
+	method := probe.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	expect := probe.ExpectStatus
+	if len(expect) == 0 {
+		expect = []int{http.StatusOK}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("sensor.http.url: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Result{Detail: fmt.Sprintf("%s %s: %v", method, target, err)}, nil
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, httpProbeBodyLimit))
+	_ = resp.Body.Close()
+
+	result := Result{
+		Satisfied: slices.Contains(expect, resp.StatusCode),
+		Detail:    fmt.Sprintf("%s %s returned %d", method, target, resp.StatusCode),
+	}
+	if result.Satisfied {
+		result.Output = map[string]string{"url": target, "status_code": strconv.Itoa(resp.StatusCode)}
+	}
+	return result, nil
+}
+
+func probeJob(ctx context.Context, db *gorm.DB, probe jobdefschema.SensorJob, data jobdefschema.RunTemplateData) Result {
+	var rows []struct {
+		ID          uuid.UUID
+		CompletedAt *time.Time
+		Params      datatypes.JSON
+	}
+	query := db.WithContext(ctx).Table("job_runs AS jr").
+		Select("jr.id AS id, jr.completed_at AS completed_at, jr.params AS params").
+		Joins("JOIN jobs AS j ON j.id = jr.job_id").
+		Where("j.namespace = ? AND j.alias = ? AND j.deleted_at IS NULL AND jr.status = ? AND jr.completed_at IS NOT NULL",
+			jobdefschema.NamespaceOrDefault(probe.Namespace), probe.Alias, string(run.StatusSucceeded)).
+		Order("jr.completed_at DESC")
+	if probe.MaxAge > 0 {
+		query = query.Where("jr.completed_at >= ?", time.Now().UTC().Add(-probe.MaxAge))
+	}
+	if probe.SameLogicalDate {
+		query = query.Limit(jobProbeRunLimit)
+	} else {
+		query = query.Limit(1)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return Result{Detail: fmt.Sprintf("query runs of job %q: %v", probe.Alias, err)}
+	}
+
+	for _, row := range rows {
+		if probe.SameLogicalDate && !sameLogicalDate(row.Params, data.LogicalDate) {
+			continue
+		}
+		return Result{
+			Satisfied: true,
+			Detail:    fmt.Sprintf("job %q run %s succeeded at %s", probe.Alias, row.ID, row.CompletedAt.UTC().Format(time.RFC3339)),
+			Output: map[string]string{
+				"run_id":       row.ID.String(),
+				"completed_at": row.CompletedAt.UTC().Format(time.RFC3339),
+			},
+		}
+	}
+
+	detail := fmt.Sprintf("no succeeded run of job %q", probe.Alias)
+	if probe.MaxAge > 0 {
+		detail += fmt.Sprintf(" within %s", probe.MaxAge)
+	}
+	if probe.SameLogicalDate {
+		detail += fmt.Sprintf(" for logical date %s", data.LogicalDate.Format(time.RFC3339))
+	}
+	return Result{Detail: detail}
+}
+
+// sameLogicalDate reports whether a run's params carry a logical_date equal
+// to want.
+func sameLogicalDate(raw datatypes.JSON, want time.Time) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var params map[string]string
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return false
+	}
+	got, err := time.Parse(time.RFC3339, params[jobdefschema.ParamLogicalDate])
+	return err == nil && got.Equal(want)
+}
+
+func probeDataset(ctx context.Context, db *gorm.DB, probe jobdefschema.SensorDataset, data jobdefschema.RunTemplateData) (Result, error) {
+	target, err := jobdefschema.RenderRunTemplate("sensor.dataset.watermark", probe.Watermark, data)
+	if err != nil {
+		return Result{}, err
+	}
+	var namespace *string
+	if probe.Namespace != "" {
+		namespace = &probe.Namespace
+	}
+	state, ok, err := freshness.NewStore(db).Get(ctx, namespace, probe.Name)
+	if err != nil {
+		return Result{Detail: fmt.Sprintf("read dataset %q: %v", probe.Name, err)}, nil
+	}
+	if !ok {
+		return Result{Detail: fmt.Sprintf("dataset %q has no recorded state", probe.Name)}, nil
+	}
+
+	var result Result
+	if target == "" {
+		result.Satisfied = state.Status == models.DatasetStatusFresh
+		result.Detail = fmt.Sprintf("dataset %q is %s", probe.Name, state.Status)
+	} else {
+		result.Satisfied = freshness.WatermarkReached(state.Watermark, target)
+		result.Detail = fmt.Sprintf("dataset %q watermark %q, want %q", probe.Name, state.Watermark, target)
+	}
+	if result.Satisfied {
+		result.Output = map[string]string{"watermark": state.Watermark}
+	}
+	return result, nil
+}
+
+func probeFile(probe jobdefschema.SensorFile, data jobdefschema.RunTemplateData) (Result, error) {
+	rel, err := jobdefschema.RenderRunTemplate("sensor.file.path", probe.Path, data)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := jobdefschema.ValidateSensorFilePath(rel); err != nil {
+		return Result{}, fmt.Errorf("sensor.file.path: %w", err)
+	}
+	if probe.HostPath == "" {
+		return Result{}, fmt.Errorf("sensor.file: volume %q has no resolved bind source", probe.Volume)
+	}
+
+	full := filepath.Join(probe.HostPath, filepath.FromSlash(rel))
+	info, err := os.Stat(full)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Result{Detail: fmt.Sprintf("%s does not exist", full)}, nil
+	case err != nil:
+		return Result{Detail: fmt.Sprintf("stat %s: %v", full, err)}, nil
+	}
+	return Result{
+		Satisfied: true,
+		Detail:    fmt.Sprintf("%s exists (%d bytes)", full, info.Size()),
+		Output: map[string]string{
+			"path":        full,
+			"size":        strconv.FormatInt(info.Size(), 10),
+			"modified_at": info.ModTime().UTC().Format(time.RFC3339),
+		},
+	}, nil
+}
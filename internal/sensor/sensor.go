@@ -0,0 +1,131 @@
+// Package sensor runs sensor steps. A step with `type: sensor` runs no
+// container: its executor evaluates the step's probe every pokeInterval until
+// the condition holds, recording each poke on the run. In poke mode the
+// executor waits in place; in reschedule mode it releases the task between
+// pokes. Both the local executor and distributed workers wait through Await.
+package sensor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+)
+
+// Poke results recorded on caesium_sensor_pokes_total.
+const (
+	resultSatisfied   = "satisfied"
+	resultUnsatisfied = "unsatisfied"
+)
+
+// Spec decodes a task's persisted sensor configuration. It returns nil for
+// tasks that are not sensors.
+func Spec(task *models.Task) (*jobdefschema.StepSensor, error) {
+	if task == nil || len(task.SensorConfig) == 0 {
+		return nil, nil
+	}
+	var spec jobdefschema.StepSensor
+	if err := json.Unmarshal(task.SensorConfig, &spec); err != nil {
+		return nil, fmt.Errorf("task %s: decode sensor config: %w", task.ID, err)
+	}
+	return &spec, nil
+}
+
+// Outcome is how a call to Await ended.
+type Outcome struct {
+	// Satisfied is set when the probe's condition held; Output carries what
+	// the probe observed and becomes the step's output.
+	Satisfied bool
+	Output    map[string]string
+	// TimedOut is set when the sensor's timeout passed first; Reason
+	// describes the last poke.
+	TimedOut bool
+	Reason   string
+	// RescheduleAt is set in reschedule mode when the condition did not
+	// hold: the executor releases the task until then.
+	RescheduleAt time.Time
+}
+
+// Await pokes the sensor until its condition holds, its timeout passes, or,
+// in reschedule mode, after a single unsatisfied poke. The timeout is measured
+// from the task run's first recorded poke. Errors are returned only for
+// invalid configuration (a template that fails to render) and store failures;
+// a probe that cannot reach its target is an unsatisfied poke.
+func Await(ctx context.Context, store *run.Store, runID, taskID uuid.UUID, spec jobdefschema.StepSensor, data jobdefschema.RunTemplateData, jobAlias string) (Outcome, error) {
+	interval := spec.PokeInterval
+	if interval <= 0 {
+		interval = jobdefschema.DefaultSensorPokeInterval
+	}
+	probe := spec.Probe()
+
+	for {
+		result, err := Probe(ctx, store.DB(), spec, data)
+		if err != nil {
+			return Outcome{}, err
+		}
+		now := time.Now().UTC()
+		first, err := store.RecordSensorPoke(ctx, runID, taskID, run.SensorPoke{
+			Probe:     probe,
+			Satisfied: result.Satisfied,
+			Detail:    result.Detail,
+			At:        now,
+		})
+		if err != nil {
+			return Outcome{}, err
+		}
+		if result.Satisfied {
+			metrics.SensorPokesTotal.WithLabelValues(jobAlias, probe, resultSatisfied).Inc()
+			return Outcome{Satisfied: true, Output: result.Output}, nil
+		}
+		metrics.SensorPokesTotal.WithLabelValues(jobAlias, probe, resultUnsatisfied).Inc()
+
+		next := now.Add(interval)
+		if spec.Timeout > 0 {
+			deadline := first.Add(spec.Timeout)
+			if !now.Before(deadline) {
+				metrics.SensorTimeoutsTotal.WithLabelValues(jobAlias, probe).Inc()
+				return Outcome{TimedOut: true, Reason: fmt.Sprintf("sensor timed out after %s: %s", spec.Timeout, result.Detail)}, nil
+			}
+			if next.After(deadline) {
+				next = deadline
+			}
+		}
+		if spec.Mode == jobdefschema.SensorModeReschedule {
+			return Outcome{RescheduleAt: next}, nil
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Outcome{}, context.Cause(ctx)
+		case <-timer.C:
+		}
+	}
+}
+
+// Failure returns the error a timed-out sensor fails its step with. It
+// returns nil when the sensor was satisfied, rescheduled, or skips on
+// timeout (see Skipped).
+func Failure(spec jobdefschema.StepSensor, o Outcome) error {
+	if !o.TimedOut || spec.OnTimeout == jobdefschema.SensorOnTimeoutSkip {
+		return nil
+	}
+	return errors.New(o.Reason)
+}
+
+// Skipped reports whether a timed-out sensor skips its step, along with the
+// skip reason recorded on the task run.
+func Skipped(spec jobdefschema.StepSensor, o Outcome) (string, bool) {
+	if !o.TimedOut || spec.OnTimeout != jobdefschema.SensorOnTimeoutSkip {
+		return "", false
+	}
+	return o.Reason, true
+}
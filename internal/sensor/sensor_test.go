@@ -0,0 +1,197 @@
+package sensor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestProbeHTTP(t *testing.T) {
+	ready := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "2026-10-17", r.URL.Query().Get("date"))
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	spec := jobdefschema.StepSensor{HTTP: &jobdefschema.SensorHTTP{
+		URL:          srv.URL + "/ready?date={{ ds .LogicalDate }}",
+		Headers:      map[string]string{"Authorization": "Bearer token"},
+		ExpectStatus: []int{http.StatusOK, http.StatusNoContent},
+	}}
+	data := jobdefschema.RunTemplateData{LogicalDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}
+
+	result, err := Probe(context.Background(), nil, spec, data)
+	require.NoError(t, err)
+	require.False(t, result.Satisfied)
+	require.Contains(t, result.Detail, "returned 503")
+
+	ready = true
+	result, err = Probe(context.Background(), nil, spec, data)
+	require.NoError(t, err)
+	require.True(t, result.Satisfied)
+	require.Equal(t, "204", result.Output["status_code"])
+}
+
+func TestProbeFile(t *testing.T) {
+	root := t.TempDir()
+	spec := jobdefschema.StepSensor{File: &jobdefschema.SensorFile{
+		Volume:   "landing",
+		Path:     "drops/{{ ds_nodash .LogicalDate }}.csv",
+		HostPath: root,
+	}}
+	data := jobdefschema.RunTemplateData{LogicalDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}
+
+	result, err := Probe(context.Background(), nil, spec, data)
+	require.NoError(t, err)
+	require.False(t, result.Satisfied)
+	require.Contains(t, result.Detail, "does not exist")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "drops"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "drops", "20261017.csv"), []byte("id\n1\n"), 0o644))
+	result, err = Probe(context.Background(), nil, spec, data)
+	require.NoError(t, err)
+	require.True(t, result.Satisfied)
+	require.Equal(t, filepath.Join(root, "drops", "20261017.csv"), result.Output["path"])
+	require.Equal(t, "5", result.Output["size"])
+
+	spec.File.Path = "{{ .Params.path }}"
+	data.Params = map[string]string{"path": "../etc/passwd"}
+	_, err = Probe(context.Background(), nil, spec, data)
+	require.ErrorContains(t, err, `must not contain ".."`)
+}
+
+func TestProbeJob(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	jobID := createJob(t, db, "nightly-extract")
+
+	logicalDate := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	spec := jobdefschema.StepSensor{Job: &jobdefschema.SensorJob{Alias: "nightly-extract", SameLogicalDate: true}}
+	data := jobdefschema.RunTemplateData{LogicalDate: logicalDate}
+
+	createJobRun(t, db, jobID, run.StatusSucceeded, logicalDate.AddDate(0, 0, -1))
+	createJobRun(t, db, jobID, run.StatusFailed, logicalDate)
+	result, err := Probe(context.Background(), db, spec, data)
+	require.NoError(t, err)
+	require.False(t, result.Satisfied)
+	require.Contains(t, result.Detail, "for logical date 2026-10-17T00:00:00Z")
+
+	want := createJobRun(t, db, jobID, run.StatusSucceeded, logicalDate)
+	result, err = Probe(context.Background(), db, spec, data)
+	require.NoError(t, err)
+	require.True(t, result.Satisfied)
+	require.Equal(t, want.String(), result.Output["run_id"])
+
+	spec.Job = &jobdefschema.SensorJob{Alias: "nightly-extract", MaxAge: time.Nanosecond}
+	result, err = Probe(context.Background(), db, spec, data)
+	require.NoError(t, err)
+	require.False(t, result.Satisfied)
+}
+
+func TestAwaitReschedulesAndTimesOut(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	store := run.NewStore(db)
+	runID, taskID := registerSensorTaskRun(t, store, db)
+	ctx := context.Background()
+
+	spec := jobdefschema.StepSensor{
+		File:         &jobdefschema.SensorFile{Volume: "landing", Path: "ready", HostPath: t.TempDir()},
+		PokeInterval: time.Hour,
+		Timeout:      90 * time.Minute,
+		Mode:         jobdefschema.SensorModeReschedule,
+		OnTimeout:    jobdefschema.SensorOnTimeoutSkip,
+	}
+	before := time.Now().UTC()
+	outcome, err := Await(ctx, store, runID, taskID, spec, jobdefschema.RunTemplateData{}, "sensed")
+	require.NoError(t, err)
+	require.False(t, outcome.Satisfied)
+	require.WithinDuration(t, before.Add(time.Hour), outcome.RescheduleAt, 5*time.Second)
+
+	// Backdate the first poke past the timeout; the next poke gives up.
+	require.NoError(t, db.Model(&models.SensorPoke{}).Where("job_run_id = ?", runID).
+		Update("created_at", before.Add(-2*time.Hour)).Error)
+	outcome, err = Await(ctx, store, runID, taskID, spec, jobdefschema.RunTemplateData{}, "sensed")
+	require.NoError(t, err)
+	require.True(t, outcome.TimedOut)
+	require.Nil(t, Failure(spec, outcome))
+	reason, skipped := Skipped(spec, outcome)
+	require.True(t, skipped)
+	require.Contains(t, reason, "sensor timed out after 1h30m0s")
+
+	spec.OnTimeout = jobdefschema.SensorOnTimeoutFail
+	require.ErrorContains(t, Failure(spec, outcome), "does not exist")
+}
+
+func createJob(t *testing.T, db *gorm.DB, alias string) uuid.UUID {
+	t.Helper()
+	now := time.Now().UTC()
+	trigger := &models.Trigger{
+		ID:            uuid.New(),
+		Alias:         alias + "-trigger",
+		Type:          models.TriggerTypeCron,
+		Configuration: `{"cron":"0 * * * *","timezone":"UTC"}`,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, db.Create(trigger).Error)
+	job := &models.Job{ID: uuid.New(), Alias: alias, TriggerID: trigger.ID, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(job).Error)
+	return job.ID
+}
+
+func createJobRun(t *testing.T, db *gorm.DB, jobID uuid.UUID, status run.Status, logicalDate time.Time) uuid.UUID {
+	t.Helper()
+	now := time.Now().UTC()
+	jobRun := &models.JobRun{
+		ID:          uuid.New(),
+		JobID:       jobID,
+		Status:      string(status),
+		Params:      datatypes.JSON(`{"` + jobdefschema.ParamLogicalDate + `":"` + logicalDate.Format(time.RFC3339) + `"}`),
+		StartedAt:   now.Add(-time.Minute),
+		CompletedAt: &now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, db.Create(jobRun).Error)
+	return jobRun.ID
+}
+
+func registerSensorTaskRun(t *testing.T, store *run.Store, db *gorm.DB) (runID, taskID uuid.UUID) {
+	t.Helper()
+	jobID := uuid.New()
+	runRecord, err := store.Start(jobID, nil)
+	require.NoError(t, err)
+
+	atom := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker}
+	require.NoError(t, db.Create(atom).Error)
+	task := &models.Task{
+		ID:           uuid.New(),
+		JobID:        jobID,
+		AtomID:       atom.ID,
+		Name:         "wait",
+		SensorConfig: datatypes.JSON(`{"job":{"alias":"upstream"}}`),
+	}
+	require.NoError(t, db.Create(task).Error)
+	require.NoError(t, store.RegisterTask(runRecord.ID, task, atom, 0))
+	return runRecord.ID, task.ID
+}
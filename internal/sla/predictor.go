@@ -0,0 +1,218 @@
+// Package sla predicts when in-flight runs will complete. An estimate combines
+// per-task duration percentiles from past task runs with the remaining
+// critical path through the job's DAG: the notification watcher compares it
+// against SLA deadlines to raise sla_at_risk, and the run API exposes it as
+// the run's ETA.
+package sla
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/dag"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPercentile is the task-duration percentile used when the caller
+	// passes none. p90 keeps at-risk alerts ahead of typical variance without
+	// chasing outliers.
+	DefaultPercentile = 90
+
+	// HistoryWindow is how many recent successful executions of a task feed
+	// its duration percentile.
+	HistoryWindow = 20
+
+	// MinSamples is the history a task needs before it contributes to an
+	// estimate. Tasks with less weigh zero, so a new step can only make an
+	// estimate optimistic, never raise a false alarm.
+	MinSamples = 3
+)
+
+// finishedStatuses are task-run statuses that no longer contribute remaining
+// time.
+var finishedStatuses = map[string]struct{}{
+	"succeeded": {},
+	"failed":    {},
+	"skipped":   {},
+	"cached":    {},
+	"cancelled": {},
+}
+
+// Estimate is a predicted completion time for an in-flight run.
+type Estimate struct {
+	// ETA is the predicted completion time.
+	ETA time.Time `json:"predicted_completion"`
+	// RemainingSeconds is the length of the remaining critical path.
+	RemainingSeconds float64 `json:"remaining_seconds"`
+	// CriticalPath lists the unfinished tasks on the longest remaining path,
+	// in execution order.
+	CriticalPath []uuid.UUID `json:"critical_path,omitempty"`
+	// Percentile is the task-duration percentile the estimate is built on.
+	Percentile int `json:"percentile"`
+	// EstimatedTasks and UnestimatedTasks count the unfinished tasks with and
+	// without enough history to estimate.
+	EstimatedTasks   int `json:"estimated_tasks"`
+	UnestimatedTasks int `json:"unestimated_tasks,omitempty"`
+}
+
+// Predictor estimates run completion from task-run history.
+type Predictor struct {
+	db         *gorm.DB
+	percentile int
+}
+
+// NewPredictor constructs a predictor. A percentile outside 1..100 defaults to
+// DefaultPercentile.
+func NewPredictor(db *gorm.DB, percentile int) *Predictor {
+	if percentile <= 0 || percentile > 100 {
+		percentile = DefaultPercentile
+	}
+	return &Predictor{db: db, percentile: percentile}
+}
+
+// Estimate predicts when the run will complete as of now. It returns nil when
+// the run has unfinished tasks but none of them has enough history to
+// estimate.
+func (p *Predictor) Estimate(ctx context.Context, jobID, runID uuid.UUID, now time.Time) (*Estimate, error) {
+	db := p.db.WithContext(ctx)
+
+	// Mapped instances share their group's task_id; the group row stands for
+	// the whole step.
+	var taskRuns []models.TaskRun
+	if err := db.Select("task_id", "status", "started_at").
+		Where("job_run_id = ? AND map_parent_id IS NULL", runID).
+		Find(&taskRuns).Error; err != nil {
+		return nil, err
+	}
+
+	unfinished := make(map[uuid.UUID]models.TaskRun, len(taskRuns))
+	for _, tr := range taskRuns {
+		if _, done := finishedStatuses[tr.Status]; !done {
+			unfinished[tr.TaskID] = tr
+		}
+	}
+	est := &Estimate{ETA: now, Percentile: p.percentile}
+	if len(unfinished) == 0 {
+		return est, nil
+	}
+
+	taskIDs := make([]uuid.UUID, 0, len(unfinished))
+	for taskID := range unfinished {
+		taskIDs = append(taskIDs, taskID)
+	}
+	durations, err := p.taskDurations(db, taskIDs, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]time.Duration, len(unfinished))
+	for taskID, tr := range unfinished {
+		typical, ok := durations[taskID]
+		if !ok {
+			est.UnestimatedTasks++
+			weights[taskID.String()] = 0
+			continue
+		}
+		est.EstimatedTasks++
+
+		remaining := typical
+		if tr.Status == "running" && tr.StartedAt != nil {
+			remaining -= now.Sub(*tr.StartedAt)
+		}
+		weights[taskID.String()] = max(remaining, 0)
+	}
+	if est.EstimatedTasks == 0 {
+		return nil, nil
+	}
+
+	var edges []models.TaskEdge
+	if err := db.Select("from_task_id", "to_task_id").
+		Where("job_id = ?", jobID).
+		Find(&edges).Error; err != nil {
+		return nil, err
+	}
+	successors := make(map[string][]string, len(unfinished))
+	for _, edge := range edges {
+		_, fromOpen := unfinished[edge.FromTaskID]
+		_, toOpen := unfinished[edge.ToTaskID]
+		if fromOpen && toOpen {
+			from := edge.FromTaskID.String()
+			successors[from] = append(successors[from], edge.ToTaskID.String())
+		}
+	}
+
+	remaining, path := dag.CriticalPath(successors, weights)
+	est.ETA = now.Add(remaining)
+	est.RemainingSeconds = remaining.Seconds()
+	for _, name := range path {
+		if id, err := uuid.Parse(name); err == nil {
+			est.CriticalPath = append(est.CriticalPath, id)
+		}
+	}
+	return est, nil
+}
+
+// taskDurations returns the configured percentile of each task's recent
+// successful durations, excluding the run being estimated. Tasks with fewer
+// than MinSamples executions are absent. A single query ranks every task's
+// history and keeps its HistoryWindow most recent executions, so the cost of
+// an estimate does not grow with the number of unfinished tasks.
+func (p *Predictor) taskDurations(db *gorm.DB, taskIDs []uuid.UUID, runID uuid.UUID) (map[uuid.UUID]time.Duration, error) {
+	var history []struct {
+		TaskID      uuid.UUID
+		StartedAt   *time.Time
+		CompletedAt *time.Time
+	}
+	if err := db.Raw(`
+SELECT task_id, started_at, completed_at
+FROM (
+	SELECT task_id, started_at, completed_at,
+		ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY completed_at DESC) AS recency
+	FROM task_runs
+	WHERE task_id IN ?
+		AND job_run_id <> ?
+		AND map_parent_id IS NULL
+		AND status = ?
+		AND started_at IS NOT NULL
+		AND completed_at IS NOT NULL
+) AS recent
+WHERE recency <= ?`, taskIDs, runID, "succeeded", HistoryWindow).
+		Scan(&history).Error; err != nil {
+		return nil, err
+	}
+
+	byTask := make(map[uuid.UUID][]time.Duration, len(taskIDs))
+	for _, tr := range history {
+		if tr.StartedAt == nil || tr.CompletedAt == nil {
+			continue
+		}
+		if d := tr.CompletedAt.Sub(*tr.StartedAt); d >= 0 {
+			byTask[tr.TaskID] = append(byTask[tr.TaskID], d)
+		}
+	}
+	typical := make(map[uuid.UUID]time.Duration, len(byTask))
+	for taskID, durations := range byTask {
+		if len(durations) < MinSamples {
+			continue
+		}
+		typical[taskID] = Percentile(durations, p.percentile)
+	}
+	return typical, nil
+}
+
+// Percentile returns the nearest-rank percentile of durations. It sorts
+// durations in place and returns zero for an empty slice.
+func Percentile(durations []time.Duration, percentile int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := int(math.Ceil(float64(percentile) / 100 * float64(len(durations))))
+	rank = min(max(rank, 1), len(durations))
+	return durations[rank-1]
+}
@@ -0,0 +1,158 @@
+package sla
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{5 * time.Second, time.Second, 3 * time.Second, 2 * time.Second, 4 * time.Second}
+	require.Equal(t, 3*time.Second, Percentile(durations, 50))
+	require.Equal(t, 5*time.Second, Percentile(durations, 90))
+	require.Equal(t, time.Second, Percentile(durations, 1))
+	require.Zero(t, Percentile(nil, 90))
+}
+
+func TestEstimateFollowsRemainingCriticalPath(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// extract -> {transform (10m), audit (2m)} -> load (5m); extract is done
+	// and transform has been running for 4m.
+	jobID := uuid.New()
+	extract, transform, audit, load := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	seedEdges(t, db, jobID, [][2]uuid.UUID{{extract, transform}, {extract, audit}, {transform, load}, {audit, load}})
+	seedHistory(t, db, transform, 10*time.Minute, now)
+	seedHistory(t, db, audit, 2*time.Minute, now)
+	seedHistory(t, db, load, 5*time.Minute, now)
+
+	runID := uuid.New()
+	transformStarted := now.Add(-4 * time.Minute)
+	seedTaskRun(t, db, runID, extract, "succeeded", nil, nil)
+	seedTaskRun(t, db, runID, transform, "running", &transformStarted, nil)
+	seedTaskRun(t, db, runID, audit, "pending", nil, nil)
+	seedTaskRun(t, db, runID, load, "pending", nil, nil)
+
+	est, err := NewPredictor(db, 0).Estimate(context.Background(), jobID, runID, now)
+	require.NoError(t, err)
+	require.NotNil(t, est)
+	require.Equal(t, now.Add(11*time.Minute), est.ETA)
+	require.Equal(t, (11 * time.Minute).Seconds(), est.RemainingSeconds)
+	require.Equal(t, []uuid.UUID{transform, load}, est.CriticalPath)
+	require.Equal(t, DefaultPercentile, est.Percentile)
+	require.Equal(t, 3, est.EstimatedTasks)
+	require.Zero(t, est.UnestimatedTasks)
+}
+
+func TestEstimateWithoutHistory(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	now := time.Now().UTC()
+
+	jobID, taskID, runID := uuid.New(), uuid.New(), uuid.New()
+	// Two samples are below MinSamples.
+	seedHistoryN(t, db, taskID, time.Minute, now, MinSamples-1)
+	seedTaskRun(t, db, runID, taskID, "pending", nil, nil)
+
+	est, err := NewPredictor(db, 50).Estimate(context.Background(), jobID, runID, now)
+	require.NoError(t, err)
+	require.Nil(t, est)
+
+	// A finished run is estimated to complete now.
+	doneRun := uuid.New()
+	seedTaskRun(t, db, doneRun, taskID, "succeeded", nil, nil)
+	est, err = NewPredictor(db, 50).Estimate(context.Background(), jobID, doneRun, now)
+	require.NoError(t, err)
+	require.NotNil(t, est)
+	require.Equal(t, now, est.ETA)
+}
+
+func TestEstimateUsesRecentHistoryAndIgnoresMapInstances(t *testing.T) {
+	db := testutil.OpenTestDB(t)
+	t.Cleanup(func() { testutil.CloseDB(db) })
+	now := time.Now().UTC().Truncate(time.Second)
+
+	jobID, taskID, runID := uuid.New(), uuid.New(), uuid.New()
+	// The HistoryWindow most recent executions took 10m; older ones took 1h
+	// and fall outside the window.
+	seedHistoryN(t, db, taskID, 10*time.Minute, now, HistoryWindow)
+	for i := range MinSamples {
+		completed := now.Add(-time.Duration(HistoryWindow+i+1) * time.Hour)
+		started := completed.Add(-time.Hour)
+		seedTaskRun(t, db, uuid.New(), taskID, "succeeded", &started, &completed)
+	}
+
+	// The mapped group has run for 4m; its pending instance shares the task
+	// id and must not replace it.
+	groupStarted := now.Add(-4 * time.Minute)
+	seedTaskRun(t, db, runID, taskID, "running", &groupStarted, nil)
+	seedTaskRun(t, db, runID, taskID, "pending", nil, nil)
+	var group models.TaskRun
+	require.NoError(t, db.Where("job_run_id = ? AND status = ?", runID, "running").First(&group).Error)
+	require.NoError(t, db.Model(&models.TaskRun{}).
+		Where("job_run_id = ? AND status = ?", runID, "pending").
+		Update("map_parent_id", group.ID).Error)
+
+	est, err := NewPredictor(db, 0).Estimate(context.Background(), jobID, runID, now)
+	require.NoError(t, err)
+	require.NotNil(t, est)
+	require.Equal(t, now.Add(6*time.Minute), est.ETA)
+	require.Equal(t, 1, est.EstimatedTasks)
+}
+
+func seedEdges(t *testing.T, db *gorm.DB, jobID uuid.UUID, edges [][2]uuid.UUID) {
+	t.Helper()
+	now := time.Now().UTC()
+	for _, e := range edges {
+		require.NoError(t, db.Create(&models.TaskEdge{
+			ID:         uuid.New(),
+			JobID:      jobID,
+			FromTaskID: e[0],
+			ToTaskID:   e[1],
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}).Error)
+	}
+}
+
+func seedHistory(t *testing.T, db *gorm.DB, taskID uuid.UUID, d time.Duration, now time.Time) {
+	t.Helper()
+	seedHistoryN(t, db, taskID, d, now, MinSamples)
+}
+
+func seedHistoryN(t *testing.T, db *gorm.DB, taskID uuid.UUID, d time.Duration, now time.Time, n int) {
+	t.Helper()
+	for i := range n {
+		completed := now.Add(-time.Duration(i+1) * time.Hour)
+		started := completed.Add(-d)
+		seedTaskRun(t, db, uuid.New(), taskID, "succeeded", &started, &completed)
+	}
+}
+
+func seedTaskRun(t *testing.T, db *gorm.DB, runID, taskID uuid.UUID, status string, started, completed *time.Time) {
+	t.Helper()
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.TaskRun{
+		ID:          uuid.New(),
+		JobRunID:    runID,
+		TaskID:      taskID,
+		AtomID:      uuid.New(),
+		Engine:      models.AtomEngineDocker,
+		Image:       "alpine:3.23",
+		Status:      status,
+		StartedAt:   started,
+		CompletedAt: completed,
+		Attempt:     1,
+		MaxAttempts: 1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}).Error)
+}
@@ -0,0 +1,178 @@
+// Package tracing exports OpenTelemetry traces for run execution.
+//
+// A trace follows one run from the trigger that started it to the containers
+// that executed its tasks: the webhook request or cron tick, run admission,
+// each task's dispatch or claim, and the atom's create and wait. The run's
+// span context is persisted on its job run and task run rows as a W3C
+// traceparent, so a worker on another node continues the same trace when it
+// claims a task, and each task container receives it as TRACEPARENT.
+//
+// When tracing is disabled the global no-op provider is left in place: spans
+// cost nothing, but an incoming traceparent is still propagated.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnvTraceparent is the environment variable that carries the task span's
+// context into task containers, following the OpenTelemetry convention for
+// propagating context through the environment.
+const EnvTraceparent = "TRACEPARENT"
+
+const instrumentationName = "github.com/caesium-cloud/caesium"
+
+// Span attribute keys shared by every caesium span.
+const (
+	AttrJobID     = attribute.Key("caesium.job.id")
+	AttrJobAlias  = attribute.Key("caesium.job.alias")
+	AttrRunID     = attribute.Key("caesium.run.id")
+	AttrTaskID    = attribute.Key("caesium.task.id")
+	AttrTaskName  = attribute.Key("caesium.task.name")
+	AttrTriggerID = attribute.Key("caesium.trigger.id")
+	AttrNode      = attribute.Key("caesium.node")
+	AttrAttempt   = attribute.Key("caesium.task.attempt")
+	AttrEngine    = attribute.Key("caesium.atom.engine")
+	AttrImage     = attribute.Key("caesium.atom.image")
+)
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+func init() {
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Setup installs the global tracer provider described by vars and returns a
+// function that flushes and stops it. It is a no-op when tracing is disabled.
+func Setup(ctx context.Context, vars env.Environment) (func(context.Context) error, error) {
+	if !vars.TracingEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{}
+	if endpoint := strings.TrimSpace(vars.TracingOTLPEndpoint); endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	if headers := parseHeaders(vars.TracingOTLPHeaders); len(headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: create OTLP exporter: %w", err)
+	}
+
+	serviceName := strings.TrimSpace(vars.TracingServiceName)
+	if serviceName == "" {
+		serviceName = "caesium"
+	}
+	attrs := []attribute.KeyValue{attribute.String("service.name", serviceName)}
+	if node := strings.TrimSpace(vars.NodeAddress); node != "" {
+		attrs = append(attrs, attribute.String("service.instance.id", node))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(vars.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// StartFrom starts a span as a child of the span in ctx, or, when ctx holds
+// none, of the span described by traceparent. Workers use it to continue a
+// run's trace from the traceparent persisted on a claimed task.
+func StartFrom(ctx context.Context, traceparent, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = WithTraceparent(ctx, traceparent)
+	}
+	return Start(ctx, name, opts...)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	Fail(span, err)
+	span.End()
+}
+
+// Fail marks span as failed with err. A nil err or a context cancellation
+// leaves the span's status unset.
+func Fail(span trace.Span, err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Traceparent returns the W3C traceparent of the span in ctx, or "" when ctx
+// holds no valid span.
+func Traceparent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// WithTraceparent returns ctx with the span described by traceparent as its
+// remote parent. An empty or malformed traceparent returns ctx unchanged.
+func WithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+}
+
+// TraceID returns the trace ID carried by traceparent, or "" when it is
+// malformed.
+func TraceID(traceparent string) string {
+	sc := trace.SpanContextFromContext(WithTraceparent(context.Background(), traceparent))
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// Inject writes the trace context in ctx into carrier, such as the headers
+// of an outgoing request.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
+
+// Extract returns ctx with the trace context read from carrier, such as the
+// headers of an incoming request.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return propagator.Extract(ctx, carrier)
+}
+
+func parseHeaders(raw string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.TrimSpace(key) != "" {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caesium-cloud/caesium/pkg/env"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	ctx := WithTraceparent(context.Background(), testTraceparent)
+	require.Equal(t, testTraceparent, Traceparent(ctx))
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceID(testTraceparent))
+
+	require.Empty(t, Traceparent(context.Background()))
+	require.Empty(t, TraceID(""))
+	require.Empty(t, TraceID("not-a-traceparent"))
+	require.Equal(t, context.Background(), WithTraceparent(context.Background(), ""))
+}
+
+func TestStartFromContinuesPersistedTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, span := StartFrom(context.Background(), testTraceparent, "task.claim")
+	_, child := Start(ctx, "atom.create")
+	child.End()
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	claim := spans[1]
+	require.Equal(t, "task.claim", claim.Name())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", claim.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", claim.Parent().SpanID().String())
+	require.True(t, claim.Parent().IsRemote())
+	require.Equal(t, claim.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestStartFromPrefersSpanInContext(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, parent := Start(context.Background(), "task.dispatch.accept")
+	_, span := StartFrom(ctx, testTraceparent, "task.execute")
+	span.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestEndRecordsFailuresButNotCancellation(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, failed := Start(context.Background(), "failed")
+	End(failed, errors.New("boom"))
+	_, cancelled := Start(context.Background(), "cancelled")
+	End(cancelled, context.Canceled)
+	_, ok := Start(context.Background(), "ok")
+	End(ok, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, "boom", spans[0].Status().Description)
+	require.Equal(t, codes.Unset, spans[1].Status().Code)
+	require.Equal(t, codes.Unset, spans[2].Status().Code)
+}
+
+func TestInjectExtractHeaders(t *testing.T) {
+	headers := propagation.HeaderCarrier{}
+	Inject(WithTraceparent(context.Background(), testTraceparent), headers)
+	require.Equal(t, testTraceparent, headers.Get("Traceparent"))
+
+	ctx := Extract(context.Background(), headers)
+	require.Equal(t, testTraceparent, Traceparent(ctx))
+}
+
+func TestSetupDisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), env.Environment{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestParseHeaders(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"authorization": "Bearer abc",
+		"x-tenant":      "a=b",
+	}, parseHeaders(" authorization = Bearer abc ,x-tenant=a=b,, =skipped,novalue"))
+	require.Empty(t, parseHeaders(""))
+}
@@ -14,12 +14,16 @@ import (
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
 	runstore "github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/internal/trigger"
 	"github.com/caesium-cloud/caesium/pkg/db"
 	"github.com/caesium-cloud/caesium/pkg/env"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
 	"github.com/robfig/cron"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Cron struct {
@@ -28,6 +32,7 @@ type Cron struct {
 	id            uuid.UUID
 	location      *time.Location
 	defaultParams map[string]string
+	runTemplates  bool
 	catchup       bool
 	catchupOnce   sync.Once
 }
@@ -79,7 +84,12 @@ func New(t *models.Trigger) (*Cron, error) {
 		return nil, err
 	}
 
-	return &Cron{schedule: sched, id: t.ID, location: loc, defaultParams: defaultParams, catchup: catchup}, nil
+	runTemplates, err := extractRunTemplates(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cron{schedule: sched, id: t.ID, location: loc, defaultParams: defaultParams, runTemplates: runTemplates, catchup: catchup}, nil
 }
 
 func (c *Cron) Listen(ctx context.Context) {
@@ -117,10 +127,18 @@ func (c *Cron) fireAt(ctx context.Context, logicalDate time.Time) error {
 		"type", models.TriggerTypeCron,
 	)
 
+	// Each tick roots a trace; the runs it starts are its children.
+	ctx, span := tracing.Start(ctx, "trigger.cron", trace.WithNewRoot(), trace.WithAttributes(
+		tracing.AttrTriggerID.String(c.id.String()),
+		attribute.String("caesium.trigger.logical_date", logicalDate.UTC().Format(time.RFC3339)),
+	))
+	defer span.End()
+
 	req := &jsvc.ListRequest{TriggerID: c.id.String()}
 
 	jobs, err := jsvc.Service(ctx).List(req)
 	if err != nil {
+		tracing.Fail(span, err)
 		return err
 	}
 
@@ -147,8 +165,12 @@ func (c *Cron) fireAt(ctx context.Context, logicalDate time.Time) error {
 				continue
 			}
 		}
+		params, err := c.scheduledRunParams(j.Alias, logicalDate)
+		if err != nil {
+			log.Error("trigger params render failure", "id", c.id, "job_id", j.ID, "error", err)
+			continue
+		}
 		metrics.TriggerFiresTotal.WithLabelValues(j.ID.String(), string(models.TriggerTypeCron)).Inc()
-		params := c.scheduledRunParams(logicalDate)
 		go func() {
 			if err = job.New(j, job.WithParams(params)).Run(ctx); err != nil {
 				log.Error("job run failure", "id", j.ID, "error", err)
@@ -201,7 +223,11 @@ func (c *Cron) fireCatchup(ctx context.Context, jobs models.Jobs) {
 
 		for _, d := range missed {
 			logicalDate := d.UTC().Format(time.RFC3339)
-			params := c.scheduledRunParams(d)
+			params, err := c.scheduledRunParams(j.Alias, d)
+			if err != nil {
+				log.Error("catchup params render failure", "job_id", j.ID, "logical_date", logicalDate, "error", err)
+				continue
+			}
 			params["is_catchup"] = "true"
 
 			metrics.TriggerFiresTotal.WithLabelValues(j.ID.String(), string(models.TriggerTypeCron)).Inc()
@@ -217,18 +243,34 @@ func (c *Cron) fireCatchup(ctx context.Context, jobs models.Jobs) {
 	}
 }
 
-func (c *Cron) scheduledRunParams(logicalDate time.Time) map[string]string {
-	params := make(map[string]string, len(c.defaultParams)+1)
-	for k, v := range c.defaultParams {
-		params[k] = v
-	}
-
+// scheduledRunParams returns the params for a run of jobAlias scheduled at
+// logicalDate: the trigger's defaultParams, rendered against the run's
+// logical date and data interval when the job opted into run templates,
+// overlaid with those date params.
+func (c *Cron) scheduledRunParams(jobAlias string, logicalDate time.Time) (map[string]string, error) {
 	date := logicalDate
 	if c.location != nil {
 		date = logicalDate.In(c.location)
 	}
-	params["logical_date"] = date.UTC().Format(time.RFC3339)
-	return params
+	scheduled := job.LogicalDateParams(c.schedule, date, c.location)
+
+	defaults := c.defaultParams
+	if c.runTemplates {
+		data := jobdefschema.NewRunTemplateData("", jobAlias, scheduled, date)
+		var err error
+		if defaults, err = jobdefschema.RenderRunValues("defaultParams", c.defaultParams, data); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make(map[string]string, len(defaults)+len(scheduled)+1)
+	for k, v := range defaults {
+		params[k] = v
+	}
+	for k, v := range scheduled {
+		params[k] = v
+	}
+	return params, nil
 }
 
 func (c *Cron) ID() uuid.UUID {
@@ -274,6 +316,26 @@ func ParseSchedule(configuration string) (cron.Schedule, *time.Location, error)
 	return sched, loc, nil
 }
 
+// ParseDefaultParams returns the unrendered defaultParams from a trigger's
+// Configuration JSON string.
+func ParseDefaultParams(configuration string) (map[string]string, error) {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(configuration), &m); err != nil {
+		return nil, fmt.Errorf("cron: invalid trigger configuration: %w", err)
+	}
+	return extractDefaultParams(m)
+}
+
+// ParseRunTemplates reports whether a trigger's Configuration JSON string
+// enables rendering of its defaultParams.
+func ParseRunTemplates(configuration string) (bool, error) {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(configuration), &m); err != nil {
+		return false, fmt.Errorf("cron: invalid trigger configuration: %w", err)
+	}
+	return extractRunTemplates(m)
+}
+
 func extractExpression(cfg map[string]interface{}) (string, error) {
 	candidates := []string{"expression", "cron", "schedule"}
 	for _, key := range candidates {
@@ -320,6 +382,18 @@ func extractCatchup(cfg map[string]interface{}) (bool, error) {
 	}
 }
 
+func extractRunTemplates(cfg map[string]interface{}) (bool, error) {
+	raw, ok := cfg["runTemplates"]
+	if !ok || raw == nil {
+		return false, nil
+	}
+	v, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("runTemplates must be a boolean")
+	}
+	return v, nil
+}
+
 func extractDefaultParams(cfg map[string]interface{}) (map[string]string, error) {
 	raw, ok := cfg["defaultParams"]
 	if !ok || raw == nil {
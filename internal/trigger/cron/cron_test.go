@@ -3,17 +3,23 @@ package cron
 import (
 	"testing"
 	"time"
+
+	"github.com/robfig/cron"
 )
 
 func TestScheduledRunParamsInjectsLogicalDateAndPreservesDefaults(t *testing.T) {
 	c := &Cron{
+		schedule: mustParseSchedule(t, "0 6 * * *"),
 		defaultParams: map[string]string{
 			"region": "us-east-1",
 		},
 	}
 
 	logicalDate := time.Date(2026, 3, 31, 6, 0, 0, 0, time.UTC)
-	params := c.scheduledRunParams(logicalDate)
+	params, err := c.scheduledRunParams("etl", logicalDate)
+	if err != nil {
+		t.Fatalf("scheduledRunParams returned error: %v", err)
+	}
 
 	if params["region"] != "us-east-1" {
 		t.Fatalf("region = %q, want %q", params["region"], "us-east-1")
@@ -25,6 +31,7 @@ func TestScheduledRunParamsInjectsLogicalDateAndPreservesDefaults(t *testing.T)
 
 func TestScheduledRunParamsOverridesUserLogicalDate(t *testing.T) {
 	c := &Cron{
+		schedule: mustParseSchedule(t, "0 0 * * *"),
 		defaultParams: map[string]string{
 			"logical_date": "stale-value",
 			"env":          "prod",
@@ -32,7 +39,10 @@ func TestScheduledRunParamsOverridesUserLogicalDate(t *testing.T) {
 	}
 
 	logicalDate := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
-	params := c.scheduledRunParams(logicalDate)
+	params, err := c.scheduledRunParams("etl", logicalDate)
+	if err != nil {
+		t.Fatalf("scheduledRunParams returned error: %v", err)
+	}
 
 	if params["env"] != "prod" {
 		t.Fatalf("env = %q, want %q", params["env"], "prod")
@@ -42,6 +52,61 @@ func TestScheduledRunParamsOverridesUserLogicalDate(t *testing.T) {
 	}
 }
 
+func TestScheduledRunParamsSetsDataIntervalAndRendersDefaults(t *testing.T) {
+	c := &Cron{
+		schedule:     mustParseSchedule(t, "0 6 * * *"),
+		runTemplates: true,
+		defaultParams: map[string]string{
+			"partition": "dt={{ ds_add .LogicalDate -1 }}",
+			"target":    "{{ .JobAlias }}-{{ ds_nodash .DataIntervalStart }}",
+		},
+	}
+
+	logicalDate := time.Date(2026, 3, 31, 6, 0, 0, 0, time.UTC)
+	params, err := c.scheduledRunParams("etl", logicalDate)
+	if err != nil {
+		t.Fatalf("scheduledRunParams returned error: %v", err)
+	}
+
+	want := map[string]string{
+		"logical_date":        "2026-03-31T06:00:00Z",
+		"data_interval_start": "2026-03-30T06:00:00Z",
+		"data_interval_end":   "2026-03-31T06:00:00Z",
+		"partition":           "dt=2026-03-30",
+		"target":              "etl-20260330",
+	}
+	for key, value := range want {
+		if params[key] != value {
+			t.Fatalf("%s = %q, want %q", key, params[key], value)
+		}
+	}
+
+	c.defaultParams = map[string]string{"region": "{{ .Params.region }}"}
+	if _, err := c.scheduledRunParams("etl", logicalDate); err == nil {
+		t.Fatal("expected error for a missing param reference")
+	}
+
+	// Without the opt-in, defaults pass through verbatim.
+	c.runTemplates = false
+	c.defaultParams = map[string]string{"model": "{{ ref('orders') }}"}
+	params, err = c.scheduledRunParams("etl", logicalDate)
+	if err != nil {
+		t.Fatalf("scheduledRunParams returned error: %v", err)
+	}
+	if params["model"] != "{{ ref('orders') }}" {
+		t.Fatalf("model = %q, want it unrendered", params["model"])
+	}
+}
+
+func mustParseSchedule(t *testing.T, expr string) cron.Schedule {
+	t.Helper()
+	sched, _, err := ParseSchedule(`{"expression":"` + expr + `"}`)
+	if err != nil {
+		t.Fatalf("ParseSchedule returned error: %v", err)
+	}
+	return sched
+}
+
 func TestExtractExpressionPrefersExpression(t *testing.T) {
 	cfg := map[string]interface{}{
 		"expression": "0 0 * * *",
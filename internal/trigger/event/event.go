@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	jsvc "github.com/caesium-cloud/caesium/api/rest/service/job"
 	"github.com/caesium-cloud/caesium/internal/job"
@@ -16,6 +17,7 @@ import (
 	runstorage "github.com/caesium-cloud/caesium/internal/run"
 	"github.com/caesium-cloud/caesium/internal/trigger"
 	"github.com/caesium-cloud/caesium/pkg/env"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
 )
@@ -33,6 +35,9 @@ type Config struct {
 	Events        []EventPattern    `json:"events,omitempty"`
 	ParamMapping  map[string]string `json:"paramMapping,omitempty"`
 	DefaultParams map[string]string `json:"defaultParams,omitempty"`
+	// RunTemplates renders DefaultParams as run templates. The importer sets
+	// it for jobs with metadata.runTemplates.
+	RunTemplates bool `json:"runTemplates,omitempty"`
 }
 
 type EventTrigger struct {
@@ -136,7 +141,10 @@ func (t *EventTrigger) FireWithParams(ctx context.Context, params map[string]str
 	}
 	log.Info("trigger firing", "id", t.id, "type", models.TriggerTypeEvent)
 
-	mergedParams := t.config.mergedParams(params)
+	mergedParams, err := t.config.mergedParams(params)
+	if err != nil {
+		return nil, err
+	}
 	if err := t.applyTriggerDepth(mergedParams); err != nil {
 		return nil, err
 	}
@@ -322,15 +330,26 @@ func (c Config) withDefaults() Config {
 	return c
 }
 
-func (c Config) mergedParams(params map[string]string) map[string]string {
-	merged := make(map[string]string, len(c.DefaultParams)+len(params))
-	for k, v := range c.DefaultParams {
+// mergedParams overlays params on the trigger's defaultParams. With
+// RunTemplates set, defaults render against params, with the fire time as
+// the logical date.
+func (c Config) mergedParams(params map[string]string) (map[string]string, error) {
+	defaults := c.DefaultParams
+	if c.RunTemplates {
+		data := jobdefschema.NewRunTemplateData("", "", params, time.Now())
+		var err error
+		if defaults, err = jobdefschema.RenderRunValues("defaultParams", c.DefaultParams, data); err != nil {
+			return nil, err
+		}
+	}
+	merged := make(map[string]string, len(defaults)+len(params))
+	for k, v := range defaults {
 		merged[k] = v
 	}
 	for k, v := range params {
 		merged[k] = v
 	}
-	return merged
+	return merged, nil
 }
 
 func extractParams(data []byte, mapping map[string]string) map[string]string {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/caesium-cloud/caesium/internal/models"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 )
 
 type Config struct {
@@ -16,6 +17,9 @@ type Config struct {
 	MaxTimestampAge string            `json:"maxTimestampAge,omitempty"`
 	ParamMapping    map[string]string `json:"paramMapping,omitempty"`
 	DefaultParams   map[string]string `json:"defaultParams,omitempty"`
+	// RunTemplates renders DefaultParams as run templates. The importer sets
+	// it for jobs with metadata.runTemplates.
+	RunTemplates bool `json:"runTemplates,omitempty"`
 }
 
 const defaultMaxTimestampAge = 5 * time.Minute
@@ -46,15 +50,26 @@ func (c Config) withDefaults() Config {
 	return c
 }
 
-func (c Config) mergedParams(params map[string]string) map[string]string {
-	merged := make(map[string]string, len(c.DefaultParams)+len(params))
-	for k, v := range c.DefaultParams {
+// mergedParams overlays params on the trigger's defaultParams. With
+// RunTemplates set, defaults render against params, with the fire time as
+// the logical date.
+func (c Config) mergedParams(params map[string]string) (map[string]string, error) {
+	defaults := c.DefaultParams
+	if c.RunTemplates {
+		data := jobdefschema.NewRunTemplateData("", "", params, time.Now())
+		var err error
+		if defaults, err = jobdefschema.RenderRunValues("defaultParams", c.DefaultParams, data); err != nil {
+			return nil, err
+		}
+	}
+	merged := make(map[string]string, len(defaults)+len(params))
+	for k, v := range defaults {
 		merged[k] = v
 	}
 	for k, v := range params {
 		merged[k] = v
 	}
-	return merged
+	return merged, nil
 }
 
 func normalizePath(path string) string {
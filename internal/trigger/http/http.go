@@ -136,7 +136,10 @@ func (h *HTTP) FireWithParams(ctx context.Context, params map[string]string, opt
 
 	log.Info("running jobs", "count", len(jobs))
 
-	mergedParams := h.config.mergedParams(params)
+	mergedParams, err := h.config.mergedParams(params)
+	if err != nil {
+		return err
+	}
 
 	for _, j := range jobs {
 		jobModel := j
@@ -164,7 +167,7 @@ func (h *HTTP) Path() string {
 	return h.config.Path
 }
 
-func (h *HTTP) MergeParams(params map[string]string) map[string]string {
+func (h *HTTP) MergeParams(params map[string]string) (map[string]string, error) {
 	return h.config.mergedParams(params)
 }
 
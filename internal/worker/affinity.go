@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/caesium-cloud/caesium/internal/run"
+)
+
+// The claim statement evaluates a task's nodeAffinity (pkg/jobdef.NodeAffinity,
+// stored as JSON in task_runs.node_affinity) against this node's labels:
+//
+//   - every required expression must hold, or the task is not claimable here;
+//   - among claimable tasks of equal priority, those whose preferred terms
+//     this node satisfies with the highest summed weight are claimed first;
+//   - spread tasks whose run already has a task running on this node are
+//     claimed after the rest.
+//
+// This node's labels are bound as a CASE lookup, so every label reference
+// repeats its bind arguments.
+
+// affinityJSON hides the JSON function differences between SQLite/dqlite and
+// Postgres.
+type affinityJSON struct {
+	postgres bool
+}
+
+func newAffinityJSON(dialect string) (affinityJSON, error) {
+	switch dialect {
+	case "dqlite", "sqlite":
+		return affinityJSON{}, nil
+	case "postgres":
+		return affinityJSON{postgres: true}, nil
+	default:
+		return affinityJSON{}, errors.New("worker: unsupported claim database dialect: " + dialect)
+	}
+}
+
+// doc returns the affinity document of column, treating NULL and the empty
+// string as {}.
+func (j affinityJSON) doc(column string) string {
+	if j.postgres {
+		return column
+	}
+	return "CASE WHEN " + column + " IS NULL OR " + column + " = '' THEN '{}' ELSE " + column + " END"
+}
+
+// elements iterates the array field of the JSON object docExpr as alias.value.
+func (j affinityJSON) elements(docExpr, field, alias string) string {
+	if j.postgres {
+		return "json_array_elements(COALESCE(" + docExpr + "->'" + field + "', '[]'::json)) AS " + alias + "(value)"
+	}
+	return "json_each(" + docExpr + ", '$." + field + "') AS " + alias
+}
+
+// textElements iterates the string array field of docExpr as alias.value text.
+func (j affinityJSON) textElements(docExpr, field, alias string) (iter, value string) {
+	if j.postgres {
+		return "json_array_elements_text(COALESCE(" + docExpr + "->'" + field + "', '[]'::json)) AS " + alias + "(value)", alias + ".value"
+	}
+	return "json_each(" + docExpr + ", '$." + field + "') AS " + alias, "CAST(" + alias + ".value AS TEXT)"
+}
+
+// text extracts the scalar field of docExpr as text.
+func (j affinityJSON) text(docExpr, field string) string {
+	if j.postgres {
+		return "(" + docExpr + "->>'" + field + "')"
+	}
+	return "CAST(json_extract(" + docExpr + ", '$." + field + "') AS TEXT)"
+}
+
+// integer extracts the scalar field of docExpr as an integer.
+func (j affinityJSON) integer(docExpr, field string) string {
+	if j.postgres {
+		return "CAST(" + docExpr + "->>'" + field + "' AS INTEGER)"
+	}
+	return "CAST(json_extract(" + docExpr + ", '$." + field + "') AS INTEGER)"
+}
+
+// flag is true when the boolean field of docExpr is true.
+func (j affinityJSON) flag(docExpr, field string) string {
+	if j.postgres {
+		return "(" + docExpr + "->>'" + field + "') = 'true'"
+	}
+	return "json_extract(" + docExpr + ", '$." + field + "') = 1"
+}
+
+// labelValueSQL returns an expression yielding this node's value for the
+// label named by keyExpr, or NULL when the node lacks it.
+func (c *Claimer) labelValueSQL(keyExpr string) (string, []interface{}) {
+	if len(c.nodeLabels) == 0 {
+		return "CAST(NULL AS TEXT)", nil
+	}
+	keys := make([]string, 0, len(c.nodeLabels))
+	for key := range c.nodeLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, "WHEN ? THEN ?")
+		args = append(args, key, c.nodeLabels[key])
+	}
+	return "CASE " + keyExpr + " " + strings.Join(parts, " ") + " ELSE NULL END", args
+}
+
+// requirementSQL returns a predicate that is true when the match expression
+// at alias.value holds on this node. Mirrors NodeSelectorRequirement.Matches.
+func (c *Claimer) requirementSQL(j affinityJSON, alias string) (string, []interface{}) {
+	item := alias + ".value"
+	operator := "COALESCE(" + j.text(item, "operator") + ", '')"
+	valuesIter, value := j.textElements(item, "values", "av")
+
+	var args []interface{}
+	label := func() string {
+		expr, labelArgs := c.labelValueSQL(j.text(item, "key"))
+		args = append(args, labelArgs...)
+		return expr
+	}
+
+	in := "(" + operator + " = 'In' AND " + label() + " IS NOT NULL AND EXISTS (SELECT 1 FROM " + valuesIter + " WHERE " + value + " = " + label() + "))"
+	notIn := "(" + operator + " = 'NotIn' AND (" + label() + " IS NULL OR NOT EXISTS (SELECT 1 FROM " + valuesIter + " WHERE " + value + " = " + label() + ")))"
+	exists := "(" + operator + " = 'Exists' AND " + label() + " IS NOT NULL)"
+	notExists := "(" + operator + " = 'DoesNotExist' AND " + label() + " IS NULL)"
+	return "(" + in + " OR " + notIn + " OR " + exists + " OR " + notExists + ")", args
+}
+
+// nodeAffinityPredicateSQL returns a WHERE predicate that is true when every
+// required expression of the candidate task holds on this node.
+func (c *Claimer) nodeAffinityPredicateSQL(dialect, tableAlias string) (string, []interface{}, error) {
+	j, err := newAffinityJSON(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	satisfied, args := c.requirementSQL(j, "ar")
+	doc := j.doc(tableAlias + ".node_affinity")
+	return "NOT EXISTS (SELECT 1 FROM " + j.elements(doc, "required", "ar") + " WHERE NOT " + satisfied + ")", args, nil
+}
+
+// nodeAffinityOrderSQL returns the ORDER BY terms that rank claimable tasks
+// by this node's preferred-term score and push spread tasks whose run already
+// runs a task here to the back.
+func (c *Claimer) nodeAffinityOrderSQL(dialect, tableAlias string) (string, []interface{}, error) {
+	j, err := newAffinityJSON(dialect)
+	if err != nil {
+		return "", nil, err
+	}
+	doc := j.doc(tableAlias + ".node_affinity")
+	satisfied, args := c.requirementSQL(j, "ar")
+
+	score := "COALESCE((SELECT SUM(" + j.integer("pt.value", "weight") + ") FROM " + j.elements(doc, "preferred", "pt") +
+		" WHERE NOT EXISTS (SELECT 1 FROM " + j.elements("pt.value", "match", "ar") + " WHERE NOT " + satisfied + ")), 0) DESC"
+	spread := "CASE WHEN " + j.flag(doc, "spread") + " AND EXISTS (SELECT 1 FROM task_runs AS sr WHERE sr.job_run_id = " +
+		tableAlias + ".job_run_id AND sr.status = ? AND sr.claimed_by = ?) THEN 1 ELSE 0 END ASC"
+	args = append(args, string(run.TaskStatusRunning), c.nodeID)
+	return score + ", " + spread, args, nil
+}
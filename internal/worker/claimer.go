@@ -10,13 +10,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/caesium-cloud/caesium/internal/cordon"
 	"github.com/caesium-cloud/caesium/internal/event"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/ratelimit"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -72,6 +76,7 @@ type Claimer struct {
 	leaseTTL          time.Duration
 	busyRetryBackoffs []time.Duration
 	rateLimiter       resourceLimiter
+	nodeStates        NodeStateReader
 }
 
 type resourceLimiter interface {
@@ -112,6 +117,13 @@ func (c *Claimer) WithRateLimiter(l resourceLimiter) *Claimer {
 	return c
 }
 
+// WithNodeStates stops claiming while an operator has cordoned or drained
+// this node.
+func (c *Claimer) WithNodeStates(states NodeStateReader) *Claimer {
+	c.nodeStates = states
+	return c
+}
+
 func defaultBusyRetryBackoffSchedule() []time.Duration {
 	return []time.Duration{
 		10 * time.Millisecond,
@@ -122,12 +134,23 @@ func defaultBusyRetryBackoffSchedule() []time.Duration {
 	}
 }
 
-// ClaimNext claims one ready task, or returns nil when no tasks are available.
+// ClaimNext claims one ready task, or returns nil when no tasks are available
+// or the node is cordoned.
 func (c *Claimer) ClaimNext(ctx context.Context) (*models.TaskRun, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if c.nodeStates != nil {
+		state, err := c.nodeStates.Get(ctx, c.nodeID)
+		if err != nil {
+			return nil, err
+		}
+		if !cordon.State(state.State).Schedulable() {
+			return nil, nil
+		}
+	}
 
+	start := time.Now()
 	var claimed *models.TaskRun
 	pendingEvents := make([]event.Event, 0, 1)
 	var counts dbWriteCounts
@@ -164,13 +187,23 @@ func (c *Claimer) ClaimNext(ctx context.Context) (*models.TaskRun, error) {
 	}
 
 	if claimed != nil {
+		_, span := tracing.StartFrom(ctx, claimed.TraceParent, "task.claim", trace.WithTimestamp(start), trace.WithAttributes(
+			tracing.AttrRunID.String(claimed.JobRunID.String()),
+			tracing.AttrTaskID.String(claimed.TaskID.String()),
+			tracing.AttrAttempt.Int(claimed.Attempt),
+			tracing.AttrNode.String(c.nodeID),
+		))
+		defer span.End()
+
 		acquired, jobAlias, retryAfter, err := c.acquireRateLimit(ctx, claimed)
 		if err != nil {
+			tracing.Fail(span, err)
 			return nil, err
 		}
+		span.SetAttributes(attribute.Bool("caesium.task.rate_limited", !acquired))
 		if !acquired {
 			counts.commit()
-			if err := c.store.RateLimitTask(ctx, claimed.JobRunID, claimed.TaskID, retryAfter); err != nil {
+			if err := c.store.RateLimitTaskRun(ctx, claimed.ID, retryAfter); err != nil {
 				return nil, err
 			}
 			metrics.RunSkippedTotal.WithLabelValues(jobAlias, "rate_limit").Inc()
@@ -192,7 +225,9 @@ func (c *Claimer) ClaimNext(ctx context.Context) (*models.TaskRun, error) {
 }
 
 func (c *Claimer) acquireRateLimit(ctx context.Context, task *models.TaskRun) (bool, string, time.Time, error) {
-	if c.rateLimiter == nil || task == nil {
+	// Collecting an expanded map group starts nothing; its instance task
+	// runs were rate limited on their own claims.
+	if c.rateLimiter == nil || task == nil || task.MapExpanded {
 		return true, "", time.Time{}, nil
 	}
 	rule, ok, err := ratelimit.RuleForTask(ctx, c.store.DB(), task.JobRunID, task.TaskID)
@@ -230,6 +265,14 @@ func (c *Claimer) claimNextSingleStatementTx(tx *gorm.DB, now, leaseExpiry time.
 	if err != nil {
 		return nil, uuid.Nil, err
 	}
+	affinitySQL, affinityArgs, err := c.nodeAffinityPredicateSQL(tx.Name(), "tr")
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	affinityOrder, affinityOrderArgs, err := c.nodeAffinityOrderSQL(tx.Name(), "tr")
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
 
 	// liveLeaseGuard returns SQL that is true only when the candidate task's run
 	// does NOT have a live (non-expired) run_leases row.  When no row exists
@@ -245,24 +288,35 @@ func (c *Claimer) claimNextSingleStatementTx(tx *gorm.DB, now, leaseExpiry time.
 		return nil, uuid.Nil, err
 	}
 
+	// poolAdmission admits a pooled task only while its pool has room and
+	// no higher-priority ready task in the same pool is waiting.
+	poolAdmission, poolArgs := run.PoolAdmissionSQL("tr", now)
+
+	// A gated task released while its approval request was pending is claimed
+	// again from awaiting_approval and keeps that status until its executor
+	// re-checks the request.
+	claimedStatus, claimedStatusArgs := run.ClaimedTaskStatusSQL("status")
+
 	sql := `
 UPDATE task_runs
-SET claimed_by = ?, claim_expires_at = ?, claim_attempt = claim_attempt + 1, status = ?, updated_at = ?, rate_limit_retry_after = NULL
+SET claimed_by = ?, claim_expires_at = ?, claim_attempt = claim_attempt + 1, status = ` + claimedStatus + `, updated_at = ?, rate_limit_retry_after = NULL
 WHERE id = (
 	SELECT tr.id
 	FROM task_runs AS tr
 	JOIN job_runs AS jr ON jr.id = tr.job_run_id
 	WHERE jr.status = ?
-		AND tr.status = ?
+		AND tr.status IN ?
 		AND tr.outstanding_predecessors = ?
 		AND (tr.claimed_by = '' OR tr.claim_expires_at IS NULL OR tr.claim_expires_at < ?)
 		AND (tr.rate_limit_retry_after IS NULL OR tr.rate_limit_retry_after <= ?)
 		AND ` + selectorSQL + `
+		AND ` + affinitySQL + `
 		AND ` + liveLeaseGuard + `
-	ORDER BY tr.priority DESC, tr.created_at ASC
+		AND ` + poolAdmission + `
+	ORDER BY tr.priority DESC, ` + affinityOrder + `, tr.created_at ASC
 	LIMIT 1
 )
-AND status = ?
+AND status IN ?
 AND outstanding_predecessors = ?
 AND (claimed_by = '' OR claim_expires_at IS NULL OR claim_expires_at < ?)
 AND (rate_limit_retry_after IS NULL OR rate_limit_retry_after <= ?)
@@ -272,18 +326,23 @@ RETURNING *, (SELECT job_id FROM job_runs WHERE id = task_runs.job_run_id) AS cl
 	args := []interface{}{
 		c.nodeID,
 		leaseExpiry,
-		string(run.TaskStatusRunning),
+	}
+	args = append(args, claimedStatusArgs...)
+	args = append(args,
 		now,
 		string(run.StatusRunning),
-		string(run.TaskStatusPending),
+		run.ClaimableTaskStatuses(),
 		0,
 		now,
 		now,
-	}
+	)
 	args = append(args, selectorArgs...)
+	args = append(args, affinityArgs...)
 	// liveLeaseGuard binds one parameter: now (the live-lease expiry cutoff).
 	args = append(args, now)
-	args = append(args, string(run.TaskStatusPending), 0, now, now, string(run.StatusRunning))
+	args = append(args, poolArgs...)
+	args = append(args, affinityOrderArgs...)
+	args = append(args, run.ClaimableTaskStatuses(), 0, now, now, string(run.StatusRunning))
 
 	var claimed claimedTaskRunRow
 	result := tx.Raw(sql, args...).Scan(&claimed)
@@ -408,6 +467,12 @@ func nodeSelectorIteratorSQL(dialect, jsonExpr string) string {
 }
 
 func (c *Claimer) ReclaimExpired(ctx context.Context) error {
+	return c.reclaim(ctx, nil)
+}
+
+// reclaim resets expired claims back to claimable. With ids set, only those
+// task runs, and only while this node holds their claims, are reset.
+func (c *Claimer) reclaim(ctx context.Context, ids []uuid.UUID) error {
 	start := time.Now()
 	defer func() {
 		// Control-plane node-load series: intentionally includes replay work and
@@ -451,8 +516,15 @@ func (c *Claimer) ReclaimExpired(ctx context.Context) error {
 			// reset claims) so the expiry criteria can't drift between them.
 			// liveLeaseGuard binds one parameter (now); it trails the three
 			// static args.
-			expiredWhere := "job_run_id IN (?) AND status = ? AND claim_expires_at IS NOT NULL AND claim_expires_at < ? AND " + liveLeaseGuard
-			expiredArgs := []interface{}{runningRunIDs, string(run.TaskStatusRunning), now, now}
+			// A task parked at an approval gate under a claim is reclaimed
+			// the same way but stays in awaiting_approval, claimable at once
+			// so the next executor re-checks its request.
+			expiredWhere := "job_run_id IN (?) AND status IN ? AND claim_expires_at IS NOT NULL AND claim_expires_at < ? AND " + liveLeaseGuard
+			expiredArgs := []interface{}{runningRunIDs, []string{string(run.TaskStatusRunning), string(run.TaskStatusAwaitingApproval)}, now, now}
+			if len(ids) > 0 {
+				expiredWhere += " AND id IN ? AND claimed_by = ?"
+				expiredArgs = append(expiredArgs, ids, c.nodeID)
+			}
 
 			var expired []models.TaskRun
 			if err := tx.Where(expiredWhere, expiredArgs...).Find(&expired).Error; err != nil {
@@ -462,7 +534,8 @@ func (c *Claimer) ReclaimExpired(ctx context.Context) error {
 			result := tx.Model(&models.TaskRun{}).
 				Where(expiredWhere, expiredArgs...).
 				Updates(map[string]interface{}{
-					"status":           string(run.TaskStatusPending),
+					"status": gorm.Expr("CASE WHEN status = ? THEN status ELSE ? END",
+						string(run.TaskStatusAwaitingApproval), string(run.TaskStatusPending)),
 					"claimed_by":       "",
 					"claim_expires_at": nil,
 					"runtime_id":       "",
@@ -557,6 +630,27 @@ func (c *Claimer) ReclaimExpired(ctx context.Context) error {
 	return err
 }
 
+// ReleaseClaims expires this node's claims on the given running task runs and
+// reclaims them at once, so a draining node hands them to other nodes without
+// waiting out the lease TTL. Only the given task runs are reset; other expired
+// claims are left to ReclaimExpired. Tasks of a live-owned run are left for
+// their owner to re-dispatch, as in ReclaimExpired.
+func (c *Claimer) ReleaseClaims(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := c.store.DB().WithContext(ctx).Model(&models.TaskRun{}).
+		Where("id IN ? AND claimed_by = ? AND status IN ?", ids, c.nodeID, []string{string(run.TaskStatusRunning), string(run.TaskStatusAwaitingApproval)}).
+		Update("claim_expires_at", time.Now().UTC().Add(-time.Second))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, nil
+	}
+	return result.RowsAffected, c.reclaim(ctx, ids)
+}
+
 func (c *Claimer) observeBusyRetry(error) {
 	// Control-plane node-load series: intentionally includes replay work and is
 	// not a run-health input.
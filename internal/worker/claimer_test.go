@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/caesium-cloud/caesium/internal/cordon"
 	jobdeftestutil "github.com/caesium-cloud/caesium/internal/jobdef/testutil"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	metrictestutil "github.com/caesium-cloud/caesium/internal/metrics/testutil"
@@ -16,6 +17,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -94,6 +96,197 @@ func TestClaimerClaimNextPrefersHigherPriority(t *testing.T) {
 	require.GreaterOrEqual(t, metrictestutil.CounterValue(t, metrics.TaskPriorityClaimTotal, "high"), float64(1))
 }
 
+func TestClaimerClaimNextRespectsPoolSlots(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.Pool{Name: "warehouse", Slots: 3, CreatedAt: now, UpdatedAt: now}).Error)
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:         string(run.TaskStatusRunning),
+		claimedBy:      "node-other",
+		claimExpiresAt: ptrTime(now.Add(time.Minute)),
+		pool:           "warehouse",
+		poolSlots:      2,
+		createdAt:      now.Add(-4 * time.Minute),
+	})
+	tooBig := seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		pool:      "warehouse",
+		poolSlots: 2,
+		createdAt: now.Add(-3 * time.Minute),
+	})
+	unpooled := seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		createdAt: now.Add(-time.Minute),
+	})
+
+	claimer := NewClaimer("node-pool", run.NewStore(db), 2*time.Minute)
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, unpooled.ID, claimed.ID, "a full pool must not block tasks outside it")
+
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, claimed, "the pooled task needs 2 slots but only 1 is free")
+
+	// Finishing the running holder frees its slots.
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("pool = ? AND status = ?", "warehouse", string(run.TaskStatusRunning)).
+		Update("status", string(run.TaskStatusSucceeded)).Error)
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, tooBig.ID, claimed.ID)
+}
+
+func TestClaimerClaimNextOrdersPoolByPriority(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.Pool{Name: "gpu", Slots: 2, CreatedAt: now, UpdatedAt: now}).Error)
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:         string(run.TaskStatusRunning),
+		claimedBy:      "node-other",
+		claimExpiresAt: ptrTime(now.Add(time.Minute)),
+		pool:           "gpu",
+		poolSlots:      1,
+		createdAt:      now.Add(-5 * time.Minute),
+	})
+	// The high-priority task needs both slots; the older low-priority task
+	// fits but must wait behind it.
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		priority:  run.PriorityLowValue,
+		pool:      "gpu",
+		poolSlots: 1,
+		createdAt: now.Add(-4 * time.Minute),
+	})
+	high := seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		priority:  run.PriorityHighValue,
+		pool:      "gpu",
+		poolSlots: 2,
+		createdAt: now.Add(-time.Minute),
+	})
+	// A task larger than the whole pool never holds the others back.
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		priority:  run.PriorityHighValue,
+		pool:      "gpu",
+		poolSlots: 3,
+		createdAt: now.Add(-2 * time.Minute),
+	})
+
+	claimer := NewClaimer("node-gpu", run.NewStore(db), 2*time.Minute)
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, claimed)
+
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("pool = ? AND status = ?", "gpu", string(run.TaskStatusRunning)).
+		Update("status", string(run.TaskStatusSucceeded)).Error)
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, high.ID, claimed.ID)
+
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, claimed, "the high-priority task now holds both slots")
+}
+
+func TestClaimerClaimNextWaitsForUndefinedPool(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	pending := seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		pool:      "missing",
+		poolSlots: 1,
+	})
+	claimer := NewClaimer("node-pool", run.NewStore(db), 2*time.Minute)
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, claimed)
+
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.Pool{Name: "missing", Slots: 1, CreatedAt: now, UpdatedAt: now}).Error)
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, pending.ID, claimed.ID)
+}
+
+func TestClaimerClaimsMapInstancesIndividually(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	store := run.NewStore(db)
+	require.NoError(t, db.Create(&models.Pool{Name: "warehouse", Slots: 1, CreatedAt: now, UpdatedAt: now}).Error)
+	group := seedTaskRun(t, db, seedTaskRunInput{
+		status:    string(run.TaskStatusPending),
+		pool:      "warehouse",
+		poolSlots: 1,
+		createdAt: now.Add(-time.Minute),
+	})
+
+	claimer := NewClaimer("node-map", store, 2*time.Minute)
+	claimed, err := claimer.ClaimNext(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, group.ID, claimed.ID)
+
+	instances, pending, err := store.ExpandMapTaskRuns(ctx, claimed, []string{"a", "b", "c"}, 2)
+	require.NoError(t, err)
+	require.Len(t, instances, 3)
+	require.Equal(t, 2, pending)
+
+	// The expanded group gives its slot to the instances, which the pool
+	// admits one at a time.
+	first, err := claimer.ClaimNext(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	require.NotNil(t, first.MapParentID)
+	require.Equal(t, group.ID, *first.MapParentID)
+	blocked, err := claimer.ClaimNext(ctx)
+	require.NoError(t, err)
+	require.Nil(t, blocked, "the pool has one slot")
+
+	completed := 0
+	for next := first; next != nil; {
+		require.NoError(t, store.CompleteMapInstance(ctx, next, run.TaskInstanceResult{Status: run.TaskStatusSucceeded, Result: "success"}))
+		completed++
+		next, err = claimer.ClaimNext(ctx)
+		require.NoError(t, err)
+		if next != nil && next.MapParentID == nil {
+			// Every instance ran; the group is claimed to collect.
+			require.Equal(t, group.ID, next.ID)
+			require.True(t, next.MapExpanded)
+			break
+		}
+	}
+	require.Equal(t, 3, completed)
+
+	var remaining int64
+	require.NoError(t, db.Model(&models.TaskRun{}).Where("map_parent_id = ?", group.ID).Count(&remaining).Error)
+	require.Zero(t, remaining)
+	recorded, err := store.TaskInstances(group.JobRunID, group.TaskID)
+	require.NoError(t, err)
+	succeeded, _, failed, left := run.SummarizeInstances(recorded)
+	require.Equal(t, []int{3, 0, 0}, []int{succeeded, failed, left})
+}
+
 func TestClaimerClaimNextSkipsUnexpiredAndReclaimsExpiredLease(t *testing.T) {
 	db := jobdeftestutil.OpenTestDB(t)
 	t.Cleanup(func() {
@@ -184,6 +377,150 @@ func TestClaimerReclaimExpiredResetsStaleRunningTasks(t *testing.T) {
 	require.GreaterOrEqual(t, metrictestutil.CounterValue(t, metrics.WorkerLeaseExpirationsTotal, "node-a"), float64(1))
 }
 
+func TestClaimerReclaimsReleasedGatedTaskInAwaitingApproval(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	gated := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusAwaitingApproval),
+		outstandingPredecessors: 0,
+		claimedBy:               "node-old",
+		claimExpiresAt:          ptrTime(now.Add(-time.Minute)),
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+
+	claimer := NewClaimer("node-a", run.NewStore(db), time.Minute)
+	require.NoError(t, claimer.ReclaimExpired(context.Background()))
+
+	var released models.TaskRun
+	require.NoError(t, db.First(&released, "id = ?", gated.ID).Error)
+	require.Equal(t, string(run.TaskStatusAwaitingApproval), released.Status)
+	require.Empty(t, released.ClaimedBy)
+
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, gated.ID, claimed.ID)
+	require.Equal(t, "node-a", claimed.ClaimedBy)
+	require.Equal(t, string(run.TaskStatusAwaitingApproval), claimed.Status)
+}
+
+func TestClaimerClaimNextSkipsWhenNodeCordoned(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	ready := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		createdAt:               time.Now().UTC().Add(-time.Minute),
+	})
+
+	states := cordon.NewStore(db)
+	_, err := states.Cordon(context.Background(), "node-a", "alice")
+	require.NoError(t, err)
+
+	claimer := NewClaimer("node-a", run.NewStore(db), time.Minute).WithNodeStates(states)
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, claimed)
+
+	var persisted models.TaskRun
+	require.NoError(t, db.First(&persisted, "id = ?", ready.ID).Error)
+	require.Equal(t, string(run.TaskStatusPending), persisted.Status)
+
+	require.NoError(t, states.Uncordon(context.Background(), "node-a"))
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, ready.ID, claimed.ID)
+}
+
+func TestClaimerReleaseClaimsResetsOnlyOwnTasks(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	own := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusRunning),
+		outstandingPredecessors: 0,
+		claimedBy:               "node-a",
+		claimExpiresAt:          ptrTime(now.Add(5 * time.Minute)),
+		claimAttempt:            1,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+	other := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusRunning),
+		outstandingPredecessors: 0,
+		claimedBy:               "node-b",
+		claimExpiresAt:          ptrTime(now.Add(5 * time.Minute)),
+		claimAttempt:            1,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+
+	claimer := NewClaimer("node-a", run.NewStore(db), time.Minute)
+	released, err := claimer.ReleaseClaims(context.Background(), []uuid.UUID{own.ID, other.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), released)
+
+	var reset models.TaskRun
+	require.NoError(t, db.First(&reset, "id = ?", own.ID).Error)
+	require.Equal(t, string(run.TaskStatusPending), reset.Status)
+	require.Equal(t, "", reset.ClaimedBy)
+
+	var untouched models.TaskRun
+	require.NoError(t, db.First(&untouched, "id = ?", other.ID).Error)
+	require.Equal(t, string(run.TaskStatusRunning), untouched.Status)
+	require.Equal(t, "node-b", untouched.ClaimedBy)
+}
+
+func TestClaimerReleaseClaimsLeavesOtherExpiredClaims(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	own := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusRunning),
+		outstandingPredecessors: 0,
+		claimedBy:               "node-a",
+		claimExpiresAt:          ptrTime(now.Add(5 * time.Minute)),
+		claimAttempt:            1,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+	// Another node's lapsed claim is for the reclaim loop, which may be
+	// gated, not for a drain hand-back.
+	stale := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusRunning),
+		outstandingPredecessors: 0,
+		claimedBy:               "node-b",
+		claimExpiresAt:          ptrTime(now.Add(-time.Minute)),
+		claimAttempt:            1,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+
+	claimer := NewClaimer("node-a", run.NewStore(db), time.Minute)
+	released, err := claimer.ReleaseClaims(context.Background(), []uuid.UUID{own.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), released)
+
+	var reset models.TaskRun
+	require.NoError(t, db.First(&reset, "id = ?", own.ID).Error)
+	require.Equal(t, string(run.TaskStatusPending), reset.Status)
+
+	var untouched models.TaskRun
+	require.NoError(t, db.First(&untouched, "id = ?", stale.ID).Error)
+	require.Equal(t, string(run.TaskStatusRunning), untouched.Status)
+	require.Equal(t, "node-b", untouched.ClaimedBy)
+}
+
 func TestClaimerClaimNextReturnsNilWhenClaimRaceIsLost(t *testing.T) {
 	db := jobdeftestutil.OpenTestDB(t)
 	t.Cleanup(func() {
@@ -297,6 +634,128 @@ func TestClaimerClaimNextReturnsNilWhenNoSelectorMatches(t *testing.T) {
 	require.Nil(t, claimed)
 }
 
+func TestClaimerClaimNextRespectsRequiredNodeAffinity(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"required":[{"key":"zone","operator":"NotIn","values":["edge"]}]}`,
+		createdAt:               now.Add(-4 * time.Minute),
+	})
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"required":[{"key":"gpu","operator":"Exists"}]}`,
+		createdAt:               now.Add(-3 * time.Minute),
+	})
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"required":[{"key":"arch","operator":"In","values":["arm64","riscv64"]}]}`,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+	match := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"required":[{"key":"arch","operator":"In","values":["amd64","arm64"]},{"key":"gpu","operator":"DoesNotExist"},{"key":"tier","operator":"NotIn","values":["spot"]}]}`,
+		createdAt:               now.Add(-time.Minute),
+	})
+
+	claimer := NewClaimer("node-edge", run.NewStore(db), time.Minute, map[string]string{"arch": "amd64", "zone": "edge"})
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, match.ID, claimed.ID)
+
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, claimed)
+}
+
+func TestClaimerClaimNextOrdersByPreferredNodeAffinity(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	plain := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		createdAt:               now.Add(-3 * time.Minute),
+	})
+	preferred := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"preferred":[{"weight":10,"match":[{"key":"gpu","operator":"Exists"}]},{"weight":90,"match":[{"key":"zone","operator":"In","values":["b"]}]}]}`,
+		createdAt:               now.Add(-time.Minute),
+	})
+	weaker := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"preferred":[{"weight":50,"match":[{"key":"gpu","operator":"Exists"},{"key":"zone","operator":"In","values":["a"]}]}]}`,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+
+	claimer := NewClaimer("node-gpu", run.NewStore(db), time.Minute, map[string]string{"gpu": "a100", "zone": "b"})
+	var order []uuid.UUID
+	for range 3 {
+		claimed, err := claimer.ClaimNext(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		order = append(order, claimed.ID)
+	}
+	// preferred scores 100 and weaker 0 (its only term needs zone=a); the
+	// unscored tasks then fall back to FIFO.
+	require.Equal(t, []uuid.UUID{preferred.ID, plain.ID, weaker.ID}, order)
+}
+
+func TestClaimerClaimNextSpreadsRunAcrossNodes(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	now := time.Now().UTC()
+	busyRun := seedJobRun(t, db, string(run.StatusRunning))
+	_ = seedTaskRun(t, db, seedTaskRunInput{
+		status:         string(run.TaskStatusRunning),
+		claimedBy:      "node-a",
+		claimExpiresAt: ptrTime(now.Add(time.Hour)),
+		jobRunID:       &busyRun,
+		createdAt:      now.Add(-5 * time.Minute),
+	})
+	sibling := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"spread":true}`,
+		jobRunID:                &busyRun,
+		createdAt:               now.Add(-2 * time.Minute),
+	})
+	other := seedTaskRun(t, db, seedTaskRunInput{
+		status:                  string(run.TaskStatusPending),
+		outstandingPredecessors: 0,
+		nodeAffinity:            `{"spread":true}`,
+		createdAt:               now.Add(-time.Minute),
+	})
+
+	claimer := NewClaimer("node-a", run.NewStore(db), time.Minute)
+	claimed, err := claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, other.ID, claimed.ID, "node-a already runs a task of the sibling's run")
+
+	claimed, err = claimer.ClaimNext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.Equal(t, sibling.ID, claimed.ID, "spread is a preference, not a requirement")
+}
+
 func TestClaimerClaimNextIgnoresNonRunningJobRuns(t *testing.T) {
 	db := jobdeftestutil.OpenTestDB(t)
 	t.Cleanup(func() {
@@ -353,10 +812,13 @@ type seedTaskRunInput struct {
 	outstandingPredecessors int
 	claimedBy               string
 	nodeSelector            map[string]string
+	nodeAffinity            string
 	claimExpiresAt          *time.Time
 	claimAttempt            int
 	priority                int
 	jobRunStatus            string
+	pool                    string
+	poolSlots               int
 	createdAt               time.Time
 	// jobRunID, when non-nil, reuses the given job_run_id instead of creating a
 	// fresh one.  The caller is responsible for ensuring the job_run already exists.
@@ -405,9 +867,12 @@ func seedTaskRun(t *testing.T, db *gorm.DB, in seedTaskRunInput) *models.TaskRun
 		Priority:                in.priority,
 		ClaimedBy:               in.claimedBy,
 		NodeSelector:            jsonmap.FromStringMap(in.nodeSelector),
+		NodeAffinity:            datatypes.JSON(in.nodeAffinity),
 		ClaimExpiresAt:          in.claimExpiresAt,
 		ClaimAttempt:            in.claimAttempt,
 		OutstandingPredecessors: in.outstandingPredecessors,
+		Pool:                    in.pool,
+		PoolSlots:               in.poolSlots,
 		CreatedAt:               in.createdAt,
 		UpdatedAt:               in.createdAt,
 	}
@@ -11,7 +11,9 @@ import (
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ownerBusyBackoffs schedules the worker's retries when the owner answers a
@@ -79,6 +81,18 @@ func (s *localSink) Cached(_ context.Context, taskRun *models.TaskRun, source ru
 	return s.store.CacheHitTaskClaimed(taskRun.JobRunID, taskRun.TaskID, source, result, taskRun.ClaimedBy, outputs, branchSelections)
 }
 
+// tracedSink records a task's failure on its task.execute span before handing
+// the completion to the wrapped sink.
+type tracedSink struct {
+	CompletionSink
+	span trace.Span
+}
+
+func (s tracedSink) Failed(ctx context.Context, taskRun *models.TaskRun, failure error) error {
+	tracing.Fail(s.span, failure)
+	return s.CompletionSink.Failed(ctx, taskRun, failure)
+}
+
 // completePoster is the seam the owner sink uses to reach the owner's
 // /internal/complete endpoint.  Production wires it to dispatch.PostComplete;
 // tests inject a fake that records the CompleteRequest.
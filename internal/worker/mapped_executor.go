@@ -0,0 +1,372 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caesium-cloud/caesium/internal/atom"
+	"github.com/caesium-cloud/caesium/internal/cache"
+	"github.com/caesium-cloud/caesium/internal/fanout"
+	jobdefruntime "github.com/caesium-cloud/caesium/internal/jobdef/runtime"
+	"github.com/caesium-cloud/caesium/internal/logarchive"
+	"github.com/caesium-cloud/caesium/internal/metrics"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/pkg/container"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/caesium-cloud/caesium/pkg/log"
+	pkgtask "github.com/caesium-cloud/caesium/pkg/task"
+)
+
+// mappedStep bundles what every instance of a mapped step executes with.
+type mappedStep struct {
+	taskModel   *models.Task
+	atomSpec    container.Spec
+	runParams   map[string]string
+	cacheCfg    jobdefschema.CacheConfig
+	jobAlias    string
+	instOutputs map[string]map[string]string
+	cacheStore  *cache.Store
+	predHashes  []string
+}
+
+// newMappedStep loads the predecessor state the instances of taskRun's step
+// share. It returns the full predecessor outputs for resolving the item list.
+func (e *runtimeExecutor) newMappedStep(taskRun *models.TaskRun, taskModel *models.Task, spec jobdefschema.StepMap, atomSpec container.Spec, runParams map[string]string, cacheCfg jobdefschema.CacheConfig, jobAlias string) (*mappedStep, map[string]map[string]string, error) {
+	predOutputs, err := e.store.PredecessorOutputs(taskRun.JobRunID, taskRun.TaskID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query predecessor outputs: %w", err)
+	}
+	step := &mappedStep{
+		taskModel:   taskModel,
+		atomSpec:    atomSpec,
+		runParams:   runParams,
+		cacheCfg:    cacheCfg,
+		jobAlias:    jobAlias,
+		instOutputs: fanout.InstanceOutputs(spec, predOutputs),
+	}
+	if cacheCfg.Enabled {
+		step.cacheStore = cache.NewStore(e.store.DB())
+		step.predHashes, err = e.store.PredecessorHashes(taskRun.JobRunID, taskRun.TaskID)
+		if err != nil {
+			log.Warn("cache: failed to query predecessor hashes", "task_id", taskRun.TaskID, "error", err)
+		}
+	}
+	return step, predOutputs, nil
+}
+
+// executeMapped runs a claimed mapped step's group task run.
+//
+// On its first claim the group expands into one task run per instance
+// (ExpandMapTaskRuns) and gives up its claim; workers then claim and run the
+// instances individually, so each one honors pools, node placement, and lease
+// recovery, and map.maxParallel caps how many exist at once. Once the last
+// instance finishes the group is queued again, and the claim that picks it up
+// collects the outcomes and completes the group.
+//
+// A group dispatched by a run owner runs its instances in-process under the
+// group's dispatch instead: the owner's DAG addresses one node per step and
+// has no way to dispatch the instance task runs.
+func (e *runtimeExecutor) executeMapped(ctx context.Context, taskRun *models.TaskRun, sink CompletionSink, taskModel *models.Task, spec jobdefschema.StepMap, atomSpec container.Spec, runParams map[string]string, cacheCfg jobdefschema.CacheConfig, jobAlias string) {
+	if taskRun.MapExpanded {
+		instances, err := e.store.TaskInstances(taskRun.JobRunID, taskRun.TaskID)
+		if err != nil {
+			e.failTask(ctx, taskRun, sink, err)
+			return
+		}
+		e.finishMapped(ctx, taskRun, sink, fanout.Collect(instances))
+		return
+	}
+
+	step, predOutputs, err := e.newMappedStep(taskRun, taskModel, spec, atomSpec, runParams, cacheCfg, jobAlias)
+	if err != nil {
+		e.failTask(ctx, taskRun, sink, err)
+		return
+	}
+	items, err := fanout.Items(spec, predOutputs)
+	if err != nil {
+		e.failTask(ctx, taskRun, sink, fmt.Errorf("task %s: %w", taskRun.TaskID, err))
+		return
+	}
+	if err := e.store.StartTaskClaimed(taskRun.JobRunID, taskRun.TaskID, "", taskRun.ClaimedBy); err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker task claim changed before mapped expansion", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+			return
+		}
+		e.failTask(ctx, taskRun, sink, err)
+		return
+	}
+
+	if _, dispatched := dispatchMetaFrom(ctx); dispatched {
+		exec := func(ctx context.Context, inst *run.TaskInstance, count int) fanout.Outcome {
+			return e.runInstance(ctx, taskRun, step, inst, count)
+		}
+		group, err := fanout.Run(ctx, e.store, taskRun.JobRunID, taskRun.TaskID, items, spec.MaxParallel, exec)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Info("worker task canceled", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+				return
+			}
+			e.failTask(ctx, taskRun, sink, err)
+			return
+		}
+		e.finishMapped(ctx, taskRun, sink, group)
+		return
+	}
+
+	instances, pending, err := e.store.ExpandMapTaskRuns(ctx, taskRun, items, spec.MaxParallel)
+	if err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker task claim changed during mapped expansion", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+			return
+		}
+		e.failTask(ctx, taskRun, sink, err)
+		return
+	}
+	if pending == 0 {
+		// Every instance already succeeded in an earlier attempt.
+		e.finishMapped(ctx, taskRun, sink, fanout.Collect(instances))
+		return
+	}
+	log.Info("mapped step expanded into instance task runs", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID, "instances", len(instances), "queued", pending)
+}
+
+// executeMapInstance runs one claimed instance task run of an expanded map
+// group and records its outcome on the instance. Instance failures are
+// outcomes, not task failures: the group decides once every instance ran.
+func (e *runtimeExecutor) executeMapInstance(ctx context.Context, taskRun *models.TaskRun, taskModel *models.Task, spec jobdefschema.StepMap, atomSpec container.Spec, runParams map[string]string, cacheCfg jobdefschema.CacheConfig, jobAlias string) {
+	var outcome fanout.Outcome
+	inst, count, err := e.store.MapInstance(ctx, taskRun.ID)
+	if err != nil {
+		log.Error("failed to load map instance", "task_run_id", taskRun.ID, "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID, "error", err)
+		return
+	}
+	step, _, err := e.newMappedStep(taskRun, taskModel, spec, atomSpec, runParams, cacheCfg, jobAlias)
+	if err != nil {
+		outcome.Status = run.TaskStatusFailed
+		outcome.Error = err.Error()
+	} else {
+		outcome = e.runInstance(ctx, taskRun, step, inst, count)
+	}
+	if ctx.Err() != nil {
+		log.Info("worker map instance canceled", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID, "index", inst.Index)
+		return
+	}
+	if err := e.store.CompleteMapInstance(ctx, taskRun, outcome.TaskInstanceResult); err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker map instance claim changed before completion", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID, "index", inst.Index)
+			return
+		}
+		log.Error("failed to persist map instance completion", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "index", inst.Index, "error", err)
+	}
+}
+
+// finishMapped completes a mapped step's group from its instances' aggregate.
+func (e *runtimeExecutor) finishMapped(ctx context.Context, taskRun *models.TaskRun, sink CompletionSink, group *fanout.Group) {
+	if group.Hash != "" {
+		if err := e.store.SetTaskHashWithBlob(taskRun.JobRunID, taskRun.TaskID, group.Hash, "", nil); err != nil {
+			log.Warn("cache: failed to persist task hash", "task_id", taskRun.TaskID, "hash", group.Hash, "error", err)
+		}
+	}
+	if group.Err != nil {
+		e.failTask(ctx, taskRun, sink, fmt.Errorf("task %s: %w", taskRun.TaskID, group.Err))
+		return
+	}
+	if err := sink.Succeeded(ctx, taskRun, string(atom.Success), group.Output, nil); err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker task claim changed before mapped completion", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+			return
+		}
+		log.Error("failed to persist mapped task completion", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", err)
+	}
+}
+
+// runInstance runs one instance to a terminal outcome: a cache hit, or the
+// step's attempts with their retry delays.
+func (e *runtimeExecutor) runInstance(ctx context.Context, taskRun *models.TaskRun, step *mappedStep, inst *run.TaskInstance, count int) fanout.Outcome {
+	var outcome fanout.Outcome
+	var hashInputBlob []byte
+	var resolvedImageDigest string
+	taskModel := step.taskModel
+	if step.cacheStore != nil {
+		hashInput := e.buildHashInput(ctx, taskRun, nil, step.atomSpec, step.cacheCfg, step.jobAlias, taskModel.Name, step.instOutputs, step.predHashes, step.runParams)
+		item := inst.Item
+		hashInput.MapItem = &item
+		resolvedImageDigest = hashInput.ResolvedImageDigest
+		outcome.Hash = hashInput.Compute()
+		blob, blobErr := hashInput.CanonicalJSON(outcome.Hash)
+		if blobErr != nil {
+			log.Warn("cache: failed to serialize hash-input blob", "task_id", taskRun.TaskID, "index", inst.Index, "error", blobErr)
+			blob = nil
+		}
+		hashInputBlob = blob
+		if err := e.store.SetTaskInstanceHash(inst.ID, outcome.Hash, hashInputBlob); err != nil {
+			log.Warn("cache: failed to persist instance hash", "task_id", taskRun.TaskID, "index", inst.Index, "error", err)
+		}
+
+		entry, found, getErr := step.cacheStore.Get(outcome.Hash)
+		switch {
+		case getErr != nil:
+			log.Warn("cache: lookup failed", "task_id", taskRun.TaskID, "index", inst.Index, "hash", outcome.Hash, "error", getErr)
+		case found && run.IsSuccessfulTaskResult(entry.Result):
+			if !taskRun.Quarantine {
+				metrics.TaskCacheHitsTotal.WithLabelValues(step.jobAlias, taskModel.Name).Inc()
+			}
+			outcome.Status = run.TaskStatusCached
+			outcome.Result = entry.Result
+			outcome.Output = entry.Output
+			outcome.Cache = &run.CacheHitSource{
+				RunID:     entry.RunID,
+				CreatedAt: entry.CreatedAt,
+				ExpiresAt: entry.ExpiresAt,
+			}
+			return outcome
+		}
+	}
+
+	maxAttempts := max(taskRun.MaxAttempts, 1)
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result, output, execErr := e.executeInstance(ctx, taskRun, inst, count, attempt, step.atomSpec, step.runParams, step.instOutputs, step.jobAlias)
+		if execErr == nil && !run.IsSuccessfulTaskResult(result) {
+			execErr = fmt.Errorf("instance %d failed with result %q", inst.Index, result)
+		}
+		if execErr == nil {
+			outcome.Status = run.TaskStatusSucceeded
+			outcome.Result = result
+			outcome.Output = output
+			if step.cacheStore != nil && !taskRun.Quarantine {
+				entry := &cache.Entry{
+					Hash:                outcome.Hash,
+					JobID:               taskModel.JobID,
+					TaskName:            taskModel.Name,
+					Result:              result,
+					Output:              output,
+					RunID:               taskRun.JobRunID,
+					TaskRunID:           inst.ID,
+					ResolvedImageDigest: resolvedImageDigest,
+					HashInputBlob:       hashInputBlob,
+					CreatedAt:           time.Now().UTC(),
+				}
+				if step.cacheCfg.TTL > 0 {
+					expiresAt := entry.CreatedAt.Add(step.cacheCfg.TTL)
+					entry.ExpiresAt = &expiresAt
+				}
+				if err := step.cacheStore.Put(entry); err != nil {
+					log.Warn("cache: failed to store entry", "task_id", taskRun.TaskID, "index", inst.Index, "hash", outcome.Hash, "error", err)
+				}
+			}
+			return outcome
+		}
+		lastErr = execErr
+		if ctx.Err() != nil || attempt >= maxAttempts {
+			break
+		}
+
+		delay := retryDelay(nil, taskModel, attempt)
+		log.Info("retrying mapped instance", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "index", inst.Index, "attempt", attempt, "next_attempt", attempt+1, "delay", delay, "error", lastErr)
+		if delay > 0 {
+			e.sleepRetryDelay(ctx, delay)
+		}
+	}
+
+	outcome.Status = run.TaskStatusFailed
+	outcome.Error = lastErr.Error()
+	return outcome
+}
+
+// executeInstance runs one attempt of one mapped instance and returns its
+// atom result and parsed output. Unlike executeTask it records nothing on the
+// group task run; the instance's lifecycle lives on its own row.
+func (e *runtimeExecutor) executeInstance(ctx context.Context, taskRun *models.TaskRun, inst *run.TaskInstance, count, attempt int, atomSpec container.Spec, runParams map[string]string, instOutputs map[string]map[string]string, jobAlias string) (string, map[string]string, error) {
+	taskCtx := ctx
+	cancel := func() {}
+	if e.taskTimeout > 0 {
+		taskCtx, cancel = context.WithTimeout(ctx, e.taskTimeout)
+	}
+	defer cancel()
+
+	engineFactory := e.engineFactory
+	if engineFactory == nil {
+		engineFactory = defaultNewEngine
+	}
+	engine, err := engineFactory(taskCtx, taskRun.Engine)
+	if err != nil {
+		return "", nil, err
+	}
+
+	atomName := fmt.Sprintf("%s-%d-%s", taskRun.TaskID, inst.Index, taskRun.JobRunID)
+	if attempt > 1 {
+		atomName = fmt.Sprintf("%s-attempt%d", atomName, attempt)
+	}
+
+	spec, _, err := jobdefruntime.ResolveContainerSpecSecretsWithIdentities(taskCtx, e.secretResolver, atomSpec)
+	if err != nil {
+		return "", nil, err
+	}
+	merged := make(map[string]string, len(spec.Env)+len(runParams)+5)
+	for k, v := range spec.Env {
+		merged[k] = v
+	}
+	for k, v := range buildRunParamEnv(taskRun.JobRunID, jobAlias, runParams) {
+		merged[k] = v
+	}
+	for k, v := range pkgtask.BuildOutputEnv(instOutputs) {
+		merged[k] = v
+	}
+	for k, v := range fanout.InstanceEnv(inst, count) {
+		merged[k] = v
+	}
+	spec.Env = merged
+
+	a, err := engine.Create(&atom.EngineCreateRequest{
+		Name:    atomName,
+		Image:   taskRun.Image,
+		Command: parseTaskCommand(taskRun.Command),
+		Spec:    spec,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if err := e.store.StartTaskInstance(inst.ID, a.ID(), attempt); err != nil {
+		return "", nil, err
+	}
+
+	taskLog := e.logArchive.RecordTask(ctx, e.store, logarchive.TaskKey{
+		RunID:      taskRun.JobRunID,
+		TaskID:     taskRun.TaskID,
+		InstanceID: inst.ID,
+		Index:      inst.Index,
+	}, atomLogs(engine, a.ID()))
+	finalAtom, err := e.monitorTask(taskCtx, taskRun, engine, a)
+	taskLog.Finish()
+	if err != nil {
+		return "", nil, err
+	}
+	a = finalAtom
+
+	var output map[string]string
+	logs, logErr := engine.Logs(&atom.EngineLogsRequest{ID: a.ID()})
+	if logErr == nil {
+		markers, parseErr := pkgtask.CaptureMarkers(logs, pkgtask.MaxLogSnapshotBytes)
+		if closeErr := logs.Close(); closeErr != nil {
+			log.Warn("failed to close log stream", "task_id", taskRun.TaskID, "index", inst.Index, "error", closeErr)
+		}
+		if parseErr != nil {
+			log.Warn("failed to parse task markers", "task_id", taskRun.TaskID, "index", inst.Index, "error", parseErr)
+		} else if markers != nil {
+			output = markers.Output
+		}
+	}
+
+	if stopErr := engine.Stop(&atom.EngineStopRequest{ID: a.ID(), Force: true}); stopErr != nil {
+		log.Warn("failed to stop atom after instance completion", "task_id", taskRun.TaskID, "atom_id", a.ID(), "error", stopErr)
+	}
+
+	if err := e.runSchemaValidation(taskRun, output); err != nil {
+		return "", nil, err
+	}
+	return string(a.Result()), output, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -14,18 +15,25 @@ import (
 	"github.com/caesium-cloud/caesium/internal/atom/kubernetes"
 	"github.com/caesium-cloud/caesium/internal/atom/podman"
 	"github.com/caesium-cloud/caesium/internal/cache"
+	"github.com/caesium-cloud/caesium/internal/fanout"
+	"github.com/caesium-cloud/caesium/internal/gate"
 	"github.com/caesium-cloud/caesium/internal/imagecheck"
+	"github.com/caesium-cloud/caesium/internal/imagepolicy"
 	jobdefruntime "github.com/caesium-cloud/caesium/internal/jobdef/runtime"
 	"github.com/caesium-cloud/caesium/internal/jobdef/secret"
+	"github.com/caesium-cloud/caesium/internal/logarchive"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/replay"
 	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/sensor"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/container"
 	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	pkgtask "github.com/caesium-cloud/caesium/pkg/task"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -47,6 +55,9 @@ type runtimeExecutor struct {
 	// nil in production → defaults to dispatch.PostComplete; tests inject a fake.
 	completePost   completePoster
 	secretResolver secret.Resolver
+	// logArchive receives each task's complete log while it runs; nil when
+	// CAESIUM_LOG_ARCHIVE_BACKEND is unset.
+	logArchive *logarchive.Archive
 }
 
 func NewRuntimeExecutor(store *run.Store, taskTimeout time.Duration, failurePolicy string, resolvers ...secret.Resolver) TaskExecutor {
@@ -65,6 +76,7 @@ func NewRuntimeExecutor(store *run.Store, taskTimeout time.Duration, failurePoli
 		engineFactory:     defaultNewEngine,
 		localSink:         NewLocalSink(store),
 		secretResolver:    resolver,
+		logArchive:        logarchive.Default(),
 	}).Execute
 }
 
@@ -85,9 +97,21 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 		return
 	}
 
+	// A dispatched task continues the accepting node's span; a claimed task
+	// continues the run's trace from its row.
+	ctx, span := tracing.StartFrom(ctx, taskRun.TraceParent, "task.execute", trace.WithAttributes(
+		tracing.AttrRunID.String(taskRun.JobRunID.String()),
+		tracing.AttrTaskID.String(taskRun.TaskID.String()),
+		tracing.AttrAttempt.Int(taskRun.Attempt),
+		tracing.AttrNode.String(taskRun.ClaimedBy),
+		tracing.AttrEngine.String(string(taskRun.Engine)),
+		tracing.AttrImage.String(taskRun.Image),
+	))
+	defer span.End()
+
 	// Select the completion sink once per task: owner-routed for dispatched
 	// tasks, local DB writes for ClaimNext'd tasks.
-	sink := e.sinkFor(ctx)
+	sink := tracedSink{CompletionSink: e.sinkFor(ctx), span: span}
 
 	jobAlias := ""
 	resolveJobAlias := func() string {
@@ -165,7 +189,7 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 			return
 		}
 	}
-	runParams, err := e.loadRunParams(taskRun.JobRunID)
+	runParams, runStartedAt, err := e.loadRunParams(taskRun.JobRunID)
 	if err != nil {
 		log.Error("failed to load run params for worker task", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID, "error", err)
 		if persistErr := sink.Failed(ctx, taskRun, err); persistErr != nil && !errors.Is(persistErr, run.ErrTaskClaimMismatch) {
@@ -173,6 +197,37 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 		}
 		return
 	}
+	// The task run's command was rendered at registration and a replay's
+	// descriptor env already holds rendered values; render the atom env here
+	// for jobs that opted into run templates.
+	if descriptor == nil && e.loadJobRunTemplates(taskRun.JobRunID) {
+		data := jobdefschema.NewRunTemplateData(taskRun.JobRunID.String(), resolveJobAlias(), runParams, runStartedAt)
+		if atomSpec.Env, err = jobdefschema.RenderRunValues("env", atomSpec.Env, data); err != nil {
+			log.Error("failed to render env for worker task", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID, "error", err)
+			if persistErr := sink.Failed(ctx, taskRun, err); persistErr != nil && !errors.Is(persistErr, run.ErrTaskClaimMismatch) {
+				log.Error("failed to persist env render failure", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", persistErr)
+			}
+			return
+		}
+	}
+
+	// Image admission runs against the task's stored image before it is
+	// cached, gated, or pulled. A verified signature pins the image to the
+	// checked digest for the rest of this execution. Collecting an expanded
+	// map group starts no container; its instances were admitted on their own.
+	if !taskRun.MapExpanded && imagepolicy.Default().Mode() != imagepolicy.ModeOff {
+		image, admitErr := imagepolicy.Default().Admit(ctx, e.store.DB(), imagepolicy.Request{
+			Job:    resolveJobAlias(),
+			Step:   taskName,
+			Image:  taskRun.Image,
+			Labels: e.loadJobLabels(taskRun.JobRunID),
+		})
+		if admitErr != nil {
+			e.failTask(ctx, taskRun, sink, admitErr)
+			return
+		}
+		taskRun.Image = image
+	}
 
 	// Cache check: attempt to satisfy the task from cache before container execution.
 	var cacheStore *cache.Store
@@ -190,6 +245,53 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 		PinDigests: taskRun.CachePinDigests,
 		DigestTTL:  taskRun.CacheDigestTTL,
 	}
+
+	// A gated task is released while it waits for approval and claimed
+	// again once the request is decided. Quarantined replays re-execute a
+	// recorded run and are never re-gated, and a mapped step's gate was
+	// passed before its group expanded.
+	if hasTaskModel && descriptor == nil && taskRun.MapParentID == nil && !taskRun.MapExpanded {
+		gateSpec, specErr := gate.Spec(&taskModel)
+		if specErr != nil {
+			e.failTask(ctx, taskRun, sink, specErr)
+			return
+		}
+		if gateSpec != nil && !e.awaitGate(ctx, taskRun, sink, *gateSpec) {
+			return
+		}
+	}
+
+	// A sensor runs no container; it pokes its probe until the condition
+	// holds, and the result cache never applies.
+	if hasTaskModel {
+		sensorSpec, specErr := sensor.Spec(&taskModel)
+		if specErr != nil {
+			e.failTask(ctx, taskRun, sink, specErr)
+			return
+		}
+		if sensorSpec != nil {
+			data := jobdefschema.NewRunTemplateData(taskRun.JobRunID.String(), resolveJobAlias(), runParams, runStartedAt)
+			e.executeSensor(ctx, taskRun, sink, *sensorSpec, data, resolveJobAlias())
+			return
+		}
+	}
+
+	if hasTaskModel {
+		mapSpec, specErr := fanout.Spec(&taskModel)
+		if specErr != nil {
+			e.failTask(ctx, taskRun, sink, specErr)
+			return
+		}
+		if mapSpec != nil && taskRun.MapParentID != nil {
+			e.executeMapInstance(ctx, taskRun, &taskModel, *mapSpec, atomSpec, runParams, cacheCfg, resolveJobAlias())
+			return
+		}
+		if mapSpec != nil {
+			e.executeMapped(ctx, taskRun, sink, &taskModel, *mapSpec, atomSpec, runParams, cacheCfg, resolveJobAlias())
+			return
+		}
+	}
+
 	if cacheCfg.Enabled {
 		cacheStore = cache.NewStore(e.store.DB())
 
@@ -210,44 +312,8 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 			log.Warn("cache: failed to query predecessor descriptor inputs", "task_id", taskRun.TaskID, "error", descriptorErr)
 		}
 
-		// Build merged env for hashing, excluding volatile per-run vars.
-		mergedEnv := make(map[string]string, len(atomSpec.Env))
-		for k, v := range atomSpec.Env {
-			mergedEnv[k] = v
-		}
-		if outputEnv := pkgtask.BuildOutputEnv(predOutputs); len(outputEnv) > 0 {
-			for k, v := range outputEnv {
-				mergedEnv[k] = v
-			}
-		}
-
-		// When digest pinning is on, resolve the image tag to its content
-		// digest and fold the digest into the cache key. A resolution failure
-		// falls back to the literal tag — a cache miss is always safe.
-		if descriptor != nil && descriptor.Runtime.ResolvedImageDigest != "" {
-			resolvedImageDigest = descriptor.Runtime.ResolvedImageDigest
-		} else if cacheCfg.PinDigests {
-			if digest, derr := imagecheck.Default().Resolve(ctx, taskRun.Engine, taskRun.Image, cacheCfg.DigestTTL); derr == nil {
-				resolvedImageDigest = digest
-			}
-		}
-
-		hashInput := cache.HashInput{
-			JobAlias:             cacheJobAlias,
-			TaskName:             taskName,
-			Image:                taskRun.Image,
-			ResolvedImageDigest:  resolvedImageDigest,
-			Command:              parseTaskCommand(taskRun.Command),
-			Env:                  mergedEnv,
-			WorkDir:              atomSpec.WorkDir,
-			Mounts:               atomSpec.Mounts,
-			ResolvedVolumeMounts: atomSpec.ResolvedVolumeMounts,
-			Kubernetes:           atomSpec.Kubernetes,
-			PredecessorHashes:    predHashes,
-			PredecessorOutputs:   predOutputs,
-			RunParams:            runParams,
-			CacheVersion:         cacheCfg.Version,
-		}
+		hashInput := e.buildHashInput(ctx, taskRun, descriptor, atomSpec, cacheCfg, cacheJobAlias, taskName, predOutputs, predHashes, runParams)
+		resolvedImageDigest = hashInput.ResolvedImageDigest
 		cacheHash = hashInput.Compute()
 		// Serialize the decomposed input to a canonical, secret-redacted blob so
 		// a distributed worker persists the same field-by-field record the local
@@ -333,19 +399,11 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 			break
 		}
 
-		// Compute retry delay (retryDelay * 2^(attempt-1) if backoff, else retryDelay).
-		var delay time.Duration
-		if descriptor != nil && descriptor.Runtime.RetryDelay > 0 {
-			delay = descriptor.Runtime.RetryDelay
-			if descriptor.Runtime.RetryBackoff {
-				delay = descriptor.Runtime.RetryDelay * (1 << uint(attempt-1))
-			}
-		} else if hasTaskModel && taskModel.RetryDelay > 0 {
-			delay = taskModel.RetryDelay
-			if taskModel.RetryBackoff {
-				delay = taskModel.RetryDelay * (1 << uint(attempt-1))
-			}
+		var retryModel *models.Task
+		if hasTaskModel {
+			retryModel = &taskModel
 		}
+		delay := retryDelay(descriptor, retryModel, attempt)
 
 		log.Info("retrying worker task", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "attempt", attempt, "next_attempt", attempt+1, "delay", delay, "error", lastErr)
 
@@ -374,7 +432,13 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 		}
 	}
 
-	if persistErr := sink.Failed(ctx, taskRun, lastErr); persistErr != nil {
+	e.failTask(ctx, taskRun, sink, lastErr)
+}
+
+// failTask records a task's terminal failure through the sink and, under the
+// continue failure policy, skips its descendants.
+func (e *runtimeExecutor) failTask(ctx context.Context, taskRun *models.TaskRun, sink CompletionSink, failure error) {
+	if persistErr := sink.Failed(ctx, taskRun, failure); persistErr != nil {
 		if errors.Is(persistErr, run.ErrTaskClaimMismatch) {
 			log.Info("worker task claim changed before failure persistence", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
 			return
@@ -400,6 +464,119 @@ func (e *runtimeExecutor) Execute(ctx context.Context, taskRun *models.TaskRun)
 	}
 }
 
+// awaitGate checks a claimed gated task's approval request and reports
+// whether the task should go on to execute. While the request is pending the
+// task stays in awaiting_approval but is released with a retry-after instead
+// of holding this worker and its claim for the whole approval window; a
+// decision makes it claimable again at once. Rejected and expired gates are completed here:
+// failed, or skipped when onTimeout is skip.
+func (e *runtimeExecutor) awaitGate(ctx context.Context, taskRun *models.TaskRun, sink CompletionSink, spec jobdefschema.StepGate) bool {
+	decided, err := gate.Open(e.store, taskRun.JobRunID, taskRun.TaskID, taskRun.ClaimedBy, spec)
+	switch {
+	case errors.Is(err, run.ErrTaskClaimMismatch):
+		log.Info("worker task claim changed before approval gate", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+		return false
+	case err != nil:
+		e.failTask(ctx, taskRun, sink, err)
+		return false
+	}
+
+	if decided.Pending() {
+		retryAfter := time.Now().Add(gate.RecheckInterval)
+		if err := e.store.ReleaseGatedTask(ctx, taskRun.JobRunID, taskRun.TaskID, taskRun.ClaimedBy, retryAfter); err != nil {
+			if errors.Is(err, run.ErrTaskClaimMismatch) {
+				log.Info("worker task claim changed before approval gate release", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+				return false
+			}
+			log.Error("failed to release task awaiting approval", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", err)
+		}
+		return false
+	}
+
+	if reason, ok := gate.Skipped(decided); ok {
+		if _, err := e.store.SkipGatedTask(taskRun.JobRunID, taskRun.TaskID, reason, taskRun.ClaimedBy); err != nil && !errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Error("failed to persist approval gate skip", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", err)
+		}
+		return false
+	}
+	if failure := gate.Failure(decided); failure != nil {
+		e.failTask(ctx, taskRun, sink, failure)
+		return false
+	}
+	if err := e.store.ResumeApprovedTask(taskRun.JobRunID, taskRun.TaskID, taskRun.ClaimedBy); err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker task claim changed after approval", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+			return false
+		}
+		e.failTask(ctx, taskRun, sink, err)
+		return false
+	}
+	return true
+}
+
+// retryDelay computes the delay before the attempt after the given one:
+// retryDelay * 2^(attempt-1) with backoff, else retryDelay. A replay
+// descriptor's captured policy wins over the task model's.
+func retryDelay(descriptor *models.TaskExecutionDescriptor, taskModel *models.Task, attempt int) time.Duration {
+	if descriptor != nil && descriptor.Runtime.RetryDelay > 0 {
+		if descriptor.Runtime.RetryBackoff {
+			return descriptor.Runtime.RetryDelay * (1 << uint(attempt-1))
+		}
+		return descriptor.Runtime.RetryDelay
+	}
+	if taskModel != nil && taskModel.RetryDelay > 0 {
+		if taskModel.RetryBackoff {
+			return taskModel.RetryDelay * (1 << uint(attempt-1))
+		}
+		return taskModel.RetryDelay
+	}
+	return 0
+}
+
+// buildHashInput rebuilds the cache identity inputs the local executor hashes
+// for a task from the scheduler-propagated TaskRun plus predecessor data.
+func (e *runtimeExecutor) buildHashInput(ctx context.Context, taskRun *models.TaskRun, descriptor *models.TaskExecutionDescriptor, atomSpec container.Spec, cacheCfg jobdefschema.CacheConfig, jobAlias, taskName string, predOutputs map[string]map[string]string, predHashes []string, runParams map[string]string) cache.HashInput {
+	// Build merged env for hashing, excluding volatile per-run vars.
+	mergedEnv := make(map[string]string, len(atomSpec.Env))
+	for k, v := range atomSpec.Env {
+		mergedEnv[k] = v
+	}
+	if outputEnv := pkgtask.BuildOutputEnv(predOutputs); len(outputEnv) > 0 {
+		for k, v := range outputEnv {
+			mergedEnv[k] = v
+		}
+	}
+
+	// When digest pinning is on, resolve the image tag to its content
+	// digest and fold the digest into the cache key. A resolution failure
+	// falls back to the literal tag — a cache miss is always safe.
+	var resolvedImageDigest string
+	if descriptor != nil && descriptor.Runtime.ResolvedImageDigest != "" {
+		resolvedImageDigest = descriptor.Runtime.ResolvedImageDigest
+	} else if cacheCfg.PinDigests {
+		if digest, derr := imagecheck.Default().Resolve(ctx, taskRun.Engine, taskRun.Image, cacheCfg.DigestTTL); derr == nil {
+			resolvedImageDigest = digest
+		}
+	}
+
+	return cache.HashInput{
+		JobAlias:             jobAlias,
+		TaskName:             taskName,
+		Image:                taskRun.Image,
+		ResolvedImageDigest:  resolvedImageDigest,
+		Command:              parseTaskCommand(taskRun.Command),
+		Env:                  mergedEnv,
+		WorkDir:              atomSpec.WorkDir,
+		Mounts:               atomSpec.Mounts,
+		ResolvedVolumeMounts: atomSpec.ResolvedVolumeMounts,
+		Kubernetes:           atomSpec.Kubernetes,
+		PredecessorHashes:    predHashes,
+		PredecessorOutputs:   predOutputs,
+		RunParams:            runParams,
+		CacheVersion:         cacheCfg.Version,
+	}
+}
+
 // sleepRetryDelay sleeps for the given duration, respecting context cancellation.
 // Lease renewal during retry delays is handled by the per-node batched renewal
 // ticker on the Worker (see Worker.runLeaseRenewal).
@@ -427,19 +604,47 @@ func (e *runtimeExecutor) loadAtomSpec(atomID uuid.UUID) (container.Spec, error)
 	return spec, nil
 }
 
-func (e *runtimeExecutor) loadRunParams(runID uuid.UUID) (map[string]string, error) {
+func (e *runtimeExecutor) loadRunParams(runID uuid.UUID) (map[string]string, time.Time, error) {
 	var jobRun models.JobRun
-	if err := e.store.DB().Select("params").First(&jobRun, "id = ?", runID).Error; err != nil {
-		return nil, err
+	if err := e.store.DB().Select("params", "started_at").First(&jobRun, "id = ?", runID).Error; err != nil {
+		return nil, time.Time{}, err
 	}
 	if len(jobRun.Params) == 0 {
-		return nil, nil
+		return nil, jobRun.StartedAt, nil
 	}
 	var params map[string]string
 	if err := json.Unmarshal(jobRun.Params, &params); err != nil {
-		return nil, fmt.Errorf("decode run params: %w", err)
+		return nil, time.Time{}, fmt.Errorf("decode run params: %w", err)
 	}
-	return params, nil
+	return params, jobRun.StartedAt, nil
+}
+
+// loadJobRunTemplates reports whether the job a run belongs to renders run
+// templates. A lookup failure is logged and treated as disabled.
+func (e *runtimeExecutor) loadJobRunTemplates(runID uuid.UUID) bool {
+	var job models.Job
+	err := e.store.DB().Select("run_templates").
+		Where("id = (?)", e.store.DB().Model(&models.JobRun{}).Select("job_id").Where("id = ?", runID)).
+		First(&job).Error
+	if err != nil {
+		log.Warn("failed to load job run template setting", "run_id", runID, "error", err)
+		return false
+	}
+	return job.RunTemplates
+}
+
+// loadJobLabels returns the labels of the job a run belongs to. A lookup
+// failure is logged and treated as no labels.
+func (e *runtimeExecutor) loadJobLabels(runID uuid.UUID) map[string]string {
+	var job models.Job
+	err := e.store.DB().Select("labels").
+		Where("id = (?)", e.store.DB().Model(&models.JobRun{}).Select("job_id").Where("id = ?", runID)).
+		First(&job).Error
+	if err != nil {
+		log.Warn("failed to load job labels for image policy", "run_id", runID, "error", err)
+		return nil
+	}
+	return imagepolicy.Labels(job.Labels)
 }
 
 func buildRunParamEnv(runID uuid.UUID, jobAlias string, params map[string]string) map[string]string {
@@ -500,8 +705,9 @@ func (e *runtimeExecutor) executeTask(ctx context.Context, taskRun *models.TaskR
 	}
 	paramEnv := buildRunParamEnv(taskRun.JobRunID, jobAlias, runParams)
 	outputEnv := pkgtask.BuildOutputEnv(predOutputs)
-	if len(spec.Env) > 0 || len(paramEnv) > 0 || len(outputEnv) > 0 {
-		merged := make(map[string]string, len(spec.Env)+len(paramEnv)+len(outputEnv))
+	traceparent := tracing.Traceparent(ctx)
+	if len(spec.Env) > 0 || len(paramEnv) > 0 || len(outputEnv) > 0 || traceparent != "" {
+		merged := make(map[string]string, len(spec.Env)+len(paramEnv)+len(outputEnv)+1)
 		for k, v := range spec.Env {
 			merged[k] = v
 		}
@@ -511,15 +717,21 @@ func (e *runtimeExecutor) executeTask(ctx context.Context, taskRun *models.TaskR
 		for k, v := range outputEnv {
 			merged[k] = v
 		}
+		if traceparent != "" {
+			merged[tracing.EnvTraceparent] = traceparent
+		}
 		spec.Env = merged
 	}
 
+	// atom.create covers the image pull as well as container creation.
+	_, createSpan := tracing.Start(ctx, "atom.create", trace.WithAttributes(tracing.AttrImage.String(taskRun.Image)))
 	a, err := engine.Create(&atom.EngineCreateRequest{
 		Name:    atomName,
 		Image:   taskRun.Image,
 		Command: command,
 		Spec:    spec,
 	})
+	tracing.End(createSpan, err)
 	if err != nil {
 		return err
 	}
@@ -528,7 +740,13 @@ func (e *runtimeExecutor) executeTask(ctx context.Context, taskRun *models.TaskR
 		return err
 	}
 
+	// Stream the complete log into the archive while the atom runs; it is
+	// sealed before Stop removes the container.
+	taskLog := e.logArchive.RecordTask(ctx, e.store, logarchive.TaskKey{RunID: taskRun.JobRunID, TaskID: taskRun.TaskID}, atomLogs(engine, a.ID()))
+	_, waitSpan := tracing.Start(ctx, "atom.wait")
 	finalAtom, monitorErr := e.monitorTask(taskCtx, taskRun, engine, a)
+	tracing.End(waitSpan, monitorErr)
+	taskLog.Finish()
 	if monitorErr != nil {
 		return monitorErr
 	}
@@ -602,6 +820,13 @@ func (e *runtimeExecutor) runSchemaValidation(taskRun *models.TaskRun, output ma
 	return run.ValidateTaskOutputSchema(e.store, taskRun.JobRunID, taskRun.TaskID, output, taskRun.OutputSchema, taskRun.SchemaValidation)
 }
 
+// atomLogs opens the log stream of the atom with the given ID.
+func atomLogs(engine atom.Engine, atomID string) logarchive.Opener {
+	return func() (io.ReadCloser, error) {
+		return engine.Logs(&atom.EngineLogsRequest{ID: atomID})
+	}
+}
+
 // storeCacheEntry reads back the completed task run and stores the result in the cache.
 func (e *runtimeExecutor) storeCacheEntry(cacheStore *cache.Store, cacheCfg jobdefschema.CacheConfig, hash, resolvedImageDigest string, hashInputBlob []byte, taskRun *models.TaskRun, jobAlias string) {
 	if taskRun.Quarantine {
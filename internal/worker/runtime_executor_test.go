@@ -12,6 +12,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/atom"
 	"github.com/caesium-cloud/caesium/internal/jobdef/secret"
 	jobdeftestutil "github.com/caesium-cloud/caesium/internal/jobdef/testutil"
+	"github.com/caesium-cloud/caesium/internal/logarchive"
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/internal/run"
 	"github.com/caesium-cloud/caesium/pkg/container"
@@ -376,6 +377,60 @@ func TestRuntimeExecutorQuarantinedTaskSkipsCacheWrite(t *testing.T) {
 	require.NotEmpty(t, persisted.Hash)
 }
 
+func TestExecuteInstanceArchivesInstanceLog(t *testing.T) {
+	db := jobdeftestutil.OpenTestDB(t)
+	t.Cleanup(func() {
+		jobdeftestutil.CloseDB(db)
+	})
+
+	store := run.NewStore(db)
+	now := time.Now().UTC()
+	trigger := &models.Trigger{ID: uuid.New(), Alias: "trigger", Type: models.TriggerTypeCron, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(trigger).Error)
+	job := &models.Job{ID: uuid.New(), Alias: "worker-mapped-job", TriggerID: trigger.ID, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(job).Error)
+	atomModel := &models.Atom{ID: uuid.New(), Engine: models.AtomEngineDocker, Image: "alpine:3.23", Command: `["true"]`, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(atomModel).Error)
+	task := &models.Task{ID: uuid.New(), JobID: job.ID, AtomID: atomModel.ID, Name: "fan", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(task).Error)
+	jobRun := &models.JobRun{ID: uuid.New(), JobID: job.ID, TriggerID: trigger.ID, TriggerType: string(trigger.Type), Status: string(run.StatusRunning), StartedAt: now, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(jobRun).Error)
+	require.NoError(t, store.RegisterTasks(jobRun.ID, []run.RegisterTaskInput{{Task: task, Atom: atomModel}}))
+	instances, err := store.ExpandMapTask(jobRun.ID, task.ID, []string{"a", "b"})
+	require.NoError(t, err)
+	var taskRun models.TaskRun
+	require.NoError(t, db.First(&taskRun, "job_run_id = ? AND task_id = ?", jobRun.ID, task.ID).Error)
+
+	fileStore, err := logarchive.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	archive := logarchive.New(fileStore, 0)
+	executor := &runtimeExecutor{
+		store:      store,
+		localSink:  NewLocalSink(store),
+		logArchive: archive,
+		engineFactory: func(context.Context, models.AtomEngine) (atom.Engine, error) {
+			return &loggingEngine{text: "processing b\n"}, nil
+		},
+	}
+	result, _, err := executor.executeInstance(context.Background(), &taskRun, instances[1], len(instances), 1, container.Spec{}, nil, nil, job.Alias)
+	require.NoError(t, err)
+	require.Equal(t, string(atom.Success), result)
+
+	ref := logarchive.InstanceRef(jobRun.ID, task.ID, 1)
+	var persisted models.TaskRunInstance
+	require.NoError(t, db.First(&persisted, "id = ?", instances[1].ID).Error)
+	require.Equal(t, ref, persisted.LogArchiveRef)
+	object, err := archive.Open(context.Background(), ref)
+	require.NoError(t, err)
+	require.True(t, object.Complete())
+	reader, err := object.NewReader(context.Background(), 0)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	text, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "processing b\n", string(text))
+}
+
 func seedSchemaValidationTaskRun(t *testing.T, schemaValidation string) (*models.TaskRun, *gorm.DB) {
 	t.Helper()
 
@@ -523,3 +578,13 @@ func (e *captureCreateEngine) Stop(*atom.EngineStopRequest) error {
 func (e *captureCreateEngine) Logs(*atom.EngineLogsRequest) (io.ReadCloser, error) {
 	return io.NopCloser(strings.NewReader("")), nil
 }
+
+// loggingEngine is a captureCreateEngine whose atoms write text to their log.
+type loggingEngine struct {
+	captureCreateEngine
+	text string
+}
+
+func (e *loggingEngine) Logs(*atom.EngineLogsRequest) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(e.text)), nil
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/caesium-cloud/caesium/internal/atom"
+	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/run"
+	"github.com/caesium-cloud/caesium/internal/sensor"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
+	"github.com/caesium-cloud/caesium/pkg/log"
+)
+
+// executeSensor runs a claimed sensor step by poking its probe in place of a
+// container. In poke mode the worker keeps the claim (and lease) between
+// pokes; in reschedule mode it releases the task after an unsatisfied poke,
+// and the next claim or dispatch resumes it once the next poke is due.
+func (e *runtimeExecutor) executeSensor(ctx context.Context, taskRun *models.TaskRun, sink CompletionSink, spec jobdefschema.StepSensor, data jobdefschema.RunTemplateData, jobAlias string) {
+	if err := e.store.StartTaskClaimed(taskRun.JobRunID, taskRun.TaskID, "", taskRun.ClaimedBy); err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker task claim changed before sensor poke", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+			return
+		}
+		e.failTask(ctx, taskRun, sink, err)
+		return
+	}
+
+	outcome, err := sensor.Await(ctx, e.store, taskRun.JobRunID, taskRun.TaskID, spec, data, jobAlias)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		log.Info("worker task canceled while sensing", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+		return
+	case err != nil:
+		e.failTask(ctx, taskRun, sink, err)
+		return
+	}
+
+	if !outcome.RescheduleAt.IsZero() {
+		if err := e.store.RescheduleSensorTask(ctx, taskRun.JobRunID, taskRun.TaskID, taskRun.ClaimedBy, outcome.RescheduleAt); err != nil {
+			if errors.Is(err, run.ErrTaskClaimMismatch) {
+				log.Info("worker task claim changed before sensor reschedule", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+				return
+			}
+			log.Error("failed to reschedule sensor task", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", err)
+		}
+		return
+	}
+	if reason, ok := sensor.Skipped(spec, outcome); ok {
+		if _, err := e.store.SkipSensorTask(taskRun.JobRunID, taskRun.TaskID, reason, taskRun.ClaimedBy); err != nil && !errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Error("failed to persist sensor timeout skip", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", err)
+		}
+		return
+	}
+	if failure := sensor.Failure(spec, outcome); failure != nil {
+		e.failTask(ctx, taskRun, sink, failure)
+		return
+	}
+
+	if err := sink.Succeeded(ctx, taskRun, string(atom.Success), outcome.Output, nil); err != nil {
+		if errors.Is(err, run.ErrTaskClaimMismatch) {
+			log.Info("worker task claim changed before sensor completion", "task_id", taskRun.TaskID, "run_id", taskRun.JobRunID)
+			return
+		}
+		log.Error("failed to persist sensor completion", "run_id", taskRun.JobRunID, "task_id", taskRun.TaskID, "error", err)
+	}
+}
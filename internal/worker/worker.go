@@ -5,11 +5,14 @@ import (
 	"errors"
 	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/caesium-cloud/caesium/internal/cordon"
 	"github.com/caesium-cloud/caesium/internal/dispatch"
 	"github.com/caesium-cloud/caesium/internal/metrics"
 	"github.com/caesium-cloud/caesium/internal/models"
+	"github.com/caesium-cloud/caesium/internal/tracing"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	"github.com/google/uuid"
 )
@@ -27,6 +30,10 @@ const (
 	defaultPollInterval    = 15 * time.Second
 	defaultReclaimInterval = 30 * time.Second
 
+	// defaultRegisterInterval is how often a worker refreshes its
+	// worker_nodes row; comfortably inside placement.DefaultNodeTTL.
+	defaultRegisterInterval = time.Minute
+
 	// defaultLeaseRenewDivisor is the fraction of lease_ttl used as the
 	// renewal interval when no explicit interval is configured.  ttl/4 gives
 	// three full renewal cycles before a lease would expire.
@@ -43,6 +50,24 @@ type ExpiredReclaimer interface {
 	ReclaimExpired(ctx context.Context) error
 }
 
+// ClaimReleaser is implemented by Claimer and used to hand a draining node's
+// running tasks back once its drain deadline passes.
+type ClaimReleaser interface {
+	ReleaseClaims(ctx context.Context, ids []uuid.UUID) (int64, error)
+}
+
+// NodeStateReader is implemented by cordon.Store and reports whether an
+// operator has cordoned or drained a node.
+type NodeStateReader interface {
+	Get(ctx context.Context, address string) (models.NodeState, error)
+}
+
+// NodeRegistrar is implemented by placement.Registry and advertises this
+// node's labels to the run-owner dispatcher and the job lint.
+type NodeRegistrar interface {
+	Register(ctx context.Context, address string, labels map[string]string) error
+}
+
 type ReclaimGate interface {
 	CanReclaim(ctx context.Context) (bool, error)
 }
@@ -61,11 +86,22 @@ type LeaseRenewer interface {
 	RenewLeases(ctx context.Context, nodeID string, ids []uuid.UUID, newExpiresAt time.Time) (int64, error)
 }
 
+// CancellationWatcher is implemented by run.Store and used by the worker's
+// cancellation watch goroutine to learn which in-flight task runs were
+// cancelled (possibly by an operator request served on another node).
+type CancellationWatcher interface {
+	CancelledTaskRuns(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+}
+
 // inFlightClaim records the minimal state needed to decide whether renewal is
-// required for a single in-flight task run.
+// required for a single in-flight task run, plus the cancel func that stops
+// its execution when the task run is cancelled and a channel closed once that
+// execution has returned.
 type inFlightClaim struct {
 	claimedBy      string
 	claimExpiresAt time.Time
+	cancel         context.CancelFunc
+	done           chan struct{}
 }
 
 type Worker struct {
@@ -85,6 +121,24 @@ type Worker struct {
 	inFlightMu         sync.Mutex
 	inFlight           map[uuid.UUID]*inFlightClaim
 
+	// Cancellation watch for in-flight task runs.
+	cancelWatcher  CancellationWatcher
+	cancelInterval time.Duration
+
+	// Cordon and drain watch for this node.
+	nodeStates  NodeStateReader
+	drainNodeID string
+	// unschedulable is set while the node is cordoned or draining, so
+	// dispatched tasks are turned away.
+	unschedulable atomic.Bool
+	drained       bool
+
+	// Label registration for this node.
+	registrar        NodeRegistrar
+	registerNodeID   string
+	registerLabels   map[string]string
+	registerInterval time.Duration
+
 	// Batched run-lease renewal (Phase 2 run-owner mode).
 	runLeaseRenewer RunLeaseRenewer
 	runLeaseTTL     time.Duration
@@ -109,8 +163,9 @@ type Worker struct {
 // inboundTask pairs a dispatched task with the owner metadata the executor
 // needs to route its completion back to the owner.
 type inboundTask struct {
-	task *models.TaskRun
-	meta dispatchMeta
+	task        *models.TaskRun
+	meta        dispatchMeta
+	traceparent string
 }
 
 func NewWorker(claimer TaskClaimer, pool *Pool, pollInterval time.Duration, executor TaskExecutor) *Worker {
@@ -170,6 +225,39 @@ func (w *Worker) WithLeaseRenewal(renewer LeaseRenewer, leaseTTL, renewInterval
 	return w
 }
 
+// WithCancellationWatch polls watcher every interval for in-flight task runs
+// that were cancelled and cancels their execution context, which stops the
+// atom through the executor's engine Stop path. An interval <= 0 uses the
+// poll interval.
+func (w *Worker) WithCancellationWatch(watcher CancellationWatcher, interval time.Duration) *Worker {
+	if interval <= 0 {
+		interval = w.pollInterval
+	}
+	w.cancelWatcher = watcher
+	w.cancelInterval = interval
+	return w
+}
+
+// WithDrainWatch polls states every poll interval for nodeID's cordon and
+// drain state. While the node is cordoned or draining it rejects dispatched
+// tasks; once a drain's deadline passes it releases the claims of the tasks
+// still running and stops them, so other nodes take them over.
+func (w *Worker) WithDrainWatch(states NodeStateReader, nodeID string) *Worker {
+	w.nodeStates = states
+	w.drainNodeID = nodeID
+	return w
+}
+
+// WithNodeRegistration advertises nodeID's labels through registrar when the
+// worker starts and again every minute, so placement sees the node as live.
+func (w *Worker) WithNodeRegistration(registrar NodeRegistrar, nodeID string, labels map[string]string) *Worker {
+	w.registrar = registrar
+	w.registerNodeID = nodeID
+	w.registerLabels = labels
+	w.registerInterval = defaultRegisterInterval
+	return w
+}
+
 // WithRunLeaseRenewal configures per-node batched run-lease renewal for
 // Phase 2 run-owner mode.  Piggybacked on the same ticker cadence as task
 // claim renewals (leaseTTL/4).  nodeID is the CAESIUM_NODE_ADDRESS value
@@ -209,6 +297,10 @@ func (w *Worker) WithInboundDispatch(completionToken string) *Worker {
 // rejectable condition and rolls the claim back so the owner re-dispatches.
 var ErrInboundFull = errors.New("worker: inbound dispatch buffer full")
 
+// ErrWorkerCordoned is returned by SubmitDispatched while an operator has
+// cordoned or drained this node.
+var ErrWorkerCordoned = errors.New("worker: node is cordoned")
+
 // ErrWorkerNotAccepting is returned by SubmitDispatched when the worker is not
 // configured to accept dispatched tasks (WithInboundDispatch was never called).
 var ErrWorkerNotAccepting = errors.New("worker: not accepting dispatched tasks")
@@ -229,6 +321,9 @@ func (w *Worker) SubmitDispatched(d dispatch.InboundDispatch) error {
 	if d.Task == nil {
 		return errors.New("worker: dispatched task is nil")
 	}
+	if w.unschedulable.Load() {
+		return ErrWorkerCordoned
+	}
 	// The completion bearer token is the internal wakeup token the worker holds
 	// (from WithInboundDispatch); it is NOT carried in the dispatch envelope so
 	// it never travels owner→worker on the wire needlessly.
@@ -240,7 +335,7 @@ func (w *Worker) SubmitDispatched(d dispatch.InboundDispatch) error {
 		Attempt:         d.Attempt,
 	}
 	select {
-	case w.inbound <- inboundTask{task: d.Task, meta: meta}:
+	case w.inbound <- inboundTask{task: d.Task, meta: meta, traceparent: d.TraceParent}:
 		// Poke the wake signal (non-blocking; a full notify buffer means the
 		// loop is already about to drain).
 		select {
@@ -278,6 +373,18 @@ func (w *Worker) Run(ctx context.Context) error {
 		go w.runLeaseRenewal(ctx)
 	}
 
+	if w.cancelWatcher != nil && w.cancelInterval > 0 {
+		go w.runCancellationWatch(ctx)
+	}
+
+	if w.nodeStates != nil && w.drainNodeID != "" {
+		go w.runDrainWatch(ctx)
+	}
+
+	if w.registrar != nil && w.registerNodeID != "" {
+		go w.runNodeRegistration(ctx)
+	}
+
 	// Start the run-lease renewal goroutine when Phase 2 owner mode is active.
 	if w.runLeaseRenewer != nil && w.runLeaseTTL > 0 && w.runLeaseNodeID != "" {
 		go w.runRunLeaseRenewal(ctx)
@@ -348,7 +455,7 @@ func (w *Worker) drainInbound(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		case in := <-w.inbound:
-			execCtx := withDispatchMeta(ctx, in.meta)
+			execCtx := tracing.WithTraceparent(withDispatchMeta(ctx, in.meta), in.traceparent)
 			if err := w.submitToPool(execCtx, ctx, in.task); err != nil {
 				return err
 			}
@@ -365,26 +472,35 @@ func (w *Worker) drainInbound(ctx context.Context) error {
 // registration is undone so the lease-renewal ticker doesn't track a task that
 // never ran.
 func (w *Worker) submitToPool(execCtx, submitCtx context.Context, task *models.TaskRun) error {
+	execCtx, cancel := context.WithCancel(execCtx)
+	done := make(chan struct{})
 	// Register the claim before submitting so the renewal ticker can see it as
 	// soon as the goroutine is alive, even before execution starts.
-	w.trackInFlight(task)
+	w.trackInFlight(task, cancel, done)
 	if err := w.pool.Submit(submitCtx, func() {
+		defer close(done)
 		defer w.untrackInFlight(task.ID)
+		defer cancel()
 		w.executor(execCtx, task)
 	}); err != nil {
 		w.untrackInFlight(task.ID)
+		cancel()
+		close(done)
 		return err
 	}
 	return nil
 }
 
-// trackInFlight registers a task run as in-flight for lease renewal purposes.
-func (w *Worker) trackInFlight(task *models.TaskRun) {
+// trackInFlight registers a task run as in-flight for lease renewal and
+// cancellation purposes.
+func (w *Worker) trackInFlight(task *models.TaskRun, cancel context.CancelFunc, done chan struct{}) {
 	if task == nil {
 		return
 	}
 	claim := &inFlightClaim{
 		claimedBy: task.ClaimedBy,
+		cancel:    cancel,
+		done:      done,
 	}
 	if task.ClaimExpiresAt != nil {
 		claim.claimExpiresAt = *task.ClaimExpiresAt
@@ -483,6 +599,183 @@ func (w *Worker) renewLeasesNow(ctx context.Context) {
 	}
 }
 
+// runCancellationWatch is the background goroutine that stops in-flight task
+// runs once they are cancelled.
+func (w *Worker) runCancellationWatch(ctx context.Context) {
+	ticker := time.NewTicker(w.cancelInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.cancelInFlightNow(ctx)
+		}
+	}
+}
+
+// cancelInFlightNow asks the watcher which in-flight task runs were cancelled
+// and cancels their execution contexts. The executor treats the resulting
+// context.Canceled as a quiet exit after stopping the atom, and the cancelled
+// row's cleared claim makes any late completion a claim mismatch.
+func (w *Worker) cancelInFlightNow(ctx context.Context) {
+	if w.cancelWatcher == nil {
+		return
+	}
+
+	w.inFlightMu.Lock()
+	ids := make([]uuid.UUID, 0, len(w.inFlight))
+	for id := range w.inFlight {
+		ids = append(ids, id)
+	}
+	w.inFlightMu.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+
+	cancelled, err := w.cancelWatcher.CancelledTaskRuns(ctx, ids)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("failed to check in-flight task cancellation", "count", len(ids), "error", err)
+		}
+		return
+	}
+
+	w.inFlightMu.Lock()
+	defer w.inFlightMu.Unlock()
+	for _, id := range cancelled {
+		if claim, ok := w.inFlight[id]; ok && claim.cancel != nil {
+			log.Info("stopping cancelled in-flight task", "task_run_id", id)
+			claim.cancel()
+		}
+	}
+}
+
+// runDrainWatch is the background goroutine that follows this node's cordon
+// and drain state.
+func (w *Worker) runDrainWatch(ctx context.Context) {
+	w.checkDrainNow(ctx)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.checkDrainNow(ctx)
+		}
+	}
+}
+
+// checkDrainNow reads the node's state and, for a drain whose deadline has
+// passed, hands the in-flight tasks back.
+func (w *Worker) checkDrainNow(ctx context.Context) {
+	state, err := w.nodeStates.Get(ctx, w.drainNodeID)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warn("failed to read node cordon state", "node_id", w.drainNodeID, "error", err)
+		}
+		return
+	}
+	current := cordon.State(state.State)
+	w.unschedulable.Store(!current.Schedulable())
+	if current != cordon.StateDraining {
+		w.drained = false
+		return
+	}
+
+	w.inFlightMu.Lock()
+	running := len(w.inFlight)
+	w.inFlightMu.Unlock()
+	if running == 0 {
+		if !w.drained {
+			log.Info("worker drained", "node_id", w.drainNodeID)
+			w.drained = true
+		}
+		return
+	}
+	w.drained = false
+	if state.DrainDeadline == nil || time.Now().Before(*state.DrainDeadline) {
+		return
+	}
+	w.handBackInFlight(ctx)
+}
+
+// handBackInFlight stops the execution of every in-flight task, waits for
+// those executions to return, and only then releases their claims, so no
+// other node can claim a task while it still runs here. The tasks leave the
+// in-flight set first so lease renewal cannot extend a claim that is being
+// released.
+func (w *Worker) handBackInFlight(ctx context.Context) {
+	releaser, ok := w.claimer.(ClaimReleaser)
+	if !ok {
+		return
+	}
+
+	w.inFlightMu.Lock()
+	ids := make([]uuid.UUID, 0, len(w.inFlight))
+	claims := make([]*inFlightClaim, 0, len(w.inFlight))
+	for id, claim := range w.inFlight {
+		ids = append(ids, id)
+		claims = append(claims, claim)
+		delete(w.inFlight, id)
+	}
+	w.inFlightMu.Unlock()
+
+	for _, claim := range claims {
+		if claim.cancel != nil {
+			claim.cancel()
+		}
+	}
+	for _, claim := range claims {
+		if claim.done == nil {
+			continue
+		}
+		select {
+		case <-claim.done:
+		case <-ctx.Done():
+			// The claims were not released and are no longer renewed, so
+			// they expire after the lease TTL.
+			return
+		}
+	}
+
+	released, err := releaser.ReleaseClaims(ctx, ids)
+	if err != nil && ctx.Err() == nil {
+		// The claims are no longer renewed, so they still expire after the
+		// lease TTL.
+		log.Error("failed to release drained task claims", "node_id", w.drainNodeID, "count", len(ids), "error", err)
+	}
+	metrics.WorkerDrainReleasedTasksTotal.WithLabelValues(w.drainNodeID).Add(float64(released))
+	log.Info("drain deadline passed; handed running tasks back", "node_id", w.drainNodeID, "count", len(ids), "released", released)
+}
+
+// runNodeRegistration is the background goroutine that keeps this node's
+// worker_nodes row fresh.
+func (w *Worker) runNodeRegistration(ctx context.Context) {
+	w.registerNow(ctx)
+
+	ticker := time.NewTicker(w.registerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.registerNow(ctx)
+		}
+	}
+}
+
+func (w *Worker) registerNow(ctx context.Context) {
+	if err := w.registrar.Register(ctx, w.registerNodeID, w.registerLabels); err != nil && ctx.Err() == nil {
+		log.Warn("failed to register node labels", "node_id", w.registerNodeID, "error", err)
+	}
+}
+
 // runRunLeaseRenewal is the background goroutine that extends run_leases rows
 // for every run owned by this node.  It piggybacks on the same leaseTTL/4
 // cadence as the task-claim renewal ticker so the two renewal paths share
@@ -247,7 +247,7 @@ func TestBatchedRenewal_NInflightOneUpdate(t *testing.T) {
 	for i := 0; i < 4; i++ {
 		task := makeTask(nodeID, imminent)
 		ids[task.ID] = struct{}{}
-		w.trackInFlight(task)
+		w.trackInFlight(task, nil, nil)
 	}
 
 	w.renewLeasesNow(t.Context())
@@ -281,7 +281,7 @@ func TestBatchedRenewal_SkipWhenNotNeeded(t *testing.T) {
 	// Tasks expire in 4 minutes — well beyond halfTTL of 2.5 minutes.
 	distant := time.Now().Add(4 * time.Minute)
 	for i := 0; i < 3; i++ {
-		w.trackInFlight(makeTask(nodeID, distant))
+		w.trackInFlight(makeTask(nodeID, distant), nil, nil)
 	}
 
 	w.renewLeasesNow(t.Context())
@@ -308,7 +308,7 @@ func TestBatchedRenewal_OtherNodeNotTouched(t *testing.T) {
 	taskA := makeTask(nodeA, imminent)
 	taskB := makeTask(nodeB, imminent)
 
-	w.trackInFlight(taskA)
+	w.trackInFlight(taskA, nil, nil)
 	// Directly insert a node-b entry into the in-flight map to simulate a
 	// cross-node scenario.
 	w.inFlightMu.Lock()
@@ -347,7 +347,7 @@ func TestBatchedRenewal_ZeroLeaseTTLNoRenewal(t *testing.T) {
 	w := NewWorker(&sequenceClaimer{}, NewPool(1), time.Millisecond, nil).
 		WithLeaseRenewal(renewer, 0, 0)
 
-	w.trackInFlight(makeTask("node-a", time.Now().Add(-time.Hour))) // already expired
+	w.trackInFlight(makeTask("node-a", time.Now().Add(-time.Hour)), nil, nil) // already expired
 	w.renewLeasesNow(t.Context())
 
 	if got := renewer.callCount(); got != 0 {
@@ -369,7 +369,7 @@ func TestBatchedRenewal_ZeroRowsAffectedNoLocalUpdate(t *testing.T) {
 
 	imminent := time.Now().Add(time.Minute)
 	task := makeTask("node-a", imminent)
-	w.trackInFlight(task)
+	w.trackInFlight(task, nil, nil)
 
 	w.renewLeasesNow(t.Context())
 
@@ -508,3 +508,200 @@ func TestWorkerRunDrainsInboundAndClaimNext(t *testing.T) {
 		t.Fatalf("expected 2 executed tasks (1 dispatched + 1 ClaimNext'd), got %d", got)
 	}
 }
+
+type fakeCancellationWatcher struct {
+	cancelled map[uuid.UUID]bool
+}
+
+func (f *fakeCancellationWatcher) CancelledTaskRuns(_ context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	var out []uuid.UUID
+	for _, id := range ids {
+		if f.cancelled[id] {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// TestCancellationWatchCancelsOnlyCancelledTasks verifies that the watch
+// cancels the execution context of in-flight task runs the watcher reports
+// as cancelled and leaves every other in-flight task running.
+func TestCancellationWatchCancelsOnlyCancelledTasks(t *testing.T) {
+	cancelledTask := makeTask("node-a", time.Now().Add(time.Minute))
+	runningTask := makeTask("node-a", time.Now().Add(time.Minute))
+	watcher := &fakeCancellationWatcher{cancelled: map[uuid.UUID]bool{cancelledTask.ID: true}}
+	w := NewWorker(&sequenceClaimer{}, NewPool(2), time.Millisecond, nil).
+		WithCancellationWatch(watcher, time.Second)
+
+	cancelledCtx, cancelCancelled := context.WithCancel(t.Context())
+	defer cancelCancelled()
+	runningCtx, cancelRunning := context.WithCancel(t.Context())
+	defer cancelRunning()
+	w.trackInFlight(cancelledTask, cancelCancelled, nil)
+	w.trackInFlight(runningTask, cancelRunning, nil)
+
+	w.cancelInFlightNow(t.Context())
+
+	if cancelledCtx.Err() == nil {
+		t.Fatal("expected cancelled task context to be cancelled")
+	}
+	if runningCtx.Err() != nil {
+		t.Fatal("expected running task context to stay active")
+	}
+}
+
+// TestWorkerRunStopsCancelledTask verifies the end-to-end path: a claimed
+// task whose run is cancelled sees its executor context cancelled.
+func TestWorkerRunStopsCancelledTask(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	task := &models.TaskRun{ID: uuid.New()}
+	claimer := &sequenceClaimer{responses: []claimerResponse{{task: task}}}
+	watcher := &fakeCancellationWatcher{cancelled: map[uuid.UUID]bool{task.ID: true}}
+
+	var stopped int32
+	w := NewWorker(claimer, NewPool(1), 10*time.Millisecond, func(execCtx context.Context, _ *models.TaskRun) {
+		<-execCtx.Done()
+		if errors.Is(execCtx.Err(), context.Canceled) && ctx.Err() == nil {
+			atomic.StoreInt32(&stopped, 1)
+		}
+		cancel()
+	}).WithCancellationWatch(watcher, 10*time.Millisecond)
+
+	if err := w.Run(ctx); err != nil {
+		t.Fatalf("worker run failed: %v", err)
+	}
+	if atomic.LoadInt32(&stopped) != 1 {
+		t.Fatal("expected the cancellation watch to cancel the in-flight task")
+	}
+}
+
+type fakeNodeStates struct {
+	mu    sync.Mutex
+	state models.NodeState
+}
+
+func (f *fakeNodeStates) Get(context.Context, string) (models.NodeState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state, nil
+}
+
+func (f *fakeNodeStates) set(state models.NodeState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = state
+}
+
+type releasingClaimer struct {
+	sequenceClaimer
+	released []uuid.UUID
+}
+
+func (r *releasingClaimer) ReleaseClaims(_ context.Context, ids []uuid.UUID) (int64, error) {
+	r.released = append(r.released, ids...)
+	return int64(len(ids)), nil
+}
+
+// TestDrainWatchRejectsDispatchWhileCordoned verifies a cordoned node turns
+// dispatched tasks away and accepts them again once uncordoned.
+func TestDrainWatchRejectsDispatchWhileCordoned(t *testing.T) {
+	states := &fakeNodeStates{state: models.NodeState{Address: "node-a", State: "cordoned"}}
+	w := NewWorker(&sequenceClaimer{}, NewPool(1), time.Millisecond, nil).
+		WithInboundDispatch("tok").
+		WithDrainWatch(states, "node-a")
+
+	w.checkDrainNow(t.Context())
+	err := w.SubmitDispatched(dispatch.InboundDispatch{Task: &models.TaskRun{ID: uuid.New()}})
+	if !errors.Is(err, ErrWorkerCordoned) {
+		t.Fatalf("expected ErrWorkerCordoned, got %v", err)
+	}
+
+	states.set(models.NodeState{Address: "node-a", State: "active"})
+	w.checkDrainNow(t.Context())
+	if err := w.SubmitDispatched(dispatch.InboundDispatch{Task: &models.TaskRun{ID: uuid.New()}}); err != nil {
+		t.Fatalf("expected dispatch to be accepted after uncordon, got %v", err)
+	}
+}
+
+// TestDrainWatchReleasesClaimsAfterDeadline verifies a draining node leaves
+// its running tasks alone until the drain deadline, then releases their
+// claims and stops them.
+func TestDrainWatchReleasesClaimsAfterDeadline(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	states := &fakeNodeStates{state: models.NodeState{Address: "node-a", State: "draining", DrainDeadline: &future}}
+	claimer := &releasingClaimer{}
+	w := NewWorker(claimer, NewPool(1), time.Millisecond, nil).
+		WithDrainWatch(states, "node-a")
+
+	task := makeTask("node-a", time.Now().Add(time.Minute))
+	taskCtx, cancelTask := context.WithCancel(t.Context())
+	defer cancelTask()
+	w.trackInFlight(task, cancelTask, nil)
+
+	w.checkDrainNow(t.Context())
+	if len(claimer.released) != 0 || taskCtx.Err() != nil {
+		t.Fatal("expected running task to keep its claim before the drain deadline")
+	}
+
+	past := time.Now().Add(-time.Second)
+	states.set(models.NodeState{Address: "node-a", State: "draining", DrainDeadline: &past})
+	w.checkDrainNow(t.Context())
+	if len(claimer.released) != 1 || claimer.released[0] != task.ID {
+		t.Fatalf("expected task claim %s to be released, got %v", task.ID, claimer.released)
+	}
+	if taskCtx.Err() == nil {
+		t.Fatal("expected released task context to be cancelled")
+	}
+	w.inFlightMu.Lock()
+	remaining := len(w.inFlight)
+	w.inFlightMu.Unlock()
+	if remaining != 0 {
+		t.Fatalf("expected no in-flight tasks after release, got %d", remaining)
+	}
+}
+
+// stopCheckingReleaser records whether the drained execution had returned by
+// the time its claim was released.
+type stopCheckingReleaser struct {
+	sequenceClaimer
+	done         chan struct{}
+	stoppedFirst bool
+}
+
+func (r *stopCheckingReleaser) ReleaseClaims(_ context.Context, ids []uuid.UUID) (int64, error) {
+	select {
+	case <-r.done:
+		r.stoppedFirst = true
+	default:
+	}
+	return int64(len(ids)), nil
+}
+
+// TestDrainWatchReleasesClaimsAfterExecutionStops verifies a drained task's
+// claim is released only once its execution has returned, so another node
+// cannot claim it while it still runs here.
+func TestDrainWatchReleasesClaimsAfterExecutionStops(t *testing.T) {
+	past := time.Now().Add(-time.Second)
+	states := &fakeNodeStates{state: models.NodeState{Address: "node-a", State: "draining", DrainDeadline: &past}}
+	done := make(chan struct{})
+	claimer := &stopCheckingReleaser{done: done}
+	w := NewWorker(claimer, NewPool(1), time.Millisecond, nil).
+		WithDrainWatch(states, "node-a")
+
+	taskCtx, cancelTask := context.WithCancel(t.Context())
+	defer cancelTask()
+	w.trackInFlight(makeTask("node-a", time.Now().Add(time.Minute)), cancelTask, done)
+	go func() {
+		// The execution takes a moment to wind down after it is cancelled.
+		<-taskCtx.Done()
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+
+	w.checkDrainNow(t.Context())
+	if !claimer.stoppedFirst {
+		t.Fatal("expected the claim to be released only after the execution stopped")
+	}
+}
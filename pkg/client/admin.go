@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// ShardReport is the row counts of the databases that can hold hot run rows.
+type ShardReport struct {
+	// Shards is the server's CAESIUM_DATABASE_SHARDS.
+	Shards    int            `json:"shards"`
+	Databases []ShardSummary `json:"databases"`
+	// Runs counts every run in the reported databases.
+	Runs int64 `json:"runs"`
+}
+
+// ShardSummary is one database in a ShardReport.
+type ShardSummary struct {
+	Name string `json:"name"`
+	// Tables is the row count of every hot run table in the database.
+	Tables map[string]int64 `json:"tables"`
+}
+
+// Backup takes an online backup of every database and returns the archive
+// as it streams from the server. The caller must close it.
+func (c *client) Backup(ctx context.Context) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/admin/backup", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.stream(req, "backup")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *client) Shards(ctx context.Context) (*ShardReport, error) {
+	var report ShardReport
+	if err := c.get(ctx, "/v1/admin/shards", nil, "shards", &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
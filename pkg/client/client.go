@@ -1,11 +1,220 @@
+// Package client is a Go client for Caesium's REST API. It declares its own
+// wire types for the responses it decodes, so importing it does not pull in
+// the server's storage and runtime dependencies.
 package client
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultServer is the address a local Caesium server listens on.
+const DefaultServer = "http://localhost:8080"
+
+// DefaultTimeout bounds every request except the streaming ones, which last
+// until the server closes them or the caller cancels their context.
+const DefaultTimeout = 30 * time.Second
+
+// Caesium is the subset of the REST API the CLI operates through.
 type Caesium interface {
+	ListJobs(ctx context.Context, req *ListJobsRequest) ([]*Job, error)
+	// GetJob fetches a job by ID or unambiguous ID prefix.
+	GetJob(ctx context.Context, id string) (*Job, error)
+	// ResolveJob finds a job by alias, ID, or unambiguous ID prefix.
+	ResolveJob(ctx context.Context, ref string) (*Job, error)
+	PauseJob(ctx context.Context, id uuid.UUID) (*Job, error)
+	UnpauseJob(ctx context.Context, id uuid.UUID) (*Job, error)
+	DeleteJob(ctx context.Context, id uuid.UUID) error
+	JobTasks(ctx context.Context, id uuid.UUID) ([]*Task, error)
+	JobDAG(ctx context.Context, id uuid.UUID) (*DAG, error)
+
+	// ListRuns returns a page of a job's runs, oldest first.
+	ListRuns(ctx context.Context, jobID uuid.UUID, req *ListRunsRequest) ([]*Run, error)
+	GetRun(ctx context.Context, jobID, runID uuid.UUID) (*Run, error)
+	RunLogs(ctx context.Context, jobID, runID, taskID uuid.UUID) (*LogStream, error)
+
+	ListTriggers(ctx context.Context, req *ListTriggersRequest) ([]*Trigger, error)
+	FireTrigger(ctx context.Context, id uuid.UUID, req *FireRequest) error
+
+	// Backup streams an online backup archive of every database. The caller
+	// must close it.
+	Backup(ctx context.Context) (io.ReadCloser, error)
+	// Shards reports every hot database's row counts and the progress of a
+	// rebalance after a shard-count change.
+	Shards(ctx context.Context) (*ShardReport, error)
+
+	// Events streams lifecycle events matching filter to fn until ctx is
+	// cancelled, the server closes the stream, or fn returns an error, which
+	// Events then returns.
+	Events(ctx context.Context, filter EventFilter, fn func(Event) error) error
+}
+
+// Option configures a client.
+type Option func(*client)
+
+// WithAPIKey authenticates every request with key as a bearer token.
+func WithAPIKey(key string) Option {
+	return func(c *client) {
+		c.apiKey = strings.TrimSpace(key)
+	}
 }
 
-func Client() Caesium {
-	return &client{}
+// WithHTTPClient replaces the HTTP client used for non-streaming requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.http = httpClient
+	}
+}
+
+// New returns a client for the server at base, e.g. http://localhost:8080.
+func New(base string, opts ...Option) Caesium {
+	c := &client{
+		base: strings.TrimSuffix(strings.TrimSpace(base), "/"),
+		http: &http.Client{Timeout: DefaultTimeout},
+	}
+	if c.base == "" {
+		c.base = DefaultServer
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type client struct {
+	base   string
+	apiKey string
+	http   *http.Client
+}
+
+// Error is a response the server rejected.
+type Error struct {
+	// Op names the failed operation, e.g. "list jobs".
+	Op         string
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s failed (%d): %s", e.Op, e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the server.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// newRequest builds an authenticated request for path, which is relative to
+// the server base and already escaped.
+func (c *client) newRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	reqURL := c.base + path
+	if encoded := query.Encode(); encoded != "" {
+		reqURL += "?" + encoded
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return req, nil
+}
+
+// do sends req and decodes a successful JSON response into out, if non-nil.
+func (c *client) do(req *http.Request, op string, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading %s response: %w", op, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(op, resp.StatusCode, body)
+	}
+	if out == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s response was not valid JSON (status %d): %w", op, resp.StatusCode, err)
+	}
+	return nil
+}
+
+// stream sends req without a client timeout and returns the open response
+// for the caller to read and close.
+func (c *client) stream(req *http.Request, op string) (*http.Response, error) {
+	streaming := *c.http
+	streaming.Timeout = 0
+
+	resp, err := streaming.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, responseError(op, resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+func (c *client) get(ctx context.Context, path string, query url.Values, op string, out any) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, op, out)
+}
+
+func responseError(op string, status int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	var decoded struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		switch {
+		case strings.TrimSpace(decoded.Message) != "":
+			message = strings.TrimSpace(decoded.Message)
+		case strings.TrimSpace(decoded.Error) != "":
+			message = strings.TrimSpace(decoded.Error)
+		}
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &Error{Op: op, StatusCode: status, Message: message}
+}
+
+func escape(id uuid.UUID) string {
+	return url.PathEscape(id.String())
 }
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListJobsSendsFiltersAndAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/jobs", r.URL.Path)
+		require.Equal(t, "5", r.URL.Query().Get("limit"))
+		require.Equal(t, "namespace,alias", r.URL.Query().Get("order_by"))
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`[{"id":"2f0c5f62-46a1-4c5c-9d43-4d0b1c1f7a10","alias":"nightly","namespace":"default","latest_run":{"status":"succeeded"}}]`))
+	}))
+	defer server.Close()
+
+	jobs, err := New(server.URL, WithAPIKey("secret")).ListJobs(context.Background(), &ListJobsRequest{
+		Limit:   5,
+		OrderBy: []string{"namespace", "alias"},
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, "nightly", jobs[0].Alias)
+	require.NotNil(t, jobs[0].LatestRun)
+	require.Equal(t, "succeeded", string(jobs[0].LatestRun.Status))
+}
+
+func TestResolveJobMatchesAliasThenFetchesByID(t *testing.T) {
+	jobID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/jobs":
+			_, _ = w.Write([]byte(`[{"id":"` + uuid.NewString() + `","alias":"nightly","namespace":"other"},{"id":"` + jobID.String() + `","alias":"nightly","namespace":"default"}]`))
+		case "/v1/jobs/" + jobID.String():
+			_, _ = w.Write([]byte(`{"id":"` + jobID.String() + `","alias":"nightly","namespace":"default","trigger":{"alias":"every-night","type":"cron"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	_, err := c.ResolveJob(context.Background(), "nightly")
+	require.ErrorContains(t, err, "several namespaces")
+
+	job, err := c.ResolveJob(context.Background(), "default/nightly")
+	require.NoError(t, err)
+	require.Equal(t, jobID, job.ID)
+	require.NotNil(t, job.Trigger)
+	require.Equal(t, "every-night", job.Trigger.Alias)
+
+	_, err = c.ResolveJob(context.Background(), "missing")
+	require.EqualError(t, err, `job "missing" not found`)
+}
+
+func TestErrorsCarryServerMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).GetRun(context.Background(), uuid.New(), uuid.New())
+	require.EqualError(t, err, "get run failed (404): Not Found")
+	require.True(t, IsNotFound(err))
+}
+
+func TestRunLogsReportsStateWithoutBody(t *testing.T) {
+	taskID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, taskID.String(), r.URL.Query().Get("task_id"))
+		w.Header().Set("X-Caesium-Log-State", "pending")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logs, err := New(server.URL).RunLogs(context.Background(), uuid.New(), uuid.New(), taskID)
+	require.NoError(t, err)
+	require.Nil(t, logs.Body)
+	require.Equal(t, "pending", logs.State)
+}
+
+func TestRunLogsStreamsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Caesium-Log-Source", "persisted")
+		w.Header().Set("X-Caesium-Log-Truncated", "true")
+		_, _ = w.Write([]byte("line one\nline two\n"))
+	}))
+	defer server.Close()
+
+	logs, err := New(server.URL).RunLogs(context.Background(), uuid.New(), uuid.New(), uuid.New())
+	require.NoError(t, err)
+	defer func() { _ = logs.Close() }()
+	require.Equal(t, "persisted", logs.Source)
+	require.True(t, logs.Truncated)
+
+	body, err := io.ReadAll(logs.Body)
+	require.NoError(t, err)
+	require.Equal(t, "line one\nline two\n", string(body))
+}
+
+func TestFireTriggerSendsManualKey(t *testing.T) {
+	triggerID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v1/triggers/"+triggerID.String()+"/fire", r.URL.Path)
+		require.Equal(t, "manual", r.Header.Get("X-Caesium-API-Key"))
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"params":{"date":"2026-01-01"},"priority":"high"}`, string(body))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("null"))
+	}))
+	defer server.Close()
+
+	err := New(server.URL).FireTrigger(context.Background(), triggerID, &FireRequest{
+		Params:    map[string]string{"date": "2026-01-01"},
+		Priority:  "high",
+		ManualKey: "manual",
+	})
+	require.NoError(t, err)
+}
+
+func TestEventsDecodesStreamAndStopsOnCallbackError(t *testing.T) {
+	runID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, runID.String(), r.URL.Query().Get("run_id"))
+		require.Equal(t, "task_started,run_completed", r.URL.Query().Get("types"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, ": ping\n\n")
+		_, _ = io.WriteString(w, "id: 7\nevent: task_started\ndata: {\"sequence\":7,\"type\":\"task_started\"}\n\n")
+		_, _ = io.WriteString(w, "id: 8\nevent: run_completed\ndata: {\"sequence\":8,\"type\":\"run_completed\"}\n\n")
+		_, _ = io.WriteString(w, "id: 9\nevent: run_completed\ndata: {\"sequence\":9,\"type\":\"run_completed\"}\n\n")
+	}))
+	defer server.Close()
+
+	stop := io.EOF
+	var seen []uint64
+	err := New(server.URL).Events(context.Background(), EventFilter{
+		RunID: runID,
+		Types: []string{"task_started", "run_completed"},
+	}, func(evt Event) error {
+		seen = append(seen, evt.Sequence)
+		if evt.Type == "run_completed" {
+			return stop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, []uint64{7, 8}, seen)
+}
+
+// TestClientImportsNoServerPackages keeps the client's wire types its own:
+// importing a server package would drag its storage dependencies into every
+// program that uses the client.
+func TestClientImportsNoServerPackages(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		require.NoError(t, err)
+		for _, spec := range parsed.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			require.NoError(t, err)
+			require.False(t, strings.HasPrefix(path, "github.com/caesium-cloud/caesium/"), "%s imports %s", file, path)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxEventSize bounds one SSE data line; log chunks are the largest events.
+const maxEventSize = 1 << 20
+
+// Event is one lifecycle event from the events stream.
+type Event struct {
+	Sequence uint64 `json:"sequence,omitempty"`
+	// Type names the event, e.g. "run_completed" or "task_failed".
+	Type       string          `json:"type"`
+	JobID      uuid.UUID       `json:"job_id,omitempty"`
+	RunID      uuid.UUID       `json:"run_id,omitempty"`
+	TaskID     uuid.UUID       `json:"task_id,omitempty"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Quarantine bool            `json:"quarantine,omitempty"`
+}
+
+// EventFilter narrows the events stream. Zero values match everything.
+type EventFilter struct {
+	JobID uuid.UUID
+	RunID uuid.UUID
+	Types []string
+}
+
+func (c *client) Events(ctx context.Context, filter EventFilter, fn func(Event) error) error {
+	query := url.Values{}
+	if filter.JobID != uuid.Nil {
+		query.Set("job_id", filter.JobID.String())
+	}
+	if filter.RunID != uuid.Nil {
+		query.Set("run_id", filter.RunID.String())
+	}
+	if len(filter.Types) > 0 {
+		query.Set("types", strings.Join(filter.Types, ","))
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/events", query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.stream(req, "event stream")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	err = readEvents(bufio.NewScanner(resp.Body), fn)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// readEvents decodes a text/event-stream body, skipping comments such as the
+// server's keep-alive pings and any field other than data.
+func readEvents(scanner *bufio.Scanner, fn func(Event) error) error {
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+		if data.Len() == 0 {
+			continue
+		}
+
+		var evt Event
+		if err := json.Unmarshal([]byte(data.String()), &evt); err != nil {
+			return fmt.Errorf("event stream sent invalid JSON: %w", err)
+		}
+		data.Reset()
+		if err := fn(evt); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("reading event stream: %w", err)
+	}
+	return nil
+}
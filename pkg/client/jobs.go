@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a job as the jobs endpoints return it. Trigger is only set by
+// GetJob, and neither Trigger nor LatestRun by PauseJob or UnpauseJob.
+type Job struct {
+	ID                 uuid.UUID      `json:"id"`
+	Namespace          string         `json:"namespace"`
+	Alias              string         `json:"alias"`
+	TriggerID          uuid.UUID      `json:"trigger_id"`
+	Labels             map[string]any `json:"labels"`
+	Annotations        map[string]any `json:"annotations"`
+	ProvenanceSourceID string         `json:"provenance_source_id"`
+	ProvenanceRepo     string         `json:"provenance_repo"`
+	ProvenanceRef      string         `json:"provenance_ref"`
+	ProvenanceCommit   string         `json:"provenance_commit"`
+	ProvenancePath     string         `json:"provenance_path"`
+	MaxParallelTasks   int            `json:"max_parallel_tasks"`
+	TaskTimeout        time.Duration  `json:"task_timeout"`
+	RunTimeout         time.Duration  `json:"run_timeout"`
+	Priority           string         `json:"priority,omitempty"`
+	// Concurrency, RateLimits, SLA, Retention, and CacheConfig are the job
+	// definition's blocks as the server stores them.
+	Concurrency      json.RawMessage `json:"concurrency,omitempty"`
+	RateLimits       json.RawMessage `json:"rate_limits,omitempty"`
+	SLA              json.RawMessage `json:"sla,omitempty"`
+	Retention        json.RawMessage `json:"retention,omitempty"`
+	CacheConfig      json.RawMessage `json:"cache_config,omitempty"`
+	SchemaValidation string          `json:"schema_validation,omitempty"`
+	ReplaySafe       bool            `json:"replay_safe"`
+	RunTemplates     bool            `json:"run_templates"`
+	Paused           bool            `json:"paused"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+
+	Trigger   *Trigger `json:"trigger,omitempty"`
+	LatestRun *Run     `json:"latest_run,omitempty"`
+}
+
+// Task is one step of a job as JobTasks returns it. The server encodes its
+// identifying fields without snake_case names.
+type Task struct {
+	ID           uuid.UUID     `json:"ID"`
+	JobID        uuid.UUID     `json:"JobID"`
+	AtomID       uuid.UUID     `json:"AtomID"`
+	Name         string        `json:"name"`
+	Type         string        `json:"type"`
+	Retries      int           `json:"retries"`
+	RetryDelay   time.Duration `json:"retry_delay"`
+	RetryBackoff bool          `json:"retry_backoff"`
+	TriggerRule  string        `json:"trigger_rule"`
+	ReplaySafe   bool          `json:"replay_safe"`
+	CreatedAt    time.Time     `json:"CreatedAt"`
+	UpdatedAt    time.Time     `json:"UpdatedAt"`
+}
+
+// ListJobsRequest filters and pages ListJobs.
+type ListJobsRequest struct {
+	Limit     uint64
+	Offset    uint64
+	OrderBy   []string
+	TriggerID string
+}
+
+// DAG is a job's task graph.
+type DAG struct {
+	JobID uuid.UUID `json:"job_id"`
+	Nodes []DAGNode `json:"nodes"`
+}
+
+// DAGNode is one task in a DAG and the tasks that run after it.
+type DAGNode struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type,omitempty"`
+	Successors []uuid.UUID `json:"successors"`
+}
+
+func (c *client) ListJobs(ctx context.Context, req *ListJobsRequest) ([]*Job, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.Limit > 0 {
+			query.Set("limit", strconv.FormatUint(req.Limit, 10))
+		}
+		if req.Offset > 0 {
+			query.Set("offset", strconv.FormatUint(req.Offset, 10))
+		}
+		if len(req.OrderBy) > 0 {
+			query.Set("order_by", strings.Join(req.OrderBy, ","))
+		}
+		if req.TriggerID != "" {
+			query.Set("trigger_id", req.TriggerID)
+		}
+	}
+
+	var jobs []*Job
+	if err := c.get(ctx, "/v1/jobs", query, "list jobs", &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (c *client) GetJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.get(ctx, "/v1/jobs/"+url.PathEscape(strings.TrimSpace(id)), nil, "get job", &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ResolveJob matches ref against job aliases first, optionally qualified as
+// namespace/alias, and falls back to treating it as an ID prefix.
+func (c *client) ResolveJob(ctx context.Context, ref string) (*Job, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("job alias or ID is required")
+	}
+	if _, err := uuid.Parse(ref); err == nil {
+		return c.GetJob(ctx, ref)
+	}
+
+	jobs, err := c.ListJobs(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	namespace, alias, qualified := strings.Cut(ref, "/")
+	if !qualified {
+		namespace, alias = "", ref
+	}
+
+	var matches []*Job
+	for _, job := range jobs {
+		if job == nil || job.Alias != alias {
+			continue
+		}
+		if qualified && job.Namespace != namespace {
+			continue
+		}
+		matches = append(matches, job)
+	}
+	if len(matches) == 1 {
+		return c.GetJob(ctx, matches[0].ID.String())
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("job alias %q exists in several namespaces; use namespace/alias", ref)
+	}
+
+	job, err := c.GetJob(ctx, ref)
+	if err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusBadRequest) {
+			return nil, fmt.Errorf("job %q not found", ref)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (c *client) PauseJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.setPaused(ctx, id, "pause")
+}
+
+func (c *client) UnpauseJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return c.setPaused(ctx, id, "unpause")
+}
+
+func (c *client) setPaused(ctx context.Context, id uuid.UUID, action string) (*Job, error) {
+	req, err := c.newRequest(ctx, http.MethodPut, "/v1/jobs/"+escape(id)+"/"+action, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := c.do(req, action+" job", &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *client) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/v1/jobs/"+escape(id), nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, "delete job", nil)
+}
+
+func (c *client) JobTasks(ctx context.Context, id uuid.UUID) ([]*Task, error) {
+	var tasks []*Task
+	if err := c.get(ctx, "/v1/jobs/"+escape(id)+"/tasks", nil, "list job tasks", &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (c *client) JobDAG(ctx context.Context, id uuid.UUID) (*DAG, error) {
+	var dag DAG
+	if err := c.get(ctx, "/v1/jobs/"+escape(id)+"/dag", nil, "get job DAG", &dag); err != nil {
+		return nil, err
+	}
+	return &dag, nil
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Run statuses; a run is finished once its status is no longer RunRunning.
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunCancelled = "cancelled"
+)
+
+// Run is a job run as the runs endpoints return it.
+type Run struct {
+	ID           uuid.UUID         `json:"id"`
+	JobID        uuid.UUID         `json:"job_id"`
+	JobAlias     string            `json:"job_alias,omitempty"`
+	JobLabels    map[string]string `json:"job_labels,omitempty"`
+	Namespace    string            `json:"namespace,omitempty"`
+	BackfillID   *uuid.UUID        `json:"backfill_id,omitempty"`
+	TriggerType  string            `json:"trigger_type,omitempty"`
+	TriggerAlias string            `json:"trigger_alias,omitempty"`
+	Status       string            `json:"status"`
+	Priority     int               `json:"priority"`
+	Params       map[string]string `json:"params,omitempty"`
+	Quarantine   bool              `json:"quarantine"`
+	StartedAt    time.Time         `json:"started_at"`
+	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Error        string            `json:"error,omitempty"`
+	Tasks        []*TaskRun        `json:"tasks"`
+	// Callbacks are the run's callback executions as the server reports
+	// them.
+	Callbacks     json.RawMessage `json:"callbacks,omitempty"`
+	CacheHits     int             `json:"cache_hits"`
+	ExecutedTasks int             `json:"executed_tasks"`
+	TotalTasks    int             `json:"total_tasks"`
+}
+
+// TaskRun is one task of a Run.
+type TaskRun struct {
+	ID           uuid.UUID         `json:"id"`
+	JobRunID     uuid.UUID         `json:"job_run_id"`
+	TaskID       uuid.UUID         `json:"task_id"`
+	AtomID       uuid.UUID         `json:"atom_id"`
+	Engine       string            `json:"engine"`
+	Image        string            `json:"image"`
+	Command      []string          `json:"command"`
+	RuntimeID    string            `json:"runtime_id,omitempty"`
+	Status       string            `json:"status"`
+	Priority     int               `json:"priority"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	ClaimedBy    string            `json:"claimed_by,omitempty"`
+	Attempt      int               `json:"attempt"`
+	MaxAttempts  int               `json:"max_attempts"`
+	Result       string            `json:"result,omitempty"`
+	Output       map[string]string `json:"output,omitempty"`
+	Quarantine   bool              `json:"quarantine"`
+	CacheHit     bool              `json:"cache_hit"`
+	StartedAt    *time.Time        `json:"started_at,omitempty"`
+	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	TraceID      string            `json:"trace_id,omitempty"`
+	// Instances and Gate are a mapped task's per-item instances and a gated
+	// task's approval request, as the server reports them.
+	Instances json.RawMessage `json:"instances,omitempty"`
+	Gate      json.RawMessage `json:"gate,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// LogStream is a task's log output. Body is nil when the server has no log
+// text to send, in which case State says why: "pending" before the task
+// starts, "empty" when it finished without output, or "unavailable".
+type LogStream struct {
+	Body io.ReadCloser
+	// Source is "live" while the server follows the task's container,
+	// "archive" when it serves the task's archived log, and "persisted" once
+	// it replays the snapshot stored at completion.
+	Source    string
+	State     string
+	Truncated bool
+}
+
+// Close releases the stream's body.
+func (l *LogStream) Close() error {
+	if l == nil || l.Body == nil {
+		return nil
+	}
+	return l.Body.Close()
+}
+
+// ListRunsRequest pages ListRuns. Pages count back from the newest run:
+// Offset skips the most recent runs and Limit caps how many older runs
+// follow. Zero values leave the server's defaults, the newest 100 runs.
+type ListRunsRequest struct {
+	Limit  int
+	Offset int
+}
+
+func (c *client) ListRuns(ctx context.Context, jobID uuid.UUID, req *ListRunsRequest) ([]*Run, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.Limit > 0 {
+			query.Set("limit", strconv.Itoa(req.Limit))
+		}
+		if req.Offset > 0 {
+			query.Set("offset", strconv.Itoa(req.Offset))
+		}
+	}
+
+	var runs []*Run
+	if err := c.get(ctx, "/v1/jobs/"+escape(jobID)+"/runs", query, "list runs", &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (c *client) GetRun(ctx context.Context, jobID, runID uuid.UUID) (*Run, error) {
+	var run Run
+	if err := c.get(ctx, "/v1/jobs/"+escape(jobID)+"/runs/"+escape(runID), nil, "get run", &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// RunLogs opens a task's log. A live log stays open for as long as the task
+// writes to it, so callers that only want what is there now should cancel
+// ctx or close the stream once they have read enough.
+func (c *client) RunLogs(ctx context.Context, jobID, runID, taskID uuid.UUID) (*LogStream, error) {
+	query := url.Values{}
+	query.Set("task_id", taskID.String())
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/jobs/"+escape(jobID)+"/runs/"+escape(runID)+"/logs", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.stream(req, "run logs")
+	if err != nil {
+		return nil, err
+	}
+
+	logs := &LogStream{
+		Source:    resp.Header.Get("X-Caesium-Log-Source"),
+		State:     resp.Header.Get("X-Caesium-Log-State"),
+		Truncated: resp.Header.Get("X-Caesium-Log-Truncated") == "true",
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		_ = resp.Body.Close()
+		return logs, nil
+	}
+	logs.Body = resp.Body
+	return logs, nil
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TriggerTypeHTTP is the type of triggers FireTrigger can fire.
+const TriggerTypeHTTP = "http"
+
+// Trigger is a trigger as the triggers and jobs endpoints return it.
+type Trigger struct {
+	ID        uuid.UUID `json:"id"`
+	Namespace string    `json:"namespace"`
+	Alias     string    `json:"alias"`
+	// Type is cron, http, event, or freshness.
+	Type               string    `json:"type"`
+	Configuration      string    `json:"configuration"`
+	ProvenanceSourceID string    `json:"provenance_source_id"`
+	ProvenanceRepo     string    `json:"provenance_repo"`
+	ProvenanceRef      string    `json:"provenance_ref"`
+	ProvenanceCommit   string    `json:"provenance_commit"`
+	ProvenancePath     string    `json:"provenance_path"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ListTriggersRequest filters and pages ListTriggers.
+type ListTriggersRequest struct {
+	Limit   uint64
+	Offset  uint64
+	OrderBy []string
+	Type    string
+}
+
+// FireRequest starts a run through an HTTP trigger.
+type FireRequest struct {
+	Params   map[string]string `json:"params,omitempty"`
+	Priority string            `json:"priority,omitempty"`
+	// ManualKey is the server's CAESIUM_MANUAL_TRIGGER_API_KEY, which the
+	// fire endpoint requires on top of regular authentication.
+	ManualKey string `json:"-"`
+}
+
+func (c *client) ListTriggers(ctx context.Context, req *ListTriggersRequest) ([]*Trigger, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.Limit > 0 {
+			query.Set("limit", strconv.FormatUint(req.Limit, 10))
+		}
+		if req.Offset > 0 {
+			query.Set("offset", strconv.FormatUint(req.Offset, 10))
+		}
+		if len(req.OrderBy) > 0 {
+			query.Set("order_by", strings.Join(req.OrderBy, ","))
+		}
+		if req.Type != "" {
+			query.Set("type", req.Type)
+		}
+	}
+
+	var triggers []*Trigger
+	if err := c.get(ctx, "/v1/triggers", query, "list triggers", &triggers); err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
+
+func (c *client) FireTrigger(ctx context.Context, id uuid.UUID, req *FireRequest) error {
+	if req == nil {
+		req = &FireRequest{}
+	}
+	httpReq, err := c.newRequest(ctx, http.MethodPost, "/v1/triggers/"+escape(id)+"/fire", nil, req)
+	if err != nil {
+		return err
+	}
+	if key := strings.TrimSpace(req.ManualKey); key != "" {
+		httpReq.Header.Set("X-Caesium-API-Key", key)
+	}
+	return c.do(httpReq, "fire trigger", nil)
+}
@@ -0,0 +1,164 @@
+package container
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GPUResourceName is the Kubernetes extended resource requested for
+// Resources.GPUCount.
+const GPUResourceName = "nvidia.com/gpu"
+
+// Resources declares the compute a step's container may use. Quantities use
+// Kubernetes notation (cpu "500m" or "2", memory "512Mi" or "1G") so a single
+// manifest maps natively onto every engine: Kubernetes applies them as pod
+// requests and limits, Docker and Podman as container limits.
+//
+// Like KubernetesSpec.QueueName, resources are scheduling metadata rather than
+// execution inputs and are deliberately EXCLUDED from the cache identity hash
+// (see internal/cache/hash.go): resizing a step must not invalidate its cache.
+type Resources struct {
+	CPU              string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory           string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	EphemeralStorage string `json:"ephemeralStorage,omitempty" yaml:"ephemeralStorage,omitempty"`
+	GPUCount         int    `json:"gpu-count,omitempty" yaml:"gpu-count,omitempty"`
+}
+
+// IsZero reports whether no resource is declared.
+func (r *Resources) IsZero() bool {
+	return r == nil ||
+		(strings.TrimSpace(r.CPU) == "" &&
+			strings.TrimSpace(r.Memory) == "" &&
+			strings.TrimSpace(r.EphemeralStorage) == "" &&
+			r.GPUCount == 0)
+}
+
+// Validate checks that every declared quantity parses and is positive.
+func (r *Resources) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.CPU != "" {
+		if _, err := ParseCPUMillis(r.CPU); err != nil {
+			return fmt.Errorf("cpu: %w", err)
+		}
+	}
+	if r.Memory != "" {
+		if _, err := ParseBytes(r.Memory); err != nil {
+			return fmt.Errorf("memory: %w", err)
+		}
+	}
+	if r.EphemeralStorage != "" {
+		if _, err := ParseBytes(r.EphemeralStorage); err != nil {
+			return fmt.Errorf("ephemeralStorage: %w", err)
+		}
+	}
+	if r.GPUCount < 0 {
+		return fmt.Errorf("gpu-count: must be >= 0, got %d", r.GPUCount)
+	}
+	return nil
+}
+
+// Merge overlays override onto r field by field and returns the result.
+// Either side may be nil; the result is nil when both are empty.
+func (r *Resources) Merge(override *Resources) *Resources {
+	if r.IsZero() && override.IsZero() {
+		return nil
+	}
+	merged := Resources{}
+	if r != nil {
+		merged = *r
+	}
+	if override != nil {
+		if override.CPU != "" {
+			merged.CPU = override.CPU
+		}
+		if override.Memory != "" {
+			merged.Memory = override.Memory
+		}
+		if override.EphemeralStorage != "" {
+			merged.EphemeralStorage = override.EphemeralStorage
+		}
+		if override.GPUCount != 0 {
+			merged.GPUCount = override.GPUCount
+		}
+	}
+	return &merged
+}
+
+// ParseCPUMillis converts a CPU quantity ("250m", "0.5", "2") to millicores.
+func ParseCPUMillis(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return 0, fmt.Errorf("invalid cpu quantity %q", value)
+	}
+
+	var millis float64
+	if raw, ok := strings.CutSuffix(trimmed, "m"); ok {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cpu quantity %q: %w", value, err)
+		}
+		millis = float64(parsed)
+	} else {
+		parsed, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cpu quantity %q: %w", value, err)
+		}
+		millis = parsed * 1000
+	}
+
+	if math.IsNaN(millis) || math.IsInf(millis, 0) || millis < 1 {
+		return 0, fmt.Errorf("invalid cpu quantity %q: must be at least 1m", value)
+	}
+	return int64(math.Round(millis)), nil
+}
+
+// ParseBytes converts a byte quantity ("512Mi", "1.5Gi", "1G", "1048576") to
+// bytes. Binary (Ki, Mi, Gi, Ti, Pi, Ei) and decimal (k, M, G, T, P, E)
+// suffixes follow Kubernetes quantity semantics.
+func ParseBytes(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return 0, fmt.Errorf("invalid byte quantity %q", value)
+	}
+
+	type suffixMultiplier struct {
+		suffix     string
+		multiplier float64
+	}
+	multipliers := []suffixMultiplier{
+		{suffix: "Ki", multiplier: 1 << 10},
+		{suffix: "Mi", multiplier: 1 << 20},
+		{suffix: "Gi", multiplier: 1 << 30},
+		{suffix: "Ti", multiplier: 1 << 40},
+		{suffix: "Pi", multiplier: 1 << 50},
+		{suffix: "Ei", multiplier: 1 << 60},
+		{suffix: "k", multiplier: 1e3},
+		{suffix: "M", multiplier: 1e6},
+		{suffix: "G", multiplier: 1e9},
+		{suffix: "T", multiplier: 1e12},
+		{suffix: "P", multiplier: 1e15},
+		{suffix: "E", multiplier: 1e18},
+	}
+
+	raw, multiplier := trimmed, float64(1)
+	for _, candidate := range multipliers {
+		if stripped, ok := strings.CutSuffix(trimmed, candidate.suffix); ok {
+			raw, multiplier = stripped, candidate.multiplier
+			break
+		}
+	}
+
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte quantity %q: %w", value, err)
+	}
+	total := parsed * multiplier
+	if math.IsNaN(total) || total < 1 || total > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte quantity %q: must be a positive size", value)
+	}
+	return int64(math.Ceil(total)), nil
+}
@@ -0,0 +1,69 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPUMillis(t *testing.T) {
+	cases := map[string]int64{
+		"250m": 250,
+		"0.5":  500,
+		"2":    2000,
+		" 1 ":  1000,
+		"1.25": 1250,
+	}
+	for input, want := range cases {
+		got, err := ParseCPUMillis(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "0", "-1", "abc", "0.0001", "1.5m"} {
+		_, err := ParseCPUMillis(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	cases := map[string]int64{
+		"512Mi":   512 << 20,
+		"1.5Gi":   3 << 29,
+		"1G":      1_000_000_000,
+		"100k":    100_000,
+		"1048576": 1 << 20,
+		"2Ti":     2 << 40,
+	}
+	for input, want := range cases {
+		got, err := ParseBytes(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "0", "-1Mi", "Mi", "1Zi", "ten"} {
+		_, err := ParseBytes(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestResourcesValidate(t *testing.T) {
+	require.NoError(t, (*Resources)(nil).Validate())
+	require.NoError(t, (&Resources{CPU: "500m", Memory: "1Gi", EphemeralStorage: "10Gi", GPUCount: 1}).Validate())
+	require.ErrorContains(t, (&Resources{CPU: "lots"}).Validate(), "cpu")
+	require.ErrorContains(t, (&Resources{Memory: "1Zi"}).Validate(), "memory")
+	require.ErrorContains(t, (&Resources{EphemeralStorage: "-1"}).Validate(), "ephemeralStorage")
+	require.ErrorContains(t, (&Resources{GPUCount: -1}).Validate(), "gpu-count")
+}
+
+func TestResourcesMerge(t *testing.T) {
+	var base *Resources
+	require.Nil(t, base.Merge(nil))
+
+	defaults := &Resources{CPU: "1", Memory: "1Gi"}
+	merged := defaults.Merge(&Resources{Memory: "4Gi", GPUCount: 2})
+	require.Equal(t, &Resources{CPU: "1", Memory: "4Gi", GPUCount: 2}, merged)
+	require.Equal(t, "1Gi", defaults.Memory, "merge must not mutate the defaults")
+
+	require.Equal(t, &Resources{CPU: "250m"}, base.Merge(&Resources{CPU: "250m"}))
+}
@@ -102,6 +102,7 @@ type Spec struct {
 	Mounts               []Mount           `json:"mounts,omitempty" yaml:"mounts,omitempty"`
 	ResolvedVolumeMounts []VolumeMount     `json:"resolvedVolumeMounts,omitempty" yaml:"-"`
 	Kubernetes           *KubernetesSpec   `json:"kubernetes,omitempty" yaml:"-"`
+	Resources            *Resources        `json:"resources,omitempty" yaml:"resources,omitempty"`
 }
 
 // HasEnv reports whether any environment variables are defined.
@@ -113,3 +114,8 @@ func (s Spec) HasEnv() bool {
 func (s Spec) HasMounts() bool {
 	return len(s.Mounts) > 0 || len(s.ResolvedVolumeMounts) > 0
 }
+
+// HasResources reports whether any resource request or limit is defined.
+func (s Spec) HasResources() bool {
+	return !s.Resources.IsZero()
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -10,6 +11,7 @@ import (
 	"github.com/caesium-cloud/caesium/internal/models"
 	"github.com/caesium-cloud/caesium/pkg/dqlite"
 	"github.com/caesium-cloud/caesium/pkg/env"
+	jobdefschema "github.com/caesium-cloud/caesium/pkg/jobdef"
 	"github.com/caesium-cloud/caesium/pkg/log"
 	_ "github.com/jackc/pgx/v4"
 	"go.uber.org/zap/zapcore"
@@ -18,6 +20,11 @@ import (
 	gormlogger "gorm.io/gorm/logger"
 )
 
+// SchemaVersion is the version of the schema Migrate produces. Bump it with
+// every change to the migrated tables or columns; backups record it so a
+// restore knows whether their data predates this build's migrations.
+const SchemaVersion = 3
+
 const (
 	defaultMaxOpenConns = 4
 	defaultMaxIdleConns = 2
@@ -60,6 +67,34 @@ func DefaultRouter() *Router {
 	return defaultRouter
 }
 
+// NamedDatabase is one database the default router opened.
+type NamedDatabase struct {
+	Name string
+	Role DatabaseRole
+	Conn *gorm.DB
+}
+
+// Databases lists the distinct databases behind the default router: the
+// catalog, then each hot shard, then cold history. Routes that alias the
+// catalog are not repeated.
+func Databases() []NamedDatabase {
+	router := DefaultRouter()
+	databases := []NamedDatabase{{Name: catalogDatabaseName, Role: DatabaseRoleCatalog, Conn: router.Catalog()}}
+	if router.ShardCount() > 1 {
+		for idx, shard := range router.HotShards() {
+			databases = append(databases, NamedDatabase{
+				Name: fmt.Sprintf(hotShardNameFormat, idx),
+				Role: DatabaseRoleHot,
+				Conn: shard,
+			})
+		}
+	}
+	if router.Cold() != router.Catalog() {
+		databases = append(databases, NamedDatabase{Name: historyDatabaseName, Role: DatabaseRoleCold, Conn: router.Cold()})
+	}
+	return databases
+}
+
 func openRouterFromEnv() (*Router, error) {
 	vars := env.Variables()
 	dbType := strings.ToLower(strings.TrimSpace(vars.DatabaseType))
@@ -89,8 +124,10 @@ func openRouterFromEnv() (*Router, error) {
 		}
 	}
 
+	// Run archival needs somewhere to move runs to, so it opens the history
+	// database even when the hot route still aliases the catalog.
 	cold := catalog
-	if shardCount > 1 {
+	if shardCount > 1 || (vars.RunArchiveEnabled && isInternalDqlite(dbType)) {
 		cold, err = openConnection(historyDatabaseName, false)
 		if err != nil {
 			return nil, err
@@ -206,6 +243,12 @@ func installDqliteReadWriteSplit(conn *gorm.DB, databaseName string, readMaxOpen
 	return nil
 }
 
+// InternalDqlite reports whether the process is configured for the built-in
+// dqlite database rather than PostgreSQL.
+func InternalDqlite() bool {
+	return isInternalDqlite(env.Variables().DatabaseType)
+}
+
 func isInternalDqlite(dbType string) bool {
 	switch strings.ToLower(strings.TrimSpace(dbType)) {
 	case "", "internal", dqlite.DriverName:
@@ -233,6 +276,9 @@ func configureConnectionPool(sqlDB *sql.DB, maxOpen, maxIdle int) {
 	sqlDB.SetMaxIdleConns(maxIdle)
 }
 
+// legacyJobAliasIndex is the pre-namespace unique index on jobs.alias.
+const legacyJobAliasIndex = "idx_jobs_alias"
+
 func Migrate() (err error) {
 	router := DefaultRouter()
 	if err = migrateModels(router.Catalog(), models.All...); err != nil {
@@ -244,11 +290,21 @@ func Migrate() (err error) {
 				return err
 			}
 		}
+	}
+	if router.Cold() != router.Catalog() {
 		if err = migrateModels(router.Cold(), hotPathModels()...); err != nil {
 			return err
 		}
 	}
 
+	// Job aliases were unique across the cluster before namespaces; they are
+	// now unique within a namespace (idx_jobs_namespace_alias).
+	if migrator := router.Catalog().Migrator(); migrator.HasIndex(&models.Job{}, legacyJobAliasIndex) {
+		if err = migrator.DropIndex(&models.Job{}, legacyJobAliasIndex); err != nil {
+			return err
+		}
+	}
+
 	var triggers []models.Trigger
 	if err = router.Catalog().Where("type = ?", models.TriggerTypeHTTP).Find(&triggers).Error; err != nil {
 		return err
@@ -266,9 +322,51 @@ func Migrate() (err error) {
 			return err
 		}
 	}
+
+	// job_runs.logical_date indexes the logical_date param; runs started
+	// before the column existed carry it only in params.
+	migrated := make(map[*gorm.DB]bool)
+	conns := append([]*gorm.DB{router.Catalog(), router.Cold()}, router.HotShards()...)
+	for _, conn := range conns {
+		if migrated[conn] {
+			continue
+		}
+		migrated[conn] = true
+		if err = backfillRunLogicalDates(conn); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// backfillRunLogicalDates copies the logical_date param of existing job runs
+// into their logical_date column, a batch at a time.
+func backfillRunLogicalDates(conn *gorm.DB) error {
+	const batchSize = 500
+	var rows []models.JobRun
+	return conn.Model(&models.JobRun{}).
+		Select("id", "params").
+		Where("logical_date = '' AND quarantine IS NOT TRUE AND params LIKE ?", "%"+jobdefschema.ParamLogicalDate+"%").
+		FindInBatches(&rows, batchSize, func(tx *gorm.DB, _ int) error {
+			for _, row := range rows {
+				var params map[string]string
+				if err := json.Unmarshal(row.Params, &params); err != nil {
+					continue
+				}
+				logicalDate := params[jobdefschema.ParamLogicalDate]
+				if logicalDate == "" {
+					continue
+				}
+				if err := conn.Model(&models.JobRun{}).
+					Where("id = ?", row.ID).
+					UpdateColumn("logical_date", logicalDate).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
 func migrateModels(conn *gorm.DB, models ...interface{}) error {
 	for _, model := range models {
 		if err := conn.AutoMigrate(model); err != nil {
@@ -282,6 +380,9 @@ func hotPathModels() []interface{} {
 	return []interface{}{
 		&models.JobRun{},
 		&models.TaskRun{},
+		&models.TaskRunInstance{},
+		&models.TaskApproval{},
+		&models.SensorPoke{},
 		&models.CallbackRun{},
 		&models.ExecutionEvent{},
 		// run_checkpoints is per-run and transactionally local to task_runs, so
@@ -21,10 +21,13 @@ const (
 )
 
 var hotTables = map[string]struct{}{
-	"job_runs":         {},
-	"task_runs":        {},
-	"callback_runs":    {},
-	"execution_events": {},
+	"job_runs":           {},
+	"task_runs":          {},
+	"task_run_instances": {},
+	"task_approvals":     {},
+	"sensor_pokes":       {},
+	"callback_runs":      {},
+	"execution_events":   {},
 }
 
 // Router owns the catalog, hot-shard, and cold-history database handles.
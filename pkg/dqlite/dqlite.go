@@ -269,6 +269,27 @@ func IsLocalLeader(ctx context.Context) (bool, error) {
 	return leader != nil && leader.Address == dqApp.Address(), nil
 }
 
+// File is one file of a database dump.
+type File = client.File
+
+// Dump returns a consistent copy of the named database, taken on the leader
+// while the cluster keeps serving: the main database file followed by its
+// write-ahead log, named after the database with a "-wal" suffix.
+func Dump(ctx context.Context, name string) ([]File, error) {
+	dqApp := currentApp.Load()
+	if dqApp == nil {
+		return nil, ErrNoNativeApp
+	}
+
+	cli, err := dqApp.FindLeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cli.Close() }()
+
+	return cli.Dump(ctx, name)
+}
+
 func (dialector Dialector) ClauseBuilders() map[string]clause.ClauseBuilder {
 	return map[string]clause.ClauseBuilder{
 		"INSERT": func(c clause.Clause, builder clause.Builder) {
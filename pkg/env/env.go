@@ -2,6 +2,7 @@ package env
 
 import (
 	"fmt"
+	"net/url"
 	"runtime"
 	"strings"
 	"time"
@@ -40,6 +41,54 @@ func validate() error {
 	if variables.DatabaseShards > 1 && dbType != "" && dbType != "internal" && dbType != "dqlite" {
 		return fmt.Errorf("CAESIUM_DATABASE_SHARDS greater than 1 requires CAESIUM_DATABASE_TYPE=internal")
 	}
+	if variables.RunArchiveEnabled && variables.RunArchiveAfter <= 0 {
+		return fmt.Errorf("CAESIUM_RUN_ARCHIVE_AFTER must be greater than 0 when CAESIUM_RUN_ARCHIVE_ENABLED=true")
+	}
+	if variables.RunRetentionKeepRuns < 0 || variables.RunRetentionKeepDays < 0 || variables.RunRetentionKeepFailedDays < 0 {
+		return fmt.Errorf("CAESIUM_RUN_RETENTION_KEEP_RUNS, CAESIUM_RUN_RETENTION_KEEP_DAYS, and CAESIUM_RUN_RETENTION_KEEP_FAILED_DAYS must be greater than or equal to 0")
+	}
+	if variables.BackupInterval > 0 && strings.TrimSpace(variables.BackupDir) == "" {
+		return fmt.Errorf("CAESIUM_BACKUP_DIR is required when CAESIUM_BACKUP_INTERVAL is set")
+	}
+	if variables.BackupKeep < 0 {
+		return fmt.Errorf("CAESIUM_BACKUP_KEEP must be greater than or equal to 0")
+	}
+	switch strings.ToLower(strings.TrimSpace(variables.LogArchiveBackend)) {
+	case "":
+	case "filesystem":
+		if strings.TrimSpace(variables.LogArchiveDir) == "" {
+			return fmt.Errorf("CAESIUM_LOG_ARCHIVE_DIR is required when CAESIUM_LOG_ARCHIVE_BACKEND=filesystem")
+		}
+	case "s3":
+		if strings.TrimSpace(variables.LogArchiveS3Endpoint) == "" || strings.TrimSpace(variables.LogArchiveS3Bucket) == "" {
+			return fmt.Errorf("CAESIUM_LOG_ARCHIVE_S3_ENDPOINT and CAESIUM_LOG_ARCHIVE_S3_BUCKET are required when CAESIUM_LOG_ARCHIVE_BACKEND=s3")
+		}
+		if variables.LogArchiveS3AccessKeyID == "" || variables.LogArchiveS3SecretAccessKey == "" {
+			return fmt.Errorf("CAESIUM_LOG_ARCHIVE_S3_ACCESS_KEY_ID and CAESIUM_LOG_ARCHIVE_S3_SECRET_ACCESS_KEY are required when CAESIUM_LOG_ARCHIVE_BACKEND=s3")
+		}
+	default:
+		return fmt.Errorf("CAESIUM_LOG_ARCHIVE_BACKEND must be one of: \"\", filesystem, s3")
+	}
+	if variables.LogArchiveChunkSize.Int64() < 0 {
+		return fmt.Errorf("CAESIUM_LOG_ARCHIVE_CHUNK_SIZE must be greater than or equal to 0")
+	}
+	if variables.TracingSampleRatio < 0 || variables.TracingSampleRatio > 1 {
+		return fmt.Errorf("CAESIUM_TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if endpoint := strings.TrimSpace(variables.TracingOTLPEndpoint); endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("CAESIUM_TRACING_OTLP_ENDPOINT must be an http(s) URL such as http://otel-collector:4318")
+		}
+	}
+	imagePolicyMode := strings.ToLower(strings.TrimSpace(variables.ImagePolicyMode))
+	switch imagePolicyMode {
+	case "", "warn", "enforce":
+	default:
+		return fmt.Errorf("CAESIUM_IMAGE_POLICY_MODE must be one of: \"\", warn, enforce")
+	}
+	if imagePolicyMode != "" && strings.TrimSpace(variables.ImagePolicySignedRepos) != "" && strings.TrimSpace(variables.ImagePolicySignatureKeys) == "" {
+		return fmt.Errorf("CAESIUM_IMAGE_POLICY_SIGNATURE_KEYS is required when CAESIUM_IMAGE_POLICY_SIGNED_REPOS is set")
+	}
 	if dbType == "" || dbType == "internal" || dbType == "dqlite" {
 		if variables.DatabaseVoters < 3 || variables.DatabaseVoters%2 == 0 {
 			return fmt.Errorf("CAESIUM_DATABASE_VOTERS must be an odd number greater than or equal to 3")
@@ -124,6 +173,22 @@ type Environment struct {
 	WorkerLeaseTTL                 time.Duration `default:"5m" split_words:"true"`
 	WorkerLeaseRenewInterval       time.Duration `default:"0" split_words:"true"`
 	WorkerPoolSize                 int           `default:"4" split_words:"true"`
+	RunCancelCheckInterval         time.Duration `default:"5s" split_words:"true"`
+	GatePollInterval               time.Duration `default:"5s" split_words:"true"`
+	GateSweepInterval              time.Duration `default:"15s" split_words:"true"`
+	PoolPollInterval               time.Duration `default:"2s" split_words:"true"`
+	PoolMetricsInterval            time.Duration `default:"15s" split_words:"true"`
+	BackfillReconcileInterval      time.Duration `default:"1s" split_words:"true"`
+	RunArchiveEnabled              bool          `default:"false" split_words:"true"`
+	RunArchiveAfter                time.Duration `default:"24h" split_words:"true"`
+	RunArchiveInterval             time.Duration `default:"5m" split_words:"true"`
+	RunArchiveBatchSize            int           `default:"100" split_words:"true"`
+	RunRetentionKeepRuns           int           `default:"0" split_words:"true"`
+	RunRetentionKeepDays           int           `default:"0" split_words:"true"`
+	RunRetentionKeepFailedDays     int           `default:"0" split_words:"true"`
+	BackupInterval                 time.Duration `default:"0" split_words:"true"`
+	BackupDir                      string        `default:"" split_words:"true"`
+	BackupKeep                     int           `default:"7" split_words:"true"`
 	ShutdownGracePeriod            time.Duration `envconfig:"SHUTDOWN_GRACE_PERIOD" default:"30s"`
 	InternalWakeupToken            string        `default:"" split_words:"true"`
 	WakeupFanoutMode               string        `default:"full" split_words:"true"`
@@ -192,8 +257,56 @@ type Environment struct {
 	ContractEnforcement            string        `envconfig:"CONTRACT_ENFORCEMENT" default:""`
 	ContractDeprecationWindow      time.Duration `envconfig:"CONTRACT_DEPRECATION_WINDOW" default:"336h"`
 
+	// NamespaceQuotas bounds the concurrent runs and tasks of each listed
+	// namespace; run admission enforces it alongside job concurrency.
+	NamespaceQuotas NamespaceQuotas `envconfig:"NAMESPACE_QUOTAS"`
+
+	// Task log archive. LogArchiveBackend selects where complete task logs
+	// are streamed: "" (disabled), "filesystem" under LogArchiveDir, or "s3"
+	// for any S3-compatible object store.
+	LogArchiveBackend           string   `envconfig:"LOG_ARCHIVE_BACKEND" default:""`
+	LogArchiveDir               string   `envconfig:"LOG_ARCHIVE_DIR" default:""`
+	LogArchiveChunkSize         ByteSize `envconfig:"LOG_ARCHIVE_CHUNK_SIZE" default:"8MiB"`
+	LogArchiveS3Endpoint        string   `envconfig:"LOG_ARCHIVE_S3_ENDPOINT" default:""`
+	LogArchiveS3Bucket          string   `envconfig:"LOG_ARCHIVE_S3_BUCKET" default:""`
+	LogArchiveS3Region          string   `envconfig:"LOG_ARCHIVE_S3_REGION" default:"us-east-1"`
+	LogArchiveS3Prefix          string   `envconfig:"LOG_ARCHIVE_S3_PREFIX" default:""`
+	LogArchiveS3AccessKeyID     string   `envconfig:"LOG_ARCHIVE_S3_ACCESS_KEY_ID" default:""`
+	LogArchiveS3SecretAccessKey string   `envconfig:"LOG_ARCHIVE_S3_SECRET_ACCESS_KEY" default:""`
+
+	// Tracing. TracingEnabled exports OpenTelemetry traces over OTLP/HTTP to
+	// TracingOTLPEndpoint; an empty endpoint defers to the standard
+	// OTEL_EXPORTER_OTLP_* variables.
+	TracingEnabled      bool    `envconfig:"TRACING_ENABLED" default:"false"`
+	TracingOTLPEndpoint string  `envconfig:"TRACING_OTLP_ENDPOINT" default:""`
+	TracingOTLPHeaders  string  `envconfig:"TRACING_OTLP_HEADERS" default:""`
+	TracingSampleRatio  float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+	TracingServiceName  string  `envconfig:"TRACING_SERVICE_NAME" default:"caesium"`
+
+	// Receipt signing. ReceiptSigningKeys is a comma-separated id=key list of
+	// ed25519 private keys (base64: or hex: seeds); ReceiptSigningKeyID picks
+	// the key that signs new receipts. ReceiptVerifyKeys lists the public keys
+	// of retired signing keys so their receipts still verify.
+	ReceiptSigningKeys  string `envconfig:"RECEIPT_SIGNING_KEYS" default:""`
+	ReceiptSigningKeyID string `envconfig:"RECEIPT_SIGNING_KEY_ID" default:""`
+	ReceiptVerifyKeys   string `envconfig:"RECEIPT_VERIFY_KEYS" default:""`
+	ReceiptBuilderID    string `envconfig:"RECEIPT_BUILDER_ID" default:"https://github.com/caesium-cloud/caesium"`
+
+	// Image admission policy. ImagePolicyMode is "" (disabled), "warn", or
+	// "enforce". List values are comma-separated: repository globs for
+	// ImagePolicyAllowedRepos and ImagePolicySignedRepos, key=value job labels
+	// for ImagePolicyRequireDigestLabels, and PEM public key files for
+	// ImagePolicySignatureKeys.
+	ImagePolicyMode                string `envconfig:"IMAGE_POLICY_MODE" default:""`
+	ImagePolicyAllowedRepos        string `envconfig:"IMAGE_POLICY_ALLOWED_REPOS" default:""`
+	ImagePolicyDeniedTags          string `envconfig:"IMAGE_POLICY_DENIED_TAGS" default:"latest"`
+	ImagePolicyRequireDigestLabels string `envconfig:"IMAGE_POLICY_REQUIRE_DIGEST_LABELS" default:"environment=production"`
+	ImagePolicySignedRepos         string `envconfig:"IMAGE_POLICY_SIGNED_REPOS" default:""`
+	ImagePolicySignatureKeys       string `envconfig:"IMAGE_POLICY_SIGNATURE_KEYS" default:""`
+
 	// Notification Watcher
 	NotificationWatcherInterval time.Duration `envconfig:"NOTIFICATION_WATCHER_INTERVAL" default:"15s"`
+	SLAETAPercentile            int           `envconfig:"SLA_ETA_PERCENTILE" default:"90"`
 
 	// Authentication & Authorization
 	AuthMode                     string        `envconfig:"AUTH_MODE" default:"none"` // none, api-key
@@ -160,6 +160,44 @@ func (s *EnvTestSuite) TestContractDeprecationWindowValidatedOnlyWhenEnforcement
 	assert.Contains(s.T(), err.Error(), "CAESIUM_CONTRACT_DEPRECATION_WINDOW")
 }
 
+func (s *EnvTestSuite) TestTracingValidation() {
+	s.T().Setenv("CAESIUM_TRACING_OTLP_ENDPOINT", "http://otel-collector:4318")
+	assert.NoError(s.T(), Process())
+	assert.Equal(s.T(), 1.0, Variables().TracingSampleRatio)
+	assert.Equal(s.T(), "caesium", Variables().TracingServiceName)
+
+	s.T().Setenv("CAESIUM_TRACING_OTLP_ENDPOINT", "otel-collector:4318")
+	err := Process()
+	s.Require().Error(err)
+	assert.Contains(s.T(), err.Error(), "CAESIUM_TRACING_OTLP_ENDPOINT")
+
+	s.T().Setenv("CAESIUM_TRACING_OTLP_ENDPOINT", "")
+	s.T().Setenv("CAESIUM_TRACING_SAMPLE_RATIO", "1.5")
+	err = Process()
+	s.Require().Error(err)
+	assert.Contains(s.T(), err.Error(), "CAESIUM_TRACING_SAMPLE_RATIO")
+}
+
+func (s *EnvTestSuite) TestImagePolicyValidation() {
+	assert.NoError(s.T(), Process())
+	assert.Equal(s.T(), "latest", Variables().ImagePolicyDeniedTags)
+	assert.Equal(s.T(), "environment=production", Variables().ImagePolicyRequireDigestLabels)
+
+	s.T().Setenv("CAESIUM_IMAGE_POLICY_MODE", "block")
+	err := Process()
+	s.Require().Error(err)
+	assert.Contains(s.T(), err.Error(), "CAESIUM_IMAGE_POLICY_MODE")
+
+	s.T().Setenv("CAESIUM_IMAGE_POLICY_MODE", "enforce")
+	s.T().Setenv("CAESIUM_IMAGE_POLICY_SIGNED_REPOS", "ghcr.io/acme/*")
+	err = Process()
+	s.Require().Error(err)
+	assert.Contains(s.T(), err.Error(), "CAESIUM_IMAGE_POLICY_SIGNATURE_KEYS")
+
+	s.T().Setenv("CAESIUM_IMAGE_POLICY_SIGNATURE_KEYS", "/etc/caesium/cosign.pub")
+	assert.NoError(s.T(), Process())
+}
+
 func (s *EnvTestSuite) TestProcessInvalidTypeFailure() {
 	s.T().Setenv("CAESIUM_PORT", "not_a_port")
 	assert.NotNil(s.T(), Process())
@@ -0,0 +1,56 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NamespaceQuotas holds per-namespace admission quotas parsed from the
+// CAESIUM_NAMESPACE_QUOTAS environment variable. The value must be a JSON
+// object keyed by namespace, for example
+// {"team-a":{"maxRuns":5,"maxTasks":40}}. Namespaces without an entry are
+// unlimited.
+type NamespaceQuotas map[string]NamespaceQuota
+
+// NamespaceQuota bounds what a namespace may run at once. A zero limit is
+// unlimited.
+type NamespaceQuota struct {
+	// MaxRuns bounds the namespace's concurrently running runs.
+	MaxRuns int `json:"maxRuns,omitempty"`
+	// MaxTasks bounds the unfinished tasks across the namespace's running
+	// runs, counting a run that has not registered its tasks yet at its job's
+	// full task count.
+	MaxTasks int `json:"maxTasks,omitempty"`
+}
+
+// Decode implements envconfig.Decoder.
+func (q *NamespaceQuotas) Decode(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		*q = nil
+		return nil
+	}
+
+	var quotas map[string]NamespaceQuota
+	if err := json.Unmarshal([]byte(value), &quotas); err != nil {
+		return fmt.Errorf("decode namespace quotas: %w", err)
+	}
+	for namespace, quota := range quotas {
+		if strings.TrimSpace(namespace) == "" {
+			return fmt.Errorf("decode namespace quotas: namespace must not be empty")
+		}
+		if quota.MaxRuns < 0 || quota.MaxTasks < 0 {
+			return fmt.Errorf("decode namespace quotas: %s: limits must be >= 0", namespace)
+		}
+	}
+
+	*q = quotas
+	return nil
+}
+
+// For returns the quota of namespace and whether it sets any limit.
+func (q NamespaceQuotas) For(namespace string) (NamespaceQuota, bool) {
+	quota, ok := q[namespace]
+	return quota, ok && (quota.MaxRuns > 0 || quota.MaxTasks > 0)
+}
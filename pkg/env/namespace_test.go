@@ -0,0 +1,41 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NamespaceQuotasSuite struct {
+	suite.Suite
+}
+
+func TestNamespaceQuotasSuite(t *testing.T) {
+	suite.Run(t, new(NamespaceQuotasSuite))
+}
+
+func (s *NamespaceQuotasSuite) TestDecode() {
+	var quotas NamespaceQuotas
+	s.Require().NoError(quotas.Decode(`{"team-a":{"maxRuns":5,"maxTasks":40},"team-b":{}}`))
+
+	quota, ok := quotas.For("team-a")
+	s.True(ok)
+	s.Equal(NamespaceQuota{MaxRuns: 5, MaxTasks: 40}, quota)
+	_, ok = quotas.For("team-b")
+	s.False(ok, "an entry without limits is unlimited")
+	_, ok = quotas.For("default")
+	s.False(ok)
+}
+
+func (s *NamespaceQuotasSuite) TestDecodeEmpty() {
+	quotas := NamespaceQuotas{"team-a": {MaxRuns: 1}}
+	s.Require().NoError(quotas.Decode("  "))
+	s.Nil(quotas)
+}
+
+func (s *NamespaceQuotasSuite) TestDecodeRejects() {
+	var quotas NamespaceQuotas
+	s.ErrorContains(quotas.Decode(`[1]`), "decode namespace quotas")
+	s.ErrorContains(quotas.Decode(`{"":{"maxRuns":1}}`), "namespace must not be empty")
+	s.ErrorContains(quotas.Decode(`{"team-a":{"maxTasks":-1}}`), "team-a: limits must be >= 0")
+}
@@ -0,0 +1,168 @@
+package jobdef
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Node affinity operators accepted in a match expression.
+const (
+	NodeOperatorIn           = "In"
+	NodeOperatorNotIn        = "NotIn"
+	NodeOperatorExists       = "Exists"
+	NodeOperatorDoesNotExist = "DoesNotExist"
+)
+
+// MaxNodeAffinityWeight bounds the weight of a single preferred term.
+const MaxNodeAffinityWeight = 100
+
+// NodeAffinity constrains which worker nodes may claim a step, by their
+// CAESIUM_NODE_LABELS. Every Required expression must hold on a node for it
+// to be eligible; Preferred terms rank eligible nodes by the summed weight of
+// the terms they satisfy. Spread prefers a node that is not already running
+// another task of the same run, so fan-out is distributed across nodes.
+// nodeSelector still applies alongside nodeAffinity.
+type NodeAffinity struct {
+	Required  []NodeSelectorRequirement `yaml:"required,omitempty" json:"required,omitempty"`
+	Preferred []PreferredNodeTerm       `yaml:"preferred,omitempty" json:"preferred,omitempty"`
+	Spread    bool                      `yaml:"spread,omitempty" json:"spread,omitempty"`
+}
+
+// NodeSelectorRequirement matches a node label. In and NotIn compare the
+// label's value against Values; Exists and DoesNotExist test only whether
+// the key is set and take no values. A node without the key satisfies
+// NotIn, as in Kubernetes.
+type NodeSelectorRequirement struct {
+	Key      string   `yaml:"key" json:"key"`
+	Operator string   `yaml:"operator" json:"operator"`
+	Values   []string `yaml:"values,omitempty" json:"values,omitempty"`
+}
+
+// PreferredNodeTerm adds Weight (1-100) to a node's score when every
+// expression in Match holds on it.
+type PreferredNodeTerm struct {
+	Weight int                       `yaml:"weight" json:"weight"`
+	Match  []NodeSelectorRequirement `yaml:"match" json:"match"`
+}
+
+// Matches reports whether the requirement holds for a node's labels.
+func (r NodeSelectorRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case NodeOperatorIn:
+		return ok && slices.Contains(r.Values, value)
+	case NodeOperatorNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case NodeOperatorExists:
+		return ok
+	case NodeOperatorDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// Matches reports whether every requirement in the term holds for labels.
+func (t PreferredNodeTerm) Matches(labels map[string]string) bool {
+	return requirementsMatch(t.Match, labels)
+}
+
+// Matches reports whether a node with labels satisfies every required
+// expression. A nil affinity matches every node.
+func (a *NodeAffinity) Matches(labels map[string]string) bool {
+	if a == nil {
+		return true
+	}
+	return requirementsMatch(a.Required, labels)
+}
+
+// Score sums the weights of the preferred terms a node with labels
+// satisfies. A nil affinity scores every node zero.
+func (a *NodeAffinity) Score(labels map[string]string) int {
+	if a == nil {
+		return 0
+	}
+	score := 0
+	for _, term := range a.Preferred {
+		if term.Matches(labels) {
+			score += term.Weight
+		}
+	}
+	return score
+}
+
+// IsZero reports whether the affinity places no constraint or preference on
+// node choice.
+func (a *NodeAffinity) IsZero() bool {
+	return a == nil || (len(a.Required) == 0 && len(a.Preferred) == 0 && !a.Spread)
+}
+
+// MatchesNodeSelector reports whether labels carry every key/value pair of
+// an exact-match nodeSelector.
+func MatchesNodeSelector(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if got, ok := labels[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+func requirementsMatch(reqs []NodeSelectorRequirement, labels map[string]string) bool {
+	for _, req := range reqs {
+		if !req.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateStepNodeAffinity(steps []Step) error {
+	for i := range steps {
+		affinity := steps[i].NodeAffinity
+		if affinity == nil {
+			continue
+		}
+		for j := range affinity.Required {
+			if err := validateNodeSelectorRequirement(&affinity.Required[j]); err != nil {
+				return fmt.Errorf("steps[%d].nodeAffinity.required[%d]: %w", i, j, err)
+			}
+		}
+		for j := range affinity.Preferred {
+			term := &affinity.Preferred[j]
+			if term.Weight < 1 || term.Weight > MaxNodeAffinityWeight {
+				return fmt.Errorf("steps[%d].nodeAffinity.preferred[%d].weight must be between 1 and %d", i, j, MaxNodeAffinityWeight)
+			}
+			if len(term.Match) == 0 {
+				return fmt.Errorf("steps[%d].nodeAffinity.preferred[%d].match requires at least one expression", i, j)
+			}
+			for k := range term.Match {
+				if err := validateNodeSelectorRequirement(&term.Match[k]); err != nil {
+					return fmt.Errorf("steps[%d].nodeAffinity.preferred[%d].match[%d]: %w", i, j, k, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateNodeSelectorRequirement(req *NodeSelectorRequirement) error {
+	req.Key = strings.TrimSpace(req.Key)
+	if req.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	switch req.Operator {
+	case NodeOperatorIn, NodeOperatorNotIn:
+		if len(req.Values) == 0 {
+			return fmt.Errorf("operator %s requires at least one value", req.Operator)
+		}
+	case NodeOperatorExists, NodeOperatorDoesNotExist:
+		if len(req.Values) > 0 {
+			return fmt.Errorf("operator %s does not take values", req.Operator)
+		}
+	default:
+		return fmt.Errorf("operator %q is not supported (want In, NotIn, Exists or DoesNotExist)", req.Operator)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package jobdef
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStepNodeAffinity(t *testing.T) {
+	src := `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: placed
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: train
+    image: alpine:3.23
+    nodeSelector: {pool: ml}
+    nodeAffinity:
+      required:
+        - {key: " arch ", operator: In, values: [amd64, arm64]}
+        - {key: zone, operator: NotIn, values: [edge]}
+      preferred:
+        - weight: 80
+          match: [{key: gpu, operator: Exists}]
+      spread: true
+`
+	def, err := Parse([]byte(src))
+	require.NoError(t, err)
+	affinity := def.Steps[0].NodeAffinity
+	require.NotNil(t, affinity)
+	require.Equal(t, "arch", affinity.Required[0].Key)
+	require.True(t, affinity.Spread)
+	require.Equal(t, map[string]string{"pool": "ml"}, def.Steps[0].NodeSelector)
+
+	raw, err := json.Marshal(def.Steps[0])
+	require.NoError(t, err)
+	var roundTrip Step
+	require.NoError(t, json.Unmarshal(raw, &roundTrip))
+	require.Equal(t, affinity, roundTrip.NodeAffinity)
+
+	invalid := `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: placed
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: train
+    image: alpine:3.23
+    nodeAffinity: AFFINITY
+`
+	for name, tc := range map[string]struct{ block, want string }{
+		"unknown operator":  {`{required: [{key: arch, operator: Gt, values: ["1"]}]}`, "required[0]: operator \"Gt\" is not supported"},
+		"missing key":       {`{required: [{operator: Exists}]}`, "required[0]: key is required"},
+		"In without values": {`{required: [{key: arch, operator: In}]}`, "required[0]: operator In requires at least one value"},
+		"Exists with value": {`{required: [{key: gpu, operator: Exists, values: [a100]}]}`, "required[0]: operator Exists does not take values"},
+		"zero weight":       {`{preferred: [{weight: 0, match: [{key: gpu, operator: Exists}]}]}`, "preferred[0].weight must be between 1 and 100"},
+		"heavy weight":      {`{preferred: [{weight: 101, match: [{key: gpu, operator: Exists}]}]}`, "preferred[0].weight must be between 1 and 100"},
+		"empty match":       {`{preferred: [{weight: 5}]}`, "preferred[0].match requires at least one expression"},
+		"bad match":         {`{preferred: [{weight: 5, match: [{key: gpu, operator: DoesNotExist, values: [x]}]}]}`, "preferred[0].match[0]: operator DoesNotExist"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(strings.Replace(invalid, "AFFINITY", tc.block, 1)))
+			require.ErrorContains(t, err, "steps[0].nodeAffinity."+tc.want)
+		})
+	}
+}
+
+func TestNodeAffinityMatchesAndScores(t *testing.T) {
+	affinity := &NodeAffinity{
+		Required: []NodeSelectorRequirement{
+			{Key: "arch", Operator: NodeOperatorIn, Values: []string{"amd64", "arm64"}},
+			{Key: "zone", Operator: NodeOperatorNotIn, Values: []string{"edge"}},
+			{Key: "retired", Operator: NodeOperatorDoesNotExist},
+		},
+		Preferred: []PreferredNodeTerm{
+			{Weight: 70, Match: []NodeSelectorRequirement{{Key: "gpu", Operator: NodeOperatorExists}}},
+			{Weight: 20, Match: []NodeSelectorRequirement{
+				{Key: "gpu", Operator: NodeOperatorIn, Values: []string{"h100"}},
+				{Key: "zone", Operator: NodeOperatorIn, Values: []string{"a"}},
+			}},
+		},
+	}
+
+	require.True(t, affinity.Matches(map[string]string{"arch": "amd64"}), "NotIn holds when the key is missing")
+	require.True(t, affinity.Matches(map[string]string{"arch": "arm64", "zone": "a"}))
+	require.False(t, affinity.Matches(map[string]string{"arch": "amd64", "zone": "edge"}))
+	require.False(t, affinity.Matches(map[string]string{"arch": "s390x"}))
+	require.False(t, affinity.Matches(map[string]string{"arch": "amd64", "retired": ""}))
+	require.False(t, affinity.Matches(nil), "In needs the key")
+
+	require.Equal(t, 0, affinity.Score(map[string]string{"arch": "amd64"}))
+	require.Equal(t, 70, affinity.Score(map[string]string{"gpu": "a100", "zone": "a"}))
+	require.Equal(t, 90, affinity.Score(map[string]string{"gpu": "h100", "zone": "a"}))
+
+	var none *NodeAffinity
+	require.True(t, none.Matches(nil))
+	require.Zero(t, none.Score(map[string]string{"gpu": "h100"}))
+	require.True(t, none.IsZero())
+	require.True(t, (&NodeAffinity{}).IsZero())
+	require.False(t, (&NodeAffinity{Spread: true}).IsZero())
+
+	require.True(t, MatchesNodeSelector(map[string]string{"disk": "ssd"}, map[string]string{"disk": "ssd", "zone": "a"}))
+	require.False(t, MatchesNodeSelector(map[string]string{"disk": "ssd"}, map[string]string{"disk": "hdd"}))
+	require.True(t, MatchesNodeSelector(nil, nil))
+}
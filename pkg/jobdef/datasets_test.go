@@ -135,6 +135,22 @@ steps:
 	}
 }
 
+func TestParseColumnLineage(t *testing.T) {
+	def, err := Parse([]byte(datasetStep(`consumes: [raw.orders, raw.customers], produces: [{name: mart.revenue, columnLineage: {
+      total: {inputFields: [{dataset: raw.orders, field: amount, subtype: AGGREGATION}]},
+      region: {inputFields: [{dataset: raw.customers, field: region}, {dataset: raw.orders, field: customer_id, type: indirect, subtype: JOIN}]}}}]`)))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	lineage := def.Steps[0].Datasets.Produces[0].ColumnLineage
+	if got := lineage["total"].InputFields[0]; got.Dataset != "raw.orders" || got.Field != "amount" || got.Type != ColumnLineageDirect || got.Subtype != "AGGREGATION" {
+		t.Fatalf("unexpected total lineage: %+v", got)
+	}
+	if got := lineage["region"].InputFields[1]; got.Type != ColumnLineageIndirect {
+		t.Fatalf("type not normalized: %+v", got)
+	}
+}
+
 // TestConsumesSurviveJSONRoundTrip pins the CLI→server apply path: a legacy
 // scalar `consumes: [name]` manifest is parsed from YAML by the CLI, marshaled
 // to JSON for the apply API (which emits the object shape), unmarshaled
@@ -289,6 +305,26 @@ func TestValidateDatasetsRejectsBadInputs(t *testing.T) {
 			yaml: datasetStep(`consumes: [{name: a, schema: {type: nope}}]`),
 			want: "consumes[0].schema: invalid schema",
 		},
+		{
+			name: "column lineage without input fields",
+			yaml: datasetStep(`consumes: [a], produces: [{name: b, columnLineage: {total: {inputFields: []}}}]`),
+			want: `columnLineage["total"].inputFields must not be empty`,
+		},
+		{
+			name: "column lineage input missing field",
+			yaml: datasetStep(`consumes: [a], produces: [{name: b, columnLineage: {total: {inputFields: [{dataset: a}]}}}]`),
+			want: "requires dataset and field",
+		},
+		{
+			name: "column lineage input not consumed",
+			yaml: datasetStep(`consumes: [a], produces: [{name: b, columnLineage: {total: {inputFields: [{dataset: c, field: x}]}}}]`),
+			want: `dataset "c" must be listed in the step's datasets.consumes`,
+		},
+		{
+			name: "column lineage bad type",
+			yaml: datasetStep(`consumes: [a], produces: [{name: b, columnLineage: {total: {inputFields: [{dataset: a, field: x, type: COPY}]}}}]`),
+			want: `type "COPY" must be "DIRECT" or "INDIRECT"`,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -38,6 +38,7 @@ const (
 
 	StepTypeTask   = "task"
 	StepTypeBranch = "branch"
+	StepTypeSensor = "sensor"
 )
 
 var simpleJSONPathPattern = regexp.MustCompile(`^\$(?:\[[0-9]+\])*(?:\.[^.\s\[\]]+(?:\[[0-9]+\])*)*$`)
@@ -49,6 +50,14 @@ var simpleJSONPathPattern = regexp.MustCompile(`^\$(?:\[[0-9]+\])*(?:\.[^.\s\[\]
 // the API server rejects at apply/run time.
 var kueueQueueNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
 
+// namespacePattern matches a namespace: a DNS label of lowercase
+// alphanumerics separated by '-', at most MaxNamespaceLength characters.
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// poolNamePattern matches a pool name: lowercase alphanumerics separated by
+// '-', '_' or '.', at most MaxPoolNameLength characters.
+var poolNamePattern = regexp.MustCompile(`^[a-z0-9]([-_a-z0-9.]*[a-z0-9])?$`)
+
 // Definition models the root job document.
 type Definition struct {
 	Schema     string     `yaml:"$schema,omitempty" json:"$schema,omitempty"`
@@ -96,7 +105,10 @@ func (s *SLAConfig) HasSLA() bool {
 
 // Metadata contains descriptive data for the job.
 type Metadata struct {
-	Alias            string            `yaml:"alias" json:"alias"`
+	Alias string `yaml:"alias" json:"alias"`
+	// Namespace groups the job with its trigger and runs. Aliases are unique
+	// within a namespace; an empty namespace is DefaultNamespace.
+	Namespace        string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 	Labels           map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 	Annotations      map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	MaxParallelTasks int               `yaml:"maxParallelTasks,omitempty" json:"maxParallelTasks,omitempty"`
@@ -113,16 +125,29 @@ type Metadata struct {
 	//                 must have a successfully completed run. Alerts even if
 	//                 no run has started.
 	SLA *SLAConfig `yaml:"sla,omitempty" json:"sla,omitempty"`
+	// Retention bounds how long this job's finished runs are kept. Runs that
+	// fall outside it are purged by the run archiver; without a policy the
+	// cluster default from CAESIUM_RUN_RETENTION_* applies.
+	Retention *Retention `yaml:"retention,omitempty" json:"retention,omitempty"`
 	// SchemaValidation controls runtime output schema validation.
 	// Values: "" (disabled), "warn" (log violations), "fail" (fail task on violation).
 	SchemaValidation string `yaml:"schemaValidation,omitempty" json:"schemaValidation,omitempty"`
 	// ReplaySafe marks every step in this job as eligible for quarantined replay.
 	// The effective per-step value is snapshotted onto TaskRun when the task runs.
-	ReplaySafe                   bool              `yaml:"replaySafe,omitempty" json:"replaySafe,omitempty"`
+	ReplaySafe bool `yaml:"replaySafe,omitempty" json:"replaySafe,omitempty"`
+	// RunTemplates opts the job into run-time templating of step command and
+	// env values and trigger defaultParams. It is off by default so manifests
+	// that pass "{{" through to tools such as dbt or Jinja apply unchanged.
+	RunTemplates                 bool              `yaml:"runTemplates,omitempty" json:"runTemplates,omitempty"`
 	Cache                        interface{}       `yaml:"cache,omitempty" json:"cache"`
 	ServiceAccountName           string            `yaml:"serviceAccountName,omitempty" json:"serviceAccountName,omitempty"`
 	PodAnnotations               map[string]string `yaml:"podAnnotations,omitempty" json:"podAnnotations,omitempty"`
 	AutomountServiceAccountToken *bool             `yaml:"automountServiceAccountToken,omitempty" json:"automountServiceAccountToken,omitempty"`
+	// Resources sets job-wide default CPU/memory/storage/GPU requests for every
+	// step. A step's own resources override these field by field. Like
+	// kueue.queueName they are scheduling metadata and do not affect the cache
+	// hash.
+	Resources *container.Resources `yaml:"resources,omitempty" json:"resources,omitempty"`
 	// Datasets declares the external source datasets this job's steps consume.
 	// It is scheduling metadata for freshness and does not affect the cache hash.
 	Datasets *MetadataDatasets `yaml:"datasets,omitempty" json:"datasets,omitempty"`
@@ -142,6 +167,33 @@ type Concurrency struct {
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
 }
 
+// Retention keeps a job's most recent KeepRuns runs and any run that finished
+// within KeepDays. Failed runs are kept for KeepFailedDays instead when it is
+// set, so failures can outlive successful history.
+type Retention struct {
+	KeepRuns       int `yaml:"keepRuns,omitempty" json:"keepRuns,omitempty"`
+	KeepDays       int `yaml:"keepDays,omitempty" json:"keepDays,omitempty"`
+	KeepFailedDays int `yaml:"keepFailedDays,omitempty" json:"keepFailedDays,omitempty"`
+}
+
+// Validate reports a policy that would keep nothing or that keeps failures
+// for less time than other runs.
+func (r *Retention) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.KeepRuns < 0 || r.KeepDays < 0 || r.KeepFailedDays < 0 {
+		return fmt.Errorf("keepRuns, keepDays, and keepFailedDays must be >= 0")
+	}
+	if r.KeepRuns == 0 && r.KeepDays == 0 {
+		return fmt.Errorf("keepRuns or keepDays must be set")
+	}
+	if r.KeepFailedDays > 0 && r.KeepFailedDays < r.KeepDays {
+		return fmt.Errorf("keepFailedDays must be >= keepDays")
+	}
+	return nil
+}
+
 // RateLimit declares a shared resource budget for task scheduling.
 type RateLimit struct {
 	Resource string `yaml:"resource" json:"resource"`
@@ -224,6 +276,104 @@ type StepRateLimit struct {
 	Units    int    `yaml:"units" json:"units"`
 }
 
+// DefaultNamespace holds jobs whose metadata.namespace is unset.
+const DefaultNamespace = "default"
+
+// MaxNamespaceLength bounds a namespace name.
+const MaxNamespaceLength = 63
+
+// ValidNamespace reports whether name is a well-formed namespace.
+func ValidNamespace(name string) bool {
+	return len(name) <= MaxNamespaceLength && namespacePattern.MatchString(name)
+}
+
+// NamespaceOrDefault returns namespace, or DefaultNamespace when it is blank.
+func NamespaceOrDefault(namespace string) string {
+	if namespace = strings.TrimSpace(namespace); namespace == "" {
+		return DefaultNamespace
+	}
+	return namespace
+}
+
+// QualifiedAlias names a job across namespaces: the bare alias for a job in
+// DefaultNamespace, "namespace/alias" otherwise.
+func QualifiedAlias(namespace, alias string) string {
+	if namespace = NamespaceOrDefault(namespace); namespace != DefaultNamespace {
+		return namespace + "/" + alias
+	}
+	return alias
+}
+
+// SplitQualifiedAlias is the inverse of QualifiedAlias: "namespace/alias"
+// yields its parts and a bare alias belongs to DefaultNamespace.
+func SplitQualifiedAlias(ref string) (namespace, alias string) {
+	ref = strings.TrimSpace(ref)
+	if idx := strings.Index(ref, "/"); idx >= 0 {
+		return NamespaceOrDefault(ref[:idx]), strings.TrimSpace(ref[idx+1:])
+	}
+	return DefaultNamespace, ref
+}
+
+// MaxPoolNameLength bounds a pool name.
+const MaxPoolNameLength = 63
+
+// StepPool runs a step under a cluster-wide pool. The step occupies Slots of
+// the pool's capacity while it runs; Slots defaults to 1.
+type StepPool struct {
+	Name  string `yaml:"name" json:"name"`
+	Slots int    `yaml:"slots,omitempty" json:"slots,omitempty"`
+}
+
+// ValidPoolName reports whether name is a well-formed pool name.
+func ValidPoolName(name string) bool {
+	return len(name) <= MaxPoolNameLength && poolNamePattern.MatchString(name)
+}
+
+// MaxMapItems bounds how many instances a single mapped step may materialize
+// in one run. A longer list fails the mapped step rather than truncating it.
+const MaxMapItems = 1024
+
+// StepMap fans a step out over the items of a JSON array emitted by a direct
+// predecessor. Over names the list as "<step>.<output key>"; MaxParallel
+// bounds how many instances run at once (0 means unbounded).
+type StepMap struct {
+	Over        string `yaml:"over" json:"over"`
+	MaxParallel int    `yaml:"maxParallel,omitempty" json:"maxParallel,omitempty"`
+}
+
+// Source splits Over into the producing step and its output key. Step names
+// may themselves contain dots, so the longest name in stepNames that prefixes
+// Over wins; ok is false when no name matches or the key is empty.
+func (m StepMap) Source(stepNames []string) (step, key string, ok bool) {
+	for _, name := range stepNames {
+		if len(name) <= len(step) || !strings.HasPrefix(m.Over, name+".") {
+			continue
+		}
+		step, key = name, m.Over[len(name)+1:]
+	}
+	return step, key, step != "" && key != ""
+}
+
+// Gate types and timeout outcomes accepted on a step's gate block.
+const (
+	GateTypeApproval  = "approval"
+	GateOnTimeoutFail = "fail"
+	GateOnTimeoutSkip = "skip"
+)
+
+// StepGate pauses a step in awaiting_approval once its dependencies are
+// satisfied, until a human approves or rejects it through the REST API.
+// Approvers optionally restricts who may decide to members of the named SSO
+// groups (a leading "@" is ignored); the route's RBAC role applies either
+// way. A zero Timeout waits indefinitely; otherwise OnTimeout decides whether
+// an undecided gate fails the step (the default) or skips it.
+type StepGate struct {
+	Type      string        `yaml:"type" json:"type"`
+	Approvers []string      `yaml:"approvers,omitempty" json:"approvers,omitempty"`
+	Timeout   time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	OnTimeout string        `yaml:"onTimeout,omitempty" json:"onTimeout,omitempty"`
+}
+
 // Dataset direction constants describe how a declaration relates a step (or the
 // job's metadata) to a dataset. They are the canonical values persisted on the
 // DatasetDeclaration registry model and read by the cross-job lint.
@@ -239,6 +389,13 @@ const (
 	// DatasetSchemaFromOutput reuses the producing step's outputSchema as the
 	// produced dataset schema.
 	DatasetSchemaFromOutput = "output"
+
+	// ColumnLineageDirect marks an output column whose value is computed from
+	// the input column (copied, cast, aggregated). ColumnLineageIndirect marks
+	// an input column that only influences which rows appear (join keys,
+	// filters, grouping). They are the OpenLineage transformation types.
+	ColumnLineageDirect   = "DIRECT"
+	ColumnLineageIndirect = "INDIRECT"
 )
 
 // Watermark identifies the ##caesium::output key a producing step emits to
@@ -268,6 +425,27 @@ type ProducedDataset struct {
 	MaxStaleness string `yaml:"maxStaleness,omitempty" json:"maxStaleness,omitempty"`
 	// Watermark names the output key this step emits to advance the dataset.
 	Watermark *Watermark `yaml:"watermark,omitempty" json:"watermark,omitempty"`
+	// ColumnLineage maps each output column to the consumed dataset columns it
+	// is derived from. It is lineage metadata only: emitted as the OpenLineage
+	// columnLineage facet and never part of the cache identity.
+	ColumnLineage map[string]ColumnLineageField `yaml:"columnLineage,omitempty" json:"columnLineage,omitempty"`
+}
+
+// ColumnLineageField lists the input columns one output column is derived
+// from.
+type ColumnLineageField struct {
+	InputFields []ColumnLineageInput `yaml:"inputFields" json:"inputFields"`
+}
+
+// ColumnLineageInput names one input column. Dataset must be consumed by the
+// same step. Type defaults to DIRECT; Subtype and Description are passed
+// through to the OpenLineage facet verbatim (e.g. subtype AGGREGATION, JOIN).
+type ColumnLineageInput struct {
+	Dataset     string `yaml:"dataset" json:"dataset"`
+	Field       string `yaml:"field" json:"field"`
+	Type        string `yaml:"type,omitempty" json:"type,omitempty"`
+	Subtype     string `yaml:"subtype,omitempty" json:"subtype,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 }
 
 // ConsumedDataset declares a dataset a step reads. Legacy YAML may use a plain
@@ -551,9 +729,17 @@ type Step struct {
 	RetryDelay   time.Duration     `yaml:"retryDelay,omitempty" json:"retryDelay,omitempty"`
 	RetryBackoff bool              `yaml:"retryBackoff,omitempty" json:"retryBackoff,omitempty"`
 	TriggerRule  string            `yaml:"triggerRule,omitempty" json:"triggerRule,omitempty"`
+	// NodeAffinity adds expression-based required and preferred node
+	// constraints on top of NodeSelector. It is scheduling metadata and does
+	// not affect the cache hash.
+	NodeAffinity *NodeAffinity `yaml:"nodeAffinity,omitempty" json:"nodeAffinity,omitempty"`
 	// ReplaySafe marks this step as eligible for quarantined replay. It is
 	// control-plane metadata, not a runtime input or cache identity field.
-	ReplaySafe                   bool              `yaml:"replaySafe,omitempty" json:"replaySafe,omitempty"`
+	ReplaySafe bool `yaml:"replaySafe,omitempty" json:"replaySafe,omitempty"`
+	// RunTemplates opts the job into run-time templating of step command and
+	// env values and trigger defaultParams. It is off by default so manifests
+	// that pass "{{" through to tools such as dbt or Jinja apply unchanged.
+	RunTemplates                 bool              `yaml:"runTemplates,omitempty" json:"runTemplates,omitempty"`
 	VolumeMounts                 []VolumeMount     `yaml:"volumeMounts,omitempty" json:"volumeMounts,omitempty"`
 	ServiceAccountName           string            `yaml:"serviceAccountName,omitempty" json:"serviceAccountName,omitempty"`
 	PodAnnotations               map[string]string `yaml:"podAnnotations,omitempty" json:"podAnnotations,omitempty"`
@@ -564,6 +750,18 @@ type Step struct {
 	// RateLimit references a job-level shared resource budget for this step.
 	// It is scheduling metadata and does not affect the cache hash.
 	RateLimit *StepRateLimit `yaml:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+	// Pool admits this step only while its cluster-wide pool has free slots.
+	// It is scheduling metadata and does not affect the cache hash.
+	Pool *StepPool `yaml:"pool,omitempty" json:"pool,omitempty"`
+	// Map runs one instance of this step per item of a predecessor's JSON
+	// array output. Each instance hashes its own item into its cache key.
+	Map *StepMap `yaml:"map,omitempty" json:"map,omitempty"`
+	// Gate holds this step for human approval before it runs. It is control
+	// metadata and does not affect the cache hash.
+	Gate *StepGate `yaml:"gate,omitempty" json:"gate,omitempty"`
+	// Sensor configures a sensor step's probe; it is set only on steps with
+	// type: sensor, which never run a container.
+	Sensor *StepSensor `yaml:"sensor,omitempty" json:"sensor,omitempty"`
 	// OutputSchema is a JSON Schema describing this step's expected output keys.
 	OutputSchema map[string]any `yaml:"outputSchema,omitempty" json:"outputSchema,omitempty"`
 	// InputSchema maps predecessor step names to JSON Schema fragments describing
@@ -571,8 +769,13 @@ type Step struct {
 	InputSchema map[string]map[string]any `yaml:"inputSchema,omitempty" json:"inputSchema,omitempty"`
 	// Datasets declares the datasets this step consumes and produces. It is
 	// scheduling metadata for freshness and does not affect the cache hash.
-	Datasets       *StepDatasets `yaml:"datasets,omitempty" json:"datasets,omitempty"`
-	Cache          interface{}   `yaml:"cache,omitempty" json:"cache"`
+	Datasets *StepDatasets `yaml:"datasets,omitempty" json:"datasets,omitempty"`
+	Cache    interface{}   `yaml:"cache,omitempty" json:"cache"`
+	// Uses expands this step from a StepTemplate ("<name>@<version>") and With
+	// binds the template's parameters. Both are consumed by
+	// Definition.ExpandTemplates before validation and never reach the server.
+	Uses           string         `yaml:"uses,omitempty" json:"uses,omitempty"`
+	With           map[string]any `yaml:"with,omitempty" json:"with,omitempty"`
 	container.Spec `yaml:",inline" json:",inline"`
 }
 
@@ -585,6 +788,7 @@ func (s *Step) UnmarshalYAML(value *yaml.Node) error {
 		Image                        string                    `yaml:"image"`
 		Command                      []string                  `yaml:"command"`
 		NodeSelector                 map[string]string         `yaml:"nodeSelector"`
+		NodeAffinity                 *NodeAffinity             `yaml:"nodeAffinity"`
 		Next                         interface{}               `yaml:"next"`
 		DependsOn                    interface{}               `yaml:"dependsOn"`
 		Retries                      int                       `yaml:"retries"`
@@ -598,10 +802,16 @@ func (s *Step) UnmarshalYAML(value *yaml.Node) error {
 		AutomountServiceAccountToken *bool                     `yaml:"automountServiceAccountToken"`
 		Kueue                        *Kueue                    `yaml:"kueue"`
 		RateLimit                    *StepRateLimit            `yaml:"rateLimit"`
+		Pool                         *StepPool                 `yaml:"pool"`
+		Map                          *StepMap                  `yaml:"map"`
+		Gate                         *StepGate                 `yaml:"gate"`
+		Sensor                       *StepSensor               `yaml:"sensor"`
 		OutputSchema                 map[string]any            `yaml:"outputSchema"`
 		InputSchema                  map[string]map[string]any `yaml:"inputSchema"`
 		Datasets                     *StepDatasets             `yaml:"datasets"`
 		Cache                        interface{}               `yaml:"cache"`
+		Uses                         string                    `yaml:"uses"`
+		With                         map[string]any            `yaml:"with"`
 		container.Spec               `yaml:",inline"`
 	}
 
@@ -632,6 +842,7 @@ func (s *Step) UnmarshalYAML(value *yaml.Node) error {
 	s.Image = rs.Image
 	s.Command = rs.Command
 	s.NodeSelector = rs.NodeSelector
+	s.NodeAffinity = rs.NodeAffinity
 	s.Next = nextList
 	s.DependsOn = dependsList
 	s.Retries = rs.Retries
@@ -645,10 +856,16 @@ func (s *Step) UnmarshalYAML(value *yaml.Node) error {
 	s.AutomountServiceAccountToken = rs.AutomountServiceAccountToken
 	s.Kueue = rs.Kueue
 	s.RateLimit = rs.RateLimit
+	s.Pool = rs.Pool
+	s.Map = rs.Map
+	s.Gate = rs.Gate
+	s.Sensor = rs.Sensor
 	s.OutputSchema = rs.OutputSchema
 	s.InputSchema = rs.InputSchema
 	s.Datasets = rs.Datasets
 	s.Cache = rs.Cache
+	s.Uses = rs.Uses
+	s.With = rs.With
 	s.Spec = rs.Spec
 
 	return nil
@@ -664,6 +881,7 @@ func (s *Step) UnmarshalJSON(data []byte) error {
 		Image                        string                    `json:"image"`
 		Command                      []string                  `json:"command"`
 		NodeSelector                 map[string]string         `json:"nodeSelector"`
+		NodeAffinity                 *NodeAffinity             `json:"nodeAffinity"`
 		Next                         []string                  `json:"next"`
 		DependsOn                    []string                  `json:"dependsOn"`
 		Retries                      int                       `json:"retries"`
@@ -677,10 +895,16 @@ func (s *Step) UnmarshalJSON(data []byte) error {
 		AutomountServiceAccountToken *bool                     `json:"automountServiceAccountToken"`
 		Kueue                        *Kueue                    `json:"kueue"`
 		RateLimit                    *StepRateLimit            `json:"rateLimit"`
+		Pool                         *StepPool                 `json:"pool"`
+		Map                          *StepMap                  `json:"map"`
+		Gate                         *StepGate                 `json:"gate"`
+		Sensor                       *StepSensor               `json:"sensor"`
 		OutputSchema                 map[string]any            `json:"outputSchema"`
 		InputSchema                  map[string]map[string]any `json:"inputSchema"`
 		Datasets                     *StepDatasets             `json:"datasets"`
 		Cache                        interface{}               `json:"cache"`
+		Uses                         string                    `json:"uses"`
+		With                         map[string]any            `json:"with"`
 		container.Spec               `json:",inline"`
 	}
 
@@ -701,6 +925,7 @@ func (s *Step) UnmarshalJSON(data []byte) error {
 	s.Image = rs.Image
 	s.Command = rs.Command
 	s.NodeSelector = rs.NodeSelector
+	s.NodeAffinity = rs.NodeAffinity
 	s.Next = rs.Next
 	s.DependsOn = rs.DependsOn
 	s.Retries = rs.Retries
@@ -714,19 +939,35 @@ func (s *Step) UnmarshalJSON(data []byte) error {
 	s.AutomountServiceAccountToken = rs.AutomountServiceAccountToken
 	s.Kueue = rs.Kueue
 	s.RateLimit = rs.RateLimit
+	s.Pool = rs.Pool
+	s.Map = rs.Map
+	s.Gate = rs.Gate
+	s.Sensor = rs.Sensor
 	s.OutputSchema = rs.OutputSchema
 	s.InputSchema = rs.InputSchema
 	s.Datasets = rs.Datasets
 	s.Cache = rs.Cache
+	s.Uses = rs.Uses
+	s.With = rs.With
 	s.Spec = rs.Spec
 
 	return nil
 }
 
-// Parse parses YAML bytes into a Definition.
+// Parse parses YAML bytes into a Definition. The input may also carry
+// StepTemplate documents; steps that use them are expanded before validation.
 func Parse(data []byte) (*Definition, error) {
+	node, templates, err := decodeDocuments(data)
+	if err != nil {
+		return nil, err
+	}
 	var def Definition
-	if err := yaml.Unmarshal(data, &def); err != nil {
+	if node != nil {
+		if err := node.Decode(&def); err != nil {
+			return nil, err
+		}
+	}
+	if err := def.ExpandTemplates(templates); err != nil {
 		return nil, err
 	}
 	if err := def.Validate(); err != nil {
@@ -746,6 +987,10 @@ func (d *Definition) Validate() error {
 	if strings.TrimSpace(d.Metadata.Alias) == "" {
 		return fmt.Errorf("metadata.alias is required")
 	}
+	d.Metadata.Namespace = NamespaceOrDefault(d.Metadata.Namespace)
+	if !ValidNamespace(d.Metadata.Namespace) {
+		return fmt.Errorf("metadata.namespace %q must be lowercase alphanumerics separated by '-' (max %d characters)", d.Metadata.Namespace, MaxNamespaceLength)
+	}
 
 	switch d.Metadata.SchemaValidation {
 	case SchemaValidationDisabled, SchemaValidationWarn, SchemaValidationFail:
@@ -775,6 +1020,11 @@ func (d *Definition) Validate() error {
 	if err := validateSteps(d.Steps, volumes, rateLimitResources); err != nil {
 		return err
 	}
+	for i := range d.Steps {
+		if sensor := d.Steps[i].Sensor; sensor != nil && sensor.Job != nil && sensor.Job.Namespace == "" {
+			sensor.Job.Namespace = d.Metadata.Namespace
+		}
+	}
 	if err := validateDatasets(d); err != nil {
 		return err
 	}
@@ -784,6 +1034,9 @@ func (d *Definition) Validate() error {
 	if err := validateRemediation(d); err != nil {
 		return err
 	}
+	if err := validateRunTemplates(d); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1052,12 +1305,61 @@ func validateDatasets(d *Definition) error {
 			seen[name] = struct{}{}
 			consumed.Name = name
 		}
+		for j := range step.Datasets.Produces {
+			if err := validateColumnLineage(fmt.Sprintf("steps[%d].datasets.produces[%d].columnLineage", i, j), step.Datasets.Produces[j].ColumnLineage, seen); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// validateColumnLineage checks a produced dataset's column lineage against the
+// step's consumed datasets and normalizes each input's type to upper case.
+func validateColumnLineage(path string, lineage map[string]ColumnLineageField, consumed map[string]struct{}) error {
+	columns := make([]string, 0, len(lineage))
+	for column := range lineage {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	for _, column := range columns {
+		if strings.TrimSpace(column) == "" {
+			return fmt.Errorf("%s must not contain an empty column name", path)
+		}
+		field := lineage[column]
+		if len(field.InputFields) == 0 {
+			return fmt.Errorf("%s[%q].inputFields must not be empty", path, column)
+		}
+		for k := range field.InputFields {
+			in := &field.InputFields[k]
+			at := fmt.Sprintf("%s[%q].inputFields[%d]", path, column, k)
+			in.Dataset = strings.TrimSpace(in.Dataset)
+			in.Field = strings.TrimSpace(in.Field)
+			if in.Dataset == "" || in.Field == "" {
+				return fmt.Errorf("%s requires dataset and field", at)
+			}
+			if _, ok := consumed[in.Dataset]; !ok {
+				return fmt.Errorf("%s.dataset %q must be listed in the step's datasets.consumes", at, in.Dataset)
+			}
+			in.Type = strings.ToUpper(strings.TrimSpace(in.Type))
+			switch in.Type {
+			case "":
+				in.Type = ColumnLineageDirect
+			case ColumnLineageDirect, ColumnLineageIndirect:
+			default:
+				return fmt.Errorf("%s.type %q must be %q or %q", at, in.Type, ColumnLineageDirect, ColumnLineageIndirect)
+			}
+		}
+	}
+	return nil
+}
+
 func validateSchedulingMetadata(metadata *Metadata) (map[string]struct{}, error) {
+	if err := metadata.Resources.Validate(); err != nil {
+		return nil, fmt.Errorf("metadata.resources.%w", err)
+	}
+
 	switch metadata.Priority {
 	case "", PriorityHigh, PriorityNormal, PriorityLow:
 	default:
@@ -1084,6 +1386,10 @@ func validateSchedulingMetadata(metadata *Metadata) (map[string]struct{}, error)
 		}
 	}
 
+	if err := metadata.Retention.Validate(); err != nil {
+		return nil, fmt.Errorf("metadata.retention: %w", err)
+	}
+
 	resources := make(map[string]struct{}, len(metadata.RateLimits))
 	for i := range metadata.RateLimits {
 		limit := &metadata.RateLimits[i]
@@ -1490,6 +1796,12 @@ func validateSteps(steps []Step, volumes map[string]*Volume, rateLimitResources
 	if err := validateStepRateLimits(steps, rateLimitResources); err != nil {
 		return err
 	}
+	if err := validateStepPools(steps); err != nil {
+		return err
+	}
+	if err := validateStepNodeAffinity(steps); err != nil {
+		return err
+	}
 	if err := detectCycles(adj, names); err != nil {
 		return err
 	}
@@ -1506,6 +1818,86 @@ func validateSteps(steps []Step, volumes map[string]*Volume, rateLimitResources
 	if err := validateSchemas(steps, names, predecessors); err != nil {
 		return err
 	}
+	if err := validateStepMaps(steps, predecessors); err != nil {
+		return err
+	}
+	if err := validateStepGates(steps); err != nil {
+		return err
+	}
+	return validateStepSensors(steps, volumes)
+}
+
+func validateStepGates(steps []Step) error {
+	for i := range steps {
+		gate := steps[i].Gate
+		if gate == nil {
+			continue
+		}
+		gate.Type = strings.TrimSpace(gate.Type)
+		if gate.Type == "" {
+			gate.Type = GateTypeApproval
+		}
+		if gate.Type != GateTypeApproval {
+			return fmt.Errorf("steps[%d].gate.type %q is not supported (want %q)", i, gate.Type, GateTypeApproval)
+		}
+		approvers := make([]string, 0, len(gate.Approvers))
+		for j, approver := range gate.Approvers {
+			approver = strings.TrimPrefix(strings.TrimSpace(approver), "@")
+			if approver == "" {
+				return fmt.Errorf("steps[%d].gate.approvers[%d] must not be empty", i, j)
+			}
+			approvers = append(approvers, approver)
+		}
+		gate.Approvers = approvers
+		if gate.Timeout < 0 {
+			return fmt.Errorf("steps[%d].gate.timeout must be >= 0", i)
+		}
+		gate.OnTimeout = strings.TrimSpace(gate.OnTimeout)
+		switch gate.OnTimeout {
+		case "":
+			gate.OnTimeout = GateOnTimeoutFail
+		case GateOnTimeoutFail, GateOnTimeoutSkip:
+		default:
+			return fmt.Errorf("steps[%d].gate.onTimeout %q must be %q or %q", i, gate.OnTimeout, GateOnTimeoutFail, GateOnTimeoutSkip)
+		}
+	}
+	return nil
+}
+
+func validateStepMaps(steps []Step, predecessors map[string]map[string]struct{}) error {
+	mapped := make(map[string]struct{})
+	for i := range steps {
+		if steps[i].Map != nil {
+			mapped[steps[i].Name] = struct{}{}
+		}
+	}
+	for i := range steps {
+		step := &steps[i]
+		if step.Map == nil {
+			continue
+		}
+		step.Map.Over = strings.TrimSpace(step.Map.Over)
+		if step.Map.Over == "" {
+			return fmt.Errorf("steps[%d].map.over is required when map is set", i)
+		}
+		if step.Type == StepTypeBranch {
+			return fmt.Errorf("steps[%d].map is not supported on branch steps", i)
+		}
+		if step.Map.MaxParallel < 0 {
+			return fmt.Errorf("steps[%d].map.maxParallel must be >= 0", i)
+		}
+		preds := make([]string, 0, len(predecessors[step.Name]))
+		for name := range predecessors[step.Name] {
+			preds = append(preds, name)
+		}
+		source, _, ok := step.Map.Source(preds)
+		if !ok {
+			return fmt.Errorf("steps[%d].map.over %q must be \"<step>.<output key>\" naming a direct predecessor of %q", i, step.Map.Over, step.Name)
+		}
+		if _, chained := mapped[source]; chained {
+			return fmt.Errorf("steps[%d].map.over %q references mapped step %q; mapped steps cannot be chained", i, step.Map.Over, source)
+		}
+	}
 	return nil
 }
 
@@ -1533,6 +1925,29 @@ func validateStepRateLimits(steps []Step, resources map[string]struct{}) error {
 	return nil
 }
 
+func validateStepPools(steps []Step) error {
+	for i := range steps {
+		pool := steps[i].Pool
+		if pool == nil {
+			continue
+		}
+		pool.Name = strings.TrimSpace(pool.Name)
+		if pool.Name == "" {
+			return fmt.Errorf("steps[%d].pool.name is required when pool is set", i)
+		}
+		if !ValidPoolName(pool.Name) {
+			return fmt.Errorf("steps[%d].pool.name %q must be lowercase alphanumerics separated by '-', '_' or '.' (max %d characters)", i, pool.Name, MaxPoolNameLength)
+		}
+		if pool.Slots < 0 {
+			return fmt.Errorf("steps[%d].pool.slots must be >= 0", i)
+		}
+		if pool.Slots == 0 {
+			pool.Slots = 1
+		}
+	}
+	return nil
+}
+
 func validateStepVolumeMounts(steps []Step, volumes map[string]*Volume) error {
 	for i := range steps {
 		step := &steps[i]
@@ -1669,6 +2084,8 @@ func (d *Definition) RuntimeSpecForStep(step *Step) (container.Spec, error) {
 		spec.ResolvedVolumeMounts = append(spec.ResolvedVolumeMounts, resolved)
 	}
 
+	spec.Resources = d.Metadata.Resources.Merge(step.Resources)
+
 	if step.Engine == EngineKubernetes {
 		k8sSpec := &container.KubernetesSpec{
 			ServiceAccountName:           strings.TrimSpace(d.Metadata.ServiceAccountName),
@@ -1780,6 +2197,10 @@ func cloneContainerSpec(spec container.Spec) container.Spec {
 			QueueName:                    spec.Kubernetes.QueueName,
 		}
 	}
+	if spec.Resources != nil {
+		resources := *spec.Resources
+		out.Resources = &resources
+	}
 	return out
 }
 
@@ -1860,7 +2281,10 @@ func computeStepAdjacency(steps []Step) (map[string]int, map[string]map[string]s
 		}
 		names[step.Name] = i
 
-		if strings.TrimSpace(step.Image) == "" {
+		if uses := strings.TrimSpace(step.Uses); uses != "" {
+			return nil, nil, fmt.Errorf("steps[%d].uses %q was not expanded; load the StepTemplate alongside the job definition", i, uses)
+		}
+		if strings.TrimSpace(step.Image) == "" && step.Type != StepTypeSensor {
 			return nil, nil, fmt.Errorf("steps[%d].image is required", i)
 		}
 		switch step.Engine {
@@ -1870,9 +2294,9 @@ func computeStepAdjacency(steps []Step) (map[string]int, map[string]map[string]s
 		}
 
 		switch step.Type {
-		case StepTypeTask, StepTypeBranch:
+		case StepTypeTask, StepTypeBranch, StepTypeSensor:
 		default:
-			return nil, nil, fmt.Errorf("steps[%d].type %q must be one of [%s,%s]", i, step.Type, StepTypeTask, StepTypeBranch)
+			return nil, nil, fmt.Errorf("steps[%d].type %q must be one of [%s,%s,%s]", i, step.Type, StepTypeTask, StepTypeBranch, StepTypeSensor)
 		}
 		if step.Type == StepTypeBranch && len(step.Next) == 0 {
 			return nil, nil, fmt.Errorf("steps[%d] is a branch step and must have at least one next entry", i)
@@ -1893,6 +2317,10 @@ func computeStepAdjacency(steps []Step) (map[string]int, map[string]map[string]s
 					i, rule, TriggerRuleAllSuccess, TriggerRuleAllDone, TriggerRuleAllFailed, TriggerRuleOneSuccess, TriggerRuleAlways)
 			}
 		}
+
+		if err := step.Resources.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("steps[%d].resources.%w", i, err)
+		}
 	}
 
 	hasExplicitEdges := false
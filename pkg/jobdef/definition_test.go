@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/caesium-cloud/caesium/pkg/container"
 	"github.com/stretchr/testify/require"
@@ -255,6 +256,41 @@ steps:
 	require.Equal(t, 1, def.Steps[0].RateLimit.Units)
 }
 
+func TestParseStepPool(t *testing.T) {
+	src := `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: pooled
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: load
+    image: alpine:3.23
+    pool: {name: " warehouse ", slots: 2}
+    next: audit
+  - name: audit
+    image: alpine:3.23
+    pool: {name: warehouse}
+`
+	def, err := Parse([]byte(src))
+	require.NoError(t, err)
+	require.Equal(t, &StepPool{Name: "warehouse", Slots: 2}, def.Steps[0].Pool)
+	require.Equal(t, &StepPool{Name: "warehouse", Slots: 1}, def.Steps[1].Pool)
+
+	for name, pool := range map[string]string{
+		"missing name":   `{slots: 1}`,
+		"invalid name":   `{name: Warehouse}`,
+		"negative slots": `{name: warehouse, slots: -1}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(strings.Replace(src, `{name: " warehouse ", slots: 2}`, pool, 1)))
+			require.ErrorContains(t, err, "steps[0].pool")
+		})
+	}
+}
+
 func TestParseInvalidDefinitions(t *testing.T) {
 	cases := map[string]string{
 		"bad version": `apiVersion: v2
@@ -783,6 +819,84 @@ steps:
 	}
 }
 
+// TestStepResourcesMergeJobDefaults asserts metadata.resources apply to every
+// step and a step's own resources override them field by field.
+func TestStepResourcesMergeJobDefaults(t *testing.T) {
+	src := `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: sized
+  resources:
+    cpu: "1"
+    memory: 1Gi
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: default
+    image: alpine:3.23
+  - name: big
+    engine: kubernetes
+    image: alpine:3.23
+    resources:
+      memory: 8Gi
+      ephemeralStorage: 20Gi
+      gpu-count: 2
+`
+	def, err := Parse([]byte(src))
+	require.NoError(t, err)
+
+	defaultSpec, err := def.RuntimeSpecForStep(&def.Steps[0])
+	require.NoError(t, err)
+	require.Equal(t, &container.Resources{CPU: "1", Memory: "1Gi"}, defaultSpec.Resources)
+
+	bigSpec, err := def.RuntimeSpecForStep(&def.Steps[1])
+	require.NoError(t, err)
+	require.Equal(t, &container.Resources{CPU: "1", Memory: "8Gi", EphemeralStorage: "20Gi", GPUCount: 2}, bigSpec.Resources)
+	require.Equal(t, "1Gi", def.Metadata.Resources.Memory, "step overrides must not leak into job defaults")
+}
+
+func TestStepResourcesRejectInvalidQuantities(t *testing.T) {
+	cases := map[string]string{
+		"steps[0].resources.cpu":              "    resources: {cpu: lots}\n",
+		"steps[0].resources.memory":           "    resources: {memory: 1Zi}\n",
+		"steps[0].resources.ephemeralStorage": "    resources: {ephemeralStorage: \"0\"}\n",
+		"steps[0].resources.gpu-count":        "    resources: {gpu-count: -1}\n",
+	}
+	for want, resources := range cases {
+		src := `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: bad-resources
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: build
+    image: alpine:3.23
+` + resources
+		_, err := Parse([]byte(src))
+		require.ErrorContains(t, err, want)
+	}
+
+	_, err := Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: bad-default
+  resources: {memory: lots}
+trigger:
+  type: cron
+  configuration: {cron: "0 * * * *"}
+steps:
+  - name: build
+    image: alpine:3.23
+`))
+	require.ErrorContains(t, err, "metadata.resources.memory")
+}
+
 func TestLegacyMountRelativeTargetStillValidates(t *testing.T) {
 	src := `
 apiVersion: v1
@@ -863,6 +977,82 @@ func TestStepUnmarshalJSONIncludesVolumeAndIdentityFields(t *testing.T) {
 	require.Equal(t, []VolumeMount{{Volume: "work", Path: "/work"}}, step.VolumeMounts)
 }
 
+func TestValidateNamespace(t *testing.T) {
+	doc := func(namespace string) string {
+		return `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: nightly
+` + namespace + `
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+steps:
+  - name: wait
+    type: sensor
+    sensor: {job: {alias: upstream}}
+  - name: run
+    image: alpine:3.23
+`
+	}
+
+	def, err := Parse([]byte(doc("")))
+	require.NoError(t, err)
+	require.Equal(t, DefaultNamespace, def.Metadata.Namespace)
+	require.Equal(t, DefaultNamespace, def.Steps[0].Sensor.Job.Namespace)
+	require.Equal(t, "nightly", QualifiedAlias(def.Metadata.Namespace, def.Metadata.Alias))
+
+	def, err = Parse([]byte(doc("  namespace: team-a")))
+	require.NoError(t, err)
+	require.Equal(t, "team-a", def.Metadata.Namespace)
+	require.Equal(t, "team-a", def.Steps[0].Sensor.Job.Namespace, "sensor jobs default to the sensing job's namespace")
+	require.Equal(t, "team-a/nightly", QualifiedAlias(def.Metadata.Namespace, def.Metadata.Alias))
+
+	namespace, alias := SplitQualifiedAlias("team-a/nightly")
+	require.Equal(t, "team-a", namespace)
+	require.Equal(t, "nightly", alias)
+	namespace, alias = SplitQualifiedAlias("nightly")
+	require.Equal(t, DefaultNamespace, namespace)
+	require.Equal(t, "nightly", alias)
+
+	for _, bad := range []string{"Team-A", "team_a", "-team", strings.Repeat("a", MaxNamespaceLength+1)} {
+		_, err = Parse([]byte(doc("  namespace: " + bad)))
+		require.ErrorContains(t, err, "metadata.namespace", bad)
+	}
+}
+
+func TestValidateRetention(t *testing.T) {
+	doc := func(retention string) string {
+		return `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: nightly
+  retention: ` + retention + `
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+steps:
+  - name: run
+    image: alpine:3.23
+`
+	}
+
+	def, err := Parse([]byte(doc("{keepRuns: 50, keepDays: 7, keepFailedDays: 30}")))
+	require.NoError(t, err)
+	require.Equal(t, &Retention{KeepRuns: 50, KeepDays: 7, KeepFailedDays: 30}, def.Metadata.Retention)
+
+	for retention, want := range map[string]string{
+		"{keepRuns: -1}":                    "keepRuns, keepDays, and keepFailedDays must be >= 0",
+		"{keepFailedDays: 30}":              "keepRuns or keepDays must be set",
+		"{keepDays: 30, keepFailedDays: 7}": "keepFailedDays must be >= keepDays",
+	} {
+		_, err = Parse([]byte(doc(retention)))
+		require.ErrorContains(t, err, "metadata.retention: "+want, retention)
+	}
+}
+
 func TestValidateSimpleJSONPath(t *testing.T) {
 	t.Parallel()
 
@@ -1090,3 +1280,229 @@ func TestMarshalPreservesFalseCacheOverrides(t *testing.T) {
 		t.Fatalf("step.cache not preserved as false: %s", string(body))
 	}
 }
+
+func TestParseStepMap(t *testing.T) {
+	def, err := Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: mapped
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+steps:
+  - name: list.files
+    image: alpine:3.23
+  - name: process
+    image: alpine:3.23
+    map:
+      over: " list.files.paths "
+      maxParallel: 4
+`))
+	require.NoError(t, err)
+	require.Equal(t, &StepMap{Over: "list.files.paths", MaxParallel: 4}, def.Steps[1].Map)
+
+	step, key, ok := def.Steps[1].Map.Source([]string{"list", "list.files"})
+	require.True(t, ok)
+	require.Equal(t, "list.files", step, "the longest matching step name wins")
+	require.Equal(t, "paths", key)
+}
+
+func TestValidateStepMapRejects(t *testing.T) {
+	base := func(extra string) string {
+		return `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: mapped
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+steps:
+  - name: list
+    image: alpine:3.23
+` + extra
+	}
+	cases := map[string]string{
+		"steps[1].map.over is required when map is set": `
+  - name: process
+    image: alpine:3.23
+    map: {maxParallel: 2}
+`,
+		"steps[1].map.maxParallel must be >= 0": `
+  - name: process
+    image: alpine:3.23
+    map: {over: list.paths, maxParallel: -1}
+`,
+		`steps[1].map.over "other.paths" must be "<step>.<output key>" naming a direct predecessor of "process"`: `
+  - name: process
+    image: alpine:3.23
+    map: {over: other.paths}
+`,
+		`steps[1].map.over "list" must be "<step>.<output key>"`: `
+  - name: process
+    image: alpine:3.23
+    map: {over: list}
+`,
+		`steps[2].map.over "process.paths" references mapped step "process"; mapped steps cannot be chained`: `
+  - name: process
+    image: alpine:3.23
+    map: {over: list.paths}
+  - name: again
+    image: alpine:3.23
+    map: {over: process.paths}
+`,
+	}
+	for want, extra := range cases {
+		_, err := Parse([]byte(base(extra)))
+		require.ErrorContains(t, err, want)
+	}
+}
+
+func TestParseStepGate(t *testing.T) {
+	def, err := Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: gated
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+steps:
+  - name: validate
+    image: alpine:3.23
+  - name: deploy
+    image: alpine:3.23
+    gate:
+      approvers: ["@data-leads", " release "]
+      timeout: 4h
+  - name: announce
+    image: alpine:3.23
+    gate: {type: approval, onTimeout: skip}
+`))
+	require.NoError(t, err)
+	require.Equal(t, &StepGate{
+		Type:      GateTypeApproval,
+		Approvers: []string{"data-leads", "release"},
+		Timeout:   4 * time.Hour,
+		OnTimeout: GateOnTimeoutFail,
+	}, def.Steps[1].Gate)
+	require.Equal(t, &StepGate{Type: GateTypeApproval, Approvers: []string{}, OnTimeout: GateOnTimeoutSkip}, def.Steps[2].Gate)
+}
+
+func TestValidateStepGateRejects(t *testing.T) {
+	base := func(gate string) string {
+		return `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: gated
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+steps:
+  - name: deploy
+    image: alpine:3.23
+    gate: ` + gate + "\n"
+	}
+	cases := map[string]string{
+		`steps[0].gate.type "manual" is not supported (want "approval")`: `{type: manual}`,
+		"steps[0].gate.approvers[1] must not be empty":                   `{approvers: [leads, "@"]}`,
+		"steps[0].gate.timeout must be >= 0":                             `{timeout: -1m}`,
+		`steps[0].gate.onTimeout "retry" must be "fail" or "skip"`:       `{onTimeout: retry}`,
+	}
+	for want, gate := range cases {
+		_, err := Parse([]byte(base(gate)))
+		require.ErrorContains(t, err, want)
+	}
+}
+
+func TestParseStepSensor(t *testing.T) {
+	def, err := Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: sensed
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+volumes:
+  - name: landing
+    source: {bind: /srv/landing}
+steps:
+  - name: wait-api
+    type: sensor
+    sensor:
+      http: {url: "https://example.com/ready?date={{ ds .LogicalDate }}"}
+  - name: wait-upstream
+    type: sensor
+    sensor:
+      job: {alias: nightly-extract, sameLogicalDate: true}
+      pokeInterval: 5m
+      timeout: 2h
+      mode: reschedule
+      onTimeout: skip
+  - name: wait-drop
+    type: sensor
+    sensor:
+      file: {volume: landing, path: "drops/{{ ds_nodash .LogicalDate }}.csv"}
+  - name: load
+    image: alpine:3.23
+    dependsOn: [wait-api, wait-upstream, wait-drop]
+`))
+	require.NoError(t, err)
+	require.Equal(t, StepTypeSensor, def.Steps[0].Type)
+	require.Equal(t, &StepSensor{
+		HTTP:         &SensorHTTP{URL: "https://example.com/ready?date={{ ds .LogicalDate }}", Method: "GET", ExpectStatus: []int{200}},
+		PokeInterval: DefaultSensorPokeInterval,
+		Mode:         SensorModePoke,
+		OnTimeout:    SensorOnTimeoutFail,
+	}, def.Steps[0].Sensor)
+	require.Equal(t, &StepSensor{
+		Job:          &SensorJob{Alias: "nightly-extract", Namespace: DefaultNamespace, SameLogicalDate: true},
+		PokeInterval: 5 * time.Minute,
+		Timeout:      2 * time.Hour,
+		Mode:         SensorModeReschedule,
+		OnTimeout:    SensorOnTimeoutSkip,
+	}, def.Steps[1].Sensor)
+	require.Equal(t, SensorProbeFile, def.Steps[2].Sensor.Probe())
+	require.Equal(t, "/srv/landing", def.Steps[2].Sensor.File.HostPath)
+}
+
+func TestValidateStepSensorRejects(t *testing.T) {
+	base := func(step string) string {
+		return `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: sensed
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+volumes:
+  - name: scratch
+    source: {volume: scratch}
+steps:
+  - name: wait
+` + step + "\n"
+	}
+	cases := map[string]string{
+		`steps[0].sensor requires type "sensor"`:                                             "    image: alpine:3.23\n    sensor: {job: {alias: up}}",
+		"steps[0] is a sensor step and requires a sensor block":                              "    type: sensor",
+		"steps[0].command is not supported on sensor steps":                                  "    type: sensor\n    command: [echo]\n    sensor: {job: {alias: up}}",
+		"steps[0].sensor must set exactly one of [http,job,dataset,file], got 2":             "    type: sensor\n    sensor: {job: {alias: up}, dataset: {name: orders}}",
+		"steps[0].sensor must set exactly one of [http,job,dataset,file], got 0":             "    type: sensor\n    sensor: {timeout: 1h}",
+		`steps[0].sensor.mode "wait" must be "poke" or "reschedule"`:                         "    type: sensor\n    sensor: {job: {alias: up}, mode: wait}",
+		"steps[0].sensor.timeout must be >= 0":                                               "    type: sensor\n    sensor: {job: {alias: up}, timeout: -1m}",
+		`steps[0].sensor.http.url "ftp://example.com" must be an absolute http or https URL`: "    type: sensor\n    sensor: {http: {url: \"ftp://example.com\"}}",
+		`steps[0].sensor.http.method "POST" must be GET or HEAD`:                             "    type: sensor\n    sensor: {http: {url: \"https://example.com\", method: post}}",
+		"steps[0].sensor.job.alias is required":                                              "    type: sensor\n    sensor: {job: {maxAge: 1h}}",
+		"steps[0].sensor.dataset.name is required":                                           "    type: sensor\n    sensor: {dataset: {watermark: x}}",
+		`steps[0].sensor.file references unknown volume "landing"`:                           "    type: sensor\n    sensor: {file: {volume: landing, path: a.csv}}",
+		`steps[0].sensor.file.volume "scratch" must have an absolute bind source`:            "    type: sensor\n    sensor: {file: {volume: scratch, path: a.csv}}",
+	}
+	for want, step := range cases {
+		_, err := Parse([]byte(base(step)))
+		require.ErrorContains(t, err, want)
+	}
+}
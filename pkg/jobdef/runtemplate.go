@@ -0,0 +1,215 @@
+package jobdef
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Run parameters carrying a run's scheduled time. Cron, catch-up, and backfill
+// runs set all three as RFC 3339 UTC timestamps; data_interval_end equals
+// logical_date and data_interval_start is the schedule's previous fire time.
+const (
+	ParamLogicalDate       = "logical_date"
+	ParamDataIntervalStart = "data_interval_start"
+	ParamDataIntervalEnd   = "data_interval_end"
+)
+
+// RunTemplateData is the data available to run-time templates in step
+// `command` and `env` values and trigger `defaultParams`.
+type RunTemplateData struct {
+	Params            map[string]string
+	LogicalDate       time.Time
+	DataIntervalStart time.Time
+	DataIntervalEnd   time.Time
+	RunID             string
+	JobAlias          string
+}
+
+// NewRunTemplateData builds template data from a run's parameters. Runs
+// without a logical_date param (manual, HTTP, and event runs) use fallback,
+// normally the run's start time, truncated to the second so every node
+// renders the same value; a missing data interval collapses onto the logical
+// date.
+func NewRunTemplateData(runID, jobAlias string, params map[string]string, fallback time.Time) RunTemplateData {
+	data := RunTemplateData{
+		Params:      params,
+		LogicalDate: fallback.UTC().Truncate(time.Second),
+		RunID:       runID,
+		JobAlias:    jobAlias,
+	}
+	if data.Params == nil {
+		data.Params = map[string]string{}
+	}
+	if t, ok := parseRunTime(params[ParamLogicalDate]); ok {
+		data.LogicalDate = t
+	}
+	data.DataIntervalEnd = data.LogicalDate
+	if t, ok := parseRunTime(params[ParamDataIntervalEnd]); ok {
+		data.DataIntervalEnd = t
+	}
+	data.DataIntervalStart = data.DataIntervalEnd
+	if t, ok := parseRunTime(params[ParamDataIntervalStart]); ok {
+		data.DataIntervalStart = t
+	}
+	return data
+}
+
+// runTemplateFuncs extends the StepTemplate function set with date helpers.
+// Every function is pure, so rendering the same run twice yields the same
+// values.
+var runTemplateFuncs = template.FuncMap{
+	"default":   templateFuncs["default"],
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"ds":        func(v any) (string, error) { return formatRunTime(v, 0, time.DateOnly) },
+	"ds_nodash": func(v any) (string, error) { return formatRunTime(v, 0, "20060102") },
+	"ds_add":    func(v any, days int) (string, error) { return formatRunTime(v, days, time.DateOnly) },
+	"ts":        func(v any) (string, error) { return formatRunTime(v, 0, time.RFC3339) },
+}
+
+// ValidateRunTemplate reports whether value parses as a run-time template.
+// Values without "{{" are plain strings and always valid.
+func ValidateRunTemplate(field, value string) error {
+	if !strings.Contains(value, "{{") {
+		return nil
+	}
+	if _, err := parseRunTemplate(field, value); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// RenderRunTemplate renders one value against data. Values without "{{" are
+// returned unchanged, and references to missing params are errors.
+func RenderRunTemplate(field, value string, data RunTemplateData) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	tmpl, err := parseRunTemplate(field, value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", field, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%s: %w", field, err)
+	}
+	return out.String(), nil
+}
+
+// RenderRunCommand renders each command argument against data.
+func RenderRunCommand(command []string, data RunTemplateData) ([]string, error) {
+	if len(command) == 0 {
+		return command, nil
+	}
+	out := make([]string, len(command))
+	for i, arg := range command {
+		rendered, err := RenderRunTemplate(fmt.Sprintf("command[%d]", i), arg, data)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = rendered
+	}
+	return out, nil
+}
+
+// RenderRunValues renders the values (never the keys) of an env or params map
+// against data. field prefixes error messages, e.g. "env".
+func RenderRunValues(field string, values map[string]string, data RunTemplateData) (map[string]string, error) {
+	if len(values) == 0 {
+		return values, nil
+	}
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		rendered, err := RenderRunTemplate(fmt.Sprintf("%s[%q]", field, key), value, data)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = rendered
+	}
+	return out, nil
+}
+
+func parseRunTemplate(field, value string) (*template.Template, error) {
+	return template.New(field).Funcs(runTemplateFuncs).Option("missingkey=error").Parse(value)
+}
+
+// validateRunTemplates parses every templated step command and env value and
+// trigger defaultParams value, so syntax errors and unknown functions fail at
+// lint time rather than when a run starts. Jobs without metadata.runTemplates
+// are not rendered, so their values are left as written.
+func validateRunTemplates(d *Definition) error {
+	if !d.Metadata.RunTemplates {
+		return nil
+	}
+	if err := validateRunTemplateValues("trigger.defaultParams", d.Trigger.DefaultParams); err != nil {
+		return err
+	}
+	if params, ok := d.Trigger.Configuration["defaultParams"].(map[string]any); ok {
+		for _, key := range slices.Sorted(maps.Keys(params)) {
+			value, _ := params[key].(string)
+			if err := ValidateRunTemplate(fmt.Sprintf("trigger.configuration.defaultParams[%q]", key), value); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range d.Steps {
+		step := &d.Steps[i]
+		for j, arg := range step.Command {
+			if err := ValidateRunTemplate(fmt.Sprintf("steps[%d].command[%d]", i, j), arg); err != nil {
+				return err
+			}
+		}
+		if err := validateRunTemplateValues(fmt.Sprintf("steps[%d].env", i), step.Env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRunTemplateValues(field string, values map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if err := ValidateRunTemplate(fmt.Sprintf("%s[%q]", field, key), values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatRunTime shifts a time by days and formats it in UTC. v may be a
+// time.Time or a string holding an RFC 3339 timestamp or a YYYY-MM-DD date,
+// so helpers work on params as well as the typed fields.
+func formatRunTime(v any, days int, layout string) (string, error) {
+	var t time.Time
+	switch value := v.(type) {
+	case time.Time:
+		t = value
+	case string:
+		parsed, ok := parseRunTime(value)
+		if !ok {
+			return "", fmt.Errorf("cannot parse %q as a date", value)
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("expected a date, got %T", v)
+	}
+	return t.UTC().AddDate(0, 0, days).Format(layout), nil
+}
+
+func parseRunTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
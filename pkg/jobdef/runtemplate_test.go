@@ -0,0 +1,141 @@
+package jobdef
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunTemplateData(t *testing.T) {
+	fallback := time.Date(2026, 5, 4, 3, 2, 1, 500, time.UTC)
+
+	data := NewRunTemplateData("run-1", "etl", map[string]string{
+		ParamLogicalDate:       "2026-03-31T06:00:00Z",
+		ParamDataIntervalStart: "2026-03-30T06:00:00Z",
+		ParamDataIntervalEnd:   "2026-03-31T06:00:00Z",
+	}, fallback)
+	require.Equal(t, time.Date(2026, 3, 31, 6, 0, 0, 0, time.UTC), data.LogicalDate)
+	require.Equal(t, time.Date(2026, 3, 30, 6, 0, 0, 0, time.UTC), data.DataIntervalStart)
+	require.Equal(t, data.LogicalDate, data.DataIntervalEnd)
+
+	// Without a logical date the fallback is used and the interval is empty.
+	data = NewRunTemplateData("run-1", "etl", nil, fallback)
+	require.Equal(t, time.Date(2026, 5, 4, 3, 2, 1, 0, time.UTC), data.LogicalDate)
+	require.Equal(t, data.LogicalDate, data.DataIntervalStart)
+	require.Equal(t, data.LogicalDate, data.DataIntervalEnd)
+	require.NotNil(t, data.Params)
+}
+
+func TestRenderRunTemplate(t *testing.T) {
+	data := NewRunTemplateData("run-1", "etl", map[string]string{
+		"region":         "eu-west-1",
+		ParamLogicalDate: "2026-03-01T06:00:00Z",
+	}, time.Time{})
+
+	cases := map[string]string{
+		"plain":                                     "plain",
+		"{{ .Params.region | upper }}":              "EU-WEST-1",
+		"{{ ds .LogicalDate }}":                     "2026-03-01",
+		"{{ ds_nodash .LogicalDate }}":              "20260301",
+		"{{ ds_add .LogicalDate -1 }}":              "2026-02-28",
+		"{{ ds_add .Params.logical_date 1 }}":       "2026-03-02",
+		"{{ ts .DataIntervalEnd }}":                 "2026-03-01T06:00:00Z",
+		`{{ default "us" (index .Params "zone") }}`: "us",
+		"{{ .JobAlias }}/{{ .RunID }}":              "etl/run-1",
+		`{{ "{{" }} literal }}`:                     "{{ literal }}",
+	}
+	for tmpl, want := range cases {
+		got, err := RenderRunTemplate("command[0]", tmpl, data)
+		require.NoError(t, err, tmpl)
+		require.Equal(t, want, got, tmpl)
+	}
+
+	_, err := RenderRunTemplate("command[0]", "{{ .Params.missing }}", data)
+	require.ErrorContains(t, err, "command[0]")
+	_, err = RenderRunTemplate("command[0]", "{{ ds .Params.region }}", data)
+	require.ErrorContains(t, err, `cannot parse "eu-west-1" as a date`)
+}
+
+func TestRenderRunCommandAndValues(t *testing.T) {
+	data := NewRunTemplateData("run-1", "etl", map[string]string{"table": "orders"}, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	command, err := RenderRunCommand([]string{"load", "--table", "{{ .Params.table }}", "--date", "{{ ds .LogicalDate }}"}, data)
+	require.NoError(t, err)
+	require.Equal(t, []string{"load", "--table", "orders", "--date", "2026-01-02"}, command)
+
+	env, err := RenderRunValues("env", map[string]string{"TARGET": "s3://lake/{{ .Params.table }}", "{{ KEY }}": "raw"}, data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"TARGET": "s3://lake/orders", "{{ KEY }}": "raw"}, env)
+
+	_, err = RenderRunValues("env", map[string]string{"TARGET": "{{ .Params.nope }}"}, data)
+	require.ErrorContains(t, err, `env["TARGET"]`)
+}
+
+func TestValidateRunTemplatesRejectsBadTemplates(t *testing.T) {
+	base := func(extra string) string {
+		return `
+apiVersion: v1
+kind: Job
+metadata:
+  alias: templated
+  runTemplates: true
+trigger:
+  type: cron
+  configuration: {cron: "0 2 * * *"}
+` + extra
+	}
+	cases := map[string]string{
+		`steps[0].command[1]`: `steps:
+  - name: load
+    image: alpine:3.23
+    command: ["echo", "{{ nope .LogicalDate }}"]
+`,
+		`steps[0].env["DAY"]`: `steps:
+  - name: load
+    image: alpine:3.23
+    env: {DAY: "{{ ds .LogicalDate"}
+`,
+		`trigger.defaultParams["day"]`: `  defaultParams: {day: "{{ end }}"}
+steps:
+  - name: load
+    image: alpine:3.23
+`,
+	}
+	for want, extra := range cases {
+		_, err := Parse([]byte(base(extra)))
+		require.ErrorContains(t, err, want)
+	}
+
+	_, err := Parse([]byte(base(`steps:
+  - name: load
+    image: alpine:3.23
+    command: ["echo", "{{ ds_add .LogicalDate -1 }}"]
+`)))
+	require.NoError(t, err)
+}
+
+func TestValidateRunTemplatesIgnoresJobsWithoutOptIn(t *testing.T) {
+	def, err := Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: dbt-models
+trigger:
+  type: cron
+  configuration:
+    cron: "0 2 * * *"
+    defaultParams: {select: "{{ config.get('tag') }}"}
+  defaultParams: {target: "{{ env_var('DBT_TARGET') }}"}
+steps:
+  - name: run
+    image: ghcr.io/dbt-labs/dbt-core:1.8.0
+    command: ["dbt", "run", "--vars", "{day: '{{ var(\"day\") }}'}"]
+    env: {SELECTOR: "{{ ref('orders') }}"}
+`))
+	require.NoError(t, err)
+	require.False(t, def.Metadata.RunTemplates)
+	require.Equal(t, []string{"dbt", "run", "--vars", `{day: '{{ var("day") }}'}`}, def.Steps[0].Command)
+	require.Equal(t, "{{ ref('orders') }}", def.Steps[0].Env["SELECTOR"])
+	require.Equal(t, "{{ env_var('DBT_TARGET') }}", def.Trigger.DefaultParams["target"])
+}
@@ -0,0 +1,286 @@
+package jobdef
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sensor modes and timeout outcomes accepted on a sensor step's sensor block.
+const (
+	SensorModePoke       = "poke"
+	SensorModeReschedule = "reschedule"
+	SensorOnTimeoutFail  = "fail"
+	SensorOnTimeoutSkip  = "skip"
+)
+
+// Sensor probe kinds, one per sensor sub-block.
+const (
+	SensorProbeHTTP    = "http"
+	SensorProbeJob     = "job"
+	SensorProbeDataset = "dataset"
+	SensorProbeFile    = "file"
+)
+
+// DefaultSensorPokeInterval is the wait between pokes when a sensor sets no
+// pokeInterval.
+const DefaultSensorPokeInterval = time.Minute
+
+// StepSensor waits for an external condition before a sensor step succeeds.
+// Exactly one probe (HTTP, Job, Dataset, or File) is set. Caesium evaluates
+// the probe itself every PokeInterval, so a sensor never starts a container.
+// In poke mode the step holds its worker claim between pokes; in reschedule
+// mode it releases the claim and is re-queued when the next poke is due. A
+// zero Timeout waits indefinitely; otherwise OnTimeout decides whether a
+// sensor whose condition never held fails (the default) or is skipped. The
+// timeout is measured from the step's first poke in the run.
+type StepSensor struct {
+	HTTP         *SensorHTTP    `yaml:"http,omitempty" json:"http,omitempty"`
+	Job          *SensorJob     `yaml:"job,omitempty" json:"job,omitempty"`
+	Dataset      *SensorDataset `yaml:"dataset,omitempty" json:"dataset,omitempty"`
+	File         *SensorFile    `yaml:"file,omitempty" json:"file,omitempty"`
+	PokeInterval time.Duration  `yaml:"pokeInterval,omitempty" json:"pokeInterval,omitempty"`
+	Timeout      time.Duration  `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Mode         string         `yaml:"mode,omitempty" json:"mode,omitempty"`
+	OnTimeout    string         `yaml:"onTimeout,omitempty" json:"onTimeout,omitempty"`
+}
+
+// Probe returns the kind of the sensor's configured probe.
+func (s StepSensor) Probe() string {
+	switch {
+	case s.HTTP != nil:
+		return SensorProbeHTTP
+	case s.Job != nil:
+		return SensorProbeJob
+	case s.Dataset != nil:
+		return SensorProbeDataset
+	case s.File != nil:
+		return SensorProbeFile
+	}
+	return ""
+}
+
+// SensorHTTP is satisfied when a request to URL answers with one of
+// ExpectStatus (default 200). URL and header values may use run templates.
+type SensorHTTP struct {
+	URL          string            `yaml:"url" json:"url"`
+	Method       string            `yaml:"method,omitempty" json:"method,omitempty"`
+	Headers      map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	ExpectStatus []int             `yaml:"expectStatus,omitempty" json:"expectStatus,omitempty"`
+}
+
+// SensorJob is satisfied by a successful run of the job with Alias in
+// Namespace, which defaults to the sensing job's own namespace. MaxAge
+// requires the run to have completed within that window; SameLogicalDate
+// requires its logical_date to match this run's.
+type SensorJob struct {
+	Alias           string        `yaml:"alias" json:"alias"`
+	Namespace       string        `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	MaxAge          time.Duration `yaml:"maxAge,omitempty" json:"maxAge,omitempty"`
+	SameLogicalDate bool          `yaml:"sameLogicalDate,omitempty" json:"sameLogicalDate,omitempty"`
+}
+
+// SensorDataset is satisfied once the named dataset's watermark reaches
+// Watermark (equal, or greater for orderable values), or, without a
+// Watermark, once the dataset is fresh. Watermark may use run templates.
+type SensorDataset struct {
+	Name      string `yaml:"name" json:"name"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Watermark string `yaml:"watermark,omitempty" json:"watermark,omitempty"`
+}
+
+// SensorFile is satisfied once Path exists under the bind source of the job
+// volume named Volume. Path is relative to the volume root and may use run
+// templates. HostPath is the bind source resolved during validation; it is
+// persisted with the task and is not set in manifests.
+type SensorFile struct {
+	Volume   string `yaml:"volume" json:"volume"`
+	Path     string `yaml:"path" json:"path"`
+	HostPath string `yaml:"-" json:"hostPath,omitempty"`
+}
+
+func validateStepSensors(steps []Step, volumes map[string]*Volume) error {
+	for i := range steps {
+		step := &steps[i]
+		if step.Type != StepTypeSensor {
+			if step.Sensor != nil {
+				return fmt.Errorf("steps[%d].sensor requires type %q", i, StepTypeSensor)
+			}
+			continue
+		}
+		if step.Sensor == nil {
+			return fmt.Errorf("steps[%d] is a sensor step and requires a sensor block", i)
+		}
+		if len(step.Command) > 0 {
+			return fmt.Errorf("steps[%d].command is not supported on sensor steps", i)
+		}
+		if step.Map != nil {
+			return fmt.Errorf("steps[%d].map is not supported on sensor steps", i)
+		}
+		if step.Gate != nil {
+			return fmt.Errorf("steps[%d].gate is not supported on sensor steps", i)
+		}
+		if step.Cache != nil {
+			return fmt.Errorf("steps[%d].cache is not supported on sensor steps; sensors never use the result cache", i)
+		}
+		if err := validateStepSensor(i, step, volumes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateStepSensor(i int, step *Step, volumes map[string]*Volume) error {
+	sensor := step.Sensor
+	probes := 0
+	for _, set := range []bool{sensor.HTTP != nil, sensor.Job != nil, sensor.Dataset != nil, sensor.File != nil} {
+		if set {
+			probes++
+		}
+	}
+	if probes != 1 {
+		return fmt.Errorf("steps[%d].sensor must set exactly one of [%s,%s,%s,%s], got %d", i, SensorProbeHTTP, SensorProbeJob, SensorProbeDataset, SensorProbeFile, probes)
+	}
+
+	if sensor.PokeInterval < 0 {
+		return fmt.Errorf("steps[%d].sensor.pokeInterval must be >= 0", i)
+	}
+	if sensor.PokeInterval == 0 {
+		sensor.PokeInterval = DefaultSensorPokeInterval
+	}
+	if sensor.Timeout < 0 {
+		return fmt.Errorf("steps[%d].sensor.timeout must be >= 0", i)
+	}
+	sensor.Mode = strings.TrimSpace(sensor.Mode)
+	switch sensor.Mode {
+	case "":
+		sensor.Mode = SensorModePoke
+	case SensorModePoke, SensorModeReschedule:
+	default:
+		return fmt.Errorf("steps[%d].sensor.mode %q must be %q or %q", i, sensor.Mode, SensorModePoke, SensorModeReschedule)
+	}
+	sensor.OnTimeout = strings.TrimSpace(sensor.OnTimeout)
+	switch sensor.OnTimeout {
+	case "":
+		sensor.OnTimeout = SensorOnTimeoutFail
+	case SensorOnTimeoutFail, SensorOnTimeoutSkip:
+	default:
+		return fmt.Errorf("steps[%d].sensor.onTimeout %q must be %q or %q", i, sensor.OnTimeout, SensorOnTimeoutFail, SensorOnTimeoutSkip)
+	}
+
+	switch {
+	case sensor.HTTP != nil:
+		return validateSensorHTTP(i, sensor.HTTP)
+	case sensor.Job != nil:
+		sensor.Job.Alias = strings.TrimSpace(sensor.Job.Alias)
+		if sensor.Job.Alias == "" {
+			return fmt.Errorf("steps[%d].sensor.job.alias is required", i)
+		}
+		if sensor.Job.MaxAge < 0 {
+			return fmt.Errorf("steps[%d].sensor.job.maxAge must be >= 0", i)
+		}
+		sensor.Job.Namespace = strings.TrimSpace(sensor.Job.Namespace)
+		if sensor.Job.Namespace != "" && !ValidNamespace(sensor.Job.Namespace) {
+			return fmt.Errorf("steps[%d].sensor.job.namespace %q is not a valid namespace", i, sensor.Job.Namespace)
+		}
+	case sensor.Dataset != nil:
+		sensor.Dataset.Name = strings.TrimSpace(sensor.Dataset.Name)
+		sensor.Dataset.Namespace = strings.TrimSpace(sensor.Dataset.Namespace)
+		if sensor.Dataset.Name == "" {
+			return fmt.Errorf("steps[%d].sensor.dataset.name is required", i)
+		}
+		if err := ValidateRunTemplate(fmt.Sprintf("steps[%d].sensor.dataset.watermark", i), sensor.Dataset.Watermark); err != nil {
+			return err
+		}
+	case sensor.File != nil:
+		return validateSensorFile(i, step, volumes)
+	}
+	return nil
+}
+
+func validateSensorHTTP(i int, probe *SensorHTTP) error {
+	probe.URL = strings.TrimSpace(probe.URL)
+	if probe.URL == "" {
+		return fmt.Errorf("steps[%d].sensor.http.url is required", i)
+	}
+	field := fmt.Sprintf("steps[%d].sensor.http.url", i)
+	if err := ValidateRunTemplate(field, probe.URL); err != nil {
+		return err
+	}
+	if !strings.Contains(probe.URL, "{{") {
+		parsed, err := url.Parse(probe.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s %q must be an absolute http or https URL", field, probe.URL)
+		}
+	}
+	probe.Method = strings.ToUpper(strings.TrimSpace(probe.Method))
+	switch probe.Method {
+	case "":
+		probe.Method = http.MethodGet
+	case http.MethodGet, http.MethodHead:
+	default:
+		return fmt.Errorf("steps[%d].sensor.http.method %q must be %s or %s", i, probe.Method, http.MethodGet, http.MethodHead)
+	}
+	if err := validateRunTemplateValues(fmt.Sprintf("steps[%d].sensor.http.headers", i), probe.Headers); err != nil {
+		return err
+	}
+	if len(probe.ExpectStatus) == 0 {
+		probe.ExpectStatus = []int{http.StatusOK}
+	}
+	for j, status := range probe.ExpectStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("steps[%d].sensor.http.expectStatus[%d] %d is not an HTTP status code", i, j, status)
+		}
+	}
+	return nil
+}
+
+func validateSensorFile(i int, step *Step, volumes map[string]*Volume) error {
+	probe := step.Sensor.File
+	probe.Volume = strings.TrimSpace(probe.Volume)
+	if probe.Volume == "" {
+		return fmt.Errorf("steps[%d].sensor.file.volume is required", i)
+	}
+	volume, ok := volumes[probe.Volume]
+	if !ok {
+		return fmt.Errorf("steps[%d].sensor.file references unknown volume %q", i, probe.Volume)
+	}
+	source, err := volume.sourceForEngine(step.Engine)
+	if err != nil {
+		return fmt.Errorf("steps[%d].sensor.file: %w", i, err)
+	}
+	hostPath := strings.TrimSpace(source.Bind)
+	if hostPath == "" || !path.IsAbs(hostPath) {
+		return fmt.Errorf("steps[%d].sensor.file.volume %q must have an absolute bind source", i, probe.Volume)
+	}
+	probe.HostPath = hostPath
+
+	probe.Path = strings.TrimSpace(probe.Path)
+	field := fmt.Sprintf("steps[%d].sensor.file.path", i)
+	if probe.Path == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	if err := ValidateRunTemplate(field, probe.Path); err != nil {
+		return err
+	}
+	if err := ValidateSensorFilePath(probe.Path); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// ValidateSensorFilePath reports whether p is a relative path that stays
+// inside its volume. Sensors check rendered paths with it again at poke time.
+func ValidateSensorFilePath(p string) error {
+	if path.IsAbs(p) {
+		return fmt.Errorf("path %q must be relative to the volume root", p)
+	}
+	if slices.Contains(strings.Split(p, "/"), "..") {
+		return fmt.Errorf("path %q must not contain \"..\"", p)
+	}
+	return nil
+}
@@ -0,0 +1,572 @@
+package jobdef
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KindStepTemplate is the document kind for reusable, parameterised steps.
+const KindStepTemplate = "StepTemplate"
+
+// Template parameter types.
+const (
+	TemplateParamString  = "string"
+	TemplateParamInteger = "integer"
+	TemplateParamNumber  = "number"
+	TemplateParamBoolean = "boolean"
+)
+
+var (
+	templateNamePattern      = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	templateVersionPattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	templateParamNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// templateParamRefPattern matches a scalar that is exactly one parameter
+	// reference, e.g. "{{ .retries }}". Such scalars take the parameter's type
+	// instead of rendering to a string.
+	templateParamRefPattern = regexp.MustCompile(`^\{\{-?\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*-?\}\}$`)
+)
+
+// templateStepOnlyKeys are step fields a template may not declare: they wire
+// the step into a particular job's DAG, or would nest templates.
+var templateStepOnlyKeys = []string{"name", "next", "dependsOn", "triggerRule", "uses", "with"}
+
+// templateFuncs is the deliberately small function set available to template
+// expressions: parameter substitution, defaults, and case transforms only.
+var templateFuncs = template.FuncMap{
+	"default": func(fallback, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// StepTemplate is a reusable step published as its own document. Jobs
+// reference it from a step with `uses: <name>@<version>` and bind its typed
+// parameters with `with`. Templates are expanded into ordinary inline steps
+// before validation, so the scheduler, the cache hash, and `caesium job diff`
+// only ever see the expanded step.
+type StepTemplate struct {
+	APIVersion string               `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string               `yaml:"kind" json:"kind"`
+	Metadata   StepTemplateMetadata `yaml:"metadata" json:"metadata"`
+	Parameters []TemplateParameter  `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	// Spec is the step body. Scalar values may contain Go text/template
+	// expressions over the parameters; it is kept as a YAML node so rendering
+	// substitutes values into scalars and can never inject YAML structure.
+	Spec yaml.Node `yaml:"spec" json:"-"`
+}
+
+// StepTemplateMetadata identifies a template. Name and Version together form
+// the reference steps use.
+type StepTemplateMetadata struct {
+	Name        string `yaml:"name" json:"name"`
+	Version     string `yaml:"version" json:"version"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// TemplateParameter declares one typed template input.
+type TemplateParameter struct {
+	Name        string `yaml:"name" json:"name"`
+	Type        string `yaml:"type" json:"type"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	Default     any    `yaml:"default,omitempty" json:"default,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Ref returns the `name@version` reference steps use for this template.
+func (t *StepTemplate) Ref() string {
+	return t.Metadata.Name + "@" + t.Metadata.Version
+}
+
+// Validate checks the template declaration and dry-renders its spec so that
+// malformed expressions and references to undeclared parameters are reported
+// when the template is loaded rather than when a job first uses it.
+func (t *StepTemplate) Validate() error {
+	if t.APIVersion != APIVersionV1 {
+		return fmt.Errorf("unsupported apiVersion: %s", t.APIVersion)
+	}
+	if t.Kind != KindStepTemplate {
+		return fmt.Errorf("unsupported kind: %s", t.Kind)
+	}
+	if !templateNamePattern.MatchString(t.Metadata.Name) {
+		return fmt.Errorf("metadata.name %q must be a lowercase DNS-1123 label", t.Metadata.Name)
+	}
+	if !templateVersionPattern.MatchString(t.Metadata.Version) {
+		return fmt.Errorf("metadata.version %q must be non-empty and contain only letters, digits, '.', '_' or '-'", t.Metadata.Version)
+	}
+
+	seen := make(map[string]struct{}, len(t.Parameters))
+	for i := range t.Parameters {
+		param := &t.Parameters[i]
+		if !templateParamNamePattern.MatchString(param.Name) {
+			return fmt.Errorf("parameters[%d].name %q must be a valid identifier", i, param.Name)
+		}
+		if _, dup := seen[param.Name]; dup {
+			return fmt.Errorf("parameters[%d].name %q is declared more than once", i, param.Name)
+		}
+		seen[param.Name] = struct{}{}
+		switch param.Type {
+		case TemplateParamString, TemplateParamInteger, TemplateParamNumber, TemplateParamBoolean:
+		default:
+			return fmt.Errorf("parameters[%d].type %q must be one of [%s,%s,%s,%s]", i, param.Type,
+				TemplateParamString, TemplateParamInteger, TemplateParamNumber, TemplateParamBoolean)
+		}
+		if param.Default != nil {
+			if param.Required {
+				return fmt.Errorf("parameters[%d] %q is required and must not declare a default", i, param.Name)
+			}
+			if err := checkTemplateParamType(param, param.Default); err != nil {
+				return fmt.Errorf("parameters[%d].default: %w", i, err)
+			}
+		}
+	}
+
+	if t.Spec.Kind != yaml.MappingNode {
+		return fmt.Errorf("spec must be a mapping")
+	}
+	hasImage, isSensor := false, false
+	for i := 0; i+1 < len(t.Spec.Content); i += 2 {
+		key := t.Spec.Content[i].Value
+		if slices.Contains(templateStepOnlyKeys, key) {
+			return fmt.Errorf("spec.%s is not allowed in a step template", key)
+		}
+		switch key {
+		case "image":
+			hasImage = true
+		case "type":
+			isSensor = t.Spec.Content[i+1].Value == StepTypeSensor
+		}
+	}
+	if !hasImage && !isSensor {
+		return fmt.Errorf("spec.image is required")
+	}
+
+	dryRun := make(map[string]any, len(t.Parameters))
+	for _, param := range t.Parameters {
+		if param.Default != nil {
+			dryRun[param.Name] = param.Default
+			continue
+		}
+		dryRun[param.Name] = zeroTemplateParam(param.Type)
+	}
+	if _, err := t.render(dryRun); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Expand renders the template with the given parameter bindings and returns
+// the resulting step. Every required parameter must be bound, every binding
+// must be declared, and each value must match its declared type.
+func (t *StepTemplate) Expand(with map[string]any) (Step, error) {
+	values, err := t.bind(with)
+	if err != nil {
+		return Step{}, err
+	}
+	return t.render(values)
+}
+
+func (t *StepTemplate) bind(with map[string]any) (map[string]any, error) {
+	declared := make(map[string]*TemplateParameter, len(t.Parameters))
+	for i := range t.Parameters {
+		declared[t.Parameters[i].Name] = &t.Parameters[i]
+	}
+	for _, name := range slices.Sorted(maps.Keys(with)) {
+		if _, ok := declared[name]; !ok {
+			return nil, fmt.Errorf("with.%s is not a parameter of %s", name, t.Ref())
+		}
+	}
+
+	values := make(map[string]any, len(t.Parameters))
+	for _, param := range t.Parameters {
+		value, ok := with[param.Name]
+		switch {
+		case ok:
+			if err := checkTemplateParamType(&param, value); err != nil {
+				return nil, fmt.Errorf("with.%s: %w", param.Name, err)
+			}
+			values[param.Name] = normalizeTemplateParam(param.Type, value)
+		case param.Required:
+			return nil, fmt.Errorf("with.%s is required by %s", param.Name, t.Ref())
+		case param.Default != nil:
+			values[param.Name] = normalizeTemplateParam(param.Type, param.Default)
+		default:
+			values[param.Name] = zeroTemplateParam(param.Type)
+		}
+	}
+	return values, nil
+}
+
+func (t *StepTemplate) render(values map[string]any) (Step, error) {
+	types := make(map[string]string, len(t.Parameters))
+	for _, param := range t.Parameters {
+		types[param.Name] = param.Type
+	}
+
+	spec := cloneYAMLNode(&t.Spec)
+	if err := renderTemplateNode(spec, "spec", values, types); err != nil {
+		return Step{}, err
+	}
+
+	var step Step
+	if err := spec.Decode(&step); err != nil {
+		return Step{}, fmt.Errorf("spec: %w", err)
+	}
+	return step, nil
+}
+
+func renderTemplateNode(node *yaml.Node, path string, values map[string]any, types map[string]string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if err := renderTemplateNode(key, path, values, types); err != nil {
+				return err
+			}
+			if err := renderTemplateNode(value, path+"."+key.Value, values, types); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := renderTemplateNode(item, fmt.Sprintf("%s[%d]", path, i), values, types); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "{{") {
+			return nil
+		}
+		tmpl, err := template.New(path).Funcs(templateFuncs).Option("missingkey=error").Parse(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, values); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		tag := "!!str"
+		if match := templateParamRefPattern.FindStringSubmatch(strings.TrimSpace(node.Value)); match != nil {
+			tag = templateParamYAMLTag(types[match[1]])
+		}
+		node.Value = out.String()
+		node.Tag = tag
+	}
+	return nil
+}
+
+func templateParamYAMLTag(paramType string) string {
+	switch paramType {
+	case TemplateParamInteger:
+		return "!!int"
+	case TemplateParamNumber:
+		return "!!float"
+	case TemplateParamBoolean:
+		return "!!bool"
+	default:
+		return "!!str"
+	}
+}
+
+func checkTemplateParamType(param *TemplateParameter, value any) error {
+	ok := false
+	switch param.Type {
+	case TemplateParamString:
+		_, ok = value.(string)
+	case TemplateParamInteger:
+		switch v := value.(type) {
+		case int, int64:
+			ok = true
+		case float64:
+			ok = v == math.Trunc(v) && !math.IsInf(v, 0)
+		}
+	case TemplateParamNumber:
+		switch value.(type) {
+		case int, int64, float64:
+			ok = true
+		}
+	case TemplateParamBoolean:
+		_, ok = value.(bool)
+	}
+	if !ok {
+		return fmt.Errorf("%q must be a %s, got %T", param.Name, param.Type, value)
+	}
+	return nil
+}
+
+// normalizeTemplateParam gives integer parameters a consistent Go type so JSON
+// numbers (float64) render as "3" rather than "3e+00" style output.
+func normalizeTemplateParam(paramType string, value any) any {
+	if paramType == TemplateParamInteger {
+		if v, ok := value.(float64); ok {
+			return int64(v)
+		}
+	}
+	return value
+}
+
+func zeroTemplateParam(paramType string) any {
+	switch paramType {
+	case TemplateParamInteger:
+		return 0
+	case TemplateParamNumber:
+		return 0.0
+	case TemplateParamBoolean:
+		return false
+	default:
+		return ""
+	}
+}
+
+func cloneYAMLNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	out := *node
+	if len(node.Content) > 0 {
+		out.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			out.Content[i] = cloneYAMLNode(child)
+		}
+	}
+	return &out
+}
+
+// TemplateRegistry holds loaded step templates keyed by `name@version`.
+type TemplateRegistry struct {
+	templates map[string]*StepTemplate
+}
+
+// NewTemplateRegistry returns an empty registry.
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{templates: make(map[string]*StepTemplate)}
+}
+
+// Add validates and registers a template. Registering the same name@version
+// twice is an error so two files cannot silently shadow each other.
+func (r *TemplateRegistry) Add(t *StepTemplate) error {
+	if err := t.Validate(); err != nil {
+		if t.Metadata.Name != "" {
+			return fmt.Errorf("step template %s: %w", t.Ref(), err)
+		}
+		return fmt.Errorf("step template: %w", err)
+	}
+	if _, exists := r.templates[t.Ref()]; exists {
+		return fmt.Errorf("duplicate step template %q", t.Ref())
+	}
+	r.templates[t.Ref()] = t
+	return nil
+}
+
+// Lookup returns the template a `uses` reference names.
+func (r *TemplateRegistry) Lookup(ref string) (*StepTemplate, error) {
+	name, version, ok := strings.Cut(strings.TrimSpace(ref), "@")
+	if !ok || name == "" || version == "" {
+		return nil, fmt.Errorf("%q must be of the form <template-name>@<version>", ref)
+	}
+	if r != nil {
+		if t, ok := r.templates[name+"@"+version]; ok {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("step template %q not found", ref)
+}
+
+// Refs returns the registered template references in sorted order.
+func (r *TemplateRegistry) Refs() []string {
+	if r == nil {
+		return nil
+	}
+	refs := slices.Collect(maps.Keys(r.templates))
+	sort.Strings(refs)
+	return refs
+}
+
+// DecodeStepTemplate decodes node as a StepTemplate when its kind is
+// StepTemplate. It returns nil, nil for any other document so loaders can fall
+// through to decoding a job Definition.
+func DecodeStepTemplate(node *yaml.Node) (*StepTemplate, error) {
+	var header struct {
+		Kind string `yaml:"kind"`
+	}
+	if err := node.Decode(&header); err != nil || header.Kind != KindStepTemplate {
+		return nil, nil
+	}
+	var t StepTemplate
+	if err := node.Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ExpandTemplates replaces every step that declares `uses` with the rendered
+// template merged with the step's own fields. It runs before Validate; the
+// expanded step no longer references its template, so everything downstream
+// (validation, the cache hash, diffs, the server) sees an ordinary step.
+//
+// Merge rules: the template owns image, command, and outputSchema (a step that
+// uses a template may not set image or command). env, nodeSelector, and
+// podAnnotations merge key by key with the step winning; mounts and
+// volumeMounts append. Every other field the step sets overrides the
+// template, including map and sensor, which a step inherits when it leaves them
+// unset; name, next, dependsOn, and triggerRule come only from the step.
+func (d *Definition) ExpandTemplates(registry *TemplateRegistry) error {
+	for i := range d.Steps {
+		step := &d.Steps[i]
+		ref := strings.TrimSpace(step.Uses)
+		if ref == "" {
+			continue
+		}
+		if strings.TrimSpace(step.Image) != "" {
+			return fmt.Errorf("steps[%d] uses %s and must not set image", i, ref)
+		}
+		if len(step.Command) > 0 {
+			return fmt.Errorf("steps[%d] uses %s and must not set command", i, ref)
+		}
+		tmpl, err := registry.Lookup(ref)
+		if err != nil {
+			return fmt.Errorf("steps[%d].uses: %w", i, err)
+		}
+		expanded, err := tmpl.Expand(step.With)
+		if err != nil {
+			return fmt.Errorf("steps[%d].uses %s: %w", i, ref, err)
+		}
+		*step = mergeTemplateStep(expanded, *step)
+	}
+	return nil
+}
+
+func mergeTemplateStep(base, step Step) Step {
+	out := base
+	out.Name = step.Name
+	out.Next = step.Next
+	out.DependsOn = step.DependsOn
+	out.TriggerRule = step.TriggerRule
+	out.Uses = ""
+	out.With = nil
+
+	if step.Type != "" && step.Type != StepTypeTask {
+		out.Type = step.Type
+	}
+	if step.Engine != "" && step.Engine != EngineDocker {
+		out.Engine = step.Engine
+	}
+	if step.Retries != 0 {
+		out.Retries = step.Retries
+	}
+	if step.RetryDelay != 0 {
+		out.RetryDelay = step.RetryDelay
+	}
+	if step.RetryBackoff {
+		out.RetryBackoff = true
+	}
+	if step.ReplaySafe {
+		out.ReplaySafe = true
+	}
+	if step.ServiceAccountName != "" {
+		out.ServiceAccountName = step.ServiceAccountName
+	}
+	if step.AutomountServiceAccountToken != nil {
+		out.AutomountServiceAccountToken = step.AutomountServiceAccountToken
+	}
+	if step.Kueue != nil {
+		out.Kueue = step.Kueue
+	}
+	if step.RateLimit != nil {
+		out.RateLimit = step.RateLimit
+	}
+	if step.Pool != nil {
+		out.Pool = step.Pool
+	}
+	if step.Gate != nil {
+		out.Gate = step.Gate
+	}
+	if step.Map != nil {
+		out.Map = step.Map
+	}
+	if step.Sensor != nil {
+		out.Sensor = step.Sensor
+	}
+	if out.OutputSchema == nil {
+		out.OutputSchema = step.OutputSchema
+	}
+	if step.InputSchema != nil {
+		out.InputSchema = step.InputSchema
+	}
+	if step.Datasets != nil {
+		out.Datasets = step.Datasets
+	}
+	if step.Cache != nil {
+		out.Cache = step.Cache
+	}
+	if step.WorkDir != "" {
+		out.WorkDir = step.WorkDir
+	}
+	if step.Resources != nil {
+		out.Resources = out.Resources.Merge(step.Resources)
+	}
+	out.NodeSelector = mergeStringMaps(out.NodeSelector, step.NodeSelector)
+	if step.NodeAffinity != nil {
+		out.NodeAffinity = step.NodeAffinity
+	}
+	out.PodAnnotations = mergeStringMaps(out.PodAnnotations, step.PodAnnotations)
+	out.Env = mergeStringMaps(out.Env, step.Env)
+	out.Mounts = append(out.Mounts, step.Mounts...)
+	out.VolumeMounts = append(out.VolumeMounts, step.VolumeMounts...)
+	return out
+}
+
+func mergeStringMaps(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(override))
+	maps.Copy(out, base)
+	maps.Copy(out, override)
+	return out
+}
+
+// decodeDocuments splits a YAML stream into step templates and the first
+// remaining document, which Parse treats as the job definition.
+func decodeDocuments(data []byte) (*yaml.Node, *TemplateRegistry, error) {
+	registry := NewTemplateRegistry()
+	var job *yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, err
+		}
+		tmpl, err := DecodeStepTemplate(&node)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tmpl != nil {
+			if err := registry.Add(tmpl); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if job == nil {
+			job = &node
+		}
+	}
+	return job, registry, nil
+}
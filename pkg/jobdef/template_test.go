@@ -0,0 +1,318 @@
+package jobdef
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caesium-cloud/caesium/pkg/container"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const dbtTemplate = `
+apiVersion: v1
+kind: StepTemplate
+metadata:
+  name: dbt-run
+  version: "2"
+  description: Run dbt against a project
+parameters:
+  - {name: project_dir, type: string, required: true}
+  - {name: target, type: string, default: dev}
+  - {name: threads, type: integer, default: 4}
+  - {name: full_refresh, type: boolean, default: false}
+spec:
+  image: ghcr.io/acme/dbt:1.7
+  command: ["dbt", "run", "--project-dir", "{{ .project_dir }}", "--target", "{{ .target | upper }}"]
+  env:
+    DBT_PROFILES_DIR: "{{ .project_dir }}/profiles"
+    DBT_THREADS: "{{ .threads }}"
+    DBT_FULL_REFRESH: "{{ .full_refresh }}"
+  retries: "{{ .threads }}"
+  retryBackoff: true
+  nodeSelector: {pool: analytics}
+  outputSchema:
+    type: object
+    properties:
+      models_run: {type: integer}
+`
+
+func TestParseExpandsStepTemplate(t *testing.T) {
+	def, err := Parse([]byte(dbtTemplate + `
+---
+apiVersion: v1
+kind: Job
+metadata:
+  alias: analytics
+trigger:
+  type: cron
+  configuration: {cron: "0 6 * * *"}
+steps:
+  - name: seed
+    image: alpine:3.23
+  - name: models
+    uses: dbt-run@2
+    with:
+      project_dir: /dbt/analytics
+      target: prod
+      threads: 8
+    dependsOn: [seed]
+    retryDelay: 30s
+    env: {DBT_THREADS: "16", EXTRA: "1"}
+    nodeSelector: {zone: a}
+    mounts:
+      - {type: bind, source: /srv/dbt, target: /dbt}
+`))
+	require.NoError(t, err)
+	require.Len(t, def.Steps, 2)
+
+	step := def.Steps[1]
+	require.Equal(t, "models", step.Name)
+	require.Empty(t, step.Uses, "expanded steps no longer reference their template")
+	require.Nil(t, step.With)
+	require.Equal(t, "ghcr.io/acme/dbt:1.7", step.Image)
+	require.Equal(t, []string{"dbt", "run", "--project-dir", "/dbt/analytics", "--target", "PROD"}, step.Command)
+	require.Equal(t, []string{"seed"}, step.DependsOn)
+	require.Equal(t, 8, step.Retries, "a whole-scalar reference keeps the parameter's type")
+	require.True(t, step.RetryBackoff)
+	require.Equal(t, 30*time.Second, step.RetryDelay)
+	require.Equal(t, map[string]string{
+		"DBT_PROFILES_DIR": "/dbt/analytics/profiles",
+		"DBT_THREADS":      "16",
+		"DBT_FULL_REFRESH": "false",
+		"EXTRA":            "1",
+	}, step.Env)
+	require.Equal(t, map[string]string{"pool": "analytics", "zone": "a"}, step.NodeSelector)
+	require.Equal(t, []container.Mount{{Type: container.MountTypeBind, Source: "/srv/dbt", Target: "/dbt"}}, step.Mounts)
+	require.NotNil(t, step.OutputSchema)
+}
+
+func TestParseStepTemplateDefaults(t *testing.T) {
+	def, err := Parse([]byte(dbtTemplate + `
+---
+apiVersion: v1
+kind: Job
+metadata:
+  alias: analytics
+trigger:
+  type: cron
+  configuration: {cron: "0 6 * * *"}
+steps:
+  - name: models
+    uses: dbt-run@2
+    with: {project_dir: /dbt/analytics}
+`))
+	require.NoError(t, err)
+	step := def.Steps[0]
+	require.Equal(t, "DEV", step.Command[5])
+	require.Equal(t, 4, step.Retries)
+	require.Equal(t, "4", step.Env["DBT_THREADS"])
+}
+
+func TestExpandTemplatesRejectsBadBindings(t *testing.T) {
+	registry := NewTemplateRegistry()
+	tmpl := parseTestTemplate(t, dbtTemplate)
+	require.NoError(t, registry.Add(tmpl))
+
+	cases := map[string]Step{
+		"with.project_dir is required by dbt-run@2":     {Name: "a", Uses: "dbt-run@2"},
+		"with.profile is not a parameter of dbt-run@2":  {Name: "a", Uses: "dbt-run@2", With: map[string]any{"project_dir": "/p", "profile": "x"}},
+		`"threads" must be a integer, got string`:       {Name: "a", Uses: "dbt-run@2", With: map[string]any{"project_dir": "/p", "threads": "8"}},
+		`"full_refresh" must be a boolean`:              {Name: "a", Uses: "dbt-run@2", With: map[string]any{"project_dir": "/p", "full_refresh": "yes"}},
+		`step template "dbt-run@3" not found`:           {Name: "a", Uses: "dbt-run@3"},
+		"must be of the form <template-name>@<version>": {Name: "a", Uses: "dbt-run"},
+		"uses dbt-run@2 and must not set image":         {Name: "a", Uses: "dbt-run@2", Image: "alpine:3.23"},
+		"uses dbt-run@2 and must not set command":       {Name: "a", Uses: "dbt-run@2", Command: []string{"sh"}},
+	}
+	for want, step := range cases {
+		def := &Definition{Steps: []Step{step}}
+		require.ErrorContains(t, def.ExpandTemplates(registry), want)
+	}
+}
+
+func TestExpandTemplatesAcceptsJSONIntegers(t *testing.T) {
+	registry := NewTemplateRegistry()
+	require.NoError(t, registry.Add(parseTestTemplate(t, dbtTemplate)))
+
+	// JSON decodes numbers as float64; an integral value still binds to an
+	// integer parameter and renders without a fractional part.
+	def := &Definition{Steps: []Step{{Name: "a", Uses: "dbt-run@2", With: map[string]any{"project_dir": "/p", "threads": float64(12)}}}}
+	require.NoError(t, def.ExpandTemplates(registry))
+	require.Equal(t, "12", def.Steps[0].Env["DBT_THREADS"])
+	require.Equal(t, 12, def.Steps[0].Retries)
+}
+
+func TestExpandTemplatesMergesSensorAndMap(t *testing.T) {
+	registry := NewTemplateRegistry()
+	sensorTmpl := parseTestTemplate(t, `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: wait-api, version: "1"}
+parameters:
+  - {name: url, type: string, required: true}
+spec:
+  type: sensor
+  sensor:
+    http: {url: "{{ .url }}"}
+    pokeInterval: 1m
+`)
+	require.NoError(t, sensorTmpl.Validate(), "a sensor template needs no image")
+	require.NoError(t, registry.Add(sensorTmpl))
+	mapTmpl := parseTestTemplate(t, `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: per-region, version: "1"}
+spec:
+  image: alpine:3.23
+  command: [sh, -c, echo]
+  map: {over: regions.list, maxParallel: 2}
+`)
+	require.NoError(t, mapTmpl.Validate())
+	require.NoError(t, registry.Add(mapTmpl))
+
+	cases := map[string]struct {
+		step       Step
+		wantSensor *StepSensor
+		wantMap    *StepMap
+	}{
+		"template sensor": {
+			step:       Step{Name: "wait", Uses: "wait-api@1", With: map[string]any{"url": "https://api/health"}},
+			wantSensor: &StepSensor{HTTP: &SensorHTTP{URL: "https://api/health"}, PokeInterval: time.Minute},
+		},
+		"step sensor overrides": {
+			step: Step{Name: "wait", Uses: "wait-api@1", With: map[string]any{"url": "https://api/health"},
+				Sensor: &StepSensor{File: &SensorFile{Path: "/data/_SUCCESS"}}},
+			wantSensor: &StepSensor{File: &SensorFile{Path: "/data/_SUCCESS"}},
+		},
+		"template map": {
+			step:    Step{Name: "load", Uses: "per-region@1"},
+			wantMap: &StepMap{Over: "regions.list", MaxParallel: 2},
+		},
+		"step map overrides": {
+			step:    Step{Name: "load", Uses: "per-region@1", Map: &StepMap{Over: "zones.list"}},
+			wantMap: &StepMap{Over: "zones.list"},
+		},
+	}
+	for name, tc := range cases {
+		def := &Definition{Steps: []Step{tc.step}}
+		require.NoError(t, def.ExpandTemplates(registry), name)
+		step := def.Steps[0]
+		require.Equal(t, tc.wantSensor, step.Sensor, name)
+		require.Equal(t, tc.wantMap, step.Map, name)
+		if tc.wantSensor != nil {
+			require.Equal(t, StepTypeSensor, step.Type, name)
+			require.Empty(t, step.Image, name)
+		}
+	}
+}
+
+func TestValidateRejectsUnexpandedUses(t *testing.T) {
+	_, err := Parse([]byte(`
+apiVersion: v1
+kind: Job
+metadata:
+  alias: analytics
+trigger:
+  type: cron
+  configuration: {cron: "0 6 * * *"}
+steps:
+  - name: models
+    uses: dbt-run@2
+`))
+	require.ErrorContains(t, err, `steps[0].uses: step template "dbt-run@2" not found`)
+
+	def := &Definition{
+		APIVersion: APIVersionV1,
+		Kind:       KindJob,
+		Metadata:   Metadata{Alias: "analytics"},
+		Trigger:    Trigger{Type: TriggerCron, Configuration: map[string]any{"cron": "0 6 * * *"}},
+		Steps:      []Step{{Name: "models", Engine: EngineDocker, Type: StepTypeTask, Uses: "dbt-run@2"}},
+	}
+	require.ErrorContains(t, def.Validate(), `steps[0].uses "dbt-run@2" was not expanded`)
+}
+
+func TestStepTemplateValidate(t *testing.T) {
+	cases := map[string]string{
+		`metadata.name "DBT" must be a lowercase DNS-1123 label`: `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: DBT, version: "1"}
+spec: {image: alpine:3.23}
+`,
+		`metadata.version "" must be non-empty`: `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt}
+spec: {image: alpine:3.23}
+`,
+		`parameters[0] "target" is required and must not declare a default`: `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+parameters:
+  - {name: target, type: string, required: true, default: dev}
+spec: {image: alpine:3.23}
+`,
+		`parameters[0].type "list" must be one of`: `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+parameters:
+  - {name: targets, type: list}
+spec: {image: alpine:3.23}
+`,
+		`parameters[0].default: "threads" must be a integer`: `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+parameters:
+  - {name: threads, type: integer, default: many}
+spec: {image: alpine:3.23}
+`,
+		"spec.dependsOn is not allowed in a step template": `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+spec: {image: alpine:3.23, dependsOn: [seed]}
+`,
+		"spec.image is required": `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+spec: {command: [dbt]}
+`,
+		`map has no entry for key "target"`: `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+spec: {image: alpine:3.23, command: ["dbt", "{{ .target }}"]}
+`,
+		"spec.command[0]": `
+apiVersion: v1
+kind: StepTemplate
+metadata: {name: dbt, version: "1"}
+spec: {image: alpine:3.23, command: ["{{ .target "]}
+`,
+	}
+	for want, src := range cases {
+		tmpl := parseTestTemplate(t, src)
+		require.ErrorContains(t, tmpl.Validate(), want)
+	}
+}
+
+func TestTemplateRegistryRejectsDuplicates(t *testing.T) {
+	registry := NewTemplateRegistry()
+	require.NoError(t, registry.Add(parseTestTemplate(t, dbtTemplate)))
+	require.ErrorContains(t, registry.Add(parseTestTemplate(t, dbtTemplate)), `duplicate step template "dbt-run@2"`)
+	require.Equal(t, []string{"dbt-run@2"}, registry.Refs())
+}
+
+func parseTestTemplate(t *testing.T, src string) *StepTemplate {
+	t.Helper()
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(src), &node))
+	tmpl, err := DecodeStepTemplate(node.Content[0])
+	require.NoError(t, err)
+	require.NotNil(t, tmpl)
+	return tmpl
+}
@@ -9,7 +9,7 @@ import {
   DialogHeader,
   DialogTitle,
 } from "@/components/ui/dialog";
-import { api, type CreateBackfillRequest } from "@/lib/api";
+import { api, type BackfillOrder, type CreateBackfillRequest } from "@/lib/api";
 
 interface BackfillDialogProps {
   jobId: string;
@@ -38,6 +38,9 @@ export function BackfillDialog({ jobId, open, onOpenChange, disabled }: Backfill
   const [end, setEnd] = useState(toDatetimeLocal(now));
   const [maxConcurrent, setMaxConcurrent] = useState(1);
   const [reprocess, setReprocess] = useState<"none" | "failed" | "all">("none");
+  const [order, setOrder] = useState<BackfillOrder>("oldest_first");
+  const [batchSize, setBatchSize] = useState(1);
+  const [maxFailures, setMaxFailures] = useState(0);
   const [validationError, setValidationError] = useState<string | null>(null);
 
   const mutation = useMutation({
@@ -69,6 +72,9 @@ export function BackfillDialog({ jobId, open, onOpenChange, disabled }: Backfill
       end: endDate.toISOString(),
       max_concurrent: maxConcurrent,
       reprocess,
+      order,
+      batch_size: batchSize,
+      max_failures: maxFailures > 0 ? maxFailures : undefined,
     });
   }
 
@@ -141,6 +147,43 @@ export function BackfillDialog({ jobId, open, onOpenChange, disabled }: Backfill
             </div>
           </div>
 
+          <div className="grid gap-4 sm:grid-cols-3">
+            <div>
+              <label className={labelClass}>Order</label>
+              <select
+                value={order}
+                onChange={(e) => setOrder(e.target.value as BackfillOrder)}
+                disabled={mutation.isPending || disabled}
+                className={inputClass}
+              >
+                <option value="oldest_first">Oldest first</option>
+                <option value="newest_first">Newest first</option>
+              </select>
+            </div>
+            <div>
+              <label className={labelClass}>Batch Size</label>
+              <input
+                type="number"
+                min={1}
+                value={batchSize}
+                onChange={(e) => setBatchSize(Math.max(1, Number(e.target.value)))}
+                disabled={mutation.isPending || disabled}
+                className={inputClass}
+              />
+            </div>
+            <div>
+              <label className={labelClass}>Max Failures</label>
+              <input
+                type="number"
+                min={0}
+                value={maxFailures}
+                onChange={(e) => setMaxFailures(Math.max(0, Number(e.target.value)))}
+                disabled={mutation.isPending || disabled}
+                className={inputClass}
+              />
+            </div>
+          </div>
+
           <div className="flex justify-end pt-2">
             <Button
               type="submit"
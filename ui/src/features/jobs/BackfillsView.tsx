@@ -1,5 +1,5 @@
 import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query";
-import { XCircle } from "lucide-react";
+import { Pause, Play, XCircle } from "lucide-react";
 import { toast } from "sonner";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
@@ -11,8 +11,12 @@ interface BackfillsViewProps {
   jobId: string;
 }
 
+function isActive(backfill: Backfill) {
+  return backfill.status === "running" || backfill.status === "paused";
+}
+
 function renderBackfillStatus(backfill: Backfill) {
-  if (backfill.status === "running" && backfill.cancel_requested_at) {
+  if (isActive(backfill) && backfill.cancel_requested_at) {
     return (
       <Badge variant="outline" className="border-warning/40 text-warning">
         Cancelling
@@ -24,6 +28,12 @@ function renderBackfillStatus(backfill: Backfill) {
   switch (status) {
     case "running":
       return <Badge variant="running">Running</Badge>;
+    case "paused":
+      return (
+        <Badge variant="outline" className="border-warning/40 text-warning">
+          Paused
+        </Badge>
+      );
     case "succeeded":
       return <Badge variant="success">Succeeded</Badge>;
     case "failed":
@@ -52,8 +62,24 @@ function BackfillRow({ jobId, backfill }: { jobId: string; backfill: Backfill })
     },
   });
 
-  const isRunning = backfill.status === "running";
+  const isRunning = isActive(backfill);
+  const isPaused = backfill.status === "paused";
   const isCancelling = isRunning && Boolean(backfill.cancel_requested_at);
+
+  const pauseMutation = useMutation({
+    mutationFn: () =>
+      isPaused
+        ? api.resumeBackfill(jobId, backfill.id)
+        : api.pauseBackfill(jobId, backfill.id),
+    onSuccess: () => {
+      toast.success(isPaused ? "Backfill resumed" : "Backfill paused");
+      queryClient.invalidateQueries({ queryKey: ["job", jobId, "backfills"] });
+    },
+    onError: (err: Error) => {
+      toast.error(`Failed to ${isPaused ? "resume" : "pause"} backfill: ${err.message}`);
+    },
+  });
+
   const progressPct =
     backfill.total_runs > 0
       ? Math.round((backfill.completed_runs / backfill.total_runs) * 100)
@@ -63,7 +89,9 @@ function BackfillRow({ jobId, backfill }: { jobId: string; backfill: Backfill })
     <div className="flex items-center justify-between gap-3 p-4">
       <div className="min-w-0 flex-1 space-y-1.5">
         <div className="font-medium text-sm truncate">
-          {formatDateRange(backfill.start, backfill.end)}
+          {backfill.dates?.length
+            ? `${backfill.dates.length} dates · ${formatDateRange(backfill.start, backfill.end)}`
+            : formatDateRange(backfill.start, backfill.end)}
         </div>
         <div className="text-xs text-muted-foreground">
           <RelativeTime date={backfill.created_at} />
@@ -73,6 +101,19 @@ function BackfillRow({ jobId, backfill }: { jobId: string; backfill: Backfill })
           reprocess: <span className="font-mono">{backfill.reprocess}</span>
           {" · "}
           max concurrent: <span className="font-mono">{backfill.max_concurrent}</span>
+          {backfill.order === "newest_first" && <span> · newest first</span>}
+          {backfill.batch_size > 1 && (
+            <>
+              {" · "}
+              batch: <span className="font-mono">{backfill.batch_size}</span>
+            </>
+          )}
+          {(backfill.max_failures ?? 0) > 0 && (
+            <>
+              {" · "}
+              max failures: <span className="font-mono">{backfill.max_failures}</span>
+            </>
+          )}
         </div>
 
         {/* Progress — shown for running and terminal states */}
@@ -104,6 +145,22 @@ function BackfillRow({ jobId, backfill }: { jobId: string; backfill: Backfill })
 
       <div className="flex shrink-0 items-center gap-2">
         {renderBackfillStatus(backfill)}
+        {isRunning && !isCancelling && (
+          <Button
+            variant="outline"
+            size="sm"
+            className="h-7 px-2 text-xs"
+            onClick={() => pauseMutation.mutate()}
+            disabled={pauseMutation.isPending}
+          >
+            {isPaused ? (
+              <Play className="mr-1 h-3.5 w-3.5" />
+            ) : (
+              <Pause className="mr-1 h-3.5 w-3.5" />
+            )}
+            {isPaused ? "Resume" : "Pause"}
+          </Button>
+        )}
         {isRunning && (
           <Button
             variant="outline"
@@ -128,7 +185,7 @@ export function BackfillsView({ jobId }: BackfillsViewProps) {
     refetchInterval: (query) => {
       const data = query.state.data;
       if (!data) return false;
-      return data.some((b) => b.status === "running") ? 3000 : false;
+      return data.some(isActive) ? 3000 : false;
     },
   });
 
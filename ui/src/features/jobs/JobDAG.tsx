@@ -12,7 +12,7 @@ import dagre from 'dagre';
 import { ShieldCheck } from 'lucide-react';
 import { Dialog, DialogContent, DialogDescription, DialogHeader, DialogTitle } from '@/components/ui/dialog';
 import { isRecord } from '@/lib/typeGuards';
-import type { JobDAGResponse, Atom, JobTask, TaskRun } from '@/lib/api';
+import type { JobDAGResponse, Atom, JobTask, TaskRun, TaskRunInstance } from '@/lib/api';
 import { TaskNode } from './components/TaskNode';
 import { BranchNode } from './components/BranchNode';
 import { DataFlowEdge } from './components/DataFlowEdge';
@@ -162,12 +162,14 @@ export function JobDAG({ dag, atoms, taskDefinitions, taskStatus, taskMetadata,
                   error: meta?.error,
                   rateLimitRetryAfter: meta?.rate_limit_retry_after,
                   taskType: n.type,
+                  mapOver: n.map?.over,
+                  instances: summarizeInstances(taskRunData?.[n.id]?.instances),
                   edgeDegree: edgeDegreeByNode.get(n.id) ?? { incoming: 0, outgoing: 0, total: 0 },
                 },
                 position: { x: 0, y: 0 }
             }
         });
-    }, [dag, atoms, taskDefinitions, resolvedTaskStatus, taskMetadata, selectedTaskId, edgeDegreeByNode]);
+    }, [dag, atoms, taskDefinitions, resolvedTaskStatus, taskMetadata, taskRunData, selectedTaskId, edgeDegreeByNode]);
 
     const initialEdges: Edge[] = useMemo(() => {
         if (!dag.edges) return [];
@@ -290,6 +292,32 @@ function incrementEdgeDegree(degreeByNode: Map<string, NodeEdgeDegree>, nodeId:
     degreeByNode.set(nodeId, degree);
 }
 
+// summarizeInstances collapses a mapped step's instances into the counts its
+// DAG node shows; the group's own status already reflects the aggregate.
+function summarizeInstances(instances?: TaskRunInstance[]) {
+    if (!instances || instances.length === 0) return undefined;
+    const summary = { total: instances.length, succeeded: 0, cached: 0, failed: 0, running: 0 };
+    instances.forEach((instance) => {
+        switch (normalizeTaskStatus(instance.status)) {
+            case 'cached':
+                summary.succeeded++;
+                summary.cached++;
+                break;
+            case 'succeeded':
+                summary.succeeded++;
+                break;
+            case 'failed':
+            case 'cancelled':
+                summary.failed++;
+                break;
+            case 'running':
+                summary.running++;
+                break;
+        }
+    });
+    return summary;
+}
+
 function normalizeTaskStatus(status?: string) {
     switch (status) {
         case 'completed':
@@ -20,11 +20,12 @@ import {
   HardDrive,
   ShieldCheck,
   TimerReset,
+  Layers,
 } from 'lucide-react';
 import { Duration } from '@/components/duration';
 
 export const TaskNode = memo(({ data }: NodeProps) => {
-  const { label, atom, status, isSelected, startedAt, completedAt, engine, command, error, rateLimitRetryAfter } = data;
+  const { label, atom, status, isSelected, startedAt, completedAt, engine, command, error, rateLimitRetryAfter, mapOver, instances } = data;
   const taskLabel = typeof label === 'string' ? label : '';
   const runtimeHints = getRuntimeHints(atom?.spec);
   const { showTargetHandle, showSourceHandle } = getHandleVisibility(data.edgeDegree);
@@ -141,6 +142,16 @@ export const TaskNode = memo(({ data }: NodeProps) => {
                     SHELL
                   </span>
                 )}
+                {typeof mapOver === 'string' && mapOver !== '' && (
+                  <span
+                    data-testid="task-node-map-badge"
+                    title={instances ? `Mapped over ${mapOver}: ${formatInstanceSummary(instances)}` : `Mapped over ${mapOver}`}
+                    className="inline-flex items-center gap-0.5 rounded border border-caesium-cyan/30 bg-caesium-cyan/10 px-1 text-[8px] font-black text-caesium-cyan"
+                  >
+                    <Layers className="h-2.5 w-2.5" />
+                    {instances ? `${instances.succeeded + instances.failed}/${instances.total}` : 'MAP'}
+                  </span>
+                )}
                 {runtimeHints.volumeCount > 0 && (
                   <span
                     data-testid="runtime-volume-badge"
@@ -268,6 +279,22 @@ export const TaskNode = memo(({ data }: NodeProps) => {
 
 TaskNode.displayName = 'TaskNode';
 
+interface InstanceSummary {
+  total: number;
+  succeeded: number;
+  cached: number;
+  failed: number;
+  running: number;
+}
+
+function formatInstanceSummary(summary: InstanceSummary) {
+  const parts = [`${summary.succeeded} succeeded`];
+  if (summary.cached > 0) parts.push(`${summary.cached} cached`);
+  if (summary.failed > 0) parts.push(`${summary.failed} failed`);
+  if (summary.running > 0) parts.push(`${summary.running} running`);
+  return `${parts.join(', ')} of ${summary.total}`;
+}
+
 function formatRetryAfter(value: unknown) {
   if (typeof value !== 'string' || value.trim() === '') {
     return 'the current window resets';
@@ -185,4 +185,18 @@ describe('TaskNode', () => {
     expect(screen.getByTestId('runtime-identity-badge')).toHaveTextContent('SA');
     expect(screen.getByTitle('ServiceAccount caesium-writer')).toBeInTheDocument();
   });
+
+  it('renders a mapped step collapsed with its instance progress', () => {
+    renderTaskNode({
+      label: 'load-partition',
+      status: 'running',
+      atom: { image: 'alpine:3.23', engine: 'docker', command: [] },
+      mapOver: 'list.partitions',
+      instances: { total: 4, succeeded: 2, cached: 1, failed: 1, running: 1 },
+    });
+
+    const badge = screen.getByTestId('task-node-map-badge');
+    expect(badge).toHaveTextContent('3/4');
+    expect(badge).toHaveAttribute('title', 'Mapped over list.partitions: 2 succeeded, 1 cached, 1 failed, 1 running of 4');
+  });
 });
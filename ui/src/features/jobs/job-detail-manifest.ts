@@ -45,6 +45,7 @@ type ManifestStep = {
   image: string;
   command?: string[];
   nodeSelector?: unknown;
+  nodeAffinity?: unknown;
   next?: string[];
   dependsOn?: string[];
   retries?: number;
@@ -57,6 +58,7 @@ type ManifestStep = {
   podAnnotations?: unknown;
   automountServiceAccountToken?: boolean;
   kueue?: { queueName: string };
+  resources?: unknown;
   rateLimit?: unknown;
   cache?: unknown;
   outputSchema?: unknown;
@@ -224,6 +226,7 @@ function buildManifestSteps(
       image: atom?.image ?? "",
       command: nonEmptyArray(normalizeCommand(atom?.command)),
       nodeSelector: structuredRecord(task.node_selector),
+      nodeAffinity: structuredRecord(task.node_affinity),
       next: nonEmptyArray(next),
       dependsOn,
       retries: positiveNumber(task.retries),
@@ -238,6 +241,7 @@ function buildManifestSteps(
         ? kubernetes.automountServiceAccountToken
         : undefined,
       kueue: queueName ? { queueName } : undefined,
+      resources: nonEmptyRecord(spec?.resources),
       rateLimit: rateLimitForTask(task),
       cache: structuredValue(task.cache_config),
       outputSchema: structuredValue(task.output_schema),
@@ -55,17 +55,26 @@ export interface CallbackRun {
   completed_at?: string;
 }
 
+export type BackfillOrder = "oldest_first" | "newest_first";
+
 export interface Backfill {
   id: string;
   job_id: string;
-  status: "running" | "succeeded" | "failed" | "cancelled";
+  status: "running" | "paused" | "succeeded" | "failed" | "cancelled";
   start: string;
   end: string;
   max_concurrent: number;
   reprocess: "none" | "failed" | "all";
+  order: BackfillOrder;
+  dates?: string[];
+  batch_size: number;
+  params?: Record<string, string>;
+  date_params?: Record<string, Record<string, string>>;
+  max_failures?: number;
   total_runs: number;
   completed_runs: number;
   failed_runs: number;
+  cursor?: string;
   cancel_requested_at?: string;
   completed_at?: string;
   created_at: string;
@@ -73,10 +82,16 @@ export interface Backfill {
 }
 
 export interface CreateBackfillRequest {
-  start: string;
-  end: string;
+  start?: string;
+  end?: string;
+  dates?: string[];
   max_concurrent?: number;
   reprocess?: "none" | "failed" | "all";
+  order?: BackfillOrder;
+  batch_size?: number;
+  params?: Record<string, string>;
+  date_params?: Record<string, Record<string, string>>;
+  max_failures?: number;
 }
 
 export interface TaskRun {
@@ -90,6 +105,7 @@ export interface TaskRun {
   runtime_id?: string;
   status: string;
   node_selector?: Record<string, unknown>;
+  node_affinity?: Record<string, unknown>;
   claimed_by?: string;
   claim_expires_at?: string;
   claim_attempt?: number;
@@ -106,12 +122,30 @@ export interface TaskRun {
   cache_expires_at?: string;
   error?: string;
   outstanding_predecessors?: number;
+  trace_id?: string;
+  instances?: TaskRunInstance[];
   started_at?: string;
   completed_at?: string;
   created_at: string;
   updated_at: string;
 }
 
+export interface TaskRunInstance {
+  id: string;
+  index: number;
+  item: string;
+  status: string;
+  attempt: number;
+  runtime_id?: string;
+  result?: string;
+  output?: Record<string, string>;
+  error?: string;
+  cache_hit: boolean;
+  cache_origin_run_id?: string;
+  started_at?: string;
+  completed_at?: string;
+}
+
 export interface RunQueueItem {
   id: string;
   position: number;
@@ -151,6 +185,7 @@ export interface JobTask {
   name: string;
   next_id?: string;
   node_selector: Record<string, unknown>;
+  node_affinity?: Record<string, unknown>;
   retries: number;
   retry_delay: number;
   retry_backoff: boolean;
@@ -169,6 +204,7 @@ type RawJobTask = Partial<JobTask> & {
   Name?: string;
   NextID?: string;
   NodeSelector?: Record<string, unknown>;
+  NodeAffinity?: Record<string, unknown>;
   Retries?: number;
   RetryDelay?: number;
   RetryBackoff?: boolean;
@@ -215,6 +251,8 @@ export interface DAGNode {
   successors?: string[];
   output_schema?: Record<string, unknown>;
   input_schema?: Record<string, Record<string, unknown>>;
+  map?: { over: string; maxParallel?: number };
+  sensor?: { mode?: string; pokeInterval?: number; timeout?: number; onTimeout?: string } & Record<string, unknown>;
 }
 
 export interface DAGEdge {
@@ -262,6 +300,15 @@ export interface StatsResponse {
   top_failing_atoms: FailingAtom[];
   slowest_jobs: SlowestJob[];
   success_rate_trend: DailyStats[];
+  pools?: PoolStats[];
+}
+
+export interface PoolStats {
+  name: string;
+  slots: number;
+  occupied_slots: number;
+  running_tasks: number;
+  queued_tasks: number;
 }
 
 export interface DailyStats {
@@ -716,6 +763,21 @@ export interface Receipt {
   degraded: boolean;
   degraded_tasks?: string[];
   receipt_digest: string;
+  signatures?: ReceiptSignature[];
+}
+
+export interface ReceiptSignature {
+  key_id: string;
+  algorithm: string;
+  signature: string;
+}
+
+export type ReceiptSignatureStatus = "valid" | "unsigned" | "unknown_key" | "invalid";
+
+export interface ReceiptSignatureCheck {
+  status: ReceiptSignatureStatus;
+  key_id?: string;
+  detail: string;
 }
 
 export type ReceiptDriftKind =
@@ -726,7 +788,8 @@ export type ReceiptDriftKind =
   | "git_commit_changed"
   | "task_missing"
   | "task_added"
-  | "receipt_version_mismatch";
+  | "receipt_version_mismatch"
+  | "signature_invalid";
 
 export interface ReceiptDrift {
   kind: ReceiptDriftKind;
@@ -744,6 +807,7 @@ export interface VerifyResult {
   expected_digest: string;
   actual_digest: string;
   drifts?: ReceiptDrift[];
+  signature?: ReceiptSignatureCheck;
   rederived: Receipt | null;
 }
 
@@ -885,6 +949,70 @@ export interface ImpactResult {
   downstream: ImpactNode[];
 }
 
+export interface LineageUpstreamQuery {
+  namespace: string;
+  name: string;
+  runId?: string;
+  field?: string;
+  maxDepth?: number;
+}
+
+export interface UpstreamNode {
+  dataset_namespace: string;
+  dataset_name: string;
+  feeds_namespace?: string;
+  feeds_name?: string;
+  producing_step?: string;
+  job_id: string;
+  job_alias?: string;
+  run_id?: string;
+  task_run_id?: string;
+  task_status?: string;
+  provenance_commit?: string;
+  provenance_repo?: string;
+  produced_at?: string;
+  watermark?: string;
+  source?: "lineage" | "derivation";
+  external?: boolean;
+  depth: number;
+}
+
+export interface ColumnEdge {
+  input_dataset: string;
+  input_field: string;
+  output_dataset: string;
+  output_field: string;
+  type: "DIRECT" | "INDIRECT";
+  subtype?: string;
+  description?: string;
+  producing_step: string;
+  job_id: string;
+  job_alias: string;
+  depth: number;
+}
+
+export interface UpstreamResult {
+  root_namespace: string;
+  root_name: string;
+  root_run_id?: string;
+  producer: UpstreamNode | null;
+  upstream: UpstreamNode[];
+  root_field?: string;
+  columns?: ColumnEdge[];
+}
+
+export interface LineageColumnImpactQuery {
+  name: string;
+  field: string;
+  maxDepth?: number;
+}
+
+export interface ColumnImpactResult {
+  root_name: string;
+  root_field: string;
+  downstream: ColumnEdge[];
+}
+
 export type ContractNodeKind = "job" | "dataset";
 export type ContractEdgeClass = "declared" | "inferred" | "evidence";
 export type ContractVerdict = "breaking" | "compatible" | "unknown";
@@ -1132,6 +1260,11 @@ function normalizeJobTask(raw: RawJobTask): JobTask {
     task.next_id = nextId;
   }
 
+  const nodeAffinity = pickDefined(raw.node_affinity, raw.NodeAffinity);
+  if (nodeAffinity !== undefined) {
+    task.node_affinity = nodeAffinity;
+  }
+
   const cacheConfig = pickDefined(raw.cache_config, raw.CacheConfig);
   if (cacheConfig !== undefined) {
     task.cache_config = cacheConfig;
@@ -1311,6 +1444,24 @@ export const api = {
         max_depth: query.maxDepth,
       })}`,
     ),
+  getLineageUpstream: (query: LineageUpstreamQuery) =>
+    request<UpstreamResult>(
+      `/lineage/upstream?${queryString({
+        namespace: query.namespace,
+        name: query.name,
+        run_id: query.runId,
+        field: query.field,
+        max_depth: query.maxDepth,
+      })}`,
+    ),
+  getLineageColumnImpact: (query: LineageColumnImpactQuery) =>
+    request<ColumnImpactResult>(
+      `/lineage/column-impact?${queryString({
+        name: query.name,
+        field: query.field,
+        max_depth: query.maxDepth,
+      })}`,
+    ),
   getHealth: () => requestURL<HealthResponse>("/health"),
   /**
    * Like `getHealth` but reads the response body on any non-401 status code.
@@ -1364,4 +1515,12 @@ export const api = {
     request<Backfill>(`/jobs/${jobId}/backfills/${backfillId}/cancel`, {
       method: "PUT",
     }),
+  pauseBackfill: (jobId: string, backfillId: string) =>
+    request<Backfill>(`/jobs/${jobId}/backfills/${backfillId}/pause`, {
+      method: "PUT",
+    }),
+  resumeBackfill: (jobId: string, backfillId: string) =>
+    request<Backfill>(`/jobs/${jobId}/backfills/${backfillId}/resume`, {
+      method: "PUT",
+    }),
 };